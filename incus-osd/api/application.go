@@ -5,15 +5,49 @@ import (
 )
 
 // ApplicationConfig represents additional configuration for an application.
-type ApplicationConfig struct{}
+type ApplicationConfig struct {
+	Update *ApplicationUpdateConfig `json:"update,omitempty" yaml:"update,omitempty"` // Update policy of the application, following the system's if not set.
+}
+
+// ApplicationUpdateConfig defines how an application is updated, independently of the OS.
+type ApplicationUpdateConfig struct {
+	Channel        string `json:"channel,omitempty"         yaml:"channel,omitempty"`         // Release channel to follow, defaults to the system's.
+	CheckFrequency string `json:"check_frequency,omitempty" yaml:"check_frequency,omitempty"` // Minimum time between two update checks of the application, or "never".
+	PinnedVersion  string `json:"pinned_version,omitempty"  yaml:"pinned_version,omitempty"`  // Only install this version of the application.
+}
 
 // Application represents the state and configuration of a generic application.
 type Application struct {
 	State struct {
-		Initialized  bool       `json:"initialized"             yaml:"initialized"`
-		Version      string     `json:"version"                 yaml:"version"`
-		LastRestored *time.Time `json:"last_restored,omitempty" yaml:"last_restored,omitempty"` // In system's timezone.
+		Initialized     bool       `json:"initialized"                 yaml:"initialized"`
+		Version         string     `json:"version"                     yaml:"version"`
+		LastRestored    *time.Time `json:"last_restored,omitempty"     yaml:"last_restored,omitempty"`     // In system's timezone.
+		LastUpdateCheck string     `json:"last_update_check,omitempty" yaml:"last_update_check,omitempty"` // RFC3339 timestamp of the last update check of the application.
 	} `json:"state" yaml:"state"`
 
 	Config ApplicationConfig `json:"config" yaml:"config"`
 }
+
+// IsCheckDue returns whether an automatic update check of the application is due at the provided time,
+// given the time of the last one.
+func (c *ApplicationUpdateConfig) IsCheckDue(lastCheck string, t time.Time) bool {
+	if c == nil || c.CheckFrequency == "" {
+		return true
+	}
+
+	if c.CheckFrequency == "never" {
+		return false
+	}
+
+	frequency, err := time.ParseDuration(c.CheckFrequency)
+	if err != nil {
+		return true
+	}
+
+	last, err := time.Parse(time.RFC3339, lastCheck)
+	if err != nil {
+		return true
+	}
+
+	return !t.Before(last.Add(frequency))
+}
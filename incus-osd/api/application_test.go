@@ -0,0 +1,30 @@
+package api_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+func TestApplicationUpdateCheckDue(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 11, 4, 12, 0, 0, 0, time.UTC)
+
+	var cfg *api.ApplicationUpdateConfig
+	require.True(t, cfg.IsCheckDue("", now))
+
+	cfg = &api.ApplicationUpdateConfig{}
+	require.True(t, cfg.IsCheckDue("2025-11-04T11:59:00Z", now))
+
+	cfg = &api.ApplicationUpdateConfig{CheckFrequency: "never"}
+	require.False(t, cfg.IsCheckDue("", now))
+
+	cfg = &api.ApplicationUpdateConfig{CheckFrequency: "168h"}
+	require.True(t, cfg.IsCheckDue("", now))
+	require.False(t, cfg.IsCheckDue("2025-11-01T12:00:00Z", now))
+	require.True(t, cfg.IsCheckDue("2025-10-28T12:00:00Z", now))
+}
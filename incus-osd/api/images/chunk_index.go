@@ -0,0 +1,17 @@
+package images
+
+// ChunkIndex represents the content of a chunk index, describing how an update file is split into
+// content-defined chunks so that only the chunks missing locally need to be downloaded.
+type ChunkIndex struct {
+	Format string `json:"format"`
+
+	Sha256 string            `json:"sha256"` // Of the uncompressed file.
+	Size   int64             `json:"size"`   // Of the uncompressed file.
+	Chunks []ChunkIndexEntry `json:"chunks"`
+}
+
+// ChunkIndexEntry represents a single chunk, stored compressed in the chunk store.
+type ChunkIndexEntry struct {
+	Sha256 string `json:"sha256"` // Of the uncompressed chunk.
+	Size   int64  `json:"size"`   // Of the uncompressed chunk.
+}
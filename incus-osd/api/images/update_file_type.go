@@ -36,6 +36,9 @@ const (
 
 	// UpdateFileTypeApplication represents an application.
 	UpdateFileTypeApplication UpdateFileType = "application"
+
+	// UpdateFileTypeChunkIndex represents the chunk index of another file, used for delta updates.
+	UpdateFileTypeChunkIndex UpdateFileType = "chunk-index"
 )
 
 // UpdateFileTypes is a map of the supported update file types.
@@ -50,6 +53,7 @@ var UpdateFileTypes = map[UpdateFileType]struct{}{
 	UpdateFileTypeUpdateUsrVerity:          {},
 	UpdateFileTypeUpdateUsrVeritySignature: {},
 	UpdateFileTypeApplication:              {},
+	UpdateFileTypeChunkIndex:               {},
 }
 
 func (u *UpdateFileType) String() string {
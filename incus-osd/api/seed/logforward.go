@@ -0,0 +1,12 @@
+package seed
+
+import (
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// LogForward represents the log forwarding seed.
+type LogForward struct {
+	api.ServiceLogForwardConfig `yaml:",inline"`
+
+	Version string `json:"version" yaml:"version"`
+}
@@ -0,0 +1,14 @@
+package seed
+
+import (
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// Security represents the security seed.
+type Security struct {
+	CACertificates []api.SystemSecurityCACertificate `json:"ca_certificates" yaml:"ca_certificates"`
+	Firewall       *api.SystemSecurityFirewall       `json:"firewall"        yaml:"firewall"`
+	NBDE           *api.SystemSecurityNBDE           `json:"nbde"            yaml:"nbde"`
+
+	Version string `json:"version" yaml:"version"`
+}
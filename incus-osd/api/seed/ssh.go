@@ -0,0 +1,12 @@
+package seed
+
+import (
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// SSH represents the SSH seed.
+type SSH struct {
+	api.ServiceSSHConfig `yaml:",inline"`
+
+	Version string `json:"version" yaml:"version"`
+}
@@ -0,0 +1,12 @@
+package seed
+
+import (
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// Tailscale represents the Tailscale seed.
+type Tailscale struct {
+	api.ServiceTailscaleConfig `yaml:",inline"`
+
+	Version string `json:"version" yaml:"version"`
+}
@@ -0,0 +1,40 @@
+package api
+
+// ServiceBGPNeighbor represents a BGP peer, typically an upstream router.
+type ServiceBGPNeighbor struct {
+	Address     string `json:"address"               yaml:"address"`
+	ASN         uint32 `json:"asn"                   yaml:"asn"`
+	Password    string `json:"password,omitempty"    yaml:"password,omitempty"` // TCP MD5 password, can reference a secret.
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// ServiceBGPConfig represents additional configuration for the BGP service.
+type ServiceBGPConfig struct {
+	Enabled   bool                 `json:"enabled"   yaml:"enabled"`
+	ASN       uint32               `json:"asn"       yaml:"asn"`
+	RouterID  string               `json:"router_id" yaml:"router_id"` // Defaults to the highest IPv4 address of the system.
+	Neighbors []ServiceBGPNeighbor `json:"neighbors" yaml:"neighbors"`
+	Prefixes  []string             `json:"prefixes"  yaml:"prefixes"` // Prefixes advertised to the neighbors, like Incus network subnets or OVN external addresses.
+}
+
+// ServiceBGP represents the state and configuration of the BGP service.
+type ServiceBGP struct {
+	State ServiceBGPState `incusos:"-" json:"state" yaml:"state"`
+
+	Config ServiceBGPConfig `json:"config" yaml:"config"`
+}
+
+// ServiceBGPState represents the state for the BGP service.
+type ServiceBGPState struct {
+	Sessions []ServiceBGPSession `json:"sessions" yaml:"sessions"`
+}
+
+// ServiceBGPSession represents the state of the session with a BGP neighbor.
+type ServiceBGPSession struct {
+	Address          string `json:"address"           yaml:"address"`
+	ASN              uint32 `json:"asn"               yaml:"asn"`
+	State            string `json:"state"             yaml:"state"` // BGP finite state machine state, like "Established".
+	Uptime           string `json:"uptime"            yaml:"uptime"`
+	ReceivedPrefixes int    `json:"received_prefixes" yaml:"received_prefixes"`
+	SentPrefixes     int    `json:"sent_prefixes"     yaml:"sent_prefixes"`
+}
@@ -2,15 +2,16 @@ package api
 
 // ServiceCephCluster represents a single Ceph cluster.
 type ServiceCephCluster struct {
-	FSID         string                        `json:"fsid"          yaml:"fsid"`
-	Monitors     []string                      `json:"monitors"      yaml:"monitors"`
-	Keyrings     map[string]ServiceCephKeyring `json:"keyrings"      yaml:"keyrings"`
-	ClientConfig map[string]string             `json:"client_config" yaml:"client_config"`
+	FSID         string                        `json:"fsid"                  yaml:"fsid"`
+	Monitors     []string                      `json:"monitors"              yaml:"monitors"`
+	Keyrings     map[string]ServiceCephKeyring `json:"keyrings"              yaml:"keyrings"`
+	ClientConfig map[string]string             `json:"client_config"         yaml:"client_config"`
+	StatusUser   string                        `json:"status_user,omitempty" yaml:"status_user,omitempty"` // Keyring used to check the cluster status, defaults to the first one.
 }
 
 // ServiceCephKeyring represents a single Ceph keyring entry.
 type ServiceCephKeyring struct {
-	Key string `json:"key" yaml:"key"`
+	Key string `json:"key" yaml:"key"` // Can reference a secret.
 }
 
 // ServiceCephConfig represents additional configuration for the Ceph service.
@@ -20,7 +21,18 @@ type ServiceCephConfig struct {
 }
 
 // ServiceCephState represents state for the Ceph service.
-type ServiceCephState struct{}
+type ServiceCephState struct {
+	Clusters map[string]ServiceCephClusterState `json:"clusters" yaml:"clusters"`
+}
+
+// ServiceCephClusterState represents the connectivity and health of a Ceph cluster.
+type ServiceCephClusterState struct {
+	Connected    bool     `json:"connected"       yaml:"connected"`
+	Health       string   `json:"health"          yaml:"health"` // Like "HEALTH_OK" or "HEALTH_WARN".
+	HealthChecks []string `json:"health_checks"   yaml:"health_checks"`
+	Monitors     []string `json:"monitors"        yaml:"monitors"` // Monitors in quorum.
+	Error        string   `json:"error,omitempty" yaml:"error,omitempty"`
+}
 
 // ServiceCeph represents the state and configuration of the Ceph service.
 type ServiceCeph struct {
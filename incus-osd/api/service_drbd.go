@@ -0,0 +1,55 @@
+package api
+
+// ServiceDRBDPeer represents the other node of a DRBD resource.
+type ServiceDRBDPeer struct {
+	Name    string `json:"name"           yaml:"name"`           // Hostname of the peer.
+	Address string `json:"address"        yaml:"address"`        // Replication address of the peer.
+	Disk    string `json:"disk,omitempty" yaml:"disk,omitempty"` // Backing disk on the peer, defaults to the local one.
+}
+
+// ServiceDRBDResource represents a block device replicated between two nodes.
+type ServiceDRBDResource struct {
+	Name     string          `json:"name"               yaml:"name"`
+	Minor    int             `json:"minor"              yaml:"minor"`              // The device is exposed as /dev/drbd<minor>.
+	Disk     string          `json:"disk"               yaml:"disk"`               // Local backing disk, its metadata is initialized on first use.
+	Address  string          `json:"address"            yaml:"address"`            // Local replication address.
+	Port     int             `json:"port,omitempty"     yaml:"port,omitempty"`     // Defaults to 7789 plus the minor.
+	Protocol string          `json:"protocol,omitempty" yaml:"protocol,omitempty"` // Replication protocol, one of "A", "B" or "C" (default).
+	Secret   string          `json:"secret,omitempty"   yaml:"secret,omitempty"`   // Shared secret authenticating the peer, can reference a secret.
+	Peer     ServiceDRBDPeer `json:"peer"               yaml:"peer"`
+}
+
+// ServiceDRBDConfig represents additional configuration for the DRBD service.
+type ServiceDRBDConfig struct {
+	Enabled   bool                  `json:"enabled"   yaml:"enabled"`
+	Resources []ServiceDRBDResource `json:"resources" yaml:"resources"`
+}
+
+// ServiceDRBDState represents state for the DRBD service.
+type ServiceDRBDState struct {
+	Resources []ServiceDRBDResourceState `json:"resources" yaml:"resources"`
+}
+
+// ServiceDRBDResourceState represents the current state of a DRBD resource.
+type ServiceDRBDResourceState struct {
+	Name            string  `json:"name"             yaml:"name"`
+	Role            string  `json:"role"             yaml:"role"`             // Either "Primary", "Secondary" or "Unknown" if the resource is down.
+	DiskState       string  `json:"disk_state"       yaml:"disk_state"`       // Like "UpToDate", "Inconsistent" or "Diskless".
+	ConnectionState string  `json:"connection_state" yaml:"connection_state"` // Like "Connected", "Connecting" or "StandAlone".
+	PeerRole        string  `json:"peer_role"        yaml:"peer_role"`
+	PeerDiskState   string  `json:"peer_disk_state"  yaml:"peer_disk_state"`
+	SyncProgress    float64 `json:"sync_progress"    yaml:"sync_progress"` // Percentage of the peer's data in sync.
+}
+
+// ServiceDRBDRoleChange represents a request to promote or demote a DRBD resource.
+type ServiceDRBDRoleChange struct {
+	Resource string `json:"resource" yaml:"resource"`
+	Force    bool   `json:"force"    yaml:"force"` // Promote even if the local data isn't up to date, like for the initial synchronization.
+}
+
+// ServiceDRBD represents the state and configuration of the DRBD service.
+type ServiceDRBD struct {
+	State ServiceDRBDState `incusos:"-" json:"state" yaml:"state"`
+
+	Config ServiceDRBDConfig `json:"config" yaml:"config"`
+}
@@ -0,0 +1,51 @@
+package api
+
+// ServiceFCConfig represents additional configuration for the Fibre Channel service.
+type ServiceFCConfig struct {
+	Enabled       bool     `json:"enabled"        yaml:"enabled"`
+	DisabledPorts []string `json:"disabled_ports" yaml:"disabled_ports"` // WWPNs of the local ports to disable.
+}
+
+// ServiceFCState represents state for the Fibre Channel service.
+type ServiceFCState struct {
+	Ports []ServiceFCPort `json:"ports" yaml:"ports"`
+}
+
+// ServiceFCPort represents a local Fibre Channel port, provided by an HBA.
+type ServiceFCPort struct {
+	Name        string                `json:"name"         yaml:"name"` // SCSI host, like "host0", empty when disabled.
+	WWPN        string                `json:"wwpn"         yaml:"wwpn"`
+	WWNN        string                `json:"wwnn"         yaml:"wwnn"`
+	State       string                `json:"state"        yaml:"state"` // Like "Online", "Linkdown" or "Disabled".
+	Speed       string                `json:"speed"        yaml:"speed"`
+	FabricName  string                `json:"fabric_name"  yaml:"fabric_name"`
+	PCIAddress  string                `json:"pci_address"  yaml:"pci_address"`
+	Driver      string                `json:"driver"       yaml:"driver"`
+	RemotePorts []ServiceFCRemotePort `json:"remote_ports" yaml:"remote_ports"`
+}
+
+// ServiceFCRemotePort represents a remote Fibre Channel port, detected through a local port.
+type ServiceFCRemotePort struct {
+	Name   string         `json:"name"    yaml:"name"`
+	WWPN   string         `json:"wwpn"    yaml:"wwpn"`
+	WWNN   string         `json:"wwnn"    yaml:"wwnn"`
+	PortID string         `json:"port_id" yaml:"port_id"`
+	State  string         `json:"state"   yaml:"state"`
+	Roles  string         `json:"roles"   yaml:"roles"` // Like "FCP Target".
+	LUNs   []ServiceFCLUN `json:"luns"    yaml:"luns"`
+}
+
+// ServiceFCLUN represents a LUN exposed by a remote Fibre Channel port.
+type ServiceFCLUN struct {
+	LUN    int    `json:"lun"    yaml:"lun"`
+	Device string `json:"device" yaml:"device"` // Block device, like "sda".
+	Vendor string `json:"vendor" yaml:"vendor"`
+	Model  string `json:"model"  yaml:"model"`
+}
+
+// ServiceFC represents the state and configuration of the Fibre Channel service.
+type ServiceFC struct {
+	State ServiceFCState `incusos:"-" json:"state" yaml:"state"`
+
+	Config ServiceFCConfig `json:"config" yaml:"config"`
+}
@@ -0,0 +1,30 @@
+package api
+
+// ServiceHAProxyConfig represents additional configuration for the HAProxy service.
+type ServiceHAProxyConfig struct {
+	Enabled               bool     `json:"enabled"                 yaml:"enabled"`
+	ListenAddress         string   `json:"listen_address"          yaml:"listen_address"` // Defaults to ":443".
+	Mode                  string   `json:"mode"                    yaml:"mode"`           // Either "http" (default) terminating TLS, or "tcp" passing TLS through to the members.
+	Backends              []string `json:"backends"                yaml:"backends"`       // Incus cluster members, as "host[:port]", defaulting to port 8443.
+	TLSServerCertificate  string   `json:"tls_server_certificate"  yaml:"tls_server_certificate"`
+	TLSServerKey          string   `json:"tls_server_key"          yaml:"tls_server_key"`          // Can reference a secret.
+	TLSBackendCertificate string   `json:"tls_backend_certificate" yaml:"tls_backend_certificate"` // Incus cluster certificate used to verify the members.
+}
+
+// ServiceHAProxyState represents state for the HAProxy service.
+type ServiceHAProxyState struct {
+	Backends []ServiceHAProxyBackendState `json:"backends" yaml:"backends"`
+}
+
+// ServiceHAProxyBackendState represents the health of an Incus cluster member.
+type ServiceHAProxyBackendState struct {
+	Address string `json:"address" yaml:"address"`
+	Status  string `json:"status"  yaml:"status"` // As reported by HAProxy, like "UP", "DOWN" or "MAINT".
+}
+
+// ServiceHAProxy represents the state and configuration of the HAProxy service.
+type ServiceHAProxy struct {
+	State ServiceHAProxyState `incusos:"-" json:"state" yaml:"state"`
+
+	Config ServiceHAProxyConfig `json:"config" yaml:"config"`
+}
@@ -0,0 +1,44 @@
+package api
+
+// ServiceIPMIConfig represents additional configuration for the IPMI service.
+type ServiceIPMIConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+}
+
+// ServiceIPMIState represents state for the IPMI service.
+type ServiceIPMIState struct {
+	FirmwareVersion string              `json:"firmware_version" yaml:"firmware_version"`
+	PowerState      string              `json:"power_state"      yaml:"power_state"` // Either "on" or "off".
+	Sensors         []ServiceIPMISensor `json:"sensors"          yaml:"sensors"`
+	Events          []ServiceIPMIEvent  `json:"events"           yaml:"events"` // Most recent entries of the System Event Log.
+}
+
+// ServiceIPMISensor represents a sensor reading from the BMC.
+type ServiceIPMISensor struct {
+	Name   string `json:"name"   yaml:"name"`
+	Value  string `json:"value"  yaml:"value"`
+	Unit   string `json:"unit"   yaml:"unit"`   // Like "degrees C", "RPM" or "discrete".
+	Status string `json:"status" yaml:"status"` // Like "ok", "nc" (non-critical), "cr" (critical) or "ns" (no reading).
+}
+
+// ServiceIPMIEvent represents an entry of the System Event Log.
+type ServiceIPMIEvent struct {
+	ID          string `json:"id"          yaml:"id"`
+	Time        string `json:"time"        yaml:"time"` // As reported by the BMC, in its own time zone.
+	Sensor      string `json:"sensor"      yaml:"sensor"`
+	Description string `json:"description" yaml:"description"`
+	Direction   string `json:"direction"   yaml:"direction"` // Either "Asserted" or "Deasserted".
+}
+
+// ServiceIPMIIdentify represents a request to turn on the chassis identify LED.
+type ServiceIPMIIdentify struct {
+	Duration int  `json:"duration" yaml:"duration"` // Seconds to keep the LED on, up to 255, 0 turning it off.
+	Force    bool `json:"force"    yaml:"force"`    // Keep the LED on until turned off.
+}
+
+// ServiceIPMI represents the state and configuration of the IPMI service.
+type ServiceIPMI struct {
+	State ServiceIPMIState `incusos:"-" json:"state" yaml:"state"`
+
+	Config ServiceIPMIConfig `json:"config" yaml:"config"`
+}
@@ -1,16 +1,57 @@
 package api
 
+// ServiceISCSIAuthentication represents the CHAP credentials used with ISCSI targets.
+type ServiceISCSIAuthentication struct {
+	Username       string `json:"username"                  yaml:"username"`
+	Password       string `json:"password"                  yaml:"password"`                  // Can reference a secret.
+	TargetUsername string `json:"target_username,omitempty" yaml:"target_username,omitempty"` // Credentials the target authenticates with for mutual CHAP.
+	TargetPassword string `json:"target_password,omitempty" yaml:"target_password,omitempty"` // Can reference a secret.
+}
+
 // ServiceISCSITarget represents a single ISCSI target.
 type ServiceISCSITarget struct {
-	Target  string `json:"target"  yaml:"target"`
-	Address string `json:"address" yaml:"address"`
-	Port    int    `json:"port"    yaml:"port"`
+	Target         string                      `json:"target"                   yaml:"target"`
+	Address        string                      `json:"address"                  yaml:"address"` // Discovered through iSNS if empty.
+	Port           int                         `json:"port"                     yaml:"port"`
+	Authentication *ServiceISCSIAuthentication `json:"authentication,omitempty" yaml:"authentication,omitempty"` // Overrides the service-wide credentials for this target.
 }
 
 // ServiceISCSIConfig represents additional configuration for the ISCSI service.
 type ServiceISCSIConfig struct {
-	Enabled bool                 `json:"enabled" yaml:"enabled"`
-	Targets []ServiceISCSITarget `json:"targets" yaml:"targets"`
+	Enabled        bool                        `json:"enabled"                  yaml:"enabled"`
+	Targets        []ServiceISCSITarget        `json:"targets"                  yaml:"targets"`
+	Authentication *ServiceISCSIAuthentication `json:"authentication,omitempty" yaml:"authentication,omitempty"` // CHAP credentials used for discovery and login, unless overridden by the target.
+	Exports        []ServiceISCSIExport        `json:"exports,omitempty"        yaml:"exports,omitempty"`        // Local targets exporting ZFS volumes to other systems.
+	ISNS           *ServiceISCSIISNS           `json:"isns,omitempty"           yaml:"isns,omitempty"`           // iSNS server used to discover targets.
+}
+
+// ServiceISCSIISNS represents the iSNS server used to discover ISCSI targets.
+type ServiceISCSIISNS struct {
+	Address string `json:"address" yaml:"address"`
+	Port    int    `json:"port"    yaml:"port"` // Defaults to 3205.
+}
+
+// ServiceISCSIExport represents a local ISCSI target, exporting ZFS volumes through LIO.
+type ServiceISCSIExport struct {
+	Target string                  `json:"target" yaml:"target"` // IQN of the target.
+	Port   int                     `json:"port"   yaml:"port"`   // Defaults to 3260.
+	LUNs   []ServiceISCSIExportLUN `json:"luns"   yaml:"luns"`
+	ACLs   []ServiceISCSIExportACL `json:"acls"   yaml:"acls"`
+}
+
+// ServiceISCSIExportLUN represents a ZFS volume exported as a LUN.
+type ServiceISCSIExportLUN struct {
+	LUN         int    `json:"lun"                     yaml:"lun"`
+	Pool        string `json:"pool"                    yaml:"pool"`
+	Volume      string `json:"volume"                  yaml:"volume"`
+	SizeInBytes int    `json:"size_in_bytes,omitempty" yaml:"size_in_bytes,omitempty"` // Creates the volume with this size if it doesn't exist.
+}
+
+// ServiceISCSIExportACL represents an initiator allowed to access an exported target.
+type ServiceISCSIExportACL struct {
+	Initiator      string                      `json:"initiator"                yaml:"initiator"`                // IQN of the initiator.
+	LUNs           []int                       `json:"luns,omitempty"           yaml:"luns,omitempty"`           // LUNs mapped to the initiator, all of them if empty.
+	Authentication *ServiceISCSIAuthentication `json:"authentication,omitempty" yaml:"authentication,omitempty"` // CHAP credentials the initiator must log in with.
 }
 
 // ServiceISCSI represents the state and configuration of the ISCSI service.
@@ -22,5 +63,20 @@ type ServiceISCSI struct {
 
 // ServiceISCSIState represents the state for the ISCSI service.
 type ServiceISCSIState struct {
-	InitiatorName string `json:"initiator_name" yaml:"initiator_name"`
+	InitiatorName string                    `json:"initiator_name" yaml:"initiator_name"`
+	Exports       []ServiceISCSIExportState `json:"exports"        yaml:"exports"`
+	Discovered    []ServiceISCSIDiscovered  `json:"discovered"     yaml:"discovered"` // Targets registered with the iSNS server.
+}
+
+// ServiceISCSIDiscovered represents a target registered with the iSNS server.
+type ServiceISCSIDiscovered struct {
+	Target  string `json:"target"  yaml:"target"`
+	Address string `json:"address" yaml:"address"`
+	Port    int    `json:"port"    yaml:"port"`
+}
+
+// ServiceISCSIExportState represents the state of a local ISCSI target.
+type ServiceISCSIExportState struct {
+	Target     string   `json:"target"     yaml:"target"`
+	Initiators []string `json:"initiators" yaml:"initiators"` // Initiators with an active session.
 }
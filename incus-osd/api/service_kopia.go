@@ -47,6 +47,8 @@ type ServiceKopiaConfig struct {
 	RepositoryPassword string                      `json:"repository_password"   yaml:"repository_password"` // Required for encrypted repositories (both init and connect)
 	Backend            ServiceKopiaBackendConfig   `json:"backend"              yaml:"backend"`
 	Retention          ServiceKopiaRetentionPolicy `json:"retention,omitempty" yaml:"retention,omitempty"`
+	// Pools lists the ZFS pools to back up. If empty, only the "local" pool is backed up.
+	Pools []string `json:"pools,omitempty" yaml:"pools,omitempty"`
 	// BackupFrequency defines the time interval between backup cycles. If empty or not set, defaults to once per maintenance window.
 	// Supported formats: Duration string (e.g., "1h", "2m", "1w", "24h")
 	// - Empty string: Once per maintenance window (default)
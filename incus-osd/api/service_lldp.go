@@ -0,0 +1,30 @@
+package api
+
+// ServiceLLDPNeighbor represents a single neighbor discovered through LLDP.
+type ServiceLLDPNeighbor struct {
+	ChassisID           string   `json:"chassis_id"           yaml:"chassis_id"`
+	ChassisName         string   `json:"chassis_name"         yaml:"chassis_name"`
+	SystemDescription   string   `json:"system_description"   yaml:"system_description"`
+	ManagementAddresses []string `json:"management_addresses" yaml:"management_addresses"`
+	PortID              string   `json:"port_id"              yaml:"port_id"`
+	PortDescription     string   `json:"port_description"     yaml:"port_description"`
+}
+
+// ServiceLLDPConfig represents additional configuration for the LLDP service.
+type ServiceLLDPConfig struct {
+	Enabled             bool     `json:"enabled"              yaml:"enabled"`
+	Interfaces          []string `json:"interfaces"           yaml:"interfaces"`
+	ManagementAddresses []string `json:"management_addresses" yaml:"management_addresses"`
+}
+
+// ServiceLLDP represents the state and configuration of the LLDP service.
+type ServiceLLDP struct {
+	State ServiceLLDPState `incusos:"-" json:"state" yaml:"state"`
+
+	Config ServiceLLDPConfig `json:"config" yaml:"config"`
+}
+
+// ServiceLLDPState represents the state for the LLDP service.
+type ServiceLLDPState struct {
+	Neighbors map[string][]ServiceLLDPNeighbor `json:"neighbors" yaml:"neighbors"`
+}
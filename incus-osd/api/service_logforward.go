@@ -0,0 +1,48 @@
+package api
+
+import (
+	"time"
+)
+
+// ServiceLogForwardTarget represents a remote log collector the system journal is forwarded to.
+type ServiceLogForwardTarget struct {
+	Name                 string            `json:"name"                             yaml:"name"`
+	Type                 string            `json:"type"                             yaml:"type"`                         // One of "syslog", "loki" or "http".
+	Address              string            `json:"address"                          yaml:"address"`                      // Host and optional port for syslog, URL for Loki and HTTP.
+	Protocol             string            `json:"protocol,omitempty"               yaml:"protocol,omitempty"`           // Syslog transport, one of "udp" (default), "tcp" or "tls".
+	Units                []string          `json:"units,omitempty"                  yaml:"units,omitempty"`              // Only forward the logs of these systemd units.
+	Priority             string            `json:"priority,omitempty"               yaml:"priority,omitempty"`           // Lowest forwarded priority, like "warning", defaults to "info".
+	Labels               map[string]string `json:"labels,omitempty"                 yaml:"labels,omitempty"`             // Additional Loki stream labels.
+	Username             string            `json:"username,omitempty"               yaml:"username,omitempty"`           // HTTP basic authentication, for Loki and HTTP.
+	Password             string            `json:"password,omitempty"               yaml:"password,omitempty"`           // Can reference a secret.
+	TLSCACertificate     string            `json:"tls_ca_certificate,omitempty"     yaml:"tls_ca_certificate,omitempty"` // Replaces the system trust store.
+	TLSClientCertificate string            `json:"tls_client_certificate,omitempty" yaml:"tls_client_certificate,omitempty"`
+	TLSClientKey         string            `json:"tls_client_key,omitempty"         yaml:"tls_client_key,omitempty"` // Can reference a secret.
+}
+
+// ServiceLogForwardConfig represents additional configuration for the log forwarding service.
+type ServiceLogForwardConfig struct {
+	Enabled bool                      `json:"enabled" yaml:"enabled"`
+	Targets []ServiceLogForwardTarget `json:"targets" yaml:"targets"`
+}
+
+// ServiceLogForwardState represents state for the log forwarding service.
+type ServiceLogForwardState struct {
+	Targets []ServiceLogForwardTargetState `json:"targets" yaml:"targets"`
+}
+
+// ServiceLogForwardTargetState represents the forwarding status of a remote log collector.
+type ServiceLogForwardTargetState struct {
+	Name          string    `json:"name"            yaml:"name"`
+	Status        string    `json:"status"          yaml:"status"`    // One of "forwarding", "retrying" or "stopped".
+	Forwarded     int64     `json:"forwarded"       yaml:"forwarded"` // Number of entries forwarded since the service started.
+	LastForwarded time.Time `json:"last_forwarded"  yaml:"last_forwarded"`
+	Error         string    `json:"error,omitempty" yaml:"error,omitempty"` // Last error, while retrying.
+}
+
+// ServiceLogForward represents the state and configuration of the log forwarding service.
+type ServiceLogForward struct {
+	State ServiceLogForwardState `incusos:"-" json:"state" yaml:"state"`
+
+	Config ServiceLogForwardConfig `json:"config" yaml:"config"`
+}
@@ -2,8 +2,32 @@ package api
 
 // ServiceLVMConfig represents additional configuration for the LVM service.
 type ServiceLVMConfig struct {
-	Enabled  bool  `json:"enabled"   yaml:"enabled"`
-	SystemID int64 `json:"system_id" yaml:"system_id"`
+	Enabled    bool                  `json:"enabled"               yaml:"enabled"`
+	SystemID   int64                 `json:"system_id"             yaml:"system_id"`
+	ThinPools  []ServiceLVMThinPool  `json:"thin_pools,omitempty"  yaml:"thin_pools,omitempty"`
+	VDOVolumes []ServiceLVMVDOVolume `json:"vdo_volumes,omitempty" yaml:"vdo_volumes,omitempty"`
+}
+
+// ServiceLVMVDOVolume represents a deduplicated and compressed logical volume, created if missing.
+type ServiceLVMVDOVolume struct {
+	VGName               string `json:"vg_name"                         yaml:"vg_name"`
+	Name                 string `json:"name"                            yaml:"name"`
+	Size                 string `json:"size"                            yaml:"size"`         // Physical size of the backing VDO pool, like "100G" or "80%FREE".
+	VirtualSize          string `json:"virtual_size"                    yaml:"virtual_size"` // Size of the volume as seen by its users.
+	DisableCompression   bool   `json:"disable_compression,omitempty"   yaml:"disable_compression,omitempty"`
+	DisableDeduplication bool   `json:"disable_deduplication,omitempty" yaml:"disable_deduplication,omitempty"`
+}
+
+// ServiceLVMThinPool represents a thin pool, created if missing and monitored while active on the system.
+type ServiceLVMThinPool struct {
+	VGName                   string `json:"vg_name"                    yaml:"vg_name"`
+	Name                     string `json:"name"                       yaml:"name"`
+	Size                     string `json:"size"                       yaml:"size"`                       // Size of the pool when created, like "100G" or "80%FREE".
+	MetadataSize             string `json:"metadata_size,omitempty"    yaml:"metadata_size,omitempty"`    // Defaults to a size computed by LVM.
+	DataWarningThreshold     int    `json:"data_warning_threshold"     yaml:"data_warning_threshold"`     // Usage percentage raising an alarm, defaults to 80.
+	MetadataWarningThreshold int    `json:"metadata_warning_threshold" yaml:"metadata_warning_threshold"` // Usage percentage raising an alarm, defaults to 80.
+	AutoExtendThreshold      int    `json:"auto_extend_threshold"      yaml:"auto_extend_threshold"`      // Usage percentage extending the pool, disabled if 0.
+	AutoExtendPercent        int    `json:"auto_extend_percent"        yaml:"auto_extend_percent"`        // Percentage to extend the pool by, defaults to 20.
 }
 
 // ServiceLVM represents the state and configuration of the LVM service.
@@ -15,9 +39,36 @@ type ServiceLVM struct {
 
 // ServiceLVMState represents the state for the LVM service.
 type ServiceLVMState struct {
-	PVs []ServiceLVMPV  `json:"pvs,omitempty" yaml:"pvs,omitempty"`
-	VGs []ServiceLVMVG  `json:"vgs,omitempty" yaml:"vgs,omitempty"`
-	Log []ServiceLVMLog `json:"log,omitempty" yaml:"log,omitempty"`
+	PVs        []ServiceLVMPV             `json:"pvs,omitempty"         yaml:"pvs,omitempty"`
+	VGs        []ServiceLVMVG             `json:"vgs,omitempty"         yaml:"vgs,omitempty"`
+	Log        []ServiceLVMLog            `json:"log,omitempty"         yaml:"log,omitempty"`
+	ThinPools  []ServiceLVMThinPoolState  `json:"thin_pools,omitempty"  yaml:"thin_pools,omitempty"`
+	VDOVolumes []ServiceLVMVDOVolumeState `json:"vdo_volumes,omitempty" yaml:"vdo_volumes,omitempty"`
+}
+
+// ServiceLVMVDOVolumeState represents the space savings of a VDO volume.
+type ServiceLVMVDOVolumeState struct {
+	VGName           string  `json:"vg_name"           yaml:"vg_name"`
+	Name             string  `json:"name"              yaml:"name"`
+	Active           bool    `json:"active"            yaml:"active"` // Statistics are only known while the volume is active on the system.
+	Size             string  `json:"size"              yaml:"size"`
+	PhysicalSize     string  `json:"physical_size"     yaml:"physical_size"`
+	UsedSize         string  `json:"used_size"         yaml:"used_size"` // Physical space in use, after deduplication and compression.
+	SavingPercent    float64 `json:"saving_percent"    yaml:"saving_percent"`
+	OperatingMode    string  `json:"operating_mode"    yaml:"operating_mode"`
+	CompressionState string  `json:"compression_state" yaml:"compression_state"`
+	IndexState       string  `json:"index_state"       yaml:"index_state"`
+}
+
+// ServiceLVMThinPoolState represents the usage of a thin pool.
+type ServiceLVMThinPoolState struct {
+	VGName          string   `json:"vg_name"          yaml:"vg_name"`
+	Name            string   `json:"name"             yaml:"name"`
+	Active          bool     `json:"active"           yaml:"active"` // Usage is only known while the pool is active on the system.
+	Size            string   `json:"size"             yaml:"size"`
+	DataPercent     float64  `json:"data_percent"     yaml:"data_percent"`
+	MetadataPercent float64  `json:"metadata_percent" yaml:"metadata_percent"`
+	Alarms          []string `json:"alarms"           yaml:"alarms"`
 }
 
 // ServiceLVMPV defines information about a given physical volume.
@@ -0,0 +1,22 @@
+package api
+
+// ServiceMetricsConfig represents additional configuration for the metrics service.
+type ServiceMetricsConfig struct {
+	Enabled                bool     `json:"enabled"                  yaml:"enabled"`
+	ListenAddress          string   `json:"listen_address"           yaml:"listen_address"` // Defaults to ":9100".
+	TLSServerCertificate   string   `json:"tls_server_certificate"   yaml:"tls_server_certificate"`
+	TLSServerKey           string   `json:"tls_server_key"           yaml:"tls_server_key"`           // Can reference a secret.
+	TLSTrustedCertificates []string `json:"tls_trusted_certificates" yaml:"tls_trusted_certificates"` // CA certificates the scrapers' client certificates must be signed by.
+}
+
+// ServiceMetricsState represents state for the metrics service.
+type ServiceMetricsState struct {
+	TLSServerCertificateExpiry string `json:"tls_server_certificate_expiry" yaml:"tls_server_certificate_expiry"`
+}
+
+// ServiceMetrics represents the state and configuration of the metrics service.
+type ServiceMetrics struct {
+	State ServiceMetricsState `incusos:"-" json:"state" yaml:"state"`
+
+	Config ServiceMetricsConfig `json:"config" yaml:"config"`
+}
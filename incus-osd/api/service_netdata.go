@@ -0,0 +1,21 @@
+package api
+
+// ServiceNetdataConfig represents additional configuration for the Netdata service.
+type ServiceNetdataConfig struct {
+	Enabled          bool     `json:"enabled"            yaml:"enabled"`
+	Destinations     []string `json:"destinations"       yaml:"destinations"` // Netdata parents, as "host[:port]", tried in order.
+	APIKey           string   `json:"api_key"            yaml:"api_key"`      // Streaming API key (UUID) accepted by the parents, can reference a secret.
+	UpdateEvery      int      `json:"update_every"       yaml:"update_every"` // Collection interval in seconds, defaults to 1.
+	TLS              bool     `json:"tls"                yaml:"tls"`
+	TLSCACertificate string   `json:"tls_ca_certificate" yaml:"tls_ca_certificate"` // Replaces the system trust store to verify the parents.
+}
+
+// ServiceNetdataState represents state for the Netdata service.
+type ServiceNetdataState struct{}
+
+// ServiceNetdata represents the state and configuration of the Netdata service.
+type ServiceNetdata struct {
+	State ServiceNetdataState `incusos:"-" json:"state" yaml:"state"`
+
+	Config ServiceNetdataConfig `json:"config" yaml:"config"`
+}
@@ -0,0 +1,36 @@
+package api
+
+// ServiceNFSMount represents a single NFS mount.
+type ServiceNFSMount struct {
+	Name    string   `json:"name"              yaml:"name"` // Mounted on /var/mnt/nfs/<name>.
+	Server  string   `json:"server"            yaml:"server"`
+	Export  string   `json:"export"            yaml:"export"`
+	Version string   `json:"version,omitempty" yaml:"version,omitempty"` // One of "3", "4", "4.1" or "4.2", negotiated with the server if empty.
+	Options []string `json:"options,omitempty" yaml:"options,omitempty"` // Additional mount options, like "ro" or "nconnect=4".
+}
+
+// ServiceNFSConfig represents additional configuration for the NFS service.
+type ServiceNFSConfig struct {
+	Enabled bool              `json:"enabled" yaml:"enabled"`
+	Mounts  []ServiceNFSMount `json:"mounts"  yaml:"mounts"`
+}
+
+// ServiceNFSState represents state for the NFS service.
+type ServiceNFSState struct {
+	Mounts []ServiceNFSMountState `json:"mounts" yaml:"mounts"`
+}
+
+// ServiceNFSMountState represents the health of an NFS mount.
+type ServiceNFSMountState struct {
+	Name   string `json:"name"            yaml:"name"`
+	Path   string `json:"path"            yaml:"path"`
+	Status string `json:"status"          yaml:"status"` // One of "mounted", "unresponsive" or "unmounted".
+	Error  string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ServiceNFS represents the state and configuration of the NFS service.
+type ServiceNFS struct {
+	State ServiceNFSState `incusos:"-" json:"state" yaml:"state"`
+
+	Config ServiceNFSConfig `json:"config" yaml:"config"`
+}
@@ -1,16 +1,33 @@
 package api
 
+const (
+	// ServiceNVMETransportTCP represents NVMe over TCP.
+	ServiceNVMETransportTCP = "tcp"
+
+	// ServiceNVMETransportRDMA represents NVMe over RDMA, using either RoCE or iWARP.
+	ServiceNVMETransportRDMA = "rdma"
+
+	// ServiceNVMETransportFC represents NVMe over fibre channel.
+	ServiceNVMETransportFC = "fc"
+)
+
+// ServiceNVMEIOPolicies lists the supported native multipath I/O policies.
+var ServiceNVMEIOPolicies = []string{"numa", "round-robin", "queue-depth"}
+
 // ServiceNVMETarget represents a single NVME target.
 type ServiceNVMETarget struct {
-	Transport string `json:"transport" yaml:"transport"`
-	Address   string `json:"address"   yaml:"address"`
-	Port      int    `json:"port"      yaml:"port"`
+	Transport string `json:"transport"           yaml:"transport"` // One of "tcp", "rdma" or "fc".
+	Address   string `json:"address"             yaml:"address"`
+	Port      int    `json:"port"                yaml:"port"`
+	Interface string `json:"interface,omitempty" yaml:"interface,omitempty"` // Local network interface to connect through, for the "tcp" and "rdma" transports.
 }
 
 // ServiceNVMEConfig represents additional configuration for the NVME service.
 type ServiceNVMEConfig struct {
-	Enabled bool                `json:"enabled" yaml:"enabled"`
-	Targets []ServiceNVMETarget `json:"targets" yaml:"targets"`
+	Enabled               bool                `json:"enabled"                 yaml:"enabled"`
+	Targets               []ServiceNVMETarget `json:"targets"                 yaml:"targets"`
+	IOPolicy              string              `json:"io_policy"               yaml:"io_policy"`               // Native multipath I/O policy, one of ServiceNVMEIOPolicies, defaults to "numa".
+	ControllerLossTimeout int                 `json:"controller_loss_timeout" yaml:"controller_loss_timeout"` // Seconds to keep reconnecting a lost controller before failing its paths, -1 to never give up, defaults to 600.
 }
 
 // ServiceNVME represents the state and configuration of the NVME service.
@@ -22,6 +39,37 @@ type ServiceNVME struct {
 
 // ServiceNVMEState represents the state for the NVME service.
 type ServiceNVMEState struct {
-	HostID  string `json:"host_id"  yaml:"host_id"`
-	HostNQN string `json:"host_nqn" yaml:"host_nqn"`
+	HostID      string                  `json:"host_id"     yaml:"host_id"`
+	HostNQN     string                  `json:"host_nqn"    yaml:"host_nqn"`
+	Connections []ServiceNVMEConnection `json:"connections" yaml:"connections"`
+	Multipath   bool                    `json:"multipath"   yaml:"multipath"` // Whether native NVME multipath is enabled.
+	Subsystems  []ServiceNVMESubsystem  `json:"subsystems"  yaml:"subsystems"`
+}
+
+// ServiceNVMESubsystem represents a remote NVME subsystem, reachable through one or more controllers.
+type ServiceNVMESubsystem struct {
+	Name     string            `json:"name"      yaml:"name"`
+	NQN      string            `json:"nqn"       yaml:"nqn"`
+	IOPolicy string            `json:"io_policy" yaml:"io_policy"`
+	Paths    []ServiceNVMEPath `json:"paths"     yaml:"paths"`
+}
+
+// ServiceNVMEPath represents a path to a namespace through a controller.
+type ServiceNVMEPath struct {
+	Name       string `json:"name"       yaml:"name"`
+	Namespace  string `json:"namespace"  yaml:"namespace"`  // Multipath block device, like "nvme0n1".
+	Controller string `json:"controller" yaml:"controller"` // Controller the path goes through, as listed in the connections.
+	ANAState   string `json:"ana_state"  yaml:"ana_state"`  // Asymmetric Namespace Access state, like "optimized", "non-optimized" or "inaccessible".
+}
+
+// ServiceNVMEConnection represents a connection to an NVME controller.
+type ServiceNVMEConnection struct {
+	Name          string `json:"name"                     yaml:"name"`
+	Transport     string `json:"transport"                yaml:"transport"`
+	Address       string `json:"address"                  yaml:"address"`
+	Port          int    `json:"port"                     yaml:"port"`
+	HostAddress   string `json:"host_address,omitempty"   yaml:"host_address,omitempty"` // Local address the connection originates from.
+	HostInterface string `json:"host_interface,omitempty" yaml:"host_interface,omitempty"`
+	SubsystemNQN  string `json:"subsystem_nqn"            yaml:"subsystem_nqn"`
+	State         string `json:"state"                    yaml:"state"` // Controller state, like "live", "connecting" or "deleting".
 }
@@ -2,18 +2,40 @@ package api
 
 // ServiceOVNConfig represents additional configuration for the OVN service.
 type ServiceOVNConfig struct {
-	Enabled              bool   `json:"enabled"                yaml:"enabled"`
-	ICChassis            bool   `json:"ic_chassis"             yaml:"ic_chassis"`
-	Database             string `json:"database"               yaml:"database"`
-	TLSClientCertificate string `json:"tls_client_certificate" yaml:"tls_client_certificate"`
-	TLSClientKey         string `json:"tls_client_key"         yaml:"tls_client_key"`
-	TLSCACertificate     string `json:"tls_ca_certificate"     yaml:"tls_ca_certificate"`
-	TunnelAddress        string `json:"tunnel_address"         yaml:"tunnel_address"`
-	TunnelProtocol       string `json:"tunnel_protocol"        yaml:"tunnel_protocol"`
+	Enabled              bool                    `json:"enabled"                yaml:"enabled"`
+	ICChassis            bool                    `json:"ic_chassis"             yaml:"ic_chassis"`
+	Database             string                  `json:"database"               yaml:"database"`
+	TLSClientCertificate string                  `json:"tls_client_certificate" yaml:"tls_client_certificate"`
+	TLSClientKey         string                  `json:"tls_client_key"         yaml:"tls_client_key"`
+	TLSCACertificate     string                  `json:"tls_ca_certificate"     yaml:"tls_ca_certificate"`
+	TunnelAddress        string                  `json:"tunnel_address"         yaml:"tunnel_address"`
+	TunnelProtocol       string                  `json:"tunnel_protocol"        yaml:"tunnel_protocol"`
+	TLSPKI               *ServiceOVNPKI          `json:"tls_pki,omitempty"      yaml:"tls_pki,omitempty"`      // Issues the client certificate from the Vault PKI secrets engine instead.
+	Interconnect         *ServiceOVNInterconnect `json:"interconnect,omitempty" yaml:"interconnect,omitempty"` // Runs the OVN-IC daemon, joining the local availability zone to the interconnection.
+}
+
+// ServiceOVNInterconnect represents the OVN interconnection (OVN-IC) configuration of an availability zone.
+type ServiceOVNInterconnect struct {
+	AvailabilityZone     string   `json:"availability_zone"      yaml:"availability_zone"`   // Name of the local availability zone.
+	NorthboundDatabase   string   `json:"northbound_database"    yaml:"northbound_database"` // Local OVN northbound database.
+	ICNorthboundDatabase string   `json:"ic_northbound_database" yaml:"ic_northbound_database"`
+	ICSouthboundDatabase string   `json:"ic_southbound_database" yaml:"ic_southbound_database"`
+	TransitSwitches      []string `json:"transit_switches"       yaml:"transit_switches"` // Created in the interconnection if missing.
+}
+
+// ServiceOVNPKI represents the Vault PKI secrets engine role issuing the OVN client certificate.
+type ServiceOVNPKI struct {
+	Mount      string `json:"mount"       yaml:"mount"` // Mount path of the PKI secrets engine, defaults to "pki".
+	Role       string `json:"role"        yaml:"role"`
+	CommonName string `json:"common_name" yaml:"common_name"` // Defaults to the hostname.
+	TTL        string `json:"ttl"         yaml:"ttl"`         // Validity of the certificate, like "720h", defaults to the role's.
 }
 
 // ServiceOVNState represents state for the OVN service.
-type ServiceOVNState struct{}
+type ServiceOVNState struct {
+	TLSClientCertificateExpiry string `json:"tls_client_certificate_expiry" yaml:"tls_client_certificate_expiry"`
+	TLSCACertificateExpiry     string `json:"tls_ca_certificate_expiry"     yaml:"tls_ca_certificate_expiry"`
+}
 
 // ServiceOVN represents the state and configuration of the OVN service.
 type ServiceOVN struct {
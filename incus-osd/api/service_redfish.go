@@ -0,0 +1,72 @@
+package api
+
+// ServiceRedfishEndpoint represents the Redfish endpoint of a BMC, either local or of a peer system.
+type ServiceRedfishEndpoint struct {
+	Name             string `json:"name"                         yaml:"name"`
+	Address          string `json:"address"                      yaml:"address"` // Base URL of the BMC, like "https://10.0.0.10".
+	Username         string `json:"username"                     yaml:"username"`
+	Password         string `json:"password"                     yaml:"password"`                     // Can reference a secret.
+	TLSCACertificate string `json:"tls_ca_certificate,omitempty" yaml:"tls_ca_certificate,omitempty"` // Replaces the system trust store.
+	TLSFingerprint   string `json:"tls_fingerprint,omitempty"    yaml:"tls_fingerprint,omitempty"`    // SHA256 fingerprint of the BMC certificate, pinning it instead of verifying its chain.
+}
+
+// ServiceRedfishConfig represents additional configuration for the Redfish service.
+type ServiceRedfishConfig struct {
+	Enabled   bool                     `json:"enabled"   yaml:"enabled"`
+	Endpoints []ServiceRedfishEndpoint `json:"endpoints" yaml:"endpoints"`
+}
+
+// ServiceRedfishState represents state for the Redfish service.
+type ServiceRedfishState struct {
+	Endpoints []ServiceRedfishEndpointState `json:"endpoints" yaml:"endpoints"`
+}
+
+// ServiceRedfishEndpointState represents the system managed by a Redfish endpoint.
+type ServiceRedfishEndpointState struct {
+	Name         string                   `json:"name"            yaml:"name"`
+	Manufacturer string                   `json:"manufacturer"    yaml:"manufacturer"`
+	Model        string                   `json:"model"           yaml:"model"`
+	SerialNumber string                   `json:"serial_number"   yaml:"serial_number"`
+	PowerState   string                   `json:"power_state"     yaml:"power_state"` // Like "On" or "Off".
+	Firmware     []ServiceRedfishFirmware `json:"firmware"        yaml:"firmware"`
+	VirtualMedia []ServiceRedfishMedia    `json:"virtual_media"   yaml:"virtual_media"`
+	Error        string                   `json:"error,omitempty" yaml:"error,omitempty"` // Set if the endpoint couldn't be queried.
+}
+
+// ServiceRedfishFirmware represents a firmware component reported by a Redfish endpoint.
+type ServiceRedfishFirmware struct {
+	Name    string `json:"name"    yaml:"name"`
+	Version string `json:"version" yaml:"version"`
+}
+
+// ServiceRedfishMedia represents a virtual media device of a Redfish endpoint.
+type ServiceRedfishMedia struct {
+	Name     string `json:"name"     yaml:"name"`
+	Inserted bool   `json:"inserted" yaml:"inserted"`
+	Image    string `json:"image"    yaml:"image"`
+}
+
+// ServiceRedfishPower represents a power action to trigger through a Redfish endpoint.
+type ServiceRedfishPower struct {
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+	Action   string `json:"action"   yaml:"action"` // A Redfish reset type, like "On", "GracefulShutdown", "GracefulRestart", "ForceOff", "ForceRestart" or "PowerCycle".
+}
+
+// ServiceRedfishMediaInsert represents a request to insert a virtual media image through a Redfish endpoint.
+type ServiceRedfishMediaInsert struct {
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+	Image    string `json:"image"    yaml:"image"` // URL of the image, like an IncusOS install ISO.
+	Boot     bool   `json:"boot"     yaml:"boot"`  // Boot from the image once, on the next boot.
+}
+
+// ServiceRedfishMediaEject represents a request to eject the virtual media images through a Redfish endpoint.
+type ServiceRedfishMediaEject struct {
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+}
+
+// ServiceRedfish represents the state and configuration of the Redfish service.
+type ServiceRedfish struct {
+	State ServiceRedfishState `incusos:"-" json:"state" yaml:"state"`
+
+	Config ServiceRedfishConfig `json:"config" yaml:"config"`
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"time"
+)
+
+// ServiceSMARTDConfig represents additional configuration for the smartd service.
+type ServiceSMARTDConfig struct {
+	Enabled             bool     `json:"enabled"              yaml:"enabled"`
+	Devices             []string `json:"devices"              yaml:"devices"`              // Drives to monitor, defaults to all of them.
+	SelfTestSchedule    string   `json:"self_test_schedule"   yaml:"self_test_schedule"`   // smartd self-test schedule, like "(S/../.././02|L/../../6/03)".
+	TemperatureWarning  int      `json:"temperature_warning"  yaml:"temperature_warning"`  // Temperature in Celsius triggering an informational alert.
+	TemperatureCritical int      `json:"temperature_critical" yaml:"temperature_critical"` // Temperature in Celsius triggering a critical alert.
+	WebhookURL          string   `json:"webhook_url"          yaml:"webhook_url"`          // URL each alert is posted to as JSON.
+}
+
+// ServiceSMARTDState represents state for the smartd service.
+type ServiceSMARTDState struct {
+	Alerts []ServiceSMARTDAlert `json:"alerts" yaml:"alerts"`
+}
+
+// ServiceSMARTDAlert represents an alert raised by smartd.
+type ServiceSMARTDAlert struct {
+	Time    time.Time `json:"time"    yaml:"time"`
+	Device  string    `json:"device"  yaml:"device"`
+	Type    string    `json:"type"    yaml:"type"` // Like "Health", "Usage", "SelfTest" or "Temperature".
+	Message string    `json:"message" yaml:"message"`
+}
+
+// ServiceSMARTD represents the state and configuration of the smartd service.
+type ServiceSMARTD struct {
+	State ServiceSMARTDState `incusos:"-" json:"state" yaml:"state"`
+
+	Config ServiceSMARTDConfig `json:"config" yaml:"config"`
+}
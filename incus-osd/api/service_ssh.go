@@ -0,0 +1,22 @@
+package api
+
+// ServiceSSHConfig represents the SSH service configuration.
+type ServiceSSHConfig struct {
+	Enabled        bool     `json:"enabled"         yaml:"enabled"`
+	Port           int      `json:"port"            yaml:"port"`            // Defaults to 22.
+	AuthorizedKeys []string `json:"authorized_keys" yaml:"authorized_keys"` // Public keys allowed to log in as root.
+	AllowedSources []string `json:"allowed_sources" yaml:"allowed_sources"` // Addresses or subnets allowed to connect, all if empty.
+	TPMHostKeys    bool     `json:"tpm_host_keys"   yaml:"tpm_host_keys"`   // Seal the host key with the TPM.
+}
+
+// ServiceSSHState represents state for the SSH service.
+type ServiceSSHState struct {
+	HostKeyFingerprint string `json:"host_key_fingerprint" yaml:"host_key_fingerprint"`
+}
+
+// ServiceSSH represents the state and configuration of the SSH service.
+type ServiceSSH struct {
+	State ServiceSSHState `incusos:"-" json:"state" yaml:"state"`
+
+	Config ServiceSSHConfig `json:"config" yaml:"config"`
+}
@@ -1,16 +1,21 @@
 package api
 
+// ServiceTailscaleConfig represents additional configuration for the Tailscale service.
+type ServiceTailscaleConfig struct {
+	Enabled          bool     `json:"enabled"           yaml:"enabled"`
+	LoginServer      string   `json:"login_server"      yaml:"login_server"`
+	AuthKey          string   `json:"auth_key"          yaml:"auth_key"`
+	AcceptRoutes     bool     `json:"accept_routes"     yaml:"accept_routes"`
+	AdvertisedRoutes []string `json:"advertised_routes" yaml:"advertised_routes"`
+	ServeEnabled     bool     `json:"serve_enabled"     yaml:"serve_enabled"`
+	ServePort        int16    `json:"serve_port"        yaml:"serve_port"`
+	RestrictAPI      bool     `json:"restrict_api"      yaml:"restrict_api"`
+	APIPort          int      `json:"api_port"          yaml:"api_port"` // Port of the primary application's API, defaults to 8443.
+}
+
 // ServiceTailscale represents the state and configuration of the Tailscale service.
 type ServiceTailscale struct {
 	State struct{} `json:"state" yaml:"state"`
 
-	Config struct {
-		Enabled          bool     `json:"enabled"           yaml:"enabled"`
-		LoginServer      string   `json:"login_server"      yaml:"login_server"`
-		AuthKey          string   `json:"auth_key"          yaml:"auth_key"`
-		AcceptRoutes     bool     `json:"accept_routes"     yaml:"accept_routes"`
-		AdvertisedRoutes []string `json:"advertised_routes" yaml:"advertised_routes"`
-		ServeEnabled     bool     `json:"serve_enabled"     yaml:"serve_enabled"`
-		ServePort        int16    `json:"serve_port"        yaml:"serve_port"`
-	} `json:"config" yaml:"config"`
+	Config ServiceTailscaleConfig `json:"config" yaml:"config"`
 }
@@ -6,13 +6,35 @@ type ServiceUSBIPTarget struct {
 	BusID   string `json:"bus_id"  yaml:"bus_id"`
 }
 
+// ServiceUSBIPExport represents local USB devices allowed to be exported. A device is exported if it
+// matches all the provided fields.
+type ServiceUSBIPExport struct {
+	Device string `json:"device,omitempty" yaml:"device,omitempty"` // As "vendor:product" hexadecimal IDs.
+	Serial string `json:"serial,omitempty" yaml:"serial,omitempty"`
+	BusID  string `json:"bus_id,omitempty" yaml:"bus_id,omitempty"` // Physical port, like "1-2".
+}
+
 // ServiceUSBIPConfig represents additional configuration for the USBIP service.
 type ServiceUSBIPConfig struct {
-	Targets []ServiceUSBIPTarget `json:"targets" yaml:"targets"`
+	Targets []ServiceUSBIPTarget `json:"targets"           yaml:"targets"`
+	Exports []ServiceUSBIPExport `json:"exports,omitempty" yaml:"exports,omitempty"` // Allowlist of the local devices to export.
 }
 
 // ServiceUSBIPState represents state for the USBIP service.
-type ServiceUSBIPState struct{}
+type ServiceUSBIPState struct {
+	Exports []ServiceUSBIPExportState `json:"exports" yaml:"exports"`
+}
+
+// ServiceUSBIPExportState represents the export status of a local USB device.
+type ServiceUSBIPExportState struct {
+	BusID     string `json:"bus_id"          yaml:"bus_id"`
+	VendorID  string `json:"vendor_id"       yaml:"vendor_id"`
+	ProductID string `json:"product_id"      yaml:"product_id"`
+	Product   string `json:"product"         yaml:"product"`
+	Serial    string `json:"serial"          yaml:"serial"`
+	Status    string `json:"status"          yaml:"status"` // One of "available", "in-use" or "error".
+	Error     string `json:"error,omitempty" yaml:"error,omitempty"`
+}
 
 // ServiceUSBIP represents the state and configuration of the USBIP service.
 type ServiceUSBIP struct {
@@ -0,0 +1,39 @@
+package api
+
+// ServiceVRRPInstance represents a VRRP instance, sharing floating addresses with other systems.
+type ServiceVRRPInstance struct {
+	Name            string   `json:"name"                       yaml:"name"`
+	Interface       string   `json:"interface"                  yaml:"interface"`
+	VRID            int      `json:"vrid"                       yaml:"vrid"`                       // Virtual router ID, from 1 to 255, shared by all the systems of the instance.
+	Priority        int      `json:"priority"                   yaml:"priority"`                   // From 1 to 254, the system with the highest priority holding the addresses.
+	Addresses       []string `json:"addresses"                  yaml:"addresses"`                  // Floating addresses, in CIDR notation.
+	AdvertInterval  int      `json:"advert_interval,omitempty"  yaml:"advert_interval,omitempty"`  // Interval between advertisements in seconds, defaults to 1.
+	NoPreempt       bool     `json:"no_preempt,omitempty"       yaml:"no_preempt,omitempty"`       // Don't take the addresses back from a lower priority system.
+	Password        string   `json:"password,omitempty"         yaml:"password,omitempty"`         // Simple authentication password of up to 8 characters, can reference a secret.
+	TrackInterfaces []string `json:"track_interfaces,omitempty" yaml:"track_interfaces,omitempty"` // Interfaces which must be up to hold the addresses.
+	UnicastPeers    []string `json:"unicast_peers,omitempty"    yaml:"unicast_peers,omitempty"`    // Sends the advertisements to these peers rather than using multicast.
+}
+
+// ServiceVRRPConfig represents additional configuration for the VRRP service.
+type ServiceVRRPConfig struct {
+	Enabled   bool                  `json:"enabled"   yaml:"enabled"`
+	Instances []ServiceVRRPInstance `json:"instances" yaml:"instances"`
+}
+
+// ServiceVRRPState represents state for the VRRP service.
+type ServiceVRRPState struct {
+	Instances []ServiceVRRPInstanceState `json:"instances" yaml:"instances"`
+}
+
+// ServiceVRRPInstanceState represents the current state of a VRRP instance.
+type ServiceVRRPInstanceState struct {
+	Name  string `json:"name"  yaml:"name"`
+	State string `json:"state" yaml:"state"` // One of "MASTER", "BACKUP", "FAULT" or "UNKNOWN".
+}
+
+// ServiceVRRP represents the state and configuration of the VRRP service.
+type ServiceVRRP struct {
+	State ServiceVRRPState `incusos:"-" json:"state" yaml:"state"`
+
+	Config ServiceVRRPConfig `json:"config" yaml:"config"`
+}
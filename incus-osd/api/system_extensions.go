@@ -0,0 +1,21 @@
+package api
+
+// SystemExtension holds information about a side-loaded system extension.
+type SystemExtension struct {
+	Name       string `json:"name"                 yaml:"name"`
+	SHA256     string `json:"sha256"               yaml:"sha256"`
+	Size       int64  `json:"size"                 yaml:"size"`
+	Active     bool   `json:"active"               yaml:"active"`               // Whether the extension is merged into the running system.
+	UploadedAt string `json:"uploaded_at"          yaml:"uploaded_at"`          // RFC3339 timestamp.
+	LastError  string `json:"last_error,omitempty" yaml:"last_error,omitempty"` // Why the extension was last deactivated, for example after failing validation on boot.
+}
+
+// SystemExtensionsState holds information about the side-loaded system extensions.
+type SystemExtensionsState struct {
+	Extensions []SystemExtension `json:"extensions" yaml:"extensions"`
+}
+
+// SystemExtensions defines a struct to hold information about the side-loaded system extensions.
+type SystemExtensions struct {
+	State SystemExtensionsState `json:"state" yaml:"state"`
+}
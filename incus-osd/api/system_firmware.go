@@ -0,0 +1,47 @@
+package api
+
+import (
+	"time"
+)
+
+// SystemFirmwareConfig holds the modifiable part of the firmware update data.
+type SystemFirmwareConfig struct {
+	Enabled   bool `json:"enabled"    yaml:"enabled"`    // Check for firmware updates from the LVFS during each update check.
+	AutoApply bool `json:"auto_apply" yaml:"auto_apply"` // Apply the available firmware updates during the update checks, without waiting for them to be requested.
+}
+
+// SystemFirmwareState holds information about the current firmware update state.
+type SystemFirmwareState struct {
+	LastCheck      time.Time              `json:"last_check"      yaml:"last_check"` // In system's timezone.
+	Status         string                 `json:"status"          yaml:"status"`
+	Devices        []SystemFirmwareDevice `json:"devices"         yaml:"devices"`
+	PendingDevices []string               `json:"pending_devices" yaml:"pending_devices"` // Devices whose update was requested, to be applied during the next update check.
+}
+
+// SystemFirmwareDevice represents a device whose firmware can be updated.
+type SystemFirmwareDevice struct {
+	ID          string                 `json:"id"           yaml:"id"` // Device ID, as reported by fwupd.
+	Name        string                 `json:"name"         yaml:"name"`
+	Vendor      string                 `json:"vendor"       yaml:"vendor"`
+	Version     string                 `json:"version"      yaml:"version"`
+	NeedsReboot bool                   `json:"needs_reboot" yaml:"needs_reboot"` // An update was applied, to be finalized on next reboot.
+	Updates     []SystemFirmwareUpdate `json:"updates"      yaml:"updates"`      // Available updates, newest first.
+}
+
+// SystemFirmwareUpdate represents a firmware update available for a device.
+type SystemFirmwareUpdate struct {
+	Version string `json:"version"           yaml:"version"`
+	Summary string `json:"summary"           yaml:"summary"`
+	Urgency string `json:"urgency,omitempty" yaml:"urgency,omitempty"` // One of "low", "medium", "high" or "critical", if known.
+}
+
+// SystemFirmwareApply defines a struct holding the devices whose firmware update is requested.
+type SystemFirmwareApply struct {
+	Devices []string `json:"devices,omitempty" yaml:"devices,omitempty"` // Defaults to all the devices with an available update.
+}
+
+// SystemFirmware defines a struct to hold information about the firmware updates of the system's devices.
+type SystemFirmware struct {
+	Config SystemFirmwareConfig `json:"config" yaml:"config"`
+	State  SystemFirmwareState  `incusos:"-"   json:"state"  yaml:"state"`
+}
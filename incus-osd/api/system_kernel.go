@@ -0,0 +1,21 @@
+package api
+
+// SystemKernelConfig holds the modifiable part of the kernel data.
+type SystemKernelConfig struct {
+	CommandLine []string `json:"command_line" yaml:"command_line"` // Additional kernel command line parameters, applied on next boot.
+}
+
+// SystemKernelState holds information about the current kernel state.
+type SystemKernelState struct {
+	CommandLine                []string `json:"command_line"                 yaml:"command_line"`                 // Additional kernel command line parameters currently in use.
+	Pending                    []string `json:"pending,omitempty"            yaml:"pending,omitempty"`            // Additional kernel command line parameters in use after the next reboot, if different.
+	Effective                  []string `json:"effective"                    yaml:"effective"`                    // Full kernel command line of the running system.
+	SigningCertificate         string   `json:"signing_certificate"          yaml:"signing_certificate"`          // PEM encoded certificate signing the command line addon.
+	SigningCertificateEnrolled bool     `json:"signing_certificate_enrolled" yaml:"signing_certificate_enrolled"` // Whether the signing certificate is in the Secure Boot db.
+}
+
+// SystemKernel defines a struct to hold information about the system's kernel configuration.
+type SystemKernel struct {
+	Config SystemKernelConfig `json:"config" yaml:"config"`
+	State  SystemKernelState  `incusos:"-"   json:"state"  yaml:"state"`
+}
@@ -7,9 +7,15 @@ type SystemLoggingSyslog struct {
 	LogFormat string `json:"log_format" yaml:"log_format"`
 }
 
+// SystemLoggingAudit contains the configuration options for the audit log.
+type SystemLoggingAudit struct {
+	Syslog SystemLoggingSyslog `json:"syslog" yaml:"syslog"` // Remote syslog server the audit log is streamed to.
+}
+
 // SystemLoggingConfig holds the modifiable part of the logging data.
 type SystemLoggingConfig struct {
 	Syslog SystemLoggingSyslog `json:"syslog" yaml:"syslog"`
+	Audit  SystemLoggingAudit  `json:"audit"  yaml:"audit"`
 }
 
 // SystemLoggingState represents state for the systme's logging configuration.
@@ -20,3 +26,21 @@ type SystemLogging struct {
 	Config SystemLoggingConfig `json:"config" yaml:"config"`
 	State  SystemLoggingState  `incusos:"-"   json:"state"  yaml:"state"`
 }
+
+// SystemLoggingAuditEntry defines a struct that holds a single entry of the audit log.
+type SystemLoggingAuditEntry struct {
+	Timestamp  string                     `json:"timestamp"   yaml:"timestamp"` // RFC3339 timestamp.
+	Identity   string                     `json:"identity"    yaml:"identity"`
+	Method     string                     `json:"method"      yaml:"method"`
+	Path       string                     `json:"path"        yaml:"path"`
+	StatusCode int                        `json:"status_code" yaml:"status_code"`
+	Changes    []SystemLoggingAuditChange `json:"changes"     yaml:"changes"`
+}
+
+// SystemLoggingAuditChange defines a struct that holds a configuration field changed by an API call.
+// Secrets are replaced by "<redacted>".
+type SystemLoggingAuditChange struct {
+	Field    string `json:"field"     yaml:"field"`
+	OldValue any    `json:"old_value" yaml:"old_value"`
+	NewValue any    `json:"new_value" yaml:"new_value"`
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"slices"
+	"time"
 )
 
 const (
@@ -25,48 +26,98 @@ type SystemNetwork struct {
 	State SystemNetworkState `incusos:"-" json:"state" yaml:"state"`
 }
 
+// SystemNetworkTry defines a network configuration to be applied, pending confirmation.
+type SystemNetworkTry struct {
+	Config  *SystemNetworkConfig `json:"config"            yaml:"config"`
+	Timeout int                  `json:"timeout,omitempty" yaml:"timeout,omitempty"` // In seconds
+}
+
 // SystemNetworkConfig represents the user modifiable network configuration.
 type SystemNetworkConfig struct {
-	DNS   *SystemNetworkDNS   `json:"dns,omitempty"   yaml:"dns,omitempty"`
-	Time  *SystemNetworkTime  `json:"time,omitempty"  yaml:"time,omitempty"`
-	Proxy *SystemNetworkProxy `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	DNS        *SystemNetworkDNS        `json:"dns,omitempty"         yaml:"dns,omitempty"`
+	Time       *SystemNetworkTime       `json:"time,omitempty"        yaml:"time,omitempty"`
+	Proxy      *SystemNetworkProxy      `json:"proxy,omitempty"       yaml:"proxy,omitempty"`
+	DHCPServer *SystemNetworkDHCPServer `json:"dhcp_server,omitempty" yaml:"dhcp_server,omitempty"`
+	IPv6       *SystemNetworkIPv6       `json:"ipv6,omitempty"        yaml:"ipv6,omitempty"`
+	Failover   *SystemNetworkFailover   `json:"failover,omitempty"    yaml:"failover,omitempty"`
 
 	Interfaces []SystemNetworkInterface `json:"interfaces,omitempty" yaml:"interfaces,omitempty"`
 	Bonds      []SystemNetworkBond      `json:"bonds,omitempty"      yaml:"bonds,omitempty"`
+	Bridges    []SystemNetworkBridge    `json:"bridges,omitempty"    yaml:"bridges,omitempty"`
+	PPPoE      []SystemNetworkPPPoE     `json:"pppoe,omitempty"      yaml:"pppoe,omitempty"`
 	VLANs      []SystemNetworkVLAN      `json:"vlans,omitempty"      yaml:"vlans,omitempty"`
+	Wifi       []SystemNetworkWifi      `json:"wifi,omitempty"       yaml:"wifi,omitempty"`
 	Wireguard  []SystemNetworkWireguard `json:"wireguard,omitempty"  yaml:"wireguard,omitempty"`
+	Tunnels    []SystemNetworkTunnel    `json:"tunnels,omitempty"    yaml:"tunnels,omitempty"`
 }
 
 // SystemNetworkInterface contains information about a network interface.
 type SystemNetworkInterface struct {
 	Addresses         []string                    `json:"addresses,omitempty"           yaml:"addresses,omitempty"`
+	EAP               *SystemNetworkEAP           `json:"eap,omitempty"                 yaml:"eap,omitempty"`
 	Ethernet          *SystemNetworkEthernet      `json:"ethernet,omitempty"            yaml:"ethernet,omitempty"`
 	FirewallRules     []SystemNetworkFirewallRule `json:"firewall_rules,omitempty"      yaml:"firewall_rules,omitempty"`
 	Hwaddr            string                      `json:"hwaddr"                        yaml:"hwaddr"`
 	LLDP              bool                        `json:"lldp,omitempty"                yaml:"lldp,omitempty"`
 	MTU               int                         `json:"mtu,omitempty"                 yaml:"mtu,omitempty"`
 	Name              string                      `json:"name"                          yaml:"name"`
+	Neighbors         []SystemNetworkNeighbor     `json:"neighbors,omitempty"           yaml:"neighbors,omitempty"`
+	Path              string                      `json:"path,omitempty"                yaml:"path,omitempty"`
+	ProxyARP          bool                        `json:"proxy_arp,omitempty"           yaml:"proxy_arp,omitempty"`
+	ProxyNDP          []string                    `json:"proxy_ndp,omitempty"           yaml:"proxy_ndp,omitempty"`
+	QoS               *SystemNetworkQoS           `json:"qos,omitempty"                 yaml:"qos,omitempty"`
 	RequiredForOnline string                      `json:"required_for_online,omitempty" yaml:"required_for_online,omitempty"`
 	Roles             []string                    `json:"roles,omitempty"               yaml:"roles,omitempty"`
 	Routes            []SystemNetworkRoute        `json:"routes,omitempty"              yaml:"routes,omitempty"`
+	RoutingRules      []SystemNetworkRoutingRule  `json:"routing_rules,omitempty"       yaml:"routing_rules,omitempty"`
+	SRIOV             *SystemNetworkSRIOV         `json:"sriov,omitempty"               yaml:"sriov,omitempty"`
 	StrictHwaddr      bool                        `json:"strict_hwaddr,omitempty"       yaml:"strict_hwaddr,omitempty"`
 	VLANTags          []int                       `json:"vlan_tags,omitempty"           yaml:"vlan_tags,omitempty"`
 }
 
 // SystemNetworkBond contains information about a network bond.
 type SystemNetworkBond struct {
+	Addresses          []string                    `json:"addresses,omitempty"            yaml:"addresses,omitempty"`
+	Ethernet           *SystemNetworkEthernet      `json:"ethernet,omitempty"             yaml:"ethernet,omitempty"`
+	FirewallRules      []SystemNetworkFirewallRule `json:"firewall_rules,omitempty"       yaml:"firewall_rules,omitempty"`
+	Hwaddr             string                      `json:"hwaddr,omitempty"               yaml:"hwaddr,omitempty"`
+	LACPRate           string                      `json:"lacp_rate,omitempty"            yaml:"lacp_rate,omitempty"`
+	LLDP               bool                        `json:"lldp,omitempty"                 yaml:"lldp,omitempty"`
+	Members            []string                    `json:"members,omitempty"              yaml:"members,omitempty"`
+	MIIMonitorInterval int                         `json:"mii_monitor_interval,omitempty" yaml:"mii_monitor_interval,omitempty"`
+	Mode               string                      `json:"mode"                           yaml:"mode"`
+	MTU                int                         `json:"mtu,omitempty"                  yaml:"mtu,omitempty"`
+	Name               string                      `json:"name"                           yaml:"name"`
+	Neighbors          []SystemNetworkNeighbor     `json:"neighbors,omitempty"            yaml:"neighbors,omitempty"`
+	ProxyARP           bool                        `json:"proxy_arp,omitempty"            yaml:"proxy_arp,omitempty"`
+	ProxyNDP           []string                    `json:"proxy_ndp,omitempty"            yaml:"proxy_ndp,omitempty"`
+	QoS                *SystemNetworkQoS           `json:"qos,omitempty"                  yaml:"qos,omitempty"`
+	RequiredForOnline  string                      `json:"required_for_online,omitempty"  yaml:"required_for_online,omitempty"`
+	Roles              []string                    `json:"roles,omitempty"                yaml:"roles,omitempty"`
+	Routes             []SystemNetworkRoute        `json:"routes,omitempty"               yaml:"routes,omitempty"`
+	RoutingRules       []SystemNetworkRoutingRule  `json:"routing_rules,omitempty"        yaml:"routing_rules,omitempty"`
+	TransmitHashPolicy string                      `json:"transmit_hash_policy,omitempty" yaml:"transmit_hash_policy,omitempty"`
+	VLANTags           []int                       `json:"vlan_tags,omitempty"            yaml:"vlan_tags,omitempty"`
+}
+
+// SystemNetworkBridge contains information about a managed network bridge.
+type SystemNetworkBridge struct {
 	Addresses         []string                    `json:"addresses,omitempty"           yaml:"addresses,omitempty"`
 	Ethernet          *SystemNetworkEthernet      `json:"ethernet,omitempty"            yaml:"ethernet,omitempty"`
 	FirewallRules     []SystemNetworkFirewallRule `json:"firewall_rules,omitempty"      yaml:"firewall_rules,omitempty"`
 	Hwaddr            string                      `json:"hwaddr,omitempty"              yaml:"hwaddr,omitempty"`
-	LLDP              bool                        `json:"lldp,omitempty"                yaml:"lldp,omitempty"`
 	Members           []string                    `json:"members,omitempty"             yaml:"members,omitempty"`
-	Mode              string                      `json:"mode"                          yaml:"mode"`
 	MTU               int                         `json:"mtu,omitempty"                 yaml:"mtu,omitempty"`
 	Name              string                      `json:"name"                          yaml:"name"`
+	Neighbors         []SystemNetworkNeighbor     `json:"neighbors,omitempty"           yaml:"neighbors,omitempty"`
+	ProxyARP          bool                        `json:"proxy_arp,omitempty"           yaml:"proxy_arp,omitempty"`
+	ProxyNDP          []string                    `json:"proxy_ndp,omitempty"           yaml:"proxy_ndp,omitempty"`
 	RequiredForOnline string                      `json:"required_for_online,omitempty" yaml:"required_for_online,omitempty"`
 	Roles             []string                    `json:"roles,omitempty"               yaml:"roles,omitempty"`
 	Routes            []SystemNetworkRoute        `json:"routes,omitempty"              yaml:"routes,omitempty"`
+	RoutingRules      []SystemNetworkRoutingRule  `json:"routing_rules,omitempty"       yaml:"routing_rules,omitempty"`
+	STP               bool                        `json:"stp,omitempty"                 yaml:"stp,omitempty"`
+	VLANFiltering     bool                        `json:"vlan_filtering,omitempty"      yaml:"vlan_filtering,omitempty"`
 	VLANTags          []int                       `json:"vlan_tags,omitempty"           yaml:"vlan_tags,omitempty"`
 }
 
@@ -77,10 +128,28 @@ type SystemNetworkVLAN struct {
 	ID                int                         `json:"id"                            yaml:"id"`
 	MTU               int                         `json:"mtu,omitempty"                 yaml:"mtu,omitempty"`
 	Name              string                      `json:"name"                          yaml:"name"`
+	Neighbors         []SystemNetworkNeighbor     `json:"neighbors,omitempty"           yaml:"neighbors,omitempty"`
 	Parent            string                      `json:"parent"                        yaml:"parent"`
+	ProxyARP          bool                        `json:"proxy_arp,omitempty"           yaml:"proxy_arp,omitempty"`
+	ProxyNDP          []string                    `json:"proxy_ndp,omitempty"           yaml:"proxy_ndp,omitempty"`
 	RequiredForOnline string                      `json:"required_for_online,omitempty" yaml:"required_for_online,omitempty"`
 	Roles             []string                    `json:"roles,omitempty"               yaml:"roles,omitempty"`
 	Routes            []SystemNetworkRoute        `json:"routes,omitempty"              yaml:"routes,omitempty"`
+	RoutingRules      []SystemNetworkRoutingRule  `json:"routing_rules,omitempty"       yaml:"routing_rules,omitempty"`
+}
+
+// SystemNetworkPPPoE contains information about a PPPoE session, established over an existing device.
+type SystemNetworkPPPoE struct {
+	DefaultRoute  bool                        `json:"default_route,omitempty"  yaml:"default_route,omitempty"`
+	FirewallRules []SystemNetworkFirewallRule `json:"firewall_rules,omitempty" yaml:"firewall_rules,omitempty"`
+	IPv6          bool                        `json:"ipv6,omitempty"           yaml:"ipv6,omitempty"`
+	MTU           int                         `json:"mtu,omitempty"            yaml:"mtu,omitempty"`
+	Name          string                      `json:"name"                     yaml:"name"`
+	Parent        string                      `json:"parent"                   yaml:"parent"`
+	Password      string                      `json:"password"                 yaml:"password"`
+	Roles         []string                    `json:"roles,omitempty"          yaml:"roles,omitempty"`
+	ServiceName   string                      `json:"service_name,omitempty"   yaml:"service_name,omitempty"`
+	Username      string                      `json:"username"                 yaml:"username"`
 }
 
 // SystemNetworkEthernet contains Ethernet-specific configuration details (offloading and other features).
@@ -90,11 +159,40 @@ type SystemNetworkEthernet struct {
 	DisableGSO             bool     `json:"disable_gso,omitempty"              yaml:"disable_gso,omitempty"`
 	DisableIPv4TSO         bool     `json:"disable_ipv4_tso,omitempty"         yaml:"disable_ipv4_tso,omitempty"`
 	DisableIPv6TSO         bool     `json:"disable_ipv6_tso,omitempty"         yaml:"disable_ipv6_tso,omitempty"`
+	DisableLRO             bool     `json:"disable_lro,omitempty"              yaml:"disable_lro,omitempty"`
 	WakeOnLAN              bool     `json:"wakeonlan,omitempty"                yaml:"wakeonlan,omitempty"`
 	WakeOnLANModes         []string `json:"wakeonlan_modes,omitempty"          yaml:"wakeonlan_modes,omitempty"`
 	WakeOnLANPassword      string   `json:"wakeonlan_password,omitempty"       yaml:"wakeonlan_password,omitempty"`
 }
 
+// SystemNetworkNeighbor defines a static neighbor (ARP or NDP) entry.
+type SystemNetworkNeighbor struct {
+	Address string `json:"address" yaml:"address"`
+	Hwaddr  string `json:"hwaddr"  yaml:"hwaddr"`
+}
+
+// SystemNetworkQoS defines egress traffic shaping and prioritization for an interface or bond.
+type SystemNetworkQoS struct {
+	EgressRate      int    `json:"egress_rate,omitempty"      yaml:"egress_rate,omitempty"`      // In Mbit/s
+	HostEgressRate  int    `json:"host_egress_rate,omitempty" yaml:"host_egress_rate,omitempty"` // In Mbit/s
+	PriorityQueuing string `json:"priority_queuing,omitempty" yaml:"priority_queuing,omitempty"`
+}
+
+// SystemNetworkSRIOV defines the SR-IOV virtual functions created on an interface.
+type SystemNetworkSRIOV struct {
+	VFs              int                    `json:"vfs"                         yaml:"vfs"`
+	VirtualFunctions []SystemNetworkSRIOVVF `json:"virtual_functions,omitempty" yaml:"virtual_functions,omitempty"`
+}
+
+// SystemNetworkSRIOVVF defines the settings of a single SR-IOV virtual function.
+type SystemNetworkSRIOVVF struct {
+	DisableSpoofCheck bool   `json:"disable_spoof_check,omitempty" yaml:"disable_spoof_check,omitempty"`
+	Hwaddr            string `json:"hwaddr,omitempty"              yaml:"hwaddr,omitempty"`
+	Index             int    `json:"index"                         yaml:"index"`
+	Trust             bool   `json:"trust,omitempty"               yaml:"trust,omitempty"`
+	VLAN              int    `json:"vlan,omitempty"                yaml:"vlan,omitempty"`
+}
+
 // SystemNetworkFirewallRule defines a firewall rule.
 type SystemNetworkFirewallRule struct {
 	Action   string `json:"action"             yaml:"action"`
@@ -115,6 +213,56 @@ type SystemNetworkWireguard struct {
 	RequiredForOnline string                       `json:"required_for_online,omitempty" yaml:"required_for_online,omitempty"`
 	Roles             []string                     `json:"roles,omitempty"               yaml:"roles,omitempty"`
 	Routes            []SystemNetworkRoute         `json:"routes,omitempty"              yaml:"routes,omitempty"`
+	RoutingRules      []SystemNetworkRoutingRule   `json:"routing_rules,omitempty"       yaml:"routing_rules,omitempty"`
+}
+
+// SystemNetworkTunnel contains information about a GRE or VXLAN tunnel.
+type SystemNetworkTunnel struct {
+	Addresses         []string                    `json:"addresses,omitempty"           yaml:"addresses,omitempty"`
+	FirewallRules     []SystemNetworkFirewallRule `json:"firewall_rules,omitempty"      yaml:"firewall_rules,omitempty"`
+	Key               int                         `json:"key,omitempty"                 yaml:"key,omitempty"`
+	Kind              string                      `json:"kind"                          yaml:"kind"`
+	Local             string                      `json:"local,omitempty"               yaml:"local,omitempty"`
+	MTU               int                         `json:"mtu,omitempty"                 yaml:"mtu,omitempty"`
+	Name              string                      `json:"name"                          yaml:"name"`
+	Port              int                         `json:"port,omitempty"                yaml:"port,omitempty"`
+	Remote            string                      `json:"remote"                        yaml:"remote"`
+	RequiredForOnline string                      `json:"required_for_online,omitempty" yaml:"required_for_online,omitempty"`
+	Roles             []string                    `json:"roles,omitempty"               yaml:"roles,omitempty"`
+	Routes            []SystemNetworkRoute        `json:"routes,omitempty"              yaml:"routes,omitempty"`
+	RoutingRules      []SystemNetworkRoutingRule  `json:"routing_rules,omitempty"       yaml:"routing_rules,omitempty"`
+	VNI               int                         `json:"vni,omitempty"                 yaml:"vni,omitempty"`
+}
+
+// SystemNetworkWifi contains information about a Wi-Fi client interface.
+type SystemNetworkWifi struct {
+	Addresses         []string                    `json:"addresses,omitempty"           yaml:"addresses,omitempty"`
+	Country           string                      `json:"country,omitempty"             yaml:"country,omitempty"`
+	EAP               *SystemNetworkEAP           `json:"eap,omitempty"                 yaml:"eap,omitempty"`
+	FirewallRules     []SystemNetworkFirewallRule `json:"firewall_rules,omitempty"      yaml:"firewall_rules,omitempty"`
+	Hidden            bool                        `json:"hidden,omitempty"              yaml:"hidden,omitempty"`
+	Hwaddr            string                      `json:"hwaddr"                        yaml:"hwaddr"`
+	MTU               int                         `json:"mtu,omitempty"                 yaml:"mtu,omitempty"`
+	Name              string                      `json:"name"                          yaml:"name"`
+	PSK               string                      `json:"psk,omitempty"                 yaml:"psk,omitempty"`
+	RequiredForOnline string                      `json:"required_for_online,omitempty" yaml:"required_for_online,omitempty"`
+	Roles             []string                    `json:"roles,omitempty"               yaml:"roles,omitempty"`
+	Routes            []SystemNetworkRoute        `json:"routes,omitempty"              yaml:"routes,omitempty"`
+	RoutingRules      []SystemNetworkRoutingRule  `json:"routing_rules,omitempty"       yaml:"routing_rules,omitempty"`
+	Security          string                      `json:"security,omitempty"            yaml:"security,omitempty"`
+	SSID              string                      `json:"ssid"                          yaml:"ssid"`
+}
+
+// SystemNetworkEAP defines the 802.1X credentials used to authenticate on an enterprise wired or Wi-Fi network.
+type SystemNetworkEAP struct {
+	AnonymousIdentity string `json:"anonymous_identity,omitempty" yaml:"anonymous_identity,omitempty"`
+	CACertificate     string `json:"ca_certificate,omitempty"     yaml:"ca_certificate,omitempty"`
+	ClientCertificate string `json:"client_certificate,omitempty" yaml:"client_certificate,omitempty"`
+	ClientKey         string `json:"client_key,omitempty"         yaml:"client_key,omitempty"`
+	Identity          string `json:"identity,omitempty"           yaml:"identity,omitempty"`
+	Method            string `json:"method"                       yaml:"method"`
+	Password          string `json:"password,omitempty"           yaml:"password,omitempty"`
+	Phase2            string `json:"phase2,omitempty"             yaml:"phase2,omitempty"`
 }
 
 // SystemNetworkWireguardPeer defines wireguard peer.
@@ -128,21 +276,98 @@ type SystemNetworkWireguardPeer struct {
 
 // SystemNetworkRoute defines a route.
 type SystemNetworkRoute struct {
-	To  string `json:"to"  yaml:"to"`
-	Via string `json:"via" yaml:"via"`
+	Metric int    `json:"metric,omitempty" yaml:"metric,omitempty"`
+	Table  int    `json:"table,omitempty"  yaml:"table,omitempty"`
+	To     string `json:"to"               yaml:"to"`
+	Via    string `json:"via"              yaml:"via"`
+}
+
+// SystemNetworkRoutingRule defines a policy routing rule, sending matching traffic to a routing table.
+type SystemNetworkRoutingRule struct {
+	From     string `json:"from,omitempty"     yaml:"from,omitempty"`
+	Priority int    `json:"priority,omitempty" yaml:"priority,omitempty"`
+	Table    int    `json:"table"              yaml:"table"`
+	To       string `json:"to,omitempty"       yaml:"to,omitempty"`
+}
+
+// SystemNetworkDHCPServer defines a DHCP server running on one of the configured devices.
+type SystemNetworkDHCPServer struct {
+	BootFilename string                         `json:"boot_filename,omitempty" yaml:"boot_filename,omitempty"`
+	BootServer   string                         `json:"boot_server,omitempty"   yaml:"boot_server,omitempty"`
+	DNS          []string                       `json:"dns,omitempty"           yaml:"dns,omitempty"`
+	Interface    string                         `json:"interface"               yaml:"interface"`
+	LeaseTime    int                            `json:"lease_time,omitempty"    yaml:"lease_time,omitempty"` // In seconds
+	PoolOffset   int                            `json:"pool_offset,omitempty"   yaml:"pool_offset,omitempty"`
+	PoolSize     int                            `json:"pool_size,omitempty"     yaml:"pool_size,omitempty"`
+	Router       string                         `json:"router,omitempty"        yaml:"router,omitempty"`
+	StaticLeases []SystemNetworkDHCPStaticLease `json:"static_leases,omitempty" yaml:"static_leases,omitempty"`
+}
+
+// SystemNetworkDHCPStaticLease defines a fixed address handed out to a given MAC address.
+type SystemNetworkDHCPStaticLease struct {
+	Address string `json:"address" yaml:"address"`
+	Hwaddr  string `json:"hwaddr"  yaml:"hwaddr"`
+}
+
+// SystemNetworkIPv6 defines IPv6 prefix delegation and router advertisement options.
+type SystemNetworkIPv6 struct {
+	PrefixDelegation     *SystemNetworkIPv6PrefixDelegation     `json:"prefix_delegation,omitempty"     yaml:"prefix_delegation,omitempty"`
+	RouterAdvertisements []SystemNetworkIPv6RouterAdvertisement `json:"router_advertisements,omitempty" yaml:"router_advertisements,omitempty"`
+}
+
+// SystemNetworkIPv6PrefixDelegation defines a DHCPv6 prefix delegation request on an uplink, and how
+// the delegated prefix is split between downstream devices.
+type SystemNetworkIPv6PrefixDelegation struct {
+	Downstreams  []SystemNetworkIPv6PrefixDownstream `json:"downstreams,omitempty"   yaml:"downstreams,omitempty"`
+	PrefixLength int                                 `json:"prefix_length,omitempty" yaml:"prefix_length,omitempty"`
+	Uplink       string                              `json:"uplink"                  yaml:"uplink"`
+}
+
+// SystemNetworkIPv6PrefixDownstream defines a device receiving a sub-prefix of the delegated prefix.
+type SystemNetworkIPv6PrefixDownstream struct {
+	Interface string `json:"interface"           yaml:"interface"`
+	SubnetID  int    `json:"subnet_id,omitempty" yaml:"subnet_id,omitempty"`
+}
+
+// SystemNetworkIPv6RouterAdvertisement defines the router advertisements sent on a device.
+type SystemNetworkIPv6RouterAdvertisement struct {
+	DNS              []string `json:"dns,omitempty"               yaml:"dns,omitempty"`
+	Interface        string   `json:"interface"                   yaml:"interface"`
+	Managed          bool     `json:"managed,omitempty"           yaml:"managed,omitempty"`
+	OtherInformation bool     `json:"other_information,omitempty" yaml:"other_information,omitempty"`
+	Prefixes         []string `json:"prefixes,omitempty"          yaml:"prefixes,omitempty"`
+	RouterLifetime   int      `json:"router_lifetime,omitempty"   yaml:"router_lifetime,omitempty"` // In seconds
 }
 
 // SystemNetworkDNS defines DNS configuration options.
 type SystemNetworkDNS struct {
-	Domain        string   `json:"domain"                   yaml:"domain"`
-	Hostname      string   `json:"hostname"                 yaml:"hostname"`
-	Nameservers   []string `json:"nameservers,omitempty"    yaml:"nameservers,omitempty"`
-	SearchDomains []string `json:"search_domains,omitempty" yaml:"search_domains,omitempty"`
+	DNSOverTLS    string                  `json:"dns_over_tls,omitempty"   yaml:"dns_over_tls,omitempty"`
+	Domain        string                  `json:"domain"                   yaml:"domain"`
+	Hostname      string                  `json:"hostname"                 yaml:"hostname"`
+	Nameservers   []string                `json:"nameservers,omitempty"    yaml:"nameservers,omitempty"`
+	Routes        []SystemNetworkDNSRoute `json:"routes,omitempty"         yaml:"routes,omitempty"`
+	SearchDomains []string                `json:"search_domains,omitempty" yaml:"search_domains,omitempty"`
+}
+
+// SystemNetworkDNSRoute defines name servers to be used for a set of domains, reached through a given device.
+type SystemNetworkDNSRoute struct {
+	Domains     []string `json:"domains"     yaml:"domains"`
+	Interface   string   `json:"interface"   yaml:"interface"`
+	Nameservers []string `json:"nameservers" yaml:"nameservers"`
+}
+
+// SystemNetworkFailover defines health-checked failover of the default route between multiple uplinks.
+type SystemNetworkFailover struct {
+	Interval  int      `json:"interval,omitempty"  yaml:"interval,omitempty"` // In seconds
+	Targets   []string `json:"targets"             yaml:"targets"`
+	Threshold int      `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+	Uplinks   []string `json:"uplinks"             yaml:"uplinks"`
 }
 
 // SystemNetworkTime defines various time related configuration options (NTP servers, timezone, etc).
 type SystemNetworkTime struct {
 	NTPServers []string `json:"ntp_servers,omitempty" yaml:"ntp_servers,omitempty"`
+	NTS        bool     `json:"nts,omitempty"         yaml:"nts,omitempty"`
 	Timezone   string   `json:"timezone,omitempty"    yaml:"timezone,omitempty"`
 }
 
@@ -170,7 +395,32 @@ type SystemNetworkProxyRule struct {
 
 // SystemNetworkState holds information about the current network state.
 type SystemNetworkState struct {
-	Interfaces map[string]SystemNetworkInterfaceState `json:"interfaces" yaml:"interfaces"`
+	ConfirmationDeadline *time.Time                             `json:"confirmation_deadline,omitempty" yaml:"confirmation_deadline,omitempty"`
+	Failover             *SystemNetworkFailoverState            `json:"failover,omitempty"              yaml:"failover,omitempty"`
+	Interfaces           map[string]SystemNetworkInterfaceState `json:"interfaces"                      yaml:"interfaces"`
+	Time                 *SystemNetworkTimeState                `json:"time,omitempty"                  yaml:"time,omitempty"`
+}
+
+// SystemNetworkFailoverState holds information about the uplinks used for failover.
+type SystemNetworkFailoverState struct {
+	ActiveUplink string                      `json:"active_uplink"           yaml:"active_uplink"`
+	Uplinks      map[string]string           `json:"uplinks"                 yaml:"uplinks"`
+	LastFailover *SystemNetworkFailoverEvent `json:"last_failover,omitempty" yaml:"last_failover,omitempty"`
+}
+
+// SystemNetworkFailoverEvent records a change of the active uplink or bond member.
+type SystemNetworkFailoverEvent struct {
+	Time time.Time `json:"time" yaml:"time"`
+	From string    `json:"from" yaml:"from"`
+	To   string    `json:"to"   yaml:"to"`
+}
+
+// SystemNetworkTimeState holds information about the time synchronization status.
+type SystemNetworkTimeState struct {
+	Offset       float64 `json:"offset"       yaml:"offset"` // In seconds
+	Server       string  `json:"server"       yaml:"server"`
+	Stratum      int     `json:"stratum"      yaml:"stratum"`
+	Synchronized bool    `json:"synchronized" yaml:"synchronized"`
 }
 
 // GetInterfaceNamesByRole returns a slice of interface names that have the given role applied to them.
@@ -189,17 +439,21 @@ func (n *SystemNetworkState) GetInterfaceNamesByRole(role string) []string {
 // SystemNetworkInterfaceState holds state information about a specific network interface.
 type SystemNetworkInterfaceState struct {
 	Addresses []string                               `json:"addresses,omitempty" yaml:"addresses,omitempty"`
+	Bond      *SystemNetworkBondState                `json:"bond,omitempty"      yaml:"bond,omitempty"`
 	Hwaddr    string                                 `json:"hwaddr,omitempty"    yaml:"hwaddr,omitempty"`
 	LACP      *SystemNetworkLACPState                `json:"lacp,omitempty"      yaml:"lacp,omitempty"`
 	LLDP      []SystemNetworkLLDPState               `json:"lldp,omitempty"      yaml:"lldp,omitempty"`
 	Members   map[string]SystemNetworkInterfaceState `json:"members,omitempty"   yaml:"members,omitempty"`
 	MTU       int                                    `json:"mtu,omitempty"       yaml:"mtu,omitempty"`
+	Path      string                                 `json:"path,omitempty"      yaml:"path,omitempty"`
+	PPPoE     *SystemNetworkPPPoEState               `json:"pppoe,omitempty"     yaml:"pppoe,omitempty"`
 	Roles     []string                               `json:"roles,omitempty"     yaml:"roles,omitempty"`
 	Routes    []SystemNetworkRoute                   `json:"routes,omitempty"    yaml:"routes,omitempty"`
 	Speed     string                                 `json:"speed,omitempty"     yaml:"speed,omitempty"`
 	State     string                                 `json:"state"               yaml:"state"`
 	Stats     SystemNetworkInterfaceStats            `json:"stats"               yaml:"stats"`
 	Type      string                                 `json:"type,omitempty"      yaml:"type,omitempty"`
+	Wifi      *SystemNetworkWifiState                `json:"wifi,omitempty"      yaml:"wifi,omitempty"`
 	Wireguard *SystemNetworkWireguardState           `json:"wireguard,omitempty" yaml:"wireguard,omitempty"`
 }
 
@@ -225,6 +479,31 @@ type SystemNetworkLACPState struct {
 	RemoteMAC string `json:"remote_mac" yaml:"remote_mac"`
 }
 
+// SystemNetworkBondState holds link monitoring information about a bond or one of its members.
+type SystemNetworkBondState struct {
+	ActiveMember string                      `json:"active_member,omitempty" yaml:"active_member,omitempty"`
+	LinkFailures int                         `json:"link_failures"           yaml:"link_failures"`
+	MIIStatus    string                      `json:"mii_status"              yaml:"mii_status"`
+	LastFailover *SystemNetworkFailoverEvent `json:"last_failover,omitempty" yaml:"last_failover,omitempty"`
+}
+
+// SystemNetworkPPPoEState holds state information about a specific PPPoE session.
+type SystemNetworkPPPoEState struct {
+	PeerAddress string `json:"peer_address,omitempty" yaml:"peer_address,omitempty"`
+	Session     string `json:"session"                yaml:"session"`
+}
+
+// SystemNetworkWifiState holds state information about a specific Wi-Fi interface.
+type SystemNetworkWifiState struct {
+	BSSID     string `json:"bssid,omitempty"      yaml:"bssid,omitempty"`
+	Frequency int    `json:"frequency,omitempty"  yaml:"frequency,omitempty"`  // In MHz
+	LinkSpeed int    `json:"link_speed,omitempty" yaml:"link_speed,omitempty"` // In Mbit/s
+	Security  string `json:"security,omitempty"   yaml:"security,omitempty"`
+	Signal    int    `json:"signal,omitempty"     yaml:"signal,omitempty"` // In dBm
+	SSID      string `json:"ssid,omitempty"       yaml:"ssid,omitempty"`
+	State     string `json:"state"                yaml:"state"`
+}
+
 // SystemNetworkWireguardState holds state information about a specific wireguard interface.
 type SystemNetworkWireguardState struct {
 	ListeningPort int                               `json:"listening_port,omitempty" yaml:"listening_port,omitempty"`
@@ -2,13 +2,29 @@ package api
 
 // SystemProviderConfig holds the modifiable part of the provider data.
 type SystemProviderConfig struct {
+	Name      string                   `json:"name"                yaml:"name"`
+	Config    map[string]string        `json:"config,omitempty"    yaml:"config,omitempty"`
+	Fallbacks []SystemProviderFallback `json:"fallbacks,omitempty" yaml:"fallbacks,omitempty"` // Providers to fetch updates from, in order, should the main provider fail.
+}
+
+// SystemProviderFallback holds the configuration of a fallback update provider.
+type SystemProviderFallback struct {
 	Name   string            `json:"name"             yaml:"name"`
 	Config map[string]string `json:"config,omitempty" yaml:"config,omitempty"`
 }
 
 // SystemProviderState holds information about the current provider state.
 type SystemProviderState struct {
-	Registered bool `json:"registered" yaml:"registered"`
+	Registered bool                   `json:"registered"       yaml:"registered"`
+	Health     []SystemProviderHealth `json:"health,omitempty" yaml:"health,omitempty"` // Main provider first, followed by the fallbacks.
+}
+
+// SystemProviderHealth holds the result of the last request made to an update provider.
+type SystemProviderHealth struct {
+	Name      string `json:"name"                 yaml:"name"`
+	Healthy   bool   `json:"healthy"              yaml:"healthy"`
+	LastCheck string `json:"last_check,omitempty" yaml:"last_check,omitempty"` // RFC3339 timestamp.
+	LastError string `json:"last_error,omitempty" yaml:"last_error,omitempty"`
 }
 
 // SystemProvider defines a struct to hold information about the system's update and configuration provider.
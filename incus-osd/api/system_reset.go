@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"time"
 )
 
 // SystemReset defines a struct that takes an optional map of seed data to set as part of the factory reset.
@@ -9,4 +10,14 @@ type SystemReset struct {
 	AllowTPMResetFailure bool                       `json:"allow_tpm_reset_failure" yaml:"allow_tpm_reset_failure"`
 	Seeds                map[string]json.RawMessage `json:"seeds"                   yaml:"seeds"`
 	WipeExistingSeeds    bool                       `json:"wipe_existing_seeds"     yaml:"wipe_existing_seeds"`
+	KeepData             bool                       `json:"keep_data"               yaml:"keep_data"`            // Keep the "local" storage pool.
+	RequestConfirmation  bool                       `json:"request_confirmation"    yaml:"request_confirmation"` // Only return a confirmation token rather than resetting.
+	ConfirmationToken    string                     `json:"confirmation_token"      yaml:"confirmation_token"`   // As returned by a prior request asking for confirmation.
+	SkipConfirmation     bool                       `json:"skip_confirmation"       yaml:"skip_confirmation"`    // Reset right away without a confirmation token, for existing automation.
+}
+
+// SystemResetConfirmation defines a struct holding the token required to confirm a factory reset.
+type SystemResetConfirmation struct {
+	Token     string    `json:"token"      yaml:"token"`
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
 }
@@ -1,5 +1,16 @@
 package api
 
+const (
+	// SystemSecurityRoleViewer represents the "viewer" role, which can only read state.
+	SystemSecurityRoleViewer = "viewer"
+
+	// SystemSecurityRoleOperator represents the "operator" role, which can also change configuration and restart services.
+	SystemSecurityRoleOperator = "operator"
+
+	// SystemSecurityRoleAdmin represents the "admin" role, which can also perform destructive or security sensitive actions.
+	SystemSecurityRoleAdmin = "admin"
+)
+
 // SystemSecurityState holds information about the current security state.
 type SystemSecurityState struct {
 	EncryptionRecoveryKeysRetrieved bool                                  `json:"encryption_recovery_keys_retrieved" yaml:"encryption_recovery_keys_retrieved"`
@@ -9,11 +20,134 @@ type SystemSecurityState struct {
 	TPMStatus                       string                                `incusos:"-"                               json:"tpm_status"                         yaml:"tpm_status"`
 	PoolRecoveryKeys                map[string]string                     `incusos:"-"                               json:"pool_recovery_keys"                 yaml:"pool_recovery_keys"`
 	SystemStateIsTrusted            bool                                  `incusos:"-"                               json:"system_state_is_trusted"            yaml:"system_state_is_trusted"`
+	FIDO2Tokens                     []SystemSecurityFIDO2Token            `incusos:"-"                               json:"fido2_tokens"                       yaml:"fido2_tokens"`
+	EncryptionRecoveryKeysMetadata  []SystemSecurityRecoveryKey           `json:"encryption_recovery_keys_metadata"  yaml:"encryption_recovery_keys_metadata"`
+	EncryptionKeySlots              []SystemSecurityEncryptionKeySlot     `incusos:"-"                               json:"encryption_key_slots"               yaml:"encryption_key_slots"`
+	TrustedCertificates             []SystemSecurityTrustedCertificate    `incusos:"-"                               json:"trusted_certificates"               yaml:"trusted_certificates"`
+	USBDevices                      []SystemSecurityUSBDevice             `incusos:"-"                               json:"usb_devices"                        yaml:"usb_devices"`
+	IMAStatus                       string                                `incusos:"-"                               json:"ima_status"                         yaml:"ima_status"` // One of "enabled", "disabled" or "unsupported".
+	IMAMeasurements                 int                                   `incusos:"-"                               json:"ima_measurements"                   yaml:"ima_measurements"`
+	AppArmorProfiles                []SystemSecurityAppArmorProfile       `incusos:"-"                               json:"apparmor_profiles"                  yaml:"apparmor_profiles"`
+	AppArmorDenials                 []SystemSecurityAppArmorDenial        `incusos:"-"                               json:"apparmor_denials"                   yaml:"apparmor_denials"`
+	Secrets                         []string                              `incusos:"-"                               json:"secrets"                            yaml:"secrets"`         // Names of the secrets stored locally.
+	KernelLockdown                  string                                `incusos:"-"                               json:"kernel_lockdown"                    yaml:"kernel_lockdown"` // One of "none", "integrity", "confidentiality" or "unsupported".
+	KernelModulesDisabled           bool                                  `incusos:"-"                               json:"kernel_modules_disabled"            yaml:"kernel_modules_disabled"`
+	TPMUnlockFailures               int                                   `json:"tpm_unlock_failures"                yaml:"tpm_unlock_failures"`     // Number of boots where the TPM failed to unlock the encrypted volumes.
+	TPMUnlockLastFailure            string                                `json:"tpm_unlock_last_failure"            yaml:"tpm_unlock_last_failure"` // RFC3339 timestamp.
+	TPMUnlockWarning                bool                                  `json:"tpm_unlock_warning"                 yaml:"tpm_unlock_warning"`      // Raised on failure, until cleared.
 }
 
 // SystemSecurityConfig holds additional security configuration settings.
 type SystemSecurityConfig struct {
-	EncryptionRecoveryKeys []string `json:"encryption_recovery_keys" yaml:"encryption_recovery_keys"`
+	EncryptionRecoveryKeys []string                      `json:"encryption_recovery_keys" yaml:"encryption_recovery_keys"`
+	IMAMeasurement         bool                          `json:"ima_measurement"          yaml:"ima_measurement"`    // Measure executed binaries into PCR 10, disabling requires a reboot.
+	Lockdown               bool                          `json:"lockdown"                 yaml:"lockdown"`           // Reject all API changes until unlocked.
+	VulnerabilityFeed      string                        `json:"vulnerability_feed"       yaml:"vulnerability_feed"` // URL of the OSV compatible API the SBOM is checked against, defaults to https://api.osv.dev.
+	ACME                   *SystemSecurityACME           `json:"acme"                     yaml:"acme"`
+	AccessControl          *SystemSecurityAccessControl  `json:"access_control"           yaml:"access_control"`
+	AppArmor               *SystemSecurityAppArmor       `json:"apparmor"                 yaml:"apparmor"`
+	CACertificates         []SystemSecurityCACertificate `json:"ca_certificates"          yaml:"ca_certificates"`
+	Firewall               *SystemSecurityFirewall       `json:"firewall"                 yaml:"firewall"`
+	Kernel                 *SystemSecurityKernel         `json:"kernel"                   yaml:"kernel"`
+	NBDE                   *SystemSecurityNBDE           `json:"nbde"                     yaml:"nbde"`
+	OIDC                   *SystemSecurityOIDC           `json:"oidc"                     yaml:"oidc"`
+	Secrets                *SystemSecuritySecrets        `json:"secrets"                  yaml:"secrets"`
+	TPMBinding             *SystemSecurityTPMBinding     `json:"tpm_binding"              yaml:"tpm_binding"`
+	UpdateSigning          *SystemSecurityUpdateSigning  `json:"update_signing"           yaml:"update_signing"`
+	USB                    *SystemSecurityUSB            `json:"usb"                      yaml:"usb"`
+}
+
+// SystemSecurityTPMBinding holds the TPM policy the encrypted volumes are bound to. When not set,
+// volumes are bound to PCR 7 and the signed PCR 11 policy of the running image.
+type SystemSecurityTPMBinding struct {
+	PCRs         []int `json:"pcrs"          yaml:"pcrs"`          // PCRs bound to their current SHA256 value, excluding PCRs 4, 9 and 11 which change with every update.
+	SignedPolicy bool  `json:"signed_policy" yaml:"signed_policy"` // Bind PCR 11 through the policy signed by the image's Secure Boot key.
+}
+
+// SystemSecurityAccessControl holds the roles of the identities allowed to use the API. When not set,
+// all the identities authenticated by the proxying application are administrators.
+type SystemSecurityAccessControl struct {
+	DefaultRole string            `json:"default_role" yaml:"default_role"` // Role of callers not listed in Identities, defaults to "viewer" and can't be "admin".
+	Identities  map[string]string `json:"identities"   yaml:"identities"`   // Role of each identity, keyed by certificate fingerprint or OIDC subject.
+}
+
+// SystemSecurityFirewall holds the host firewall configuration protecting the management plane.
+type SystemSecurityFirewall struct {
+	DefaultPolicy string                      `json:"default_policy" yaml:"default_policy"` // One of "accept" (default) or "drop", applied to interfaces with the management role.
+	APISources    []string                    `json:"api_sources"    yaml:"api_sources"`    // Addresses or subnets allowed to reach the HTTPS API, all if empty.
+	Rules         []SystemNetworkFirewallRule `json:"rules"          yaml:"rules"`
+}
+
+// SystemSecurityUSB holds the policy controlling which USB devices are authorized to attach to the host.
+type SystemSecurityUSB struct {
+	Policy         string   `json:"policy"          yaml:"policy"`          // One of "allow" (default) or "block", applied to devices not explicitly allowed.
+	AllowedClasses []string `json:"allowed_classes" yaml:"allowed_classes"` // Allowed USB classes, as two hexadecimal digits (e.g. "03" for HID).
+	AllowedDevices []string `json:"allowed_devices" yaml:"allowed_devices"` // Allowed devices, as "vendor:product" hexadecimal IDs.
+}
+
+// SystemSecurityKernel holds the kernel lockdown and module loading policy.
+type SystemSecurityKernel struct {
+	Lockdown       string   `json:"lockdown"        yaml:"lockdown"`        // One of "integrity" or "confidentiality", can't be lowered until a reboot.
+	ModulePolicy   string   `json:"module_policy"   yaml:"module_policy"`   // One of "allow" (default) or "restrict", disabling module loading once the system has started.
+	AllowedModules []string `json:"allowed_modules" yaml:"allowed_modules"` // Modules loaded before module loading is disabled.
+	DeniedModules  []string `json:"denied_modules"  yaml:"denied_modules"`  // Modules which can never be loaded.
+}
+
+// SystemSecurityAppArmor holds the AppArmor confinement settings of the subprocesses managed by IncusOS.
+type SystemSecurityAppArmor struct {
+	Mode string `json:"mode" yaml:"mode"` // One of "enforce", "complain" (default) or "disabled".
+}
+
+// SystemSecuritySecrets holds the configuration of the external secrets providers, used to resolve
+// secret references in service configurations.
+type SystemSecuritySecrets struct {
+	Vault *SystemSecuritySecretsVault `json:"vault" yaml:"vault"`
+}
+
+// SystemSecuritySecretsVault holds the configuration used to fetch secrets from HashiCorp Vault.
+type SystemSecuritySecretsVault struct {
+	Address   string `json:"address"   yaml:"address"`
+	Token     string `json:"token"     yaml:"token"`
+	Namespace string `json:"namespace" yaml:"namespace"`
+	Mount     string `json:"mount"     yaml:"mount"` // Mount path of the KV version 2 secrets engine, defaults to "secret".
+}
+
+// SystemSecurityOIDC holds the OpenID Connect configuration used to authenticate callers of the
+// primary application's HTTPS API.
+type SystemSecurityOIDC struct {
+	Issuer   string `json:"issuer"    yaml:"issuer"`
+	ClientID string `json:"client_id" yaml:"client_id"`
+	Audience string `json:"audience"  yaml:"audience"`
+	Scopes   string `json:"scopes"    yaml:"scopes"` // Space separated list of scopes to request.
+	Claim    string `json:"claim"     yaml:"claim"`  // Claim used to identify the user.
+}
+
+// SystemSecurityACME holds the ACME configuration used to issue and renew the certificate of the
+// primary application's HTTPS API.
+type SystemSecurityACME struct {
+	Domain               string   `json:"domain"                 yaml:"domain"`
+	Email                string   `json:"email"                  yaml:"email"`  // Email address used for the account registration.
+	CAURL                string   `json:"ca_url"                 yaml:"ca_url"` // URL of the ACME directory, defaults to Let's Encrypt.
+	AgreeTOS             bool     `json:"agree_tos"              yaml:"agree_tos"`
+	Challenge            string   `json:"challenge"              yaml:"challenge"`              // One of "HTTP-01" (default) or "DNS-01".
+	HTTPChallengeAddress string   `json:"http_challenge_address" yaml:"http_challenge_address"` // Address to listen on for HTTP-01, defaults to ":80".
+	Provider             string   `json:"provider"               yaml:"provider"`               // DNS provider used for DNS-01, as supported by lego.
+	ProviderEnvironment  []string `json:"provider_environment"   yaml:"provider_environment"`   // Environment variables, as "KEY=value", configuring the DNS provider.
+	ProviderResolvers    []string `json:"provider_resolvers"     yaml:"provider_resolvers"`     // DNS resolvers used to check the DNS-01 records.
+	Incus                bool     `json:"incus"                  yaml:"incus"`                  // Also configure Incus when installed alongside another primary application.
+}
+
+// SystemSecurityNBDE holds the network-bound disk encryption configuration. It only applies to
+// the non-root encrypted volumes, as the root volume is unlocked before the network is up.
+type SystemSecurityNBDE struct {
+	Servers   []SystemSecurityTangServer `json:"servers"   yaml:"servers"`
+	Threshold int                        `json:"threshold" yaml:"threshold"`
+}
+
+// SystemSecurityTangServer defines a Tang server used for network-bound disk encryption.
+type SystemSecurityTangServer struct {
+	Thumbprint string `json:"thumbprint" yaml:"thumbprint"`
+	URL        string `json:"url"        yaml:"url"`
 }
 
 // SystemSecurity defines a struct to hold information about the system's security state.
@@ -23,6 +157,42 @@ type SystemSecurity struct {
 	State SystemSecurityState `json:"state" yaml:"state"`
 }
 
+// SystemSecurityUSBDevice defines a struct that holds information about a USB device attached to the host.
+type SystemSecurityUSBDevice struct {
+	ID           string   `json:"id"           yaml:"id"` // Kernel name of the device (e.g. "1-2").
+	VendorID     string   `json:"vendor_id"    yaml:"vendor_id"`
+	ProductID    string   `json:"product_id"   yaml:"product_id"`
+	Manufacturer string   `json:"manufacturer" yaml:"manufacturer"`
+	Product      string   `json:"product"      yaml:"product"`
+	Serial       string   `json:"serial"       yaml:"serial"`
+	Classes      []string `json:"classes"      yaml:"classes"` // Device and interface classes.
+	Authorized   bool     `json:"authorized"   yaml:"authorized"`
+}
+
+// SystemSecurityAppArmorProfile defines a struct that holds the status of an AppArmor profile shipped by IncusOS.
+type SystemSecurityAppArmorProfile struct {
+	Name   string `json:"name"   yaml:"name"`
+	Binary string `json:"binary" yaml:"binary"` // Path of the confined binary.
+	Mode   string `json:"mode"   yaml:"mode"`   // One of "enforce", "complain" or "unloaded".
+}
+
+// SystemSecurityAppArmorDenial defines a struct that holds an access denied by an AppArmor profile shipped by IncusOS.
+type SystemSecurityAppArmorDenial struct {
+	Timestamp  string `json:"timestamp"   yaml:"timestamp"`
+	Profile    string `json:"profile"     yaml:"profile"`
+	Operation  string `json:"operation"   yaml:"operation"`
+	Name       string `json:"name"        yaml:"name"` // Path or resource the access was denied to.
+	Command    string `json:"command"     yaml:"command"`
+	DeniedMask string `json:"denied_mask" yaml:"denied_mask"`
+	Enforced   bool   `json:"enforced"    yaml:"enforced"` // False when only logged by a profile in complain mode.
+}
+
+// SystemSecurityUSBAuthorize defines a struct used to authorize a blocked USB device.
+type SystemSecurityUSBAuthorize struct {
+	ID        string `json:"id"        yaml:"id"`
+	Permanent bool   `json:"permanent" yaml:"permanent"` // Also add the device to the allowed devices.
+}
+
 // SystemSecuritySecureBootCertificate defines a struct that holds information about Secure Boot keys present on the host.
 type SystemSecuritySecureBootCertificate struct {
 	Type        string `json:"type"        yaml:"type"`
@@ -36,3 +206,176 @@ type SystemSecurityEncryptedVolume struct {
 	Volume string `json:"volume" yaml:"volume"`
 	State  string `json:"state"  yaml:"state"`
 }
+
+// SystemSecurityRecoveryKey defines a struct that holds metadata about an encryption recovery key.
+// The key itself is only included when it was just generated.
+type SystemSecurityRecoveryKey struct {
+	ID        string `json:"id"         yaml:"id"`
+	Key       string `incusos:"-"       json:"key,omitempty" yaml:"key,omitempty"`
+	CreatedAt string `json:"created_at" yaml:"created_at"` // RFC3339 timestamp.
+	CreatedBy string `json:"created_by" yaml:"created_by"` // One of "install", "api", "user" or "unknown".
+}
+
+// SystemSecurityEncryptionKeySlot defines a struct that holds information about a key slot of an encrypted volume.
+type SystemSecurityEncryptionKeySlot struct {
+	Volume string `json:"volume" yaml:"volume"`
+	Slot   int    `json:"slot"   yaml:"slot"`
+	Type   string `json:"type"   yaml:"type"` // One of "tpm2", "recovery", "password", "fido2" or "clevis".
+}
+
+// SystemSecurityFIDO2Token defines a struct that holds information about a FIDO2 token enrolled on an encrypted volume.
+type SystemSecurityFIDO2Token struct {
+	Volume                   string `json:"volume"                     yaml:"volume"`
+	Slot                     int    `json:"slot"                       yaml:"slot"`
+	ClientPINRequired        bool   `json:"client_pin_required"        yaml:"client_pin_required"`
+	UserPresenceRequired     bool   `json:"user_presence_required"     yaml:"user_presence_required"`
+	UserVerificationRequired bool   `json:"user_verification_required" yaml:"user_verification_required"`
+}
+
+// SystemSecurityFIDO2Enroll defines a struct used to enroll a FIDO2 token on the encrypted volumes.
+type SystemSecurityFIDO2Enroll struct {
+	Device           string `json:"device"            yaml:"device"`
+	PIN              string `json:"pin"               yaml:"pin"`
+	UserVerification bool   `json:"user_verification" yaml:"user_verification"`
+}
+
+// SystemSecuritySecureBootEnroll defines a struct used to enroll a Secure Boot key.
+type SystemSecuritySecureBootEnroll struct {
+	Update   []byte `json:"update"   yaml:"update"`   // Signed (.auth) EFI variable update.
+	Variable string `json:"variable" yaml:"variable"` // One of "PK", "KEK" or "db".
+}
+
+// SystemSecurityAttestationRequest defines a struct used to request a TPM quote from the system.
+type SystemSecurityAttestationRequest struct {
+	Nonce string `json:"nonce" yaml:"nonce"`
+	PCRs  []int  `json:"pcrs"  yaml:"pcrs"`
+}
+
+// SystemSecurityAttestation holds a TPM quote and the evidence needed for an external verifier to check it.
+type SystemSecurityAttestation struct {
+	AttestationKey string         `json:"attestation_key" yaml:"attestation_key"`
+	EndorsementKey string         `json:"endorsement_key" yaml:"endorsement_key"`
+	EventLog       string         `json:"event_log"       yaml:"event_log"`
+	IMALog         string         `json:"ima_log"         yaml:"ima_log"` // Binary IMA measurement log, if enabled.
+	Nonce          string         `json:"nonce"           yaml:"nonce"`
+	OSName         string         `json:"os_name"         yaml:"os_name"`
+	OSVersion      string         `json:"os_version"      yaml:"os_version"`
+	PCRs           map[int]string `json:"pcrs"            yaml:"pcrs"`
+	Quote          string         `json:"quote"           yaml:"quote"`
+	Signature      string         `json:"signature"       yaml:"signature"`
+}
+
+// SystemSecurityTrustedCertificate defines a client certificate trusted by the HTTPS API of the primary application.
+// When adding a certificate, either the PEM-encoded certificate or its fingerprint must be provided.
+type SystemSecurityTrustedCertificate struct {
+	Name        string   `json:"name"                  yaml:"name"`
+	Description string   `json:"description"           yaml:"description"`
+	Fingerprint string   `json:"fingerprint"           yaml:"fingerprint"`
+	Certificate string   `json:"certificate,omitempty" yaml:"certificate,omitempty"`
+	Restricted  bool     `json:"restricted"            yaml:"restricted"`
+	Projects    []string `json:"projects"              yaml:"projects"` // Projects the certificate is restricted to.
+}
+
+// SystemSecurityCACertificate defines a struct that holds a custom CA certificate added to the system trust store.
+type SystemSecurityCACertificate struct {
+	Name        string `json:"name"        yaml:"name"`
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"` // SHA256 fingerprint, computed by IncusOS.
+	Certificate string `json:"certificate" yaml:"certificate"` // PEM-encoded CA certificate.
+}
+
+// SystemSecurityUpdateSigning holds the CA certificates trusted to sign updates, such as those of
+// self-built images, in addition to or instead of the one used to distribute IncusOS updates.
+type SystemSecurityUpdateSigning struct {
+	Certificates   []SystemSecurityCACertificate `json:"certificates"    yaml:"certificates"`
+	ReplaceDefault bool                          `json:"replace_default" yaml:"replace_default"` // Stop trusting the default update CA certificate.
+}
+
+// SystemSecurityCACertificateRemove defines a struct used to remove a custom CA certificate from the system trust store.
+type SystemSecurityCACertificateRemove struct {
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+}
+
+// SystemSecuritySecret defines a struct used to store a secret locally, sealed by the TPM.
+type SystemSecuritySecret struct {
+	Name  string `json:"name"  yaml:"name"`
+	Value string `json:"value" yaml:"value"`
+}
+
+// SystemSecuritySecretRemove defines a struct used to remove a locally stored secret.
+type SystemSecuritySecretRemove struct {
+	Name string `json:"name" yaml:"name"`
+}
+
+// SystemSecurityTrustedCertificateRemove defines a struct used to revoke a trusted client certificate.
+type SystemSecurityTrustedCertificateRemove struct {
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+}
+
+// SystemSecurityPosture defines a struct that holds a summary of the security posture of the system,
+// aggregating the state relevant to compliance scanning.
+type SystemSecurityPosture struct {
+	Timestamp            string                             `json:"timestamp"               yaml:"timestamp"` // RFC3339 timestamp.
+	SecureBootEnabled    bool                               `json:"secure_boot_enabled"     yaml:"secure_boot_enabled"`
+	TPMStatus            string                             `json:"tpm_status"              yaml:"tpm_status"`
+	UsingSWTPM           bool                               `json:"using_swtpm"             yaml:"using_swtpm"`
+	SystemStateIsTrusted bool                               `json:"system_state_is_trusted" yaml:"system_state_is_trusted"`
+	EncryptedVolumes     []SystemSecurityEncryptedVolume    `json:"encrypted_volumes"       yaml:"encrypted_volumes"`
+	TPMUnlockWarning     bool                               `json:"tpm_unlock_warning"      yaml:"tpm_unlock_warning"`
+	Firewall             SystemSecurityPostureFirewall      `json:"firewall"                yaml:"firewall"`
+	Listeners            []SystemSecurityPostureListener    `json:"listeners"               yaml:"listeners"`
+	Updates              SystemSecurityPostureUpdates       `json:"updates"                 yaml:"updates"`
+	TrustedCertificates  []SystemSecurityTrustedCertificate `json:"trusted_certificates"    yaml:"trusted_certificates"`
+	CACertificates       []SystemSecurityCACertificate      `json:"ca_certificates"         yaml:"ca_certificates"`
+}
+
+// SystemSecurityPostureFirewall defines a struct that holds the status of the host firewall.
+type SystemSecurityPostureFirewall struct {
+	Enabled       bool     `json:"enabled"        yaml:"enabled"`
+	DefaultPolicy string   `json:"default_policy" yaml:"default_policy"`
+	APISources    []string `json:"api_sources"    yaml:"api_sources"`
+}
+
+// SystemSecurityPostureListener defines a struct that holds a socket accepting network traffic on the host.
+type SystemSecurityPostureListener struct {
+	Protocol string `json:"protocol" yaml:"protocol"` // One of "tcp" or "udp".
+	Address  string `json:"address"  yaml:"address"`
+	Port     int    `json:"port"     yaml:"port"`
+	Process  string `json:"process"  yaml:"process"`
+}
+
+// SystemSecurityPostureUpdates defines a struct that holds the status of the OS and application updates.
+type SystemSecurityPostureUpdates struct {
+	RunningRelease    string            `json:"running_release"               yaml:"running_release"`
+	NextRelease       string            `json:"next_release"                  yaml:"next_release"` // Release applied on the next reboot, if any.
+	NeedsReboot       bool              `json:"needs_reboot"                  yaml:"needs_reboot"`
+	RebootScheduledAt string            `json:"reboot_scheduled_at,omitempty" yaml:"reboot_scheduled_at,omitempty"` // RFC3339 timestamp of the reboot finalizing a pending update, if scheduled.
+	LastCheck         string            `json:"last_check"                    yaml:"last_check"`                    // RFC3339 timestamp.
+	Status            string            `json:"status"                        yaml:"status"`
+	Applications      map[string]string `json:"applications"                  yaml:"applications"` // Running version of each application.
+}
+
+// SystemSecuritySBOM defines a struct that holds the software bill of materials of the running system.
+type SystemSecuritySBOM struct {
+	OSName            string                          `json:"os_name"            yaml:"os_name"`
+	OSVersion         string                          `json:"os_version"         yaml:"os_version"`
+	Packages          []SystemSecuritySBOMPackage     `json:"packages"           yaml:"packages"`
+	Applications      []SystemSecuritySBOMApplication `json:"applications"       yaml:"applications"`
+	VulnerabilityFeed string                          `json:"vulnerability_feed" yaml:"vulnerability_feed"` // Feed the packages were checked against, if requested.
+}
+
+// SystemSecuritySBOMPackage defines a struct that holds a package included in the running system.
+type SystemSecuritySBOMPackage struct {
+	Component       string   `json:"component"                 yaml:"component"` // Image including the package, "base" for the OS image.
+	Name            string   `json:"name"                      yaml:"name"`
+	Version         string   `json:"version"                   yaml:"version"`
+	Architecture    string   `json:"architecture"              yaml:"architecture"`
+	SourceName      string   `json:"source_name"               yaml:"source_name"`
+	SourceVersion   string   `json:"source_version"            yaml:"source_version"`
+	Vulnerabilities []string `json:"vulnerabilities,omitempty" yaml:"vulnerabilities,omitempty"` // Known vulnerabilities, if checked.
+}
+
+// SystemSecuritySBOMApplication defines a struct that holds an application installed on the system.
+type SystemSecuritySBOMApplication struct {
+	Name    string `json:"name"    yaml:"name"`
+	Version string `json:"version" yaml:"version"`
+}
@@ -42,15 +42,32 @@ type SystemStoragePool struct {
 	RawPoolSizeInBytes        int                           `json:"raw_pool_size_in_bytes"        yaml:"raw_pool_size_in_bytes"`
 	UsablePoolSizeInBytes     int                           `json:"usable_pool_size_in_bytes"     yaml:"usable_pool_size_in_bytes"`
 	PoolAllocatedSpaceInBytes int                           `json:"pool_allocated_space_in_bytes" yaml:"pool_allocated_space_in_bytes"`
+	HasCheckpoint             bool                          `json:"has_checkpoint"                yaml:"has_checkpoint"`
+	CheckpointSizeInBytes     int                           `json:"checkpoint_size_in_bytes"      yaml:"checkpoint_size_in_bytes"`
+	CheckpointReason          string                        `json:"checkpoint_reason,omitempty"   yaml:"checkpoint_reason,omitempty"`
 	Volumes                   []SystemStoragePoolVolume     `json:"volumes"                       yaml:"volumes"`
 }
 
 // SystemStoragePoolVolume represents a single IncusOS-managed volume in a pool.
 type SystemStoragePoolVolume struct {
-	Name         string `json:"name"           yaml:"name"`
-	UsageInBytes int    `json:"usage_in_bytes" yaml:"usage_in_bytes"`
-	QuotaInBytes int    `json:"quota_in_bytes" yaml:"quota_in_bytes"`
-	Use          string `json:"use"            yaml:"use"`
+	Name         string                            `json:"name"           yaml:"name"`
+	UsageInBytes int                               `json:"usage_in_bytes" yaml:"usage_in_bytes"`
+	QuotaInBytes int                               `json:"quota_in_bytes" yaml:"quota_in_bytes"`
+	Use          string                            `json:"use"            yaml:"use"`
+	Properties   SystemStoragePoolVolumeProperties `json:"properties"     yaml:"properties"`
+}
+
+// SystemStoragePoolVolumeProperties represents the tunable ZFS properties of a volume.
+// An empty value leaves the current property unchanged when updating a volume.
+type SystemStoragePoolVolumeProperties struct {
+	// Supported values: on, off, lz4, lzjb, zle, gzip, gzip-[1-9], zstd, zstd-[1-19], zstd-fast and zstd-fast-[1-10,20,30,...,100,500,1000].
+	Compression string `json:"compression,omitempty" yaml:"compression,omitempty"`
+	// A power of two between 512 and 16M, for example "16K" or "1M".
+	RecordSize string `json:"record_size,omitempty" yaml:"record_size,omitempty"`
+	// Supported values: on, off.
+	Atime string `json:"atime,omitempty" yaml:"atime,omitempty"`
+	// Supported values: standard, always, disabled.
+	Sync string `json:"sync,omitempty" yaml:"sync,omitempty"`
 }
 
 // SystemStoragePoolScrubState represents the state of a scan in a pool.
@@ -108,3 +125,38 @@ type SystemStoragePoolKey struct {
 	Type          string `json:"type"           yaml:"type"`
 	EncryptionKey string `json:"encryption_key" yaml:"encryption_key"`
 }
+
+// SystemStorageIOStats defines a struct holding I/O statistics for all storage pools.
+type SystemStorageIOStats struct {
+	Pools []SystemStoragePoolIOStats `json:"pools" yaml:"pools"`
+}
+
+// SystemStoragePoolIOStats defines a struct holding I/O statistics for a single pool, its members and its datasets.
+type SystemStoragePoolIOStats struct {
+	Name     string                        `json:"name"     yaml:"name"`
+	Pool     SystemStorageDeviceIOStats    `json:"pool"     yaml:"pool"`
+	Devices  []SystemStorageDeviceIOStats  `json:"devices"  yaml:"devices"`
+	Datasets []SystemStorageDatasetIOStats `json:"datasets" yaml:"datasets"`
+}
+
+// SystemStorageDeviceIOStats defines a struct holding rolling I/O statistics of a pool or pool member,
+// averaged over a one second interval. Latencies are in nanoseconds.
+type SystemStorageDeviceIOStats struct {
+	Name                string `json:"name"                   yaml:"name"`
+	ReadOpsPerSecond    int    `json:"read_ops_per_second"    yaml:"read_ops_per_second"`
+	WriteOpsPerSecond   int    `json:"write_ops_per_second"   yaml:"write_ops_per_second"`
+	ReadBytesPerSecond  int    `json:"read_bytes_per_second"  yaml:"read_bytes_per_second"`
+	WriteBytesPerSecond int    `json:"write_bytes_per_second" yaml:"write_bytes_per_second"`
+	ReadLatency         int    `json:"read_latency"           yaml:"read_latency"`
+	WriteLatency        int    `json:"write_latency"          yaml:"write_latency"`
+	QueueDepth          int    `json:"queue_depth"            yaml:"queue_depth"`
+}
+
+// SystemStorageDatasetIOStats defines a struct holding cumulative I/O counters of a dataset since the pool was imported.
+type SystemStorageDatasetIOStats struct {
+	Name         string `json:"name"          yaml:"name"`
+	Reads        int    `json:"reads"         yaml:"reads"`
+	Writes       int    `json:"writes"        yaml:"writes"`
+	BytesRead    int    `json:"bytes_read"    yaml:"bytes_read"`
+	BytesWritten int    `json:"bytes_written" yaml:"bytes_written"`
+}
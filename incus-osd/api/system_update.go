@@ -16,14 +16,234 @@ type SystemUpdateConfig struct {
 	AutoReboot         bool                            `json:"auto_reboot"                   yaml:"auto_reboot"`
 	Channel            string                          `json:"channel"                       yaml:"channel"`
 	CheckFrequency     string                          `json:"check_frequency"               yaml:"check_frequency"`
+	CheckJitter        string                          `json:"check_jitter,omitempty"        yaml:"check_jitter,omitempty"` // Maximum random delay added to each periodic update check.
+	HealthChecks       *SystemUpdateHealthChecks       `json:"health_checks,omitempty"       yaml:"health_checks,omitempty"`
+	Hold               *SystemUpdateHold               `json:"hold,omitempty"                yaml:"hold,omitempty"`
 	MaintenanceWindows []SystemUpdateMaintenanceWindow `json:"maintenance_windows,omitempty" yaml:"maintenance_windows,omitempty"`
+	PeerCache          *SystemUpdatePeerCache          `json:"peer_cache,omitempty"          yaml:"peer_cache,omitempty"`
+	PinnedVersion      string                          `json:"pinned_version,omitempty"      yaml:"pinned_version,omitempty"` // Only install this OS version, if set.
+	RebootPolicy       string                          `json:"reboot_policy"                 yaml:"reboot_policy"`            // One of SystemUpdateRebootPolicies, defaults to "immediately" if AutoReboot is set or "never" otherwise.
+	RebootTime         string                          `json:"reboot_time,omitempty"         yaml:"reboot_time,omitempty"`    // Daily "HH:MM" reboot time for the "scheduled" policy, in system's timezone.
+	StageUpdates       bool                            `json:"stage_updates"                 yaml:"stage_updates"`            // Only download OS updates, leaving them to be applied through the API.
 }
 
 // SystemUpdateState holds information about the current update state.
 type SystemUpdateState struct {
-	LastCheck   time.Time `json:"last_check"   yaml:"last_check"` // In system's timezone.
-	Status      string    `json:"status"       yaml:"status"`
-	NeedsReboot bool      `json:"needs_reboot" yaml:"needs_reboot"`
+	LastCheck         time.Time                     `json:"last_check"                    yaml:"last_check"`           // In system's timezone.
+	NextCheck         *time.Time                    `json:"next_check,omitempty"          yaml:"next_check,omitempty"` // When the next periodic update check is scheduled, if any.
+	Status            string                        `json:"status"                        yaml:"status"`
+	NeedsReboot       bool                          `json:"needs_reboot"                  yaml:"needs_reboot"`
+	Channel           string                        `json:"channel"                       yaml:"channel"`                       // Channel followed during the last update check.
+	AvailableVersions map[string][]string           `json:"available_versions"            yaml:"available_versions"`            // Versions available in each channel, newest first.
+	RollbackVersion   string                        `json:"rollback_version"              yaml:"rollback_version"`              // Previous version which can be rolled back to, if any.
+	RollbackPending   bool                          `json:"rollback_pending"              yaml:"rollback_pending"`              // A rollback will be completed on next reboot.
+	HealthCheck       *SystemUpdateHealthCheckState `json:"health_check,omitempty"        yaml:"health_check,omitempty"`        // Health checks of the last installed OS version, if any.
+	OnHold            bool                          `json:"on_hold"                       yaml:"on_hold"`                       // Updates are currently on hold.
+	RebootScheduledAt *time.Time                    `json:"reboot_scheduled_at,omitempty" yaml:"reboot_scheduled_at,omitempty"` // When the system will reboot to finalize a pending update, if scheduled.
+	Progress          []SystemUpdateProgress        `json:"progress"                      yaml:"progress"`                      // Progress of each artifact updated by the last update check.
+	StagedVersion     string                        `json:"staged_version"                yaml:"staged_version"`                // OS version downloaded and waiting to be applied, if any.
+	ReleaseNotes      *SystemUpdateReleaseNotes     `json:"release_notes,omitempty"       yaml:"release_notes,omitempty"`       // Release notes of the pending OS version, if any.
+	Bundle            *SystemUpdateBundle           `json:"bundle,omitempty"              yaml:"bundle,omitempty"`              // Provenance of the update bundle used by the bundle provider, if any.
+	BootSlots         []SystemUpdateBootSlot        `json:"boot_slots"                    yaml:"boot_slots"`                    // Status of the two OS image slots.
+	BootHistory       []SystemUpdateBootEvent       `json:"boot_history"                  yaml:"boot_history"`                  // Most recent boots, oldest first.
+}
+
+// SystemUpdateBootSlot holds the status of one of the two OS image slots.
+type SystemUpdateBootSlot struct {
+	Name        string `json:"name"                   yaml:"name"`                   // Either "a" or "b".
+	Version     string `json:"version"                yaml:"version"`                // Empty if the slot doesn't hold any OS version.
+	Role        string `json:"role"                   yaml:"role"`                   // Either "active", "previous" or empty.
+	BootStatus  string `json:"boot_status"            yaml:"boot_status"`            // Either "good", "pending" (not yet assessed), "bad" (out of boot attempts) or "missing".
+	TriesLeft   int    `json:"tries_left"             yaml:"tries_left"`             // Boot attempts left before the boot loader falls back to the other slot.
+	TriesDone   int    `json:"tries_done"             yaml:"tries_done"`             // Boot attempts made without the boot being assessed as good.
+	LastFailure string `json:"last_failure,omitempty" yaml:"last_failure,omitempty"` // Why the system last fell back from this slot's version, if it did.
+}
+
+// SystemUpdateBootEvent records a boot of the system.
+type SystemUpdateBootEvent struct {
+	Timestamp     string `json:"timestamp"                yaml:"timestamp"` // RFC3339 timestamp.
+	BootID        string `json:"boot_id"                  yaml:"boot_id"`
+	Version       string `json:"version"                  yaml:"version"`
+	Slot          string `json:"slot"                     yaml:"slot"`
+	FailedVersion string `json:"failed_version,omitempty" yaml:"failed_version,omitempty"` // Version expected to boot instead, if the system fell back.
+	Reason        string `json:"reason,omitempty"         yaml:"reason,omitempty"`         // Why the system fell back.
+}
+
+// SystemUpdateBundle holds the provenance of an update bundle.
+type SystemUpdateBundle struct {
+	Source      string `json:"source"                yaml:"source"` // Either "import", "media" or the configured bundle path.
+	IndexSHA256 string `json:"index_sha256"          yaml:"index_sha256"`
+	Version     string `json:"version"               yaml:"version"` // Latest release of the followed channel in the bundle.
+	Origin      string `json:"origin"                yaml:"origin"`
+	ImportedAt  string `json:"imported_at,omitempty" yaml:"imported_at,omitempty"` // RFC3339 timestamp, for imported bundles.
+}
+
+// SystemUpdateReleaseNotes holds the release notes of an OS version, as published by the provider.
+type SystemUpdateReleaseNotes struct {
+	Version string `json:"version" yaml:"version"`
+	Notes   string `json:"notes"   yaml:"notes"`
+}
+
+// SystemUpdateHealthChecks defines the checks run after booting into a new OS version. Should any check
+// keep failing past the grace period, the system rolls back to the previous OS version.
+type SystemUpdateHealthChecks struct {
+	Enabled           bool                      `json:"enabled"                yaml:"enabled"`
+	GracePeriod       string                    `json:"grace_period,omitempty" yaml:"grace_period,omitempty"` // Time given to the checks to pass, defaults to 10m.
+	Services          []string                  `json:"services,omitempty"     yaml:"services,omitempty"`     // Systemd units which must be active.
+	CheckPools        bool                      `json:"check_pools"            yaml:"check_pools"`            // Storage pools must be online.
+	CheckApplications bool                      `json:"check_applications"     yaml:"check_applications"`     // Installed applications must be running.
+	Probes            []SystemUpdateHealthProbe `json:"probes,omitempty"       yaml:"probes,omitempty"`
+}
+
+// SystemUpdateHealthProbe defines a custom health check.
+type SystemUpdateHealthProbe struct {
+	Name   string `json:"name"   yaml:"name"`
+	Type   string `json:"type"   yaml:"type"`   // Either "http" or "tcp".
+	Target string `json:"target" yaml:"target"` // URL for "http" probes, which must return a 2xx status, or address and port for "tcp" probes.
+}
+
+// SystemUpdateHealthCheckState holds the state of the health checks of an installed OS version.
+type SystemUpdateHealthCheckState struct {
+	Version string                          `json:"version" yaml:"version"`
+	Status  string                          `json:"status"  yaml:"status"` // One of "running", "passed", "failed" or "reverted".
+	Results []SystemUpdateHealthCheckResult `json:"results" yaml:"results"`
+}
+
+// SystemUpdateHealthCheckResult holds the result of a single health check.
+type SystemUpdateHealthCheckResult struct {
+	Name    string `json:"name"              yaml:"name"`
+	Passed  bool   `json:"passed"            yaml:"passed"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// SystemUpdateHold defines a temporary hold on updates, such as during a change freeze.
+type SystemUpdateHold struct {
+	Reason string `json:"reason" yaml:"reason"`
+	Until  string `json:"until"  yaml:"until"` // RFC3339 timestamp.
+}
+
+// IsActive returns true if the hold is in effect at the given point in time.
+func (h *SystemUpdateHold) IsActive(t time.Time) bool {
+	if h == nil {
+		return false
+	}
+
+	until, err := time.Parse(time.RFC3339, h.Until)
+	if err != nil {
+		return false
+	}
+
+	return t.Before(until)
+}
+
+// SystemUpdatePeerCache defines the sharing of downloaded update files between systems.
+type SystemUpdatePeerCache struct {
+	Enabled        bool     `json:"enabled"                   yaml:"enabled"`                   // Serve the downloaded update files to peers.
+	Port           int      `json:"port,omitempty"            yaml:"port,omitempty"`            // Port to serve the update files on, defaults to 8444.
+	AllowedClients []string `json:"allowed_clients,omitempty" yaml:"allowed_clients,omitempty"` // Addresses or subnets allowed to fetch the served update files.
+	Peers          []string `json:"peers,omitempty"           yaml:"peers,omitempty"`           // Address, optionally with a port, of the peers to fetch update files from.
+}
+
+// SystemUpdatePeerCacheDefaultPort is the port the downloaded update files are served to peers on by default.
+const SystemUpdatePeerCacheDefaultPort = 8444
+
+// GetPort returns the port the downloaded update files are served to peers on.
+func (c *SystemUpdatePeerCache) GetPort() int {
+	if c.Port == 0 {
+		return SystemUpdatePeerCacheDefaultPort
+	}
+
+	return c.Port
+}
+
+// SystemUpdateProgress holds the progress of an artifact being updated.
+type SystemUpdateProgress struct {
+	Type       string    `json:"type"            yaml:"type"` // "SecureBoot", "OS" or "application".
+	Name       string    `json:"name"            yaml:"name"` // Name of the application, or of the OS.
+	Version    string    `json:"version"         yaml:"version"`
+	Phase      string    `json:"phase"           yaml:"phase"`    // One of SystemUpdatePhases.
+	Progress   float64   `json:"progress"        yaml:"progress"` // Download progress, between 0 and 1.
+	Error      string    `json:"error,omitempty" yaml:"error,omitempty"`
+	LastUpdate time.Time `json:"last_update"     yaml:"last_update"` // In system's timezone.
+}
+
+// SystemUpdatePhases lists the phases an artifact goes through while being updated.
+var SystemUpdatePhases = []string{"downloading", "verifying", "installing", "updating-efi", "staged", "completed", "failed"}
+
+// SystemUpdateApply defines a struct holding the options used when applying a staged update.
+type SystemUpdateApply struct {
+	Reboot bool `json:"reboot" yaml:"reboot"`
+}
+
+// SystemUpdateChannels lists the release channels published by the images provider.
+var SystemUpdateChannels = []string{"stable", "candidate", "testing"}
+
+// SystemUpdateRebootPolicies lists the supported policies for rebooting to finalize an update.
+var SystemUpdateRebootPolicies = []string{"never", "immediately", "maintenance-window", "scheduled"}
+
+// GetCheckJitter returns the maximum random delay added to each periodic update check.
+func (c *SystemUpdateConfig) GetCheckJitter() time.Duration {
+	jitter, err := time.ParseDuration(c.CheckJitter)
+	if err != nil || jitter < 0 {
+		return 0
+	}
+
+	return jitter
+}
+
+// GetRebootPolicy returns the effective reboot policy, honoring AutoReboot if no policy is set.
+func (c *SystemUpdateConfig) GetRebootPolicy() string {
+	if c.RebootPolicy != "" {
+		return c.RebootPolicy
+	}
+
+	if c.AutoReboot {
+		return "immediately"
+	}
+
+	return "never"
+}
+
+// TimeUntilReboot returns the amount of time until the system may reboot to finalize an update, compared
+// to the given reference time, waiting for any hold to expire. The returned boolean is false if the
+// system should never reboot by itself.
+func (c *SystemUpdateConfig) TimeUntilReboot(t time.Time) (time.Duration, bool) {
+	// Never reboot while updates are on hold.
+	if c.Hold.IsActive(t) {
+		until, _ := time.Parse(time.RFC3339, c.Hold.Until)
+		delay, ok := c.TimeUntilReboot(until)
+
+		return until.Sub(t) + delay, ok
+	}
+
+	switch c.GetRebootPolicy() {
+	case "immediately":
+		return 0, true
+	case "maintenance-window":
+		var delay time.Duration
+
+		for i, window := range c.MaintenanceWindows {
+			if i == 0 || window.TimeUntilActiveReference(t) < delay {
+				delay = window.TimeUntilActiveReference(t)
+			}
+		}
+
+		return delay, true
+	case "scheduled":
+		rebootTime, err := time.Parse("15:04", c.RebootTime)
+		if err != nil {
+			return 0, false
+		}
+
+		next := time.Date(t.Year(), t.Month(), t.Day(), rebootTime.Hour(), rebootTime.Minute(), 0, 0, t.Location())
+		if next.Before(t) {
+			next = next.AddDate(0, 0, 1)
+		}
+
+		return next.Sub(t), true
+	default:
+		return 0, false
+	}
 }
 
 // SystemUpdateMaintenanceWindow defines a maintenance window for when it is acceptable to check for and apply updates.
@@ -278,3 +278,95 @@ func TestMigrationWindows(t *testing.T) {
 		require.Equal(t, timeUntilActive, tst.Duration, "Test %d failed", i)
 	}
 }
+
+func TestRebootPolicy(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 8, 28, 12, 0, 0, 0, time.UTC)
+
+	cfg := api.SystemUpdateConfig{}
+	require.Equal(t, "never", cfg.GetRebootPolicy())
+
+	_, ok := cfg.TimeUntilReboot(now)
+	require.False(t, ok)
+
+	cfg.AutoReboot = true
+	require.Equal(t, "immediately", cfg.GetRebootPolicy())
+
+	delay, ok := cfg.TimeUntilReboot(now)
+	require.True(t, ok)
+	require.Equal(t, 0*time.Minute, delay)
+
+	cfg.RebootPolicy = "maintenance-window"
+	cfg.MaintenanceWindows = []api.SystemUpdateMaintenanceWindow{mw2, mw1}
+
+	delay, ok = cfg.TimeUntilReboot(now)
+	require.True(t, ok)
+	require.Equal(t, 11*time.Hour+30*time.Minute, delay)
+
+	cfg.RebootPolicy = "scheduled"
+	cfg.RebootTime = "03:15"
+
+	delay, ok = cfg.TimeUntilReboot(now)
+	require.True(t, ok)
+	require.Equal(t, 15*time.Hour+15*time.Minute, delay)
+
+	cfg.RebootTime = "12:30"
+
+	delay, ok = cfg.TimeUntilReboot(now)
+	require.True(t, ok)
+	require.Equal(t, 30*time.Minute, delay)
+
+	cfg.RebootTime = "invalid"
+
+	_, ok = cfg.TimeUntilReboot(now)
+	require.False(t, ok)
+}
+
+func TestUpdateHold(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 8, 28, 12, 0, 0, 0, time.UTC)
+
+	var hold *api.SystemUpdateHold
+	require.False(t, hold.IsActive(now))
+
+	hold = &api.SystemUpdateHold{Reason: "Change freeze", Until: "2025-08-29T00:00:00Z"}
+	require.True(t, hold.IsActive(now))
+	require.False(t, hold.IsActive(now.Add(12*time.Hour)))
+
+	hold.Until = "invalid"
+	require.False(t, hold.IsActive(now))
+
+	// Reboots wait for the hold to expire.
+	cfg := api.SystemUpdateConfig{
+		RebootPolicy: "scheduled",
+		RebootTime:   "03:15",
+		Hold:         &api.SystemUpdateHold{Reason: "Change freeze", Until: "2025-08-29T06:00:00Z"},
+	}
+
+	delay, ok := cfg.TimeUntilReboot(now)
+	require.True(t, ok)
+	require.Equal(t, 39*time.Hour+15*time.Minute, delay)
+
+	cfg.RebootPolicy = "never"
+
+	_, ok = cfg.TimeUntilReboot(now)
+	require.False(t, ok)
+}
+
+func TestCheckJitter(t *testing.T) {
+	t.Parallel()
+
+	cfg := api.SystemUpdateConfig{}
+	require.Equal(t, time.Duration(0), cfg.GetCheckJitter())
+
+	cfg.CheckJitter = "30m"
+	require.Equal(t, 30*time.Minute, cfg.GetCheckJitter())
+
+	cfg.CheckJitter = "-5m"
+	require.Equal(t, time.Duration(0), cfg.GetCheckJitter())
+
+	cfg.CheckJitter = "invalid"
+	require.Equal(t, time.Duration(0), cfg.GetCheckJitter())
+}
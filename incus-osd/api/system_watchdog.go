@@ -0,0 +1,30 @@
+package api
+
+// SystemWatchdogConfig holds the modifiable part of the hardware watchdog data.
+type SystemWatchdogConfig struct {
+	Enabled       bool   `json:"enabled"        yaml:"enabled"`        // Whether systemd keeps the hardware watchdog armed, resetting the system if it hangs.
+	Device        string `json:"device"         yaml:"device"`         // Watchdog device, defaults to "/dev/watchdog0".
+	Timeout       int    `json:"timeout"        yaml:"timeout"`        // Seconds without a keepalive before the system is reset, defaults to 60.
+	RebootTimeout int    `json:"reboot_timeout" yaml:"reboot_timeout"` // Seconds allowed for a reboot to complete before the system is reset, defaults to 600.
+	Daemon        bool   `json:"daemon"         yaml:"daemon"`         // Whether systemd restarts incus-osd if it stops responding for the timeout.
+}
+
+// SystemWatchdogState holds information about the current hardware watchdog state.
+type SystemWatchdogState struct {
+	Devices         []SystemWatchdogDevice `json:"devices"           yaml:"devices"`
+	LastResetReason string                 `json:"last_reset_reason" yaml:"last_reset_reason"` // Reported by the watchdog, like "watchdog", "overheat" or "none".
+}
+
+// SystemWatchdogDevice represents a hardware watchdog.
+type SystemWatchdogDevice struct {
+	Name     string `json:"name"     yaml:"name"`
+	Identity string `json:"identity" yaml:"identity"` // Driver identity, like "iTCO_wdt".
+	Active   bool   `json:"active"   yaml:"active"`
+	Timeout  int    `json:"timeout"  yaml:"timeout"`
+}
+
+// SystemWatchdog defines a struct to hold information about the system's hardware watchdog.
+type SystemWatchdog struct {
+	Config SystemWatchdogConfig `json:"config" yaml:"config"`
+	State  SystemWatchdogState  `incusos:"-"   json:"state"  yaml:"state"`
+}
@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"strings"
+
 	cli "github.com/lxc/incus/v6/shared/cmd"
 	"github.com/spf13/cobra"
 )
@@ -29,15 +31,7 @@ func (c *cmdAdminOSSystem) command() *cobra.Command {
 	cmd.AddCommand(backupCmd.command())
 
 	// Factory reset.
-	factoryResetCmd := cmdGenericRun{
-		os:          c.os,
-		action:      "factory-reset",
-		description: "Factory reset the system",
-		endpoint:    "system",
-		hasData:     true,
-		defaultData: "{}",
-		confirm:     "factory-reset the system",
-	}
+	factoryResetCmd := cmdAdminOSSystemFactoryReset{os: c.os}
 	cmd.AddCommand(factoryResetCmd.command())
 
 	// Power off.
@@ -87,10 +81,67 @@ func (c *cmdAdminOSSystem) command() *cobra.Command {
 	}
 
 	subCommands := []subCommand{
+		{
+			name:        "extensions",
+			description: "Side-loaded system extensions",
+			isWritable:  false,
+			extraCommands: func() []*cobra.Command {
+				// Extension upload.
+				uploadCmd := cmdAdminOSSystemExtensionsAction{
+					os:          c.os,
+					action:      "upload",
+					description: "Upload a signed system extension image",
+					method:      "PUT",
+					url:         "/os/1.0/system/extensions/%s",
+					hasFile:     true,
+				}
+
+				// Extension activation.
+				activateCmd := cmdAdminOSSystemExtensionsAction{
+					os:          c.os,
+					action:      "activate",
+					description: "Merge a system extension into the running system",
+					method:      "POST",
+					url:         "/os/1.0/system/extensions/%s/:activate",
+				}
+
+				// Extension deactivation.
+				deactivateCmd := cmdAdminOSSystemExtensionsAction{
+					os:          c.os,
+					action:      "deactivate",
+					description: "Remove a system extension from the running system",
+					method:      "POST",
+					url:         "/os/1.0/system/extensions/%s/:deactivate",
+				}
+
+				// Extension removal.
+				deleteCmd := cmdAdminOSSystemExtensionsAction{
+					os:          c.os,
+					action:      "delete",
+					description: "Delete a system extension",
+					method:      "DELETE",
+					url:         "/os/1.0/system/extensions/%s",
+					confirm:     "delete the system extension",
+				}
+
+				return []*cobra.Command{activateCmd.command(), deactivateCmd.command(), deleteCmd.command(), uploadCmd.command()}
+			},
+		},
+		{
+			name:        "kernel",
+			description: "Kernel configuration",
+			isWritable:  true,
+		},
 		{
 			name:        "logging",
 			description: "System logging",
 			isWritable:  true,
+			extraCommands: func() []*cobra.Command {
+				// Audit log.
+				auditCmd := cmdAdminOSSystemLoggingAudit{os: c.os}
+
+				return []*cobra.Command{auditCmd.command()}
+			},
 		},
 		{
 			name:        "network",
@@ -120,7 +171,187 @@ func (c *cmdAdminOSSystem) command() *cobra.Command {
 					endpoint:    "system/security",
 				}
 
-				return []*cobra.Command{tpmRebindCmd.command()}
+				// TPM attestation.
+				attestCmd := cmdGenericRun{
+					os:            c.os,
+					action:        "attest",
+					description:   "Get a signed TPM attestation of the system",
+					endpoint:      "system/security",
+					hasData:       true,
+					hasFileOutput: true,
+				}
+
+				// TPM unlock failure warning.
+				clearTPMUnlockWarningCmd := cmdGenericRun{
+					os:          c.os,
+					action:      "clear-tpm-unlock-warning",
+					description: "Clear the warning raised after the TPM failed to unlock the encrypted volumes",
+					endpoint:    "system/security",
+				}
+
+				// Security posture report.
+				postureShowCmd := cmdGenericShow{
+					os:       c.os,
+					endpoint: "system/security/posture",
+				}
+
+				postureCmd := postureShowCmd.command()
+				postureCmd.Use = strings.Replace(postureCmd.Use, "show", "posture", 1)
+				postureCmd.Short = "Get the security posture report"
+				postureCmd.Long = cli.FormatSection("Description", postureCmd.Short)
+
+				// Software bill of materials.
+				sbomCmd := cmdAdminOSSystemSecuritySBOM{os: c.os}
+
+				// Local secret deletion.
+				deleteSecretCmd := cmdGenericRun{
+					os:          c.os,
+					action:      "delete-secret",
+					description: "Delete a locally stored secret",
+					endpoint:    "system/security",
+					hasData:     true,
+					confirm:     "delete the secret",
+				}
+
+				// FIDO2 token enrollment.
+				fido2EnrollCmd := cmdGenericRun{
+					os:          c.os,
+					action:      "fido2-enroll",
+					description: "Enroll a FIDO2 token to unlock the encrypted volumes",
+					endpoint:    "system/security",
+					hasData:     true,
+				}
+
+				// FIDO2 token removal.
+				fido2RemoveCmd := cmdGenericRun{
+					os:          c.os,
+					action:      "fido2-remove",
+					description: "Remove all enrolled FIDO2 tokens",
+					endpoint:    "system/security",
+					confirm:     "remove all FIDO2 tokens",
+				}
+
+				// Recovery key generation.
+				generateRecoveryKeyCmd := cmdGenericRun{
+					os:            c.os,
+					action:        "generate-recovery-key",
+					description:   "Generate an additional encryption recovery key",
+					endpoint:      "system/security",
+					hasFileOutput: true,
+				}
+
+				// Recovery key invalidation.
+				invalidateRecoveryKeyCmd := cmdGenericRun{
+					os:          c.os,
+					action:      "invalidate-recovery-key",
+					description: "Invalidate an encryption recovery key",
+					endpoint:    "system/security",
+					hasData:     true,
+					confirm:     "invalidate the recovery key",
+				}
+
+				// Recovery key rotation.
+				rotateRecoveryKeysCmd := cmdGenericRun{
+					os:            c.os,
+					action:        "rotate-recovery-keys",
+					description:   "Replace all encryption recovery keys with a newly generated one",
+					endpoint:      "system/security",
+					confirm:       "invalidate all existing recovery keys",
+					hasFileOutput: true,
+				}
+
+				// Secure Boot key enrollment.
+				secureBootEnrollCmd := cmdGenericRun{
+					os:          c.os,
+					action:      "secureboot-enroll",
+					description: "Enroll a signed Secure Boot key update",
+					endpoint:    "system/security",
+					hasData:     true,
+					confirm:     "update the Secure Boot keys",
+				}
+
+				// CA certificate addition.
+				addCACertificateCmd := cmdGenericRun{
+					os:          c.os,
+					action:      "add-ca-certificate",
+					description: "Add a CA certificate to the system trust store",
+					endpoint:    "system/security",
+					hasData:     true,
+				}
+
+				// Trusted client certificate addition.
+				addTrustedCertificateCmd := cmdGenericRun{
+					os:          c.os,
+					action:      "add-trusted-certificate",
+					description: "Trust a client certificate for the HTTPS API",
+					endpoint:    "system/security",
+					hasData:     true,
+				}
+
+				// Lockdown mode.
+				lockCmd := cmdGenericRun{
+					os:          c.os,
+					action:      "lock",
+					description: "Put the system in lockdown mode, rejecting any change until unlocked",
+					endpoint:    "system/security",
+					confirm:     "reject any change to the system until unlocked",
+				}
+
+				unlockCmd := cmdGenericRun{
+					os:          c.os,
+					action:      "unlock",
+					description: "Leave lockdown mode, allowing changes to the system again",
+					endpoint:    "system/security",
+				}
+
+				// CA certificate removal.
+				removeCACertificateCmd := cmdGenericRun{
+					os:          c.os,
+					action:      "remove-ca-certificate",
+					description: "Remove a CA certificate from the system trust store",
+					endpoint:    "system/security",
+					hasData:     true,
+					confirm:     "remove the CA certificate",
+				}
+
+				// Trusted client certificate removal.
+				removeTrustedCertificateCmd := cmdGenericRun{
+					os:          c.os,
+					action:      "remove-trusted-certificate",
+					description: "Revoke a trusted client certificate",
+					endpoint:    "system/security",
+					hasData:     true,
+					confirm:     "revoke the client certificate",
+				}
+
+				// Local secret storage.
+				setSecretCmd := cmdGenericRun{
+					os:          c.os,
+					action:      "set-secret",
+					description: "Store a secret locally, sealed by the TPM",
+					endpoint:    "system/security",
+					hasData:     true,
+				}
+
+				// TPM re-seal.
+				tpmResealCmd := cmdGenericRun{
+					os:          c.os,
+					action:      "tpm-reseal",
+					description: "Re-seal the encrypted volumes to the current TPM measurements",
+					endpoint:    "system/security",
+					confirm:     "re-seal the encrypted volumes to the current TPM measurements",
+				}
+
+				// USB device authorization.
+				usbAuthorizeCmd := cmdGenericRun{
+					os:          c.os,
+					action:      "usb-authorize",
+					description: "Authorize a blocked USB device",
+					endpoint:    "system/security",
+					hasData:     true,
+				}
+
+				return []*cobra.Command{addCACertificateCmd.command(), addTrustedCertificateCmd.command(), attestCmd.command(), clearTPMUnlockWarningCmd.command(), deleteSecretCmd.command(), fido2EnrollCmd.command(), fido2RemoveCmd.command(), generateRecoveryKeyCmd.command(), invalidateRecoveryKeyCmd.command(), lockCmd.command(), postureCmd, removeCACertificateCmd.command(), removeTrustedCertificateCmd.command(), rotateRecoveryKeysCmd.command(), sbomCmd.command(), secureBootEnrollCmd.command(), setSecretCmd.command(), tpmRebindCmd.command(), tpmResealCmd.command(), unlockCmd.command(), usbAuthorizeCmd.command()}
 			},
 		},
 		{
@@ -189,7 +420,38 @@ func (c *cmdAdminOSSystem) command() *cobra.Command {
 					hasData:     true,
 				}
 
-				return []*cobra.Command{createVolumeCmd.command(), deletePoolCmd.command(), deleteVolumeCmd.command(), importPoolCmd.command(), wipeDriveCmd.command(), scrubPoolCmd.command()}
+				// Update storage volume.
+				updateVolumeCmd := cmdGenericRun{
+					os:          c.os,
+					name:        "update-volume",
+					description: "Update the storage volume properties",
+					action:      "update-volume",
+					endpoint:    "system/storage",
+					hasData:     true,
+				}
+
+				// Discard pool checkpoint.
+				discardCheckpointCmd := cmdGenericRun{
+					os:          c.os,
+					name:        "discard-checkpoint",
+					description: "Discard the storage pool checkpoint",
+					action:      "discard-checkpoint",
+					endpoint:    "system/storage",
+					hasData:     true,
+				}
+
+				// Rewind pool to checkpoint.
+				rewindCheckpointCmd := cmdGenericRun{
+					os:          c.os,
+					name:        "rewind-checkpoint",
+					description: "Rewind the storage pool to its checkpoint",
+					action:      "rewind-checkpoint",
+					endpoint:    "system/storage",
+					hasData:     true,
+					confirm:     "rewind the storage pool, losing all changes made since the checkpoint",
+				}
+
+				return []*cobra.Command{createVolumeCmd.command(), deletePoolCmd.command(), deleteVolumeCmd.command(), importPoolCmd.command(), wipeDriveCmd.command(), scrubPoolCmd.command(), updateVolumeCmd.command(), discardCheckpointCmd.command(), rewindCheckpointCmd.command()}
 			},
 		},
 		{
@@ -206,7 +468,59 @@ func (c *cmdAdminOSSystem) command() *cobra.Command {
 					endpoint:    "system/update",
 				}
 
-				return []*cobra.Command{checkUpdatesCmd.command()}
+				// Roll back to the previous OS version.
+				rollbackCmd := cmdGenericRun{
+					os:          c.os,
+					action:      "rollback",
+					name:        "rollback",
+					description: "Roll back to the previous OS version",
+					endpoint:    "system/update",
+					confirm:     "reboot the system into the previous OS version",
+				}
+
+				// Stage the latest OS update.
+				stageCmd := cmdGenericRun{
+					os:          c.os,
+					action:      "stage",
+					name:        "stage",
+					description: "Download the latest OS update without applying it",
+					endpoint:    "system/update",
+				}
+
+				// Apply the staged OS update.
+				applyCmd := cmdGenericRun{
+					os:          c.os,
+					action:      "apply",
+					name:        "apply",
+					description: "Apply the staged OS update",
+					endpoint:    "system/update",
+					hasData:     true,
+					defaultData: "{}",
+				}
+
+				// Export the latest release as an update bundle.
+				exportBundleCmd := cmdGenericRun{
+					os:            c.os,
+					action:        "export-bundle",
+					name:          "export-bundle",
+					description:   "Export the latest release as an update bundle for air-gapped systems",
+					endpoint:      "system/update",
+					hasData:       true,
+					defaultData:   "{}",
+					hasFileOutput: true,
+				}
+
+				// Import an update bundle.
+				importBundleCmd := cmdGenericRun{
+					os:           c.os,
+					action:       "import-bundle",
+					name:         "import-bundle",
+					description:  "Import an update bundle",
+					endpoint:     "system/update",
+					hasFileInput: true,
+				}
+
+				return []*cobra.Command{checkUpdatesCmd.command(), rollbackCmd.command(), stageCmd.command(), applyCmd.command(), exportBundleCmd.command(), importBundleCmd.command()}
 			},
 		},
 	}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+
+	"github.com/lxc/incus/v6/shared/ask"
+	cli "github.com/lxc/incus/v6/shared/cmd"
+	"github.com/spf13/cobra"
+)
+
+// Side-loaded system extension actions.
+type cmdAdminOSSystemExtensionsAction struct {
+	os *cmdAdminOS
+
+	action      string
+	description string
+	method      string
+	url         string
+	confirm     string
+	hasFile     bool
+}
+
+func (c *cmdAdminOSSystemExtensionsAction) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	if c.hasFile {
+		cmd.Use = cli.Usage(c.action, "[<remote>:]<name> <file>")
+	} else {
+		cmd.Use = cli.Usage(c.action, "[<remote>:]<name>")
+	}
+
+	cmd.Short = c.description
+
+	cmd.Long = cli.FormatSection("Description", c.description)
+	if c.os.args.SupportsTarget {
+		cmd.Flags().StringVar(&c.os.flagTarget, "target", "", "Cluster member name``")
+	}
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdAdminOSSystemExtensionsAction) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	expectedArgs := 1
+	if c.hasFile {
+		expectedArgs = 2
+	}
+
+	exit, err := cli.CheckArgs(cmd, args, expectedArgs, expectedArgs)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote, name := parseRemote(args[0])
+
+	// Ask for confirmation.
+	if c.confirm != "" {
+		asker := ask.NewAsker(bufio.NewReader(os.Stdin))
+
+		confirm, err := asker.AskBool(fmt.Sprintf("Are you sure you want to %s? (yes/no) [default=no]: ", c.confirm), "no")
+		if err != nil {
+			return err
+		}
+
+		if !confirm {
+			return nil
+		}
+	}
+
+	// Prepare the URL.
+	u, err := url.Parse(fmt.Sprintf(c.url, url.PathEscape(name)))
+	if err != nil {
+		return err
+	}
+
+	values := u.Query()
+	if c.os.flagTarget != "" {
+		values.Set("target", c.os.flagTarget)
+	}
+
+	u.RawQuery = values.Encode()
+
+	// Send the image along if needed.
+	var inData io.Reader
+
+	if c.hasFile {
+		f, err := os.Open(args[1])
+		if err != nil {
+			return err
+		}
+
+		defer f.Close()
+
+		inData = f
+	}
+
+	_, _, err = doQuery(c.os.args.DoHTTP, remote, c.method, u.String(), inData, nil, "")
+
+	return err
+}
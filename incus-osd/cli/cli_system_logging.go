@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	cli "github.com/lxc/incus/v6/shared/cmd"
+	"github.com/spf13/cobra"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// Audit log.
+type cmdAdminOSSystemLoggingAudit struct {
+	os *cmdAdminOS
+
+	flagSince   string
+	flagEntries string
+}
+
+func (c *cmdAdminOSSystemLoggingAudit) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.Usage("audit")
+	cmd.Short = "Get the audit log"
+
+	cmd.Long = cli.FormatSection("Description", "Get the audit log")
+	if c.os.args.SupportsTarget {
+		cmd.Flags().StringVar(&c.os.flagTarget, "target", "", "Cluster member name``")
+	}
+
+	cmd.Flags().StringVarP(&c.flagSince, "since", "s", "", "Only show entries after this RFC3339 timestamp``")
+	cmd.Flags().StringVarP(&c.flagEntries, "entries", "n", "", "Number of entries``")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdAdminOSSystemLoggingAudit) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := cli.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) > 0 {
+		remote, _ = parseRemote(args[0])
+	}
+
+	// Prepare the URL.
+	u, err := url.Parse("/os/1.0/system/logging/audit")
+	if err != nil {
+		return err
+	}
+
+	values := u.Query()
+	if c.os.flagTarget != "" {
+		values.Set("target", c.os.flagTarget)
+	}
+
+	if c.flagSince != "" {
+		values.Set("since", c.flagSince)
+	}
+
+	if c.flagEntries != "" {
+		values.Set("entries", c.flagEntries)
+	}
+
+	u.RawQuery = values.Encode()
+
+	// Get the audit log.
+	resp, _, err := doQuery(c.os.args.DoHTTP, remote, "GET", u.String(), nil, nil, "")
+	if err != nil {
+		return err
+	}
+
+	var entries []api.SystemLoggingAuditEntry
+
+	err = resp.MetadataAsStruct(&entries)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		identity := entry.Identity
+		if identity == "" {
+			identity = "-"
+		}
+
+		_, _ = fmt.Printf("[%s] %s %s %s (%d)\n", entry.Timestamp, identity, entry.Method, entry.Path, entry.StatusCode) //nolint:forbidigo
+
+		for _, change := range entry.Changes {
+			_, _ = fmt.Printf("    %s: %s -> %s\n", change.Field, formatAuditValue(change.OldValue), formatAuditValue(change.NewValue)) //nolint:forbidigo
+		}
+	}
+
+	return nil
+}
+
+// formatAuditValue renders a changed value on a single line.
+func formatAuditValue(value any) string {
+	if value == nil {
+		return "(unset)"
+	}
+
+	return strings.ReplaceAll(fmt.Sprintf("%v", value), "\n", " ")
+}
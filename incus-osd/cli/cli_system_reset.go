@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/url"
+	"os"
+
+	"github.com/lxc/incus/v6/shared/ask"
+	cli "github.com/lxc/incus/v6/shared/cmd"
+	"github.com/spf13/cobra"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// Factory reset.
+type cmdAdminOSSystemFactoryReset struct {
+	os *cmdAdminOS
+
+	flagData     string
+	flagKeepData bool
+}
+
+func (c *cmdAdminOSSystemFactoryReset) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.Usage("factory-reset")
+	cmd.Short = "Factory reset the system"
+
+	cmd.Long = cli.FormatSection("Description", "Factory reset the system")
+	if c.os.args.SupportsTarget {
+		cmd.Flags().StringVar(&c.os.flagTarget, "target", "", "Cluster member name``")
+	}
+
+	cmd.Flags().StringVarP(&c.flagData, "data", "d", "{}", "Command data``")
+	cmd.Flags().BoolVar(&c.flagKeepData, "keep-data", false, "Keep the local storage pool")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdAdminOSSystemFactoryReset) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := cli.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) > 0 {
+		remote, _ = parseRemote(args[0])
+	}
+
+	resetData := api.SystemReset{}
+
+	err = json.Unmarshal([]byte(c.flagData), &resetData)
+	if err != nil {
+		return err
+	}
+
+	if c.flagKeepData {
+		resetData.KeepData = true
+	}
+
+	// Ask for confirmation.
+	msg := "Are you sure you want to factory-reset the system, wiping the local storage pool? (yes/no) [default=no]: "
+	if resetData.KeepData {
+		msg = "Are you sure you want to factory-reset the system? (yes/no) [default=no]: "
+	}
+
+	asker := ask.NewAsker(bufio.NewReader(os.Stdin))
+
+	confirm, err := asker.AskBool(msg, "no")
+	if err != nil {
+		return err
+	}
+
+	if !confirm {
+		return nil
+	}
+
+	// Prepare the URL.
+	u, err := url.Parse("/os/1.0/system/:factory-reset")
+	if err != nil {
+		return err
+	}
+
+	values := u.Query()
+	if c.os.flagTarget != "" {
+		values.Set("target", c.os.flagTarget)
+	}
+
+	u.RawQuery = values.Encode()
+
+	// Get a confirmation token, then perform the reset.
+	resetData.RequestConfirmation = true
+	resetData.ConfirmationToken = ""
+
+	resp, _, err := doQuery(c.os.args.DoHTTP, remote, "POST", u.String(), resetData, nil, "")
+	if err != nil {
+		return err
+	}
+
+	confirmation := api.SystemResetConfirmation{}
+
+	err = resp.MetadataAsStruct(&confirmation)
+	if err != nil {
+		return err
+	}
+
+	resetData.RequestConfirmation = false
+	resetData.ConfirmationToken = confirmation.Token
+
+	_, _, err = doQuery(c.os.args.DoHTTP, remote, "POST", u.String(), resetData, nil, "")
+
+	return err
+}
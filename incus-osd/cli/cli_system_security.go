@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+	"net/url"
+
+	cli "github.com/lxc/incus/v6/shared/cmd"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// Software bill of materials.
+type cmdAdminOSSystemSecuritySBOM struct {
+	os *cmdAdminOS
+
+	flagVulnerabilities bool
+}
+
+func (c *cmdAdminOSSystemSecuritySBOM) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = cli.Usage("sbom")
+	cmd.Short = "Get the software bill of materials"
+
+	cmd.Long = cli.FormatSection("Description", "Get the software bill of materials")
+	if c.os.args.SupportsTarget {
+		cmd.Flags().StringVar(&c.os.flagTarget, "target", "", "Cluster member name``")
+	}
+
+	cmd.Flags().BoolVar(&c.flagVulnerabilities, "vulnerabilities", false, "Check the packages for known vulnerabilities")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdAdminOSSystemSecuritySBOM) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := cli.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) > 0 {
+		remote, _ = parseRemote(args[0])
+	}
+
+	// Prepare the URL.
+	u, err := url.Parse("/os/1.0/system/security/sbom")
+	if err != nil {
+		return err
+	}
+
+	values := u.Query()
+	if c.os.flagTarget != "" {
+		values.Set("target", c.os.flagTarget)
+	}
+
+	if c.flagVulnerabilities {
+		values.Set("vulnerabilities", "true")
+	}
+
+	u.RawQuery = values.Encode()
+
+	// Get the software bill of materials.
+	resp, _, err := doQuery(c.os.args.DoHTTP, remote, "GET", u.String(), nil, nil, "")
+	if err != nil {
+		return err
+	}
+
+	var bom api.SystemSecuritySBOM
+
+	err = resp.MetadataAsStruct(&bom)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(bom)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Printf("%s", data) //nolint:forbidigo
+
+	return nil
+}
@@ -72,10 +72,14 @@ type apiImagesPostSeeds struct {
 	Applications     *apiseed.Applications     `json:"applications"      yaml:"applications"`
 	Incus            *apiseed.Incus            `json:"incus"             yaml:"incus"`
 	Install          *apiseed.Install          `json:"install"           yaml:"install"`
+	LogForward       *apiseed.LogForward       `json:"logforward"        yaml:"logforward"`
 	MigrationManager *apiseed.MigrationManager `json:"migration-manager" yaml:"migration-manager"` //nolint:tagliatelle
 	OperationsCenter *apiseed.OperationsCenter `json:"operations-center" yaml:"operations-center"` //nolint:tagliatelle
 	Network          *apiseed.Network          `json:"network"           yaml:"network"`
 	Provider         *apiseed.Provider         `json:"provider"          yaml:"provider"`
+	Security         *apiseed.Security         `json:"security"          yaml:"security"`
+	SSH              *apiseed.SSH              `json:"ssh"               yaml:"ssh"`
+	Tailscale        *apiseed.Tailscale        `json:"tailscale"         yaml:"tailscale"`
 }
 
 func main() {
@@ -572,6 +576,16 @@ func writeSeed(writer io.Writer, seeds apiImagesPostSeeds) (int, error) {
 		archiveContents = append(archiveContents, []string{"install.yaml", string(yamlContents)})
 	}
 
+	// Create logforward yaml contents.
+	if seeds.LogForward != nil {
+		yamlContents, err := yaml.Marshal(seeds.LogForward)
+		if err != nil {
+			return -1, err
+		}
+
+		archiveContents = append(archiveContents, []string{"logforward.yaml", string(yamlContents)})
+	}
+
 	// Create network yaml contents.
 	if seeds.Network != nil {
 		yamlContents, err := yaml.Marshal(seeds.Network)
@@ -592,6 +606,36 @@ func writeSeed(writer io.Writer, seeds apiImagesPostSeeds) (int, error) {
 		archiveContents = append(archiveContents, []string{"provider.yaml", string(yamlContents)})
 	}
 
+	// Create security yaml contents.
+	if seeds.Security != nil {
+		yamlContents, err := yaml.Marshal(seeds.Security)
+		if err != nil {
+			return -1, err
+		}
+
+		archiveContents = append(archiveContents, []string{"security.yaml", string(yamlContents)})
+	}
+
+	// Create ssh yaml contents.
+	if seeds.SSH != nil {
+		yamlContents, err := yaml.Marshal(seeds.SSH)
+		if err != nil {
+			return -1, err
+		}
+
+		archiveContents = append(archiveContents, []string{"ssh.yaml", string(yamlContents)})
+	}
+
+	// Create tailscale yaml contents.
+	if seeds.Tailscale != nil {
+		yamlContents, err := yaml.Marshal(seeds.Tailscale)
+		if err != nil {
+			return -1, err
+		}
+
+		archiveContents = append(archiveContents, []string{"tailscale.yaml", string(yamlContents)})
+	}
+
 	// Put a size counter in place.
 	wc := &writeCounter{}
 
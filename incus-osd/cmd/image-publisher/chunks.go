@@ -0,0 +1,154 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	apiupdate "github.com/lxc/incus-os/incus-osd/api/images"
+	"github.com/lxc/incus-os/incus-osd/internal/delta"
+)
+
+// generateChunkIndex splits a compressed asset into chunks, adding them to the chunk store, and writes
+// its compressed chunk index. It returns the hash and size of the written chunk index.
+func generateChunkIndex(assetPath string, indexPath string, storePath string) (string, int64, error) {
+	// Open the asset.
+	f, err := os.Open(assetPath) //nolint:gosec
+	if err != nil {
+		return "", 0, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	rc, err := gzip.NewReader(f)
+	if err != nil {
+		return "", 0, err
+	}
+
+	defer func() { _ = rc.Close() }()
+
+	// Chunk the asset.
+	index, err := delta.CreateIndex(rc, storePath)
+	if err != nil {
+		return "", 0, err
+	}
+
+	// Write the chunk index.
+	fd, err := os.Create(indexPath) //nolint:gosec
+	if err != nil {
+		return "", 0, err
+	}
+
+	defer func() { _ = fd.Close() }()
+
+	hash256 := sha256.New()
+	gz := gzip.NewWriter(io.MultiWriter(fd, hash256))
+
+	err = json.NewEncoder(gz).Encode(index)
+	if err != nil {
+		return "", 0, err
+	}
+
+	err = gz.Close()
+	if err != nil {
+		return "", 0, err
+	}
+
+	info, err := fd.Stat()
+	if err != nil {
+		return "", 0, err
+	}
+
+	return hex.EncodeToString(hash256.Sum(nil)), info.Size(), nil
+}
+
+// pruneChunks removes the chunks no longer referenced by any chunk index.
+func pruneChunks(ctx context.Context, targetPath string) error {
+	storePath := filepath.Join(targetPath, "chunks")
+
+	_, err := os.Stat(storePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	// Get all the referenced chunks.
+	indexPaths, err := filepath.Glob(filepath.Join(targetPath, "*", "*", "*.chunks.gz"))
+	if err != nil {
+		return err
+	}
+
+	referenced := map[string]bool{}
+
+	for _, indexPath := range indexPaths {
+		index, err := readChunkIndex(indexPath)
+		if err != nil {
+			return err
+		}
+
+		for _, chunk := range index.Chunks {
+			referenced[filepath.Join(storePath, delta.ChunkPath(chunk.Sha256))] = true
+		}
+	}
+
+	// Remove the others.
+	count := 0
+
+	err = filepath.WalkDir(storePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || !strings.HasSuffix(path, ".gz") || referenced[path] {
+			return nil
+		}
+
+		count++
+
+		return os.Remove(path)
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Removed unused chunks", "count", count)
+
+	return nil
+}
+
+// readChunkIndex reads a compressed chunk index.
+func readChunkIndex(indexPath string) (*apiupdate.ChunkIndex, error) {
+	f, err := os.Open(indexPath) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	rc, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = rc.Close() }()
+
+	index := &apiupdate.ChunkIndex{}
+
+	err = json.NewDecoder(rc).Decode(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return index, nil
+}
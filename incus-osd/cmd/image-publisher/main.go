@@ -34,6 +34,10 @@ func main() {
 		Hidden: true,
 	})
 
+	// bundle sub-command.
+	bundleCmd := cmdBundle{global: &globalCmd}
+	app.AddCommand(bundleCmd.command())
+
 	// demote sub-command.
 	demoteCmd := cmdDemote{global: &globalCmd}
 	app.AddCommand(demoteCmd.command())
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	apiupdate "github.com/lxc/incus-os/incus-osd/api/images"
+)
+
+type cmdBundle struct {
+	global *cmdGlobal
+}
+
+func (c *cmdBundle) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "bundle <path> <target> [<channel>]"
+	cmd.Short = "Export an update bundle"
+	cmd.Long = formatSection("Description",
+		`Export an update bundle
+
+This will write a tar archive holding the signed index and the latest
+image of the channel (or the latest image overall), suitable for import
+on air-gapped systems using the bundle provider.
+`)
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdBundle) run(cmd *cobra.Command, args []string) error {
+	ctx := context.TODO()
+
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 3)
+	if exit {
+		return err
+	}
+
+	channel := ""
+	if len(args) > 2 {
+		channel = args[2]
+	}
+
+	// Read the index.
+	var metaIndex apiupdate.Index
+
+	metaFile, err := os.Open(filepath.Join(args[0], "index.json"))
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = metaFile.Close() }()
+
+	err = json.NewDecoder(metaFile).Decode(&metaIndex)
+	if err != nil {
+		return err
+	}
+
+	// Find the image to export.
+	var latestUpdate *apiupdate.UpdateFull
+
+	for _, update := range metaIndex.Updates {
+		if channel != "" && !slices.Contains(update.Channels, channel) {
+			continue
+		}
+
+		latestUpdate = &update
+
+		break
+	}
+
+	if latestUpdate == nil {
+		return errors.New("no image available for the channel")
+	}
+
+	slog.InfoContext(ctx, "Exporting update bundle", "image", latestUpdate.Version)
+
+	// Write the archive.
+	target, err := os.Create(args[1])
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = target.Close() }()
+
+	tw := tar.NewWriter(target)
+
+	err = bundleAddFile(tw, filepath.Join(args[0], "index.sjson"), "index.sjson")
+	if err != nil {
+		return err
+	}
+
+	for _, file := range latestUpdate.Files {
+		err = bundleAddFile(tw, filepath.Join(args[0], latestUpdate.Version, file.Filename), latestUpdate.Version+"/"+file.Filename)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return err
+	}
+
+	return target.Close()
+}
+
+// bundleAddFile adds a file to the update bundle.
+func bundleAddFile(tw *tar.Writer, path string, name string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	hdr, err := tar.FileInfoHeader(fi, "")
+	if err != nil {
+		return err
+	}
+
+	hdr.Name = name
+
+	err = tw.WriteHeader(hdr)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, f)
+
+	return err
+}
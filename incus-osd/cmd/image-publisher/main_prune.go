@@ -25,7 +25,7 @@ func (c *cmdPrune) command() *cobra.Command {
 		`Prunes the image server
 
 This will prune images that aren't needed to satisfy the specified
-per-channel retention policy.
+per-channel retention policy, along with the chunks only used by them.
 `)
 	cmd.RunE = c.run
 
@@ -85,6 +85,12 @@ func (c *cmdPrune) run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Remove the chunks only used by the removed images.
+	err = pruneChunks(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
 	// Re-generate the index.
 	return generateIndex(ctx, args[0])
 }
@@ -14,6 +14,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -350,6 +351,29 @@ func (*cmdSync) downloadImage(ctx context.Context, archName string, releaseURL *
 			Size:         assetSize,
 			Type:         assetType,
 		})
+
+		// Publish the chunk index of large files, allowing for delta updates.
+		if !slices.Contains([]apiupdate.UpdateFileType{apiupdate.UpdateFileTypeUpdateEFI, apiupdate.UpdateFileTypeUpdateUsr, apiupdate.UpdateFileTypeUpdateUsrVerity, apiupdate.UpdateFileTypeApplication}, assetType) {
+			continue
+		}
+
+		slog.InfoContext(ctx, "Generating chunk index", "name", assetName, "arch", archName)
+
+		indexName := strings.TrimSuffix(assetName, ".gz") + ".chunks.gz"
+
+		indexHash, indexSize, err := generateChunkIndex(filepath.Join(targetPath, archName, assetName), filepath.Join(targetPath, archName, indexName), filepath.Join(filepath.Dir(targetPath), "chunks")) //nolint:gosec
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, apiupdate.UpdateFile{
+			Architecture: apiupdate.UpdateFileArchitecture(archName),
+			Component:    assetComponent,
+			Filename:     filepath.Join(archName, indexName), //nolint:gosec
+			Sha256:       indexHash,
+			Size:         indexSize,
+			Type:         apiupdate.UpdateFileTypeChunkIndex,
+		})
 	}
 
 	return files, nil
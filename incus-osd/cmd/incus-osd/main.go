@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"math/rand/v2"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -20,8 +21,13 @@ import (
 	"github.com/lxc/incus/v6/shared/subprocess"
 	"golang.org/x/sys/unix"
 
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/apparmor"
 	"github.com/lxc/incus-os/incus-osd/internal/applications"
+	"github.com/lxc/incus-os/incus-osd/internal/extensions"
+	"github.com/lxc/incus-os/incus-osd/internal/health"
 	"github.com/lxc/incus-os/incus-osd/internal/install"
+	"github.com/lxc/incus-os/incus-osd/internal/kernel"
 	"github.com/lxc/incus-os/incus-osd/internal/keyring"
 	"github.com/lxc/incus-os/incus-osd/internal/nftables"
 	"github.com/lxc/incus-os/incus-osd/internal/providers"
@@ -33,7 +39,9 @@ import (
 	"github.com/lxc/incus-os/incus-osd/internal/state"
 	"github.com/lxc/incus-os/incus-osd/internal/storage"
 	"github.com/lxc/incus-os/incus-osd/internal/systemd"
+	"github.com/lxc/incus-os/incus-osd/internal/truststore"
 	"github.com/lxc/incus-os/incus-osd/internal/tui"
+	"github.com/lxc/incus-os/incus-osd/internal/usb"
 	"github.com/lxc/incus-os/incus-osd/internal/zfs"
 )
 
@@ -228,6 +236,17 @@ func run(ctx context.Context, s *state.State, t *tui.TUI) error {
 	slog.InfoContext(ctx, "System is ready", "version", s.OS.RunningRelease)
 	s.OS.SuccessfulBoot = true
 
+	// Check the health of a newly installed OS version, discarding the storage pool checkpoints
+	// taken ahead of the update once it's healthy.
+	go func() {
+		if health.Monitor(ctx, s) {
+			zfs.DiscardUpdateCheckpoints(ctx, s)
+		}
+	}()
+
+	// Keep the incus-osd watchdog fed while the API responds.
+	go systemd.RunWatchdog(ctx, s, filepath.Join(runPath, "unix.socket"))
+
 	// Wait for the API to go down.
 	return <-chErr
 }
@@ -318,7 +337,7 @@ func startup(ctx context.Context, s *state.State, t *tui.TUI) error { //nolint:r
 	if len(s.System.Security.Config.EncryptionRecoveryKeys) == 0 {
 		slog.InfoContext(ctx, "Auto-generating encryption recovery key, this may take a few seconds")
 
-		err := systemd.GenerateRecoveryKey(ctx, s)
+		_, err := systemd.GenerateRecoveryKey(ctx, s, systemd.RecoveryKeyCreatedByInstall)
 		if err != nil {
 			return err
 		}
@@ -337,11 +356,65 @@ func startup(ctx context.Context, s *state.State, t *tui.TUI) error { //nolint:r
 		slog.WarnContext(ctx, "Degraded security state: no physical TPM found, using swtpm")
 	}
 
+	// Enable IMA measurement as early as possible, so the log covers most of the executed binaries.
+	if s.System.Security.Config.IMAMeasurement {
+		err := secureboot.EnableIMAMeasurement()
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to enable IMA measurement: "+err.Error())
+		}
+	}
+
+	// Confine the managed subprocesses before any of them gets started.
+	err = apparmor.ApplyProfiles(ctx, s.System.Security.Config.AppArmor)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to apply AppArmor profiles: "+err.Error())
+	}
+
+	// Prevent the denied kernel modules from being loaded.
+	err = kernel.ApplyModuleDenials(s.System.Security.Config.Kernel)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to apply the kernel module denials: "+err.Error())
+	}
+
+	// On first boot, trust the CA certificates from the security seed, if any.
+	if s.System.Network.Config == nil && len(s.System.Security.Config.CACertificates) == 0 {
+		securitySeed, err := seed.GetSecurity(ctx)
+		if err != nil && !seed.IsMissing(err) {
+			return err
+		}
+
+		if securitySeed != nil && len(securitySeed.CACertificates) > 0 {
+			err = truststore.Validate(securitySeed.CACertificates)
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed configuring CA certificates from seed", "err", err)
+			} else {
+				slog.InfoContext(ctx, "Configuring CA certificates from seed")
+
+				s.System.Security.Config.CACertificates = securitySeed.CACertificates
+			}
+		}
+	}
+
+	// Update the system trust store before any TLS connection is made, as it's only loaded once.
+	err = truststore.Apply(s.System.Security.Config.CACertificates)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to update the system trust store: "+err.Error())
+	}
+
 	// Display a warning if we're running from the backup image.
 	if s.OS.NextRelease != "" && s.OS.RunningRelease != s.OS.NextRelease {
 		slog.WarnContext(ctx, "Booted from backup "+s.OS.Name+" image version "+s.OS.RunningRelease)
 	}
 
+	// Record the boot, along with why the system fell back to the backup image if it did.
+	systemd.RecordBoot(s)
+
+	// Keep the side-loaded system extensions in use, provided they're still trusted.
+	err = extensions.Restore(ctx, s)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to restore system extensions: "+err.Error())
+	}
+
 	// Check for and run recovery logic if present.
 	err = recovery.CheckRunRecovery(ctx, s)
 	if err != nil {
@@ -350,6 +423,10 @@ func startup(ctx context.Context, s *state.State, t *tui.TUI) error { //nolint:r
 		slog.ErrorContext(ctx, "Recovery failed: "+err.Error())
 	}
 
+	// Without a network configuration in the state, this is the system's first boot. The seeds only
+	// get applied then, so they don't override the configuration changes made since.
+	firstBoot := s.System.Network.Config == nil
+
 	// If there's no network configuration in the state, attempt to fetch from the seed info.
 	if s.System.Network.Config == nil {
 		s.System.Network.Config, err = seed.GetNetwork(ctx)
@@ -365,6 +442,19 @@ func startup(ctx context.Context, s *state.State, t *tui.TUI) error { //nolint:r
 		return err
 	}
 
+	// Raise a warning if the TPM couldn't unlock the encrypted volumes, as this may indicate tampering
+	// with the boot chain. The warning is logged, so it can be forwarded to a remote syslog server.
+	var sysinfo unix.Sysinfo_t
+
+	err = unix.Sysinfo(&sysinfo)
+	if err != nil {
+		return err
+	}
+
+	if systemd.RecordTPMUnlockFailure(s, time.Now().Add(-time.Duration(sysinfo.Uptime)*time.Second)) {
+		slog.WarnContext(ctx, "The TPM failed to unlock the encrypted volumes, a recovery passphrase was used", "failures", s.System.Security.State.TPMUnlockFailures)
+	}
+
 	// Sometimes the system may not be able to immediately check the provider for any updates.
 	// One such example is when Operations Center is installed and the underlying IncusOS system
 	// is registered to it as the provider. We need to wait until the Operations Center
@@ -377,22 +467,60 @@ func startup(ctx context.Context, s *state.State, t *tui.TUI) error { //nolint:r
 	// Perform network configuration.
 	slog.InfoContext(ctx, "Bringing up the network")
 
+	systemd.RestoreUnconfirmedNetworkConfiguration(ctx, s)
+
 	err = nftables.ApplyHwaddrFilters(ctx, s.System.Network.Config)
 	if err != nil {
 		return err
 	}
 
+	// Protect the management plane if a security seed with a host firewall was provided and the firewall was never configured.
+	if s.System.Security.Config.Firewall == nil {
+		securitySeed, err := seed.GetSecurity(ctx)
+		if err != nil && !seed.IsMissing(err) {
+			return err
+		}
+
+		if securitySeed != nil && securitySeed.Firewall != nil {
+			err = nftables.ValidateHostFirewall(securitySeed.Firewall)
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed configuring host firewall from seed", "err", err)
+			} else {
+				slog.InfoContext(ctx, "Configuring host firewall from seed")
+
+				s.System.Security.Config.Firewall = securitySeed.Firewall
+			}
+		}
+	}
+
 	err = systemd.ApplyNetworkConfiguration(ctx, s, s.System.Network.Config, 30*time.Second, s.OS.SuccessfulBoot, providers.Refresh, delayInitialUpdateCheck)
 	if err != nil {
 		return err
 	}
 
+	// Monitor the uplinks if failover is configured.
+	go systemd.RunUplinkFailover(ctx, s)
+
+	// Apply the USB device authorization policy.
+	err = usb.ApplyPolicy(ctx, s.System.Security.Config.USB)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed applying the USB device authorization policy", "err", err)
+	}
+
+	go usb.RunPolicy(ctx, s)
+
 	// Configure logging.
 	err = systemd.SetSyslog(ctx, s.System.Logging.Config.Syslog)
 	if err != nil {
 		return err
 	}
 
+	// Configure the hardware watchdog.
+	err = systemd.ApplyWatchdog(ctx, s.System.Watchdog.Config)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed configuring the hardware watchdog", "err", err)
+	}
+
 	// Get the provider.
 	var provider string
 
@@ -429,7 +557,90 @@ func startup(ctx context.Context, s *state.State, t *tui.TUI) error { //nolint:r
 
 	if !delayInitialUpdateCheck {
 		// Perform an initial blocking check for updates before proceeding.
-		updateChecker(ctx, s, t, p, true, false)
+		updateChecker(ctx, s, t, p, true, false, false)
+	}
+
+	// On first boot, join a tailnet if a Tailscale seed was provided.
+	if firstBoot {
+		tailscaleSeed, err := seed.GetTailscale(ctx)
+		if err != nil && !seed.IsMissing(err) {
+			return err
+		}
+
+		if tailscaleSeed != nil {
+			srv, err := services.Load(ctx, s, "tailscale")
+			if err != nil {
+				return err
+			}
+
+			slog.InfoContext(ctx, "Configuring Tailscale from seed")
+
+			err = srv.Update(ctx, &api.ServiceTailscale{Config: tailscaleSeed.ServiceTailscaleConfig})
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed configuring Tailscale from seed", "err", err)
+			}
+		}
+	}
+
+	// On first boot, forward the logs if a log forwarding seed was provided.
+	if firstBoot {
+		logForwardSeed, err := seed.GetLogForward(ctx)
+		if err != nil && !seed.IsMissing(err) {
+			return err
+		}
+
+		if logForwardSeed != nil {
+			srv, err := services.Load(ctx, s, "logforward")
+			if err != nil {
+				return err
+			}
+
+			slog.InfoContext(ctx, "Configuring log forwarding from seed")
+
+			err = srv.Update(ctx, &api.ServiceLogForward{Config: logForwardSeed.ServiceLogForwardConfig})
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed configuring log forwarding from seed", "err", err)
+			}
+		}
+	}
+
+	// On first boot, enable emergency SSH access if an SSH seed was provided.
+	if firstBoot {
+		sshSeed, err := seed.GetSSH(ctx)
+		if err != nil && !seed.IsMissing(err) {
+			return err
+		}
+
+		if sshSeed != nil {
+			srv, err := services.Load(ctx, s, "ssh")
+			if err != nil {
+				return err
+			}
+
+			slog.InfoContext(ctx, "Configuring SSH from seed")
+
+			err = srv.Update(ctx, &api.ServiceSSH{Config: sshSeed.ServiceSSHConfig})
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed configuring SSH from seed", "err", err)
+			}
+		}
+	}
+
+	// On first boot, bind the encrypted volumes to Tang servers if a security seed was provided.
+	if firstBoot {
+		securitySeed, err := seed.GetSecurity(ctx)
+		if err != nil && !seed.IsMissing(err) {
+			return err
+		}
+
+		if securitySeed != nil && securitySeed.NBDE != nil {
+			slog.InfoContext(ctx, "Configuring network-bound disk encryption from seed")
+
+			err = systemd.ApplyNBDEConfiguration(ctx, s, securitySeed.NBDE)
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed configuring network-bound disk encryption from seed", "err", err)
+			}
+		}
 	}
 
 	// Run services startup actions. This must be done before bringing up any storage pools.
@@ -451,6 +662,9 @@ func startup(ctx context.Context, s *state.State, t *tui.TUI) error { //nolint:r
 		}
 	}
 
+	// Monitor the LVM thin pools usage.
+	go services.RunLVMThinPoolMonitor(ctx, s)
+
 	// Ensure any locally-defined pools are available.
 	slog.InfoContext(ctx, "Bringing up the local storage")
 
@@ -467,9 +681,21 @@ func startup(ctx context.Context, s *state.State, t *tui.TUI) error { //nolint:r
 		}
 	}
 
+	// Restrict module loading and raise the kernel lockdown mode once everything has started.
+	err = kernel.ApplyRestrictions(ctx, s.System.Security.Config.Kernel)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to apply the kernel restrictions: "+err.Error())
+	}
+
+	// Serve the downloaded update files to peers.
+	err = providers.ApplyPeerCache(ctx, s)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to start the peer cache: "+err.Error())
+	}
+
 	// Run periodic update checks if we have a working provider.
 	if p != nil {
-		go updateChecker(ctx, s, t, p, false, false)
+		go updateChecker(ctx, s, t, p, false, false, false)
 	}
 
 	// Handle registration.
@@ -498,6 +724,7 @@ func startup(ctx context.Context, s *state.State, t *tui.TUI) error { //nolint:r
 	s.TriggerReboot = make(chan error, 1)
 	s.TriggerShutdown = make(chan error, 1)
 	s.TriggerUpdate = make(chan bool, 1)
+	s.TriggerStage = make(chan bool, 1)
 	chSignal := make(chan os.Signal, 1)
 	signal.Notify(chSignal, unix.SIGTERM)
 
@@ -513,7 +740,11 @@ func startup(ctx context.Context, s *state.State, t *tui.TUI) error { //nolint:r
 		case <-s.TriggerShutdown:
 			action = "shutdown"
 		case <-s.TriggerUpdate:
-			updateChecker(ctx, s, t, p, false, true)
+			updateChecker(ctx, s, t, p, false, true, false)
+
+			goto waitSignal
+		case <-s.TriggerStage:
+			updateChecker(ctx, s, t, p, false, true, true)
 
 			goto waitSignal
 		}
@@ -539,7 +770,7 @@ func startup(ctx context.Context, s *state.State, t *tui.TUI) error { //nolint:r
 		go func() {
 			time.Sleep(30 * time.Second)
 
-			updateChecker(ctx, s, t, p, true, false)
+			updateChecker(ctx, s, t, p, true, false, false)
 		}()
 	}
 
@@ -603,7 +834,7 @@ func startInitializeApplication(ctx context.Context, s *state.State, appName str
 	return nil
 }
 
-func updateChecker(ctx context.Context, s *state.State, t *tui.TUI, p providers.Provider, isStartupCheck bool, isUserRequested bool) { //nolint:revive
+func updateChecker(ctx context.Context, s *state.State, t *tui.TUI, p providers.Provider, isStartupCheck bool, isUserRequested bool, isStageRequested bool) { //nolint:revive
 	showModalError := func(msg string, err error) {
 		slog.ErrorContext(ctx, msg, "err", err.Error(), "provider", p.Type())
 
@@ -653,13 +884,26 @@ func updateChecker(ctx context.Context, s *state.State, t *tui.TUI, p providers.
 			if timeSinceCheck < frequency {
 				// Add one minute to the calculated sleep to protect against an edge case
 				// where we try to do an update check right at the start of a maintenance window.
-				time.Sleep(frequency - timeSinceCheck + 1*time.Minute)
+				delay := frequency - timeSinceCheck + 1*time.Minute
+
+				// Spread the checks of the systems sharing a provider over the configured jitter.
+				jitter := s.System.Update.Config.GetCheckJitter()
+				if jitter > 0 {
+					delay += rand.N(jitter) //nolint:gosec
+				}
+
+				nextCheck := time.Now().Add(delay)
+				s.System.Update.State.NextCheck = &nextCheck
+
+				time.Sleep(delay)
 			}
 		}
 
 		// Save when we last performed an update check.
 		s.System.Update.State.LastCheck = time.Now()
+		s.System.Update.State.NextCheck = nil
 		s.System.Update.State.Status = "Running update check"
+		s.System.Update.State.Progress = []api.SystemUpdateProgress{}
 
 		// Check maintenance window, except if we're performing a startup or manual check.
 		if !isStartupCheck && !isUserRequested {
@@ -692,8 +936,37 @@ func updateChecker(ctx context.Context, s *state.State, t *tui.TUI, p providers.
 			}
 		}
 
+		// Record the followed channel and the versions available in each channel.
+		s.System.Update.State.Channel = s.System.Update.Config.Channel
+
+		channelVersions, err := p.GetChannelVersions(ctx)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to get the versions available in each channel", "err", err.Error())
+		} else {
+			s.System.Update.State.AvailableVersions = channelVersions
+		}
+
+		// Don't apply any update while on hold, such as during a change freeze.
+		hold := s.System.Update.Config.Hold
+
+		s.System.Update.State.OnHold = hold.IsActive(time.Now())
+		if s.System.Update.State.OnHold {
+			s.System.Update.State.Status = "Updates are on hold until " + hold.Until
+			if hold.Reason != "" {
+				s.System.Update.State.Status += " (" + hold.Reason + ")"
+			}
+
+			slog.InfoContext(ctx, s.System.Update.State.Status)
+
+			if isStartupCheck || isUserRequested {
+				break
+			}
+
+			continue
+		}
+
 		// Check for and apply any Secure Boot key updates before performing any OS or application updates.
-		_, err := checkDownloadUpdate(ctx, s, t, p, "SecureBoot", "", isStartupCheck)
+		_, err = checkDownloadUpdate(ctx, s, t, p, "SecureBoot", "", isStartupCheck, false)
 		if err != nil {
 			s.System.Update.State.Status = "Failed to check for Secure Boot key updates"
 			showModalError(s.System.Update.State.Status, err)
@@ -760,7 +1033,15 @@ func updateChecker(ctx context.Context, s *state.State, t *tui.TUI, p providers.
 		appsUpdated := map[string]string{}
 
 		for _, appName := range toInstall {
-			newAppVersion, err := checkDownloadUpdate(ctx, s, t, p, "application", appName, isStartupCheck)
+			// Only check installed applications when due, following their update policy.
+			appInfo := s.Applications[appName]
+			if appInfo.State.Version != "" && !isUserRequested && !appInfo.Config.Update.IsCheckDue(appInfo.State.LastUpdateCheck, time.Now()) {
+				slog.DebugContext(ctx, "Skipping application update check until due", "application", appName)
+
+				continue
+			}
+
+			newAppVersion, err := checkDownloadUpdate(ctx, s, t, p, "application", appName, isStartupCheck, false)
 			if err != nil {
 				s.System.Update.State.Status = "Failed to check for application updates"
 				showModalError(s.System.Update.State.Status, err)
@@ -768,6 +1049,13 @@ func updateChecker(ctx context.Context, s *state.State, t *tui.TUI, p providers.
 				break
 			}
 
+			appInfo, ok := s.Applications[appName]
+			if ok && appInfo.State.Version != "" {
+				appInfo.State.LastUpdateCheck = time.Now().UTC().Format(time.RFC3339)
+				s.Applications[appName] = appInfo
+				_ = s.Save()
+			}
+
 			if newAppVersion != "" {
 				appsUpdated[appName] = newAppVersion
 			}
@@ -790,8 +1078,8 @@ func updateChecker(ctx context.Context, s *state.State, t *tui.TUI, p providers.
 			}
 		}
 
-		// Check for the latest OS update.
-		newInstalledOSVersion, err := checkDownloadUpdate(ctx, s, t, p, "OS", "", isStartupCheck)
+		// Check for the latest OS update, only staging it if requested or configured to.
+		newInstalledOSVersion, err := checkDownloadUpdate(ctx, s, t, p, "OS", "", isStartupCheck, isStageRequested || s.System.Update.Config.StageUpdates)
 		if err != nil {
 			s.System.Update.State.Status = "Failed to check for OS updates"
 			showModalError(s.System.Update.State.Status, err)
@@ -803,6 +1091,11 @@ func updateChecker(ctx context.Context, s *state.State, t *tui.TUI, p providers.
 			continue
 		}
 
+		// Check for firmware updates, only applying them outside of the startup check.
+		if s.System.Firmware.Config.Enabled {
+			checkFirmwareUpdates(ctx, s, !isStartupCheck)
+		}
+
 		// Notify the applications that they need to update/restart.
 		for appName, appVersion := range appsUpdated {
 			// Get the application.
@@ -846,9 +1139,27 @@ func updateChecker(ctx context.Context, s *state.State, t *tui.TUI, p providers.
 			}
 
 			s.System.Update.State.Status = s.OS.Name + " has been updated to version " + newInstalledOSVersion
-			updateModal.Update(s.OS.Name + " has been updated to version " + newInstalledOSVersion + ".\nPlease reboot the system to finalize update.")
-
 			s.System.Update.State.NeedsReboot = true
+
+			systemd.ScheduleUpdateReboot(ctx, s)
+
+			if s.System.Update.State.RebootScheduledAt != nil {
+				updateModal.Update(s.OS.Name + " has been updated to version " + newInstalledOSVersion + ".\nThe system will reboot to finalize update at " + s.System.Update.State.RebootScheduledAt.Format("2006-01-02 15:04") + ".")
+			} else {
+				updateModal.Update(s.OS.Name + " has been updated to version " + newInstalledOSVersion + ".\nPlease reboot the system to finalize update.")
+			}
+		} else if s.OS.StagedRelease != "" {
+			s.System.Update.State.Status = s.OS.Name + " update to version " + s.OS.StagedRelease + " is staged"
+
+			if updateModal != nil {
+				updateModal.Done()
+			}
+		} else if s.System.Update.Config.PinnedVersion != "" {
+			s.System.Update.State.Status = "Update check completed, " + s.OS.Name + " is pinned to version " + s.System.Update.Config.PinnedVersion
+
+			if updateModal != nil {
+				updateModal.Done()
+			}
 		} else {
 			s.System.Update.State.Status = "Update check completed"
 
@@ -864,7 +1175,38 @@ func updateChecker(ctx context.Context, s *state.State, t *tui.TUI, p providers.
 	}
 }
 
-func checkDownloadUpdate(ctx context.Context, s *state.State, t *tui.TUI, p providers.Provider, updateType string, appName string, isStartupCheck bool) (string, error) {
+// checkFirmwareUpdates refreshes the available firmware updates, applying the requested ones if allowed.
+// Failures are only reported in the firmware state, not interrupting the OS and application updates.
+func checkFirmwareUpdates(ctx context.Context, s *state.State, apply bool) {
+	s.System.Firmware.State.Status = "Checking for firmware updates"
+
+	err := systemd.RefreshFirmware(ctx, s)
+	if err != nil {
+		s.System.Firmware.State.Status = "Failed to check for firmware updates"
+		slog.ErrorContext(ctx, s.System.Firmware.State.Status, "err", err.Error())
+
+		return
+	}
+
+	if apply {
+		s.UpdateMutex.Lock()
+		err = systemd.ApplyFirmwareUpdates(ctx, s)
+		s.UpdateMutex.Unlock()
+
+		if err != nil {
+			s.System.Firmware.State.Status = "Failed to apply firmware updates"
+			slog.ErrorContext(ctx, s.System.Firmware.State.Status, "err", err.Error())
+
+			return
+		}
+	}
+
+	s.System.Firmware.State.Status = "Firmware update check completed"
+
+	_ = s.Save()
+}
+
+func checkDownloadUpdate(ctx context.Context, s *state.State, t *tui.TUI, p providers.Provider, updateType string, appName string, isStartupCheck bool, stageOnly bool) (string, error) { //nolint:revive
 	s.UpdateMutex.Lock()
 	defer s.UpdateMutex.Unlock()
 
@@ -907,7 +1249,7 @@ func checkDownloadUpdate(ctx context.Context, s *state.State, t *tui.TUI, p prov
 	updateNeeded := false
 
 	// Skip any update that isn't newer than what we are already running.
-	switch update.(type) {
+	switch typedUpdate := update.(type) {
 	case providers.SecureBootCertUpdate:
 		updateNeeded = update.Version() != s.SecureBoot.Version
 
@@ -915,6 +1257,13 @@ func checkDownloadUpdate(ctx context.Context, s *state.State, t *tui.TUI, p prov
 			return "", errors.New("installed Secure Boot keys version (" + s.SecureBoot.Version + ") is newer than available update (" + update.Version() + "); skipping")
 		}
 	case providers.OSUpdate:
+		// Only install the pinned version, if any.
+		if s.System.Update.Config.PinnedVersion != "" && update.Version() != s.System.Update.Config.PinnedVersion {
+			slog.DebugContext(ctx, "Skipping "+s.OS.Name+" update not matching the pinned version", "version", update.Version(), "pinned", s.System.Update.Config.PinnedVersion)
+
+			return "", nil
+		}
+
 		// If we're running from the backup image don't attempt to re-update to a broken version.
 		if !s.System.Update.State.NeedsReboot && s.OS.NextRelease != "" && s.OS.RunningRelease != s.OS.NextRelease && s.OS.NextRelease == update.Version() {
 			slog.WarnContext(ctx, "Latest "+s.OS.Name+" image version "+s.OS.NextRelease+" has been identified as problematic, skipping update")
@@ -924,10 +1273,26 @@ func checkDownloadUpdate(ctx context.Context, s *state.State, t *tui.TUI, p prov
 
 		updateNeeded = update.Version() != s.OS.RunningRelease && update.Version() != s.OS.NextRelease
 
+		// Expose the release notes of the pending version.
+		setReleaseNotes(ctx, s, typedUpdate)
+
+		// Don't download an already staged update again.
+		if stageOnly && update.Version() == s.OS.StagedRelease {
+			updateNeeded = false
+		}
+
 		if updateNeeded && s.OS.RunningRelease != update.Version() && !update.IsNewerThan(s.OS.RunningRelease) {
 			return "", errors.New("local " + s.OS.Name + " version (" + s.OS.RunningRelease + ") is newer than available update (" + update.Version() + "); skipping")
 		}
 	case providers.ApplicationUpdate:
+		// Only install the pinned version of the application, if any.
+		appConfig := s.Applications[appName].Config.Update
+		if appConfig != nil && appConfig.PinnedVersion != "" && update.Version() != appConfig.PinnedVersion {
+			slog.DebugContext(ctx, "Skipping application update not matching the pinned version", "application", appName, "version", update.Version(), "pinned", appConfig.PinnedVersion)
+
+			return "", nil
+		}
+
 		updateNeeded = update.Version() != s.Applications[appName].State.Version
 
 		if updateNeeded && s.Applications[appName].State.Version != "" && !update.IsNewerThan(s.Applications[appName].State.Version) {
@@ -937,7 +1302,12 @@ func checkDownloadUpdate(ctx context.Context, s *state.State, t *tui.TUI, p prov
 
 	// Apply the update.
 	if updateNeeded {
-		return applyUpdate(ctx, s, t, update, updateType, appName, isStartupCheck)
+		newVersion, err := applyUpdate(ctx, s, t, update, updateType, appName, isStartupCheck, stageOnly)
+		if err != nil {
+			setUpdateProgress(ctx, s, updateType, appName, update.Version(), "failed", -1, err)
+		}
+
+		return newVersion, err
 	} else if isStartupCheck {
 		_, isApplication := update.(providers.ApplicationUpdate)
 		if isApplication {
@@ -950,7 +1320,29 @@ func checkDownloadUpdate(ctx context.Context, s *state.State, t *tui.TUI, p prov
 	return "", nil
 }
 
-func applyUpdate(ctx context.Context, s *state.State, t *tui.TUI, update providers.CommonUpdate, updateType string, appName string, isStartupCheck bool) (string, error) {
+// setReleaseNotes records the release notes of an OS update not yet running.
+func setReleaseNotes(ctx context.Context, s *state.State, update providers.OSUpdate) {
+	if update.Version() == s.OS.RunningRelease {
+		s.System.Update.State.ReleaseNotes = nil
+
+		return
+	}
+
+	if s.System.Update.State.ReleaseNotes != nil && s.System.Update.State.ReleaseNotes.Version == update.Version() {
+		return
+	}
+
+	notes, err := update.GetReleaseNotes(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to get the release notes of "+s.OS.Name+" version "+update.Version(), "err", err.Error())
+
+		return
+	}
+
+	s.System.Update.State.ReleaseNotes = &api.SystemUpdateReleaseNotes{Version: update.Version(), Notes: notes}
+}
+
+func applyUpdate(ctx context.Context, s *state.State, t *tui.TUI, update providers.CommonUpdate, updateType string, appName string, isStartupCheck bool, stageOnly bool) (string, error) { //nolint:revive
 	updateModal := t.GetModal("update")
 
 	if t.GetModal("update") == nil {
@@ -967,6 +1359,8 @@ func applyUpdate(ctx context.Context, s *state.State, t *tui.TUI, update provide
 		updateModal.Update("Downloading " + updateType + " update " + update.Version())
 	}
 
+	setUpdateProgress(ctx, s, updateType, appName, update.Version(), "downloading", 0, nil)
+
 	targetPath := ""
 
 	switch update.(type) {
@@ -978,7 +1372,10 @@ func applyUpdate(ctx context.Context, s *state.State, t *tui.TUI, update provide
 		targetPath = systemd.SystemExtensionsPath
 	}
 
-	err := update.Download(ctx, targetPath, updateModal.UpdateProgress)
+	err := update.Download(ctx, targetPath, func(progress float64) {
+		updateModal.UpdateProgress(progress)
+		setUpdateProgress(ctx, s, updateType, appName, update.Version(), "downloading", progress, nil)
+	})
 	if err != nil {
 		return "", err
 	}
@@ -995,6 +1392,8 @@ func applyUpdate(ctx context.Context, s *state.State, t *tui.TUI, update provide
 		s.SecureBoot.FullyApplied = false
 		_ = s.Save()
 
+		setUpdateProgress(ctx, s, updateType, appName, update.Version(), "updating-efi", 1, nil)
+
 		needsReboot, err := secureboot.UpdateSecureBootCerts(ctx, filepath.Join(targetPath, u.GetFilename()))
 		if err != nil {
 			return "", err
@@ -1003,6 +1402,7 @@ func applyUpdate(ctx context.Context, s *state.State, t *tui.TUI, update provide
 		// If an EFI variable was updated, we'll either be rebooting automatically or waiting
 		// for the user to restart the system before going any further.
 		if needsReboot {
+			setUpdateProgress(ctx, s, updateType, appName, update.Version(), "completed", 1, nil)
 			updateModal.Done()
 
 			s.System.Update.State.NeedsReboot = true
@@ -1034,17 +1434,37 @@ func applyUpdate(ctx context.Context, s *state.State, t *tui.TUI, update provide
 		s.SecureBoot.Version = update.Version()
 		s.SecureBoot.FullyApplied = true
 	case providers.OSUpdate:
+		// Leave a staged update to be applied through the API.
+		if stageOnly {
+			slog.InfoContext(ctx, "Staged OS update", "version", update.Version())
+			updateModal.Update(s.OS.Name + " update version " + update.Version() + " has been staged")
+
+			s.OS.StagedRelease = update.Version()
+			_ = s.Save()
+
+			setUpdateProgress(ctx, s, updateType, appName, update.Version(), "staged", 1, nil)
+
+			return "", nil
+		}
+
 		// Record the release. Need to do it here, since if the system reboots as part of the
 		// update we won't be able to save the state to disk.
 		priorNextRelease := s.OS.NextRelease
 		s.OS.NextRelease = update.Version()
+		s.OS.StagedRelease = ""
+		health.Prepare(ctx, s, update.Version())
 		_ = s.Save()
 
-		// Apply the update and reboot if first time through loop, otherwise wait for user to reboot system.
+		// Apply the update and reboot if first time through loop, otherwise leave the reboot to the reboot policy.
 		slog.InfoContext(ctx, "Applying OS update", "version", update.Version())
 		updateModal.Update("Applying " + s.OS.Name + " update version " + update.Version())
 
-		err = systemd.ApplySystemUpdate(ctx, s.System.Security.Config.EncryptionRecoveryKeys[0], update.Version(), s.System.Update.Config.AutoReboot || isStartupCheck)
+		setUpdateProgress(ctx, s, updateType, appName, update.Version(), "installing", 1, nil)
+
+		// Checkpoint the storage pools, allowing them to be rewound should the update go wrong.
+		zfs.CheckpointPoolsForUpdate(ctx, s, update.Version())
+
+		err = systemd.ApplySystemUpdate(ctx, s.System.Security.Config.EncryptionRecoveryKeys[0], update.Version(), isStartupCheck)
 		if err != nil {
 			s.OS.NextRelease = priorNextRelease
 			_ = s.Save()
@@ -1063,6 +1483,8 @@ func applyUpdate(ctx context.Context, s *state.State, t *tui.TUI, update provide
 			return "", err
 		}
 	case providers.ApplicationUpdate:
+		setUpdateProgress(ctx, s, updateType, appName, update.Version(), "verifying", 1, nil)
+
 		// Verify the application is signed with a trusted key in the kernel's keyring.
 		err = systemd.VerifyExtensionCertificateFingerprint(ctx, filepath.Join(systemd.SystemExtensionsPath, appName+".raw"))
 		if err != nil {
@@ -1077,9 +1499,53 @@ func applyUpdate(ctx context.Context, s *state.State, t *tui.TUI, update provide
 		_ = s.Save()
 	}
 
+	setUpdateProgress(ctx, s, updateType, appName, update.Version(), "completed", 1, nil)
+
 	return update.Version(), nil
 }
 
+// setUpdateProgress records the phase and download progress of an artifact being updated, logging
+// each phase change. A negative progress keeps the previously recorded one.
+func setUpdateProgress(ctx context.Context, s *state.State, updateType string, appName string, version string, phase string, progress float64, err error) { //nolint:revive
+	name := appName
+	if name == "" {
+		name = s.OS.Name
+	}
+
+	idx := slices.IndexFunc(s.System.Update.State.Progress, func(p api.SystemUpdateProgress) bool {
+		return p.Type == updateType && p.Name == name
+	})
+
+	if idx == -1 {
+		s.System.Update.State.Progress = append(s.System.Update.State.Progress, api.SystemUpdateProgress{Type: updateType, Name: name})
+		idx = len(s.System.Update.State.Progress) - 1
+	}
+
+	entry := &s.System.Update.State.Progress[idx]
+
+	if entry.Phase != phase || entry.Version != version {
+		if err != nil {
+			slog.WarnContext(ctx, "Update progress", "type", updateType, "name", name, "version", version, "phase", phase, "err", err.Error())
+		} else {
+			slog.InfoContext(ctx, "Update progress", "type", updateType, "name", name, "version", version, "phase", phase)
+		}
+	}
+
+	entry.Version = version
+	entry.Phase = phase
+	entry.LastUpdate = time.Now()
+
+	if progress >= 0 {
+		entry.Progress = progress
+	}
+
+	if err != nil {
+		entry.Error = err.Error()
+	} else {
+		entry.Error = ""
+	}
+}
+
 func setTimezone(ctx context.Context) error {
 	// Get the network seed.
 	config, err := seed.GetNetwork(ctx)
@@ -0,0 +1,251 @@
+package apparmor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// SecurityfsPath is the securityfs directory exposed by the kernel's AppArmor module.
+var SecurityfsPath = "/sys/kernel/security/apparmor"
+
+// profilesPath is the directory the rendered profiles are written to before being loaded.
+const profilesPath = "/run/incus-os/apparmor"
+
+// maxDenials is the maximum number of recent denials returned.
+const maxDenials = 50
+
+var auditFieldRegex = regexp.MustCompile(`(\w+)=("[^"]*"|\S+)`)
+
+// ValidateConfig checks that the AppArmor configuration is usable.
+func ValidateConfig(cfg *api.SystemSecurityAppArmor) error {
+	if cfg == nil {
+		return nil
+	}
+
+	if !slices.Contains([]string{"", "enforce", "complain", "disabled"}, cfg.Mode) {
+		return fmt.Errorf("invalid AppArmor mode %q", cfg.Mode)
+	}
+
+	return nil
+}
+
+// IsEnabled returns whether AppArmor is enabled in the running kernel.
+func IsEnabled() bool {
+	_, err := os.Stat(filepath.Join(SecurityfsPath, "profiles"))
+
+	return err == nil
+}
+
+// ApplyProfiles loads, or unloads when disabled, the profiles confining the subprocesses managed by
+// IncusOS. Already running processes only pick up a newly loaded profile once restarted.
+func ApplyProfiles(ctx context.Context, cfg *api.SystemSecurityAppArmor) error {
+	if !IsEnabled() {
+		return nil
+	}
+
+	mode := getMode(cfg)
+
+	err := os.MkdirAll(profilesPath, 0o700)
+	if err != nil {
+		return err
+	}
+
+	loaded := getLoadedProfiles()
+
+	for _, p := range profiles {
+		profilePath := filepath.Join(profilesPath, p.name)
+
+		err := os.WriteFile(profilePath, []byte(renderProfile(p, mode)), 0o600)
+		if err != nil {
+			return err
+		}
+
+		if mode == "disabled" {
+			_, ok := loaded[p.name]
+			if !ok {
+				continue
+			}
+
+			_, err := subprocess.RunCommandContext(ctx, "apparmor_parser", "--remove", profilePath)
+			if err != nil {
+				return fmt.Errorf("failed to unload AppArmor profile %q: %w", p.name, err)
+			}
+
+			continue
+		}
+
+		_, err = subprocess.RunCommandContext(ctx, "apparmor_parser", "--replace", profilePath)
+		if err != nil {
+			return fmt.Errorf("failed to load AppArmor profile %q: %w", p.name, err)
+		}
+	}
+
+	return nil
+}
+
+// ListProfiles returns the status of the profiles shipped by IncusOS.
+func ListProfiles() []api.SystemSecurityAppArmorProfile {
+	ret := []api.SystemSecurityAppArmorProfile{}
+
+	if !IsEnabled() {
+		return ret
+	}
+
+	loaded := getLoadedProfiles()
+
+	for _, p := range profiles {
+		mode, ok := loaded[p.name]
+		if !ok {
+			mode = "unloaded"
+		}
+
+		ret = append(ret, api.SystemSecurityAppArmorProfile{Name: p.name, Binary: p.binary, Mode: mode})
+	}
+
+	return ret
+}
+
+// ListDenials returns the accesses recently denied, or logged in complain mode, by the profiles
+// shipped by IncusOS during the current boot.
+func ListDenials(ctx context.Context) ([]api.SystemSecurityAppArmorDenial, error) {
+	ret := []api.SystemSecurityAppArmorDenial{}
+
+	if !IsEnabled() {
+		return ret, nil
+	}
+
+	output, err := subprocess.RunCommandContext(ctx, "journalctl", "-b", "0", "-o", "json", "-n", strconv.Itoa(maxDenials), "-g", `apparmor="(DENIED|ALLOWED)".* profile="incus-osd-`, "_TRANSPORT=audit", "_TRANSPORT=kernel")
+	if err != nil {
+		// journalctl fails when no entry matches.
+		return ret, nil //nolint:nilerr
+	}
+
+	for line := range strings.SplitSeq(output, "\n") {
+		if line == "" {
+			continue
+		}
+
+		entry := struct {
+			Message   string `json:"MESSAGE"`
+			Timestamp string `json:"__REALTIME_TIMESTAMP"`
+		}{}
+
+		err := json.Unmarshal([]byte(line), &entry)
+		if err != nil {
+			return nil, err
+		}
+
+		denial := parseDenial(entry.Message)
+		if denial == nil {
+			continue
+		}
+
+		usec, err := strconv.ParseInt(entry.Timestamp, 10, 64)
+		if err == nil {
+			denial.Timestamp = time.UnixMicro(usec).UTC().Format(time.RFC3339)
+		}
+
+		ret = append(ret, *denial)
+	}
+
+	return ret, nil
+}
+
+// getMode returns the configured profile mode. The profiles only log what they would deny by default,
+// until they have been exercised against all the operations of the confined binaries, such as restores.
+func getMode(cfg *api.SystemSecurityAppArmor) string {
+	if cfg == nil || cfg.Mode == "" {
+		return "complain"
+	}
+
+	return cfg.Mode
+}
+
+// getLoadedProfiles returns the mode of each loaded profile, keyed by name.
+func getLoadedProfiles() map[string]string {
+	ret := map[string]string{}
+
+	content, err := os.ReadFile(filepath.Join(SecurityfsPath, "profiles"))
+	if err != nil {
+		return ret
+	}
+
+	// Each line looks like "incus-osd-kopia (enforce)".
+	for line := range strings.SplitSeq(string(content), "\n") {
+		name, mode, ok := strings.Cut(line, " (")
+		if !ok {
+			continue
+		}
+
+		ret[name] = strings.TrimSuffix(mode, ")")
+	}
+
+	return ret
+}
+
+// renderProfile returns the text of a profile, including the restrictions common to all profiles.
+func renderProfile(p profile, mode string) string {
+	flags := "attach_disconnected,mediate_deleted"
+	if mode == "complain" {
+		flags += ",complain"
+	}
+
+	return fmt.Sprintf(`# Generated by IncusOS, do not edit.
+abi <abi/3.0>,
+
+#include <tunables/global>
+
+profile %s %s flags=(%s) {
+%s
+  # Common restrictions.
+  deny mount,
+  deny umount,
+  deny pivot_root,
+  deny ptrace,
+  deny /boot/** w,
+  deny /efi/** w,
+  deny /var/lib/incus-os/zpool.*.key rw,
+  deny @{PROC}/sysrq-trigger rw,
+  deny @{PROC}/kcore rw,
+}
+`, p.name, p.binary, flags, p.rules)
+}
+
+// parseDenial parses an AppArmor audit message, returning nil if it isn't a denial by one of the
+// profiles shipped by IncusOS.
+func parseDenial(message string) *api.SystemSecurityAppArmorDenial {
+	fields := map[string]string{}
+
+	for _, match := range auditFieldRegex.FindAllStringSubmatch(message, -1) {
+		fields[match[1]] = strings.Trim(match[2], `"`)
+	}
+
+	if fields["apparmor"] != "DENIED" && fields["apparmor"] != "ALLOWED" {
+		return nil
+	}
+
+	if !strings.HasPrefix(fields["profile"], "incus-osd-") {
+		return nil
+	}
+
+	return &api.SystemSecurityAppArmorDenial{
+		Profile:    fields["profile"],
+		Operation:  fields["operation"],
+		Name:       fields["name"],
+		Command:    fields["comm"],
+		DeniedMask: fields["denied_mask"],
+		Enforced:   fields["apparmor"] == "DENIED",
+	}
+}
@@ -0,0 +1,45 @@
+package apparmor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+func TestValidateConfig(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidateConfig(nil))
+	require.NoError(t, ValidateConfig(&api.SystemSecurityAppArmor{Mode: "complain"}))
+	require.Error(t, ValidateConfig(&api.SystemSecurityAppArmor{Mode: "kill"}))
+
+	// Profiles aren't enforced unless explicitly requested.
+	require.Equal(t, "complain", getMode(nil))
+	require.Equal(t, "complain", getMode(&api.SystemSecurityAppArmor{}))
+	require.Equal(t, "enforce", getMode(&api.SystemSecurityAppArmor{Mode: "enforce"}))
+}
+
+func TestRenderProfile(t *testing.T) {
+	t.Parallel()
+
+	p := profile{name: "incus-osd-test", binary: "/usr/bin/test", rules: "  /etc/test r,\n"}
+
+	require.Contains(t, renderProfile(p, "enforce"), "profile incus-osd-test /usr/bin/test flags=(attach_disconnected,mediate_deleted) {\n  /etc/test r,\n")
+	require.Contains(t, renderProfile(p, "complain"), "flags=(attach_disconnected,mediate_deleted,complain)")
+}
+
+func TestParseDenial(t *testing.T) {
+	t.Parallel()
+
+	denial := parseDenial(`audit: type=1400 audit(1735689600.123:45): apparmor="DENIED" operation="open" class="file" profile="incus-osd-kopia" name="/var/lib/incus-os/zpool.local.key" pid=1234 comm="kopia" requested_mask="r" denied_mask="r" fsuid=0 ouid=0`)
+	require.Equal(t, &api.SystemSecurityAppArmorDenial{Profile: "incus-osd-kopia", Operation: "open", Name: "/var/lib/incus-os/zpool.local.key", Command: "kopia", DeniedMask: "r", Enforced: true}, denial)
+
+	denial = parseDenial(`audit: type=1400 audit(1735689600.123:46): apparmor="ALLOWED" operation="capable" class="cap" profile="incus-osd-lldpd" pid=1234 comm="lldpd" capability=21 capname="sys_admin"`)
+	require.NotNil(t, denial)
+	require.False(t, denial.Enforced)
+
+	require.Nil(t, parseDenial(`audit: type=1400 audit(1735689600.123:47): apparmor="DENIED" operation="open" class="file" profile="incus-default" name="/etc/shadow" comm="cat"`))
+	require.Nil(t, parseDenial(`audit: type=1400 audit(1735689600.123:48): apparmor="STATUS" operation="profile_load" profile="unconfined" name="incus-osd-kopia"`))
+}
@@ -0,0 +1,2 @@
+// Package apparmor provides logic to confine the subprocesses managed by IncusOS using AppArmor.
+package apparmor
@@ -0,0 +1,115 @@
+package apparmor
+
+// profile defines an AppArmor profile shipped by IncusOS.
+type profile struct {
+	name   string
+	binary string
+	rules  string
+}
+
+// profiles lists the profiles confining the helpers and service daemons managed by IncusOS. They're
+// attached by path, so apply whether the binary is run by IncusOS directly or through a systemd unit.
+var profiles = []profile{
+	{
+		name:   "incus-osd-kopia",
+		binary: "/usr/{,local/}bin/kopia",
+		rules: `  #include <abstractions/base>
+  #include <abstractions/nameservice>
+  #include <abstractions/ssl_certs>
+
+  network inet stream,
+  network inet6 stream,
+  network inet dgram,
+  network inet6 dgram,
+
+  capability dac_read_search,
+
+  # Read access for backups.
+  / r,
+  /** r,
+
+  # Repository configuration and cache.
+  owner /root/.config/kopia/** rwk,
+  owner /root/.cache/kopia/** rwk,
+  /var/lib/incus-os/kopia/** rwk,
+  /tmp/** rwk,
+`,
+	},
+	{
+		name:   "incus-osd-lldpd",
+		binary: "/usr/sbin/lldp{d,cli}",
+		rules: `  #include <abstractions/base>
+  #include <abstractions/nameservice>
+
+  network packet raw,
+  network unix stream,
+  network netlink raw,
+
+  capability chown,
+  capability net_admin,
+  capability net_raw,
+  capability setgid,
+  capability setuid,
+  capability sys_chroot,
+
+  /usr/sbin/lldp{d,cli} mr,
+  /etc/lldpd.conf r,
+  /etc/lldpd.d/ r,
+  /etc/lldpd.d/* r,
+  /etc/os-release r,
+  /usr/lib/os-release r,
+  /proc/sys/net/** r,
+  /sys/class/net/ r,
+  /sys/devices/** r,
+  /{,var/}run/lldpd.* rwk,
+  /{,var/}run/lldpd/ rw,
+  /{,var/}run/lldpd/** rwk,
+`,
+	},
+	{
+		name:   "incus-osd-tailscaled",
+		binary: "/usr/{,local/}bin/tailscale{,d}",
+		rules: `  #include <abstractions/base>
+  #include <abstractions/nameservice>
+  #include <abstractions/ssl_certs>
+
+  network,
+
+  capability net_admin,
+  capability net_bind_service,
+  capability net_raw,
+  capability sys_module,
+
+  /usr/{,local/}bin/tailscale{,d} mrix,
+  /usr/{,s}bin/ip mrix,
+  /usr/{,s}bin/{,ip6tables-,iptables-,}nft mrix,
+  /dev/net/tun rw,
+  /proc/** r,
+  /sys/** r,
+  /etc/resolv.conf rw,
+  /run/systemd/resolve/** r,
+  /{,var/}run/tailscale/ rw,
+  /{,var/}run/tailscale/** rwk,
+  /var/lib/tailscale/ rw,
+  /var/lib/tailscale/** rwk,
+  /var/cache/tailscale/ rw,
+  /var/cache/tailscale/** rwk,
+`,
+	},
+	{
+		name:   "incus-osd-usbip",
+		binary: "/usr/{,s}bin/usbip",
+		rules: `  #include <abstractions/base>
+  #include <abstractions/nameservice>
+
+  network inet stream,
+  network inet6 stream,
+
+  /usr/share/hwdata/usb.ids r,
+  /sys/bus/usb/** r,
+  /sys/devices/** rw,
+  /{,var/}run/vhci_hcd/ rw,
+  /{,var/}run/vhci_hcd/* rw,
+`,
+	},
+}
@@ -17,6 +17,7 @@ import (
 	"io/fs"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"slices"
@@ -27,6 +28,7 @@ import (
 	"github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/revert"
 
+	incusosapi "github.com/lxc/incus-os/incus-osd/api"
 	"github.com/lxc/incus-os/incus-osd/internal/state"
 	"github.com/lxc/incus-os/incus-osd/internal/systemd"
 )
@@ -39,8 +41,33 @@ func (*common) Name() string {
 	return ""
 }
 
+// AddStoragePool makes a storage volume available to the application as a storage pool.
+func (*common) AddStoragePool(_ context.Context, _ string, _ string) error {
+	return errors.New("not supported")
+}
+
 // AddTrustedCertificate adds a new trusted certificate to the application.
-func (*common) AddTrustedCertificate(_ context.Context, _ string, _ string) error {
+func (*common) AddTrustedCertificate(_ context.Context, _ *incusosapi.SystemSecurityTrustedCertificate) error {
+	return errors.New("not supported")
+}
+
+// SetACMEConfiguration configures ACME certificate issuance for the application, or disables it if nil.
+func (*common) SetACMEConfiguration(_ context.Context, _ *incusosapi.SystemSecurityACME) error {
+	return errors.New("not supported")
+}
+
+// SetOIDCConfiguration configures OpenID Connect authentication for the application, or disables it if nil.
+func (*common) SetOIDCConfiguration(_ context.Context, _ *incusosapi.SystemSecurityOIDC) error {
+	return errors.New("not supported")
+}
+
+// GetTrustedCertificates returns the client certificates trusted by the application.
+func (*common) GetTrustedCertificates(_ context.Context) ([]incusosapi.SystemSecurityTrustedCertificate, error) {
+	return nil, errors.New("not supported")
+}
+
+// RemoveTrustedCertificate revokes a trusted certificate from the application.
+func (*common) RemoveTrustedCertificate(_ context.Context, _ string) error {
 	return errors.New("not supported")
 }
 
@@ -214,6 +241,106 @@ func getCertificateFingerprint(certificate string) (string, error) {
 	return hex.EncodeToString(rawFp[:]), nil
 }
 
+// ValidateOIDCConfiguration checks that the OpenID Connect configuration is usable.
+func ValidateOIDCConfiguration(cfg *incusosapi.SystemSecurityOIDC) error {
+	if cfg == nil {
+		return nil
+	}
+
+	issuerURL, err := url.Parse(cfg.Issuer)
+	if err != nil {
+		return fmt.Errorf("invalid OIDC issuer: %w", err)
+	}
+
+	if issuerURL.Scheme != "https" || issuerURL.Host == "" {
+		return errors.New("OIDC issuer must be an https URL")
+	}
+
+	if cfg.ClientID == "" {
+		return errors.New("OIDC client ID must be provided")
+	}
+
+	return nil
+}
+
+// ValidateACMEConfiguration checks that the ACME configuration is usable.
+func ValidateACMEConfiguration(cfg *incusosapi.SystemSecurityACME) error {
+	if cfg == nil {
+		return nil
+	}
+
+	if cfg.Domain == "" {
+		return errors.New("ACME domain must be provided")
+	}
+
+	if !cfg.AgreeTOS {
+		return errors.New("the ACME terms of service must be agreed to")
+	}
+
+	if cfg.CAURL != "" {
+		caURL, err := url.Parse(cfg.CAURL)
+		if err != nil {
+			return fmt.Errorf("invalid ACME CA URL: %w", err)
+		}
+
+		if caURL.Scheme != "https" || caURL.Host == "" {
+			return errors.New("ACME CA URL must be an https URL")
+		}
+	}
+
+	switch cfg.Challenge {
+	case "", "HTTP-01":
+	case "DNS-01":
+		if cfg.Provider == "" {
+			return errors.New("a DNS provider must be provided for the DNS-01 challenge")
+		}
+	default:
+		return fmt.Errorf("unsupported ACME challenge %q", cfg.Challenge)
+	}
+
+	for _, env := range cfg.ProviderEnvironment {
+		if !strings.Contains(env, "=") {
+			return fmt.Errorf("invalid ACME provider environment variable %q, expected \"KEY=value\"", env)
+		}
+	}
+
+	return nil
+}
+
+// getTrustedCertificateFingerprint returns the SHA256 fingerprint of a certificate to be trusted by an
+// application which only keeps track of fingerprints, and so can't store descriptions or restrictions.
+func getTrustedCertificateFingerprint(cert *incusosapi.SystemSecurityTrustedCertificate) (string, error) {
+	if cert.Description != "" {
+		return "", errors.New("certificate descriptions aren't supported by the application")
+	}
+
+	if cert.Restricted || len(cert.Projects) > 0 {
+		return "", errors.New("certificate restrictions aren't supported by the application")
+	}
+
+	if cert.Certificate != "" {
+		fp, err := getCertificateFingerprint(cert.Certificate)
+		if err != nil {
+			return "", err
+		}
+
+		if cert.Fingerprint != "" && !strings.EqualFold(cert.Fingerprint, fp) {
+			return "", errors.New("fingerprint doesn't match the provided certificate")
+		}
+
+		return fp, nil
+	}
+
+	fp := strings.ToLower(cert.Fingerprint)
+
+	rawFp, err := hex.DecodeString(fp)
+	if err != nil || len(rawFp) != sha256.Size {
+		return "", errors.New("invalid certificate fingerprint")
+	}
+
+	return fp, nil
+}
+
 func createTarArchive(archiveRoot string, excludePaths []string, archive io.Writer) error {
 	zw := gzip.NewWriter(archive)
 	tw := tar.NewWriter(zw)
@@ -7,11 +7,14 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
+	"strings"
 
 	incusclient "github.com/lxc/incus/v6/client"
 	incusapi "github.com/lxc/incus/v6/shared/api"
 	"github.com/lxc/incus/v6/shared/subprocess"
 
+	"github.com/lxc/incus-os/incus-osd/api"
 	apiseed "github.com/lxc/incus-os/incus-osd/api/seed"
 	"github.com/lxc/incus-os/incus-osd/internal/seed"
 	"github.com/lxc/incus-os/incus-osd/internal/storage"
@@ -206,7 +209,23 @@ func (*incus) GetDependencies() []string {
 }
 
 // AddTrustedCertificate adds a new trusted certificate to the application.
-func (*incus) AddTrustedCertificate(_ context.Context, name string, cert string) error {
+func (*incus) AddTrustedCertificate(_ context.Context, cert *api.SystemSecurityTrustedCertificate) error {
+	// Incus needs the full certificate to authenticate clients.
+	if cert.Certificate == "" {
+		return errors.New("incus requires the PEM-encoded certificate")
+	}
+
+	if cert.Fingerprint != "" {
+		fp, err := getCertificateFingerprint(cert.Certificate)
+		if err != nil {
+			return err
+		}
+
+		if !strings.EqualFold(cert.Fingerprint, fp) {
+			return errors.New("fingerprint doesn't match the provided certificate")
+		}
+	}
+
 	// Connect to Incus.
 	c, err := incusclient.ConnectIncusUnix("", nil)
 	if err != nil {
@@ -215,9 +234,12 @@ func (*incus) AddTrustedCertificate(_ context.Context, name string, cert string)
 
 	// Add the certificate.
 	req := incusapi.CertificatesPost{}
-	req.Name = name
+	req.Name = cert.Name
+	req.Description = cert.Description
 	req.Type = "client"
-	req.Certificate = cert
+	req.Certificate = cert.Certificate
+	req.Restricted = cert.Restricted
+	req.Projects = cert.Projects
 
 	err = c.CreateCertificate(req)
 	if err != nil {
@@ -227,6 +249,168 @@ func (*incus) AddTrustedCertificate(_ context.Context, name string, cert string)
 	return nil
 }
 
+// SetACMEConfiguration configures ACME certificate issuance for the application, or disables it if nil.
+func (*incus) SetACMEConfiguration(_ context.Context, cfg *api.SystemSecurityACME) error {
+	// Connect to Incus.
+	c, err := incusclient.ConnectIncusUnix("", nil)
+	if err != nil {
+		return err
+	}
+
+	conf, etag, err := c.GetServer()
+	if err != nil {
+		return err
+	}
+
+	if cfg == nil {
+		cfg = &api.SystemSecurityACME{}
+	}
+
+	agreeTOS := ""
+	if cfg.AgreeTOS {
+		agreeTOS = "true"
+	}
+
+	for key, value := range map[string]string{
+		"acme.domain":               cfg.Domain,
+		"acme.email":                cfg.Email,
+		"acme.ca_url":               cfg.CAURL,
+		"acme.agree_tos":            agreeTOS,
+		"acme.challenge":            cfg.Challenge,
+		"acme.http.port":            cfg.HTTPChallengeAddress,
+		"acme.provider":             cfg.Provider,
+		"acme.provider.environment": strings.Join(cfg.ProviderEnvironment, "\n"),
+		"acme.provider.resolvers":   strings.Join(cfg.ProviderResolvers, ","),
+	} {
+		if value == "" {
+			delete(conf.Config, key)
+
+			continue
+		}
+
+		conf.Config[key] = value
+	}
+
+	return c.UpdateServer(conf.Writable(), etag)
+}
+
+// SetOIDCConfiguration configures OpenID Connect authentication for the application, or disables it if nil.
+func (*incus) SetOIDCConfiguration(_ context.Context, cfg *api.SystemSecurityOIDC) error {
+	// Connect to Incus.
+	c, err := incusclient.ConnectIncusUnix("", nil)
+	if err != nil {
+		return err
+	}
+
+	conf, etag, err := c.GetServer()
+	if err != nil {
+		return err
+	}
+
+	if cfg == nil {
+		cfg = &api.SystemSecurityOIDC{}
+	}
+
+	for key, value := range map[string]string{
+		"oidc.issuer":    cfg.Issuer,
+		"oidc.client.id": cfg.ClientID,
+		"oidc.audience":  cfg.Audience,
+		"oidc.scopes":    cfg.Scopes,
+		"oidc.claim":     cfg.Claim,
+	} {
+		if value == "" {
+			delete(conf.Config, key)
+
+			continue
+		}
+
+		conf.Config[key] = value
+	}
+
+	return c.UpdateServer(conf.Writable(), etag)
+}
+
+// GetTrustedCertificates returns the client certificates trusted by the application.
+func (*incus) GetTrustedCertificates(_ context.Context) ([]api.SystemSecurityTrustedCertificate, error) {
+	// Connect to Incus.
+	c, err := incusclient.ConnectIncusUnix("", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	certs, err := c.GetCertificates()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []api.SystemSecurityTrustedCertificate{}
+
+	for _, cert := range certs {
+		if cert.Type != "client" {
+			continue
+		}
+
+		ret = append(ret, api.SystemSecurityTrustedCertificate{
+			Name:        cert.Name,
+			Description: cert.Description,
+			Fingerprint: cert.Fingerprint,
+			Certificate: cert.Certificate,
+			Restricted:  cert.Restricted,
+			Projects:    cert.Projects,
+		})
+	}
+
+	return ret, nil
+}
+
+// RemoveTrustedCertificate revokes a trusted certificate from the application.
+func (*incus) RemoveTrustedCertificate(_ context.Context, fingerprint string) error {
+	// Connect to Incus.
+	c, err := incusclient.ConnectIncusUnix("", nil)
+	if err != nil {
+		return err
+	}
+
+	return c.DeleteCertificate(fingerprint)
+}
+
+// AddStoragePool makes a storage volume available to the application as a storage pool.
+func (*incus) AddStoragePool(_ context.Context, pool string, volume string) error {
+	// Connect to Incus.
+	c, err := incusclient.ConnectIncusUnix("", nil)
+	if err != nil {
+		return err
+	}
+
+	// The main Incus volume of a pool is exposed under the pool's own name.
+	name := pool
+	if volume != "incus" {
+		name = pool + "-" + volume
+	}
+
+	// Skip if the storage pool already exists.
+	storagePools, err := c.GetStoragePoolNames()
+	if err != nil {
+		return err
+	}
+
+	if slices.Contains(storagePools, name) {
+		return nil
+	}
+
+	// Create the storage pool.
+	return c.CreateStoragePool(incusapi.StoragePoolsPost{
+		Name:   name,
+		Driver: "zfs",
+		StoragePoolPut: incusapi.StoragePoolPut{
+			Config: map[string]string{
+				"source": pool + "/" + volume,
+			},
+			Description: "Storage pool on '" + pool + "'",
+		},
+	})
+}
+
 // FactoryReset performs a full factory reset of the application.
 func (a *incus) FactoryReset(ctx context.Context) error {
 	// Stop the application.
@@ -11,10 +11,12 @@ import (
 	"net/http"
 	"os"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/FuturFusion/operations-center/shared/api"
 
+	incusosapi "github.com/lxc/incus-os/incus-osd/api"
 	apiseed "github.com/lxc/incus-os/incus-osd/api/seed"
 	"github.com/lxc/incus-os/incus-osd/internal/seed"
 	"github.com/lxc/incus-os/incus-osd/internal/systemd"
@@ -225,9 +227,9 @@ func (*operationsCenter) GetDependencies() []string {
 }
 
 // AddTrustedCertificate adds a new trusted certificate to the application.
-func (*operationsCenter) AddTrustedCertificate(ctx context.Context, _ string, cert string) error {
-	// Compute the certificate's fingerprint.
-	fp, err := getCertificateFingerprint(cert)
+func (*operationsCenter) AddTrustedCertificate(ctx context.Context, cert *incusosapi.SystemSecurityTrustedCertificate) error {
+	// Get the certificate's fingerprint.
+	fp, err := getTrustedCertificateFingerprint(cert)
 	if err != nil {
 		return err
 	}
@@ -263,6 +265,144 @@ func (*operationsCenter) AddTrustedCertificate(ctx context.Context, _ string, ce
 	return err
 }
 
+// SetACMEConfiguration configures ACME certificate issuance for the application, or disables it if nil.
+func (*operationsCenter) SetACMEConfiguration(ctx context.Context, cfg *incusosapi.SystemSecurityACME) error {
+	// Get the current security configuration.
+	body, err := doOCRequest(ctx, "http://localhost/1.0/system/security", http.MethodGet, nil)
+	if err != nil {
+		return err
+	}
+
+	sec := &api.SystemSecurity{}
+
+	err = json.Unmarshal(body, sec)
+	if err != nil {
+		return err
+	}
+
+	sec.ACME = api.SystemSecurityACME{}
+
+	if cfg != nil {
+		sec.ACME = api.SystemSecurityACME{
+			AgreeTOS:            cfg.AgreeTOS,
+			CAURL:               cfg.CAURL,
+			Challenge:           api.ACMEChallengeType(cfg.Challenge),
+			Domain:              cfg.Domain,
+			Email:               cfg.Email,
+			Address:             cfg.HTTPChallengeAddress,
+			Provider:            cfg.Provider,
+			ProviderEnvironment: cfg.ProviderEnvironment,
+			ProviderResolvers:   cfg.ProviderResolvers,
+		}
+
+		if sec.ACME.Challenge == "" {
+			sec.ACME.Challenge = api.ACMEChallengeHTTP
+		}
+	}
+
+	contentJSON, err := json.Marshal(sec)
+	if err != nil {
+		return err
+	}
+
+	_, err = doOCRequest(ctx, "http://localhost/1.0/system/security", http.MethodPut, contentJSON)
+
+	return err
+}
+
+// SetOIDCConfiguration configures OpenID Connect authentication for the application, or disables it if nil.
+func (*operationsCenter) SetOIDCConfiguration(ctx context.Context, cfg *incusosapi.SystemSecurityOIDC) error {
+	// Get the current security configuration.
+	body, err := doOCRequest(ctx, "http://localhost/1.0/system/security", http.MethodGet, nil)
+	if err != nil {
+		return err
+	}
+
+	sec := &api.SystemSecurity{}
+
+	err = json.Unmarshal(body, sec)
+	if err != nil {
+		return err
+	}
+
+	sec.OIDC = api.SystemSecurityOIDC{}
+
+	if cfg != nil {
+		sec.OIDC = api.SystemSecurityOIDC{
+			Issuer:   cfg.Issuer,
+			ClientID: cfg.ClientID,
+			Scope:    cfg.Scopes,
+			Audience: cfg.Audience,
+			Claim:    cfg.Claim,
+		}
+	}
+
+	contentJSON, err := json.Marshal(sec)
+	if err != nil {
+		return err
+	}
+
+	_, err = doOCRequest(ctx, "http://localhost/1.0/system/security", http.MethodPut, contentJSON)
+
+	return err
+}
+
+// GetTrustedCertificates returns the client certificates trusted by the application.
+func (*operationsCenter) GetTrustedCertificates(ctx context.Context) ([]incusosapi.SystemSecurityTrustedCertificate, error) {
+	// Get the current security configuration.
+	body, err := doOCRequest(ctx, "http://localhost/1.0/system/security", http.MethodGet, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	sec := &api.SystemSecurity{}
+
+	err = json.Unmarshal(body, sec)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]incusosapi.SystemSecurityTrustedCertificate, 0, len(sec.TrustedTLSClientCertFingerprints))
+	for _, fp := range sec.TrustedTLSClientCertFingerprints {
+		ret = append(ret, incusosapi.SystemSecurityTrustedCertificate{Fingerprint: fp})
+	}
+
+	return ret, nil
+}
+
+// RemoveTrustedCertificate revokes a trusted certificate from the application.
+func (*operationsCenter) RemoveTrustedCertificate(ctx context.Context, fingerprint string) error {
+	// Get the current security configuration.
+	body, err := doOCRequest(ctx, "http://localhost/1.0/system/security", http.MethodGet, nil)
+	if err != nil {
+		return err
+	}
+
+	sec := &api.SystemSecurity{}
+
+	err = json.Unmarshal(body, sec)
+	if err != nil {
+		return err
+	}
+
+	// Remove the certificate's fingerprint from the list of trusted clients.
+	index := slices.Index(sec.TrustedTLSClientCertFingerprints, strings.ToLower(fingerprint))
+	if index == -1 {
+		return errors.New("client certificate isn't trusted")
+	}
+
+	sec.TrustedTLSClientCertFingerprints = slices.Delete(sec.TrustedTLSClientCertFingerprints, index, index+1)
+
+	contentJSON, err := json.Marshal(sec)
+	if err != nil {
+		return err
+	}
+
+	_, err = doOCRequest(ctx, "http://localhost/1.0/system/security", http.MethodPut, contentJSON)
+
+	return err
+}
+
 // Operations Center specific helper to interact with the REST API.
 func doOCRequest(ctx context.Context, url string, method string, body []byte) ([]byte, error) {
 	return doRequest(ctx, "/run/operations-center/unix.socket", url, method, body)
@@ -4,23 +4,30 @@ import (
 	"context"
 	"crypto/tls"
 	"io"
+
+	"github.com/lxc/incus-os/incus-osd/api"
 )
 
 // Application represents an installed application.
 type Application interface { //nolint:interfacebloat
-	AddTrustedCertificate(ctx context.Context, name string, cert string) error
+	AddStoragePool(ctx context.Context, pool string, volume string) error
+	AddTrustedCertificate(ctx context.Context, cert *api.SystemSecurityTrustedCertificate) error
 	FactoryReset(ctx context.Context) error
 	GetBackup(archive io.Writer, complete bool) error
 	GetClientCertificate() (*tls.Certificate, error)
 	GetDependencies() []string
 	GetServerCertificate() (*tls.Certificate, error)
+	GetTrustedCertificates(ctx context.Context) ([]api.SystemSecurityTrustedCertificate, error)
 	Initialize(ctx context.Context) error
 	IsPrimary() bool
 	IsRunning(ctx context.Context) bool
 	Name() string
 	NeedsLateUpdateCheck() bool
+	RemoveTrustedCertificate(ctx context.Context, fingerprint string) error
 	Restart(ctx context.Context, version string) error
 	RestoreBackup(ctx context.Context, archive io.Reader) error
+	SetACMEConfiguration(ctx context.Context, cfg *api.SystemSecurityACME) error
+	SetOIDCConfiguration(ctx context.Context, cfg *api.SystemSecurityOIDC) error
 	Start(ctx context.Context, version string) error
 	Stop(ctx context.Context, version string) error
 	Update(ctx context.Context, version string) error
@@ -0,0 +1,353 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// LogPath is the path of the append-only audit log.
+var LogPath = "/var/lib/incus-os/audit.log"
+
+// MaxLogSize is the size past which the audit log is rotated. Only the previous log is kept, as LogPath
+// with a ".1" suffix, bounding the history to twice this size.
+var MaxLogSize int64 = 8 * 1024 * 1024
+
+// redactedValue replaces the value of secrets in the audit log.
+const redactedValue = "<redacted>"
+
+// sensitiveWords are the last words of field names holding secrets, such as "private_key" or "password".
+// Environment variables commonly hold credentials, such as the API token of a DNS provider.
+var sensitiveWords = []string{"environment", "key", "keys", "passphrase", "password", "pin", "psk", "secret", "token"}
+
+var logMutex sync.Mutex
+
+// Record appends an entry to the audit log and streams it to the remote syslog server, if configured.
+func Record(ctx context.Context, cfg api.SystemLoggingAudit, entry api.SystemLoggingAuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	err = rotateLog(int64(len(line) + 1))
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(LogPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	_, err = f.Write(append(line, '\n'))
+	if err != nil {
+		return err
+	}
+
+	if cfg.Syslog.Address == "" {
+		return nil
+	}
+
+	// Stream the entry in the background, so an unreachable syslog server doesn't delay the API.
+	go func() {
+		err := sendSyslog(context.WithoutCancel(ctx), cfg.Syslog, string(line))
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to send audit log entry to syslog server", "err", err)
+		}
+	}()
+
+	return nil
+}
+
+// List returns the audit log entries recorded after the provided time, keeping only the most recent
+// entries if a limit is set. The log is read from its end, so only the returned entries are parsed.
+func List(since time.Time, limit int) ([]api.SystemLoggingAuditEntry, error) {
+	ret := []api.SystemLoggingAuditEntry{}
+
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	// Go through the current log, then the rotated one, from the most recent entry.
+	for _, logPath := range []string{LogPath, LogPath + ".1"} {
+		content, err := os.ReadFile(logPath)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		lines := bytes.Split(bytes.TrimRight(content, "\n"), []byte("\n"))
+
+		for i := len(lines) - 1; i >= 0; i-- {
+			if len(lines[i]) == 0 {
+				continue
+			}
+
+			if limit > 0 && len(ret) >= limit {
+				slices.Reverse(ret)
+
+				return ret, nil
+			}
+
+			entry := api.SystemLoggingAuditEntry{}
+
+			err := json.Unmarshal(lines[i], &entry)
+			if err != nil {
+				return nil, err
+			}
+
+			// Entries are recorded in order, so anything older has been recorded before.
+			if !since.IsZero() {
+				ts, err := time.Parse(time.RFC3339, entry.Timestamp)
+				if err == nil && !ts.After(since) {
+					slices.Reverse(ret)
+
+					return ret, nil
+				}
+			}
+
+			ret = append(ret, entry)
+		}
+	}
+
+	slices.Reverse(ret)
+
+	return ret, nil
+}
+
+// rotateLog moves the audit log aside, replacing any previously rotated log, if appending the provided
+// number of bytes would make it grow past MaxLogSize. The caller must hold logMutex.
+func rotateLog(size int64) error {
+	info, err := os.Stat(LogPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	if info.Size() == 0 || info.Size()+size <= MaxLogSize {
+		return nil
+	}
+
+	return os.Rename(LogPath, LogPath+".1")
+}
+
+// Diff returns the fields that differ between two values, as marshaled to JSON. Fields are named
+// using their JSON path, and the values of secrets are redacted.
+func Diff(oldValue any, newValue any) ([]api.SystemLoggingAuditChange, error) {
+	oldObj, err := toGeneric(oldValue)
+	if err != nil {
+		return nil, err
+	}
+
+	newObj, err := toGeneric(newValue)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []api.SystemLoggingAuditChange{}
+	diffValues("", oldObj, newObj, false, &ret)
+
+	slices.SortFunc(ret, func(a api.SystemLoggingAuditChange, b api.SystemLoggingAuditChange) int {
+		return strings.Compare(a.Field, b.Field)
+	})
+
+	return ret, nil
+}
+
+// toGeneric converts a value to its generic JSON representation.
+func toGeneric(value any) (any, error) {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret any
+
+	err = json.Unmarshal(content, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// diffValues recursively compares two generic JSON values, recording any change.
+func diffValues(field string, oldValue any, newValue any, sensitive bool, changes *[]api.SystemLoggingAuditChange) {
+	if reflect.DeepEqual(oldValue, newValue) {
+		return
+	}
+
+	oldMap, oldIsMap := oldValue.(map[string]any)
+	newMap, newIsMap := newValue.(map[string]any)
+
+	// Descend into objects, so only the changed fields are recorded.
+	if oldIsMap && newIsMap {
+		keys := map[string]bool{}
+
+		for key := range oldMap {
+			keys[key] = true
+		}
+
+		for key := range newMap {
+			keys[key] = true
+		}
+
+		for key := range keys {
+			childField := key
+			if field != "" {
+				childField = field + "." + key
+			}
+
+			diffValues(childField, oldMap[key], newMap[key], sensitive || isSensitive(key), changes)
+		}
+
+		return
+	}
+
+	// Descend into lists of the same length, which covers in-place updates.
+	oldList, oldIsList := oldValue.([]any)
+	newList, newIsList := newValue.([]any)
+
+	if oldIsList && newIsList && len(oldList) == len(newList) {
+		for i := range oldList {
+			diffValues(fmt.Sprintf("%s[%d]", field, i), oldList[i], newList[i], sensitive, changes)
+		}
+
+		return
+	}
+
+	change := api.SystemLoggingAuditChange{
+		Field:    field,
+		OldValue: oldValue,
+		NewValue: newValue,
+	}
+
+	if sensitive {
+		change.OldValue = redact(oldValue)
+		change.NewValue = redact(newValue)
+	}
+
+	*changes = append(*changes, change)
+}
+
+// isSensitive reports whether the field with the provided name holds secrets.
+func isSensitive(name string) bool {
+	words := strings.Split(strings.ToLower(name), "_")
+
+	return slices.Contains(sensitiveWords, words[len(words)-1])
+}
+
+// RedactSecrets returns a copy of a generic JSON value with the values of all the fields holding secrets
+// redacted. Unlike in the audit log, the type of the redacted values is preserved, so the result can still
+// be unmarshaled into its original structure.
+func RedactSecrets(value any) any {
+	return redactSecrets(value, false)
+}
+
+// redactSecrets recursively redacts a generic JSON value, redacting all the strings within secrets.
+func redactSecrets(value any, sensitive bool) any {
+	switch v := value.(type) {
+	case map[string]any:
+		ret := make(map[string]any, len(v))
+		for key, child := range v {
+			ret[key] = redactSecrets(child, sensitive || isSensitive(key))
+		}
+
+		return ret
+	case []any:
+		ret := make([]any, 0, len(v))
+		for _, child := range v {
+			ret = append(ret, redactSecrets(child, sensitive))
+		}
+
+		return ret
+	case string:
+		if sensitive {
+			return redact(v)
+		}
+	}
+
+	return value
+}
+
+// redact hides a secret value while preserving whether it was set.
+func redact(value any) any {
+	if value == nil || value == "" {
+		return value
+	}
+
+	return redactedValue
+}
+
+// sendSyslog sends a message to a remote syslog server, using the same defaults as systemd-netlogd.
+func sendSyslog(ctx context.Context, cfg api.SystemLoggingSyslog, message string) error {
+	protocol := strings.ToLower(cfg.Protocol)
+	if protocol == "" {
+		protocol = "udp"
+	}
+
+	if protocol != "udp" && protocol != "tcp" {
+		return fmt.Errorf("unsupported syslog protocol %q", cfg.Protocol)
+	}
+
+	address := cfg.Address
+
+	_, _, err := net.SplitHostPort(address)
+	if err != nil {
+		address = net.JoinHostPort(address, "514")
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "-"
+	}
+
+	// Use the "authpriv" facility with the "notice" severity.
+	priority := 10*8 + 5
+	now := time.Now()
+
+	var msg string
+
+	if strings.ToLower(cfg.LogFormat) == "rfc3164" {
+		msg = fmt.Sprintf("<%d>%s %s incus-osd-audit: %s", priority, now.Format(time.Stamp), hostname, message)
+	} else {
+		msg = fmt.Sprintf("<%d>1 %s %s incus-osd - audit - %s", priority, now.Format(time.RFC3339), hostname, message)
+	}
+
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+
+	conn, err := dialer.DialContext(ctx, protocol, address)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	_ = conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+
+	_, err = conn.Write([]byte(msg + "\n"))
+
+	return err
+}
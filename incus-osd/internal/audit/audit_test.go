@@ -0,0 +1,179 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	oldCfg := api.SystemSecurityConfig{
+		EncryptionRecoveryKeys: []string{"old-key"},
+		OIDC:                   &api.SystemSecurityOIDC{Issuer: "https://sso.example.com", ClientID: "incus-os"},
+	}
+
+	newCfg := api.SystemSecurityConfig{
+		EncryptionRecoveryKeys: []string{"new-key"},
+		OIDC:                   &api.SystemSecurityOIDC{Issuer: "https://sso.example.com", ClientID: "incus"},
+	}
+
+	changes, err := Diff(oldCfg, newCfg)
+	require.NoError(t, err)
+	require.Equal(t, []api.SystemLoggingAuditChange{
+		{Field: "encryption_recovery_keys[0]", OldValue: "<redacted>", NewValue: "<redacted>"},
+		{Field: "oidc.client_id", OldValue: "incus-os", NewValue: "incus"},
+	}, changes)
+
+	// Adding a key changes the length of the list, which is recorded as a whole.
+	newCfg.EncryptionRecoveryKeys = append(newCfg.EncryptionRecoveryKeys, "another-key")
+	newCfg.OIDC = nil
+
+	changes, err = Diff(oldCfg, newCfg)
+	require.NoError(t, err)
+	require.Equal(t, []api.SystemLoggingAuditChange{
+		{Field: "encryption_recovery_keys", OldValue: "<redacted>", NewValue: "<redacted>"},
+		{Field: "oidc", OldValue: map[string]any{"issuer": "https://sso.example.com", "client_id": "incus-os", "audience": "", "scopes": "", "claim": ""}, NewValue: nil},
+	}, changes)
+
+	// No changes.
+	changes, err = Diff(oldCfg, oldCfg)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestDiffACME(t *testing.T) {
+	t.Parallel()
+
+	oldCfg := api.SystemSecurityConfig{
+		ACME: &api.SystemSecurityACME{Domain: "server.example.com", Challenge: "DNS-01", Provider: "cloudflare", ProviderEnvironment: []string{"CF_DNS_API_TOKEN=old-token"}},
+	}
+
+	newCfg := api.SystemSecurityConfig{
+		ACME: &api.SystemSecurityACME{Domain: "server.example.com", Challenge: "DNS-01", Provider: "cloudflare", ProviderEnvironment: []string{"CF_DNS_API_TOKEN=new-token", "CF_ZONE_API_TOKEN=zone-token"}},
+	}
+
+	// The DNS provider credentials never make it to the audit log.
+	changes, err := Diff(oldCfg, newCfg)
+	require.NoError(t, err)
+	require.Equal(t, []api.SystemLoggingAuditChange{
+		{Field: "acme.provider_environment", OldValue: "<redacted>", NewValue: "<redacted>"},
+	}, changes)
+
+	newCfg.ACME.ProviderEnvironment = []string{"CF_DNS_API_TOKEN=another-token"}
+
+	changes, err = Diff(oldCfg, newCfg)
+	require.NoError(t, err)
+	require.Equal(t, []api.SystemLoggingAuditChange{
+		{Field: "acme.provider_environment[0]", OldValue: "<redacted>", NewValue: "<redacted>"},
+	}, changes)
+}
+
+func TestIsSensitive(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, isSensitive("private_key"))
+	require.True(t, isSensitive("encryption_recovery_keys"))
+	require.True(t, isSensitive("password"))
+	require.True(t, isSensitive("psk"))
+	require.True(t, isSensitive("provider_environment"))
+	require.False(t, isSensitive("encryption_key_slots"))
+	require.False(t, isSensitive("thumbprint"))
+	require.False(t, isSensitive("keyrings"))
+}
+
+func TestRecordAndList(t *testing.T) { //nolint:paralleltest
+	LogPath = filepath.Join(t.TempDir(), "audit.log")
+
+	for i, ts := range []string{"2025-10-01T00:00:00Z", "2025-10-02T00:00:00Z", "2025-10-03T00:00:00Z"} {
+		err := Record(t.Context(), api.SystemLoggingAudit{}, api.SystemLoggingAuditEntry{Timestamp: ts, Method: "PUT", Path: "/1.0/system/logging", StatusCode: 200 + i})
+		require.NoError(t, err)
+	}
+
+	entries, err := List(time.Time{}, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	entries, err = List(time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC), 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, 201, entries[0].StatusCode)
+
+	entries, err = List(time.Time{}, 1)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, 202, entries[0].StatusCode)
+}
+
+func TestRotation(t *testing.T) { //nolint:paralleltest
+	LogPath = filepath.Join(t.TempDir(), "audit.log")
+	MaxLogSize = 300
+
+	defer func() { MaxLogSize = 8 * 1024 * 1024 }()
+
+	for i := range 10 {
+		ts := time.Date(2025, 10, 1+i, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+
+		err := Record(t.Context(), api.SystemLoggingAudit{}, api.SystemLoggingAuditEntry{Timestamp: ts, Method: "PUT", Path: "/1.0/system/logging", StatusCode: 200 + i})
+		require.NoError(t, err)
+	}
+
+	// The log never grows past its maximum size, and only the previous log is kept.
+	info, err := os.Stat(LogPath)
+	require.NoError(t, err)
+	require.LessOrEqual(t, info.Size(), MaxLogSize)
+	require.FileExists(t, LogPath+".1")
+	require.NoFileExists(t, LogPath+".2")
+
+	// Entries are listed in order across both logs.
+	entries, err := List(time.Time{}, 0)
+	require.NoError(t, err)
+	require.Less(t, len(entries), 10)
+	require.Equal(t, 209, entries[len(entries)-1].StatusCode)
+
+	for i := 1; i < len(entries); i++ {
+		require.Equal(t, entries[i-1].StatusCode+1, entries[i].StatusCode)
+	}
+
+	// Both limits stop at the most recent entries.
+	entries, err = List(time.Time{}, 4)
+	require.NoError(t, err)
+	require.Equal(t, []int{206, 207, 208, 209}, []int{entries[0].StatusCode, entries[1].StatusCode, entries[2].StatusCode, entries[3].StatusCode})
+
+	entries, err = List(time.Date(2025, 10, 8, 0, 0, 0, 0, time.UTC), 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, 208, entries[0].StatusCode)
+}
+
+func TestRedactSecrets(t *testing.T) {
+	t.Parallel()
+
+	config := map[string]any{
+		"private_key": "secret-value",
+		"password":    "",
+		"keyrings":    map[string]any{"admin": map[string]any{"key": "ceph-key", "caps": 3.0}},
+		"peers":       []any{map[string]any{"endpoint": "192.0.2.1", "psk": "peer-psk"}},
+	}
+
+	value := map[string]any{"name": "wg0", "config": config}
+
+	require.Equal(t, map[string]any{
+		"name": "wg0",
+		"config": map[string]any{
+			"private_key": "<redacted>",
+			"password":    "",
+			"keyrings":    map[string]any{"admin": map[string]any{"key": "<redacted>", "caps": 3.0}},
+			"peers":       []any{map[string]any{"endpoint": "192.0.2.1", "psk": "<redacted>"}},
+		},
+	}, RedactSecrets(value))
+
+	// The original value is left untouched.
+	require.Equal(t, "secret-value", config["private_key"])
+}
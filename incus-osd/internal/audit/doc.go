@@ -0,0 +1,2 @@
+// Package audit provides logic to record and query the audit log of API changes.
+package audit
@@ -320,18 +320,32 @@ func processNewState(ctx context.Context, oldState **state.State, newState *stat
 	newState.OS = (*oldState).OS
 
 	// Clear any stale state from the new struct.
+	newState.Services.BGP.State = api.ServiceBGPState{}
 	newState.Services.Ceph.State = api.ServiceCephState{}
+	newState.Services.DRBD.State = api.ServiceDRBDState{}
+	newState.Services.FC.State = api.ServiceFCState{}
+	newState.Services.HAProxy.State = api.ServiceHAProxyState{}
+	newState.Services.IPMI.State = api.ServiceIPMIState{}
 	newState.Services.ISCSI.State = api.ServiceISCSIState{}
+	newState.Services.LLDP.State = api.ServiceLLDPState{}
+	newState.Services.LogForward.State = api.ServiceLogForwardState{}
 	newState.Services.LVM.State = api.ServiceLVMState{}
+	newState.Services.Metrics.State = api.ServiceMetricsState{}
 	newState.Services.Multipath.State = api.ServiceMultipathState{}
+	newState.Services.NFS.State = api.ServiceNFSState{}
 	newState.Services.NVME.State = api.ServiceNVMEState{}
 	newState.Services.OVN.State = api.ServiceOVNState{}
+	newState.Services.Redfish.State = api.ServiceRedfishState{}
+	newState.Services.SMARTD.State = api.ServiceSMARTDState{}
 	newState.Services.USBIP.State = api.ServiceUSBIPState{}
+	newState.Services.VRRP.State = api.ServiceVRRPState{}
+	newState.System.Firmware.State = api.SystemFirmwareState{}
 	newState.System.Logging.State = api.SystemLoggingState{}
 	newState.System.Network.State = api.SystemNetworkState{}
 	newState.System.Provider.State = api.SystemProviderState{}
 	newState.System.Security.State = api.SystemSecurityState{}
 	newState.System.Update.State = api.SystemUpdateState{}
+	newState.System.Watchdog.State = api.SystemWatchdogState{}
 
 	// If instructed to skip restoring network MACs, replace any value with the Interface
 	// or Bond name, which will be dynamically resolved to the actual device's MAC when
@@ -348,6 +362,15 @@ func processNewState(ctx context.Context, oldState **state.State, newState *stat
 				newState.System.Network.Config.Bonds[i].Hwaddr = newState.System.Network.Config.Bonds[i].Name
 			}
 		}
+
+		for i := range newState.System.Network.Config.Wifi {
+			newState.System.Network.Config.Wifi[i].Hwaddr = newState.System.Network.Config.Wifi[i].Name
+		}
+
+		// Bridges fall back to the MAC of their first member when no address is set.
+		for i := range newState.System.Network.Config.Bridges {
+			newState.System.Network.Config.Bridges[i].Hwaddr = ""
+		}
 	}
 
 	if !slices.Contains(skipOptions, "encryption-recovery-keys") {
@@ -370,7 +393,7 @@ func processNewState(ctx context.Context, oldState **state.State, newState *stat
 		newState.System.Security.Config.EncryptionRecoveryKeys = []string{}
 
 		for _, key := range newKeys {
-			err := systemd.AddEncryptionKey(ctx, newState, key)
+			err := systemd.AddEncryptionKey(ctx, newState, key, systemd.RecoveryKeyCreatedByUnknown)
 			if err != nil {
 				return err
 			}
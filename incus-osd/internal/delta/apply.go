@@ -0,0 +1,252 @@
+package delta
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+
+	apiupdate "github.com/lxc/incus-os/incus-osd/api/images"
+)
+
+// chunkLocation records where a chunk can be read from locally.
+type chunkLocation struct {
+	fd     *os.File
+	offset int64
+	size   int64
+}
+
+// Apply reconstructs the file described by the chunk index, copying the chunks found in the seed files
+// and downloading the others from the first chunk store providing them. It returns the number of bytes
+// downloaded.
+func Apply(ctx context.Context, client *http.Client, index *apiupdate.ChunkIndex, storeURLs []string, seeds []string, target string, progressFunc func(float64)) (int64, error) {
+	for _, chunk := range index.Chunks {
+		if len(chunk.Sha256) != sha256.Size*2 {
+			return 0, fmt.Errorf("invalid chunk hash %q", chunk.Sha256)
+		}
+	}
+
+	// Locate the chunks already available in the seeds.
+	locations := map[string]chunkLocation{}
+	seedFiles := []*os.File{}
+
+	defer func() {
+		for _, fd := range seedFiles {
+			_ = fd.Close()
+		}
+	}()
+
+	for _, seed := range seeds {
+		fd, err := os.Open(seed) //nolint:gosec
+		if err != nil {
+			continue
+		}
+
+		seedFiles = append(seedFiles, fd)
+
+		err = scanSeed(fd, index, locations)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// Write to a temporary file, only replacing the target once complete. This also allows the
+	// target to be used as a seed.
+	partialPath := target + ".partial"
+
+	fd, err := os.OpenFile(partialPath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0o600) //nolint:gosec
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() { _ = os.Remove(partialPath) }()
+	defer func() { _ = fd.Close() }()
+
+	fileHash := sha256.New()
+
+	// Stores which can't be reached are skipped for the remaining chunks.
+	stores := slices.Clone(storeURLs)
+
+	var offset int64
+
+	var downloaded int64
+
+	for _, chunk := range index.Chunks {
+		var data []byte
+
+		location, ok := locations[chunk.Sha256]
+		if ok {
+			data = make([]byte, location.size)
+
+			_, err = location.fd.ReadAt(data, location.offset)
+			if err != nil {
+				return 0, err
+			}
+		} else {
+			data, err = fetchChunk(ctx, client, &stores, chunk)
+			if err != nil {
+				return 0, err
+			}
+
+			downloaded += chunk.Size
+		}
+
+		err = checkChunk(data, chunk)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = fd.Write(data)
+		if err != nil {
+			return 0, err
+		}
+
+		_, _ = fileHash.Write(data)
+
+		// Chunks repeated within the file only need to be fetched once.
+		if !ok {
+			locations[chunk.Sha256] = chunkLocation{fd: fd, offset: offset, size: chunk.Size}
+		}
+
+		offset += chunk.Size
+
+		if progressFunc != nil && index.Size > 0 {
+			progressFunc(float64(offset) / float64(index.Size))
+		}
+	}
+
+	// Check the reconstructed file.
+	if offset != index.Size || hex.EncodeToString(fileHash.Sum(nil)) != index.Sha256 {
+		return 0, errors.New("sha256 mismatch for file " + target)
+	}
+
+	err = fd.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	err = os.Rename(partialPath, target)
+	if err != nil {
+		return 0, err
+	}
+
+	return downloaded, nil
+}
+
+// scanSeed records the location of the chunks of a seed file which are part of the chunk index.
+func scanSeed(fd *os.File, index *apiupdate.ChunkIndex, locations map[string]chunkLocation) error {
+	needed := make(map[string]bool, len(index.Chunks))
+	for _, chunk := range index.Chunks {
+		_, found := locations[chunk.Sha256]
+		if !found {
+			needed[chunk.Sha256] = true
+		}
+	}
+
+	if len(needed) == 0 {
+		return nil
+	}
+
+	c := newChunker(fd)
+
+	var offset int64
+
+	for {
+		data, err := c.next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		chunkHash := sha256.Sum256(data)
+		hash := hex.EncodeToString(chunkHash[:])
+
+		if needed[hash] {
+			locations[hash] = chunkLocation{fd: fd, offset: offset, size: int64(len(data))}
+			delete(needed, hash)
+
+			if len(needed) == 0 {
+				return nil
+			}
+		}
+
+		offset += int64(len(data))
+	}
+}
+
+// fetchChunk downloads a chunk from the first store providing a valid copy of it, removing the stores
+// which can't be reached from the list.
+func fetchChunk(ctx context.Context, client *http.Client, stores *[]string, chunk apiupdate.ChunkIndexEntry) ([]byte, error) {
+	err := errors.New("no chunk store available")
+
+	for _, storeURL := range slices.Clone(*stores) {
+		var data []byte
+
+		data, err = downloadChunk(ctx, client, storeURL, chunk)
+		if err == nil {
+			err = checkChunk(data, chunk)
+			if err == nil {
+				return data, nil
+			}
+
+			continue
+		}
+
+		urlErr := &url.Error{}
+		if errors.As(err, &urlErr) {
+			*stores = slices.DeleteFunc(*stores, func(u string) bool { return u == storeURL })
+		}
+	}
+
+	return nil, err
+}
+
+// downloadChunk fetches and decompresses a chunk from the chunk store.
+func downloadChunk(ctx context.Context, client *http.Client, storeURL string, chunk apiupdate.ChunkIndexEntry) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, storeURL+"/"+ChunkPath(chunk.Sha256), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download chunk %s: %s", chunk.Sha256, resp.Status)
+	}
+
+	body, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	defer body.Close()
+
+	// Never read more than the expected size.
+	return io.ReadAll(io.LimitReader(body, chunk.Size+1))
+}
+
+// checkChunk validates the content of a chunk against the chunk index.
+func checkChunk(data []byte, chunk apiupdate.ChunkIndexEntry) error {
+	chunkHash := sha256.Sum256(data)
+
+	if int64(len(data)) != chunk.Size || hex.EncodeToString(chunkHash[:]) != chunk.Sha256 {
+		return fmt.Errorf("sha256 mismatch for chunk %s", chunk.Sha256)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package delta
+
+import (
+	"errors"
+	"io"
+)
+
+const (
+	minChunkSize = 16 * 1024
+	maxChunkSize = 256 * 1024
+
+	// boundaryMask gives an average chunk size of 64KiB. The top bits are used as they depend on
+	// the last 64 bytes, while the bottom ones only depend on the last few bytes.
+	boundaryMask = uint64(0xffff) << 48
+)
+
+// gearTable holds the per-byte values of the rolling gear hash. It must never change, as the chunk
+// boundaries must match between the image server and the systems.
+var gearTable = func() [256]uint64 {
+	var table [256]uint64
+
+	// splitmix64 with a fixed seed.
+	seed := uint64(0x696e6375732d6f73)
+	for i := range table {
+		seed += 0x9e3779b97f4a7c15
+
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+
+	return table
+}()
+
+// chunker splits a stream into content-defined chunks, so that an insertion or removal only affects
+// the chunks around it.
+type chunker struct {
+	r     io.Reader
+	buf   []byte
+	start int
+	end   int
+	eof   bool
+}
+
+func newChunker(r io.Reader) *chunker {
+	return &chunker{r: r, buf: make([]byte, maxChunkSize)}
+}
+
+// next returns the next chunk, or io.EOF once the stream is exhausted. The returned slice is only
+// valid until the following call.
+func (c *chunker) next() ([]byte, error) {
+	// Refill the buffer.
+	if c.end-c.start < maxChunkSize && !c.eof {
+		copy(c.buf, c.buf[c.start:c.end])
+		c.end -= c.start
+		c.start = 0
+
+		for c.end < len(c.buf) && !c.eof {
+			n, err := c.r.Read(c.buf[c.end:])
+			c.end += n
+
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					return nil, err
+				}
+
+				c.eof = true
+			}
+		}
+	}
+
+	if c.start == c.end {
+		return nil, io.EOF
+	}
+
+	data := c.buf[c.start:c.end]
+	size := findBoundary(data)
+	c.start += size
+
+	return data[:size], nil
+}
+
+// findBoundary returns the size of the chunk at the start of data.
+func findBoundary(data []byte) int {
+	if len(data) <= minChunkSize {
+		return len(data)
+	}
+
+	limit := min(len(data), maxChunkSize)
+
+	var hash uint64
+
+	// Warm up the hash over the 64 bytes preceding the minimum chunk size.
+	for i := minChunkSize - 64; i < limit; i++ {
+		hash = (hash << 1) + gearTable[data[i]]
+
+		if i >= minChunkSize && hash&boundaryMask == 0 {
+			return i + 1
+		}
+	}
+
+	return limit
+}
@@ -0,0 +1,132 @@
+package delta
+
+import (
+	"bytes"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunker(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 4*1024*1024)
+	_, _ = rand.New(rand.NewSource(1)).Read(data) //nolint:gosec
+
+	// Inserting data only affects the surrounding chunks.
+	modified := append(append(append([]byte{}, data[:1024*1024]...), []byte("inserted")...), data[1024*1024:]...)
+
+	chunks := map[string]bool{}
+
+	index, err := CreateIndex(bytes.NewReader(data), t.TempDir())
+	require.NoError(t, err)
+	require.Equal(t, int64(len(data)), index.Size)
+
+	for _, chunk := range index.Chunks {
+		require.GreaterOrEqual(t, chunk.Size, int64(minChunkSize))
+		require.LessOrEqual(t, chunk.Size, int64(maxChunkSize))
+
+		chunks[chunk.Sha256] = true
+	}
+
+	modifiedIndex, err := CreateIndex(bytes.NewReader(modified), t.TempDir())
+	require.NoError(t, err)
+
+	changed := 0
+
+	for _, chunk := range modifiedIndex.Chunks {
+		if !chunks[chunk.Sha256] {
+			changed++
+		}
+	}
+
+	require.LessOrEqual(t, changed, 2)
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 2*1024*1024)
+	_, _ = rand.New(rand.NewSource(2)).Read(data) //nolint:gosec
+
+	// The new version changes the start of the file and repeats a zeroed block.
+	modified := append(append(append([]byte("new header"), data[4096:]...), make([]byte, 512*1024)...), make([]byte, 512*1024)...)
+
+	// Publish the new version.
+	storePath := t.TempDir()
+
+	index, err := CreateIndex(bytes.NewReader(modified), storePath)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.FileServer(http.Dir(storePath)))
+	defer server.Close()
+
+	// Reconstruct it from the old version.
+	tmpDir := t.TempDir()
+	seedPath := filepath.Join(tmpDir, "seed")
+	targetPath := filepath.Join(tmpDir, "target")
+
+	require.NoError(t, os.WriteFile(seedPath, data, 0o600))
+
+	downloaded, err := Apply(t.Context(), server.Client(), index, []string{server.URL}, []string{seedPath, filepath.Join(tmpDir, "missing")}, targetPath, nil)
+	require.NoError(t, err)
+	require.Less(t, downloaded, int64(len(modified)/2))
+
+	content, err := os.ReadFile(targetPath)
+	require.NoError(t, err)
+	require.Equal(t, modified, content)
+
+	// Without a seed, everything but the repeated chunks is downloaded.
+	downloaded, err = Apply(t.Context(), server.Client(), index, []string{server.URL}, nil, targetPath, nil)
+	require.NoError(t, err)
+	require.Greater(t, downloaded, int64(len(data)/2))
+
+	// A corrupted chunk store is detected.
+	index.Chunks[0].Sha256 = index.Chunks[1].Sha256
+
+	_, err = Apply(t.Context(), server.Client(), index, []string{server.URL}, []string{seedPath}, targetPath, nil)
+	require.Error(t, err)
+
+	content, err = os.ReadFile(targetPath)
+	require.NoError(t, err)
+	require.Equal(t, modified, content)
+}
+
+func TestLocalStore(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 1024*1024)
+	_, _ = rand.New(rand.NewSource(3)).Read(data) //nolint:gosec
+
+	index, err := CreateIndex(bytes.NewReader(data), t.TempDir())
+	require.NoError(t, err)
+
+	// A peer which already has the file.
+	peerPath := filepath.Join(t.TempDir(), "update.img")
+	require.NoError(t, os.WriteFile(peerPath, data, 0o600))
+
+	peer := httptest.NewServer(NewLocalStore(func() []string { return []string{peerPath} }))
+	defer peer.Close()
+
+	// Unreachable stores are skipped.
+	targetPath := filepath.Join(t.TempDir(), "target")
+
+	downloaded, err := Apply(t.Context(), peer.Client(), index, []string{"http://127.0.0.1:1", peer.URL}, nil, targetPath, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(data)), downloaded)
+
+	content, err := os.ReadFile(targetPath)
+	require.NoError(t, err)
+	require.Equal(t, data, content)
+
+	// Chunks are no longer served once the file changes.
+	require.NoError(t, os.WriteFile(peerPath, []byte("replaced"), 0o600))
+
+	_, err = Apply(t.Context(), peer.Client(), index, []string{peer.URL}, nil, targetPath, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,3 @@
+// Package delta provides logic to split update files into content-defined chunks and to reconstruct
+// them from the chunks already present locally, only downloading the missing ones.
+package delta
@@ -0,0 +1,107 @@
+package delta
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	apiupdate "github.com/lxc/incus-os/incus-osd/api/images"
+)
+
+// ChunkPath returns the path of a chunk relative to the chunk store.
+func ChunkPath(hash string) string {
+	return hash[:4] + "/" + hash + ".gz"
+}
+
+// CreateIndex splits the content of the reader into chunks, adding any new chunk to the chunk store,
+// and returns the resulting chunk index.
+func CreateIndex(r io.Reader, storePath string) (*apiupdate.ChunkIndex, error) {
+	index := &apiupdate.ChunkIndex{
+		Format: "1.0",
+		Chunks: []apiupdate.ChunkIndexEntry{},
+	}
+
+	fileHash := sha256.New()
+	c := newChunker(io.TeeReader(r, fileHash))
+
+	for {
+		data, err := c.next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, err
+		}
+
+		chunkHash := sha256.Sum256(data)
+		hash := hex.EncodeToString(chunkHash[:])
+
+		err = storeChunk(filepath.Join(storePath, ChunkPath(hash)), data)
+		if err != nil {
+			return nil, err
+		}
+
+		index.Chunks = append(index.Chunks, apiupdate.ChunkIndexEntry{Sha256: hash, Size: int64(len(data))})
+		index.Size += int64(len(data))
+	}
+
+	index.Sha256 = hex.EncodeToString(fileHash.Sum(nil))
+
+	return index, nil
+}
+
+// storeChunk writes a compressed chunk to the chunk store, unless already present.
+func storeChunk(path string, data []byte) error {
+	_, err := os.Stat(path)
+	if err == nil {
+		return nil
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first to never expose a partial chunk.
+	fd, err := os.CreateTemp(filepath.Dir(path), ".chunk-")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(fd.Name()) }()
+
+	gz := gzip.NewWriter(fd)
+
+	_, err = gz.Write(data)
+	if err != nil {
+		_ = fd.Close()
+
+		return err
+	}
+
+	err = gz.Close()
+	if err != nil {
+		_ = fd.Close()
+
+		return err
+	}
+
+	err = fd.Chmod(0o644)
+	if err != nil {
+		_ = fd.Close()
+
+		return err
+	}
+
+	err = fd.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(fd.Name(), path)
+}
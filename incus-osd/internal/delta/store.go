@@ -0,0 +1,186 @@
+package delta
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// localChunk records where a chunk of a local file can be found.
+type localChunk struct {
+	path   string
+	offset int64
+	size   int64
+}
+
+// localFile records the state of a local file when its chunks were last scanned.
+type localFile struct {
+	size    int64
+	modTime time.Time
+}
+
+// LocalStore serves the chunks of local files as a chunk store, allowing other systems to fetch the
+// update files already downloaded by this one. As chunks are validated against the signed chunk
+// index by the caller, the store doesn't need to be trusted.
+type LocalStore struct {
+	getPaths func() []string
+
+	mu     sync.Mutex
+	files  map[string]localFile
+	chunks map[string]localChunk
+}
+
+// NewLocalStore returns a chunk store serving the files returned by getPaths. The files are scanned
+// again whenever they change.
+func NewLocalStore(getPaths func() []string) *LocalStore {
+	return &LocalStore{
+		getPaths: getPaths,
+		files:    map[string]localFile{},
+		chunks:   map[string]localChunk{},
+	}
+}
+
+// ServeHTTP serves a compressed chunk, using the same layout as the chunk store.
+func (s *LocalStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	hash := strings.TrimSuffix(path.Base(r.URL.Path), ".gz")
+	if len(hash) != sha256.Size*2 || strings.TrimPrefix(r.URL.Path, "/") != ChunkPath(hash) {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	data, err := s.getChunk(hash)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+
+	gz := gzip.NewWriter(w)
+	_, _ = gz.Write(data)
+	_ = gz.Close()
+}
+
+// getChunk returns the content of a chunk, checking it still matches its hash.
+func (s *LocalStore) getChunk(hash string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refresh()
+
+	chunk, ok := s.chunks[hash]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+
+	fd, err := os.Open(chunk.path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer fd.Close()
+
+	data := make([]byte, chunk.size)
+
+	_, err = fd.ReadAt(data, chunk.offset)
+	if err != nil {
+		return nil, err
+	}
+
+	// The file may have been replaced since it was scanned.
+	chunkHash := sha256.Sum256(data)
+	if hex.EncodeToString(chunkHash[:]) != hash {
+		return nil, errors.New("chunk content changed")
+	}
+
+	return data, nil
+}
+
+// refresh scans the files which changed since the last scan. The caller must hold the lock.
+func (s *LocalStore) refresh() {
+	changed := false
+	paths := map[string]bool{}
+
+	for _, filePath := range s.getPaths() {
+		paths[filePath] = true
+
+		info, err := os.Stat(filePath)
+		if err != nil {
+			paths[filePath] = false
+
+			continue
+		}
+
+		current := localFile{size: info.Size(), modTime: info.ModTime()}
+		if s.files[filePath] != current {
+			s.files[filePath] = current
+			changed = true
+		}
+	}
+
+	for filePath := range s.files {
+		if !paths[filePath] {
+			delete(s.files, filePath)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return
+	}
+
+	s.chunks = map[string]localChunk{}
+
+	for filePath := range s.files {
+		err := s.scanFile(filePath)
+		if err != nil {
+			delete(s.files, filePath)
+		}
+	}
+}
+
+// scanFile records the location of the chunks of a local file.
+func (s *LocalStore) scanFile(filePath string) error {
+	fd, err := os.Open(filePath) //nolint:gosec
+	if err != nil {
+		return err
+	}
+
+	defer fd.Close()
+
+	c := newChunker(fd)
+
+	var offset int64
+
+	for {
+		data, err := c.next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		chunkHash := sha256.Sum256(data)
+		s.chunks[hex.EncodeToString(chunkHash[:])] = localChunk{path: filePath, offset: offset, size: int64(len(data))}
+
+		offset += int64(len(data))
+	}
+}
@@ -0,0 +1,3 @@
+// Package extensions provides logic to side-load additional signed system extensions, such as
+// vendor storage drivers or monitoring agents, keeping them active across OS updates.
+package extensions
@@ -0,0 +1,278 @@
+package extensions
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+	"github.com/lxc/incus-os/incus-osd/internal/systemd"
+)
+
+// StoragePath is the directory holding the uploaded system extension images, outside of the
+// systemd-sysext search path so they are only merged once activated.
+var StoragePath = "/var/lib/incus-os/extensions"
+
+// ErrNotFound is returned when a side-loaded system extension doesn't exist.
+var ErrNotFound = errors.New("system extension not found")
+
+var nameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidateName checks that the name of a side-loaded system extension is usable and doesn't
+// conflict with an installed application.
+func ValidateName(s *state.State, name string) error {
+	if !nameRegex.MatchString(name) {
+		return fmt.Errorf("invalid system extension name %q", name)
+	}
+
+	_, ok := s.Applications[name]
+	if ok {
+		return fmt.Errorf("system extension name %q conflicts with an installed application", name)
+	}
+
+	return nil
+}
+
+// Upload stores a new or replacement system extension image, after validating it is signed by a
+// trusted certificate. A replaced extension which was active is refreshed immediately.
+func Upload(ctx context.Context, s *state.State, name string, r io.Reader) error {
+	err := ValidateName(s, name)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(StoragePath, 0o700)
+	if err != nil {
+		return err
+	}
+
+	// Write the image next to its final location, so a partially written one is never used.
+	tmpPath := getPath(name) + ".new"
+
+	// #nosec G304
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	hash := sha256.New()
+
+	size, err := io.Copy(io.MultiWriter(f, hash), r)
+	if err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	err = systemd.VerifyExtensionCertificateFingerprint(ctx, tmpPath)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmpPath, getPath(name))
+	if err != nil {
+		return err
+	}
+
+	ext := api.SystemExtension{
+		Name:       name,
+		SHA256:     hex.EncodeToString(hash.Sum(nil)),
+		Size:       size,
+		UploadedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	idx := getIndex(s, name)
+	if idx < 0 {
+		s.System.Extensions.State.Extensions = append(s.System.Extensions.State.Extensions, ext)
+		_ = s.Save()
+
+		return nil
+	}
+
+	ext.Active = s.System.Extensions.State.Extensions[idx].Active
+	s.System.Extensions.State.Extensions[idx] = ext
+	_ = s.Save()
+
+	if ext.Active {
+		return systemd.RefreshExtensions(ctx)
+	}
+
+	return nil
+}
+
+// Activate merges a side-loaded system extension into the running system.
+func Activate(ctx context.Context, s *state.State, name string) error {
+	idx := getIndex(s, name)
+	if idx < 0 {
+		return ErrNotFound
+	}
+
+	// Validate the image again, as the trusted certificates may have changed since its upload.
+	err := systemd.VerifyExtensionCertificateFingerprint(ctx, getPath(name))
+	if err != nil {
+		return err
+	}
+
+	err = link(name)
+	if err != nil {
+		return err
+	}
+
+	s.System.Extensions.State.Extensions[idx].Active = true
+	s.System.Extensions.State.Extensions[idx].LastError = ""
+	_ = s.Save()
+
+	return systemd.RefreshExtensions(ctx)
+}
+
+// Deactivate removes a side-loaded system extension from the running system, keeping its image.
+func Deactivate(ctx context.Context, s *state.State, name string) error {
+	idx := getIndex(s, name)
+	if idx < 0 {
+		return ErrNotFound
+	}
+
+	err := unlink(name)
+	if err != nil {
+		return err
+	}
+
+	s.System.Extensions.State.Extensions[idx].Active = false
+	_ = s.Save()
+
+	return systemd.RefreshExtensions(ctx)
+}
+
+// Delete deactivates a side-loaded system extension and removes its image.
+func Delete(ctx context.Context, s *state.State, name string) error {
+	idx := getIndex(s, name)
+	if idx < 0 {
+		return ErrNotFound
+	}
+
+	wasActive := s.System.Extensions.State.Extensions[idx].Active
+
+	err := unlink(name)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(getPath(name))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	s.System.Extensions.State.Extensions = slices.Delete(s.System.Extensions.State.Extensions, idx, idx+1)
+	_ = s.Save()
+
+	if wasActive {
+		return systemd.RefreshExtensions(ctx)
+	}
+
+	return nil
+}
+
+// Restore re-validates the active side-loaded system extensions on startup, so they remain in use
+// after an OS update. Extensions which are no longer trusted are deactivated and their error recorded.
+func Restore(ctx context.Context, s *state.State) error {
+	changed := false
+
+	for i, ext := range s.System.Extensions.State.Extensions {
+		if !ext.Active {
+			continue
+		}
+
+		err := systemd.VerifyExtensionCertificateFingerprint(ctx, getPath(ext.Name))
+		if err == nil {
+			err = link(ext.Name)
+		}
+
+		if err != nil {
+			slog.WarnContext(ctx, "Deactivating system extension", "name", ext.Name, "err", err.Error())
+
+			_ = unlink(ext.Name)
+
+			s.System.Extensions.State.Extensions[i].Active = false
+			s.System.Extensions.State.Extensions[i].LastError = err.Error()
+
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	_ = s.Save()
+
+	return systemd.RefreshExtensions(ctx)
+}
+
+// getIndex returns the index of a side-loaded system extension in the state, or -1 if it doesn't exist.
+func getIndex(s *state.State, name string) int {
+	return slices.IndexFunc(s.System.Extensions.State.Extensions, func(ext api.SystemExtension) bool {
+		return ext.Name == name
+	})
+}
+
+// getPath returns the location of a side-loaded system extension image.
+func getPath(name string) string {
+	return filepath.Join(StoragePath, name+".raw")
+}
+
+// link makes a side-loaded system extension image visible to systemd-sysext.
+func link(name string) error {
+	err := os.MkdirAll(systemd.SystemExtensionsPath, 0o755)
+	if err != nil {
+		return err
+	}
+
+	target := filepath.Join(systemd.SystemExtensionsPath, name+".raw")
+
+	fi, err := os.Lstat(target)
+	if err == nil && fi.Mode()&os.ModeSymlink == 0 {
+		return fmt.Errorf("system extension %q conflicts with an existing image", name)
+	}
+
+	current, err := os.Readlink(target)
+	if err == nil && current == getPath(name) {
+		return nil
+	}
+
+	err = os.Remove(target)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return os.Symlink(getPath(name), target)
+}
+
+// unlink hides a side-loaded system extension image from systemd-sysext.
+func unlink(name string) error {
+	target := filepath.Join(systemd.SystemExtensionsPath, name+".raw")
+
+	// Only ever remove the link, never an application's image.
+	_, err := os.Readlink(target)
+	if err != nil {
+		return nil //nolint:nilerr
+	}
+
+	return os.Remove(target)
+}
@@ -0,0 +1,62 @@
+package extensions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+	"github.com/lxc/incus-os/incus-osd/internal/systemd"
+)
+
+func TestValidateName(t *testing.T) {
+	t.Parallel()
+
+	s := &state.State{Applications: map[string]api.Application{"incus": {}}}
+
+	require.NoError(t, ValidateName(s, "vendor-storage"))
+	require.NoError(t, ValidateName(s, "node_exporter2"))
+	require.Error(t, ValidateName(s, ""))
+	require.Error(t, ValidateName(s, "../incus"))
+	require.Error(t, ValidateName(s, "-storage"))
+	require.Error(t, ValidateName(s, "Storage"))
+	require.ErrorContains(t, ValidateName(s, "incus"), "conflicts with an installed application")
+}
+
+func TestLinks(t *testing.T) { //nolint:paralleltest
+	StoragePath = t.TempDir()
+	systemd.SystemExtensionsPath = t.TempDir()
+
+	err := os.WriteFile(getPath("vendor-storage"), []byte("image"), 0o600)
+	require.NoError(t, err)
+
+	// Linking is idempotent.
+	require.NoError(t, link("vendor-storage"))
+	require.NoError(t, link("vendor-storage"))
+
+	target, err := os.Readlink(filepath.Join(systemd.SystemExtensionsPath, "vendor-storage.raw"))
+	require.NoError(t, err)
+	require.Equal(t, getPath("vendor-storage"), target)
+
+	// An application's image is never replaced nor removed.
+	err = os.WriteFile(filepath.Join(systemd.SystemExtensionsPath, "incus.raw"), []byte("image"), 0o600)
+	require.NoError(t, err)
+
+	require.ErrorContains(t, link("incus"), "conflicts with an existing image")
+	require.NoError(t, unlink("incus"))
+	require.FileExists(t, filepath.Join(systemd.SystemExtensionsPath, "incus.raw"))
+
+	// Deleting an inactive extension removes both its link and image.
+	s := &state.State{}
+	s.System.Extensions.State.Extensions = []api.SystemExtension{{Name: "vendor-storage"}}
+
+	require.NoError(t, Delete(t.Context(), s, "vendor-storage"))
+	require.Empty(t, s.System.Extensions.State.Extensions)
+	require.NoFileExists(t, getPath("vendor-storage"))
+	require.NoFileExists(t, filepath.Join(systemd.SystemExtensionsPath, "vendor-storage.raw"))
+
+	require.ErrorIs(t, Delete(t.Context(), s, "vendor-storage"), ErrNotFound)
+}
@@ -0,0 +1,2 @@
+// Package health provides the health checks run after booting into a new OS version.
+package health
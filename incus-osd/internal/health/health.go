@@ -0,0 +1,305 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/applications"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+	"github.com/lxc/incus-os/incus-osd/internal/storage"
+	"github.com/lxc/incus-os/incus-osd/internal/systemd"
+)
+
+const (
+	// defaultGracePeriod is the time given to the health checks to pass when not configured.
+	defaultGracePeriod = 10 * time.Minute
+
+	// checkInterval is the time between two runs of the health checks while waiting for them to pass.
+	checkInterval = 30 * time.Second
+)
+
+// ValidateConfig checks that the health checks configuration is usable.
+func ValidateConfig(cfg *api.SystemUpdateHealthChecks) error {
+	if cfg == nil {
+		return nil
+	}
+
+	if cfg.GracePeriod != "" {
+		_, err := time.ParseDuration(cfg.GracePeriod)
+		if err != nil {
+			return fmt.Errorf("invalid health check grace period %q", cfg.GracePeriod)
+		}
+	}
+
+	names := []string{}
+
+	for _, probe := range cfg.Probes {
+		if probe.Name == "" || slices.Contains(names, probe.Name) {
+			return fmt.Errorf("health probes must have a unique name, got %q", probe.Name)
+		}
+
+		names = append(names, probe.Name)
+
+		switch probe.Type {
+		case "http":
+			u, err := url.Parse(probe.Target)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				return fmt.Errorf("invalid URL %q for health probe %q", probe.Target, probe.Name)
+			}
+		case "tcp":
+			_, _, err := net.SplitHostPort(probe.Target)
+			if err != nil {
+				return fmt.Errorf("invalid address %q for health probe %q", probe.Target, probe.Name)
+			}
+		default:
+			return fmt.Errorf("invalid type %q for health probe %q", probe.Type, probe.Name)
+		}
+	}
+
+	return nil
+}
+
+// Prepare records the OS version whose health checks will run once booted. The checks are run
+// beforehand, so the checks already failing don't cause the new version to be rolled back.
+func Prepare(ctx context.Context, s *state.State, version string) {
+	cfg := s.System.Update.Config.HealthChecks
+	if cfg == nil || !cfg.Enabled {
+		s.OS.HealthCheckRelease = ""
+		s.OS.HealthCheckKnownFailures = nil
+
+		return
+	}
+
+	s.OS.HealthCheckRelease = version
+	s.OS.HealthCheckKnownFailures = getFailures(Run(ctx, s), nil)
+
+	if len(s.OS.HealthCheckKnownFailures) > 0 {
+		slog.WarnContext(ctx, "Health checks failing prior to the OS update", "checks", s.OS.HealthCheckKnownFailures)
+	}
+}
+
+// Monitor runs the health checks of a newly booted OS version until they pass, rolling back to the
+// previous version and rebooting should they still fail once the grace period expires. Returns
+// whether the running version is considered healthy.
+func Monitor(ctx context.Context, s *state.State) bool {
+	version := s.OS.HealthCheckRelease
+	if version == "" {
+		return true
+	}
+
+	cfg := s.System.Update.Config.HealthChecks
+
+	// Only check the version the checks were prepared for, which may not have booted.
+	if version != s.OS.RunningRelease || cfg == nil || !cfg.Enabled {
+		clearPending(s)
+
+		return true
+	}
+
+	gracePeriod := defaultGracePeriod
+	if cfg.GracePeriod != "" {
+		gracePeriod, _ = time.ParseDuration(cfg.GracePeriod)
+	}
+
+	s.System.Update.State.HealthCheck = &api.SystemUpdateHealthCheckState{Version: version, Status: "running"}
+	slog.InfoContext(ctx, "Running the health checks of the new OS version", "version", version, "grace_period", gracePeriod.String())
+
+	deadline := time.Now().Add(gracePeriod)
+
+	for {
+		results := Run(ctx, s)
+		failures := getFailures(results, s.OS.HealthCheckKnownFailures)
+
+		s.System.Update.State.HealthCheck.Results = results
+
+		if len(failures) == 0 {
+			s.System.Update.State.HealthCheck.Status = "passed"
+			slog.InfoContext(ctx, "Health checks of the new OS version passed", "version", version)
+
+			clearPending(s)
+
+			return true
+		}
+
+		if time.Now().After(deadline) {
+			slog.ErrorContext(ctx, "Health checks of the new OS version failed", "version", version, "checks", failures)
+
+			revert(ctx, s, failures)
+
+			return false
+		}
+
+		time.Sleep(checkInterval)
+	}
+}
+
+// Run runs the configured health checks.
+func Run(ctx context.Context, s *state.State) []api.SystemUpdateHealthCheckResult {
+	results := []api.SystemUpdateHealthCheckResult{}
+
+	cfg := s.System.Update.Config.HealthChecks
+	if cfg == nil {
+		return results
+	}
+
+	for _, unit := range cfg.Services {
+		result := api.SystemUpdateHealthCheckResult{Name: "service:" + unit, Passed: systemd.IsActive(ctx, unit)}
+		if !result.Passed {
+			result.Message = "unit isn't active"
+		}
+
+		results = append(results, result)
+	}
+
+	if cfg.CheckPools {
+		results = append(results, checkPools(ctx)...)
+	}
+
+	if cfg.CheckApplications {
+		for appName := range s.Applications {
+			result := api.SystemUpdateHealthCheckResult{Name: "application:" + appName}
+
+			app, err := applications.Load(ctx, s, appName)
+			if err != nil {
+				result.Message = err.Error()
+			} else {
+				result.Passed = app.IsRunning(ctx)
+				if !result.Passed {
+					result.Message = "application isn't running"
+				}
+			}
+
+			results = append(results, result)
+		}
+	}
+
+	for _, probe := range cfg.Probes {
+		result := api.SystemUpdateHealthCheckResult{Name: "probe:" + probe.Name, Passed: true}
+
+		err := runProbe(ctx, probe)
+		if err != nil {
+			result.Passed = false
+			result.Message = err.Error()
+		}
+
+		results = append(results, result)
+	}
+
+	slices.SortFunc(results, func(a api.SystemUpdateHealthCheckResult, b api.SystemUpdateHealthCheckResult) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return results
+}
+
+// checkPools checks that all the storage pools are online.
+func checkPools(ctx context.Context) []api.SystemUpdateHealthCheckResult {
+	info, err := storage.GetStorageInfo(ctx)
+	if err != nil {
+		return []api.SystemUpdateHealthCheckResult{{Name: "pools", Message: err.Error()}}
+	}
+
+	results := []api.SystemUpdateHealthCheckResult{}
+
+	for _, pool := range info.State.Pools {
+		result := api.SystemUpdateHealthCheckResult{Name: "pool:" + pool.Name, Passed: pool.State == "ONLINE"}
+		if !result.Passed {
+			result.Message = "pool is " + pool.State
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// runProbe runs a custom health probe.
+func runProbe(ctx context.Context, probe api.SystemUpdateHealthProbe) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	switch probe.Type {
+	case "http":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, probe.Target, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+
+		_ = resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("unexpected status %q", resp.Status)
+		}
+
+		return nil
+	case "tcp":
+		dialer := &net.Dialer{}
+
+		conn, err := dialer.DialContext(ctx, "tcp", probe.Target)
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+	default:
+		return errors.New("unsupported probe type")
+	}
+}
+
+// getFailures returns the name of the failed checks, ignoring the known failures.
+func getFailures(results []api.SystemUpdateHealthCheckResult, knownFailures []string) []string {
+	failures := []string{}
+
+	for _, result := range results {
+		if !result.Passed && !slices.Contains(knownFailures, result.Name) {
+			failures = append(failures, result.Name)
+		}
+	}
+
+	return failures
+}
+
+// revert rolls back to the previous OS version and reboots.
+func revert(ctx context.Context, s *state.State, failures []string) {
+	s.UpdateMutex.Lock()
+	defer s.UpdateMutex.Unlock()
+
+	clearPending(s)
+
+	version, err := systemd.RollbackOS(ctx, s, "health checks failed: "+strings.Join(failures, ", "))
+	if err != nil {
+		s.System.Update.State.HealthCheck.Status = "failed"
+		slog.ErrorContext(ctx, "Failed to roll back to the previous OS version", "err", err.Error())
+
+		return
+	}
+
+	s.System.Update.State.HealthCheck.Status = "reverted"
+	slog.WarnContext(ctx, "Rolling back "+s.OS.Name+" to version "+version+" following failed health checks")
+
+	select {
+	case s.TriggerReboot <- nil:
+	default:
+	}
+}
+
+// clearPending clears the recorded health checks once complete.
+func clearPending(s *state.State) {
+	s.OS.HealthCheckRelease = ""
+	s.OS.HealthCheckKnownFailures = nil
+	_ = s.Save()
+}
@@ -0,0 +1,80 @@
+package health
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+func TestValidateConfig(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidateConfig(nil))
+	require.NoError(t, ValidateConfig(&api.SystemUpdateHealthChecks{GracePeriod: "5m"}))
+	require.Error(t, ValidateConfig(&api.SystemUpdateHealthChecks{GracePeriod: "soon"}))
+
+	require.NoError(t, ValidateConfig(&api.SystemUpdateHealthChecks{Probes: []api.SystemUpdateHealthProbe{
+		{Name: "web", Type: "http", Target: "https://localhost:8443/"},
+		{Name: "ssh", Type: "tcp", Target: "localhost:22"},
+	}}))
+	require.Error(t, ValidateConfig(&api.SystemUpdateHealthChecks{Probes: []api.SystemUpdateHealthProbe{
+		{Name: "web", Type: "http", Target: "https://localhost:8443/"},
+		{Name: "web", Type: "tcp", Target: "localhost:22"},
+	}}))
+	require.Error(t, ValidateConfig(&api.SystemUpdateHealthChecks{Probes: []api.SystemUpdateHealthProbe{{Type: "tcp", Target: "localhost:22"}}}))
+	require.Error(t, ValidateConfig(&api.SystemUpdateHealthChecks{Probes: []api.SystemUpdateHealthProbe{{Name: "web", Type: "http", Target: "localhost"}}}))
+	require.Error(t, ValidateConfig(&api.SystemUpdateHealthChecks{Probes: []api.SystemUpdateHealthProbe{{Name: "ssh", Type: "tcp", Target: "localhost"}}}))
+	require.Error(t, ValidateConfig(&api.SystemUpdateHealthChecks{Probes: []api.SystemUpdateHealthProbe{{Name: "ping", Type: "icmp", Target: "localhost"}}}))
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ok.Close()
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer broken.Close()
+
+	// Get an address nothing is listening on.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	closed := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	s := &state.State{}
+	s.System.Update.Config.HealthChecks = &api.SystemUpdateHealthChecks{
+		Enabled: true,
+		Probes: []api.SystemUpdateHealthProbe{
+			{Name: "ok", Type: "http", Target: ok.URL},
+			{Name: "broken", Type: "http", Target: broken.URL},
+			{Name: "listening", Type: "tcp", Target: ok.Listener.Addr().String()},
+			{Name: "closed", Type: "tcp", Target: closed},
+		},
+	}
+
+	results := Run(t.Context(), s)
+	require.Len(t, results, 4)
+	require.Equal(t, "probe:broken", results[0].Name)
+	require.False(t, results[0].Passed)
+	require.Equal(t, "probe:closed", results[1].Name)
+	require.False(t, results[1].Passed)
+	require.Equal(t, "probe:listening", results[2].Name)
+	require.True(t, results[2].Passed)
+	require.Equal(t, "probe:ok", results[3].Name)
+	require.True(t, results[3].Passed)
+
+	require.Equal(t, []string{"probe:broken", "probe:closed"}, getFailures(results, nil))
+	require.Equal(t, []string{"probe:closed"}, getFailures(results, []string{"probe:broken"}))
+}
@@ -0,0 +1,231 @@
+package kernel
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"debug/pe"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+
+	"github.com/lxc/incus-os/incus-osd/internal/systemd"
+)
+
+// CmdlinePath is the kernel command line of the running system.
+var CmdlinePath = "/proc/cmdline"
+
+// CmdlineAddonPath is the global systemd-stub addon holding the additional kernel command line parameters.
+var CmdlineAddonPath = "/boot/loader/addons/incus-os-cmdline.addon.efi"
+
+// CmdlineSigningKeyPath is the private key signing the kernel command line addon.
+var CmdlineSigningKeyPath = "/var/lib/incus-os/cmdline-signing.key"
+
+// CmdlineSigningCertificatePath is the certificate signing the kernel command line addon, which must be
+// enrolled into the Secure Boot db for the boot loader to load the addon.
+var CmdlineSigningCertificatePath = "/var/lib/incus-os/cmdline-signing.crt"
+
+// deniedCmdlinePrefixes are the kernel command line parameters which could be used to bypass the
+// integrity of the system or are already managed by IncusOS.
+var deniedCmdlinePrefixes = []string{"init=", "rdinit=", "root=", "rootflags=", "mount.usr", "usrhash=", "roothash=", "systemd.", "rd.", "lockdown=", "module.sig_enforce", "efi=", "console="}
+
+// ValidateCmdline checks that the additional kernel command line parameters can be safely applied.
+func ValidateCmdline(params []string) error {
+	for _, param := range params {
+		if param == "" || strings.ContainsAny(param, " \t\n\"'\\") {
+			return fmt.Errorf("invalid kernel command line parameter %q", param)
+		}
+
+		for _, prefix := range deniedCmdlinePrefixes {
+			if strings.HasPrefix(param, prefix) {
+				return fmt.Errorf("kernel command line parameter %q can't be changed", param)
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetFullCmdline returns the full kernel command line of the running system.
+func GetFullCmdline() ([]string, error) {
+	content, err := os.ReadFile(CmdlinePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Fields(string(content)), nil
+}
+
+// GetCmdline returns the additional kernel command line parameters in use, being those not part of
+// the command line embedded in the running OS image.
+func GetCmdline(osName string, osVersion string) ([]string, error) {
+	cmdline, err := GetFullCmdline()
+	if err != nil {
+		return nil, err
+	}
+
+	// Boot images still being assessed carry a boot counter in their name.
+	ukis, err := filepath.Glob(filepath.Join(systemd.BootImagesPath, osName+"_"+osVersion+"*.efi"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ukis) == 0 {
+		return nil, fmt.Errorf("no boot image found for %s %s", osName, osVersion)
+	}
+
+	embedded, err := getUKICmdline(ukis[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return getExtraCmdline(cmdline, embedded), nil
+}
+
+// ApplyCmdline writes the additional kernel command line parameters to a boot loader addon, taking
+// effect on the next boot.
+func ApplyCmdline(ctx context.Context, params []string) error {
+	// WORKAROUND: Start the boot.mount unit so /boot autofs is active.
+	err := systemd.StartUnit(ctx, "boot.mount")
+	if err != nil {
+		return err
+	}
+
+	if len(params) == 0 {
+		err := os.Remove(CmdlineAddonPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		return nil
+	}
+
+	err = os.MkdirAll(filepath.Dir(CmdlineAddonPath), 0o755)
+	if err != nil {
+		return err
+	}
+
+	// Make sure the signing key exists.
+	_, err = GetCmdlineSigningCertificate()
+	if err != nil {
+		return err
+	}
+
+	// Build the addon next to its final location, so a partially written one is never used.
+	_, err = subprocess.RunCommandContext(ctx, "ukify", "build", "--cmdline="+strings.Join(params, " "), "--signtool=sbsign", "--secureboot-private-key="+CmdlineSigningKeyPath, "--secureboot-certificate="+CmdlineSigningCertificatePath, "--output="+CmdlineAddonPath+".new")
+	if err != nil {
+		_ = os.Remove(CmdlineAddonPath + ".new")
+
+		return err
+	}
+
+	return os.Rename(CmdlineAddonPath+".new", CmdlineAddonPath)
+}
+
+// GetCmdlineSigningCertificate returns the certificate signing the kernel command line addon, generating
+// a new signing key on first use.
+func GetCmdlineSigningCertificate() (*x509.Certificate, error) {
+	content, err := os.ReadFile(CmdlineSigningCertificatePath)
+	if err == nil {
+		block, _ := pem.Decode(content)
+		if block == nil {
+			return nil, errors.New("invalid kernel command line signing certificate")
+		}
+
+		return x509.ParseCertificate(block.Bytes)
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	// Secure Boot firmware is only guaranteed to support RSA 2048 keys.
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "IncusOS kernel command line (" + hostname + ")"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(10, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Write the key first, so a certificate is never left without its key.
+	err = os.WriteFile(CmdlineSigningKeyPath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.WriteFile(CmdlineSigningCertificatePath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}), 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return x509.ParseCertificate(certDER)
+}
+
+// getUKICmdline returns the kernel command line embedded in a UKI image.
+func getUKICmdline(ukiFile string) ([]string, error) {
+	peFile, err := pe.Open(ukiFile)
+	if err != nil {
+		return nil, err
+	}
+	defer peFile.Close()
+
+	cmdlineSection := peFile.Section(".cmdline")
+	if cmdlineSection == nil {
+		return nil, fmt.Errorf("failed to read .cmdline section from '%s'", ukiFile)
+	}
+
+	cmdlineData, err := cmdlineSection.Data()
+	if err != nil {
+		return nil, err
+	}
+
+	// Trim null bytes from returned buffer.
+	return strings.Fields(strings.TrimRight(string(cmdlineData), "\x00")), nil
+}
+
+// getExtraCmdline returns the parameters of the kernel command line which aren't embedded in the image.
+func getExtraCmdline(cmdline []string, embedded []string) []string {
+	ret := []string{}
+
+	for _, param := range cmdline {
+		if slices.Contains(embedded, param) {
+			continue
+		}
+
+		ret = append(ret, param)
+	}
+
+	return ret
+}
@@ -0,0 +1,3 @@
+// Package kernel provides logic to enforce the kernel lockdown and module loading policy, and to
+// manage the additional kernel command line parameters.
+package kernel
@@ -0,0 +1,159 @@
+package kernel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// LockdownPath is the securityfs file controlling the kernel lockdown mode.
+var LockdownPath = "/sys/kernel/security/lockdown"
+
+// ModulesDisabledPath is the sysctl preventing any further module loading once set.
+var ModulesDisabledPath = "/proc/sys/kernel/modules_disabled"
+
+// ModprobeConfigPath is the modprobe configuration file holding the denied modules.
+var ModprobeConfigPath = "/run/modprobe.d/incus-os-security.conf"
+
+// lockdownModes are the kernel lockdown modes, from the least to the most restrictive.
+var lockdownModes = []string{"none", "integrity", "confidentiality"}
+
+var moduleRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ValidateConfig checks that the kernel lockdown and module loading policy is usable.
+func ValidateConfig(cfg *api.SystemSecurityKernel) error {
+	if cfg == nil {
+		return nil
+	}
+
+	if cfg.Lockdown != "" && cfg.Lockdown != "integrity" && cfg.Lockdown != "confidentiality" {
+		return fmt.Errorf("invalid kernel lockdown mode %q", cfg.Lockdown)
+	}
+
+	if cfg.ModulePolicy != "" && cfg.ModulePolicy != "allow" && cfg.ModulePolicy != "restrict" {
+		return fmt.Errorf("invalid kernel module policy %q", cfg.ModulePolicy)
+	}
+
+	for _, module := range slices.Concat(cfg.AllowedModules, cfg.DeniedModules) {
+		if !moduleRegex.MatchString(module) {
+			return fmt.Errorf("invalid kernel module name %q", module)
+		}
+	}
+
+	for _, module := range cfg.AllowedModules {
+		if slices.Contains(cfg.DeniedModules, module) {
+			return fmt.Errorf("kernel module %q can't be both allowed and denied", module)
+		}
+	}
+
+	return nil
+}
+
+// GetLockdown returns the current kernel lockdown mode, or "unsupported" if not available.
+func GetLockdown() string {
+	content, err := os.ReadFile(LockdownPath)
+	if err != nil {
+		return "unsupported"
+	}
+
+	// The active mode is shown between brackets, for example "none [integrity] confidentiality".
+	for mode := range strings.FieldsSeq(string(content)) {
+		if strings.HasPrefix(mode, "[") {
+			return strings.Trim(mode, "[]")
+		}
+	}
+
+	return "unsupported"
+}
+
+// ModulesDisabled returns whether module loading was disabled until the next reboot.
+func ModulesDisabled() bool {
+	content, err := os.ReadFile(ModulesDisabledPath)
+	if err != nil {
+		return false
+	}
+
+	return strings.TrimSpace(string(content)) == "1"
+}
+
+// ApplyModuleDenials prevents the denied modules from being loaded. Modules which are already loaded
+// remain so until the next reboot.
+func ApplyModuleDenials(cfg *api.SystemSecurityKernel) error {
+	if cfg == nil || len(cfg.DeniedModules) == 0 {
+		err := os.Remove(ModprobeConfigPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		return nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(ModprobeConfigPath), 0o755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(ModprobeConfigPath, []byte(renderModprobeConfig(cfg.DeniedModules)), 0o644)
+}
+
+// ApplyRestrictions loads the allowed modules before disabling module loading if the policy is
+// restricted, then raises the kernel lockdown mode. Neither can be reverted until the next reboot,
+// so this should only be called once the system has fully started.
+func ApplyRestrictions(ctx context.Context, cfg *api.SystemSecurityKernel) error {
+	if cfg == nil {
+		return nil
+	}
+
+	if cfg.ModulePolicy == "restrict" && !ModulesDisabled() {
+		for _, module := range cfg.AllowedModules {
+			_, err := subprocess.RunCommandContext(ctx, "modprobe", module)
+			if err != nil {
+				return fmt.Errorf("failed to load kernel module %q: %w", module, err)
+			}
+		}
+
+		err := os.WriteFile(ModulesDisabledPath, []byte("1\n"), 0o644)
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.Lockdown == "" {
+		return nil
+	}
+
+	current := GetLockdown()
+	if current == "unsupported" {
+		return errors.New("kernel lockdown isn't supported by the running kernel")
+	}
+
+	// The kernel refuses lowering the lockdown mode.
+	if slices.Index(lockdownModes, current) >= slices.Index(lockdownModes, cfg.Lockdown) {
+		return nil
+	}
+
+	return os.WriteFile(LockdownPath, []byte(cfg.Lockdown), 0o644)
+}
+
+// renderModprobeConfig returns the modprobe configuration preventing the denied modules from being
+// loaded, whether directly or as a dependency.
+func renderModprobeConfig(modules []string) string {
+	var sb strings.Builder
+
+	sb.WriteString("# Generated by IncusOS, do not edit.\n")
+
+	for _, module := range modules {
+		fmt.Fprintf(&sb, "blacklist %s\ninstall %s /bin/false\n", module, module)
+	}
+
+	return sb.String()
+}
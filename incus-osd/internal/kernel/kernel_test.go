@@ -0,0 +1,71 @@
+package kernel
+
+import (
+	"crypto/x509"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+func TestValidateConfig(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidateConfig(nil))
+	require.NoError(t, ValidateConfig(&api.SystemSecurityKernel{Lockdown: "integrity", ModulePolicy: "restrict", AllowedModules: []string{"vhost_vsock", "nbd"}, DeniedModules: []string{"firewire-core"}}))
+	require.Error(t, ValidateConfig(&api.SystemSecurityKernel{Lockdown: "none"}))
+	require.Error(t, ValidateConfig(&api.SystemSecurityKernel{ModulePolicy: "deny"}))
+	require.Error(t, ValidateConfig(&api.SystemSecurityKernel{DeniedModules: []string{"../usb-storage"}}))
+	require.Error(t, ValidateConfig(&api.SystemSecurityKernel{AllowedModules: []string{"nbd"}, DeniedModules: []string{"nbd"}}))
+}
+
+func TestRenderModprobeConfig(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, `# Generated by IncusOS, do not edit.
+blacklist usb-storage
+install usb-storage /bin/false
+blacklist thunderbolt
+install thunderbolt /bin/false
+`, renderModprobeConfig([]string{"usb-storage", "thunderbolt"}))
+}
+
+func TestValidateCmdline(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidateCmdline(nil))
+	require.NoError(t, ValidateCmdline([]string{"hugepagesz=1G", "hugepages=16", "isolcpus=2-7", "nohz_full=2-7"}))
+	require.Error(t, ValidateCmdline([]string{""}))
+	require.Error(t, ValidateCmdline([]string{"hugepages=16 init=/bin/sh"}))
+	require.Error(t, ValidateCmdline([]string{"init=/bin/sh"}))
+	require.Error(t, ValidateCmdline([]string{"systemd.unit=emergency.target"}))
+	require.Error(t, ValidateCmdline([]string{"module.sig_enforce=0"}))
+}
+
+func TestGetExtraCmdline(t *testing.T) {
+	t.Parallel()
+
+	embedded := []string{"rw", "quiet", "iommu=pt"}
+
+	require.Equal(t, []string{}, getExtraCmdline([]string{"rw", "quiet", "iommu=pt"}, embedded))
+	require.Equal(t, []string{"hugepages=16", "isolcpus=2-7"}, getExtraCmdline([]string{"rw", "quiet", "iommu=pt", "hugepages=16", "isolcpus=2-7"}, embedded))
+}
+
+func TestGetCmdlineSigningCertificate(t *testing.T) { //nolint:paralleltest
+	dir := t.TempDir()
+
+	CmdlineSigningKeyPath = filepath.Join(dir, "cmdline-signing.key")
+	CmdlineSigningCertificatePath = filepath.Join(dir, "cmdline-signing.crt")
+
+	cert, err := GetCmdlineSigningCertificate()
+	require.NoError(t, err)
+	require.Equal(t, []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}, cert.ExtKeyUsage)
+	require.FileExists(t, CmdlineSigningKeyPath)
+
+	// The same key is used from then on.
+	again, err := GetCmdlineSigningCertificate()
+	require.NoError(t, err)
+	require.True(t, cert.Equal(again))
+}
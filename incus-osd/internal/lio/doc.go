@@ -0,0 +1,3 @@
+// Package lio provides logic to configure the kernel's LIO target through configfs, exporting
+// local ZFS volumes as iSCSI targets.
+package lio
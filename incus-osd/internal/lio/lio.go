@@ -0,0 +1,382 @@
+package lio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// ConfigPath is the configfs location of the LIO target configuration.
+var ConfigPath = "/sys/kernel/config/target"
+
+// ZvolPath is the location of the ZFS volume block devices.
+var ZvolPath = "/dev/zvol"
+
+// backstorePrefix identifies the block backstores created by IncusOS.
+const backstorePrefix = "incusos_"
+
+// linkName is the name of the links between LIO objects.
+const linkName = "incusos"
+
+var nameRegex = regexp.MustCompile(`^(iqn\.[0-9]{4}-[0-9]{2}\.[a-z0-9.-]+(:[^\s/]+)?|eui\.[0-9a-fA-F]{16}|naa\.[0-9a-fA-F]{16,32})$`)
+
+// Validate checks that the exported targets are consistent.
+func Validate(exports []api.ServiceISCSIExport) error {
+	targets := map[string]bool{}
+	volumes := map[string]bool{}
+
+	for _, export := range exports {
+		if !nameRegex.MatchString(export.Target) {
+			return fmt.Errorf("invalid iSCSI target name %q", export.Target)
+		}
+
+		if targets[export.Target] {
+			return fmt.Errorf("duplicate iSCSI target %q", export.Target)
+		}
+
+		targets[export.Target] = true
+
+		if export.Port < 0 || export.Port > 65535 {
+			return fmt.Errorf("invalid port %d for iSCSI target %q", export.Port, export.Target)
+		}
+
+		luns := []int{}
+
+		for _, lun := range export.LUNs {
+			if lun.LUN < 0 || lun.LUN > 255 || slices.Contains(luns, lun.LUN) {
+				return fmt.Errorf("invalid or duplicate LUN %d for iSCSI target %q", lun.LUN, export.Target)
+			}
+
+			luns = append(luns, lun.LUN)
+
+			if lun.Pool == "" || lun.Volume == "" {
+				return fmt.Errorf("missing pool or volume for LUN %d of iSCSI target %q", lun.LUN, export.Target)
+			}
+
+			volume := lun.Pool + "/" + lun.Volume
+			if volumes[volume] {
+				return fmt.Errorf("volume %q is exported more than once", volume)
+			}
+
+			volumes[volume] = true
+		}
+
+		initiators := []string{}
+		withAuthentication := 0
+
+		for _, acl := range export.ACLs {
+			if !nameRegex.MatchString(acl.Initiator) || slices.Contains(initiators, acl.Initiator) {
+				return fmt.Errorf("invalid or duplicate initiator %q for iSCSI target %q", acl.Initiator, export.Target)
+			}
+
+			initiators = append(initiators, acl.Initiator)
+
+			for _, lun := range acl.LUNs {
+				if !slices.Contains(luns, lun) {
+					return fmt.Errorf("initiator %q is mapped to unknown LUN %d of iSCSI target %q", acl.Initiator, lun, export.Target)
+				}
+			}
+
+			if acl.Authentication != nil {
+				withAuthentication++
+			}
+		}
+
+		// Authentication is enabled for the whole target.
+		if withAuthentication > 0 && withAuthentication != len(export.ACLs) {
+			return fmt.Errorf("either all or none of the initiators of iSCSI target %q must use authentication", export.Target)
+		}
+	}
+
+	return nil
+}
+
+// Apply configures the LIO targets exporting the provided volumes, replacing any existing target.
+// The CHAP passwords of the initiators must already be resolved.
+func Apply(ctx context.Context, exports []api.ServiceISCSIExport) error {
+	err := Clear()
+	if err != nil {
+		return err
+	}
+
+	if len(exports) == 0 {
+		return nil
+	}
+
+	// Ensure we have the right modules.
+	for _, module := range []string{"target_core_mod", "target_core_iblock", "iscsi_target_mod"} {
+		_, err := subprocess.RunCommandContext(ctx, "modprobe", module)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, export := range exports {
+		err := applyExport(export)
+		if err != nil {
+			return fmt.Errorf("failed to configure iSCSI target %q: %w", export.Target, err)
+		}
+	}
+
+	return nil
+}
+
+// applyExport configures a single target, with its LUNs, ACLs and portal.
+func applyExport(export api.ServiceISCSIExport) error {
+	tpgPath := filepath.Join(ConfigPath, "iscsi", export.Target, "tpgt_1")
+
+	err := os.MkdirAll(tpgPath, 0o755)
+	if err != nil {
+		return err
+	}
+
+	// Create a block backstore for each volume and map it to its LUN.
+	for _, lun := range export.LUNs {
+		backstorePath := filepath.Join(ConfigPath, "core", "iblock_0", getBackstoreName(lun))
+
+		err := os.MkdirAll(backstorePath, 0o755)
+		if err != nil {
+			return err
+		}
+
+		err = writeAttr(filepath.Join(backstorePath, "control"), "udev_path="+filepath.Join(ZvolPath, lun.Pool, lun.Volume))
+		if err != nil {
+			return err
+		}
+
+		err = writeAttr(filepath.Join(backstorePath, "enable"), "1")
+		if err != nil {
+			return err
+		}
+
+		lunPath := filepath.Join(tpgPath, "lun", "lun_"+strconv.Itoa(lun.LUN))
+
+		err = os.MkdirAll(lunPath, 0o755)
+		if err != nil {
+			return err
+		}
+
+		err = os.Symlink(backstorePath, filepath.Join(lunPath, linkName))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Only allow the listed initiators, each to its mapped LUNs.
+	authentication := "0"
+
+	for _, acl := range export.ACLs {
+		aclPath := filepath.Join(tpgPath, "acls", acl.Initiator)
+
+		err := os.MkdirAll(aclPath, 0o755)
+		if err != nil {
+			return err
+		}
+
+		for _, lun := range export.LUNs {
+			if len(acl.LUNs) > 0 && !slices.Contains(acl.LUNs, lun.LUN) {
+				continue
+			}
+
+			mappedPath := filepath.Join(aclPath, "lun_"+strconv.Itoa(lun.LUN))
+
+			err := os.MkdirAll(mappedPath, 0o755)
+			if err != nil {
+				return err
+			}
+
+			err = os.Symlink(filepath.Join(tpgPath, "lun", "lun_"+strconv.Itoa(lun.LUN)), filepath.Join(mappedPath, linkName))
+			if err != nil {
+				return err
+			}
+		}
+
+		if acl.Authentication == nil {
+			continue
+		}
+
+		authentication = "1"
+
+		auth := map[string]string{
+			"userid":          acl.Authentication.Username,
+			"password":        acl.Authentication.Password,
+			"userid_mutual":   acl.Authentication.TargetUsername,
+			"password_mutual": acl.Authentication.TargetPassword,
+		}
+
+		for key, value := range auth {
+			if value == "" {
+				continue
+			}
+
+			err := writeAttr(filepath.Join(aclPath, "auth", key), value)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	attributes := map[string]string{
+		"authentication":     authentication,
+		"generate_node_acls": "0",
+	}
+
+	for key, value := range attributes {
+		err := writeAttr(filepath.Join(tpgPath, "attrib", key), value)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Listen on all addresses.
+	port := export.Port
+	if port == 0 {
+		port = 3260
+	}
+
+	err = os.MkdirAll(filepath.Join(tpgPath, "np", net.JoinHostPort("::", strconv.Itoa(port))), 0o755)
+	if err != nil {
+		return err
+	}
+
+	return writeAttr(filepath.Join(tpgPath, "enable"), "1")
+}
+
+// Clear removes all the iSCSI targets along with the backstores created by IncusOS. As configfs
+// objects can only be removed once unused, links are removed first, then the objects in reverse order.
+func Clear() error {
+	targets, err := os.ReadDir(filepath.Join(ConfigPath, "iscsi"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	for _, target := range targets {
+		if !target.IsDir() || !nameRegex.MatchString(target.Name()) {
+			continue
+		}
+
+		targetPath := filepath.Join(ConfigPath, "iscsi", target.Name())
+
+		tpgs, err := filepath.Glob(filepath.Join(targetPath, "tpgt_*"))
+		if err != nil {
+			return err
+		}
+
+		for _, tpgPath := range tpgs {
+			// Mapped LUNs, then ACLs.
+			acls, _ := filepath.Glob(filepath.Join(tpgPath, "acls", "*"))
+			for _, aclPath := range acls {
+				err := removeDirs(filepath.Join(aclPath, "lun_*"))
+				if err != nil {
+					return err
+				}
+
+				err = os.Remove(aclPath)
+				if err != nil {
+					return err
+				}
+			}
+
+			// Portals and LUNs.
+			err := removeDirs(filepath.Join(tpgPath, "np", "*"))
+			if err != nil {
+				return err
+			}
+
+			err = removeDirs(filepath.Join(tpgPath, "lun", "lun_*"))
+			if err != nil {
+				return err
+			}
+
+			err = os.Remove(tpgPath)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = os.Remove(targetPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	return removeDirs(filepath.Join(ConfigPath, "core", "iblock_*", backstorePrefix+"*"))
+}
+
+// GetSessions returns the initiators with an active session to each of the exported targets.
+func GetSessions(exports []api.ServiceISCSIExport) []api.ServiceISCSIExportState {
+	ret := []api.ServiceISCSIExportState{}
+
+	for _, export := range exports {
+		state := api.ServiceISCSIExportState{
+			Target:     export.Target,
+			Initiators: []string{},
+		}
+
+		for _, acl := range export.ACLs {
+			// The session information starts with "InitiatorName:" when a session is active.
+			info, err := os.ReadFile(filepath.Join(ConfigPath, "iscsi", export.Target, "tpgt_1", "acls", acl.Initiator, "info")) //nolint:gosec
+			if err == nil && strings.Contains(string(info), "InitiatorName:") {
+				state.Initiators = append(state.Initiators, acl.Initiator)
+			}
+		}
+
+		ret = append(ret, state)
+	}
+
+	return ret
+}
+
+// getBackstoreName returns the name of the block backstore of a volume.
+func getBackstoreName(lun api.ServiceISCSIExportLUN) string {
+	return backstorePrefix + strings.ReplaceAll(lun.Pool+"/"+lun.Volume, "/", "_")
+}
+
+// removeDirs removes the links found in the directories matching the pattern, then the directories.
+func removeDirs(pattern string) error {
+	dirs, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if entry.Type()&os.ModeSymlink == 0 {
+				continue
+			}
+
+			err := os.Remove(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				return err
+			}
+		}
+
+		err = os.Remove(dir)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeAttr sets the value of a configfs attribute.
+func writeAttr(path string, value string) error {
+	return os.WriteFile(path, []byte(value), 0o600)
+}
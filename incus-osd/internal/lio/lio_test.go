@@ -0,0 +1,57 @@
+package lio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	auth := &api.ServiceISCSIAuthentication{Username: "initiator", Password: "password1234"}
+
+	valid := api.ServiceISCSIExport{
+		Target: "iqn.2004-10.org.linuxcontainers:storage",
+		LUNs: []api.ServiceISCSIExportLUN{
+			{LUN: 0, Pool: "local", Volume: "iscsi/lun0"},
+			{LUN: 1, Pool: "local", Volume: "iscsi/lun1", SizeInBytes: 1073741824},
+		},
+		ACLs: []api.ServiceISCSIExportACL{
+			{Initiator: "iqn.1993-08.org.debian:01:abcdef", LUNs: []int{1}, Authentication: auth},
+			{Initiator: "iqn.2004-10.org.linuxcontainers:01:server02", Authentication: auth},
+		},
+	}
+
+	require.NoError(t, Validate(nil))
+	require.NoError(t, Validate([]api.ServiceISCSIExport{valid}))
+
+	tests := []struct {
+		name   string
+		modify func(export *api.ServiceISCSIExport)
+		err    string
+	}{
+		{"invalid target", func(e *api.ServiceISCSIExport) { e.Target = "storage" }, "invalid iSCSI target name"},
+		{"invalid port", func(e *api.ServiceISCSIExport) { e.Port = 70000 }, "invalid port"},
+		{"duplicate LUN", func(e *api.ServiceISCSIExport) { e.LUNs[1].LUN = 0 }, "invalid or duplicate LUN"},
+		{"missing volume", func(e *api.ServiceISCSIExport) { e.LUNs[0].Volume = "" }, "missing pool or volume"},
+		{"duplicate volume", func(e *api.ServiceISCSIExport) { e.LUNs[1].Volume = "iscsi/lun0" }, "exported more than once"},
+		{"invalid initiator", func(e *api.ServiceISCSIExport) { e.ACLs[0].Initiator = "server01" }, "invalid or duplicate initiator"},
+		{"unknown LUN", func(e *api.ServiceISCSIExport) { e.ACLs[0].LUNs = []int{2} }, "unknown LUN"},
+		{"mixed authentication", func(e *api.ServiceISCSIExport) { e.ACLs[1].Authentication = nil }, "either all or none"},
+	}
+
+	for _, tc := range tests {
+		export := valid
+		export.LUNs = append([]api.ServiceISCSIExportLUN{}, valid.LUNs...)
+		export.ACLs = append([]api.ServiceISCSIExportACL{}, valid.ACLs...)
+		tc.modify(&export)
+
+		require.ErrorContains(t, Validate([]api.ServiceISCSIExport{export}), tc.err, tc.name)
+	}
+
+	// Targets must be unique.
+	require.ErrorContains(t, Validate([]api.ServiceISCSIExport{valid, {Target: valid.Target}}), "duplicate iSCSI target")
+}
@@ -0,0 +1,2 @@
+// Package listeners provides logic to list the sockets accepting network traffic on the host.
+package listeners
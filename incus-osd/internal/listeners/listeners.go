@@ -0,0 +1,174 @@
+package listeners
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// ProcPath is the path of the proc file system.
+var ProcPath = "/proc"
+
+// Socket states, as reported by the kernel.
+const (
+	tcpListen = "0A"
+	udpClosed = "07"
+)
+
+// List returns the TCP sockets listening for connections and the bound UDP sockets of the host,
+// along with the name of the process owning them.
+func List() ([]api.SystemSecurityPostureListener, error) {
+	ret := []api.SystemSecurityPostureListener{}
+
+	processes := getSocketProcesses()
+
+	for _, protocol := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		content, err := os.ReadFile(filepath.Join(ProcPath, "net", protocol))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		listeners, err := parseProcNet(string(content), protocol)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, listener := range listeners {
+			listener.Process = processes[listener.inode]
+			ret = append(ret, listener.SystemSecurityPostureListener)
+		}
+	}
+
+	sortListeners(ret)
+
+	return ret, nil
+}
+
+// listener is a listening socket along with its inode.
+type listener struct {
+	api.SystemSecurityPostureListener
+
+	inode string
+}
+
+// parseProcNet parses the content of /proc/net/{tcp,tcp6,udp,udp6}, returning the listening sockets.
+func parseProcNet(content string, protocol string) ([]listener, error) {
+	ret := []listener{}
+
+	for i, line := range strings.Split(strings.TrimSpace(content), "\n") {
+		// Skip the header.
+		if i == 0 {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 10 {
+			return nil, fmt.Errorf("invalid %s socket entry %q", protocol, line)
+		}
+
+		state := fields[3]
+		if (strings.HasPrefix(protocol, "tcp") && state != tcpListen) || (strings.HasPrefix(protocol, "udp") && state != udpClosed) {
+			continue
+		}
+
+		address, port, err := parseAddress(fields[1])
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, listener{
+			SystemSecurityPostureListener: api.SystemSecurityPostureListener{
+				Protocol: strings.TrimSuffix(protocol, "6"),
+				Address:  address,
+				Port:     port,
+			},
+			inode: fields[9],
+		})
+	}
+
+	return ret, nil
+}
+
+// parseAddress parses a hexadecimal "address:port" socket address, with the address being stored as
+// 32-bit words in host (little-endian) byte order.
+func parseAddress(value string) (string, int, error) {
+	rawAddress, rawPort, ok := strings.Cut(value, ":")
+	if !ok {
+		return "", 0, fmt.Errorf("invalid socket address %q", value)
+	}
+
+	words, err := hex.DecodeString(rawAddress)
+	if err != nil || (len(words) != net.IPv4len && len(words) != net.IPv6len) {
+		return "", 0, fmt.Errorf("invalid socket address %q", value)
+	}
+
+	ip := make(net.IP, len(words))
+	for i := 0; i < len(words); i += 4 {
+		binary.BigEndian.PutUint32(ip[i:], binary.LittleEndian.Uint32(words[i:]))
+	}
+
+	port, err := strconv.ParseUint(rawPort, 16, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid socket port %q", value)
+	}
+
+	return ip.String(), int(port), nil
+}
+
+// getSocketProcesses returns the name of the process owning each socket, keyed by socket inode.
+func getSocketProcesses() map[string]string {
+	ret := map[string]string{}
+
+	fds, _ := filepath.Glob(filepath.Join(ProcPath, "[0-9]*", "fd", "*"))
+
+	for _, fd := range fds {
+		target, err := os.Readlink(fd)
+		if err != nil || !strings.HasPrefix(target, "socket:[") {
+			continue
+		}
+
+		inode := strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]")
+
+		_, ok := ret[inode]
+		if ok {
+			continue
+		}
+
+		comm, err := os.ReadFile(filepath.Join(filepath.Dir(filepath.Dir(fd)), "comm"))
+		if err != nil {
+			continue
+		}
+
+		ret[inode] = strings.TrimSpace(string(comm))
+	}
+
+	return ret
+}
+
+// sortListeners orders the listeners by protocol, port and address.
+func sortListeners(listeners []api.SystemSecurityPostureListener) {
+	slices.SortFunc(listeners, func(a api.SystemSecurityPostureListener, b api.SystemSecurityPostureListener) int {
+		if a.Protocol != b.Protocol {
+			return strings.Compare(a.Protocol, b.Protocol)
+		}
+
+		if a.Port != b.Port {
+			return a.Port - b.Port
+		}
+
+		return strings.Compare(a.Address, b.Address)
+	})
+}
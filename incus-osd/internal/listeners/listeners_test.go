@@ -0,0 +1,59 @@
+package listeners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+func TestParseProcNet(t *testing.T) {
+	t.Parallel()
+
+	tcp := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:01BB 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 21451 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 21452 1 0000000000000000 100 0 0 10 0
+   2: 0A00020F:01BB 0A000201:D431 01 00000000:00000000 02:000A6AB4 00000000     0        0 31337 2 0000000000000000 20 4 30 10 -1
+`
+
+	listeners, err := parseProcNet(tcp, "tcp")
+	require.NoError(t, err)
+	require.Equal(t, []listener{
+		{SystemSecurityPostureListener: api.SystemSecurityPostureListener{Protocol: "tcp", Address: "0.0.0.0", Port: 443}, inode: "21451"},
+		{SystemSecurityPostureListener: api.SystemSecurityPostureListener{Protocol: "tcp", Address: "127.0.0.1", Port: 22}, inode: "21452"},
+	}, listeners)
+
+	udp6 := `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+  0: 00000000000000000000000001000000:0202 00000000000000000000000000000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 18874 2 0000000000000000 0
+`
+
+	listeners, err = parseProcNet(udp6, "udp6")
+	require.NoError(t, err)
+	require.Equal(t, []listener{
+		{SystemSecurityPostureListener: api.SystemSecurityPostureListener{Protocol: "udp", Address: "::1", Port: 514}, inode: "18874"},
+	}, listeners)
+
+	_, err = parseProcNet("header\n 0: 0100007F:0016\n", "tcp")
+	require.Error(t, err)
+}
+
+func TestSortListeners(t *testing.T) {
+	t.Parallel()
+
+	listeners := []api.SystemSecurityPostureListener{
+		{Protocol: "udp", Address: "0.0.0.0", Port: 53},
+		{Protocol: "tcp", Address: "::", Port: 8443},
+		{Protocol: "tcp", Address: "0.0.0.0", Port: 8443},
+		{Protocol: "tcp", Address: "127.0.0.1", Port: 22},
+	}
+
+	sortListeners(listeners)
+
+	require.Equal(t, []api.SystemSecurityPostureListener{
+		{Protocol: "tcp", Address: "127.0.0.1", Port: 22},
+		{Protocol: "tcp", Address: "0.0.0.0", Port: 8443},
+		{Protocol: "tcp", Address: "::", Port: 8443},
+		{Protocol: "udp", Address: "0.0.0.0", Port: 53},
+	}, listeners)
+}
@@ -0,0 +1,3 @@
+// Package logforward provides logic to forward the system journal to remote syslog servers, Grafana
+// Loki or HTTP endpoints.
+package logforward
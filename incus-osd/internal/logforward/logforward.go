@@ -0,0 +1,475 @@
+package logforward
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// CursorPath is the directory holding the journal cursor of the last entry forwarded to each target.
+var CursorPath = "/var/lib/incus-os/logforward"
+
+// batchSize is the maximum number of journal entries sent at once.
+const batchSize = 100
+
+// flushInterval is how long journal entries are held before being sent as a partial batch.
+const flushInterval = time.Second
+
+var nameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// priorities are the syslog priority names, by severity.
+var priorities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+var (
+	forwarders   = map[string]*forwarder{}
+	forwardersMu sync.Mutex
+)
+
+// entry represents a single journal entry.
+type entry struct {
+	Cursor     string
+	Timestamp  time.Time
+	Hostname   string
+	Unit       string
+	Identifier string
+	PID        string
+	Facility   int
+	Priority   int
+	Message    string
+}
+
+// forwarder follows the journal, sending its entries to a target.
+type forwarder struct {
+	target api.ServiceLogForwardTarget
+	sender func(ctx context.Context, entries []entry) error
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu     sync.Mutex
+	status api.ServiceLogForwardTargetState
+}
+
+// Validate checks that the forwarding targets are usable.
+func Validate(targets []api.ServiceLogForwardTarget) error {
+	names := []string{}
+
+	for _, target := range targets {
+		if !nameRegex.MatchString(target.Name) || slices.Contains(names, target.Name) {
+			return fmt.Errorf("invalid or duplicate target name %q", target.Name)
+		}
+
+		names = append(names, target.Name)
+
+		switch target.Type {
+		case "syslog":
+			if target.Address == "" {
+				return fmt.Errorf("missing address for target %q", target.Name)
+			}
+
+			if !slices.Contains([]string{"", "udp", "tcp", "tls"}, target.Protocol) {
+				return fmt.Errorf("invalid protocol %q for target %q", target.Protocol, target.Name)
+			}
+
+			if len(target.Labels) > 0 || target.Username != "" || target.Password != "" {
+				return fmt.Errorf("labels and authentication aren't supported by the syslog target %q", target.Name)
+			}
+		case "loki", "http":
+			u, err := url.Parse(target.Address)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("invalid URL %q for target %q", target.Address, target.Name)
+			}
+
+			if target.Protocol != "" {
+				return fmt.Errorf("a protocol can only be set for syslog targets, not %q", target.Name)
+			}
+
+			if len(target.Labels) > 0 && target.Type != "loki" {
+				return fmt.Errorf("labels are only supported by Loki targets, not %q", target.Name)
+			}
+		default:
+			return fmt.Errorf("invalid type %q for target %q", target.Type, target.Name)
+		}
+
+		if target.Priority != "" && !slices.Contains(priorities, target.Priority) {
+			return fmt.Errorf("invalid priority %q for target %q", target.Priority, target.Name)
+		}
+
+		for _, unit := range target.Units {
+			if unit == "" || strings.ContainsAny(unit, " /") {
+				return fmt.Errorf("invalid unit %q for target %q", unit, target.Name)
+			}
+		}
+
+		if (target.TLSClientCertificate == "") != (target.TLSClientKey == "") {
+			return fmt.Errorf("both a TLS client certificate and key are required for target %q", target.Name)
+		}
+	}
+
+	return nil
+}
+
+// Start starts forwarding the journal to the targets, replacing any running forwarder. The secrets
+// referenced by the targets must already be resolved.
+func Start(ctx context.Context, targets []api.ServiceLogForwardTarget) error {
+	Stop()
+
+	err := os.MkdirAll(CursorPath, 0o700)
+	if err != nil {
+		return err
+	}
+
+	forwardersMu.Lock()
+	defer forwardersMu.Unlock()
+
+	for _, target := range targets {
+		sender, err := getSender(target)
+		if err != nil {
+			return fmt.Errorf("failed to configure target %q: %w", target.Name, err)
+		}
+
+		// The forwarder runs past the request that started it.
+		fctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+		f := &forwarder{
+			target: target,
+			sender: sender,
+			cancel: cancel,
+			done:   make(chan struct{}),
+			status: api.ServiceLogForwardTargetState{
+				Name:   target.Name,
+				Status: "forwarding",
+			},
+		}
+
+		forwarders[target.Name] = f
+
+		go f.run(fctx)
+	}
+
+	return nil
+}
+
+// Stop stops forwarding the journal to all targets.
+func Stop() {
+	forwardersMu.Lock()
+	defer forwardersMu.Unlock()
+
+	for name, f := range forwarders {
+		f.cancel()
+		<-f.done
+
+		delete(forwarders, name)
+	}
+}
+
+// Status returns the forwarding status of the targets.
+func Status(targets []api.ServiceLogForwardTarget) []api.ServiceLogForwardTargetState {
+	forwardersMu.Lock()
+	defer forwardersMu.Unlock()
+
+	ret := make([]api.ServiceLogForwardTargetState, 0, len(targets))
+
+	for _, target := range targets {
+		f, ok := forwarders[target.Name]
+		if !ok {
+			ret = append(ret, api.ServiceLogForwardTargetState{Name: target.Name, Status: "stopped"})
+
+			continue
+		}
+
+		f.mu.Lock()
+		ret = append(ret, f.status)
+		f.mu.Unlock()
+	}
+
+	return ret
+}
+
+// run follows the journal until the context is cancelled, restarting journalctl if it fails.
+func (f *forwarder) run(ctx context.Context) {
+	defer close(f.done)
+
+	for {
+		err := f.follow(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		slog.WarnContext(ctx, "Failed reading the journal for log forwarding", "target", f.target.Name, "err", err)
+		f.setError(err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
+
+// follow reads the journal entries matching the target filters, starting after the last forwarded
+// entry, and sends them in batches. When the target is unreachable, sending is retried and the journal
+// isn't read further, so the pending entries are buffered by the journal itself, including across
+// reboots.
+func (f *forwarder) follow(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "journalctl", f.getJournalArgs()...) //nolint:gosec
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		cancel()
+
+		_ = cmd.Wait()
+	}()
+
+	lines := make(chan []byte, batchSize)
+
+	go func() {
+		defer close(lines)
+
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 0, 64*1024), 8*1024*1024)
+
+		for scanner.Scan() {
+			select {
+			case lines <- slices.Clone(scanner.Bytes()):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	batch := []entry{}
+	ticker := time.NewTicker(flushInterval)
+
+	defer ticker.Stop()
+
+	for {
+		flush := false
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case line, ok := <-lines:
+			if !ok {
+				return errors.New("journalctl exited")
+			}
+
+			e, err := parseEntry(line)
+			if err != nil {
+				slog.DebugContext(ctx, "Skipping invalid journal entry", "target", f.target.Name, "err", err)
+
+				continue
+			}
+
+			batch = append(batch, e)
+			flush = len(batch) >= batchSize
+		case <-ticker.C:
+			flush = len(batch) > 0
+		}
+
+		if !flush {
+			continue
+		}
+
+		err := f.send(ctx, batch)
+		if err != nil {
+			return err
+		}
+
+		batch = []entry{}
+	}
+}
+
+// send sends a batch of entries, retrying with an increasing delay until it succeeds, and records the
+// cursor of the last entry.
+func (f *forwarder) send(ctx context.Context, batch []entry) error {
+	delay := time.Second
+
+	for {
+		err := f.sender(ctx, batch)
+		if err == nil {
+			break
+		}
+
+		f.setError(err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay = min(delay*2, time.Minute)
+	}
+
+	f.mu.Lock()
+	f.status.Status = "forwarding"
+	f.status.Error = ""
+	f.status.Forwarded += int64(len(batch))
+	f.status.LastForwarded = time.Now().UTC()
+	f.mu.Unlock()
+
+	return os.WriteFile(f.getCursorFile(), []byte(batch[len(batch)-1].Cursor), 0o600)
+}
+
+// setError records the last error encountered while forwarding.
+func (f *forwarder) setError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.status.Status = "retrying"
+	f.status.Error = err.Error()
+}
+
+// getCursorFile returns the path of the file holding the cursor of the last forwarded entry.
+func (f *forwarder) getCursorFile() string {
+	return filepath.Join(CursorPath, f.target.Name+".cursor")
+}
+
+// getJournalArgs returns the journalctl arguments following the entries matching the target filters.
+// Without a cursor, only new entries are forwarded.
+func (f *forwarder) getJournalArgs() []string {
+	args := []string{"--follow", "--output=json", "--no-pager"}
+
+	priority := f.target.Priority
+	if priority == "" {
+		priority = "info"
+	}
+
+	args = append(args, "--priority="+priority)
+
+	for _, unit := range f.target.Units {
+		args = append(args, "--unit="+unit)
+	}
+
+	cursor, err := os.ReadFile(f.getCursorFile())
+	if err == nil && len(cursor) > 0 {
+		args = append(args, "--after-cursor="+strings.TrimSpace(string(cursor)))
+	} else {
+		args = append(args, "--lines=0")
+	}
+
+	return args
+}
+
+// parseEntry parses a journal entry exported as JSON by journalctl.
+func parseEntry(line []byte) (entry, error) {
+	fields := map[string]json.RawMessage{}
+
+	err := json.Unmarshal(line, &fields)
+	if err != nil {
+		return entry{}, err
+	}
+
+	// Fields are either strings or, for binary data, arrays of bytes.
+	getField := func(name string) string {
+		raw, ok := fields[name]
+		if !ok {
+			return ""
+		}
+
+		var value string
+
+		err := json.Unmarshal(raw, &value)
+		if err == nil {
+			return value
+		}
+
+		var values []int
+
+		err = json.Unmarshal(raw, &values)
+		if err == nil {
+			ret := make([]byte, 0, len(values))
+			for _, v := range values {
+				ret = append(ret, byte(v))
+			}
+
+			return string(ret)
+		}
+
+		return ""
+	}
+
+	ret := entry{
+		Cursor:     getField("__CURSOR"),
+		Hostname:   getField("_HOSTNAME"),
+		Unit:       getField("_SYSTEMD_UNIT"),
+		Identifier: getField("SYSLOG_IDENTIFIER"),
+		PID:        getField("_PID"),
+		Facility:   3,
+		Priority:   6,
+		Message:    getField("MESSAGE"),
+	}
+
+	if ret.Cursor == "" {
+		return entry{}, errors.New("missing journal cursor")
+	}
+
+	usec, err := strconv.ParseInt(getField("__REALTIME_TIMESTAMP"), 10, 64)
+	if err != nil {
+		return entry{}, fmt.Errorf("invalid journal timestamp: %w", err)
+	}
+
+	ret.Timestamp = time.UnixMicro(usec).UTC()
+
+	priority, err := strconv.Atoi(getField("PRIORITY"))
+	if err == nil && priority >= 0 && priority < len(priorities) {
+		ret.Priority = priority
+	}
+
+	facility, err := strconv.Atoi(getField("SYSLOG_FACILITY"))
+	if err == nil && facility >= 0 && facility < 24 {
+		ret.Facility = facility
+	}
+
+	if ret.Identifier == "" {
+		ret.Identifier = strings.TrimSuffix(ret.Unit, ".service")
+	}
+
+	if ret.Hostname == "" {
+		ret.Hostname, _ = os.Hostname()
+	}
+
+	return ret, nil
+}
+
+// getSyslogAddress returns the address of a syslog target, with the default port of its protocol.
+func getSyslogAddress(target api.ServiceLogForwardTarget) string {
+	_, _, err := net.SplitHostPort(target.Address)
+	if err == nil {
+		return target.Address
+	}
+
+	if target.Protocol == "tls" {
+		return net.JoinHostPort(target.Address, "6514")
+	}
+
+	return net.JoinHostPort(target.Address, "514")
+}
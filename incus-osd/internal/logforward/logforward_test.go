@@ -0,0 +1,157 @@
+package logforward
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	valid := []api.ServiceLogForwardTarget{
+		{Name: "syslog", Type: "syslog", Address: "10.0.0.10", Protocol: "tls", Priority: "warning"},
+		{Name: "loki", Type: "loki", Address: "https://loki.example.com/loki/api/v1/push", Units: []string{"incus.service"}, Labels: map[string]string{"site": "lab"}},
+		{Name: "http", Type: "http", Address: "http://10.0.0.20:8080/logs", Username: "user", Password: "secret://local/logs"},
+	}
+
+	require.NoError(t, Validate(nil))
+	require.NoError(t, Validate(valid))
+
+	tests := []struct {
+		name   string
+		target api.ServiceLogForwardTarget
+		err    string
+	}{
+		{"invalid name", api.ServiceLogForwardTarget{Name: "../logs", Type: "syslog", Address: "10.0.0.10"}, "invalid or duplicate target name"},
+		{"duplicate name", api.ServiceLogForwardTarget{Name: "loki", Type: "syslog", Address: "10.0.0.10"}, "invalid or duplicate target name"},
+		{"invalid type", api.ServiceLogForwardTarget{Name: "other", Type: "kafka", Address: "10.0.0.10"}, "invalid type"},
+		{"missing address", api.ServiceLogForwardTarget{Name: "other", Type: "syslog"}, "missing address"},
+		{"invalid protocol", api.ServiceLogForwardTarget{Name: "other", Type: "syslog", Address: "10.0.0.10", Protocol: "quic"}, "invalid protocol"},
+		{"syslog labels", api.ServiceLogForwardTarget{Name: "other", Type: "syslog", Address: "10.0.0.10", Labels: map[string]string{"a": "b"}}, "aren't supported"},
+		{"invalid URL", api.ServiceLogForwardTarget{Name: "other", Type: "loki", Address: "loki.example.com"}, "invalid URL"},
+		{"HTTP labels", api.ServiceLogForwardTarget{Name: "other", Type: "http", Address: "http://10.0.0.20", Labels: map[string]string{"a": "b"}}, "only supported by Loki"},
+		{"invalid priority", api.ServiceLogForwardTarget{Name: "other", Type: "syslog", Address: "10.0.0.10", Priority: "error"}, "invalid priority"},
+		{"invalid unit", api.ServiceLogForwardTarget{Name: "other", Type: "syslog", Address: "10.0.0.10", Units: []string{""}}, "invalid unit"},
+		{"missing key", api.ServiceLogForwardTarget{Name: "other", Type: "syslog", Address: "10.0.0.10", TLSClientCertificate: "cert"}, "both a TLS client certificate and key"},
+	}
+
+	for _, tc := range tests {
+		require.ErrorContains(t, Validate(append(valid, tc.target)), tc.err, tc.name)
+	}
+}
+
+func TestParseEntry(t *testing.T) {
+	t.Parallel()
+
+	e, err := parseEntry([]byte(`{"__CURSOR":"s=abc;i=1","__REALTIME_TIMESTAMP":"1760536800123456","_HOSTNAME":"server01","_SYSTEMD_UNIT":"incus.service","SYSLOG_IDENTIFIER":"incusd","_PID":"1234","PRIORITY":"4","SYSLOG_FACILITY":"1","MESSAGE":"Instance started"}`))
+	require.NoError(t, err)
+	require.Equal(t, entry{
+		Cursor:     "s=abc;i=1",
+		Timestamp:  time.UnixMicro(1760536800123456).UTC(),
+		Hostname:   "server01",
+		Unit:       "incus.service",
+		Identifier: "incusd",
+		PID:        "1234",
+		Facility:   1,
+		Priority:   4,
+		Message:    "Instance started",
+	}, e)
+
+	// Binary messages are exported as arrays of bytes, and the identifier defaults to the unit name.
+	e, err = parseEntry([]byte(`{"__CURSOR":"s=abc;i=2","__REALTIME_TIMESTAMP":"1760536800123456","_HOSTNAME":"server01","_SYSTEMD_UNIT":"lldpd.service","MESSAGE":[104,105,10]}`))
+	require.NoError(t, err)
+	require.Equal(t, "hi\n", e.Message)
+	require.Equal(t, "lldpd", e.Identifier)
+	require.Equal(t, 6, e.Priority)
+	require.Equal(t, 3, e.Facility)
+
+	_, err = parseEntry([]byte(`{"__REALTIME_TIMESTAMP":"1760536800123456"}`))
+	require.ErrorContains(t, err, "missing journal cursor")
+
+	_, err = parseEntry([]byte(`{"__CURSOR":"s=abc;i=3"}`))
+	require.ErrorContains(t, err, "invalid journal timestamp")
+}
+
+func TestFormatSyslog(t *testing.T) {
+	t.Parallel()
+
+	e := entry{
+		Timestamp:  time.Date(2025, 10, 15, 14, 0, 0, 0, time.UTC),
+		Hostname:   "server01",
+		Identifier: "incusd",
+		PID:        "1234",
+		Facility:   3,
+		Priority:   3,
+		Message:    "Failed to start instance",
+	}
+
+	require.Equal(t, "<27>1 2025-10-15T14:00:00Z server01 incusd 1234 - - Failed to start instance", formatSyslog(e))
+
+	e.Identifier = ""
+	e.PID = ""
+	require.Equal(t, "<27>1 2025-10-15T14:00:00Z server01 - - - - Failed to start instance", formatSyslog(e))
+}
+
+func TestFormatLoki(t *testing.T) {
+	t.Parallel()
+
+	target := api.ServiceLogForwardTarget{Labels: map[string]string{"site": "lab"}}
+	ts := time.Date(2025, 10, 15, 14, 0, 0, 0, time.UTC)
+
+	body, err := formatLoki(target, []entry{
+		{Timestamp: ts, Hostname: "server01", Unit: "incus.service", Identifier: "incusd", Priority: 6, Message: "one"},
+		{Timestamp: ts.Add(time.Second), Hostname: "server01", Unit: "incus.service", Identifier: "incusd", Priority: 6, Message: "two"},
+		{Timestamp: ts, Hostname: "server01", Identifier: "kernel", Priority: 4, Message: "three"},
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"streams":[
+		{"stream":{"site":"lab","host":"server01","unit":"incus.service","identifier":"incusd","priority":"info"},"values":[["1760536800000000000","one"],["1760536801000000000","two"]]},
+		{"stream":{"site":"lab","host":"server01","identifier":"kernel","priority":"warning"},"values":[["1760536800000000000","three"]]}
+	]}`, string(body))
+}
+
+func TestSendHTTP(t *testing.T) {
+	t.Parallel()
+
+	received := []map[string]string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "user" || password != "password" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+	}))
+
+	defer server.Close()
+
+	target := api.ServiceLogForwardTarget{Name: "http", Type: "http", Address: server.URL, Username: "user", Password: "password"}
+
+	sender, err := getSender(target)
+	require.NoError(t, err)
+
+	entries := []entry{{Timestamp: time.Date(2025, 10, 15, 14, 0, 0, 0, time.UTC), Hostname: "server01", Unit: "incus.service", Priority: 6, Message: "hello"}}
+
+	require.NoError(t, sender(context.Background(), entries))
+	require.Equal(t, []map[string]string{{"timestamp": "2025-10-15T14:00:00Z", "hostname": "server01", "unit": "incus.service", "priority": "info", "message": "hello"}}, received)
+
+	// Failures are reported, so the batch can be retried.
+	target.Password = "wrong"
+
+	sender, err = getSender(target)
+	require.NoError(t, err)
+	require.ErrorContains(t, sender(context.Background(), entries), "401")
+}
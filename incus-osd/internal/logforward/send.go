@@ -0,0 +1,257 @@
+package logforward
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// getSender returns the function sending a batch of journal entries to a target.
+func getSender(target api.ServiceLogForwardTarget) (func(ctx context.Context, entries []entry) error, error) {
+	tlsConfig, err := getTLSConfig(target)
+	if err != nil {
+		return nil, err
+	}
+
+	if target.Type == "syslog" {
+		return func(ctx context.Context, entries []entry) error {
+			return sendSyslog(ctx, target, tlsConfig, entries)
+		}, nil
+	}
+
+	defaultTransport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, errors.New("unexpected default HTTP transport")
+	}
+
+	transport := defaultTransport.Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	client := &http.Client{Transport: transport, Timeout: 30 * time.Second}
+
+	return func(ctx context.Context, entries []entry) error {
+		var body []byte
+
+		if target.Type == "loki" {
+			body, err = formatLoki(target, entries)
+		} else {
+			body, err = formatHTTP(entries)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return sendHTTP(ctx, client, target, body)
+	}, nil
+}
+
+// getTLSConfig returns the TLS configuration used to reach a target.
+func getTLSConfig(target api.ServiceLogForwardTarget) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if target.TLSCACertificate != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(target.TLSCACertificate)) {
+			return nil, errors.New("invalid CA certificate")
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if target.TLSClientCertificate != "" {
+		cert, err := tls.X509KeyPair([]byte(target.TLSClientCertificate), []byte(target.TLSClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// sendSyslog sends the entries to a remote syslog server as RFC5424 messages. Over TCP and TLS, the
+// messages are framed using octet counting.
+func sendSyslog(ctx context.Context, target api.ServiceLogForwardTarget, tlsConfig *tls.Config, entries []entry) error {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+
+	switch target.Protocol {
+	case "tls":
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: tlsConfig}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", getSyslogAddress(target))
+	case "tcp":
+		conn, err = dialer.DialContext(ctx, "tcp", getSyslogAddress(target))
+	default:
+		conn, err = dialer.DialContext(ctx, "udp", getSyslogAddress(target))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	_ = conn.SetWriteDeadline(time.Now().Add(30 * time.Second))
+
+	for _, e := range entries {
+		msg := formatSyslog(e)
+
+		if target.Protocol == "tcp" || target.Protocol == "tls" {
+			msg = strconv.Itoa(len(msg)) + " " + msg
+		}
+
+		_, err = conn.Write([]byte(msg))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sendHTTP posts a JSON payload to a Loki or HTTP target.
+func sendHTTP(ctx context.Context, client *http.Client, target api.ServiceLogForwardTarget, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.Address, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if target.Username != "" || target.Password != "" {
+		req.SetBasicAuth(target.Username, target.Password)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return fmt.Errorf("unexpected status %q: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
+
+// formatSyslog returns an entry as an RFC5424 syslog message.
+func formatSyslog(e entry) string {
+	// Fields must be printable ASCII without spaces, "-" meaning empty.
+	field := func(value string, maxLength int) string {
+		value = strings.Map(func(r rune) rune {
+			if r <= ' ' || r > '~' {
+				return -1
+			}
+
+			return r
+		}, value)
+
+		if value == "" {
+			return "-"
+		}
+
+		if len(value) > maxLength {
+			return value[:maxLength]
+		}
+
+		return value
+	}
+
+	return fmt.Sprintf("<%d>1 %s %s %s %s - - %s", e.Facility*8+e.Priority, e.Timestamp.Format(time.RFC3339Nano), field(e.Hostname, 255), field(e.Identifier, 48), field(e.PID, 128), e.Message)
+}
+
+// formatLoki returns the entries as a Loki push request, with a stream per unit and priority.
+func formatLoki(target api.ServiceLogForwardTarget, entries []entry) ([]byte, error) {
+	type lokiStream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][]string        `json:"values"`
+	}
+
+	streams := []*lokiStream{}
+	streamsByKey := map[string]*lokiStream{}
+
+	for _, e := range entries {
+		key := e.Hostname + "/" + e.Unit + "/" + e.Identifier + "/" + priorities[e.Priority]
+
+		stream, ok := streamsByKey[key]
+		if !ok {
+			labels := maps.Clone(target.Labels)
+			if labels == nil {
+				labels = map[string]string{}
+			}
+
+			labels["host"] = e.Hostname
+			labels["priority"] = priorities[e.Priority]
+
+			if e.Unit != "" {
+				labels["unit"] = e.Unit
+			}
+
+			if e.Identifier != "" {
+				labels["identifier"] = e.Identifier
+			}
+
+			stream = &lokiStream{Stream: labels, Values: [][]string{}}
+			streamsByKey[key] = stream
+			streams = append(streams, stream)
+		}
+
+		stream.Values = append(stream.Values, []string{strconv.FormatInt(e.Timestamp.UnixNano(), 10), e.Message})
+	}
+
+	return json.Marshal(map[string]any{"streams": streams})
+}
+
+// formatHTTP returns the entries as a JSON array.
+func formatHTTP(entries []entry) ([]byte, error) {
+	type httpEntry struct {
+		Timestamp  string `json:"timestamp"`
+		Hostname   string `json:"hostname"`
+		Unit       string `json:"unit,omitempty"`
+		Identifier string `json:"identifier,omitempty"`
+		PID        string `json:"pid,omitempty"`
+		Priority   string `json:"priority"`
+		Message    string `json:"message"`
+	}
+
+	ret := make([]httpEntry, 0, len(entries))
+
+	for _, e := range entries {
+		ret = append(ret, httpEntry{
+			Timestamp:  e.Timestamp.Format(time.RFC3339Nano),
+			Hostname:   e.Hostname,
+			Unit:       e.Unit,
+			Identifier: e.Identifier,
+			PID:        e.PID,
+			Priority:   priorities[e.Priority],
+			Message:    e.Message,
+		})
+	}
+
+	return json.Marshal(ret)
+}
@@ -0,0 +1,434 @@
+package nftables
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+// apiPort is the port of the HTTPS API exposed by the primary application.
+const apiPort = 8443
+
+// ValidateHostFirewall checks that the host firewall configuration is usable.
+func ValidateHostFirewall(cfg *api.SystemSecurityFirewall) error {
+	if cfg == nil {
+		return nil
+	}
+
+	if cfg.DefaultPolicy != "" && cfg.DefaultPolicy != "accept" && cfg.DefaultPolicy != "drop" {
+		return fmt.Errorf("invalid default policy %q", cfg.DefaultPolicy)
+	}
+
+	for _, source := range cfg.APISources {
+		_, err := getAddressFamily(source)
+		if err != nil {
+			return err
+		}
+	}
+
+	for index, rule := range cfg.Rules {
+		if rule.Action != "accept" && rule.Action != "drop" && rule.Action != "reject" {
+			return fmt.Errorf("rule %d has invalid action %q", index, rule.Action)
+		}
+
+		if rule.Protocol != "" && rule.Protocol != "tcp" && rule.Protocol != "udp" {
+			return fmt.Errorf("rule %d has invalid protocol %q", index, rule.Protocol)
+		}
+
+		if rule.Protocol != "" && (rule.Port < 1 || rule.Port > 65535) {
+			return fmt.Errorf("rule %d has invalid port %d", index, rule.Port)
+		}
+
+		_, err := generateRule(rule)
+		if err != nil {
+			return fmt.Errorf("rule %d: %w", index, err)
+		}
+	}
+
+	return nil
+}
+
+// ApplyHostFirewall applies the host firewall protecting the management plane, replacing any
+// previous rules. It must be re-applied whenever the network or service configuration changes.
+//
+// The rules live in their own chain so they're applied on top of the per-interface rules.
+func ApplyHostFirewall(ctx context.Context, s *state.State) error {
+	// Make sure we have the expected chains.
+	err := SetupChains(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = subprocess.RunCommandContext(ctx, "nft", "add", "chain", "inet", "incus-osd", "host-firewall", "{ type filter hook input priority 5 ; policy accept ; }")
+	if err != nil {
+		return err
+	}
+
+	// Empty the chain.
+	_, err = subprocess.RunCommandContext(ctx, "nft", "flush", "chain", "inet", "incus-osd", "host-firewall")
+	if err != nil {
+		return err
+	}
+
+	cfg := s.System.Security.Config.Firewall
+	if cfg == nil {
+		return nil
+	}
+
+	rules, err := generateHostFirewallRules(cfg, getManagementDevices(s.System.Network.Config), getServiceRules(s))
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		_, err = subprocess.RunCommandContext(ctx, "nft", append([]string{"add", "rule", "inet", "incus-osd", "host-firewall"}, rule...)...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ApplySSHRestriction only allows the listed sources to connect to the SSH service on the given port.
+// An empty list of sources lifts the restriction.
+func ApplySSHRestriction(ctx context.Context, port int, sources []string) error {
+	// Make sure we have the expected chains.
+	err := SetupChains(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = subprocess.RunCommandContext(ctx, "nft", "add", "chain", "inet", "incus-osd", "ssh-restriction", "{ type filter hook input priority 10 ; policy accept ; }")
+	if err != nil {
+		return err
+	}
+
+	// Empty the chain.
+	_, err = subprocess.RunCommandContext(ctx, "nft", "flush", "chain", "inet", "incus-osd", "ssh-restriction")
+	if err != nil {
+		return err
+	}
+
+	if len(sources) == 0 {
+		return nil
+	}
+
+	rules := [][]string{{"iifname", "lo", "accept"}}
+
+	for _, source := range sources {
+		family, err := getAddressFamily(source)
+		if err != nil {
+			return err
+		}
+
+		rules = append(rules, []string{family, "saddr", source, "tcp", "dport", strconv.Itoa(port), "accept"})
+	}
+
+	rules = append(rules, []string{"tcp", "dport", strconv.Itoa(port), "drop"})
+
+	for _, rule := range rules {
+		_, err = subprocess.RunCommandContext(ctx, "nft", append([]string{"add", "rule", "inet", "incus-osd", "ssh-restriction"}, rule...)...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// generateHostFirewallRules returns the list of nft rules implementing the host firewall.
+func generateHostFirewallRules(cfg *api.SystemSecurityFirewall, managementDevices []string, serviceRules []api.SystemNetworkFirewallRule) ([][]string, error) {
+	apiPortStr := strconv.Itoa(apiPort)
+
+	// Local traffic and existing connections are always allowed.
+	rules := [][]string{
+		{"iifname", "lo", "accept"},
+		{"ct", "state", "established,related", "accept"},
+	}
+
+	// Only allow the listed sources to reach the API.
+	if len(cfg.APISources) > 0 {
+		sources := map[string][]string{}
+
+		for _, source := range cfg.APISources {
+			family, err := getAddressFamily(source)
+			if err != nil {
+				return nil, err
+			}
+
+			sources[family] = append(sources[family], source)
+		}
+
+		for _, family := range []string{"ip", "ip6"} {
+			if len(sources[family]) == 0 {
+				continue
+			}
+
+			rules = append(rules, []string{family, "saddr", "{ " + strings.Join(sources[family], ", ") + " }", "tcp", "dport", apiPortStr, "accept"})
+		}
+
+		rules = append(rules, []string{"tcp", "dport", apiPortStr, "drop"})
+	}
+
+	// Add the user rules.
+	for _, firewallRule := range cfg.Rules {
+		rule, err := generateRule(firewallRule)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if cfg.DefaultPolicy != "drop" || len(managementDevices) == 0 {
+		return rules, nil
+	}
+
+	// Drop anything not explicitly allowed on the management interfaces, keeping the API, the DHCP
+	// clients and the enabled services reachable.
+	devices := slices.Clone(managementDevices)
+	slices.Sort(devices)
+
+	match := []string{"iifname", "{ " + strings.Join(devices, ", ") + " }"}
+
+	defaultRules := [][]string{
+		{"ct", "state", "invalid", "drop"},
+		{"ip", "protocol", "icmp", "accept"},
+		{"icmpv6", "type", "{echo-request,destination-unreachable,packet-too-big,time-exceeded,parameter-problem,nd-neighbor-solicit,nd-neighbor-advert,nd-router-solicit,nd-router-advert,mld-listener-query}", "accept"},
+		{"udp", "dport", "{ 68, 546 }", "accept"},
+		{"tcp", "dport", apiPortStr, "accept"},
+	}
+
+	for _, serviceRule := range serviceRules {
+		rule, err := generateRule(serviceRule)
+		if err != nil {
+			return nil, err
+		}
+
+		defaultRules = append(defaultRules, rule)
+	}
+
+	defaultRules = append(defaultRules, []string{"drop"})
+
+	for _, rule := range defaultRules {
+		rules = append(rules, append(slices.Clone(match), rule...))
+	}
+
+	return rules, nil
+}
+
+// getServiceRules returns the rules allowing incoming connections to the enabled services. Services
+// only acting as clients, such as the iSCSI initiator and NVMe over Fabrics, are covered by connection tracking.
+func getServiceRules(s *state.State) []api.SystemNetworkFirewallRule {
+	rules := []api.SystemNetworkFirewallRule{}
+
+	// The DHCP server.
+	if s.System.Network.Config != nil && s.System.Network.Config.DHCPServer != nil {
+		rules = append(rules, api.SystemNetworkFirewallRule{Action: "accept", Protocol: "udp", Port: 67})
+	}
+
+	// BGP sessions initiated by the neighbors.
+	if s.Services.BGP.Config.Enabled {
+		rules = append(rules, api.SystemNetworkFirewallRule{Action: "accept", Protocol: "tcp", Port: 179})
+	}
+
+	// Exported iSCSI targets.
+	if s.Services.ISCSI.Config.Enabled {
+		for _, export := range s.Services.ISCSI.Config.Exports {
+			port := export.Port
+			if port == 0 {
+				port = 3260
+			}
+
+			rules = append(rules, api.SystemNetworkFirewallRule{Action: "accept", Protocol: "tcp", Port: port})
+		}
+	}
+
+	// DRBD replication.
+	if s.Services.DRBD.Config.Enabled {
+		for _, resource := range s.Services.DRBD.Config.Resources {
+			port := resource.Port
+			if port == 0 {
+				port = 7789 + resource.Minor
+			}
+
+			rules = append(rules, api.SystemNetworkFirewallRule{Action: "accept", Protocol: "tcp", Port: port})
+		}
+	}
+
+	// The Incus API, when load-balanced by HAProxy.
+	if s.Services.HAProxy.Config.Enabled {
+		port := 443
+
+		if s.Services.HAProxy.Config.ListenAddress != "" {
+			_, portStr, err := net.SplitHostPort(s.Services.HAProxy.Config.ListenAddress)
+			if err == nil {
+				port, _ = strconv.Atoi(portStr)
+			}
+		}
+
+		rules = append(rules, api.SystemNetworkFirewallRule{Action: "accept", Protocol: "tcp", Port: port})
+	}
+
+	// The Linstor satellite.
+	if s.Services.Linstor.Config.Enabled {
+		port := 3366
+		if s.Services.Linstor.Config.TLSServerCertificate != "" && s.Services.Linstor.Config.TLSServerKey != "" && len(s.Services.Linstor.Config.TLSTrustedCertificates) > 0 {
+			port = 3367
+		}
+
+		if s.Services.Linstor.Config.ListenAddress != "" {
+			_, portStr, err := net.SplitHostPort(s.Services.Linstor.Config.ListenAddress)
+			if err == nil {
+				port, _ = strconv.Atoi(portStr)
+			}
+		}
+
+		rules = append(rules, api.SystemNetworkFirewallRule{Action: "accept", Protocol: "tcp", Port: port})
+	}
+
+	// The node exporter, when exposed by the metrics service.
+	if s.Services.Metrics.Config.Enabled {
+		port := 9100
+
+		if s.Services.Metrics.Config.ListenAddress != "" {
+			_, portStr, err := net.SplitHostPort(s.Services.Metrics.Config.ListenAddress)
+			if err == nil {
+				port, _ = strconv.Atoi(portStr)
+			}
+		}
+
+		rules = append(rules, api.SystemNetworkFirewallRule{Action: "accept", Protocol: "tcp", Port: port})
+	}
+
+	// OVN tunnels.
+	if s.Services.OVN.Config.Enabled {
+		port := 6081
+		if s.Services.OVN.Config.TunnelProtocol == "vxlan" {
+			port = 4789
+		}
+
+		rules = append(rules, api.SystemNetworkFirewallRule{Action: "accept", Protocol: "udp", Port: port})
+	}
+
+	// Emergency SSH access.
+	if s.Services.SSH.Config.Enabled {
+		port := s.Services.SSH.Config.Port
+		if port == 0 {
+			port = 22
+		}
+
+		rules = append(rules, api.SystemNetworkFirewallRule{Action: "accept", Protocol: "tcp", Port: port})
+	}
+
+	// VRRP advertisements.
+	if s.Services.VRRP.Config.Enabled {
+		rules = append(rules, api.SystemNetworkFirewallRule{Action: "accept", Protocol: "vrrp"})
+	}
+
+	// ACME HTTP-01 challenges.
+	acme := s.System.Security.Config.ACME
+	if acme != nil && (acme.Challenge == "" || acme.Challenge == "HTTP-01") {
+		port := 80
+
+		if acme.HTTPChallengeAddress != "" {
+			_, portStr, err := net.SplitHostPort(acme.HTTPChallengeAddress)
+			if err == nil {
+				port, _ = strconv.Atoi(portStr)
+			}
+		}
+
+		rules = append(rules, api.SystemNetworkFirewallRule{Action: "accept", Protocol: "tcp", Port: port})
+	}
+
+	// Update files served to peers.
+	peerCache := s.System.Update.Config.PeerCache
+	if peerCache != nil && peerCache.Enabled {
+		for _, client := range peerCache.AllowedClients {
+			rules = append(rules, api.SystemNetworkFirewallRule{Action: "accept", Source: client, Protocol: "tcp", Port: peerCache.GetPort()})
+		}
+	}
+
+	// Exported USB devices.
+	if len(s.Services.USBIP.Config.Exports) > 0 {
+		rules = append(rules, api.SystemNetworkFirewallRule{Action: "accept", Protocol: "tcp", Port: 3240})
+	}
+
+	// Direct Tailscale connections.
+	if s.Services.Tailscale.Config.Enabled {
+		rules = append(rules, api.SystemNetworkFirewallRule{Action: "accept", Protocol: "udp", Port: 41641})
+	}
+
+	return rules
+}
+
+// getManagementDevices returns the host devices of the interfaces with the management role. Physical
+// interfaces and bridges are reached through the host side of their veth pair.
+func getManagementDevices(networkCfg *api.SystemNetworkConfig) []string {
+	devices := []string{}
+
+	if networkCfg == nil {
+		return devices
+	}
+
+	role := api.SystemNetworkInterfaceRoleManagement
+
+	for _, iface := range networkCfg.Interfaces {
+		if slices.Contains(iface.Roles, role) {
+			devices = append(devices, "_v"+iface.Name)
+		}
+	}
+
+	for _, iface := range networkCfg.Bonds {
+		if slices.Contains(iface.Roles, role) {
+			devices = append(devices, "_v"+iface.Name)
+		}
+	}
+
+	for _, iface := range networkCfg.Bridges {
+		if slices.Contains(iface.Roles, role) {
+			devices = append(devices, "_v"+iface.Name)
+		}
+	}
+
+	for _, iface := range networkCfg.VLANs {
+		if slices.Contains(iface.Roles, role) {
+			devices = append(devices, iface.Name)
+		}
+	}
+
+	for _, iface := range networkCfg.Wifi {
+		if slices.Contains(iface.Roles, role) {
+			devices = append(devices, iface.Name)
+		}
+	}
+
+	for _, iface := range networkCfg.PPPoE {
+		if slices.Contains(iface.Roles, role) {
+			devices = append(devices, iface.Name)
+		}
+	}
+
+	for _, iface := range networkCfg.Wireguard {
+		if slices.Contains(iface.Roles, role) {
+			devices = append(devices, iface.Name)
+		}
+	}
+
+	for _, iface := range networkCfg.Tunnels {
+		if slices.Contains(iface.Roles, role) {
+			devices = append(devices, iface.Name)
+		}
+	}
+
+	return devices
+}
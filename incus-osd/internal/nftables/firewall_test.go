@@ -0,0 +1,73 @@
+package nftables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+func TestHostFirewall(t *testing.T) {
+	t.Parallel()
+
+	// Validation.
+	require.NoError(t, ValidateHostFirewall(nil))
+	require.NoError(t, ValidateHostFirewall(&api.SystemSecurityFirewall{}))
+	require.Error(t, ValidateHostFirewall(&api.SystemSecurityFirewall{DefaultPolicy: "reject"}))
+	require.Error(t, ValidateHostFirewall(&api.SystemSecurityFirewall{APISources: []string{"not-an-address"}}))
+	require.Error(t, ValidateHostFirewall(&api.SystemSecurityFirewall{Rules: []api.SystemNetworkFirewallRule{{Action: "allow"}}}))
+	require.Error(t, ValidateHostFirewall(&api.SystemSecurityFirewall{Rules: []api.SystemNetworkFirewallRule{{Action: "accept", Protocol: "tcp"}}}))
+
+	// Source allowlist without a default policy.
+	cfg := &api.SystemSecurityFirewall{
+		APISources: []string{"10.0.0.0/8", "2001:db8::/32", "192.0.2.1"},
+		Rules:      []api.SystemNetworkFirewallRule{{Action: "accept", Protocol: "tcp", Port: 22, Source: "10.0.0.1"}},
+	}
+
+	require.NoError(t, ValidateHostFirewall(cfg))
+
+	rules, err := generateHostFirewallRules(cfg, []string{"_veth0"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"iifname", "lo", "accept"},
+		{"ct", "state", "established,related", "accept"},
+		{"ip", "saddr", "{ 10.0.0.0/8, 192.0.2.1 }", "tcp", "dport", "8443", "accept"},
+		{"ip6", "saddr", "{ 2001:db8::/32 }", "tcp", "dport", "8443", "accept"},
+		{"tcp", "dport", "8443", "drop"},
+		{"ip", "saddr", "10.0.0.1", "tcp", "dport", "22", "accept"},
+	}, rules)
+
+	// Default-deny on the management interfaces, with the service rules.
+	cfg = &api.SystemSecurityFirewall{DefaultPolicy: "drop"}
+
+	rules, err = generateHostFirewallRules(cfg, []string{"_veth1", "_veth0"}, []api.SystemNetworkFirewallRule{{Action: "accept", Protocol: "udp", Port: 6081}})
+	require.NoError(t, err)
+	require.Len(t, rules, 9)
+	require.Equal(t, []string{"iifname", "{ _veth0, _veth1 }", "udp", "dport", "6081", "accept"}, rules[7])
+	require.Equal(t, []string{"iifname", "{ _veth0, _veth1 }", "drop"}, rules[8])
+
+	// VRRP advertisements.
+	rules, err = generateHostFirewallRules(cfg, []string{"_veth0"}, []api.SystemNetworkFirewallRule{{Action: "accept", Protocol: "vrrp"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"iifname", "{ _veth0 }", "meta", "l4proto", "112", "accept"}, rules[7])
+
+	// No management interface, so nothing to drop.
+	rules, err = generateHostFirewallRules(cfg, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+
+	// Bonded management uplinks are reached through the host side of their veth pair.
+	networkCfg := &api.SystemNetworkConfig{
+		Interfaces: []api.SystemNetworkInterface{{Name: "eth0", Roles: []string{api.SystemNetworkInterfaceRoleManagement}}, {Name: "eth1"}},
+		Bonds:      []api.SystemNetworkBond{{Name: "uplink", Roles: []string{api.SystemNetworkInterfaceRoleManagement}}},
+		VLANs:      []api.SystemNetworkVLAN{{Name: "mgmt", Roles: []string{api.SystemNetworkInterfaceRoleManagement}}},
+	}
+
+	devices := getManagementDevices(networkCfg)
+	require.Equal(t, []string{"_veth0", "_vuplink", "mgmt"}, devices)
+
+	rules, err = generateHostFirewallRules(cfg, devices, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"iifname", "{ _veth0, _vuplink, mgmt }", "drop"}, rules[len(rules)-1])
+}
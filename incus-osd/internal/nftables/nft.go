@@ -72,6 +72,41 @@ func ApplyHwaddrFilters(ctx context.Context, networkCfg *api.SystemNetworkConfig
 	return nil
 }
 
+// ApplyAPIRestriction only allows connections to the Incus API, listening on the given port, through
+// the given interface. An empty interface name lifts the restriction.
+//
+// The rule lives in its own chain so it isn't affected by network configuration changes.
+func ApplyAPIRestriction(ctx context.Context, iface string, port int) error {
+	// Make sure we have the expected chains.
+	err := SetupChains(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = subprocess.RunCommandContext(ctx, "nft", "add", "chain", "inet", "incus-osd", "api-restriction", "{ type filter hook input priority 10 ; policy accept ; }")
+	if err != nil {
+		return err
+	}
+
+	// Empty the chain.
+	_, err = subprocess.RunCommandContext(ctx, "nft", "flush", "chain", "inet", "incus-osd", "api-restriction")
+	if err != nil {
+		return err
+	}
+
+	if iface == "" {
+		return nil
+	}
+
+	// Local connections, such as those proxied by Tailscale Serve, are always allowed.
+	_, err = subprocess.RunCommandContext(ctx, "nft", "add", "rule", "inet", "incus-osd", "api-restriction", "iifname", "!=", "{ lo, "+iface+" }", "tcp", "dport", strconv.Itoa(port), "drop")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // ApplyInputFilters applies the input firewall rules.
 func ApplyInputFilters(ctx context.Context, networkCfg *api.SystemNetworkConfig) error {
 	// Make sure we have the expected chains.
@@ -99,38 +134,11 @@ func ApplyInputFilters(ctx context.Context, networkCfg *api.SystemNetworkConfig)
 
 		// Add the user rules.
 		for _, firewallRule := range firewallRules {
-			rule := []string{}
-
-			if firewallRule.Source != "" {
-				var ip net.IP
-
-				if strings.Contains(firewallRule.Source, "/") {
-					var err error
-
-					ip, _, err = net.ParseCIDR(firewallRule.Source)
-					if err != nil {
-						return err
-					}
-				} else {
-					ip = net.ParseIP(firewallRule.Source)
-				}
-
-				if ip == nil {
-					return fmt.Errorf("bad source %q", firewallRule.Source)
-				}
-
-				if ip.To4() == nil {
-					rule = append(rule, "ip6", "saddr", firewallRule.Source)
-				} else {
-					rule = append(rule, "ip", "saddr", firewallRule.Source)
-				}
-			}
-
-			if firewallRule.Protocol != "" {
-				rule = append(rule, firewallRule.Protocol, "dport", strconv.Itoa(firewallRule.Port))
+			rule, err := generateRule(firewallRule)
+			if err != nil {
+				return err
 			}
 
-			rule = append(rule, firewallRule.Action)
 			rules = append(rules, rule)
 		}
 
@@ -146,12 +154,21 @@ func ApplyInputFilters(ctx context.Context, networkCfg *api.SystemNetworkConfig)
 		return nil
 	}
 
+	// Always allow DHCP requests on the device running the DHCP server.
+	withDHCPServer := func(name string, firewallRules []api.SystemNetworkFirewallRule) []api.SystemNetworkFirewallRule {
+		if networkCfg.DHCPServer == nil || networkCfg.DHCPServer.Interface != name {
+			return firewallRules
+		}
+
+		return append([]api.SystemNetworkFirewallRule{{Action: "accept", Protocol: "udp", Port: 67}}, firewallRules...)
+	}
+
 	for _, iface := range networkCfg.Interfaces {
 		if len(iface.FirewallRules) == 0 {
 			continue
 		}
 
-		err := applyFirewall("_v"+iface.Name, iface.FirewallRules)
+		err := applyFirewall("_v"+iface.Name, withDHCPServer(iface.Name, iface.FirewallRules))
 		if err != nil {
 			return err
 		}
@@ -162,7 +179,18 @@ func ApplyInputFilters(ctx context.Context, networkCfg *api.SystemNetworkConfig)
 			continue
 		}
 
-		err := applyFirewall(iface.Name, iface.FirewallRules)
+		err := applyFirewall(iface.Name, withDHCPServer(iface.Name, iface.FirewallRules))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, iface := range networkCfg.Bridges {
+		if len(iface.FirewallRules) == 0 {
+			continue
+		}
+
+		err := applyFirewall("_v"+iface.Name, withDHCPServer(iface.Name, iface.FirewallRules))
 		if err != nil {
 			return err
 		}
@@ -173,6 +201,28 @@ func ApplyInputFilters(ctx context.Context, networkCfg *api.SystemNetworkConfig)
 			continue
 		}
 
+		err := applyFirewall(iface.Name, withDHCPServer(iface.Name, iface.FirewallRules))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, iface := range networkCfg.Wifi {
+		if len(iface.FirewallRules) == 0 {
+			continue
+		}
+
+		err := applyFirewall(iface.Name, iface.FirewallRules)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, iface := range networkCfg.PPPoE {
+		if len(iface.FirewallRules) == 0 {
+			continue
+		}
+
 		err := applyFirewall(iface.Name, iface.FirewallRules)
 		if err != nil {
 			return err
@@ -189,6 +239,67 @@ func ApplyInputFilters(ctx context.Context, networkCfg *api.SystemNetworkConfig)
 			return err
 		}
 	}
+	for _, iface := range networkCfg.Tunnels {
+		if len(iface.FirewallRules) == 0 {
+			continue
+		}
+
+		err := applyFirewall(iface.Name, iface.FirewallRules)
+		if err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
+
+// generateRule returns the nft arguments matching a firewall rule.
+func generateRule(firewallRule api.SystemNetworkFirewallRule) ([]string, error) {
+	rule := []string{}
+
+	if firewallRule.Source != "" {
+		family, err := getAddressFamily(firewallRule.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		rule = append(rule, family, "saddr", firewallRule.Source)
+	}
+
+	if firewallRule.Protocol == "vrrp" {
+		// VRRP is its own IP protocol, without ports. It's only used by the service rules.
+		rule = append(rule, "meta", "l4proto", "112")
+	} else if firewallRule.Protocol != "" {
+		rule = append(rule, firewallRule.Protocol, "dport", strconv.Itoa(firewallRule.Port))
+	}
+
+	rule = append(rule, firewallRule.Action)
+
+	return rule, nil
+}
+
+// getAddressFamily returns the nft family ("ip" or "ip6") of an address or subnet.
+func getAddressFamily(source string) (string, error) {
+	var ip net.IP
+
+	if strings.Contains(source, "/") {
+		var err error
+
+		ip, _, err = net.ParseCIDR(source)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		ip = net.ParseIP(source)
+	}
+
+	if ip == nil {
+		return "", fmt.Errorf("bad source %q", source)
+	}
+
+	if ip.To4() == nil {
+		return "ip6", nil
+	}
+
+	return "ip", nil
+}
@@ -0,0 +1,299 @@
+package providers
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	apiupdate "github.com/lxc/incus-os/incus-osd/api/images"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+// BundleImportPath is where an imported update bundle gets extracted.
+var BundleImportPath = "/var/lib/incus-os/update-bundle"
+
+// bundleExporter is implemented by the providers able to export their latest release as an update bundle.
+type bundleExporter interface {
+	exportBundle(ctx context.Context, w io.Writer) error
+}
+
+// ExportBundle writes the signed index and the files of the latest release as a tar archive,
+// suitable for import on an air-gapped system using the bundle provider.
+func ExportBundle(ctx context.Context, p Provider, w io.Writer) error {
+	exporter, ok := p.(bundleExporter)
+	if !ok {
+		return fmt.Errorf("provider %q doesn't support exporting update bundles", p.Type())
+	}
+
+	return exporter.exportBundle(ctx, w)
+}
+
+func (p *bundle) exportBundle(ctx context.Context, w io.Writer) error {
+	err := p.locate()
+	if err != nil {
+		return err
+	}
+
+	return p.images.exportBundle(ctx, w)
+}
+
+func (p *images) exportBundle(ctx context.Context, w io.Writer) error {
+	// Always export the current release.
+	p.lastCheck = time.Time{}
+
+	latestUpdate, err := p.checkRelease(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Get the signed index, kept as-is so that it can be validated on import.
+	resp, err := p.getIndex(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	signedIndex, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	// Make sure the index wasn't replaced in the meantime.
+	index, err := p.verifyIndex(ctx, bytes.NewReader(signedIndex))
+	if err != nil {
+		return err
+	}
+
+	if !slices.ContainsFunc(index.Updates, func(update apiupdate.UpdateFull) bool { return update.Version == latestUpdate.Version }) {
+		return fmt.Errorf("release %q is no longer listed in the index", latestUpdate.Version)
+	}
+
+	// Write the archive.
+	tw := tar.NewWriter(w)
+
+	err = tw.WriteHeader(&tar.Header{Name: "index.sjson", Mode: 0o644, Size: int64(len(signedIndex)), ModTime: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	_, err = tw.Write(signedIndex)
+	if err != nil {
+		return err
+	}
+
+	err = tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: latestUpdate.Version + "/", Mode: 0o755, ModTime: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	for _, file := range latestUpdate.Files {
+		err = p.exportFile(ctx, tw, latestUpdate.Version, file)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tw.Close()
+}
+
+// exportFile adds an update file to the archive, validating it against the signed index.
+func (p *images) exportFile(ctx context.Context, tw *tar.Writer, version string, file apiupdate.UpdateFile) error {
+	var resp *http.Response
+
+	for _, baseURL := range p.getBaseURLs() {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/"+version+"/"+file.Filename, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err = tryRequest(p.client, req)
+		if err == nil && resp.StatusCode == http.StatusOK {
+			break
+		}
+
+		if resp != nil {
+			_ = resp.Body.Close()
+			resp = nil
+		}
+	}
+
+	if resp == nil {
+		return fmt.Errorf("failed to get file %q", file.Filename)
+	}
+
+	defer resp.Body.Close()
+
+	err := tw.WriteHeader(&tar.Header{Name: version + "/" + file.Filename, Mode: 0o644, Size: file.Size, ModTime: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.New()
+
+	_, err = io.CopyN(io.MultiWriter(tw, hash), resp.Body, file.Size)
+	if err != nil {
+		return fmt.Errorf("failed to export file %q: %w", file.Filename, err)
+	}
+
+	if hex.EncodeToString(hash.Sum(nil)) != file.Sha256 {
+		return fmt.Errorf("file %q doesn't match the signed index", file.Filename)
+	}
+
+	return nil
+}
+
+// ImportBundle extracts an update bundle, as produced by ExportBundle, for use by the bundle provider.
+// The signed index is validated before the bundle replaces any previously imported one.
+func ImportBundle(ctx context.Context, s *state.State, r io.Reader) error {
+	// Find the bundle provider, either the main one or a fallback.
+	var config map[string]string
+
+	candidates := []api.SystemProviderFallback{{Name: s.System.Provider.Config.Name, Config: s.System.Provider.Config.Config}}
+	candidates = append(candidates, s.System.Provider.Config.Fallbacks...)
+
+	for _, candidate := range candidates {
+		if candidate.Name == "bundle" && candidate.Config["path"] == "" {
+			config = map[string]string{"update_ca": candidate.Config["update_ca"]}
+
+			break
+		}
+	}
+
+	if config == nil {
+		return errors.New("importing update bundles requires the bundle provider with no configured path")
+	}
+
+	// Extract the archive next to its final location.
+	target := BundleImportPath + ".new"
+
+	err := os.RemoveAll(target)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.RemoveAll(target) }()
+
+	err = extractBundle(r, target)
+	if err != nil {
+		return err
+	}
+
+	// Validate the signed index.
+	signedIndex, err := os.ReadFile(filepath.Join(target, "index.sjson")) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("invalid update bundle: %w", err)
+	}
+
+	updateCA := config["update_ca"]
+	if updateCA == "" {
+		updateCA = LXCUpdateCA
+	}
+
+	_, err = (&images{state: s, updateCA: updateCA}).verifyIndex(ctx, bytes.NewReader(signedIndex))
+	if err != nil {
+		return fmt.Errorf("invalid update bundle signature: %w", err)
+	}
+
+	// Replace the previous bundle.
+	err = os.RemoveAll(BundleImportPath)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(target, BundleImportPath)
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256(signedIndex)
+
+	s.OS.UpdateBundle = &api.SystemUpdateBundle{
+		Source:      "import",
+		IndexSHA256: hex.EncodeToString(hash[:]),
+		ImportedAt:  time.Now().UTC().Format(time.RFC3339),
+	}
+
+	_ = s.Save()
+
+	return nil
+}
+
+// extractBundle extracts a tar archive, only allowing for directories and regular files within the target.
+func extractBundle(r io.Reader, target string) error {
+	err := os.MkdirAll(target, 0o700)
+	if err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(r)
+
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		name := filepath.Clean(hdr.Name)
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid path %q in update bundle", hdr.Name)
+		}
+
+		path := filepath.Join(target, name)
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(path, 0o755)
+			if err != nil {
+				return err
+			}
+
+		case tar.TypeReg:
+			err = os.MkdirAll(filepath.Dir(path), 0o755)
+			if err != nil {
+				return err
+			}
+
+			err = extractFile(tr, path)
+			if err != nil {
+				return err
+			}
+
+		default:
+			return fmt.Errorf("unsupported entry %q in update bundle", hdr.Name)
+		}
+	}
+}
+
+// extractFile writes the current tar entry to disk.
+func extractFile(r io.Reader, path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644) //nolint:gosec
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	_, err = io.Copy(f, r) //nolint:gosec
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
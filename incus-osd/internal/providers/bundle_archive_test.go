@@ -0,0 +1,61 @@
+package providers
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func buildBundle(t *testing.T, entries map[string]string) *bytes.Buffer {
+	t.Helper()
+
+	buf := bytes.NewBuffer(nil)
+	tw := tar.NewWriter(buf)
+
+	for name, content := range entries {
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))})
+		require.NoError(t, err)
+
+		_, err = tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+
+	return buf
+}
+
+func TestExtractBundle(t *testing.T) {
+	t.Parallel()
+
+	target := filepath.Join(t.TempDir(), "bundle")
+
+	err := extractBundle(buildBundle(t, map[string]string{"index.sjson": "index", "202510272025/IncusOS.efi": "efi"}), target)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(target, "202510272025", "IncusOS.efi"))
+	require.NoError(t, err)
+	require.Equal(t, "efi", string(content))
+}
+
+func TestExtractBundleInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"../index.sjson", "/etc/passwd", "202510272025/../../index.sjson"} {
+		err := extractBundle(buildBundle(t, map[string]string{name: "bad"}), filepath.Join(t.TempDir(), "bundle"))
+		require.Error(t, err, name)
+	}
+
+	// Links are rejected.
+	buf := bytes.NewBuffer(nil)
+	tw := tar.NewWriter(buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: "index.sjson", Linkname: "/etc/shadow"}))
+	require.NoError(t, tw.Close())
+
+	err := extractBundle(buf, filepath.Join(t.TempDir(), "bundle"))
+	require.Error(t, err)
+}
@@ -8,15 +8,68 @@ import (
 	"github.com/lxc/incus-os/incus-osd/internal/state"
 )
 
-// Load gets a specific provider and initializes it with the provider configuration.
+// Load gets a specific provider and initializes it with the provider configuration. When fallback
+// providers are configured, updates are fetched from the first provider answering successfully.
 func Load(ctx context.Context, s *state.State) (Provider, error) {
+	p, err := load(ctx, s, s.System.Provider.Config.Name, s.System.Provider.Config.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(s.System.Provider.Config.Fallbacks) == 0 {
+		return p, nil
+	}
+
+	f := &failover{
+		state:     s,
+		providers: []Provider{p},
+	}
+
+	for _, fallback := range s.System.Provider.Config.Fallbacks {
+		// Fallback providers are only used for updates, so can't require registration.
+		if fallback.Name == "operations-center" {
+			return nil, errors.New("the operations-center provider can't be used as a fallback")
+		}
+
+		fp, err := load(ctx, s, fallback.Name, fallback.Config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load fallback provider %q: %w", fallback.Name, err)
+		}
+
+		f.providers = append(f.providers, fp)
+	}
+
+	return f, nil
+}
+
+// load gets a specific provider and initializes it with the provided configuration.
+func load(ctx context.Context, s *state.State, name string, config map[string]string) (Provider, error) {
 	var p Provider
 
-	switch s.System.Provider.Config.Name {
+	switch name {
 	case "images":
 		// Setup the images provider.
 		p = &images{
-			state: s,
+			state:  s,
+			config: config,
+		}
+
+	case "bundle":
+		// Setup the bundle provider.
+		p = &bundle{
+			images: images{
+				state:  s,
+				config: config,
+			},
+		}
+
+	case "oci":
+		// Setup the OCI provider.
+		p = &oci{
+			images: images{
+				state:  s,
+				config: config,
+			},
 		}
 
 	case "local":
@@ -32,7 +85,7 @@ func Load(ctx context.Context, s *state.State) (Provider, error) {
 		}
 
 	default:
-		return nil, fmt.Errorf("unknown provider %q", s.System.Provider.Config.Name)
+		return nil, fmt.Errorf("unknown provider %q", name)
 	}
 
 	err := p.load(ctx)
@@ -0,0 +1,205 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/delta"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+	"github.com/lxc/incus-os/incus-osd/internal/systemd"
+)
+
+var (
+	peerCacheMu     sync.Mutex
+	peerCacheServer *http.Server
+)
+
+// ValidatePeerCache checks that the peer cache configuration is usable.
+func ValidatePeerCache(cfg *api.SystemUpdatePeerCache) error {
+	if cfg == nil {
+		return nil
+	}
+
+	if cfg.Port < 0 || cfg.Port > 65535 {
+		return fmt.Errorf("invalid peer cache port %d", cfg.Port)
+	}
+
+	if cfg.Enabled && len(cfg.AllowedClients) == 0 {
+		return errors.New("at least one allowed client is required to serve update files to peers")
+	}
+
+	for _, client := range cfg.AllowedClients {
+		_, err := parsePeerCacheClient(client)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, peer := range cfg.Peers {
+		host, _, err := net.SplitHostPort(peer)
+		if err != nil {
+			host = peer
+		}
+
+		if host == "" {
+			return fmt.Errorf("invalid peer %q", peer)
+		}
+	}
+
+	return nil
+}
+
+// ApplyPeerCache starts or stops serving the downloaded update files to peers, following the configuration.
+func ApplyPeerCache(ctx context.Context, s *state.State) error {
+	peerCacheMu.Lock()
+	defer peerCacheMu.Unlock()
+
+	// Stop any existing server.
+	if peerCacheServer != nil {
+		err := peerCacheServer.Close()
+		if err != nil {
+			return err
+		}
+
+		peerCacheServer = nil
+	}
+
+	cfg := s.System.Update.Config.PeerCache
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
+	lc := &net.ListenConfig{}
+
+	listener, err := lc.Listen(ctx, "tcp", ":"+strconv.Itoa(cfg.GetPort()))
+	if err != nil {
+		return err
+	}
+
+	router := http.NewServeMux()
+	router.Handle("/chunks/", http.StripPrefix("/chunks", delta.NewLocalStore(getPeerCacheFiles)))
+
+	allowedClients := make([]netip.Prefix, 0, len(cfg.AllowedClients))
+
+	for _, client := range cfg.AllowedClients {
+		prefix, err := parsePeerCacheClient(client)
+		if err != nil {
+			_ = listener.Close()
+
+			return err
+		}
+
+		allowedClients = append(allowedClients, prefix)
+	}
+
+	peerCacheServer = &http.Server{
+		// Don't rely on the firewall alone, it may be configured to accept all traffic.
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isPeerCacheClientAllowed(allowedClients, r.RemoteAddr) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+
+				return
+			}
+
+			router.ServeHTTP(w, r)
+		}),
+
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: time.Minute,
+	}
+
+	go func(server *http.Server) {
+		err := server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.ErrorContext(ctx, "Peer cache server failed", "err", err.Error())
+		}
+	}(peerCacheServer)
+
+	return nil
+}
+
+// parsePeerCacheClient parses an allowed peer cache client, either a single address or a subnet.
+func parsePeerCacheClient(client string) (netip.Prefix, error) {
+	if strings.Contains(client, "/") {
+		prefix, err := netip.ParsePrefix(client)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid allowed client %q", client)
+		}
+
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(client)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid allowed client %q", client)
+	}
+
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// isPeerCacheClientAllowed returns whether the remote address of a request is one of the allowed clients.
+func isPeerCacheClientAllowed(allowedClients []netip.Prefix, remoteAddr string) bool {
+	addrPort, err := netip.ParseAddrPort(remoteAddr)
+	if err != nil {
+		return false
+	}
+
+	addr := addrPort.Addr().Unmap()
+
+	for _, prefix := range allowedClients {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getPeerCacheFiles returns the downloaded update files which can be served to peers.
+func getPeerCacheFiles() []string {
+	files := []string{}
+
+	for _, pattern := range []string{filepath.Join(systemd.SystemUpdatesPath, "*"), filepath.Join(systemd.SystemExtensionsPath, "*.raw")} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			continue
+		}
+
+		files = append(files, matches...)
+	}
+
+	return files
+}
+
+// getPeerStoreURLs returns the URLs of the chunk stores exposed by the configured peers.
+func getPeerStoreURLs(s *state.State) []string {
+	urls := []string{}
+
+	cfg := s.System.Update.Config.PeerCache
+	if cfg == nil {
+		return urls
+	}
+
+	for _, peer := range cfg.Peers {
+		// Peers are expected to serve the update files on the same port as this system.
+		_, _, err := net.SplitHostPort(peer)
+		if err != nil {
+			peer = net.JoinHostPort(peer, strconv.Itoa(cfg.GetPort()))
+		}
+
+		urls = append(urls, "http://"+peer+"/chunks")
+	}
+
+	return urls
+}
@@ -0,0 +1,39 @@
+package providers
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+func TestValidatePeerCache(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidatePeerCache(&api.SystemUpdatePeerCache{Enabled: true, AllowedClients: []string{"10.0.0.0/24", "2001:db8::1"}}))
+	require.NoError(t, ValidatePeerCache(&api.SystemUpdatePeerCache{Peers: []string{"10.0.0.10"}}))
+	require.EqualError(t, ValidatePeerCache(&api.SystemUpdatePeerCache{Enabled: true}), "at least one allowed client is required to serve update files to peers")
+	require.EqualError(t, ValidatePeerCache(&api.SystemUpdatePeerCache{Enabled: true, AllowedClients: []string{"10.0.0.0/33"}}), `invalid allowed client "10.0.0.0/33"`)
+}
+
+func TestIsPeerCacheClientAllowed(t *testing.T) {
+	t.Parallel()
+
+	allowedClients := []netip.Prefix{}
+
+	for _, client := range []string{"10.0.0.1/24", "2001:db8::1"} {
+		prefix, err := parsePeerCacheClient(client)
+		require.NoError(t, err)
+
+		allowedClients = append(allowedClients, prefix)
+	}
+
+	require.True(t, isPeerCacheClientAllowed(allowedClients, "10.0.0.20:41234"))
+	require.True(t, isPeerCacheClientAllowed(allowedClients, "[::ffff:10.0.0.20]:41234"))
+	require.True(t, isPeerCacheClientAllowed(allowedClients, "[2001:db8::1]:41234"))
+	require.False(t, isPeerCacheClientAllowed(allowedClients, "10.0.1.20:41234"))
+	require.False(t, isPeerCacheClientAllowed(allowedClients, "[2001:db8::2]:41234"))
+	require.False(t, isPeerCacheClientAllowed(allowedClients, "invalid"))
+}
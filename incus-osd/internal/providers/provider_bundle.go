@@ -0,0 +1,200 @@
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// updateMediaLabel is the partition or file system label of removable media holding an update bundle.
+const updateMediaLabel = "UPDATE_DATA"
+
+// updateMediaPath is where removable media holding an update bundle gets mounted.
+var updateMediaPath = "/run/incus-os/update-media"
+
+// The bundle provider, consuming a signed update bundle from a local path or removable media. The
+// bundle uses the same layout as the images server.
+type bundle struct {
+	images
+
+	path string
+}
+
+func (*bundle) Type() string {
+	return "bundle"
+}
+
+func (p *bundle) GetSecureBootCertUpdate(ctx context.Context) (SecureBootCertUpdate, error) {
+	err := p.locate()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.images.GetSecureBootCertUpdate(ctx)
+}
+
+func (p *bundle) GetOSUpdate(ctx context.Context) (OSUpdate, error) {
+	err := p.locate()
+	if err != nil {
+		return nil, err
+	}
+
+	update, err := p.images.GetOSUpdate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record the release provided by the bundle.
+	p.state.OS.UpdateBundle.Version = p.latestUpdate.Version
+	p.state.OS.UpdateBundle.Origin = p.latestUpdate.Origin
+
+	return update, nil
+}
+
+func (p *bundle) GetApplicationUpdate(ctx context.Context, name string) (ApplicationUpdate, error) {
+	err := p.locate()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.images.GetApplicationUpdate(ctx, name)
+}
+
+func (p *bundle) GetChannelVersions(ctx context.Context) (map[string][]string, error) {
+	err := p.locate()
+	if err != nil {
+		if errors.Is(err, ErrNoUpdateAvailable) {
+			return map[string][]string{}, nil
+		}
+
+		return nil, err
+	}
+
+	return p.images.GetChannelVersions(ctx)
+}
+
+func (p *bundle) load(_ context.Context) error {
+	// Set up the configuration.
+	p.path = p.config["path"]
+	p.updateCA = p.config["update_ca"]
+
+	// Basic validation.
+	if p.path != "" && !filepath.IsAbs(p.path) {
+		return fmt.Errorf("bundle path %q must be absolute", p.path)
+	}
+
+	if p.updateCA == "" {
+		p.updateCA = LXCUpdateCA
+	}
+
+	// Read the bundle through a file transport, allowing for the images provider logic to be reused.
+	transport := &http.Transport{}
+	transport.RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
+
+	p.client = &http.Client{Transport: transport}
+
+	return nil
+}
+
+// locate finds the update bundle, preferring an imported bundle and mounting removable media if no
+// path is configured, and records its provenance.
+func (p *bundle) locate() error {
+	bundlePath := p.path
+	source := p.path
+
+	if bundlePath == "" {
+		_, err := os.Stat(filepath.Join(BundleImportPath, "index.sjson"))
+		if err == nil {
+			bundlePath = BundleImportPath
+			source = "import"
+		} else {
+			bundlePath, err = mountUpdateMedia()
+			if err != nil {
+				return err
+			}
+
+			source = "media"
+		}
+	}
+
+	signedIndex, err := os.ReadFile(filepath.Join(bundlePath, "index.sjson")) //nolint:gosec
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ErrNoUpdateAvailable
+		}
+
+		return err
+	}
+
+	// Keep the existing record, including when the bundle was imported, if the bundle didn't change.
+	hash := sha256.Sum256(signedIndex)
+	indexSHA256 := hex.EncodeToString(hash[:])
+
+	if p.state.OS.UpdateBundle == nil || p.state.OS.UpdateBundle.Source != source || p.state.OS.UpdateBundle.IndexSHA256 != indexSHA256 {
+		p.state.OS.UpdateBundle = &api.SystemUpdateBundle{
+			Source:      source,
+			IndexSHA256: indexSHA256,
+		}
+	}
+
+	// The bundle may be replaced at any time, so always read its index again.
+	p.serverURL = (&url.URL{Scheme: "file", Path: bundlePath}).String()
+	p.lastCheck = time.Time{}
+
+	return nil
+}
+
+// mountUpdateMedia mounts the removable media holding an update bundle, if present, and returns its path.
+func mountUpdateMedia() (string, error) {
+	// Reuse already mounted media.
+	_, err := os.Stat(filepath.Join(updateMediaPath, "index.sjson"))
+	if err == nil {
+		return updateMediaPath, nil
+	}
+
+	// Detach any media which has since been removed.
+	_ = unix.Unmount(updateMediaPath, unix.MNT_DETACH)
+
+	// Check if update media is present.
+	device := ""
+
+	for _, candidate := range []string{"/dev/disk/by-partlabel/" + updateMediaLabel, "/dev/disk/by-label/" + updateMediaLabel} {
+		_, err := os.Stat(candidate)
+		if err == nil {
+			device = candidate
+
+			break
+		}
+	}
+
+	if device == "" {
+		return "", ErrNoUpdateAvailable
+	}
+
+	err = os.MkdirAll(updateMediaPath, 0o700)
+	if err != nil {
+		return "", err
+	}
+
+	// Mount the media read-only.
+	for _, fsType := range []string{"vfat", "iso9660", "ext4"} {
+		err = unix.Mount(device, updateMediaPath, fsType, unix.MS_RDONLY|unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, "")
+		if err == nil {
+			return updateMediaPath, nil
+		}
+	}
+
+	return "", errors.New("unable to mount update media as vfat, iso9660 or ext4")
+}
@@ -0,0 +1,155 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+// The failover provider, wrapping the main provider and its fallbacks. Updates are fetched from the
+// first provider answering successfully, while registration is only handled by the main provider.
+type failover struct {
+	state *state.State
+
+	providers []Provider
+}
+
+func (p *failover) ClearCache(ctx context.Context) error {
+	for _, provider := range p.providers {
+		err := provider.ClearCache(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *failover) RefreshRegister(ctx context.Context) error {
+	return p.providers[0].RefreshRegister(ctx)
+}
+
+func (p *failover) Register(ctx context.Context, isFirstBoot bool) error {
+	return p.providers[0].Register(ctx, isFirstBoot)
+}
+
+func (p *failover) Deregister(ctx context.Context) error {
+	return p.providers[0].Deregister(ctx)
+}
+
+func (p *failover) Type() string {
+	return p.providers[0].Type()
+}
+
+func (p *failover) GetSecureBootCertUpdate(ctx context.Context) (SecureBootCertUpdate, error) {
+	return tryProviders(ctx, p, func(provider Provider) (SecureBootCertUpdate, error) {
+		return provider.GetSecureBootCertUpdate(ctx)
+	})
+}
+
+func (p *failover) GetOSUpdate(ctx context.Context) (OSUpdate, error) {
+	return tryProviders(ctx, p, func(provider Provider) (OSUpdate, error) {
+		return provider.GetOSUpdate(ctx)
+	})
+}
+
+func (p *failover) GetApplicationUpdate(ctx context.Context, name string) (ApplicationUpdate, error) {
+	return tryProviders(ctx, p, func(provider Provider) (ApplicationUpdate, error) {
+		return provider.GetApplicationUpdate(ctx, name)
+	})
+}
+
+func (p *failover) GetChannelVersions(ctx context.Context) (map[string][]string, error) {
+	return tryProviders(ctx, p, func(provider Provider) (map[string][]string, error) {
+		return provider.GetChannelVersions(ctx)
+	})
+}
+
+func (*failover) load(_ context.Context) error {
+	return nil
+}
+
+func (p *failover) exportBundle(ctx context.Context, w io.Writer) error {
+	// Only fall back to the next provider if nothing was written yet.
+	cw := &countingWriter{w: w}
+
+	_, err := tryProviders(ctx, p, func(provider Provider) (bool, error) {
+		if cw.written > 0 {
+			return false, errors.New("export was interrupted")
+		}
+
+		exporter, ok := provider.(bundleExporter)
+		if !ok {
+			return false, fmt.Errorf("provider %q doesn't support exporting update bundles", provider.Type())
+		}
+
+		return true, exporter.exportBundle(ctx, cw)
+	})
+
+	return err
+}
+
+// tryProviders runs the request against each provider in turn, until one answers successfully, and
+// records the health of the providers tried. Reporting that no update is available is a valid answer.
+func tryProviders[T any](ctx context.Context, p *failover, request func(Provider) (T, error)) (T, error) {
+	errs := []error{}
+
+	for i, provider := range p.providers {
+		result, err := request(provider)
+		p.setHealth(i, provider, err)
+
+		if err == nil || errors.Is(err, ErrNoUpdateAvailable) {
+			return result, err
+		}
+
+		if i < len(p.providers)-1 {
+			slog.WarnContext(ctx, "Provider failed, trying the next one", "provider", provider.Type(), "err", err)
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Type(), err))
+	}
+
+	var empty T
+
+	return empty, fmt.Errorf("all providers failed: %w", errors.Join(errs...))
+}
+
+// setHealth records the result of the last request made to a provider.
+func (p *failover) setHealth(index int, provider Provider, err error) {
+	health := p.state.System.Provider.State.Health
+
+	if len(health) != len(p.providers) {
+		health = make([]api.SystemProviderHealth, len(p.providers))
+	}
+
+	health[index] = api.SystemProviderHealth{
+		Name:      provider.Type(),
+		Healthy:   err == nil || errors.Is(err, ErrNoUpdateAvailable),
+		LastCheck: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	if !health[index].Healthy {
+		health[index].LastError = err.Error()
+	}
+
+	p.state.System.Provider.State.Health = health
+}
+
+// countingWriter keeps track of the number of bytes written.
+type countingWriter struct {
+	w       io.Writer
+	written int64
+}
+
+func (c *countingWriter) Write(data []byte) (int, error) {
+	n, err := c.w.Write(data)
+	c.written += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,69 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+type fakeProvider struct {
+	local
+
+	name     string
+	versions map[string][]string
+	err      error
+}
+
+func (p *fakeProvider) Type() string {
+	return p.name
+}
+
+func (p *fakeProvider) GetChannelVersions(_ context.Context) (map[string][]string, error) {
+	return p.versions, p.err
+}
+
+func TestFailover(t *testing.T) {
+	t.Parallel()
+
+	s := &state.State{}
+	p := &failover{
+		state: s,
+		providers: []Provider{
+			&fakeProvider{name: "operations-center", err: errors.New("connection refused")},
+			&fakeProvider{name: "images", versions: map[string][]string{"stable": {"202510272025"}}},
+			&fakeProvider{name: "bundle", err: errors.New("unreachable")},
+		},
+	}
+
+	// The first provider answering successfully is used.
+	versions, err := p.GetChannelVersions(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, []string{"202510272025"}, versions["stable"])
+
+	require.Len(t, s.System.Provider.State.Health, 3)
+	require.False(t, s.System.Provider.State.Health[0].Healthy)
+	require.Equal(t, "connection refused", s.System.Provider.State.Health[0].LastError)
+	require.True(t, s.System.Provider.State.Health[1].Healthy)
+	require.Empty(t, s.System.Provider.State.Health[2].Name)
+
+	// No update being available is a valid answer.
+	p.providers[0].(*fakeProvider).err = ErrNoUpdateAvailable
+
+	_, err = p.GetChannelVersions(t.Context())
+	require.ErrorIs(t, err, ErrNoUpdateAvailable)
+	require.True(t, s.System.Provider.State.Health[0].Healthy)
+
+	// All providers failing.
+	p.providers = []Provider{p.providers[2]}
+
+	_, err = p.GetChannelVersions(t.Context())
+	require.ErrorContains(t, err, "unreachable")
+	require.Len(t, s.System.Provider.State.Health, 1)
+
+	// Registration is handled by the main provider.
+	require.ErrorIs(t, p.Register(t.Context(), false), ErrRegistrationUnsupported)
+}
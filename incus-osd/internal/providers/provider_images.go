@@ -6,8 +6,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"slices"
 	"strings"
@@ -15,20 +19,27 @@ import (
 
 	"github.com/lxc/incus/v6/shared/osarch"
 	"github.com/lxc/incus/v6/shared/subprocess"
+	"gopkg.in/yaml.v3"
 
 	apiupdate "github.com/lxc/incus-os/incus-osd/api/images"
+	"github.com/lxc/incus-os/incus-osd/internal/delta"
 	"github.com/lxc/incus-os/incus-osd/internal/state"
 )
 
 // The images provider.
 type images struct {
-	state *state.State
+	state  *state.State
+	config map[string]string
 
+	client    *http.Client
 	serverURL string
+	mirrorURL string
 	updateCA  string
 
-	lastCheck    time.Time // In system's timezone.
-	latestUpdate *apiupdate.UpdateFull
+	lastCheck       time.Time // In system's timezone.
+	latestUpdate    *apiupdate.UpdateFull
+	updates         []apiupdate.UpdateFull
+	channelVersions map[string][]string
 }
 
 func (p *images) ClearCache(_ context.Context) error {
@@ -121,6 +132,13 @@ func (p *images) GetOSUpdate(ctx context.Context) (OSUpdate, error) {
 func (p *images) GetApplicationUpdate(ctx context.Context, name string) (ApplicationUpdate, error) {
 	// Get latest release.
 	latestUpdate, err := p.checkRelease(ctx)
+
+	// Follow the application's own update policy, if any.
+	appConfig := p.state.Applications[name].Config.Update
+	if appConfig != nil && (appConfig.Channel != "" || appConfig.PinnedVersion != "") && (err == nil || errors.Is(err, ErrNoUpdateAvailable)) {
+		latestUpdate, err = p.getApplicationRelease(name, appConfig.Channel, appConfig.PinnedVersion)
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -150,10 +168,43 @@ func (p *images) GetApplicationUpdate(ctx context.Context, name string) (Applica
 	return &app, nil
 }
 
+// getApplicationRelease returns the latest release including the application in the given channel,
+// or the release of the given version.
+func (p *images) getApplicationRelease(name string, channel string, version string) (*apiupdate.UpdateFull, error) {
+	for _, update := range p.updates {
+		if version != "" && update.Version != version {
+			continue
+		}
+
+		if version == "" && !slices.Contains(update.Channels, channel) {
+			continue
+		}
+
+		for _, file := range update.Files {
+			if string(file.Component) == name {
+				return &update, nil
+			}
+		}
+	}
+
+	return nil, ErrNoUpdateAvailable
+}
+
+func (p *images) GetChannelVersions(ctx context.Context) (map[string][]string, error) {
+	_, err := p.checkRelease(ctx)
+	if err != nil && !errors.Is(err, ErrNoUpdateAvailable) {
+		return nil, err
+	}
+
+	return p.channelVersions, nil
+}
+
 func (p *images) load(_ context.Context) error {
 	// Set up the configuration.
-	p.serverURL = p.state.System.Provider.Config.Config["server_url"]
-	p.updateCA = p.state.System.Provider.Config.Config["update_ca"]
+	p.serverURL = p.config["server_url"]
+	p.updateCA = p.config["update_ca"]
+
+	p.mirrorURL = strings.TrimSuffix(p.config["mirror_url"], "/")
 
 	// Basic validation.
 	if p.serverURL == "" {
@@ -161,75 +212,170 @@ func (p *images) load(_ context.Context) error {
 		p.updateCA = LXCUpdateCA
 	}
 
+	if p.mirrorURL != "" {
+		u, err := url.Parse(p.mirrorURL)
+		if err != nil {
+			return err
+		}
+
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("invalid mirror URL %q", p.mirrorURL)
+		}
+	}
+
+	// Apply the CA and certificate pinning configuration.
+	client, err := getHTTPClient(p.config)
+	if err != nil {
+		return err
+	}
+
+	p.client = client
+
 	return nil
 }
 
-func (p *images) checkRelease(ctx context.Context) (*apiupdate.UpdateFull, error) {
-	// Only talk to image server once an hour.
-	if p.latestUpdate != nil && !p.lastCheck.IsZero() && p.lastCheck.Add(time.Hour).After(time.Now()) {
-		return p.latestUpdate, nil
+// getBaseURLs returns the base URLs to download update files from, trying the mirror first.
+func (p *images) getBaseURLs() []string {
+	if p.mirrorURL == "" {
+		return []string{p.serverURL}
 	}
 
-	// Get local architecture.
-	archName, err := osarch.ArchitectureGetLocal()
-	if err != nil {
-		return nil, err
+	return []string{p.mirrorURL, p.serverURL}
+}
+
+// getIndex fetches the signed index from the server, falling back to the mirror should that fail.
+// As the index is signed, it can safely be served by the mirror.
+func (p *images) getIndex(ctx context.Context) (*http.Response, error) {
+	var err error
+
+	for _, baseURL := range []string{p.serverURL, p.mirrorURL} {
+		if baseURL == "" {
+			continue
+		}
+
+		var req *http.Request
+
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/index.sjson", nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var resp *http.Response
+
+		resp, err = tryRequest(p.client, req)
+		if err != nil {
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			_ = resp.Body.Close()
+			err = errors.New("server failed to return expected file")
+
+			continue
+		}
+
+		return resp, nil
 	}
 
-	// Get the latest signed index.
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.serverURL+"/index.sjson", nil)
-	if err != nil {
-		return nil, err
+	return nil, err
+}
+
+// downloadFile downloads an update file, from the mirror first if configured. When a chunk index is
+// published for it, only the chunks missing from the seed files are downloaded, falling back to a full
+// download should that fail.
+func (p *images) downloadFile(ctx context.Context, update *apiupdate.UpdateFull, file apiupdate.UpdateFile, seeds []string, target string, progressFunc func(float64)) error {
+	indexFile := getChunkIndexFile(update, file)
+
+	var err error
+
+	for _, baseURL := range p.getBaseURLs() {
+		if indexFile != nil && (len(seeds) > 0 || len(getPeerStoreURLs(p.state)) > 0) {
+			downloaded, deltaErr := p.downloadDelta(ctx, baseURL, update, *indexFile, seeds, target, progressFunc)
+			if deltaErr == nil {
+				slog.InfoContext(ctx, "Downloaded delta update", "file", file.Filename, "downloaded", downloaded)
+
+				return nil
+			}
+
+			slog.WarnContext(ctx, "Failed to download delta update, falling back to full download", "file", file.Filename, "url", baseURL, "err", deltaErr)
+		}
+
+		fileURL := baseURL + "/" + update.Version + "/" + file.Filename
+
+		err = downloadAsset(ctx, p.client, fileURL, file.Sha256, target, progressFunc)
+		if err == nil {
+			return nil
+		}
+
+		err = fmt.Errorf("while downloading %s, got error '%s'", fileURL, err.Error())
+
+		if baseURL == p.mirrorURL {
+			slog.WarnContext(ctx, "Failed to download from mirror, falling back to server", "file", file.Filename, "err", err)
+		}
 	}
 
-	resp, err := tryRequest(http.DefaultClient, req)
+	return err
+}
+
+// downloadDelta reconstructs an update file from its chunk index, returning the number of bytes downloaded.
+func (p *images) downloadDelta(ctx context.Context, baseURL string, update *apiupdate.UpdateFull, indexFile apiupdate.UpdateFile, seeds []string, target string, progressFunc func(float64)) (int64, error) {
+	// Get the chunk index, validated against the signed update metadata.
+	indexPath := target + ".chunks"
+
+	err := downloadAsset(ctx, p.client, baseURL+"/"+update.Version+"/"+indexFile.Filename, indexFile.Sha256, indexPath, nil)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New("server failed to return expected file")
-	}
+	defer func() { _ = os.Remove(indexPath) }()
 
-	// Write the CA certificate.
-	rootCA, err := os.CreateTemp("", "")
+	content, err := os.ReadFile(indexPath) //nolint:gosec
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	_, err = fmt.Fprintf(rootCA, "%s", p.updateCA)
+	index := &apiupdate.ChunkIndex{}
+
+	err = json.Unmarshal(content, index)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	defer func() { _ = os.Remove(rootCA.Name()) }()
+	// Fetch the chunks from peers first, falling back to the chunk store.
+	return delta.Apply(ctx, p.client, index, append(getPeerStoreURLs(p.state), baseURL+"/chunks"), seeds, target, progressFunc)
+}
 
-	// Validate signed index.
-	verified := bytes.NewBuffer(nil)
+func (p *images) checkRelease(ctx context.Context) (*apiupdate.UpdateFull, error) {
+	// Only talk to image server once an hour.
+	if p.latestUpdate != nil && !p.lastCheck.IsZero() && p.lastCheck.Add(time.Hour).After(time.Now()) {
+		return p.latestUpdate, nil
+	}
 
-	err = subprocess.RunCommandWithFds(ctx, resp.Body, verified, "openssl", "smime", "-verify", "-text", "-CAfile", rootCA.Name())
+	// Get local architecture.
+	archName, err := osarch.ArchitectureGetLocal()
 	if err != nil {
 		return nil, err
 	}
 
-	// Parse the update list.
-	index := &apiupdate.Index{}
+	// Get the latest signed index.
+	resp, err := p.getIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
 
-	err = json.NewDecoder(bytes.NewReader(verified.Bytes())).Decode(index)
+	index, err := p.verifyIndex(ctx, resp.Body)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get the latest update for the expected channel.
+	// Get the latest update for the expected channel, and the versions available in each channel.
 	var latestUpdate *apiupdate.UpdateFull
 
-	for _, update := range index.Updates {
-		// Skip any update targeting the wrong channel(s).
-		if update.Version != p.state.OS.RunningRelease && p.state.System.Update.Config.Channel != "" && !slices.Contains(update.Channels, p.state.System.Update.Config.Channel) {
-			continue
-		}
+	updates := []apiupdate.UpdateFull{}
+	channelVersions := map[string][]string{}
 
+	for _, update := range index.Updates {
 		// Skip any update with no files.
 		if len(update.Files) == 0 {
 			continue
@@ -253,11 +399,23 @@ func (p *images) checkRelease(ctx context.Context) (*apiupdate.UpdateFull, error
 			continue
 		}
 
-		latestUpdate = &update
+		for _, channel := range update.Channels {
+			channelVersions[channel] = append(channelVersions[channel], update.Version)
+		}
 
-		break
+		updates = append(updates, update)
+
+		// Skip any update targeting the wrong channel(s).
+		if latestUpdate != nil || (update.Version != p.state.OS.RunningRelease && p.state.System.Update.Config.Channel != "" && !slices.Contains(update.Channels, p.state.System.Update.Config.Channel)) {
+			continue
+		}
+
+		latestUpdate = &update
 	}
 
+	p.updates = updates
+	p.channelVersions = channelVersions
+
 	if latestUpdate == nil {
 		return nil, ErrNoUpdateAvailable
 	}
@@ -269,6 +427,40 @@ func (p *images) checkRelease(ctx context.Context) (*apiupdate.UpdateFull, error
 	return latestUpdate, nil
 }
 
+// verifyIndex validates the signature of a signed index and returns its content.
+func (p *images) verifyIndex(ctx context.Context, signedIndex io.Reader) (*apiupdate.Index, error) {
+	// Write the CA certificate.
+	rootCA, err := os.CreateTemp("", "")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = fmt.Fprintf(rootCA, "%s", GetUpdateCA(p.state, p.updateCA))
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = os.Remove(rootCA.Name()) }()
+
+	// Validate signed index.
+	verified := bytes.NewBuffer(nil)
+
+	err = subprocess.RunCommandWithFds(ctx, signedIndex, verified, "openssl", "smime", "-verify", "-text", "-CAfile", rootCA.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the update list.
+	index := &apiupdate.Index{}
+
+	err = json.NewDecoder(bytes.NewReader(verified.Bytes())).Decode(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return index, nil
+}
+
 // An application from the images provider.
 type imagesApplication struct {
 	provider *images
@@ -302,13 +494,12 @@ func (a *imagesApplication) Download(ctx context.Context, targetPath string, pro
 			continue
 		}
 
-		fileURL := a.provider.serverURL + "/" + a.latestUpdate.Version + "/" + file.Filename
 		targetName := strings.TrimSuffix(filepath.Base(file.Filename), ".gz")
 
-		// Download the application.
-		err = downloadAsset(ctx, http.DefaultClient, fileURL, file.Sha256, filepath.Join(targetPath, targetName), progressFunc)
+		// Download the application, using the currently installed version as the delta seed.
+		err = a.provider.downloadFile(ctx, a.latestUpdate, file, []string{filepath.Join(targetPath, targetName)}, filepath.Join(targetPath, targetName), progressFunc)
 		if err != nil {
-			return fmt.Errorf("while downloading %s, got error '%s'", fileURL, err.Error())
+			return err
 		}
 	}
 
@@ -343,20 +534,43 @@ func (o *imagesOSUpdate) Download(ctx context.Context, targetPath string, progre
 		return err
 	}
 
+	// Only select OS updates.
+	files := []apiupdate.UpdateFile{}
+
 	for _, file := range o.latestUpdate.Files {
-		// Only select OS updates.
 		if file.Component != apiupdate.UpdateFileComponentOS || !slices.Contains([]apiupdate.UpdateFileType{apiupdate.UpdateFileTypeUpdateEFI, apiupdate.UpdateFileTypeUpdateUsr, apiupdate.UpdateFileTypeUpdateUsrVerity, apiupdate.UpdateFileTypeUpdateUsrVeritySignature}, file.Type) {
 			continue
 		}
 
-		fileURL := o.provider.serverURL + "/" + o.latestUpdate.Version + "/" + file.Filename
+		files = append(files, file)
+	}
+
+	// Report the progress across all the files, weighted by their size.
+	var totalSize int64
+	for _, file := range files {
+		totalSize += file.Size
+	}
+
+	var doneSize int64
+
+	for _, file := range files {
 		targetName := strings.TrimSuffix(filepath.Base(file.Filename), ".gz")
+		seeds := getOSDeltaSeeds(o.provider.state.OS.Name, o.provider.state.OS.RunningRelease, file.Type)
+
+		fileProgressFunc := progressFunc
+		if progressFunc != nil && totalSize > 0 {
+			fileProgressFunc = func(progress float64) {
+				progressFunc((float64(doneSize) + progress*float64(file.Size)) / float64(totalSize))
+			}
+		}
 
-		// Download the application.
-		err = downloadAsset(ctx, http.DefaultClient, fileURL, file.Sha256, filepath.Join(targetPath, targetName), progressFunc)
+		// Download the update.
+		err = o.provider.downloadFile(ctx, o.latestUpdate, file, seeds, filepath.Join(targetPath, targetName), fileProgressFunc)
 		if err != nil {
-			return fmt.Errorf("while downloading %s, got error '%s'", fileURL, err.Error())
+			return err
 		}
+
+		doneSize += file.Size
 	}
 
 	return nil
@@ -375,11 +589,10 @@ func (o *imagesOSUpdate) DownloadImage(ctx context.Context, imageType string, ta
 			continue
 		}
 
-		fileURL := o.provider.serverURL + "/" + o.latestUpdate.Version + "/" + file.Filename
 		targetName := strings.TrimSuffix(filepath.Base(file.Filename), ".gz")
 
-		// Download the application.
-		err = downloadAsset(ctx, http.DefaultClient, fileURL, file.Sha256, filepath.Join(targetPath, targetName), progressFunc)
+		// Download the image.
+		err = o.provider.downloadFile(ctx, o.latestUpdate, file, nil, filepath.Join(targetPath, targetName), progressFunc)
 
 		return targetName, err
 	}
@@ -387,6 +600,50 @@ func (o *imagesOSUpdate) DownloadImage(ctx context.Context, imageType string, ta
 	return "", fmt.Errorf("failed to download image type '%s' for release %s", imageType, o.latestUpdate.Version)
 }
 
+func (o *imagesOSUpdate) GetReleaseNotes(ctx context.Context) (string, error) {
+	// Get the changelog published for the followed channel.
+	channel := o.provider.state.System.Update.Config.Channel
+	if channel == "" {
+		channel = "stable"
+	}
+
+	for _, file := range o.latestUpdate.Files {
+		if file.Type != apiupdate.UpdateFileTypeChangelog || path.Base(file.Filename) != "changelog-"+channel+".yaml.gz" {
+			continue
+		}
+
+		target, err := os.CreateTemp("", "changelog")
+		if err != nil {
+			return "", err
+		}
+
+		_ = target.Close()
+
+		defer func() { _ = os.Remove(target.Name()) }()
+
+		err = o.provider.downloadFile(ctx, o.latestUpdate, file, nil, target.Name(), nil)
+		if err != nil {
+			return "", err
+		}
+
+		content, err := os.ReadFile(target.Name())
+		if err != nil {
+			return "", err
+		}
+
+		changelog := apiupdate.Changelog{}
+
+		err = yaml.Unmarshal(content, &changelog)
+		if err != nil {
+			return "", err
+		}
+
+		return formatChangelog(&changelog), nil
+	}
+
+	return "", nil
+}
+
 // Secure Boot key updates from the images provider.
 type imagesSecureBootCertUpdate struct {
 	provider *images
@@ -426,12 +683,10 @@ func (o *imagesSecureBootCertUpdate) Download(ctx context.Context, targetPath st
 			continue
 		}
 
-		fileURL := o.provider.serverURL + "/" + o.latestUpdate.Version + "/" + file.Filename
-
-		// Download the application.
-		err = downloadAsset(ctx, http.DefaultClient, fileURL, file.Sha256, filepath.Join(targetPath, o.GetFilename()), nil)
+		// Download the Secure Boot update.
+		err = o.provider.downloadFile(ctx, o.latestUpdate, file, nil, filepath.Join(targetPath, o.GetFilename()), nil)
 		if err != nil {
-			return fmt.Errorf("while downloading %s, got error '%s'", fileURL, err.Error())
+			return err
 		}
 	}
 
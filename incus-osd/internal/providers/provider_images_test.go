@@ -0,0 +1,39 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apiupdate "github.com/lxc/incus-os/incus-osd/api/images"
+)
+
+func TestImagesApplicationRelease(t *testing.T) {
+	t.Parallel()
+
+	p := &images{updates: []apiupdate.UpdateFull{
+		{Update: apiupdate.Update{Version: "202511060312", Channels: []string{"testing"}, Files: []apiupdate.UpdateFile{{Component: apiupdate.UpdateFileComponentOS}, {Component: apiupdate.UpdateFileComponentIncus}}}},
+		{Update: apiupdate.Update{Version: "202511040120", Channels: []string{"stable", "testing"}, Files: []apiupdate.UpdateFile{{Component: apiupdate.UpdateFileComponentOS}}}},
+		{Update: apiupdate.Update{Version: "202510280120", Channels: []string{"stable", "testing"}, Files: []apiupdate.UpdateFile{{Component: apiupdate.UpdateFileComponentOS}, {Component: apiupdate.UpdateFileComponentIncus}}}},
+	}}
+
+	// Latest release of the channel including the application.
+	update, err := p.getApplicationRelease("incus", "testing", "")
+	require.NoError(t, err)
+	require.Equal(t, "202511060312", update.Version)
+
+	update, err = p.getApplicationRelease("incus", "stable", "")
+	require.NoError(t, err)
+	require.Equal(t, "202510280120", update.Version)
+
+	// Pinned version.
+	update, err = p.getApplicationRelease("incus", "", "202510280120")
+	require.NoError(t, err)
+	require.Equal(t, "202510280120", update.Version)
+
+	_, err = p.getApplicationRelease("incus", "", "202511040120")
+	require.ErrorIs(t, err, ErrNoUpdateAvailable)
+
+	_, err = p.getApplicationRelease("incus", "candidate", "")
+	require.ErrorIs(t, err, ErrNoUpdateAvailable)
+}
@@ -146,6 +146,11 @@ func (p *local) GetApplicationUpdate(ctx context.Context, name string) (Applicat
 	return &app, nil
 }
 
+func (*local) GetChannelVersions(_ context.Context) (map[string][]string, error) {
+	// The local provider has no channels.
+	return map[string][]string{}, nil
+}
+
 func (p *local) load(_ context.Context) error {
 	// Use a hardcoded path for now.
 	p.path = "/root/updates/"
@@ -354,6 +359,11 @@ func (*localOSUpdate) DownloadImage(_ context.Context, _ string, _ string, _ fun
 	return "", errors.New("downloading full image not supported by local provider")
 }
 
+func (*localOSUpdate) GetReleaseNotes(_ context.Context) (string, error) {
+	// No release notes for local (development) builds.
+	return "", nil
+}
+
 // Secure Boot key updates from the Local provider.
 type localSecureBootCertUpdate struct {
 	provider *local
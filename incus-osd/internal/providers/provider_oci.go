@@ -0,0 +1,333 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// ociIndexTag is the tag of the artifact holding the signed index.
+const ociIndexTag = "index"
+
+// ociTitleAnnotation is the layer annotation holding the name of the file stored in the layer.
+const ociTitleAnnotation = "org.opencontainers.image.title"
+
+// ociManifestTypes are the manifest media types accepted from the registry.
+var ociManifestTypes = []string{"application/vnd.oci.image.manifest.v1+json", "application/vnd.docker.distribution.manifest.v2+json"}
+
+// The OCI provider, fetching updates from an OCI registry. The registry holds an artifact tagged
+// "index" for the signed index, along with an artifact tagged with each release's version. Each
+// file is stored as a layer, named through its title annotation, allowing for the images
+// provider logic to be reused.
+type oci struct {
+	images
+}
+
+// ociManifest is the subset of an OCI image manifest needed to locate the files of an artifact.
+type ociManifest struct {
+	Layers []ociDescriptor `json:"layers"`
+}
+
+// ociDescriptor is an OCI content descriptor.
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"` //nolint:tagliatelle
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+func (*oci) Type() string {
+	return "oci"
+}
+
+func (p *oci) load(_ context.Context) error {
+	// Set up the configuration.
+	repository := p.config["repository"]
+	p.updateCA = p.config["update_ca"]
+
+	// Basic validation.
+	registry, name, ok := strings.Cut(repository, "/")
+	if !ok || registry == "" || name == "" {
+		return fmt.Errorf("invalid OCI repository %q", repository)
+	}
+
+	if p.updateCA == "" {
+		p.updateCA = LXCUpdateCA
+	}
+
+	// Apply the CA and certificate pinning configuration.
+	client, err := getHTTPClient(p.config)
+	if err != nil {
+		return err
+	}
+
+	// Translate the requests for the images server layout into registry requests.
+	transport := &ociTransport{
+		registry:   registry,
+		repository: name,
+		username:   p.config["username"],
+		password:   p.config["password"],
+
+		client:    client,
+		manifests: map[string]*ociManifest{},
+	}
+
+	p.serverURL = "https://" + registry + "/" + name
+	p.client = &http.Client{Transport: transport}
+
+	return nil
+}
+
+// ociTransport serves the files of the images server layout from an OCI registry, passing any other
+// request through.
+type ociTransport struct {
+	registry   string
+	repository string
+	username   string
+	password   string
+
+	client *http.Client
+
+	mu            sync.Mutex
+	manifests     map[string]*ociManifest
+	authorization string
+}
+
+// RoundTrip resolves the requested file to a blob of the registry and fetches it.
+func (t *ociTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Pass through requests not targeting the repository, like those to peers.
+	prefix := "/" + t.repository + "/"
+	if req.URL.Host != t.registry || !strings.HasPrefix(req.URL.Path, prefix) {
+		return http.DefaultTransport.RoundTrip(req)
+	}
+
+	// Map the path to the tag of the artifact and the name of the file.
+	tag, fileName, ok := strings.Cut(strings.TrimPrefix(req.URL.Path, prefix), "/")
+	if !ok {
+		tag = ociIndexTag
+		fileName = strings.TrimPrefix(req.URL.Path, prefix)
+	}
+
+	manifest, err := t.getManifest(req.Context(), tag)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, layer := range manifest.Layers {
+		if layer.Annotations[ociTitleAnnotation] != fileName {
+			continue
+		}
+
+		return t.do(req.Context(), "/blobs/"+layer.Digest, nil)
+	}
+
+	return &http.Response{
+		Status:     http.StatusText(http.StatusNotFound),
+		StatusCode: http.StatusNotFound,
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+// getManifest returns the manifest of an artifact. Only the release manifests are cached, as the index
+// artifact is replaced with each new release.
+func (t *ociTransport) getManifest(ctx context.Context, tag string) (*ociManifest, error) {
+	t.mu.Lock()
+	manifest, ok := t.manifests[tag]
+	t.mu.Unlock()
+
+	if ok {
+		return manifest, nil
+	}
+
+	resp, err := t.do(ctx, "/manifests/"+tag, map[string]string{"Accept": strings.Join(ociManifestTypes, ", ")})
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get manifest %q: %s", tag, resp.Status)
+	}
+
+	manifest = &ociManifest{}
+
+	err = json.NewDecoder(resp.Body).Decode(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	if tag != ociIndexTag {
+		t.mu.Lock()
+		t.manifests[tag] = manifest
+		t.mu.Unlock()
+	}
+
+	return manifest, nil
+}
+
+// do makes a request to the registry API of the repository, authenticating as requested by the registry.
+func (t *ociTransport) do(ctx context.Context, path string, headers map[string]string) (*http.Response, error) {
+	var resp *http.Response
+
+	for attempt := range 2 {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+t.registry+"/v2/"+t.repository+path, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+
+		t.mu.Lock()
+		authorization := t.authorization
+		t.mu.Unlock()
+
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+
+		resp, err = tryRequest(t.client, req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusUnauthorized || attempt > 0 {
+			break
+		}
+
+		// Authenticate following the registry's challenge and try again.
+		challenge := resp.Header.Get("WWW-Authenticate")
+		_ = resp.Body.Close()
+
+		authorization, err = t.authenticate(ctx, challenge)
+		if err != nil {
+			return nil, err
+		}
+
+		t.mu.Lock()
+		t.authorization = authorization
+		t.mu.Unlock()
+	}
+
+	return resp, nil
+}
+
+// authenticate returns the authorization header answering a registry challenge.
+func (t *ociTransport) authenticate(ctx context.Context, challenge string) (string, error) {
+	scheme, params := parseAuthChallenge(challenge)
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if t.username == "" {
+			return "", errors.New("registry requires credentials")
+		}
+
+		req := &http.Request{Header: http.Header{}}
+		req.SetBasicAuth(t.username, t.password)
+
+		return req.Header.Get("Authorization"), nil
+	case "bearer":
+		if params["realm"] == "" {
+			return "", errors.New("registry didn't provide an authentication realm")
+		}
+
+		// Request a pull token from the registry's token server.
+		tokenURL, err := url.Parse(params["realm"])
+		if err != nil {
+			return "", err
+		}
+
+		values := tokenURL.Query()
+
+		if params["service"] != "" {
+			values.Set("service", params["service"])
+		}
+
+		scope := params["scope"]
+		if scope == "" {
+			scope = "repository:" + t.repository + ":pull"
+		}
+
+		values.Set("scope", scope)
+		tokenURL.RawQuery = values.Encode()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+		if err != nil {
+			return "", err
+		}
+
+		if t.username != "" {
+			req.SetBasicAuth(t.username, t.password)
+		}
+
+		resp, err := tryRequest(t.client, req)
+		if err != nil {
+			return "", err
+		}
+
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("failed to get registry token: %s", resp.Status)
+		}
+
+		token := struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}{}
+
+		err = json.NewDecoder(resp.Body).Decode(&token)
+		if err != nil {
+			return "", err
+		}
+
+		if token.Token == "" {
+			token.Token = token.AccessToken
+		}
+
+		if token.Token == "" {
+			return "", errors.New("registry returned an empty token")
+		}
+
+		return "Bearer " + token.Token, nil
+	default:
+		return "", fmt.Errorf("unsupported registry authentication scheme %q", scheme)
+	}
+}
+
+// parseAuthChallenge parses a WWW-Authenticate header into its scheme and parameters.
+func parseAuthChallenge(challenge string) (string, map[string]string) {
+	params := map[string]string{}
+
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+
+	for rest != "" {
+		var key string
+
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, " ,"), "=")
+		if key == "" {
+			break
+		}
+
+		var value string
+
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+
+		params[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+
+	return scheme, params
+}
@@ -0,0 +1,127 @@
+package providers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAuthChallenge(t *testing.T) {
+	t.Parallel()
+
+	scheme, params := parseAuthChallenge(`Bearer realm="https://auth.example.net/token",service="registry.example.net",scope="repository:incus-os:pull"`)
+	require.Equal(t, "Bearer", scheme)
+	require.Equal(t, map[string]string{"realm": "https://auth.example.net/token", "service": "registry.example.net", "scope": "repository:incus-os:pull"}, params)
+
+	scheme, params = parseAuthChallenge(`Basic realm=registry`)
+	require.Equal(t, "Basic", scheme)
+	require.Equal(t, map[string]string{"realm": "registry"}, params)
+}
+
+func TestOCITransport(t *testing.T) {
+	t.Parallel()
+
+	manifests := map[string]ociManifest{
+		"index": {Layers: []ociDescriptor{{Digest: "sha256:index", Annotations: map[string]string{ociTitleAnnotation: "index.sjson"}}}},
+		"202501010000": {Layers: []ociDescriptor{
+			{Digest: "sha256:efi", Annotations: map[string]string{ociTitleAnnotation: "IncusOS_202501010000.efi.gz"}},
+			{Digest: "sha256:usr", Annotations: map[string]string{ociTitleAnnotation: "IncusOS_202501010000.usr-x86-64.raw.gz"}},
+		}},
+	}
+
+	blobs := map[string]string{"sha256:index": "signed index", "sha256:efi": "efi content", "sha256:usr": "usr content"}
+
+	var server *httptest.Server
+
+	router := http.NewServeMux()
+	router.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "user" || password != "secret" || r.URL.Query().Get("scope") != "repository:incus-os:pull" {
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": "pull-token"})
+	})
+
+	router.HandleFunc("/v2/incus-os/{kind}/{ref}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer pull-token" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		switch r.PathValue("kind") {
+		case "manifests":
+			manifest, ok := manifests[r.PathValue("ref")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			_ = json.NewEncoder(w).Encode(manifest)
+		case "blobs":
+			blob, ok := blobs[r.PathValue("ref")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(blob))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	server = httptest.NewTLSServer(router)
+	defer server.Close()
+
+	registry := server.Listener.Addr().String()
+
+	client := &http.Client{Transport: &ociTransport{
+		registry:   registry,
+		repository: "incus-os",
+		username:   "user",
+		password:   "secret",
+
+		client:    server.Client(),
+		manifests: map[string]*ociManifest{},
+	}}
+
+	get := func(path string) (int, string) {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "https://"+registry+"/incus-os/"+path, nil)
+		require.NoError(t, err)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		content, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp.StatusCode, string(content)
+	}
+
+	status, content := get("index.sjson")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "signed index", content)
+
+	status, content = get("202501010000/IncusOS_202501010000.usr-x86-64.raw.gz")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "usr content", content)
+
+	status, _ = get("202501010000/missing.gz")
+	require.Equal(t, http.StatusNotFound, status)
+
+	_, err := client.Get("https://" + registry + "/incus-os/202601010000/IncusOS_202601010000.efi.gz") //nolint:noctx
+	require.Error(t, err)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
 	"encoding/pem"
 	"errors"
@@ -24,6 +25,7 @@ import (
 	"github.com/lxc/incus/v6/shared/osarch"
 	incustls "github.com/lxc/incus/v6/shared/tls"
 
+	incusosapi "github.com/lxc/incus-os/incus-osd/api"
 	apiupdate "github.com/lxc/incus-os/incus-osd/api/images"
 	"github.com/lxc/incus-os/incus-osd/internal/applications"
 	"github.com/lxc/incus-os/incus-osd/internal/state"
@@ -31,9 +33,10 @@ import (
 
 // API structs.
 type operationsCenterUpdate struct {
-	Channels []string `json:"channels"`
-	UUID     string   `json:"uuid"`
-	Version  string   `json:"version"`
+	Channels  []string `json:"channels"`
+	UUID      string   `json:"uuid"`
+	Version   string   `json:"version"`
+	Changelog string   `json:"changelog"`
 
 	Files []operationsCenterUpdateFile
 }
@@ -49,6 +52,14 @@ type operationsCenterUpdateFile struct {
 	url string
 }
 
+// operationsCenterJoinToken is the decoded form of a join token, providing everything needed to
+// register with Operations Center.
+type operationsCenterJoinToken struct {
+	ServerURL   string `json:"server_url"`
+	Token       string `json:"token"`
+	Fingerprint string `json:"fingerprint"`
+}
+
 // The Operations Center provider.
 type operationsCenter struct {
 	state *state.State
@@ -59,9 +70,10 @@ type operationsCenter struct {
 	serverURL         string
 	serverToken       string
 
-	lastCheck    time.Time // In system's timezone.
-	latestUpdate *operationsCenterUpdate
-	releaseMu    sync.Mutex
+	lastCheck       time.Time // In system's timezone.
+	latestUpdate    *operationsCenterUpdate
+	channelVersions map[string][]string
+	releaseMu       sync.Mutex
 }
 
 func (p *operationsCenter) ClearCache(_ context.Context) error {
@@ -165,7 +177,7 @@ func (p *operationsCenter) Register(ctx context.Context, _ bool) error {
 
 	// Get the server certificate.
 	if registrationResp.Certificate != "" {
-		err = app.AddTrustedCertificate(ctx, p.serverURL, registrationResp.Certificate)
+		err = app.AddTrustedCertificate(ctx, &incusosapi.SystemSecurityTrustedCertificate{Name: p.serverURL, Certificate: registrationResp.Certificate})
 		if err != nil {
 			return err
 		}
@@ -276,6 +288,18 @@ func (p *operationsCenter) GetApplicationUpdate(ctx context.Context, name string
 	return &app, nil
 }
 
+func (p *operationsCenter) GetChannelVersions(ctx context.Context) (map[string][]string, error) {
+	_, err := p.checkRelease(ctx)
+	if err != nil && !errors.Is(err, ErrNoUpdateAvailable) {
+		return nil, err
+	}
+
+	p.releaseMu.Lock()
+	defer p.releaseMu.Unlock()
+
+	return p.channelVersions, nil
+}
+
 func (p *operationsCenter) load(ctx context.Context) error {
 	p.client = &http.Client{}
 
@@ -305,6 +329,14 @@ func (p *operationsCenter) load(ctx context.Context) error {
 		return nil
 	}
 
+	// Handle join tokens.
+	if p.state.System.Provider.Config.Config["join_token"] != "" {
+		err = p.applyJoinToken(ctx, p.state.System.Provider.Config.Config["join_token"])
+		if err != nil {
+			return fmt.Errorf("invalid operations center join token: %w", err)
+		}
+	}
+
 	// Basic validation.
 	if p.serverURL == "" {
 		return errors.New("no operations center URL provided")
@@ -318,6 +350,103 @@ func (p *operationsCenter) load(ctx context.Context) error {
 	return p.loadTLS(ctx)
 }
 
+// applyJoinToken sets up the provider configuration from a join token, retrieving the server
+// certificate matching the fingerprint included in the token.
+func (p *operationsCenter) applyJoinToken(ctx context.Context, joinToken string) error {
+	// Decode the token.
+	data, err := base64.StdEncoding.DecodeString(joinToken)
+	if err != nil {
+		return err
+	}
+
+	token := operationsCenterJoinToken{}
+
+	err = json.Unmarshal(data, &token)
+	if err != nil {
+		return err
+	}
+
+	if token.ServerURL == "" || token.Token == "" {
+		return errors.New("missing server URL or token")
+	}
+
+	// Retrieve the server certificate.
+	certificate := ""
+
+	if token.Fingerprint != "" {
+		certificate, err = getServerCertificate(ctx, token.ServerURL, token.Fingerprint)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Replace the token by the resulting configuration.
+	p.serverURL = token.ServerURL
+	p.serverToken = token.Token
+	p.serverCertificate = certificate
+
+	p.state.System.Provider.Config.Config["server_url"] = token.ServerURL
+	p.state.System.Provider.Config.Config["server_token"] = token.Token
+
+	if certificate != "" {
+		p.state.System.Provider.Config.Config["server_certificate"] = certificate
+	} else {
+		delete(p.state.System.Provider.Config.Config, "server_certificate")
+	}
+
+	delete(p.state.System.Provider.Config.Config, "join_token")
+
+	return nil
+}
+
+// getServerCertificate retrieves the certificate of a server, checking that it matches the expected fingerprint.
+func getServerCertificate(ctx context.Context, serverURL string, fingerprint string) (string, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return "", err
+	}
+
+	if u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported server URL %q", serverURL)
+	}
+
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "443")
+	}
+
+	// The certificate is validated through its fingerprint below.
+	dialer := &tls.Dialer{
+		Config: &tls.Config{
+			InsecureSkipVerify: true, //nolint:gosec
+			MinVersion:         tls.VersionTLS13,
+		},
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return "", err
+	}
+
+	defer conn.Close()
+
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		return "", errors.New("unexpected connection type")
+	}
+
+	peerCerts := tlsConn.ConnectionState().PeerCertificates
+	if len(peerCerts) == 0 {
+		return "", errors.New("no certificate provided by the server")
+	}
+
+	if !strings.EqualFold(incustls.CertFingerprint(peerCerts[0]), fingerprint) {
+		return "", errors.New("server certificate doesn't match the token fingerprint")
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: peerCerts[0].Raw})), nil
+}
+
 func (p *operationsCenter) loadTLS(ctx context.Context) error {
 	// Skip for local connections.
 	if p.serverURL == "" {
@@ -329,6 +458,12 @@ func (p *operationsCenter) loadTLS(ctx context.Context) error {
 		MinVersion: tls.VersionTLS13,
 	}
 
+	// Apply the CA and certificate pinning configuration.
+	err := configureTLS(tlsConfig, p.state.System.Provider.Config.Config)
+	if err != nil {
+		return err
+	}
+
 	// Setup the server for self-signed certirficates.
 	if p.serverCertificate != "" {
 		// Parse the provided certificate.
@@ -347,7 +482,7 @@ func (p *operationsCenter) loadTLS(ctx context.Context) error {
 	}
 
 	// Set the client certificate (if present).
-	err := p.configureClientCertificate(ctx, tlsConfig)
+	err = p.configureClientCertificate(ctx, tlsConfig)
 	if err != nil {
 		return fmt.Errorf("failed to set client certificate: %w", err)
 	}
@@ -509,20 +644,26 @@ func (p *operationsCenter) checkRelease(ctx context.Context) (*operationsCenterU
 		return nil, ErrNoUpdateAvailable
 	}
 
-	// Get the latest update for the expected channel.
+	// Get the latest update for the expected channel, and the versions available in each channel.
 	var latestUpdate *operationsCenterUpdate
 
+	channelVersions := map[string][]string{}
+
 	for _, update := range updates {
+		for _, channel := range update.Channels {
+			channelVersions[channel] = append(channelVersions[channel], update.Version)
+		}
+
 		// Skip any update targeting the wrong channel(s).
-		if update.Version != p.state.OS.RunningRelease && p.state.System.Update.Config.Channel != "" && !slices.Contains(update.Channels, p.state.System.Update.Config.Channel) {
+		if latestUpdate != nil || (update.Version != p.state.OS.RunningRelease && p.state.System.Update.Config.Channel != "" && !slices.Contains(update.Channels, p.state.System.Update.Config.Channel)) {
 			continue
 		}
 
 		latestUpdate = &update
-
-		break
 	}
 
+	p.channelVersions = channelVersions
+
 	if latestUpdate == nil {
 		return nil, ErrNoUpdateAvailable
 	}
@@ -680,6 +821,10 @@ func (o *operationsCenterOSUpdate) DownloadImage(ctx context.Context, imageType
 	return "", fmt.Errorf("failed to download image type '%s' for release %s", imageType, o.latestUpdate.Version)
 }
 
+func (o *operationsCenterOSUpdate) GetReleaseNotes(_ context.Context) (string, error) {
+	return o.latestUpdate.Changelog, nil
+}
+
 // Secure Boot key updates from the Operations Center provider.
 type operationsCenterSecureBootCertUpdate struct {
 	provider *operationsCenter
@@ -0,0 +1,57 @@
+package providers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	incustls "github.com/lxc/incus/v6/shared/tls"
+
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+func TestOperationsCenterJoinToken(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	encode := func(token operationsCenterJoinToken) string {
+		data, err := json.Marshal(token)
+		require.NoError(t, err)
+
+		return base64.StdEncoding.EncodeToString(data)
+	}
+
+	fingerprint := incustls.CertFingerprint(server.Certificate())
+
+	// Valid token.
+	s := &state.State{}
+	s.System.Provider.Config.Config = map[string]string{}
+
+	p := &operationsCenter{state: s}
+
+	err := p.applyJoinToken(t.Context(), encode(operationsCenterJoinToken{ServerURL: server.URL, Token: "secret", Fingerprint: fingerprint}))
+	require.NoError(t, err)
+	require.Equal(t, server.URL, p.serverURL)
+	require.Equal(t, "secret", p.serverToken)
+	require.Contains(t, p.serverCertificate, "BEGIN CERTIFICATE")
+	require.Equal(t, map[string]string{"server_url": server.URL, "server_token": "secret", "server_certificate": p.serverCertificate}, s.System.Provider.Config.Config)
+
+	// Mismatching fingerprint.
+	err = p.applyJoinToken(t.Context(), encode(operationsCenterJoinToken{ServerURL: server.URL, Token: "secret", Fingerprint: "0000"}))
+	require.Error(t, err)
+
+	// Incomplete and invalid tokens.
+	err = p.applyJoinToken(t.Context(), encode(operationsCenterJoinToken{ServerURL: server.URL}))
+	require.Error(t, err)
+
+	err = p.applyJoinToken(t.Context(), "not-a-token")
+	require.Error(t, err)
+}
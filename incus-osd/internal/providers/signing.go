@@ -0,0 +1,44 @@
+package providers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+	"github.com/lxc/incus-os/incus-osd/internal/truststore"
+)
+
+// ValidateUpdateSigning checks that the update signing configuration is usable.
+func ValidateUpdateSigning(cfg *api.SystemSecurityUpdateSigning) error {
+	if cfg == nil {
+		return nil
+	}
+
+	if cfg.ReplaceDefault && len(cfg.Certificates) == 0 {
+		return errors.New("at least one update signing certificate is required to replace the default one")
+	}
+
+	return truststore.Validate(cfg.Certificates)
+}
+
+// GetUpdateCA returns the PEM-encoded CA certificates trusted to sign updates, combining the default
+// CA certificate with those configured by the administrator.
+func GetUpdateCA(s *state.State, defaultCA string) string {
+	cfg := s.System.Security.Config.UpdateSigning
+	if cfg == nil {
+		return defaultCA
+	}
+
+	certs := []string{}
+
+	if !cfg.ReplaceDefault && defaultCA != "" {
+		certs = append(certs, strings.TrimSpace(defaultCA))
+	}
+
+	for _, cert := range cfg.Certificates {
+		certs = append(certs, strings.TrimSpace(cert.Certificate))
+	}
+
+	return strings.Join(certs, "\n") + "\n"
+}
@@ -0,0 +1,34 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+func TestGetUpdateCA(t *testing.T) {
+	t.Parallel()
+
+	s := &state.State{}
+	require.Equal(t, LXCUpdateCA, GetUpdateCA(s, LXCUpdateCA))
+
+	s.System.Security.Config.UpdateSigning = &api.SystemSecurityUpdateSigning{
+		Certificates: []api.SystemSecurityCACertificate{{Name: "fork", Certificate: "-----BEGIN CERTIFICATE-----\nfork\n-----END CERTIFICATE-----\n"}},
+	}
+	require.Equal(t, LXCUpdateCA+"-----BEGIN CERTIFICATE-----\nfork\n-----END CERTIFICATE-----\n", GetUpdateCA(s, LXCUpdateCA))
+
+	s.System.Security.Config.UpdateSigning.ReplaceDefault = true
+	require.Equal(t, "-----BEGIN CERTIFICATE-----\nfork\n-----END CERTIFICATE-----\n", GetUpdateCA(s, LXCUpdateCA))
+}
+
+func TestValidateUpdateSigning(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidateUpdateSigning(nil))
+	require.NoError(t, ValidateUpdateSigning(&api.SystemSecurityUpdateSigning{Certificates: []api.SystemSecurityCACertificate{{Name: "lxc", Certificate: LXCUpdateCA}}}))
+	require.Error(t, ValidateUpdateSigning(&api.SystemSecurityUpdateSigning{ReplaceDefault: true}))
+	require.Error(t, ValidateUpdateSigning(&api.SystemSecurityUpdateSigning{Certificates: []api.SystemSecurityCACertificate{{Name: "bad", Certificate: "bad"}}}))
+}
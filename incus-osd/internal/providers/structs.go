@@ -40,6 +40,7 @@ type OSUpdate interface {
 	CommonUpdate
 
 	DownloadImage(ctx context.Context, imageType string, targetPath string, progressFunc func(float64)) (string, error)
+	GetReleaseNotes(ctx context.Context) (string, error)
 }
 
 // SecureBootCertUpdate represents a Secure Boot UEFI certificate update (typically a db or dbx addition).
@@ -58,6 +59,7 @@ type Provider interface {
 	GetSecureBootCertUpdate(ctx context.Context) (SecureBootCertUpdate, error)
 	GetOSUpdate(ctx context.Context) (OSUpdate, error)
 	GetApplicationUpdate(ctx context.Context, name string) (ApplicationUpdate, error)
+	GetChannelVersions(ctx context.Context) (map[string][]string, error)
 
 	Register(ctx context.Context, isFirstBoot bool) error
 	RefreshRegister(ctx context.Context) error
@@ -0,0 +1,79 @@
+package providers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	incustls "github.com/lxc/incus/v6/shared/tls"
+)
+
+// getHTTPClient returns the HTTP client to reach a provider endpoint, applying its TLS configuration.
+// The default client is returned when the provider has no specific TLS configuration.
+func getHTTPClient(config map[string]string) (*http.Client, error) {
+	if config["tls_ca"] == "" && config["tls_fingerprint"] == "" {
+		return http.DefaultClient, nil
+	}
+
+	defaultTransport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, errors.New("unexpected default HTTP transport")
+	}
+
+	transport := defaultTransport.Clone()
+	transport.TLSClientConfig = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	err := configureTLS(transport.TLSClientConfig, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// configureTLS applies the TLS configuration of a provider endpoint. The "tls_ca" key replaces the
+// system trust store with the provided PEM encoded CA certificates, while the "tls_fingerprint" key
+// pins the SHA256 fingerprint of the server certificate itself, in which case the certificate chain
+// isn't validated, allowing for self-signed certificates.
+func configureTLS(tlsConfig *tls.Config, config map[string]string) error {
+	if config["tls_ca"] != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(config["tls_ca"])) {
+			return errors.New("invalid provider CA certificate")
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if config["tls_fingerprint"] != "" {
+		fingerprint := strings.ToLower(strings.ReplaceAll(config["tls_fingerprint"], ":", ""))
+		if len(fingerprint) != 64 {
+			return fmt.Errorf("invalid provider certificate fingerprint %q", config["tls_fingerprint"])
+		}
+
+		tlsConfig.InsecureSkipVerify = true //nolint:gosec
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return errors.New("no certificate provided by the provider")
+			}
+
+			cert, err := x509.ParseCertificate(rawCerts[0])
+			if err != nil {
+				return err
+			}
+
+			if incustls.CertFingerprint(cert) != fingerprint {
+				return errors.New("provider certificate doesn't match the pinned fingerprint")
+			}
+
+			return nil
+		}
+	}
+
+	return nil
+}
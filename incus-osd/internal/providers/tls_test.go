@@ -0,0 +1,76 @@
+package providers
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	incustls "github.com/lxc/incus/v6/shared/tls"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetHTTPClient(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cert := server.Certificate()
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	fingerprint := incustls.CertFingerprint(cert)
+
+	get := func(config map[string]string) error {
+		client, err := getHTTPClient(config)
+		require.NoError(t, err)
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+
+		return resp.Body.Close()
+	}
+
+	// No specific configuration.
+	client, err := getHTTPClient(nil)
+	require.NoError(t, err)
+	require.Equal(t, http.DefaultClient, client)
+
+	// Private CA.
+	require.NoError(t, get(map[string]string{"tls_ca": certPEM}))
+
+	// Pinned certificate, in either fingerprint format.
+	require.NoError(t, get(map[string]string{"tls_ca": certPEM, "tls_fingerprint": fingerprint}))
+
+	colons := []string{}
+	for i := 0; i < len(fingerprint); i += 2 {
+		colons = append(colons, strings.ToUpper(fingerprint[i:i+2]))
+	}
+
+	require.NoError(t, get(map[string]string{"tls_ca": certPEM, "tls_fingerprint": strings.Join(colons, ":")}))
+
+	// Mismatched pin.
+	err = get(map[string]string{"tls_ca": certPEM, "tls_fingerprint": strings.Repeat("0", 64)})
+	require.ErrorContains(t, err, "doesn't match the pinned fingerprint")
+
+	// The pin alone is enough for a self-signed certificate.
+	require.NoError(t, get(map[string]string{"tls_fingerprint": fingerprint}))
+
+	// Only the server certificate itself can be pinned.
+	err = get(map[string]string{"tls_fingerprint": strings.Repeat("0", 64)})
+	require.ErrorContains(t, err, "doesn't match the pinned fingerprint")
+
+	// Invalid configuration.
+	_, err = getHTTPClient(map[string]string{"tls_ca": "invalid"})
+	require.Error(t, err)
+
+	_, err = getHTTPClient(map[string]string{"tls_fingerprint": "abcd"})
+	require.Error(t, err)
+}
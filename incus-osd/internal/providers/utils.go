@@ -10,7 +10,13 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
+
+	apiupdate "github.com/lxc/incus-os/incus-osd/api/images"
+	"github.com/lxc/incus-os/incus-osd/internal/systemd"
 )
 
 func downloadAsset(ctx context.Context, client *http.Client, assetURL string, expectedSHA256 string, target string, progressFunc func(float64)) error {
@@ -87,6 +93,41 @@ func downloadAsset(ctx context.Context, client *http.Client, assetURL string, ex
 	return nil
 }
 
+// formatChangelog renders a changelog as plain text.
+func formatChangelog(changelog *apiupdate.Changelog) string {
+	var sb strings.Builder
+
+	if changelog.PriorVersion != "" {
+		_, _ = fmt.Fprintf(&sb, "Changes from %s to %s:\n", changelog.PriorVersion, changelog.CurrnetVersion)
+	} else {
+		_, _ = fmt.Fprintf(&sb, "Changes in %s:\n", changelog.CurrnetVersion)
+	}
+
+	components := make([]string, 0, len(changelog.Components))
+	for component := range changelog.Components {
+		components = append(components, component)
+	}
+
+	slices.Sort(components)
+
+	for _, component := range components {
+		entries := changelog.Components[component]
+
+		_, _ = fmt.Fprintf(&sb, "\n%s:\n", component)
+
+		for _, change := range []struct {
+			name     string
+			packages []string
+		}{{"Added", entries.Added}, {"Updated", entries.Updated}, {"Removed", entries.Removed}} {
+			for _, pkg := range change.packages {
+				_, _ = fmt.Fprintf(&sb, "  %s: %s\n", change.name, pkg)
+			}
+		}
+	}
+
+	return sb.String()
+}
+
 // tryRequest attempts the request multiple times over 5s.
 func tryRequest(client *http.Client, req *http.Request) (*http.Response, error) {
 	var err error
@@ -104,3 +145,32 @@ func tryRequest(client *http.Client, req *http.Request) (*http.Response, error)
 
 	return nil, fmt.Errorf("http request timed out after five seconds: %w", err)
 }
+
+// getChunkIndexFile returns the chunk index published for an update file, if any.
+func getChunkIndexFile(update *apiupdate.UpdateFull, file apiupdate.UpdateFile) *apiupdate.UpdateFile {
+	indexName := strings.TrimSuffix(file.Filename, ".gz") + ".chunks.gz"
+
+	for _, f := range update.Files {
+		if f.Type == apiupdate.UpdateFileTypeChunkIndex && f.Filename == indexName {
+			return &f
+		}
+	}
+
+	return nil
+}
+
+// getOSDeltaSeeds returns the local files holding the running version of an OS update file.
+func getOSDeltaSeeds(osName string, runningRelease string, fileType apiupdate.UpdateFileType) []string {
+	switch fileType { //nolint:exhaustive
+	case apiupdate.UpdateFileTypeUpdateEFI:
+		seeds, _ := filepath.Glob(filepath.Join(systemd.BootImagesPath, osName+"_"+runningRelease+"*.efi"))
+
+		return seeds
+	case apiupdate.UpdateFileTypeUpdateUsr:
+		return []string{filepath.Join(systemd.PartitionLabelsPath, osName+"_"+runningRelease)}
+	case apiupdate.UpdateFileTypeUpdateUsrVerity:
+		return []string{filepath.Join(systemd.PartitionLabelsPath, osName+"_"+runningRelease+"_verity")}
+	default:
+		return nil
+	}
+}
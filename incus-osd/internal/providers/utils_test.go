@@ -0,0 +1,35 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apiupdate "github.com/lxc/incus-os/incus-osd/api/images"
+)
+
+func TestFormatChangelog(t *testing.T) {
+	t.Parallel()
+
+	changelog := &apiupdate.Changelog{
+		CurrnetVersion: "202510272025",
+		PriorVersion:   "202510201500",
+		Channel:        "stable",
+		Components: map[string]apiupdate.ChangelogEntries{
+			"os":    {Updated: []string{"linux-image-6.17.5"}, Removed: []string{"nano"}},
+			"incus": {Added: []string{"incus-6.18"}},
+		},
+	}
+
+	require.Equal(t, `Changes from 202510201500 to 202510272025:
+
+incus:
+  Added: incus-6.18
+
+os:
+  Updated: linux-image-6.17.5
+  Removed: nano
+`, formatChangelog(changelog))
+
+	require.Equal(t, "Changes in 202510272025:\n", formatChangelog(&apiupdate.Changelog{CurrnetVersion: "202510272025"}))
+}
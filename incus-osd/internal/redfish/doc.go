@@ -0,0 +1,3 @@
+// Package redfish provides a minimal client for the Redfish API of BMCs, covering power actions,
+// virtual media and firmware inventory.
+package redfish
@@ -0,0 +1,397 @@
+package redfish
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	incustls "github.com/lxc/incus/v6/shared/tls"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// Client is a Redfish API client for a single BMC, managing a single system.
+type Client struct {
+	address  string
+	username string
+	password string
+	client   *http.Client
+}
+
+type odataID struct {
+	ID string `json:"@odata.id"`
+}
+
+type collection struct {
+	Members []odataID `json:"Members"`
+}
+
+type action struct {
+	Target string `json:"target"`
+}
+
+type computerSystem struct {
+	Manufacturer string   `json:"Manufacturer"`
+	Model        string   `json:"Model"`
+	SerialNumber string   `json:"SerialNumber"`
+	PowerState   string   `json:"PowerState"`
+	VirtualMedia *odataID `json:"VirtualMedia"`
+	Actions      struct {
+		Reset action `json:"#ComputerSystem.Reset"`
+	} `json:"Actions"`
+}
+
+type manager struct {
+	VirtualMedia *odataID `json:"VirtualMedia"`
+}
+
+type virtualMedia struct {
+	path string
+
+	Name       string   `json:"Name"`
+	MediaTypes []string `json:"MediaTypes"`
+	Inserted   bool     `json:"Inserted"`
+	Image      string   `json:"Image"`
+	Actions    struct {
+		InsertMedia action `json:"#VirtualMedia.InsertMedia"`
+		EjectMedia  action `json:"#VirtualMedia.EjectMedia"`
+	} `json:"Actions"`
+}
+
+type firmware struct {
+	Name    string `json:"Name"`
+	Version string `json:"Version"`
+}
+
+// NewClient returns a client for a Redfish endpoint, whose password was already resolved.
+func NewClient(endpoint api.ServiceRedfishEndpoint) (*Client, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if endpoint.TLSCACertificate != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(endpoint.TLSCACertificate)) {
+			return nil, errors.New("invalid TLS CA certificate")
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	// BMCs commonly use self-signed certificates, which can be pinned instead.
+	if endpoint.TLSFingerprint != "" {
+		fingerprint := strings.ToLower(strings.ReplaceAll(endpoint.TLSFingerprint, ":", ""))
+
+		tlsConfig.InsecureSkipVerify = true //nolint:gosec
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return errors.New("no certificate provided by the BMC")
+			}
+
+			cert, err := x509.ParseCertificate(rawCerts[0])
+			if err != nil {
+				return err
+			}
+
+			if incustls.CertFingerprint(cert) != fingerprint {
+				return errors.New("BMC certificate doesn't match the pinned fingerprint")
+			}
+
+			return nil
+		}
+	}
+
+	return &Client{
+		address:  strings.TrimSuffix(endpoint.Address, "/"),
+		username: endpoint.Username,
+		password: endpoint.Password,
+		client: &http.Client{
+			Timeout:   60 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+	}, nil
+}
+
+// GetState returns the state of the managed system, along with its firmware inventory and virtual media.
+func (c *Client) GetState(ctx context.Context) (api.ServiceRedfishEndpointState, error) {
+	ret := api.ServiceRedfishEndpointState{
+		Firmware:     []api.ServiceRedfishFirmware{},
+		VirtualMedia: []api.ServiceRedfishMedia{},
+	}
+
+	_, system, err := c.getSystem(ctx)
+	if err != nil {
+		return ret, err
+	}
+
+	ret.Manufacturer = system.Manufacturer
+	ret.Model = system.Model
+	ret.SerialNumber = system.SerialNumber
+	ret.PowerState = system.PowerState
+
+	// Not all BMCs provide a firmware inventory.
+	inventory := collection{}
+
+	err = c.get(ctx, "/redfish/v1/UpdateService/FirmwareInventory", &inventory)
+	if err == nil {
+		for _, member := range inventory.Members {
+			entry := firmware{}
+
+			err := c.get(ctx, member.ID, &entry)
+			if err != nil {
+				return ret, err
+			}
+
+			ret.Firmware = append(ret.Firmware, api.ServiceRedfishFirmware{Name: entry.Name, Version: entry.Version})
+		}
+	}
+
+	media, err := c.getVirtualMedia(ctx, system)
+	if err != nil {
+		return ret, err
+	}
+
+	for _, entry := range media {
+		ret.VirtualMedia = append(ret.VirtualMedia, api.ServiceRedfishMedia{Name: entry.Name, Inserted: entry.Inserted, Image: entry.Image})
+	}
+
+	return ret, nil
+}
+
+// Reset triggers a power action on the managed system, using a Redfish reset type like "On" or "ForceRestart".
+func (c *Client) Reset(ctx context.Context, resetType string) error {
+	path, system, err := c.getSystem(ctx)
+	if err != nil {
+		return err
+	}
+
+	target := system.Actions.Reset.Target
+	if target == "" {
+		target = path + "/Actions/ComputerSystem.Reset"
+	}
+
+	return c.send(ctx, http.MethodPost, target, map[string]any{"ResetType": resetType})
+}
+
+// InsertMedia inserts an image in the first virtual CD or DVD drive, optionally booting from it once.
+func (c *Client) InsertMedia(ctx context.Context, image string, boot bool) error {
+	path, system, err := c.getSystem(ctx)
+	if err != nil {
+		return err
+	}
+
+	media, err := c.getVirtualMedia(ctx, system)
+	if err != nil {
+		return err
+	}
+
+	index := slices.IndexFunc(media, func(entry virtualMedia) bool {
+		return slices.Contains(entry.MediaTypes, "CD") || slices.Contains(entry.MediaTypes, "DVD")
+	})
+
+	if index < 0 {
+		return errors.New("no virtual CD or DVD drive found")
+	}
+
+	target := media[index].Actions.InsertMedia.Target
+	if target == "" {
+		target = media[index].path + "/Actions/VirtualMedia.InsertMedia"
+	}
+
+	err = c.send(ctx, http.MethodPost, target, map[string]any{"Image": image, "Inserted": true, "WriteProtected": true})
+	if err != nil {
+		return err
+	}
+
+	if !boot {
+		return nil
+	}
+
+	return c.send(ctx, http.MethodPatch, path, map[string]any{"Boot": map[string]any{"BootSourceOverrideEnabled": "Once", "BootSourceOverrideTarget": "Cd"}})
+}
+
+// EjectMedia ejects all the inserted virtual media.
+func (c *Client) EjectMedia(ctx context.Context) error {
+	_, system, err := c.getSystem(ctx)
+	if err != nil {
+		return err
+	}
+
+	media, err := c.getVirtualMedia(ctx, system)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range media {
+		if !entry.Inserted {
+			continue
+		}
+
+		target := entry.Actions.EjectMedia.Target
+		if target == "" {
+			target = entry.path + "/Actions/VirtualMedia.EjectMedia"
+		}
+
+		err := c.send(ctx, http.MethodPost, target, map[string]any{})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getSystem returns the path and the details of the first system managed by the BMC.
+func (c *Client) getSystem(ctx context.Context) (string, computerSystem, error) {
+	system := computerSystem{}
+	systems := collection{}
+
+	err := c.get(ctx, "/redfish/v1/Systems", &systems)
+	if err != nil {
+		return "", system, err
+	}
+
+	if len(systems.Members) == 0 {
+		return "", system, errors.New("no system managed by the BMC")
+	}
+
+	err = c.get(ctx, systems.Members[0].ID, &system)
+	if err != nil {
+		return "", system, err
+	}
+
+	return systems.Members[0].ID, system, nil
+}
+
+// getVirtualMedia returns the virtual media of a system, falling back to those of the first manager.
+func (c *Client) getVirtualMedia(ctx context.Context, system computerSystem) ([]virtualMedia, error) {
+	mediaCollection := system.VirtualMedia
+
+	if mediaCollection == nil {
+		managers := collection{}
+
+		err := c.get(ctx, "/redfish/v1/Managers", &managers)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(managers.Members) == 0 {
+			return []virtualMedia{}, nil
+		}
+
+		bmc := manager{}
+
+		err = c.get(ctx, managers.Members[0].ID, &bmc)
+		if err != nil {
+			return nil, err
+		}
+
+		mediaCollection = bmc.VirtualMedia
+	}
+
+	media := []virtualMedia{}
+
+	if mediaCollection == nil {
+		return media, nil
+	}
+
+	members := collection{}
+
+	err := c.get(ctx, mediaCollection.ID, &members)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, member := range members.Members {
+		entry := virtualMedia{path: member.ID}
+
+		err := c.get(ctx, member.ID, &entry)
+		if err != nil {
+			return nil, err
+		}
+
+		media = append(media, entry)
+	}
+
+	return media, nil
+}
+
+// get retrieves a resource.
+func (c *Client) get(ctx context.Context, path string, dest any) error {
+	resp, err := c.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	return json.NewDecoder(resp.Body).Decode(dest)
+}
+
+// send sends a request with a JSON body, like an action or a resource update.
+func (c *Client) send(ctx context.Context, method string, path string, body any) error {
+	content, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(ctx, method, path, content)
+	if err != nil {
+		return err
+	}
+
+	_ = resp.Body.Close()
+
+	return nil
+}
+
+// do runs an authenticated request, returning an error for unsuccessful responses.
+func (c *Client) do(ctx context.Context, method string, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.address+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.SetBasicAuth(c.username, c.password)
+	req.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer func() { _ = resp.Body.Close() }()
+
+		// Redfish errors carry a message, possibly with more details in the extended info.
+		redfishErr := struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}{}
+
+		content, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+		err := json.Unmarshal(content, &redfishErr)
+		if err == nil && redfishErr.Error.Message != "" {
+			return nil, fmt.Errorf("redfish request %s %q failed: %s", method, path, redfishErr.Error.Message)
+		}
+
+		return nil, fmt.Errorf("redfish request %s %q failed: %s", method, path, resp.Status)
+	}
+
+	return resp, nil
+}
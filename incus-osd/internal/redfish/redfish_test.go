@@ -0,0 +1,141 @@
+package redfish
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	incustls "github.com/lxc/incus/v6/shared/tls"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// newBMC returns a mock BMC, recording the actions and updates it receives.
+func newBMC(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	resources := map[string]string{
+		"/redfish/v1/Systems":                                                     `{"Members":[{"@odata.id":"/redfish/v1/Systems/1"}]}`,
+		"/redfish/v1/Systems/1":                                                   `{"Manufacturer":"Example","Model":"Server","SerialNumber":"ABC123","PowerState":"On","Actions":{"#ComputerSystem.Reset":{"target":"/redfish/v1/Systems/1/Actions/ComputerSystem.Reset"}}}`,
+		"/redfish/v1/UpdateService/FirmwareInventory":                             `{"Members":[{"@odata.id":"/redfish/v1/UpdateService/FirmwareInventory/BMC"}]}`,
+		"/redfish/v1/UpdateService/FirmwareInventory/BMC":                         `{"Name":"BMC","Version":"1.2.3"}`,
+		"/redfish/v1/Managers":                                                    `{"Members":[{"@odata.id":"/redfish/v1/Managers/1"}]}`,
+		"/redfish/v1/Managers/1":                                                  `{"VirtualMedia":{"@odata.id":"/redfish/v1/Managers/1/VirtualMedia"}}`,
+		"/redfish/v1/Managers/1/VirtualMedia":                                     `{"Members":[{"@odata.id":"/redfish/v1/Managers/1/VirtualMedia/Floppy"},{"@odata.id":"/redfish/v1/Managers/1/VirtualMedia/CD"}]}`,
+		"/redfish/v1/Managers/1/VirtualMedia/Floppy":                              `{"Name":"Floppy","MediaTypes":["Floppy"]}`,
+		"/redfish/v1/Managers/1/VirtualMedia/CD":                                  `{"Name":"CD","MediaTypes":["CD","DVD"],"Inserted":true,"Image":"https://example.com/old.iso"}`,
+		"/redfish/v1/Systems/1/Actions/ComputerSystem.Reset":                      "",
+		"/redfish/v1/Managers/1/VirtualMedia/CD/Actions/VirtualMedia.InsertMedia": "",
+		"/redfish/v1/Managers/1/VirtualMedia/CD/Actions/VirtualMedia.EjectMedia":  "",
+	}
+
+	lock := sync.Mutex{}
+	requests := []string{}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "admin" || password != "password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"message":"Invalid credentials"}}`))
+
+			return
+		}
+
+		content, ok := resources[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(content))
+
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		require.True(t, json.Valid(body))
+
+		lock.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		lock.Unlock()
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newBMC(t)
+
+	client, err := NewClient(api.ServiceRedfishEndpoint{
+		Address:        server.URL,
+		Username:       "admin",
+		Password:       "password",
+		TLSFingerprint: incustls.CertFingerprint(server.Certificate()),
+	})
+	require.NoError(t, err)
+
+	state, err := client.GetState(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, api.ServiceRedfishEndpointState{
+		Manufacturer: "Example",
+		Model:        "Server",
+		SerialNumber: "ABC123",
+		PowerState:   "On",
+		Firmware:     []api.ServiceRedfishFirmware{{Name: "BMC", Version: "1.2.3"}},
+		VirtualMedia: []api.ServiceRedfishMedia{{Name: "Floppy"}, {Name: "CD", Inserted: true, Image: "https://example.com/old.iso"}},
+	}, state)
+
+	require.NoError(t, client.Reset(t.Context(), "ForceRestart"))
+	require.NoError(t, client.EjectMedia(t.Context()))
+	require.NoError(t, client.InsertMedia(t.Context(), "https://example.com/new.iso", true))
+
+	require.Equal(t, []string{
+		`POST /redfish/v1/Systems/1/Actions/ComputerSystem.Reset {"ResetType":"ForceRestart"}`,
+		`POST /redfish/v1/Managers/1/VirtualMedia/CD/Actions/VirtualMedia.EjectMedia {}`,
+		`POST /redfish/v1/Managers/1/VirtualMedia/CD/Actions/VirtualMedia.InsertMedia {"Image":"https://example.com/new.iso","Inserted":true,"WriteProtected":true}`,
+		`PATCH /redfish/v1/Systems/1 {"Boot":{"BootSourceOverrideEnabled":"Once","BootSourceOverrideTarget":"Cd"}}`,
+	}, *requests)
+}
+
+func TestClientErrors(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newBMC(t)
+
+	// Unknown certificate.
+	client, err := NewClient(api.ServiceRedfishEndpoint{Address: server.URL, Username: "admin", Password: "password"})
+	require.NoError(t, err)
+
+	_, err = client.GetState(t.Context())
+	require.ErrorContains(t, err, "certificate")
+
+	// Mismatched fingerprint.
+	client, err = NewClient(api.ServiceRedfishEndpoint{Address: server.URL, Username: "admin", Password: "password", TLSFingerprint: "00"})
+	require.NoError(t, err)
+
+	_, err = client.GetState(t.Context())
+	require.ErrorContains(t, err, "pinned fingerprint")
+
+	// Invalid credentials.
+	client, err = NewClient(api.ServiceRedfishEndpoint{Address: server.URL, Username: "admin", Password: "wrong", TLSFingerprint: incustls.CertFingerprint(server.Certificate())})
+	require.NoError(t, err)
+
+	_, err = client.GetState(t.Context())
+	require.ErrorContains(t, err, "Invalid credentials")
+
+	// Invalid CA certificate.
+	_, err = NewClient(api.ServiceRedfishEndpoint{Address: server.URL, TLSCACertificate: "invalid"})
+	require.ErrorContains(t, err, "invalid TLS CA certificate")
+}
@@ -0,0 +1,52 @@
+package reset
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// ConfirmationTimeout is how long a factory reset confirmation token remains valid.
+const ConfirmationTimeout = 5 * time.Minute
+
+// ErrInvalidConfirmation is returned when a factory reset confirmation token is unknown or expired.
+var ErrInvalidConfirmation = errors.New("invalid or expired confirmation token")
+
+// Confirmation tracks the single-use token required to confirm a factory reset.
+type Confirmation struct {
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// Issue generates a new confirmation token, replacing any previous one.
+func (c *Confirmation) Issue() api.SystemResetConfirmation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.token = rand.Text()
+	c.expiry = time.Now().Add(ConfirmationTimeout)
+
+	return api.SystemResetConfirmation{
+		Token:     c.token,
+		ExpiresAt: c.expiry,
+	}
+}
+
+// Check validates and consumes a confirmation token.
+func (c *Confirmation) Check(token string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token == "" || time.Now().After(c.expiry) || subtle.ConstantTimeCompare([]byte(c.token), []byte(token)) != 1 {
+		return ErrInvalidConfirmation
+	}
+
+	c.token = ""
+
+	return nil
+}
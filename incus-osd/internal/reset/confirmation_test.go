@@ -0,0 +1,30 @@
+package reset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirmation(t *testing.T) {
+	t.Parallel()
+
+	c := &Confirmation{}
+
+	// No token issued yet.
+	require.ErrorIs(t, c.Check(""), ErrInvalidConfirmation)
+
+	// Tokens are single-use and replaced when a new one is issued.
+	first := c.Issue()
+	second := c.Issue()
+	require.NotEqual(t, first.Token, second.Token)
+	require.ErrorIs(t, c.Check(first.Token), ErrInvalidConfirmation)
+	require.NoError(t, c.Check(second.Token))
+	require.ErrorIs(t, c.Check(second.Token), ErrInvalidConfirmation)
+
+	// Expired tokens are rejected.
+	expired := c.Issue()
+	c.expiry = time.Now().Add(-time.Second)
+	require.ErrorIs(t, c.Check(expired.Token), ErrInvalidConfirmation)
+}
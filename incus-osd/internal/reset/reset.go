@@ -21,7 +21,7 @@ import (
 // PerformOSFactoryReset performs an OS-level factory reset.
 // !!! THIS WILL RESULT IN THE DESTRUCTION OF ALL DATA CREATED BY !!!
 // !!! IncusOS, ANY APPLICATIONS, AND ANY ZFS DATASETS CREATED IN !!!
-// !!! THE "local" POOL.                                          !!!
+// !!! THE "local" POOL, UNLESS ASKED TO KEEP IT.                 !!!
 func PerformOSFactoryReset(ctx context.Context, resetSeed *api.SystemReset) error {
 	// systemd v258 introduced the factory-reset.target, which in
 	// theory should automate the following steps. However, trixie
@@ -133,8 +133,13 @@ func PerformOSFactoryReset(ctx context.Context, resetSeed *api.SystemReset) erro
 		}
 	}
 
-	// Third, wipe system partitions (swap, root, and local-data).
-	for _, partitionIndex := range []string{"9", "10", "11"} {
+	// Third, wipe system partitions (swap, root, and local-data unless keeping it).
+	partitionIndexes := []string{"9", "10", "11"}
+	if resetSeed.KeepData {
+		partitionIndexes = []string{"9", "10"}
+	}
+
+	for _, partitionIndex := range partitionIndexes {
 		_, err := subprocess.RunCommandContext(ctx, "sgdisk", "-d", partitionIndex, underlyingDevice)
 		if err != nil {
 			return err
@@ -0,0 +1,221 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/audit"
+	"github.com/lxc/incus-os/incus-osd/internal/rest/response"
+)
+
+// adminEndpoints lists the endpoints which may cause data loss, replace the running system or expose
+// secrets, along with the methods requiring the admin role. A nil list means all methods.
+var adminEndpoints = map[string][]string{
+	"/1.0/applications/{name}":                {http.MethodPut},
+	"/1.0/applications/{name}/:backup":        nil,
+	"/1.0/applications/{name}/:factory-reset": nil,
+	"/1.0/applications/{name}/:restore":       nil,
+	"/1.0/debug":                              nil,
+	"/1.0/debug/log":                          nil,
+	"/1.0/debug/secureboot/:update":           nil,
+	"/1.0/debug/tui/:write-message":           nil,
+	"/1.0/services/ipmi/:power-cycle":         nil,
+	"/1.0/services/redfish/:eject-media":      nil,
+	"/1.0/services/redfish/:insert-media":     nil,
+	"/1.0/services/redfish/:power":            nil,
+	"/1.0/services/ssh":                       {http.MethodPut},
+	"/1.0/services/ssh/:reset":                nil,
+	"/1.0/system/:backup":                     nil,
+	"/1.0/system/:factory-reset":              nil,
+	"/1.0/system/:restore":                    nil,
+	"/1.0/system/extensions/{name}":           {http.MethodPut, http.MethodDelete},
+	"/1.0/system/extensions/{name}/:activate": nil,
+	"/1.0/system/firmware":                    {http.MethodPut},
+	"/1.0/system/firmware/:apply":             nil,
+	"/1.0/system/kernel":                      {http.MethodPut},
+	"/1.0/system/security":                    nil,
+	"/1.0/system/storage/:delete-pool":        nil,
+	"/1.0/system/storage/:delete-volume":      nil,
+	"/1.0/system/storage/:rewind-checkpoint":  nil,
+	"/1.0/system/storage/:wipe-drive":         nil,
+	"/1.0/system/update":                      {http.MethodPut},
+	"/1.0/system/update/:apply":               nil,
+	"/1.0/system/update/:check":               nil,
+	"/1.0/system/update/:export-bundle":       nil,
+	"/1.0/system/update/:import-bundle":       nil,
+	"/1.0/system/update/:rollback":            nil,
+	"/1.0/system/update/:stage":               nil,
+	"/1.0/system/watchdog":                    {http.MethodPut},
+}
+
+// roleLevels orders the roles from least to most privileged.
+var roleLevels = []string{api.SystemSecurityRoleViewer, api.SystemSecurityRoleOperator, api.SystemSecurityRoleAdmin}
+
+// ValidateAccessControl checks that the access control configuration only uses known roles.
+func ValidateAccessControl(cfg *api.SystemSecurityAccessControl) error {
+	if cfg == nil {
+		return nil
+	}
+
+	if cfg.DefaultRole != "" && !slices.Contains(roleLevels, cfg.DefaultRole) {
+		return fmt.Errorf("invalid default role %q", cfg.DefaultRole)
+	}
+
+	// Callers which aren't explicitly listed must never be administrators.
+	if cfg.DefaultRole == api.SystemSecurityRoleAdmin {
+		return errors.New("the default role can't be admin")
+	}
+
+	for identity, role := range cfg.Identities {
+		if !slices.Contains(roleLevels, role) {
+			return fmt.Errorf("invalid role %q for identity %q", role, identity)
+		}
+	}
+
+	return nil
+}
+
+// getRequiredRole returns the role needed to access an endpoint using the provided method. Endpoints
+// are matched on their route pattern, or on their path for a specific instance of a resource.
+func getRequiredRole(pattern string, path string, method string) string {
+	methods, ok := adminEndpoints[pattern]
+	if !ok {
+		methods, ok = adminEndpoints[path]
+	}
+
+	if !ok && strings.HasPrefix(pattern, "/1.0/system/security/:") {
+		// All security actions are restricted to administrators.
+		ok = true
+	}
+
+	if ok && (methods == nil || slices.Contains(methods, method)) {
+		return api.SystemSecurityRoleAdmin
+	}
+
+	if method == http.MethodGet || method == http.MethodHead {
+		return api.SystemSecurityRoleViewer
+	}
+
+	return api.SystemSecurityRoleOperator
+}
+
+// getCallerRole returns the role of the caller making the request. Local root processes are always
+// administrators, while once access control is configured, callers which the proxy doesn't identify get
+// the lowest role.
+func getCallerRole(cfg *api.SystemSecurityAccessControl, c caller, identity string) string {
+	if c.local {
+		return api.SystemSecurityRoleAdmin
+	}
+
+	if !c.proxied {
+		return api.SystemSecurityRoleViewer
+	}
+
+	// Without any access control, all the callers authenticated by the proxy are administrators.
+	if cfg == nil {
+		return api.SystemSecurityRoleAdmin
+	}
+
+	if identity == "" {
+		return api.SystemSecurityRoleViewer
+	}
+
+	role, ok := cfg.Identities[identity]
+	if ok {
+		return role
+	}
+
+	if cfg.DefaultRole == "" {
+		return api.SystemSecurityRoleViewer
+	}
+
+	return cfg.DefaultRole
+}
+
+// checkAccess wraps the router, rejecting requests from callers whose role doesn't allow using the endpoint.
+func (s *Server) checkAccess(router *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, pattern := router.Handler(r)
+
+		callerRole := getCallerRole(s.state.System.Security.Config.AccessControl, getCaller(r), getIdentity(r))
+		requiredRole := getRequiredRole(pattern, r.URL.Path, r.Method)
+
+		if slices.Index(roleLevels, callerRole) < slices.Index(roleLevels, requiredRole) {
+			w.Header().Set("Content-Type", "application/json")
+
+			_ = response.Forbidden(errors.New("the " + requiredRole + " role is required")).Render(w)
+
+			return
+		}
+
+		// Only administrators may read secrets, such as passwords or private keys.
+		if callerRole != api.SystemSecurityRoleAdmin && (r.Method == http.MethodGet || r.Method == http.MethodHead) {
+			redactor := &redactingWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+			router.ServeHTTP(redactor, r)
+
+			body, err := redactor.redactedBody()
+			if err != nil {
+				w.Header().Set("Content-Type", "application/json")
+
+				_ = response.InternalError(err).Render(w)
+
+				return
+			}
+
+			w.WriteHeader(redactor.statusCode)
+			_, _ = w.Write(body)
+
+			return
+		}
+
+		router.ServeHTTP(w, r)
+	})
+}
+
+// redactingWriter buffers a response, so the secrets it holds can be redacted before it's sent.
+type redactingWriter struct {
+	http.ResponseWriter
+
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (w *redactingWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+}
+
+func (w *redactingWriter) Write(p []byte) (int, error) {
+	return w.body.Write(p)
+}
+
+// redactedBody returns the buffered response body, with all the secrets redacted from JSON responses.
+// Responses which aren't JSON, such as metrics, are returned as is.
+func (w *redactingWriter) redactedBody() ([]byte, error) {
+	body := w.body.Bytes()
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var content any
+
+	err := decoder.Decode(&content)
+	if err != nil {
+		return body, nil //nolint:nilerr
+	}
+
+	body, err = json.Marshal(audit.RedactSecrets(content))
+	if err != nil {
+		return nil, err
+	}
+
+	w.Header().Del("Content-Length")
+
+	return body, nil
+}
@@ -0,0 +1,135 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/rest/response"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+func TestGetCallerRole(t *testing.T) {
+	t.Parallel()
+
+	cfg := &api.SystemSecurityAccessControl{
+		DefaultRole: api.SystemSecurityRoleViewer,
+		Identities:  map[string]string{"admin-fingerprint": api.SystemSecurityRoleAdmin, "operator-fingerprint": api.SystemSecurityRoleOperator},
+	}
+
+	proxied := caller{proxied: true}
+
+	tests := []struct {
+		name     string
+		cfg      *api.SystemSecurityAccessControl
+		caller   caller
+		identity string
+		role     string
+	}{
+		{"local root", cfg, caller{local: true}, "", api.SystemSecurityRoleAdmin},
+		{"unknown peer", cfg, caller{}, "admin-fingerprint", api.SystemSecurityRoleViewer},
+		{"unknown peer without access control", nil, caller{}, "", api.SystemSecurityRoleViewer},
+		{"listed admin", cfg, proxied, "admin-fingerprint", api.SystemSecurityRoleAdmin},
+		{"listed operator", cfg, proxied, "operator-fingerprint", api.SystemSecurityRoleOperator},
+		{"unknown identity", cfg, proxied, "other", api.SystemSecurityRoleViewer},
+		{"missing identity", cfg, proxied, "", api.SystemSecurityRoleViewer},
+		{"missing identity without access control", nil, proxied, "", api.SystemSecurityRoleAdmin},
+		{"unknown identity without default role", &api.SystemSecurityAccessControl{}, proxied, "other", api.SystemSecurityRoleViewer},
+		{"identity without access control", nil, proxied, "other", api.SystemSecurityRoleAdmin},
+	}
+
+	for _, tc := range tests {
+		require.Equal(t, tc.role, getCallerRole(tc.cfg, tc.caller, tc.identity), tc.name)
+	}
+
+	require.Error(t, ValidateAccessControl(&api.SystemSecurityAccessControl{DefaultRole: api.SystemSecurityRoleAdmin}))
+}
+
+func TestForgedHeaders(t *testing.T) {
+	t.Parallel()
+
+	newRequest := func(c *caller) *http.Request {
+		r := httptest.NewRequest(http.MethodPut, "/1.0/system/security", nil)
+		r.Header.Set(identityHeader, "admin-fingerprint")
+
+		if c != nil {
+			r = r.WithContext(context.WithValue(r.Context(), callerKey{}, *c))
+		}
+
+		return r
+	}
+
+	cfg := &api.SystemSecurityAccessControl{Identities: map[string]string{"admin-fingerprint": api.SystemSecurityRoleAdmin}}
+
+	// Headers sent on a connection which isn't from a proxy are ignored.
+	for _, r := range []*http.Request{newRequest(nil), newRequest(&caller{})} {
+		require.Empty(t, getIdentity(r))
+		require.Equal(t, api.SystemSecurityRoleViewer, getCallerRole(cfg, getCaller(r), getIdentity(r)))
+		require.Equal(t, api.SystemSecurityRoleViewer, getCallerRole(nil, getCaller(r), getIdentity(r)))
+	}
+
+	// Connections which aren't over the unix socket have no peer credentials.
+	client, server := net.Pipe()
+
+	defer func() { _ = client.Close() }()
+	defer func() { _ = server.Close() }()
+
+	require.Equal(t, caller{}, getCaller(newRequest(nil).WithContext(connCaller(t.Context(), server))))
+
+	// Headers are only trusted when set by the proxy.
+	r := newRequest(&caller{proxied: true})
+	require.Equal(t, "admin-fingerprint", getIdentity(r))
+	require.Equal(t, api.SystemSecurityRoleAdmin, getCallerRole(cfg, getCaller(r), getIdentity(r)))
+}
+
+func TestRedactSecrets(t *testing.T) {
+	t.Parallel()
+
+	s := &Server{state: &state.State{}}
+	s.state.System.Security.Config.AccessControl = &api.SystemSecurityAccessControl{
+		DefaultRole: api.SystemSecurityRoleOperator,
+		Identities:  map[string]string{"admin-fingerprint": api.SystemSecurityRoleAdmin},
+	}
+
+	router := http.NewServeMux()
+	router.HandleFunc("/1.0/services/{name}", func(w http.ResponseWriter, _ *http.Request) {
+		_ = response.SyncResponse(true, api.ServiceRedfish{Config: api.ServiceRedfishConfig{
+			Enabled:   true,
+			Endpoints: []api.ServiceRedfishEndpoint{{Address: "https://bmc.example.com", Username: "root", Password: "bmc-password"}},
+		}}).Render(w)
+	})
+
+	get := func(identity string) api.ServiceRedfish {
+		r := httptest.NewRequest(http.MethodGet, "/1.0/services/redfish", nil)
+		r.Header.Set(identityHeader, identity)
+		r = r.WithContext(context.WithValue(r.Context(), callerKey{}, caller{proxied: true}))
+
+		w := httptest.NewRecorder()
+		s.checkAccess(router).ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		resp := struct {
+			Metadata api.ServiceRedfish `json:"metadata"`
+		}{}
+
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+		return resp.Metadata
+	}
+
+	// Administrators get the secrets.
+	service := get("admin-fingerprint")
+	require.Equal(t, "bmc-password", service.Config.Endpoints[0].Password)
+
+	// Other callers get them redacted, leaving the rest of the response intact.
+	service = get("other")
+	require.Equal(t, "<redacted>", service.Config.Endpoints[0].Password)
+	require.Equal(t, "root", service.Config.Endpoints[0].Username)
+	require.True(t, service.Config.Enabled)
+}
@@ -3,6 +3,7 @@ package rest
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
@@ -183,18 +184,48 @@ func (s *Server) apiApplications(w http.ResponseWriter, r *http.Request) {
 //	        metadata:
 //	          type: json
 //	          description: State and configuration for the application
-//	          example: {"state":{"initialized":true,"version":"202511041601"},"config":{}}
+//	          example: {"state":{"initialized":true,"version":"202511041601","last_update_check":"2025-11-04T16:21:34Z"},"config":{"update":{"channel":"stable","check_frequency":"168h"}}}
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+
+// swagger:operation PUT /1.0/applications/{name} applications applications_put_application
+//
+//	Update application configuration
+//
+//	Updates the application configuration, such as its update policy.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: path
+//	    name: name
+//	    description: Application name
+//	    required: true
+//	    type: string
+//	  - in: body
+//	    name: configuration
+//	    description: Application configuration
+//	    required: true
+//	    schema:
+//	      type: object
+//	      properties:
+//	        config:
+//	          type: object
+//	          description: The application configuration
+//	          example: {"update":{"channel":"stable","check_frequency":"168h","pinned_version":"202511041601"}}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
 //	  "404":
 //	    $ref: "#/responses/NotFound"
 func (s *Server) apiApplicationsEndpoint(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	if r.Method != http.MethodGet {
-		_ = response.NotImplemented(nil).Render(w)
-
-		return
-	}
-
 	name := r.PathValue("name")
 
 	// Check if the application is valid.
@@ -205,8 +236,48 @@ func (s *Server) apiApplicationsEndpoint(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Handle the request.
-	_ = response.SyncResponse(true, app).Render(w)
+	switch r.Method {
+	case http.MethodGet:
+		// Handle the request.
+		_ = response.SyncResponse(true, app).Render(w)
+	case http.MethodPut:
+		newApp := &api.Application{}
+
+		err := json.NewDecoder(r.Body).Decode(newApp)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		// Check the update policy.
+		updateConfig := newApp.Config.Update
+		if updateConfig != nil {
+			if updateConfig.CheckFrequency != "" && updateConfig.CheckFrequency != "never" {
+				_, err = time.ParseDuration(updateConfig.CheckFrequency)
+				if err != nil {
+					_ = response.BadRequest(errors.New("invalid application update check frequency")).Render(w)
+
+					return
+				}
+			}
+
+			if s.state.System.Provider.Config.Name == "images" && updateConfig.Channel != "" && !slices.Contains(api.SystemUpdateChannels, updateConfig.Channel) {
+				_ = response.BadRequest(fmt.Errorf("invalid update channel %q", updateConfig.Channel)).Render(w)
+
+				return
+			}
+		}
+
+		// Apply the new configuration.
+		app.Config = newApp.Config
+		s.state.Applications[name] = app
+		_ = s.state.Save()
+
+		_ = response.EmptySyncResponse.Render(w)
+	default:
+		_ = response.NotImplemented(nil).Render(w)
+	}
 }
 
 // swagger:operation POST /1.0/applications/{name}/:factory-reset applications applications_post_reset
@@ -0,0 +1,112 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/rest/response"
+	"github.com/lxc/incus-os/incus-osd/internal/storage"
+)
+
+// swagger:operation GET /1.0/metrics metrics metrics_get
+//
+//	Get metrics
+//
+//	Returns system metrics in the Prometheus text exposition format.
+//
+//	---
+//	produces:
+//	  - text/plain
+//	responses:
+//	  "200":
+//	    description: Metrics
+//	    schema:
+//	      type: string
+//	      example: |-
+//	        # HELP incusos_storage_device_read_ops Read operations per second.
+//	        # TYPE incusos_storage_device_read_ops gauge
+//	        incusos_storage_device_read_ops{pool="local",device="nvme0n1p11"} 12
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (*Server) apiMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	ioStats, err := storage.GetIOStats(r.Context())
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.SyncResponsePlain(true, false, storageMetrics(ioStats)).Render(w)
+}
+
+// storageMetrics renders storage I/O statistics in the Prometheus text exposition format.
+func storageMetrics(stats api.SystemStorageIOStats) string {
+	var sb strings.Builder
+
+	deviceMetrics := []struct {
+		name  string
+		help  string
+		value func(api.SystemStorageDeviceIOStats) int
+	}{
+		{"read_ops", "Read operations per second.", func(d api.SystemStorageDeviceIOStats) int { return d.ReadOpsPerSecond }},
+		{"write_ops", "Write operations per second.", func(d api.SystemStorageDeviceIOStats) int { return d.WriteOpsPerSecond }},
+		{"read_bytes", "Bytes read per second.", func(d api.SystemStorageDeviceIOStats) int { return d.ReadBytesPerSecond }},
+		{"write_bytes", "Bytes written per second.", func(d api.SystemStorageDeviceIOStats) int { return d.WriteBytesPerSecond }},
+		{"read_latency_seconds", "Average read latency.", func(d api.SystemStorageDeviceIOStats) int { return d.ReadLatency }},
+		{"write_latency_seconds", "Average write latency.", func(d api.SystemStorageDeviceIOStats) int { return d.WriteLatency }},
+		{"queue_depth", "Pending and active I/O requests.", func(d api.SystemStorageDeviceIOStats) int { return d.QueueDepth }},
+	}
+
+	for _, metric := range deviceMetrics {
+		fmt.Fprintf(&sb, "# HELP incusos_storage_device_%s %s\n", metric.name, metric.help)
+		fmt.Fprintf(&sb, "# TYPE incusos_storage_device_%s gauge\n", metric.name)
+
+		for _, pool := range stats.Pools {
+			for _, device := range append([]api.SystemStorageDeviceIOStats{pool.Pool}, pool.Devices...) {
+				value := strconv.Itoa(metric.value(device))
+
+				// Latencies are reported in nanoseconds.
+				if strings.HasSuffix(metric.name, "_seconds") {
+					value = strconv.FormatFloat(float64(metric.value(device))/1e9, 'g', -1, 64)
+				}
+
+				fmt.Fprintf(&sb, "incusos_storage_device_%s{pool=%q,device=%q} %s\n", metric.name, pool.Name, device.Name, value)
+			}
+		}
+	}
+
+	datasetMetrics := []struct {
+		name  string
+		help  string
+		value func(api.SystemStorageDatasetIOStats) int
+	}{
+		{"reads_total", "Read operations since the pool was imported.", func(d api.SystemStorageDatasetIOStats) int { return d.Reads }},
+		{"writes_total", "Write operations since the pool was imported.", func(d api.SystemStorageDatasetIOStats) int { return d.Writes }},
+		{"read_bytes_total", "Bytes read since the pool was imported.", func(d api.SystemStorageDatasetIOStats) int { return d.BytesRead }},
+		{"written_bytes_total", "Bytes written since the pool was imported.", func(d api.SystemStorageDatasetIOStats) int { return d.BytesWritten }},
+	}
+
+	for _, metric := range datasetMetrics {
+		fmt.Fprintf(&sb, "# HELP incusos_storage_dataset_%s %s\n", metric.name, metric.help)
+		fmt.Fprintf(&sb, "# TYPE incusos_storage_dataset_%s counter\n", metric.name)
+
+		for _, pool := range stats.Pools {
+			for _, dataset := range pool.Datasets {
+				fmt.Fprintf(&sb, "incusos_storage_dataset_%s{pool=%q,dataset=%q} %d\n", metric.name, pool.Name, dataset.Name, metric.value(dataset))
+			}
+		}
+	}
+
+	return sb.String()
+}
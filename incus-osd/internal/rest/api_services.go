@@ -2,10 +2,13 @@ package rest
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/url"
 	"slices"
 
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/nftables"
 	"github.com/lxc/incus-os/incus-osd/internal/rest/response"
 	"github.com/lxc/incus-os/incus-osd/internal/services"
 )
@@ -43,7 +46,7 @@ import (
 //	          description: List of services
 //	          items:
 //	            type: string
-//	          example: ["/1.0/services/ceph","/1.0/services/iscsi","/1.0/services/linstor","/1.0/services/lvm","/1.0/services/multipath","/1.0/services/nvme","/1.0/services/ovn","/1.0/services/tailscale","/1.0/services/usbip"]
+//	          example: ["/1.0/services/bgp","/1.0/services/ceph","/1.0/services/drbd","/1.0/services/fc","/1.0/services/haproxy","/1.0/services/ipmi","/1.0/services/iscsi","/1.0/services/linstor","/1.0/services/lldp","/1.0/services/logforward","/1.0/services/lvm","/1.0/services/metrics","/1.0/services/multipath","/1.0/services/netdata","/1.0/services/nfs","/1.0/services/nvme","/1.0/services/ovn","/1.0/services/redfish","/1.0/services/smartd","/1.0/services/ssh","/1.0/services/tailscale","/1.0/services/usbip","/1.0/services/vrrp"]
 func (s *Server) apiServices(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -198,6 +201,14 @@ func (s *Server) apiServicesEndpoint(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// Refresh the host firewall rules for the service.
+		err = nftables.ApplyHostFirewall(r.Context(), s.state)
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+
 		_ = response.EmptySyncResponse.Render(w)
 	default:
 		_ = response.NotImplemented(nil).Render(w)
@@ -265,3 +276,478 @@ func (s *Server) apiServicesEndpointReset(w http.ResponseWriter, r *http.Request
 		return
 	}
 }
+
+// swagger:operation POST /1.0/services/drbd/:promote services services_post_drbd_promote
+//
+//	Promote a DRBD resource
+//
+//	Makes the system primary for a DRBD resource, allowing its device to be used.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: resource
+//	    description: Resource to promote
+//	    required: true
+//	    schema:
+//	      type: object
+//	      example: {"resource":"r0","force":false}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiServicesDRBDPromote(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	srv, req, ok := s.loadDRBDRoleChange(w, r)
+	if !ok {
+		return
+	}
+
+	err := srv.Promote(r.Context(), req.Resource, req.Force)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/services/drbd/:demote services services_post_drbd_demote
+//
+//	Demote a DRBD resource
+//
+//	Makes the system secondary for a DRBD resource, allowing its peer to be promoted.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: resource
+//	    description: Resource to demote
+//	    required: true
+//	    schema:
+//	      type: object
+//	      example: {"resource":"r0"}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiServicesDRBDDemote(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	srv, req, ok := s.loadDRBDRoleChange(w, r)
+	if !ok {
+		return
+	}
+
+	err := srv.Demote(r.Context(), req.Resource)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// loadDRBDRoleChange loads the DRBD service and decodes a role change request, rendering any error.
+func (s *Server) loadDRBDRoleChange(w http.ResponseWriter, r *http.Request) (*services.DRBD, *api.ServiceDRBDRoleChange, bool) {
+	// Check if the service is valid.
+	if !slices.Contains(services.Supported(s.state), "drbd") {
+		_ = response.NotFound(nil).Render(w)
+
+		return nil, nil, false
+	}
+
+	srv, err := services.Load(r.Context(), s.state, "drbd")
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return nil, nil, false
+	}
+
+	drbd, ok := srv.(*services.DRBD)
+	if !ok {
+		_ = response.InternalError(errors.New("unexpected DRBD service type")).Render(w)
+
+		return nil, nil, false
+	}
+
+	req := &api.ServiceDRBDRoleChange{}
+
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		_ = response.BadRequest(err).Render(w)
+
+		return nil, nil, false
+	}
+
+	if req.Resource == "" {
+		_ = response.BadRequest(errors.New("a DRBD resource is required")).Render(w)
+
+		return nil, nil, false
+	}
+
+	return drbd, req, true
+}
+
+// swagger:operation POST /1.0/services/ipmi/:identify services services_post_ipmi_identify
+//
+//	Turn on the chassis identify LED
+//
+//	Has the BMC turn on the chassis identify LED, helping locate the system.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: identify
+//	    description: Identify request
+//	    required: true
+//	    schema:
+//	      type: object
+//	      example: {"duration":60,"force":false}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiServicesIPMIIdentify(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	srv, ok := s.loadIPMI(w, r)
+	if !ok {
+		return
+	}
+
+	req := &api.ServiceIPMIIdentify{}
+
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	err = srv.Identify(r.Context(), req.Duration, req.Force)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/services/ipmi/:power-cycle services services_post_ipmi_power_cycle
+//
+//	Power cycle the system
+//
+//	Flushes the file systems and has the BMC power cycle the system, as a last resort when it can
+//	no longer reboot cleanly.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiServicesIPMIPowerCycle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	srv, ok := s.loadIPMI(w, r)
+	if !ok {
+		return
+	}
+
+	err := srv.PowerCycle(r.Context())
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// loadIPMI loads the IPMI service, rendering any error.
+func (s *Server) loadIPMI(w http.ResponseWriter, r *http.Request) (*services.IPMI, bool) {
+	// Check if the service is valid.
+	if !slices.Contains(services.Supported(s.state), "ipmi") {
+		_ = response.NotFound(nil).Render(w)
+
+		return nil, false
+	}
+
+	srv, err := services.Load(r.Context(), s.state, "ipmi")
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return nil, false
+	}
+
+	ipmi, ok := srv.(*services.IPMI)
+	if !ok {
+		_ = response.InternalError(errors.New("unexpected IPMI service type")).Render(w)
+
+		return nil, false
+	}
+
+	return ipmi, true
+}
+
+// swagger:operation POST /1.0/services/redfish/:power services services_post_redfish_power
+//
+//	Trigger a power action through a Redfish endpoint
+//
+//	Has a BMC power on, power off or reset the system it manages.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: power
+//	    description: Power request
+//	    required: true
+//	    schema:
+//	      type: object
+//	      example: {"endpoint":"server01","action":"GracefulRestart"}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiServicesRedfishPower(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	srv, ok := s.loadRedfish(w, r)
+	if !ok {
+		return
+	}
+
+	req := &api.ServiceRedfishPower{}
+
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	err = srv.Power(r.Context(), req.Endpoint, req.Action)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/services/redfish/:insert-media services services_post_redfish_insert_media
+//
+//	Insert a virtual media image through a Redfish endpoint
+//
+//	Has a BMC insert an image in its virtual CD or DVD drive, optionally booting from it once.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: media
+//	    description: Media insertion request
+//	    required: true
+//	    schema:
+//	      type: object
+//	      example: {"endpoint":"server01","image":"https://images.example.com/IncusOS.iso","boot":true}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiServicesRedfishInsertMedia(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	srv, ok := s.loadRedfish(w, r)
+	if !ok {
+		return
+	}
+
+	req := &api.ServiceRedfishMediaInsert{}
+
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	err = srv.InsertMedia(r.Context(), req.Endpoint, req.Image, req.Boot)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/services/redfish/:eject-media services services_post_redfish_eject_media
+//
+//	Eject the virtual media images through a Redfish endpoint
+//
+//	Has a BMC eject all the inserted virtual media images.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: media
+//	    description: Media ejection request
+//	    required: true
+//	    schema:
+//	      type: object
+//	      example: {"endpoint":"server01"}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiServicesRedfishEjectMedia(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	srv, ok := s.loadRedfish(w, r)
+	if !ok {
+		return
+	}
+
+	req := &api.ServiceRedfishMediaEject{}
+
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	err = srv.EjectMedia(r.Context(), req.Endpoint)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// loadRedfish loads the Redfish service, rendering any error.
+func (s *Server) loadRedfish(w http.ResponseWriter, r *http.Request) (*services.Redfish, bool) {
+	srv, err := services.Load(r.Context(), s.state, "redfish")
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return nil, false
+	}
+
+	redfish, ok := srv.(*services.Redfish)
+	if !ok {
+		_ = response.InternalError(errors.New("unexpected Redfish service type")).Render(w)
+
+		return nil, false
+	}
+
+	return redfish, true
+}
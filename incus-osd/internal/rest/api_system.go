@@ -40,7 +40,7 @@ import (
 //	          description: List of system endpoints
 //	          items:
 //	            type: string
-//	          example: ["/1.0/system/logging","/1.0/system/network","/1.0/system/provider","/1.0/system/resources","/1.0/system/security","/1.0/system/storage","/1.0/system/update"]
+//	          example: ["/1.0/system/firmware","/1.0/system/logging","/1.0/system/network","/1.0/system/provider","/1.0/system/resources","/1.0/system/security","/1.0/system/storage","/1.0/system/update","/1.0/system/watchdog"]
 func (*Server) apiSystem(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -54,7 +54,7 @@ func (*Server) apiSystem(w http.ResponseWriter, r *http.Request) {
 
 	urls := []string{}
 
-	for _, system := range []string{"logging", "network", "provider", "resources", "security", "storage", "update"} {
+	for _, system := range []string{"firmware", "logging", "network", "provider", "resources", "security", "storage", "update", "watchdog"} {
 		systemURL, _ := url.JoinPath(endpoint, system)
 		urls = append(urls, systemURL)
 	}
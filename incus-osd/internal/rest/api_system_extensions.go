@@ -0,0 +1,296 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/extensions"
+	"github.com/lxc/incus-os/incus-osd/internal/rest/response"
+)
+
+// swagger:operation GET /1.0/system/extensions system system_get_extensions
+//
+//	Get side-loaded system extensions
+//
+//	Returns the side-loaded system extensions, whether they're active and why they were last deactivated.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: State of the side-loaded system extensions
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          description: Response type
+//	          example: sync
+//	          type: string
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: json
+//	          description: State of the side-loaded system extensions
+//	          example: {"state":{"extensions":[{"name":"vendor-storage","sha256":"4f1b7a3b9e0c6d2f8a5e1c3d7b9f0a2e4c6d8b0a1f3e5c7d9b2a4f6e8c0d1b3a","size":26214400,"active":true,"uploaded_at":"2025-11-04T16:21:34Z"}]}}
+func (s *Server) apiSystemExtensions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	if s.state.System.Extensions.State.Extensions == nil {
+		s.state.System.Extensions.State.Extensions = []api.SystemExtension{}
+	}
+
+	_ = response.SyncResponse(true, s.state.System.Extensions).Render(w)
+}
+
+// swagger:operation GET /1.0/system/extensions/{name} system system_get_extension
+//
+//	Get a side-loaded system extension
+//
+//	Returns the state of a side-loaded system extension.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: path
+//	    name: name
+//	    description: System extension name
+//	    required: true
+//	    type: string
+//	responses:
+//	  "200":
+//	    description: State of the system extension
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          description: Response type
+//	          example: sync
+//	          type: string
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: json
+//	          description: State of the system extension
+//	          example: {"name":"vendor-storage","sha256":"4f1b7a3b9e0c6d2f8a5e1c3d7b9f0a2e4c6d8b0a1f3e5c7d9b2a4f6e8c0d1b3a","size":26214400,"active":true,"uploaded_at":"2025-11-04T16:21:34Z"}
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+
+// swagger:operation PUT /1.0/system/extensions/{name} system system_put_extension
+//
+//	Upload a system extension
+//
+//	Uploads a new or replacement system extension image, which must be signed by a certificate trusted
+//	through Secure Boot. A new extension must then be activated, while a replaced one stays active.
+//
+//	Remember to properly set the `Content-Type: application/octet-stream` HTTP header.
+//
+//	---
+//	consumes:
+//	  - application/octet-stream
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: path
+//	    name: name
+//	    description: System extension name
+//	    required: true
+//	    type: string
+//	  - in: body
+//	    name: image
+//	    description: System extension image
+//	    required: true
+//	    schema:
+//	      type: file
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+
+// swagger:operation DELETE /1.0/system/extensions/{name} system system_delete_extension
+//
+//	Delete a system extension
+//
+//	Deactivates a side-loaded system extension and removes its image.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: path
+//	    name: name
+//	    description: System extension name
+//	    required: true
+//	    type: string
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemExtensionsEndpoint(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	name := r.PathValue("name")
+
+	switch r.Method {
+	case http.MethodGet:
+		idx := slices.IndexFunc(s.state.System.Extensions.State.Extensions, func(ext api.SystemExtension) bool {
+			return ext.Name == name
+		})
+		if idx < 0 {
+			_ = response.NotFound(nil).Render(w)
+
+			return
+		}
+
+		_ = response.SyncResponse(true, s.state.System.Extensions.State.Extensions[idx]).Render(w)
+	case http.MethodPut:
+		err := extensions.Upload(r.Context(), s.state, name, r.Body)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		_ = response.EmptySyncResponse.Render(w)
+	case http.MethodDelete:
+		err := extensions.Delete(r.Context(), s.state, name)
+		if err != nil {
+			if errors.Is(err, extensions.ErrNotFound) {
+				_ = response.NotFound(err).Render(w)
+
+				return
+			}
+
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+
+		_ = response.EmptySyncResponse.Render(w)
+	default:
+		// If none of the supported methods, return NotImplemented.
+		_ = response.NotImplemented(nil).Render(w)
+	}
+}
+
+// swagger:operation POST /1.0/system/extensions/{name}/:activate system system_post_extension_activate
+//
+//	Activate a system extension
+//
+//	Validates the system extension image again and merges it into the running system. Active
+//	extensions are validated on every boot, remaining in use across OS updates.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: path
+//	    name: name
+//	    description: System extension name
+//	    required: true
+//	    type: string
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+func (s *Server) apiSystemExtensionsActivate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	err := extensions.Activate(r.Context(), s.state, r.PathValue("name"))
+	if err != nil {
+		if errors.Is(err, extensions.ErrNotFound) {
+			_ = response.NotFound(err).Render(w)
+
+			return
+		}
+
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/system/extensions/{name}/:deactivate system system_post_extension_deactivate
+//
+//	Deactivate a system extension
+//
+//	Removes a system extension from the running system, keeping its image so it can be activated again.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: path
+//	    name: name
+//	    description: System extension name
+//	    required: true
+//	    type: string
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemExtensionsDeactivate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	err := extensions.Deactivate(r.Context(), s.state, r.PathValue("name"))
+	if err != nil {
+		if errors.Is(err, extensions.ErrNotFound) {
+			_ = response.NotFound(err).Render(w)
+
+			return
+		}
+
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
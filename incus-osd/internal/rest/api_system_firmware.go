@@ -0,0 +1,251 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/rest/response"
+	"github.com/lxc/incus-os/incus-osd/internal/systemd"
+)
+
+// swagger:operation GET /1.0/system/firmware system system_get_firmware
+//
+//	Get firmware update information
+//
+//	Returns the devices whose firmware can be updated and their available updates, as of the last update
+//	check, along with the firmware update configuration.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: State and configuration for the firmware updates
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          description: Response type
+//	          example: sync
+//	          type: string
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: json
+//	          description: State and configuration for the firmware updates
+//	          example: {"config":{"enabled":true,"auto_apply":false},"state":{"last_check":"2025-11-04T16:21:34.929524792Z","status":"Firmware update check completed","devices":[{"id":"71b677ca0f1bc2c5b804fa1d59e52064ce589293","name":"Samsung SSD 980 PRO 1TB","vendor":"Samsung","version":"5B2QGXA7","needs_reboot":false,"updates":[{"version":"5B2QGXA8","summary":"Firmware for the Samsung 980 PRO","urgency":"high"}]}],"pending_devices":[]}}
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/system/firmware system system_put_firmware
+//
+//	Update firmware update configuration
+//
+//	Updates the firmware update configuration, used by the following update checks.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: configuration
+//	    description: Firmware update configuration
+//	    required: true
+//	    schema:
+//	      type: object
+//	      properties:
+//	        config:
+//	          type: object
+//	          description: The firmware update configuration
+//	          example: {"enabled":true,"auto_apply":false}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemFirmware(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		// Initialize the lists if missing.
+		if s.state.System.Firmware.State.Devices == nil {
+			s.state.System.Firmware.State.Devices = []api.SystemFirmwareDevice{}
+		}
+
+		if s.state.System.Firmware.State.PendingDevices == nil {
+			s.state.System.Firmware.State.PendingDevices = []string{}
+		}
+
+		_ = response.SyncResponse(true, s.state.System.Firmware).Render(w)
+	case http.MethodPut:
+		firmwareData := &api.SystemFirmware{}
+
+		err := json.NewDecoder(r.Body).Decode(firmwareData)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		// Forget about the devices and the requested updates when disabled.
+		if !firmwareData.Config.Enabled {
+			s.state.System.Firmware.State = api.SystemFirmwareState{}
+		}
+
+		s.state.System.Firmware.Config = firmwareData.Config
+
+		_ = s.state.Save()
+
+		_ = response.EmptySyncResponse.Render(w)
+	default:
+		// If none of the supported methods, return NotImplemented.
+		_ = response.NotImplemented(nil).Render(w)
+	}
+}
+
+// swagger:operation POST /1.0/system/firmware/:apply system system_post_firmware_apply
+//
+//	Apply firmware updates
+//
+//	Requests the available firmware updates to be applied, either to the listed devices or to all of them.
+//	The updates are applied right away within a maintenance window, or during the next update check in
+//	one otherwise, and the system reboots to finalize them according to the update reboot policy.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: devices
+//	    description: Devices to update
+//	    required: false
+//	    schema:
+//	      type: object
+//	      example: {"devices":["71b677ca0f1bc2c5b804fa1d59e52064ce589293"]}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemFirmwareApply(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	req := &api.SystemFirmwareApply{}
+
+	counter := &countWrapper{ReadCloser: r.Body}
+
+	err := json.NewDecoder(counter).Decode(req)
+	if err != nil && counter.n > 0 {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	if !s.state.System.Firmware.Config.Enabled {
+		_ = response.BadRequest(errors.New("firmware updates aren't enabled")).Render(w)
+
+		return
+	}
+
+	// Don't race with updates being applied.
+	s.state.UpdateMutex.Lock()
+	defer s.state.UpdateMutex.Unlock()
+
+	// Only accept devices with an available update, defaulting to all of them.
+	available := []string{}
+
+	for _, device := range s.state.System.Firmware.State.Devices {
+		if len(device.Updates) > 0 {
+			available = append(available, device.ID)
+		}
+	}
+
+	devices := req.Devices
+	if len(devices) == 0 {
+		devices = available
+	}
+
+	if len(devices) == 0 {
+		_ = response.BadRequest(errors.New("no firmware update available")).Render(w)
+
+		return
+	}
+
+	for _, device := range devices {
+		if !slices.Contains(available, device) {
+			_ = response.BadRequest(fmt.Errorf("no firmware update available for device %q", device)).Render(w)
+
+			return
+		}
+
+		if !slices.Contains(s.state.System.Firmware.State.PendingDevices, device) {
+			s.state.System.Firmware.State.PendingDevices = append(s.state.System.Firmware.State.PendingDevices, device)
+		}
+	}
+
+	_ = s.state.Save()
+
+	slog.InfoContext(r.Context(), "Firmware updates requested", "devices", devices, "identity", getIdentity(r))
+
+	// Apply the updates now if within a maintenance window, like the update checks.
+	inMaintenanceWindow := len(s.state.System.Update.Config.MaintenanceWindows) == 0
+	for _, window := range s.state.System.Update.Config.MaintenanceWindows {
+		if window.IsCurrentlyActive() {
+			inMaintenanceWindow = true
+
+			break
+		}
+	}
+
+	if inMaintenanceWindow && !s.state.System.Update.Config.Hold.IsActive(time.Now()) {
+		ctx := context.WithoutCancel(r.Context())
+
+		go func() {
+			s.state.UpdateMutex.Lock()
+			defer s.state.UpdateMutex.Unlock()
+
+			s.state.System.Firmware.State.Status = "Applying firmware updates"
+
+			err := systemd.ApplyFirmwareUpdates(ctx, s.state)
+			if err != nil {
+				s.state.System.Firmware.State.Status = "Failed to apply firmware updates"
+				slog.ErrorContext(ctx, s.state.System.Firmware.State.Status, "err", err.Error())
+			} else {
+				s.state.System.Firmware.State.Status = "Firmware updates applied"
+			}
+
+			_ = s.state.Save()
+		}()
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
@@ -0,0 +1,210 @@
+package rest
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/kernel"
+	"github.com/lxc/incus-os/incus-osd/internal/rest/response"
+	"github.com/lxc/incus-os/incus-osd/internal/secureboot"
+)
+
+// swagger:operation GET /1.0/system/kernel system system_get_kernel
+//
+//	Get kernel information
+//
+//	Returns the additional kernel command line parameters currently in use and those in use after
+//	the next reboot, if different, along with the full kernel command line, the certificate signing
+//	the parameters and the kernel configuration.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: State and configuration for the kernel
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          description: Response type
+//	          example: sync
+//	          type: string
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: json
+//	          description: State and configuration for the kernel
+//	          example: {"config":{"command_line":["hugepagesz=1G","hugepages=16","isolcpus=2-7"]},"state":{"command_line":["hugepagesz=1G","hugepages=16"],"pending":["hugepagesz=1G","hugepages=16","isolcpus=2-7"],"effective":["console=tty1","hugepagesz=1G","hugepages=16"],"signing_certificate":"-----BEGIN CERTIFICATE-----\n...","signing_certificate_enrolled":true}}
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/system/kernel system system_put_kernel
+//
+//	Update kernel configuration
+//
+//	Updates the additional kernel command line parameters, applied by the boot loader on the next reboot.
+//	Under Secure Boot, the signing certificate must first be enrolled into the Secure Boot db.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: configuration
+//	    description: Kernel configuration
+//	    required: true
+//	    schema:
+//	      type: object
+//	      properties:
+//	        config:
+//	          type: object
+//	          description: The kernel configuration
+//	          example: {"command_line":["hugepagesz=1G","hugepages=16","isolcpus=2-7"]}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemKernel(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		// Return the current kernel state.
+		commandLine, err := kernel.GetCmdline(s.state.OS.Name, s.state.OS.RunningRelease)
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+
+		effective, err := kernel.GetFullCmdline()
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+
+		signingCert, err := kernel.GetCmdlineSigningCertificate()
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+
+		// Systems without Secure Boot variables simply report the certificate as not enrolled.
+		signingCertEnrolled, _ := isCmdlineSigningCertificateEnrolled(signingCert)
+
+		s.state.System.Kernel.State.CommandLine = commandLine
+		s.state.System.Kernel.State.Effective = effective
+		s.state.System.Kernel.State.SigningCertificate = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: signingCert.Raw}))
+		s.state.System.Kernel.State.SigningCertificateEnrolled = signingCertEnrolled
+		s.state.System.Kernel.State.Pending = nil
+
+		if !slices.Equal(commandLine, s.state.System.Kernel.Config.CommandLine) {
+			s.state.System.Kernel.State.Pending = append([]string{}, s.state.System.Kernel.Config.CommandLine...)
+		}
+
+		_ = response.SyncResponse(true, s.state.System.Kernel).Render(w)
+	case http.MethodPut:
+		kernelData := &api.SystemKernel{}
+
+		err := json.NewDecoder(r.Body).Decode(kernelData)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		err = kernel.ValidateCmdline(kernelData.Config.CommandLine)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		// Under Secure Boot, the boot loader only loads the addon if its signing certificate is trusted.
+		if len(kernelData.Config.CommandLine) > 0 {
+			secureBootEnabled, err := secureboot.Enabled()
+			if err != nil {
+				_ = response.InternalError(err).Render(w)
+
+				return
+			}
+
+			if secureBootEnabled {
+				signingCert, err := kernel.GetCmdlineSigningCertificate()
+				if err != nil {
+					_ = response.InternalError(err).Render(w)
+
+					return
+				}
+
+				enrolled, err := isCmdlineSigningCertificateEnrolled(signingCert)
+				if err != nil {
+					_ = response.InternalError(err).Render(w)
+
+					return
+				}
+
+				if !enrolled {
+					_ = response.BadRequest(errors.New("the kernel command line signing certificate must first be enrolled into the Secure Boot db")).Render(w)
+
+					return
+				}
+			}
+		}
+
+		// Apply new configuration.
+		err = kernel.ApplyCmdline(r.Context(), kernelData.Config.CommandLine)
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+
+		// Persist the configuration.
+		s.state.System.Kernel.Config = kernelData.Config
+
+		_ = s.state.Save()
+
+		_ = response.EmptySyncResponse.Render(w)
+	default:
+		// If none of the supported methods, return NotImplemented.
+		_ = response.NotImplemented(nil).Render(w)
+	}
+}
+
+// isCmdlineSigningCertificateEnrolled returns whether the certificate signing the kernel command line addon
+// is trusted by the Secure Boot db.
+func isCmdlineSigningCertificateEnrolled(cert *x509.Certificate) (bool, error) {
+	dbCerts, err := secureboot.GetCertificatesFromVar("db")
+	if err != nil {
+		return false, err
+	}
+
+	for _, dbCert := range dbCerts {
+		if dbCert.Equal(cert) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
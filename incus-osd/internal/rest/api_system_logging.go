@@ -2,9 +2,13 @@ package rest
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/audit"
 	"github.com/lxc/incus-os/incus-osd/internal/rest/response"
 	"github.com/lxc/incus-os/incus-osd/internal/systemd"
 )
@@ -108,3 +112,99 @@ func (s *Server) apiSystemLogging(w http.ResponseWriter, r *http.Request) {
 
 	_ = s.state.Save()
 }
+
+// swagger:operation GET /1.0/system/logging/audit system system_get_logging_audit
+//
+//	Get the audit log
+//
+//	Returns the audit log of API calls which may have changed the system, optionally filtered
+//	to entries recorded after the RFC3339 "since" timestamp and limited to the most recent "entries".
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: since
+//	    description: Only return entries recorded after this RFC3339 timestamp
+//	    type: string
+//	    example: 2025-10-01T00:00:00Z
+//	  - in: query
+//	    name: entries
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	responses:
+//	  "200":
+//	    description: Audit log entries
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          description: Response type
+//	          example: sync
+//	          type: string
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: json
+//	          description: Audit log entries
+//	          example: [{"timestamp":"2025-10-15T09:12:44Z","identity":"3f2a9c1b7e4d0a5b6c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b","method":"PUT","path":"/1.0/system/security","status_code":200,"changes":[{"field":"system.security.config.encryption_recovery_keys[0]","old_value":"<redacted>","new_value":"<redacted>"}]}]
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (*Server) apiSystemLoggingAudit(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	var since time.Time
+
+	if r.Form.Get("since") != "" {
+		since, err = time.Parse(time.RFC3339, r.Form.Get("since"))
+		if err != nil {
+			_ = response.BadRequest(errors.New("invalid since timestamp")).Render(w)
+
+			return
+		}
+	}
+
+	numEntries := 0
+
+	if r.Form.Get("entries") != "" {
+		numEntries, err = strconv.Atoi(r.Form.Get("entries"))
+		if err != nil || numEntries < 0 {
+			_ = response.BadRequest(errors.New("invalid number of entries")).Render(w)
+
+			return
+		}
+	}
+
+	entries, err := audit.List(since, numEntries)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.SyncResponse(true, entries).Render(w)
+}
@@ -123,6 +123,13 @@ func (s *Server) apiSystemNetwork(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// Don't allow replacing a configuration which is still pending confirmation.
+		if s.state.NetworkRollbackConfig != nil {
+			_ = response.BadRequest(errors.New("network configuration is pending confirmation")).Render(w)
+
+			return
+		}
+
 		slog.InfoContext(r.Context(), "Applying new network configuration")
 
 		err = nftables.ApplyHwaddrFilters(r.Context(), newConfig.Config)
@@ -148,3 +155,120 @@ func (s *Server) apiSystemNetwork(w http.ResponseWriter, r *http.Request) {
 		_ = response.NotImplemented(nil).Render(w)
 	}
 }
+
+// swagger:operation POST /1.0/system/network/:try system system_post_network_try
+//
+//	Try a new system network configuration
+//
+//	Applies a new system network configuration which must be confirmed within the given timeout, otherwise the previous configuration is automatically restored.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: configuration
+//	    description: Network configuration to try
+//	    required: true
+//	    schema:
+//	      type: object
+//	      properties:
+//	        config:
+//	          type: object
+//	          description: The network configuration
+//	          example: {"interfaces":[{"name":"enp5s0","addresses":["dhcp4"],"required_for_online":"yes","hwaddr":"10:66:6a:1a:20:0f"}]}
+//	        timeout:
+//	          type: integer
+//	          description: Number of seconds to wait for a confirmation, defaults to 60
+//	          example: 120
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemNetworkTry(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	tryConfig := &api.SystemNetworkTry{}
+
+	err := json.NewDecoder(r.Body).Decode(tryConfig)
+	if err != nil {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	// Don't allow a new configuration that doesn't define any interfaces, bonds, or vlans.
+	if tryConfig.Config == nil || seed.NetworkConfigHasEmptyDevices(*tryConfig.Config) {
+		_ = response.BadRequest(errors.New("network configuration has no devices defined")).Render(w)
+
+		return
+	}
+
+	if tryConfig.Timeout < 0 {
+		_ = response.BadRequest(errors.New("timeout can't be negative")).Render(w)
+
+		return
+	}
+
+	if tryConfig.Timeout == 0 {
+		tryConfig.Timeout = 60
+	}
+
+	slog.InfoContext(r.Context(), "Trying new network configuration", "timeout", tryConfig.Timeout)
+
+	err = systemd.TryNetworkConfiguration(r.Context(), s.state, tryConfig.Config, 30*time.Second, time.Duration(tryConfig.Timeout)*time.Second, providers.Refresh)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to try network configuration: "+err.Error())
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/system/network/:confirm system system_post_network_confirm
+//
+//	Confirm the system network configuration
+//
+//	Confirms a network configuration previously applied through the "try" endpoint, preventing it from being reverted.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+func (s *Server) apiSystemNetworkConfirm(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	err := systemd.ConfirmNetworkConfiguration(s.state)
+	if err != nil {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Network configuration confirmed")
+
+	_ = response.EmptySyncResponse.Render(w)
+}
@@ -42,7 +42,7 @@ import (
 //	        metadata:
 //	          type: json
 //	          description: State and configuration for the system provider
-//	          example: {"config":{"name":"images","config":null},"state":{"registered":false}}
+//	          example: {"config":{"name":"operations-center","config":{"server_url":"https://oc.example.net"},"fallbacks":[{"name":"images"}]},"state":{"registered":true,"health":[{"name":"operations-center","healthy":false,"last_check":"2025-10-27T20:25:00Z","last_error":"connection refused"},{"name":"images","healthy":true,"last_check":"2025-10-27T20:25:01Z"}]}}
 
 // swagger:operation PUT /1.0/system/provider system system_put_provider
 //
@@ -66,7 +66,7 @@ import (
 //	        config:
 //	          type: object
 //	          description: The provider configuration
-//	          example: {"name":"images","config":null}
+//	          example: {"name":"images","config":null,"fallbacks":[{"name":"bundle"}]}
 //	responses:
 //	  "200":
 //	    $ref: "#/responses/EmptySyncResponse"
@@ -114,8 +114,9 @@ func (s *Server) apiSystemProvider(w http.ResponseWriter, r *http.Request) {
 			s.state.System.Provider.State.Registered = false
 		}
 
-		// Apply the updated configuration.
+		// Apply the updated configuration, resetting the health of the previous providers.
 		s.state.System.Provider.Config = newConfig.Config
+		s.state.System.Provider.State.Health = nil
 
 		// Load the new provider.
 		p, err := providers.Load(r.Context(), s.state)
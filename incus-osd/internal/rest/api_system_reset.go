@@ -2,6 +2,7 @@ package rest
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/lxc/incus-os/incus-osd/api"
@@ -13,7 +14,11 @@ import (
 //
 //	Perform a factory reset of the system
 //
-//	Factory reset the entire system and immediately reboot. This is a DESTRUCTIVE action and will wipe all installed applications, configuration, and the "local" ZFS datapool.
+//	Factory reset the entire system and immediately reboot. This is a DESTRUCTIVE action and will wipe all installed applications, configuration, and unless "keep_data" is set, the "local" ZFS datapool.
+//
+//	The reset must be confirmed. A request with "request_confirmation" set returns a single-use token, valid for five minutes, which must then be provided as "confirmation_token" in a second request to actually reset the system. Setting "skip_confirmation" resets the system right away instead.
+//
+//	Keeping the "local" ZFS datapool requires its encryption key to have been retrieved first, as the key is lost during the reset.
 //
 //	---
 //	produces:
@@ -25,15 +30,35 @@ import (
 //	    required: false
 //	    schema:
 //	      type: object
-//	      example: {"allow_tpm_reset_failure":false,"wipe_existing_seeds":true,"seeds":{"incus":{"apply_defaults":true}}}
+//	      example: {"allow_tpm_reset_failure":false,"wipe_existing_seeds":true,"seeds":{"incus":{"apply_defaults":true}},"keep_data":false,"request_confirmation":false,"confirmation_token":"NWV6KVCSPGJRDTZKL3WK43MZ4A","skip_confirmation":false}
 //	responses:
 //	  "200":
-//	    $ref: "#/responses/EmptySyncResponse"
+//	    description: Confirmation token if requested, otherwise an empty response
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          description: Response type
+//	          example: sync
+//	          type: string
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: json
+//	          description: Confirmation token
+//	          example: {"token":"NWV6KVCSPGJRDTZKL3WK43MZ4A","expires_at":"2025-10-27T20:30:00Z"}
 //	  "400":
 //	    $ref: "#/responses/BadRequest"
 //	  "500":
 //	    $ref: "#/responses/InternalServerError"
-func (*Server) apiSystemFactoryReset(w http.ResponseWriter, r *http.Request) {
+func (s *Server) apiSystemFactoryReset(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	if r.Method != http.MethodPost {
@@ -53,6 +78,35 @@ func (*Server) apiSystemFactoryReset(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The encryption key of the "local" pool is lost during the reset, so make sure it can be imported afterwards.
+	if resetData.KeepData && !s.state.System.Security.State.EncryptionRecoveryKeysRetrieved {
+		_ = response.BadRequest(errors.New("the encryption recovery keys must be retrieved before keeping the local storage pool")).Render(w)
+
+		return
+	}
+
+	// Return a token to confirm the reset with.
+	if resetData.RequestConfirmation {
+		_ = response.SyncResponse(true, s.resetConfirm.Issue()).Render(w)
+
+		return
+	}
+
+	if !resetData.SkipConfirmation {
+		if resetData.ConfirmationToken == "" {
+			_ = response.BadRequest(errors.New("a confirmation token is required, request one by setting request_confirmation")).Render(w)
+
+			return
+		}
+
+		err = s.resetConfirm.Check(resetData.ConfirmationToken)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+	}
+
 	err = reset.PerformOSFactoryReset(r.Context(), resetData)
 	if err != nil {
 		_ = response.InternalError(err).Render(w)
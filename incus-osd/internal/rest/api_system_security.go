@@ -3,13 +3,28 @@ package rest
 import (
 	"encoding/json"
 	"errors"
+	"log/slog"
 	"net/http"
+	"reflect"
 	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/apparmor"
+	"github.com/lxc/incus-os/incus-osd/internal/applications"
+	"github.com/lxc/incus-os/incus-osd/internal/kernel"
+	"github.com/lxc/incus-os/incus-osd/internal/listeners"
+	"github.com/lxc/incus-os/incus-osd/internal/nftables"
+	"github.com/lxc/incus-os/incus-osd/internal/providers"
 	"github.com/lxc/incus-os/incus-osd/internal/rest/response"
+	"github.com/lxc/incus-os/incus-osd/internal/sbom"
+	"github.com/lxc/incus-os/incus-osd/internal/secrets"
 	"github.com/lxc/incus-os/incus-osd/internal/secureboot"
 	"github.com/lxc/incus-os/incus-osd/internal/systemd"
+	"github.com/lxc/incus-os/incus-osd/internal/truststore"
+	"github.com/lxc/incus-os/incus-osd/internal/usb"
 	"github.com/lxc/incus-os/incus-osd/internal/zfs"
 )
 
@@ -57,6 +72,8 @@ import (
 //	Some other simple complexity checks are applied, and any key that doesn't pass will
 //	be rejected with an error.
 //
+//	Also updates the list of Tang servers the encrypted volumes are bound to.
+//
 //	---
 //	consumes:
 //	  - application/json
@@ -73,7 +90,7 @@ import (
 //	        config:
 //	          type: object
 //	          description: The security configuration
-//	          example: {"encryption_recovery_keys":["my-super-secret-passphrase"]}
+//	          example: {"encryption_recovery_keys":["my-super-secret-passphrase"],"nbde":{"servers":[{"url":"http://tang.example.com","thumbprint":"Dr2ESfXm9LDQk8hz0bVMNqhBFnM"}],"threshold":1}}
 //	responses:
 //	  "200":
 //	    $ref: "#/responses/EmptySyncResponse"
@@ -118,6 +135,66 @@ func (s *Server) apiSystemSecurity(w http.ResponseWriter, r *http.Request) {
 
 		s.state.System.Security.State.SystemStateIsTrusted = !secureboot.IsTrustedFuseBlown()
 
+		// Get recovery key metadata and the key slots in use.
+		s.state.System.Security.State.EncryptionRecoveryKeysMetadata = systemd.GetRecoveryKeysMetadata(s.state)
+
+		s.state.System.Security.State.EncryptionKeySlots, err = systemd.ListEncryptionKeySlots(r.Context())
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+
+		// Get enrolled FIDO2 tokens.
+		s.state.System.Security.State.FIDO2Tokens, err = systemd.ListFIDO2Tokens(r.Context())
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+
+		// Get the client certificates trusted by the primary application.
+		s.state.System.Security.State.TrustedCertificates, err = s.getTrustedCertificates(r)
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+
+		// Get the attached USB devices.
+		s.state.System.Security.State.USBDevices, err = usb.ListDevices()
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+
+		// Get the IMA measurement state.
+		s.state.System.Security.State.IMAStatus = secureboot.GetIMAStatus()
+		s.state.System.Security.State.IMAMeasurements = secureboot.GetIMAMeasurementCount()
+
+		// Get the kernel lockdown and module loading state.
+		s.state.System.Security.State.KernelLockdown = kernel.GetLockdown()
+		s.state.System.Security.State.KernelModulesDisabled = kernel.ModulesDisabled()
+
+		// Get the AppArmor profiles status and their recent denials.
+		s.state.System.Security.State.AppArmorProfiles = apparmor.ListProfiles()
+
+		s.state.System.Security.State.AppArmorDenials, err = apparmor.ListDenials(r.Context())
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+
+		// Get the names of the locally stored secrets.
+		s.state.System.Security.State.Secrets, err = secrets.ListLocal()
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+
 		// Return the current system security state.
 		_ = response.SyncResponse(true, s.state.System.Security).Render(w)
 	case http.MethodPut:
@@ -139,10 +216,115 @@ func (s *Server) apiSystemSecurity(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		err = systemd.ValidateNBDEConfiguration(securityStruct.Config.NBDE)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		err = secureboot.ValidateTPMBinding(securityStruct.Config.TPMBinding)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		err = applications.ValidateOIDCConfiguration(securityStruct.Config.OIDC)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		err = applications.ValidateACMEConfiguration(securityStruct.Config.ACME)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		err = ValidateAccessControl(securityStruct.Config.AccessControl)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		err = nftables.ValidateHostFirewall(securityStruct.Config.Firewall)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		err = usb.ValidatePolicy(securityStruct.Config.USB)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		err = secrets.ValidateConfig(securityStruct.Config.Secrets)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		err = truststore.Validate(securityStruct.Config.CACertificates)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		err = apparmor.ValidateConfig(securityStruct.Config.AppArmor)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		err = sbom.ValidateVulnerabilityFeed(securityStruct.Config.VulnerabilityFeed)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		err = kernel.ValidateConfig(securityStruct.Config.Kernel)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		err = providers.ValidateUpdateSigning(securityStruct.Config.UpdateSigning)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		// Access control relies on the proxy identifying the callers.
+		if securityStruct.Config.AccessControl != nil && getCaller(r).proxied && getIdentity(r) == "" {
+			_ = response.BadRequest(errors.New("access control can't be enabled as the proxy doesn't provide the identity of the current caller")).Render(w)
+
+			return
+		}
+
+		// Prevent callers from locking themselves out.
+		if getCallerRole(securityStruct.Config.AccessControl, getCaller(r), getIdentity(r)) != api.SystemSecurityRoleAdmin {
+			_ = response.BadRequest(errors.New("access control configuration would remove the admin role from the current caller")).Render(w)
+
+			return
+		}
+
 		// Add any new encryption keys.
 		for _, newKey := range securityStruct.Config.EncryptionRecoveryKeys {
 			if !slices.Contains(s.state.System.Security.Config.EncryptionRecoveryKeys, newKey) {
-				err := systemd.AddEncryptionKey(r.Context(), s.state, newKey)
+				err := systemd.AddEncryptionKey(r.Context(), s.state, newKey, systemd.RecoveryKeyCreatedByUser)
 				if err != nil {
 					_ = response.InternalError(err).Render(w)
 
@@ -163,6 +345,153 @@ func (s *Server) apiSystemSecurity(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		// Update the Tang binding if changed.
+		if !reflect.DeepEqual(securityStruct.Config.NBDE, s.state.System.Security.Config.NBDE) {
+			err := systemd.ApplyNBDEConfiguration(r.Context(), s.state, securityStruct.Config.NBDE)
+			if err != nil {
+				_ = response.InternalError(err).Render(w)
+
+				return
+			}
+		}
+
+		// Update the OIDC configuration of the primary application if changed.
+		if !reflect.DeepEqual(securityStruct.Config.OIDC, s.state.System.Security.Config.OIDC) {
+			app, err := applications.GetPrimary(r.Context(), s.state)
+			if err != nil {
+				_ = response.BadRequest(err).Render(w)
+
+				return
+			}
+
+			err = app.SetOIDCConfiguration(r.Context(), securityStruct.Config.OIDC)
+			if err != nil {
+				_ = response.InternalError(err).Render(w)
+
+				return
+			}
+
+			s.state.System.Security.Config.OIDC = securityStruct.Config.OIDC
+		}
+
+		// Update the ACME configuration of the primary application, and optionally Incus, if changed.
+		acmeChanged := !reflect.DeepEqual(securityStruct.Config.ACME, s.state.System.Security.Config.ACME)
+		if acmeChanged {
+			err := s.applyACMEConfiguration(r, securityStruct.Config.ACME)
+			if err != nil {
+				if errors.Is(err, applications.ErrNoPrimary) {
+					_ = response.BadRequest(err).Render(w)
+
+					return
+				}
+
+				_ = response.InternalError(err).Render(w)
+
+				return
+			}
+
+			s.state.System.Security.Config.ACME = securityStruct.Config.ACME
+		}
+
+		// Update the access control and secrets providers configuration.
+		s.state.System.Security.Config.AccessControl = securityStruct.Config.AccessControl
+		s.state.System.Security.Config.Secrets = securityStruct.Config.Secrets
+		s.state.System.Security.Config.Lockdown = securityStruct.Config.Lockdown
+		s.state.System.Security.Config.VulnerabilityFeed = securityStruct.Config.VulnerabilityFeed
+		s.state.System.Security.Config.UpdateSigning = securityStruct.Config.UpdateSigning
+
+		// Update the host firewall if changed, or if the port used by ACME HTTP-01 challenges may have.
+		if acmeChanged || !reflect.DeepEqual(securityStruct.Config.Firewall, s.state.System.Security.Config.Firewall) {
+			s.state.System.Security.Config.Firewall = securityStruct.Config.Firewall
+
+			err := nftables.ApplyHostFirewall(r.Context(), s.state)
+			if err != nil {
+				_ = response.InternalError(err).Render(w)
+
+				return
+			}
+		}
+
+		// Update the USB device authorization policy if changed.
+		if !reflect.DeepEqual(securityStruct.Config.USB, s.state.System.Security.Config.USB) {
+			err := usb.ApplyPolicy(r.Context(), securityStruct.Config.USB)
+			if err != nil {
+				_ = response.InternalError(err).Render(w)
+
+				return
+			}
+
+			s.state.System.Security.Config.USB = securityStruct.Config.USB
+		}
+
+		// Update the system trust store if changed.
+		if !reflect.DeepEqual(securityStruct.Config.CACertificates, s.state.System.Security.Config.CACertificates) {
+			err := truststore.Apply(securityStruct.Config.CACertificates)
+			if err != nil {
+				_ = response.InternalError(err).Render(w)
+
+				return
+			}
+
+			s.state.System.Security.Config.CACertificates = securityStruct.Config.CACertificates
+		}
+
+		// Update the AppArmor profiles if changed.
+		if !reflect.DeepEqual(securityStruct.Config.AppArmor, s.state.System.Security.Config.AppArmor) {
+			err := apparmor.ApplyProfiles(r.Context(), securityStruct.Config.AppArmor)
+			if err != nil {
+				_ = response.InternalError(err).Render(w)
+
+				return
+			}
+
+			s.state.System.Security.Config.AppArmor = securityStruct.Config.AppArmor
+		}
+
+		// Update the kernel lockdown and module loading policy if changed.
+		if !reflect.DeepEqual(securityStruct.Config.Kernel, s.state.System.Security.Config.Kernel) {
+			err := kernel.ApplyModuleDenials(securityStruct.Config.Kernel)
+			if err != nil {
+				_ = response.InternalError(err).Render(w)
+
+				return
+			}
+
+			err = kernel.ApplyRestrictions(r.Context(), securityStruct.Config.Kernel)
+			if err != nil {
+				_ = response.InternalError(err).Render(w)
+
+				return
+			}
+
+			s.state.System.Security.Config.Kernel = securityStruct.Config.Kernel
+		}
+
+		// Enable IMA measurement if requested. The kernel policy can't be unloaded, so disabling
+		// only takes effect after a reboot.
+		if securityStruct.Config.IMAMeasurement && !s.state.System.Security.Config.IMAMeasurement {
+			err := secureboot.EnableIMAMeasurement()
+			if err != nil {
+				_ = response.InternalError(err).Render(w)
+
+				return
+			}
+		}
+
+		s.state.System.Security.Config.IMAMeasurement = securityStruct.Config.IMAMeasurement
+
+		// Update the TPM binding policy if changed.
+		if !reflect.DeepEqual(securityStruct.Config.TPMBinding, s.state.System.Security.Config.TPMBinding) {
+			err := secureboot.ApplyTPMBinding(r.Context(), s.state.OS.Name, s.state.OS.RunningRelease, s.state.System.Security.Config.EncryptionRecoveryKeys[0], securityStruct.Config.TPMBinding)
+			if err != nil {
+				_ = response.InternalError(err).Render(w)
+
+				return
+			}
+
+			s.state.System.Security.Config.TPMBinding = securityStruct.Config.TPMBinding
+		}
+
 		_ = response.EmptySyncResponse.Render(w)
 	default:
 		// If none of the supported methods, return NotImplemented.
@@ -172,36 +501,1386 @@ func (s *Server) apiSystemSecurity(w http.ResponseWriter, r *http.Request) {
 	_ = s.state.Save()
 }
 
-// swagger:operation POST /1.0/system/security/:tpm-rebind system system_post_security_tpm_rebind
+// swagger:operation GET /1.0/system/security/posture system system_get_security_posture
 //
-//	Reset TPM bindings
+//	Get the security posture report
 //
-//	Forcibly resets TPM encryption bindings; intended only for use if it was required to enter a recovery passphrase to boot the system.
+//	Returns a summary of the security posture of the system, aggregating the Secure Boot, TPM and
+//	encryption state, the host firewall status, the listening sockets, the pending updates and the
+//	trusted certificates, for fleet compliance scanning.
 //
 //	---
 //	produces:
 //	  - application/json
 //	responses:
 //	  "200":
-//	    $ref: "#/responses/EmptySyncResponse"
+//	    description: Security posture report
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          description: Response type
+//	          example: sync
+//	          type: string
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: json
+//	          description: Security posture report
+//	          example: {"timestamp":"2025-10-15T09:12:44Z","secure_boot_enabled":true,"tpm_status":"ok","using_swtpm":false,"system_state_is_trusted":true,"encrypted_volumes":[{"volume":"root","state":"unlocked (TPM)"}],"tpm_unlock_warning":false,"firewall":{"enabled":true,"default_policy":"drop","api_sources":["192.0.2.0/24"]},"listeners":[{"protocol":"tcp","address":"::","port":8443,"process":"incusd"}],"updates":{"running_release":"202510150120","next_release":"","needs_reboot":false,"last_check":"2025-10-15T09:00:00Z","status":"Update check completed","applications":{"incus":"202510150120"}},"trusted_certificates":[],"ca_certificates":[]}
 //	  "500":
 //	    $ref: "#/responses/InternalServerError"
-func (s *Server) apiSystemSecurityTPMRebind(w http.ResponseWriter, r *http.Request) {
+func (s *Server) apiSystemSecurityPosture(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	if r.Method != http.MethodPost {
+	if r.Method != http.MethodGet {
 		_ = response.NotImplemented(nil).Render(w)
 
 		return
 	}
 
-	err := secureboot.ForceUpdatePCRBindings(r.Context(), s.state.OS.Name, s.state.OS.RunningRelease, s.state.System.Security.Config.EncryptionRecoveryKeys[0])
+	posture := api.SystemSecurityPosture{
+		Timestamp:            time.Now().UTC().Format(time.RFC3339),
+		TPMStatus:            secureboot.TPMStatus(),
+		UsingSWTPM:           s.state.UsingSWTPM,
+		SystemStateIsTrusted: !secureboot.IsTrustedFuseBlown(),
+		EncryptedVolumes:     s.state.System.Security.State.EncryptedVolumes,
+		TPMUnlockWarning:     s.state.System.Security.State.TPMUnlockWarning,
+		Updates: api.SystemSecurityPostureUpdates{
+			RunningRelease: s.state.OS.RunningRelease,
+			NeedsReboot:    s.state.System.Update.State.NeedsReboot,
+			Status:         s.state.System.Update.State.Status,
+			Applications:   map[string]string{},
+		},
+		CACertificates: []api.SystemSecurityCACertificate{},
+	}
+
+	var err error
+
+	posture.SecureBootEnabled, err = secureboot.Enabled()
 	if err != nil {
 		_ = response.InternalError(err).Render(w)
 
 		return
 	}
 
-	_ = response.EmptySyncResponse.Render(w)
-	_ = s.state.Save()
+	// Get the host firewall status.
+	firewall := s.state.System.Security.Config.Firewall
+	if firewall != nil {
+		posture.Firewall.Enabled = true
+		posture.Firewall.DefaultPolicy = firewall.DefaultPolicy
+		posture.Firewall.APISources = firewall.APISources
+	}
+
+	if posture.Firewall.DefaultPolicy == "" {
+		posture.Firewall.DefaultPolicy = "accept"
+	}
+
+	// Get the sockets accepting network traffic.
+	posture.Listeners, err = listeners.List()
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	// Get the update status.
+	if s.state.OS.NextRelease != s.state.OS.RunningRelease {
+		posture.Updates.NextRelease = s.state.OS.NextRelease
+	}
+
+	if s.state.System.Update.State.RebootScheduledAt != nil {
+		posture.Updates.RebootScheduledAt = s.state.System.Update.State.RebootScheduledAt.UTC().Format(time.RFC3339)
+	}
+
+	if !s.state.System.Update.State.LastCheck.IsZero() {
+		posture.Updates.LastCheck = s.state.System.Update.State.LastCheck.UTC().Format(time.RFC3339)
+	}
+
+	for name, app := range s.state.Applications {
+		posture.Updates.Applications[name] = app.State.Version
+	}
+
+	// Get the trusted certificates, only keeping their fingerprint.
+	posture.TrustedCertificates, err = s.getTrustedCertificates(r)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	for i := range posture.TrustedCertificates {
+		posture.TrustedCertificates[i].Certificate = ""
+	}
+
+	for _, cert := range s.state.System.Security.Config.CACertificates {
+		posture.CACertificates = append(posture.CACertificates, api.SystemSecurityCACertificate{Name: cert.Name, Fingerprint: cert.Fingerprint})
+	}
+
+	_ = response.SyncResponse(true, posture).Render(w)
+}
+
+// swagger:operation GET /1.0/system/security/sbom system system_get_security_sbom
+//
+//	Get the software bill of materials
+//
+//	Returns the packages included in the running OS image along with the installed applications,
+//	optionally checking the packages for known vulnerabilities.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: vulnerabilities
+//	    description: Check the packages against the vulnerability feed
+//	    type: boolean
+//	    example: true
+//	responses:
+//	  "200":
+//	    description: Software bill of materials
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          description: Response type
+//	          example: sync
+//	          type: string
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: json
+//	          description: Software bill of materials
+//	          example: {"os_name":"IncusOS","os_version":"202510150120","packages":[{"component":"base","name":"libssl3t64","version":"3.5.1-1","architecture":"amd64","source_name":"openssl","source_version":"3.5.1-1","vulnerabilities":["CVE-2025-9230"]}],"applications":[{"name":"incus","version":"202510150120"}],"vulnerability_feed":"https://api.osv.dev"}
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemSecuritySBOM(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	checkVulnerabilities := false
+
+	if r.Form.Get("vulnerabilities") != "" {
+		checkVulnerabilities, err = strconv.ParseBool(r.Form.Get("vulnerabilities"))
+		if err != nil {
+			_ = response.BadRequest(errors.New("invalid vulnerabilities value")).Render(w)
+
+			return
+		}
+	}
+
+	bom := api.SystemSecuritySBOM{
+		OSName:       s.state.OS.Name,
+		OSVersion:    s.state.OS.RunningRelease,
+		Applications: []api.SystemSecuritySBOMApplication{},
+	}
+
+	bom.Packages, err = sbom.List()
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	for name, app := range s.state.Applications {
+		bom.Applications = append(bom.Applications, api.SystemSecuritySBOMApplication{Name: name, Version: app.State.Version})
+	}
+
+	slices.SortFunc(bom.Applications, func(a api.SystemSecuritySBOMApplication, b api.SystemSecuritySBOMApplication) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	if checkVulnerabilities {
+		bom.VulnerabilityFeed = s.state.System.Security.Config.VulnerabilityFeed
+		if bom.VulnerabilityFeed == "" {
+			bom.VulnerabilityFeed = sbom.DefaultVulnerabilityFeed
+		}
+
+		err = sbom.CheckVulnerabilities(r.Context(), bom.VulnerabilityFeed, bom.Packages)
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+	}
+
+	_ = response.SyncResponse(true, bom).Render(w)
+}
+
+// swagger:operation POST /1.0/system/security/:attest system system_post_security_attest
+//
+//	Get a TPM attestation
+//
+//	Returns a TPM quote over the requested PCRs (0, 2, 4, 7 and 11 by default), signed by the system's
+//	attestation key and bound to the provided hex-encoded nonce, along with the TPM event log and OS version.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: attestation
+//	    description: Attestation request
+//	    required: true
+//	    schema:
+//	      type: object
+//	      example: {"nonce":"5d3c8e1ad8a36b0f7a7e4f3b1c9d2e60","pcrs":[7,11]}
+//	responses:
+//	  "200":
+//	    description: TPM attestation
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          description: Response type
+//	          example: sync
+//	          type: string
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: json
+//	          description: TPM attestation
+//	          example: {"attestation_key":"-----BEGIN PUBLIC KEY-----\n...","endorsement_key":"-----BEGIN PUBLIC KEY-----\n...","event_log":"AAAAAAMAAAA...","nonce":"5d3c8e1ad8a36b0f7a7e4f3b1c9d2e60","os_name":"IncusOS","os_version":"202510150000","pcrs":{"7":"c3ba5e0b3bd8c1e0d1f4f3e2a0c3e5f08a2b5c1d3e4f5a6b7c8d9e0f1a2b3c4d","11":"8f1a4e9d0c3b2a1f0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8a7f"},"quote":"/1RDR4AYACIAC...","signature":"ABQACwEAk..."}
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemSecurityAttest(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	attestationStruct := &api.SystemSecurityAttestationRequest{}
+
+	counter := &countWrapper{ReadCloser: r.Body}
+
+	err := json.NewDecoder(counter).Decode(attestationStruct)
+	if err != nil && counter.n > 0 {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	err = secureboot.ValidateAttestationRequest(attestationStruct)
+	if err != nil {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	attestation, err := secureboot.GetAttestation(r.Context(), s.state.OS.Name, s.state.OS.RunningRelease, attestationStruct)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.SyncResponse(true, attestation).Render(w)
+}
+
+// swagger:operation POST /1.0/system/security/:clear-tpm-unlock-warning system system_post_security_clear_tpm_unlock_warning
+//
+//	Clear the TPM unlock failure warning
+//
+//	Acknowledges the warning raised after the TPM failed to unlock the encrypted volumes. The failure
+//	counter is kept.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemSecurityClearTPMUnlockWarning(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	s.state.System.Security.State.TPMUnlockWarning = false
+	_ = s.state.Save()
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/system/security/:delete-secret system system_post_security_delete_secret
+//
+//	Delete a local secret
+//
+//	Deletes a secret stored locally on the system.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: secret
+//	    description: Secret to delete
+//	    required: true
+//	    schema:
+//	      type: object
+//	      example: {"name":"kopia-password"}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (*Server) apiSystemSecurityDeleteSecret(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	removeStruct := &api.SystemSecuritySecretRemove{}
+
+	counter := &countWrapper{ReadCloser: r.Body}
+
+	err := json.NewDecoder(counter).Decode(removeStruct)
+	if err != nil && counter.n > 0 {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	err = secrets.DeleteLocal(removeStruct.Name)
+	if err != nil {
+		if errors.Is(err, secrets.ErrNotFound) {
+			_ = response.NotFound(err).Render(w)
+
+			return
+		}
+
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/system/security/:fido2-enroll system system_post_security_fido2_enroll
+//
+//	Enroll a FIDO2 token
+//
+//	Enrolls a FIDO2 token as an additional unlock method for the encrypted volumes. The token must be
+//	plugged into the system and touched once for each encrypted volume during enrollment.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: token
+//	    description: FIDO2 token to enroll
+//	    required: true
+//	    schema:
+//	      type: object
+//	      example: {"device":"auto","pin":"123456","user_verification":false}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemSecurityFIDO2Enroll(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	enrollStruct := &api.SystemSecurityFIDO2Enroll{}
+
+	counter := &countWrapper{ReadCloser: r.Body}
+
+	err := json.NewDecoder(counter).Decode(enrollStruct)
+	if err != nil && counter.n > 0 {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	err = systemd.EnrollFIDO2Token(r.Context(), s.state, enrollStruct)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/system/security/:fido2-remove system system_post_security_fido2_remove
+//
+//	Remove FIDO2 tokens
+//
+//	Removes all enrolled FIDO2 tokens from the encrypted volumes.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemSecurityFIDO2Remove(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	err := systemd.RemoveFIDO2Tokens(r.Context(), s.state)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/system/security/:generate-recovery-key system system_post_security_generate_recovery_key
+//
+//	Generate a recovery key
+//
+//	Generates a new encryption recovery key, enrolled alongside any existing ones.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Generated recovery key
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          description: Response type
+//	          example: sync
+//	          type: string
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: json
+//	          description: Generated recovery key
+//	          example: {"id":"3f2a9c1b7e4d","key":"fkrjjenn-tbtjbjgh-jtvvchjr-ctienevu-crknfkvi-vjlvblhl-kbneribu-htjtldch","created_at":"2025-10-15T10:00:00Z","created_by":"api"}
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemSecurityGenerateRecoveryKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	key, err := systemd.GenerateRecoveryKey(r.Context(), s.state, systemd.RecoveryKeyCreatedByAPI)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	s.renderRecoveryKey(w, key)
+	_ = s.state.Save()
+}
+
+// swagger:operation POST /1.0/system/security/:rotate-recovery-keys system system_post_security_rotate_recovery_keys
+//
+//	Rotate the recovery keys
+//
+//	Generates a new encryption recovery key and invalidates all existing ones.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Generated recovery key
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          description: Response type
+//	          example: sync
+//	          type: string
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: json
+//	          description: Generated recovery key
+//	          example: {"id":"3f2a9c1b7e4d","key":"fkrjjenn-tbtjbjgh-jtvvchjr-ctienevu-crknfkvi-vjlvblhl-kbneribu-htjtldch","created_at":"2025-10-15T10:00:00Z","created_by":"api"}
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemSecurityRotateRecoveryKeys(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	key, err := systemd.RotateRecoveryKeys(r.Context(), s.state)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	s.renderRecoveryKey(w, key)
+	_ = s.state.Save()
+}
+
+// swagger:operation POST /1.0/system/security/:invalidate-recovery-key system system_post_security_invalidate_recovery_key
+//
+//	Invalidate a recovery key
+//
+//	Removes the encryption recovery key with the provided ID. The last remaining key can't be removed.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: key
+//	    description: Recovery key to invalidate
+//	    required: true
+//	    schema:
+//	      type: object
+//	      example: {"id":"3f2a9c1b7e4d"}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemSecurityInvalidateRecoveryKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	keyStruct := &api.SystemSecurityRecoveryKey{}
+
+	counter := &countWrapper{ReadCloser: r.Body}
+
+	err := json.NewDecoder(counter).Decode(keyStruct)
+	if err != nil && counter.n > 0 {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	key, err := systemd.GetRecoveryKeyByID(s.state, keyStruct.ID)
+	if err != nil {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	err = systemd.DeleteEncryptionKey(r.Context(), s.state, key)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+	_ = s.state.Save()
+}
+
+// renderRecoveryKey returns a newly generated recovery key along with its metadata.
+func (s *Server) renderRecoveryKey(w http.ResponseWriter, key string) {
+	id := systemd.RecoveryKeyID(key)
+
+	for _, metadata := range systemd.GetRecoveryKeysMetadata(s.state) {
+		if metadata.ID == id {
+			metadata.Key = key
+
+			_ = response.SyncResponse(true, metadata).Render(w)
+
+			return
+		}
+	}
+
+	_ = response.SyncResponse(true, api.SystemSecurityRecoveryKey{ID: id, Key: key}).Render(w)
+}
+
+// swagger:operation POST /1.0/system/security/:secureboot-enroll system system_post_security_secureboot_enroll
+//
+//	Enroll a Secure Boot key
+//
+//	Applies a signed (.auth) update to the PK, KEK or db EFI variable, enrolling an organization-owned
+//	Secure Boot key. The PK is replaced, while KEK and db updates are appended. The TPM bindings of the
+//	encrypted volumes are updated to match.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: update
+//	    description: Secure Boot variable update
+//	    required: true
+//	    schema:
+//	      type: object
+//	      example: {"variable":"db","update":"5QcLEw8AAAAAAAAA..."}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (*Server) apiSystemSecuritySecureBootEnroll(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	enrollStruct := &api.SystemSecuritySecureBootEnroll{}
+
+	counter := &countWrapper{ReadCloser: r.Body}
+
+	err := json.NewDecoder(counter).Decode(enrollStruct)
+	if err != nil && counter.n > 0 {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	if enrollStruct.Variable == "" || len(enrollStruct.Update) == 0 {
+		_ = response.BadRequest(errors.New("missing variable and/or update")).Render(w)
+
+		return
+	}
+
+	err = secureboot.EnrollSecureBootKey(r.Context(), enrollStruct.Variable, enrollStruct.Update)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/system/security/:set-secret system system_post_security_set_secret
+//
+//	Store a local secret
+//
+//	Stores a secret locally on the system, sealed by the TPM, replacing any existing secret with the
+//	same name. The secret can then be referenced from service configurations as "secret://local/NAME".
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: secret
+//	    description: Secret to store
+//	    required: true
+//	    schema:
+//	      type: object
+//	      example: {"name":"kopia-password","value":"my-repository-password"}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (*Server) apiSystemSecuritySetSecret(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	secretStruct := &api.SystemSecuritySecret{}
+
+	counter := &countWrapper{ReadCloser: r.Body}
+
+	err := json.NewDecoder(counter).Decode(secretStruct)
+	if err != nil && counter.n > 0 {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	err = secrets.ValidateName(secretStruct.Name)
+	if err != nil {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	if secretStruct.Value == "" {
+		_ = response.BadRequest(errors.New("no secret value provided")).Render(w)
+
+		return
+	}
+
+	err = secrets.SetLocal(r.Context(), secretStruct.Name, secretStruct.Value)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/system/security/:tpm-rebind system system_post_security_tpm_rebind
+//
+//	Reset TPM bindings
+//
+//	Forcibly resets TPM encryption bindings; intended only for use if it was required to enter a recovery passphrase to boot the system.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemSecurityTPMRebind(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	err := secureboot.ForceUpdatePCRBindings(r.Context(), s.state.OS.Name, s.state.OS.RunningRelease, s.state.System.Security.Config.EncryptionRecoveryKeys[0])
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+	_ = s.state.Save()
+}
+
+// swagger:operation POST /1.0/system/security/:tpm-reseal system system_post_security_tpm_reseal
+//
+//	Re-seal the encrypted volumes to the TPM
+//
+//	Binds the encrypted volumes to the current values of the PCRs selected by the configured TPM binding policy.
+//	This is intended to be used after a deliberate firmware or configuration change, while the system is still unlocked.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemSecurityTPMReseal(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	if len(s.state.System.Security.Config.EncryptionRecoveryKeys) == 0 {
+		_ = response.BadRequest(errors.New("no recovery key available to unlock the encrypted volumes")).Render(w)
+
+		return
+	}
+
+	err := secureboot.ApplyTPMBinding(r.Context(), s.state.OS.Name, s.state.OS.RunningRelease, s.state.System.Security.Config.EncryptionRecoveryKeys[0], s.state.System.Security.Config.TPMBinding)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/system/security/:add-ca-certificate system system_post_security_add_ca_certificate
+//
+//	Add a CA certificate to the trust store
+//
+//	Adds a custom CA certificate to the system trust store, allowing TLS connections to servers using a
+//	private CA. The CA certificate is kept across IncusOS updates.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: certificate
+//	    description: CA certificate to add
+//	    required: true
+//	    schema:
+//	      type: object
+//	      example: {"name":"internal","certificate":"-----BEGIN CERTIFICATE-----\n..."}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemSecurityAddCACertificate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	certStruct := &api.SystemSecurityCACertificate{}
+
+	counter := &countWrapper{ReadCloser: r.Body}
+
+	err := json.NewDecoder(counter).Decode(certStruct)
+	if err != nil && counter.n > 0 {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	certs := append(slices.Clone(s.state.System.Security.Config.CACertificates), *certStruct)
+
+	err = truststore.Validate(certs)
+	if err != nil {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	err = truststore.Apply(certs)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	s.state.System.Security.Config.CACertificates = certs
+	_ = s.state.Save()
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/system/security/:add-trusted-certificate system system_post_security_add_trusted_certificate
+//
+//	Trust a client certificate
+//
+//	Adds a client certificate to those trusted by the HTTPS API of the primary application, either from
+//	its PEM encoding or its fingerprint. Descriptions and restrictions are only supported by Incus.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: certificate
+//	    description: Certificate to trust
+//	    required: true
+//	    schema:
+//	      type: object
+//	      example: {"name":"admin","description":"Admin workstation","certificate":"-----BEGIN CERTIFICATE-----\n...","restricted":true,"projects":["default"]}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemSecurityAddTrustedCertificate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	certStruct := &api.SystemSecurityTrustedCertificate{}
+
+	counter := &countWrapper{ReadCloser: r.Body}
+
+	err := json.NewDecoder(counter).Decode(certStruct)
+	if err != nil && counter.n > 0 {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	if certStruct.Certificate == "" && certStruct.Fingerprint == "" {
+		_ = response.BadRequest(errors.New("a certificate or fingerprint must be provided")).Render(w)
+
+		return
+	}
+
+	app, err := applications.GetPrimary(r.Context(), s.state)
+	if err != nil {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	err = app.AddTrustedCertificate(r.Context(), certStruct)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/system/security/:lock system system_post_security_lock
+//
+//	Enter lockdown mode
+//
+//	Puts the system in lockdown mode, rejecting any request which may change the system until it's
+//	unlocked by an administrator.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemSecurityLock(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Entering lockdown mode", "identity", getIdentity(r))
+
+	s.state.System.Security.Config.Lockdown = true
+	_ = s.state.Save()
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/system/security/:remove-ca-certificate system system_post_security_remove_ca_certificate
+//
+//	Remove a CA certificate from the trust store
+//
+//	Removes a custom CA certificate from the system trust store.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: certificate
+//	    description: CA certificate to remove
+//	    required: true
+//	    schema:
+//	      type: object
+//	      example: {"fingerprint":"2243c49fcf6f84fe670f100ecafa801389dc207536cb9ca87aa2c062ddebfde5"}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemSecurityRemoveCACertificate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	removeStruct := &api.SystemSecurityCACertificateRemove{}
+
+	counter := &countWrapper{ReadCloser: r.Body}
+
+	err := json.NewDecoder(counter).Decode(removeStruct)
+	if err != nil && counter.n > 0 {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	if removeStruct.Fingerprint == "" {
+		_ = response.BadRequest(errors.New("no fingerprint provided")).Render(w)
+
+		return
+	}
+
+	certs := slices.DeleteFunc(slices.Clone(s.state.System.Security.Config.CACertificates), func(cert api.SystemSecurityCACertificate) bool {
+		return cert.Fingerprint == removeStruct.Fingerprint
+	})
+
+	if len(certs) == len(s.state.System.Security.Config.CACertificates) {
+		_ = response.NotFound(errors.New("CA certificate not found")).Render(w)
+
+		return
+	}
+
+	err = truststore.Apply(certs)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	s.state.System.Security.Config.CACertificates = certs
+	_ = s.state.Save()
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/system/security/:remove-trusted-certificate system system_post_security_remove_trusted_certificate
+//
+//	Revoke a client certificate
+//
+//	Removes a client certificate from those trusted by the HTTPS API of the primary application.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: certificate
+//	    description: Certificate to revoke
+//	    required: true
+//	    schema:
+//	      type: object
+//	      example: {"fingerprint":"3f2a9c1b7e4d0a5b6c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b"}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemSecurityRemoveTrustedCertificate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	removeStruct := &api.SystemSecurityTrustedCertificateRemove{}
+
+	counter := &countWrapper{ReadCloser: r.Body}
+
+	err := json.NewDecoder(counter).Decode(removeStruct)
+	if err != nil && counter.n > 0 {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	if removeStruct.Fingerprint == "" {
+		_ = response.BadRequest(errors.New("no fingerprint provided")).Render(w)
+
+		return
+	}
+
+	app, err := applications.GetPrimary(r.Context(), s.state)
+	if err != nil {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	err = app.RemoveTrustedCertificate(r.Context(), removeStruct.Fingerprint)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/system/security/:unlock system system_post_security_unlock
+//
+//	Leave lockdown mode
+//
+//	Allows changes to the system again after it was put in lockdown mode.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemSecurityUnlock(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Leaving lockdown mode", "identity", getIdentity(r))
+
+	s.state.System.Security.Config.Lockdown = false
+	_ = s.state.Save()
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/system/security/:usb-authorize system system_post_security_usb_authorize
+//
+//	Authorize a USB device
+//
+//	Authorizes a USB device blocked by the USB device authorization policy, optionally allowing it permanently.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: device
+//	    description: Device to authorize
+//	    required: true
+//	    schema:
+//	      type: object
+//	      example: {"id":"1-2","permanent":true}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemSecurityUSBAuthorize(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	authorizeStruct := &api.SystemSecurityUSBAuthorize{}
+
+	counter := &countWrapper{ReadCloser: r.Body}
+
+	err := json.NewDecoder(counter).Decode(authorizeStruct)
+	if err != nil && counter.n > 0 {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	err = usb.Authorize(authorizeStruct.ID)
+	if err != nil {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	// Allow the device permanently.
+	if authorizeStruct.Permanent && s.state.System.Security.Config.USB != nil {
+		devices, err := usb.ListDevices()
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+
+		for _, device := range devices {
+			if device.ID != authorizeStruct.ID {
+				continue
+			}
+
+			deviceID := device.VendorID + ":" + device.ProductID
+			if !slices.Contains(s.state.System.Security.Config.USB.AllowedDevices, deviceID) {
+				s.state.System.Security.Config.USB.AllowedDevices = append(s.state.System.Security.Config.USB.AllowedDevices, deviceID)
+			}
+		}
+
+		_ = s.state.Save()
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// getTrustedCertificates returns the client certificates trusted by the primary application, if running.
+func (s *Server) getTrustedCertificates(r *http.Request) ([]api.SystemSecurityTrustedCertificate, error) {
+	app, err := applications.GetPrimary(r.Context(), s.state)
+	if err != nil {
+		if errors.Is(err, applications.ErrNoPrimary) {
+			return []api.SystemSecurityTrustedCertificate{}, nil
+		}
+
+		return nil, err
+	}
+
+	if !app.IsRunning(r.Context()) {
+		return []api.SystemSecurityTrustedCertificate{}, nil
+	}
+
+	return app.GetTrustedCertificates(r.Context())
+}
+
+// applyACMEConfiguration configures ACME on the primary application, serving the HTTPS API, and on
+// Incus when requested and installed alongside another primary application.
+func (s *Server) applyACMEConfiguration(r *http.Request, cfg *api.SystemSecurityACME) error {
+	app, err := applications.GetPrimary(r.Context(), s.state)
+	if err != nil {
+		return err
+	}
+
+	err = app.SetACMEConfiguration(r.Context(), cfg)
+	if err != nil {
+		return err
+	}
+
+	_, hasIncus := s.state.Applications["incus"]
+	if !hasIncus || app.Name() == "incus" {
+		return nil
+	}
+
+	oldCfg := s.state.System.Security.Config.ACME
+
+	incusCfg := cfg
+	if cfg == nil || !cfg.Incus {
+		// Only reset Incus if previously configured.
+		if oldCfg == nil || !oldCfg.Incus {
+			return nil
+		}
+
+		incusCfg = nil
+	}
+
+	incus, err := applications.Load(r.Context(), s.state, "incus")
+	if err != nil {
+		return err
+	}
+
+	return incus.SetACMEConfiguration(r.Context(), incusCfg)
 }
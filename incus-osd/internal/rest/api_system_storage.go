@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/applications"
 	"github.com/lxc/incus-os/incus-osd/internal/rest/response"
 	"github.com/lxc/incus-os/incus-osd/internal/storage"
 	"github.com/lxc/incus-os/incus-osd/internal/zfs"
@@ -91,6 +92,16 @@ func (s *Server) apiSystemStorage(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// Include the pools managed by IncusOS.
+		ret.Config = s.state.System.Storage.Config
+
+		// Report why the checkpoints held by the pools were taken.
+		for i, pool := range ret.State.Pools {
+			if pool.HasCheckpoint {
+				ret.State.Pools[i].CheckpointReason = s.state.StorageCheckpoints[pool.Name]
+			}
+		}
+
 		// Return the current system storage state.
 		_ = response.SyncResponse(true, ret).Render(w)
 	case http.MethodPut:
@@ -131,7 +142,13 @@ func (s *Server) apiSystemStorage(w http.ResponseWriter, r *http.Request) {
 			if !storage.PoolExists(r.Context(), pool.Name) {
 				err = zfs.CreateZpool(r.Context(), pool, s.state)
 			} else {
-				err = zfs.UpdateZpool(r.Context(), pool)
+				// Skip any pool whose configuration hasn't changed.
+				tracked, ok := zfs.GetTrackedPool(s.state, pool.Name)
+				if ok && tracked.Type == pool.Type && slices.Equal(tracked.Devices, pool.Devices) && slices.Equal(tracked.Cache, pool.Cache) && slices.Equal(tracked.Log, pool.Log) {
+					continue
+				}
+
+				err = zfs.UpdateZpool(r.Context(), s.state, pool)
 			}
 
 			if err != nil {
@@ -176,7 +193,7 @@ func (s *Server) apiSystemStorage(w http.ResponseWriter, r *http.Request) {
 //	    $ref: "#/responses/BadRequest"
 //	  "500":
 //	    $ref: "#/responses/InternalServerError"
-func (*Server) apiSystemStorageDeletePool(w http.ResponseWriter, r *http.Request) {
+func (s *Server) apiSystemStorageDeletePool(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	if r.Method != http.MethodPost {
@@ -207,13 +224,15 @@ func (*Server) apiSystemStorageDeletePool(w http.ResponseWriter, r *http.Request
 	}
 
 	// Delete the pool.
-	err = zfs.DestroyZpool(r.Context(), config.Name)
+	err = zfs.DestroyZpool(r.Context(), s.state, config.Name)
 	if err != nil {
 		_ = response.InternalError(err).Render(w)
 
 		return
 	}
 
+	_ = s.state.Save()
+
 	_ = response.EmptySyncResponse.Render(w)
 }
 
@@ -306,7 +325,7 @@ func (*Server) apiSystemStorageWipeDrive(w http.ResponseWriter, r *http.Request)
 //	    $ref: "#/responses/BadRequest"
 //	  "500":
 //	    $ref: "#/responses/InternalServerError"
-func (*Server) apiSystemStorageImportPool(w http.ResponseWriter, r *http.Request) {
+func (s *Server) apiSystemStorageImportPool(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	if r.Method != http.MethodPost {
@@ -339,13 +358,15 @@ func (*Server) apiSystemStorageImportPool(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	err = zfs.ImportExistingPool(r.Context(), poolStruct.Name, poolStruct.EncryptionKey)
+	err = zfs.ImportExistingPool(r.Context(), s.state, poolStruct.Name, poolStruct.EncryptionKey)
 	if err != nil {
 		_ = response.InternalError(err).Render(w)
 
 		return
 	}
 
+	_ = s.state.Save()
+
 	_ = response.EmptySyncResponse.Render(w)
 }
 
@@ -367,7 +388,7 @@ func (*Server) apiSystemStorageImportPool(w http.ResponseWriter, r *http.Request
 //	    required: true
 //	    schema:
 //	      type: object
-//	      example: {"pool":"local", "name":"my-volume", "quota":0, "use":"incus"}
+//	      example: {"pool":"local", "name":"my-volume", "quota":0, "use":"incus", "properties":{"compression":"zstd","record_size":"16K"}}
 //	responses:
 //	  "200":
 //	    $ref: "#/responses/EmptySyncResponse"
@@ -375,7 +396,7 @@ func (*Server) apiSystemStorageImportPool(w http.ResponseWriter, r *http.Request
 //	    $ref: "#/responses/BadRequest"
 //	  "500":
 //	    $ref: "#/responses/InternalServerError"
-func (*Server) apiSystemStorageCreateVolume(w http.ResponseWriter, r *http.Request) {
+func (s *Server) apiSystemStorageCreateVolume(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	if r.Method != http.MethodPost {
@@ -385,10 +406,11 @@ func (*Server) apiSystemStorageCreateVolume(w http.ResponseWriter, r *http.Reque
 	}
 
 	type createStruct struct {
-		Pool  string `json:"pool"`
-		Name  string `json:"name"`
-		Quota int    `json:"quota"`
-		Use   string `json:"use"`
+		Pool       string                                `json:"pool"`
+		Name       string                                `json:"name"`
+		Quota      int                                   `json:"quota"`
+		Use        string                                `json:"use"`
+		Properties api.SystemStoragePoolVolumeProperties `json:"properties"`
 	}
 
 	config := &createStruct{}
@@ -439,7 +461,13 @@ func (*Server) apiSystemStorageCreateVolume(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Create the volume.
-	props := map[string]string{}
+	props, err := zfs.ValidateDatasetProperties(config.Properties, "filesystem")
+	if err != nil {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
 	props["incusos:use"] = config.Use
 
 	if config.Use == "linstor" {
@@ -458,6 +486,24 @@ func (*Server) apiSystemStorageCreateVolume(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Offer the new volume to Incus, if installed.
+	_, ok := s.state.Applications["incus"]
+	if ok && config.Use == "incus" {
+		app, err := applications.Load(r.Context(), s.state, "incus")
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+
+		err = app.AddStoragePool(r.Context(), config.Pool, config.Name)
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+	}
+
 	_ = response.EmptySyncResponse.Render(w)
 }
 
@@ -548,6 +594,119 @@ func (*Server) apiSystemStorageDeleteVolume(w http.ResponseWriter, r *http.Reque
 	_ = response.EmptySyncResponse.Render(w)
 }
 
+// swagger:operation POST /1.0/system/storage/:update-volume system system_post_storage_update_volume
+//
+//	Update a volume
+//
+//	Updates the ZFS properties of a storage pool volume.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: configuration
+//	    description: The volume to be updated
+//	    required: true
+//	    schema:
+//	      type: object
+//	      example: {"pool":"local", "name":"my-volume", "properties":{"compression":"lz4","record_size":"1M","atime":"off","sync":"standard"}}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (*Server) apiSystemStorageUpdateVolume(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	type updateStruct struct {
+		Pool       string                                `json:"pool"`
+		Name       string                                `json:"name"`
+		Properties api.SystemStoragePoolVolumeProperties `json:"properties"`
+	}
+
+	config := &updateStruct{}
+
+	counter := &countWrapper{ReadCloser: r.Body}
+
+	err := json.NewDecoder(counter).Decode(config)
+	if err != nil && counter.n > 0 {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	if config.Pool == "" {
+		_ = response.BadRequest(errors.New("no pool name provided")).Render(w)
+
+		return
+	}
+
+	if strings.Contains(config.Pool, "/") {
+		_ = response.BadRequest(errors.New("invalid pool name provided")).Render(w)
+
+		return
+	}
+
+	if config.Name == "" {
+		_ = response.BadRequest(errors.New("no volume name provided")).Render(w)
+
+		return
+	}
+
+	if strings.Contains(config.Name, "/") {
+		_ = response.BadRequest(errors.New("invalid volume name provided")).Render(w)
+
+		return
+	}
+
+	if !storage.DatasetExists(r.Context(), config.Pool+"/"+config.Name) {
+		_ = response.BadRequest(errors.New("volume '" + config.Name + "' doesn't exist in pool '" + config.Pool + "'")).Render(w)
+
+		return
+	}
+
+	datasetType, err := storage.GetDatasetType(r.Context(), config.Pool+"/"+config.Name)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	props, err := zfs.ValidateDatasetProperties(config.Properties, datasetType)
+	if err != nil {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	if len(props) == 0 {
+		_ = response.BadRequest(errors.New("no volume properties provided")).Render(w)
+
+		return
+	}
+
+	// Update the volume.
+	err = zfs.SetDatasetProperties(r.Context(), config.Pool, config.Name, props)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
 // swagger:operation POST /1.0/system/storage/:scrub-pool system system_post_storage_scrub_pool
 //
 //	Scrub local pool
@@ -620,3 +779,245 @@ func (*Server) apiSystemStorageScrubPool(w http.ResponseWriter, r *http.Request)
 
 	_ = response.EmptySyncResponse.Render(w)
 }
+
+// swagger:operation POST /1.0/system/storage/:discard-checkpoint system system_post_storage_discard_checkpoint
+//
+//	Discard pool checkpoint
+//
+//	Discards the checkpoint of a local storage pool.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: configuration
+//	    description: The pool whose checkpoint should be discarded
+//	    required: true
+//	    schema:
+//	      type: object
+//	      example: {"name":"mypool"}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemStorageDiscardCheckpoint(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	type checkpointStruct struct {
+		Name string `json:"name"`
+	}
+
+	config := &checkpointStruct{}
+
+	counter := &countWrapper{ReadCloser: r.Body}
+
+	err := json.NewDecoder(counter).Decode(config)
+	if err != nil && counter.n > 0 {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	if config.Name == "" {
+		_ = response.BadRequest(errors.New("no pool name provided")).Render(w)
+
+		return
+	}
+
+	// Discard the checkpoint.
+	err = zfs.DiscardCheckpoint(r.Context(), config.Name)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	zfs.ForgetCheckpoint(s.state, config.Name)
+	_ = s.state.Save()
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/system/storage/:rewind-checkpoint system system_post_storage_rewind_checkpoint
+//
+//	Rewind pool to checkpoint
+//
+//	Rewinds a local storage pool to its checkpoint, discarding all changes made since the checkpoint was taken.
+//	Applications are stopped for the duration of the operation.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: configuration
+//	    description: The pool to be rewound
+//	    required: true
+//	    schema:
+//	      type: object
+//	      example: {"name":"mypool"}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemStorageRewindCheckpoint(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	type checkpointStruct struct {
+		Name string `json:"name"`
+	}
+
+	config := &checkpointStruct{}
+
+	counter := &countWrapper{ReadCloser: r.Body}
+
+	err := json.NewDecoder(counter).Decode(config)
+	if err != nil && counter.n > 0 {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	if config.Name == "" {
+		_ = response.BadRequest(errors.New("no pool name provided")).Render(w)
+
+		return
+	}
+
+	hasCheckpoint, err := storage.PoolHasCheckpoint(r.Context(), config.Name)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	if !hasCheckpoint {
+		_ = response.BadRequest(errors.New("pool '" + config.Name + "' doesn't have a checkpoint")).Render(w)
+
+		return
+	}
+
+	// Stop all applications so the pool can be exported.
+	for appName, appInfo := range s.state.Applications {
+		app, err := applications.Load(r.Context(), s.state, appName)
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+
+		err = app.Stop(r.Context(), appInfo.State.Version)
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+	}
+
+	// Rewind the pool.
+	rewindErr := zfs.RewindCheckpoint(r.Context(), s.state, config.Name)
+
+	// Start all applications back up, even if the rewind failed.
+	for appName, appInfo := range s.state.Applications {
+		app, err := applications.Load(r.Context(), s.state, appName)
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+
+		err = app.Start(r.Context(), appInfo.State.Version)
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+	}
+
+	if rewindErr != nil {
+		_ = response.InternalError(rewindErr).Render(w)
+
+		return
+	}
+
+	_ = s.state.Save()
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation GET /1.0/system/storage/iostat system system_get_storage_iostat
+//
+//	Get storage I/O statistics
+//
+//	Returns I/O statistics for each local storage pool, its member devices and its datasets.
+//	Device statistics are averaged over a one second interval.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Storage I/O statistics
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          description: Response type
+//	          example: sync
+//	          type: string
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: json
+//	          description: Storage I/O statistics
+//	          example: {"pools":[{"name":"local","pool":{"name":"local","read_ops_per_second":2,"write_ops_per_second":50,"read_bytes_per_second":8192,"write_bytes_per_second":1048576,"read_latency":150000,"write_latency":320000,"queue_depth":3},"devices":[{"name":"scsi-0QEMU_QEMU_HARDDISK_incus_root-part11","read_ops_per_second":2,"write_ops_per_second":50,"read_bytes_per_second":8192,"write_bytes_per_second":1048576,"read_latency":150000,"write_latency":320000,"queue_depth":3}],"datasets":[{"name":"local/incus","reads":312,"writes":1520,"bytes_read":1277952,"bytes_written":6225920}]}]}
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (*Server) apiSystemStorageIOStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	ret, err := storage.GetIOStats(r.Context())
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	_ = response.SyncResponse(true, ret).Render(w)
+}
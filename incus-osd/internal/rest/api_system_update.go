@@ -3,11 +3,19 @@ package rest
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/health"
+	"github.com/lxc/incus-os/incus-osd/internal/nftables"
+	"github.com/lxc/incus-os/incus-osd/internal/providers"
 	"github.com/lxc/incus-os/incus-osd/internal/rest/response"
+	"github.com/lxc/incus-os/incus-osd/internal/systemd"
+	"github.com/lxc/incus-os/incus-osd/internal/zfs"
 )
 
 // swagger:operation GET /1.0/system/update system system_get_update
@@ -41,7 +49,7 @@ import (
 //	        metadata:
 //	          type: json
 //	          description: State and configuration for the system update
-//	          example: {"config":{"auto_reboot":false,"channel":"stable","check_frequency":"6h"},"state":{"last_check":"2025-11-04T16:21:34.929524792Z","status":"Update check completed","needs_reboot":false}}
+//	          example: {"config":{"auto_reboot":false,"channel":"stable","check_frequency":"6h","reboot_policy":"maintenance-window","stage_updates":false},"state":{"last_check":"2025-11-04T16:21:34.929524792Z","status":"Update check completed","needs_reboot":false,"rollback_version":"202510280120","rollback_pending":false,"on_hold":false,"staged_version":"","progress":[{"type":"OS","name":"IncusOS","version":"202511040120","phase":"downloading","progress":0.42,"last_update":"2025-11-04T16:22:10.102938475Z"}],"channel":"stable","available_versions":{"stable":["202511040120","202510280120"],"candidate":["202511040120"],"testing":["202511060312","202511040120"]},"boot_slots":[{"name":"a","version":"202511040120","role":"active","boot_status":"good"},{"name":"b","version":"202510280120","role":"previous","boot_status":"good"}],"boot_history":[{"timestamp":"2025-11-04T16:40:02Z","boot_id":"3f1c2e9ab8d44c8f9e5e0b7c6a1d2f34","version":"202511040120","slot":"a"}]}}
 
 // swagger:operation PUT /1.0/system/update system system_put_update
 //
@@ -65,7 +73,7 @@ import (
 //	        config:
 //	          type: object
 //	          description: The update configuration
-//	          example: {"auto_reboot":false,"channel":"testing","check_frequency":"1d"}
+//	          example: {"auto_reboot":false,"channel":"testing","check_frequency":"24h","check_jitter":"30m","hold":{"reason":"Change freeze","until":"2025-12-01T00:00:00Z"},"reboot_policy":"scheduled","reboot_time":"03:00"}
 //	responses:
 //	  "200":
 //	    $ref: "#/responses/EmptySyncResponse"
@@ -77,6 +85,13 @@ func (s *Server) apiSystemUpdate(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
 		// Return the current system update state.
+		s.state.System.Update.State.RollbackVersion = systemd.GetRollbackVersion(s.state.OS.Name, s.state.OS.RunningRelease)
+		s.state.System.Update.State.StagedVersion = s.state.OS.StagedRelease
+		s.state.System.Update.State.OnHold = s.state.System.Update.Config.Hold.IsActive(time.Now())
+		s.state.System.Update.State.Bundle = s.state.OS.UpdateBundle
+		s.state.System.Update.State.BootSlots = systemd.GetBootSlots(s.state)
+		s.state.System.Update.State.BootHistory = s.state.OS.BootHistory
+
 		_ = response.SyncResponse(true, s.state.System.Update).Render(w)
 	case http.MethodPut:
 		// Apply a new system update configuration.
@@ -120,9 +135,107 @@ func (s *Server) apiSystemUpdate(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		// Check the update jitter is valid.
+		if newConfig.Config.CheckJitter != "" {
+			jitter, err := time.ParseDuration(newConfig.Config.CheckJitter)
+			if err != nil || jitter < 0 {
+				_ = response.BadRequest(errors.New("invalid update check jitter")).Render(w)
+
+				return
+			}
+		}
+
+		// Check the channel is published by the images provider.
+		if s.state.System.Provider.Config.Name == "images" && newConfig.Config.Channel != "" && !slices.Contains(api.SystemUpdateChannels, newConfig.Config.Channel) {
+			_ = response.BadRequest(fmt.Errorf("invalid update channel %q", newConfig.Config.Channel)).Render(w)
+
+			return
+		}
+
+		// Check the reboot policy.
+		if newConfig.Config.RebootPolicy != "" && !slices.Contains(api.SystemUpdateRebootPolicies, newConfig.Config.RebootPolicy) {
+			_ = response.BadRequest(fmt.Errorf("invalid reboot policy %q", newConfig.Config.RebootPolicy)).Render(w)
+
+			return
+		}
+
+		if newConfig.Config.RebootPolicy == "maintenance-window" && len(newConfig.Config.MaintenanceWindows) == 0 {
+			_ = response.BadRequest(errors.New("the maintenance-window reboot policy requires at least one maintenance window")).Render(w)
+
+			return
+		}
+
+		if newConfig.Config.RebootPolicy == "scheduled" {
+			_, err = time.Parse("15:04", newConfig.Config.RebootTime)
+			if err != nil {
+				_ = response.BadRequest(fmt.Errorf("invalid reboot time %q", newConfig.Config.RebootTime)).Render(w)
+
+				return
+			}
+		}
+
+		// Check the update hold.
+		hold := newConfig.Config.Hold
+		if hold != nil {
+			_, err = time.Parse(time.RFC3339, hold.Until)
+			if err != nil {
+				_ = response.BadRequest(fmt.Errorf("invalid update hold expiry %q", hold.Until)).Render(w)
+
+				return
+			}
+
+			if hold.Reason == "" {
+				_ = response.BadRequest(errors.New("a reason must be provided for the update hold")).Render(w)
+
+				return
+			}
+		}
+
+		err = health.ValidateConfig(newConfig.Config.HealthChecks)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		err = providers.ValidatePeerCache(newConfig.Config.PeerCache)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		channelChanged := newConfig.Config.Channel != s.state.System.Update.Config.Channel
+
 		// Apply the updated configuration.
 		s.state.System.Update.Config = newConfig.Config
 
+		// Reschedule any pending reboot following the new policy.
+		systemd.ScheduleUpdateReboot(r.Context(), s.state)
+
+		// Start or stop serving the update files to peers.
+		err = providers.ApplyPeerCache(r.Context(), s.state)
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+
+		err = nftables.ApplyHostFirewall(r.Context(), s.state)
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+
+		// Immediately follow the new channel.
+		if channelChanged {
+			select {
+			case s.state.TriggerUpdate <- true:
+			default:
+			}
+		}
+
 		_ = response.EmptySyncResponse.Render(w)
 
 		_ = s.state.Save()
@@ -158,3 +271,290 @@ func (s *Server) apiSystemUpdateCheck(w http.ResponseWriter, r *http.Request) {
 
 	_ = response.EmptySyncResponse.Render(w)
 }
+
+// swagger:operation POST /1.0/system/update/:rollback system system_post_update_rollback
+//
+//	Roll back to the previous OS version
+//
+//	Makes the previous OS version the default boot entry and reboots the system. If maintenance windows
+//	are defined, the reboot is delayed until the next one starts.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemUpdateRollback(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	// Don't race with an update being applied.
+	s.state.UpdateMutex.Lock()
+	defer s.state.UpdateMutex.Unlock()
+
+	version, err := systemd.RollbackOS(r.Context(), s.state, "rollback requested")
+	if err != nil {
+		if errors.Is(err, systemd.ErrNoRollbackVersion) {
+			_ = response.BadRequest(errors.New("no previous " + s.state.OS.Name + " version available for rollback")).Render(w)
+
+			return
+		}
+
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	// Reboot now, or once the next maintenance window starts.
+	var delay time.Duration
+
+	for i, window := range s.state.System.Update.Config.MaintenanceWindows {
+		if i == 0 || window.TimeUntilActive() < delay {
+			delay = window.TimeUntilActive()
+		}
+	}
+
+	slog.InfoContext(r.Context(), "Rolling back "+s.state.OS.Name+" to version "+version, "delay", delay.String(), "identity", getIdentity(r))
+
+	go func() {
+		time.Sleep(delay)
+
+		select {
+		case s.state.TriggerReboot <- nil:
+		default:
+		}
+	}()
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/system/update/:stage system system_post_update_stage
+//
+//	Stage the latest OS update
+//
+//	Triggers an immediate update check, only downloading the latest OS update so it can later be applied.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+func (s *Server) apiSystemUpdateStage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	// Trigger a manual update check, staging the OS update.
+	s.state.TriggerStage <- true
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/system/update/:apply system system_post_update_apply
+//
+//	Apply the staged OS update
+//
+//	Installs the previously staged OS update, optionally rebooting the system to finalize it.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: configuration
+//	    description: Apply options
+//	    required: false
+//	    schema:
+//	      type: object
+//	      example: {"reboot":true}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemUpdateApply(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	req := &api.SystemUpdateApply{}
+
+	counter := &countWrapper{ReadCloser: r.Body}
+
+	err := json.NewDecoder(counter).Decode(req)
+	if err != nil && counter.n > 0 {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	// Don't race with an update being downloaded.
+	s.state.UpdateMutex.Lock()
+	defer s.state.UpdateMutex.Unlock()
+
+	version := s.state.OS.StagedRelease
+	if version == "" {
+		_ = response.BadRequest(errors.New("no staged " + s.state.OS.Name + " update")).Render(w)
+
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Applying staged OS update", "version", version, "reboot", req.Reboot, "identity", getIdentity(r))
+
+	// Record the release, as done by the update checker.
+	priorNextRelease := s.state.OS.NextRelease
+	s.state.OS.NextRelease = version
+	health.Prepare(r.Context(), s.state, version)
+	_ = s.state.Save()
+
+	// Checkpoint the storage pools, allowing them to be rewound should the update go wrong.
+	zfs.CheckpointPoolsForUpdate(r.Context(), s.state, version)
+
+	err = systemd.ApplySystemUpdate(r.Context(), s.state.System.Security.Config.EncryptionRecoveryKeys[0], version, false)
+	if err != nil {
+		s.state.OS.NextRelease = priorNextRelease
+		_ = s.state.Save()
+
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	s.state.System.Security.State.EncryptedVolumes, err = systemd.ListEncryptedVolumes(r.Context())
+	if err != nil {
+		slog.WarnContext(r.Context(), "Failed to list encrypted volumes", "err", err.Error())
+	}
+
+	s.state.OS.StagedRelease = ""
+	s.state.System.Update.State.StagedVersion = ""
+	s.state.System.Update.State.NeedsReboot = true
+	s.state.System.Update.State.Status = s.state.OS.Name + " has been updated to version " + version
+	_ = s.state.Save()
+
+	// Reboot through the regular shutdown sequence, or as allowed by the reboot policy.
+	if req.Reboot {
+		select {
+		case s.state.TriggerReboot <- nil:
+		default:
+		}
+	} else {
+		systemd.ScheduleUpdateReboot(r.Context(), s.state)
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
+
+// swagger:operation POST /1.0/system/update/:export-bundle system system_post_update_export_bundle
+//
+//	Export an update bundle
+//
+//	Returns a tar archive holding the signed index and the files of the latest release from the
+//	current provider, suitable for import on an air-gapped system.
+//
+//	---
+//	produces:
+//	  - application/json
+//	  - application/x-tar
+//	responses:
+//	  "200":
+//	    description: tar archive
+//	    schema:
+//	      type: file
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemUpdateExportBundle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	p, err := providers.Load(r.Context(), s.state)
+	if err != nil {
+		_ = response.InternalError(err).Render(w)
+
+		return
+	}
+
+	// Errors past this point can't be reported as the archive is being streamed.
+	w.Header().Set("Content-Type", "application/x-tar")
+
+	err = providers.ExportBundle(r.Context(), p, w)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to export update bundle", "err", err)
+	}
+}
+
+// swagger:operation POST /1.0/system/update/:import-bundle system system_post_update_import_bundle
+//
+//	Import an update bundle
+//
+//	Imports a tar archive produced by an export, validating its signed index, and triggers an update
+//	check. Requires the bundle provider with no configured path.
+//
+//	Remember to properly set the `Content-Type: application/x-tar` HTTP header.
+//
+//	---
+//	consumes:
+//	  - application/x-tar
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: tar archive
+//	    description: Update bundle to import
+//	    required: true
+//	    schema:
+//	      type: file
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+func (s *Server) apiSystemUpdateImportBundle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		_ = response.NotImplemented(nil).Render(w)
+
+		return
+	}
+
+	err := providers.ImportBundle(r.Context(), s.state, r.Body)
+	if err != nil {
+		_ = response.BadRequest(err).Render(w)
+
+		return
+	}
+
+	// Immediately check the imported bundle for updates.
+	select {
+	case s.state.TriggerUpdate <- true:
+	default:
+	}
+
+	_ = response.EmptySyncResponse.Render(w)
+}
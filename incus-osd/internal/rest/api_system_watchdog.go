@@ -0,0 +1,122 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/rest/response"
+	"github.com/lxc/incus-os/incus-osd/internal/systemd"
+)
+
+// swagger:operation GET /1.0/system/watchdog system system_get_watchdog
+//
+//	Get hardware watchdog information
+//
+//	Returns the hardware watchdogs and the reason of the last reset they reported, along with the
+//	watchdog configuration.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: State and configuration for the hardware watchdog
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          description: Response type
+//	          example: sync
+//	          type: string
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: json
+//	          description: State and configuration for the hardware watchdog
+//	          example: {"config":{"enabled":true,"device":"","timeout":60,"reboot_timeout":600,"daemon":true},"state":{"devices":[{"name":"watchdog0","identity":"iTCO_wdt","active":true,"timeout":60}],"last_reset_reason":"none"}}
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/system/watchdog system system_put_watchdog
+//
+//	Update hardware watchdog configuration
+//
+//	Updates the hardware watchdog configuration, applied immediately.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: configuration
+//	    description: Hardware watchdog configuration
+//	    required: true
+//	    schema:
+//	      type: object
+//	      properties:
+//	        config:
+//	          type: object
+//	          description: The hardware watchdog configuration
+//	          example: {"enabled":true,"timeout":60,"daemon":true}
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func (s *Server) apiSystemWatchdog(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		// Return the current watchdog state.
+		s.state.System.Watchdog.State = systemd.GetWatchdogState()
+
+		_ = response.SyncResponse(true, s.state.System.Watchdog).Render(w)
+	case http.MethodPut:
+		watchdogData := &api.SystemWatchdog{}
+
+		err := json.NewDecoder(r.Body).Decode(watchdogData)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		err = systemd.ValidateWatchdog(watchdogData.Config)
+		if err != nil {
+			_ = response.BadRequest(err).Render(w)
+
+			return
+		}
+
+		// Apply new configuration.
+		err = systemd.ApplyWatchdog(r.Context(), watchdogData.Config)
+		if err != nil {
+			_ = response.InternalError(err).Render(w)
+
+			return
+		}
+
+		// Persist the configuration, the incus-osd watchdog picking it up on its next check.
+		s.state.System.Watchdog.Config = watchdogData.Config
+
+		_ = s.state.Save()
+
+		_ = response.EmptySyncResponse.Render(w)
+	default:
+		// If none of the supported methods, return NotImplemented.
+		_ = response.NotImplemented(nil).Render(w)
+	}
+}
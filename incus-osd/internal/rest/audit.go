@@ -0,0 +1,91 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/audit"
+)
+
+// statusRecorder keeps track of the status code returned by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+
+	statusCode int
+}
+
+func (w *statusRecorder) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// getConfigSnapshot returns the parts of the state which are recorded in the audit log. The
+// snapshot is marshaled to JSON, so it isn't affected by later changes to the state.
+func (s *Server) getConfigSnapshot() (json.RawMessage, error) {
+	return json.Marshal(map[string]any{
+		"applications": slices.Sorted(maps.Keys(s.state.Applications)),
+		"services":     s.state.Services,
+		"system":       s.state.System,
+	})
+}
+
+// recordAudit wraps a handler, recording every request which may change the system in the audit log.
+func (s *Server) recordAudit(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			handler.ServeHTTP(w, r)
+
+			return
+		}
+
+		before, beforeErr := s.getConfigSnapshot()
+		recorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+
+		handler.ServeHTTP(recorder, r)
+
+		entry := api.SystemLoggingAuditEntry{
+			Timestamp:  time.Now().UTC().Format(time.RFC3339),
+			Identity:   getIdentity(r),
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			StatusCode: recorder.statusCode,
+			Changes:    []api.SystemLoggingAuditChange{},
+		}
+
+		after, afterErr := s.getConfigSnapshot()
+
+		var changes []api.SystemLoggingAuditChange
+
+		err := errors.Join(beforeErr, afterErr)
+		if err == nil {
+			changes, err = audit.Diff(before, after)
+		}
+
+		if err != nil {
+			slog.WarnContext(r.Context(), "Failed to compute audit log changes", "err", err)
+		}
+
+		// Only keep configuration changes, as the state may be updated in the background.
+		for _, change := range changes {
+			if strings.HasPrefix(change.Field, "applications") || strings.Contains("."+change.Field+".", ".config.") {
+				entry.Changes = append(entry.Changes, change)
+			}
+		}
+
+		err = audit.Record(r.Context(), s.state.System.Logging.Config.Audit, entry)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Failed to record audit log entry", "err", err)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// identityHeader is set by the proxying application to the certificate fingerprint or OIDC subject of the
+// caller. It's only trusted on connections from a proxy, which overwrites any value sent by the client.
+const identityHeader = "X-IncusOS-Identity"
+
+// proxyUnits lists the systemd units of the applications exposing the API, which authenticate the callers
+// before forwarding their requests.
+var proxyUnits = []string{"incus.service", "migration-manager.service", "operations-center.service"}
+
+type callerKey struct{}
+
+// caller holds the origin of a request, as established from the peer credentials of the connection
+// rather than from anything the client controls.
+type caller struct {
+	local   bool // A local root process, connecting directly to the socket.
+	proxied bool // A request forwarded by one of the proxying applications.
+}
+
+// connCaller records the origin of each connection, for use as the server's ConnContext. Connections
+// whose peer can't be established get no privilege.
+func connCaller(ctx context.Context, c net.Conn) context.Context {
+	conn, ok := c.(*net.UnixConn)
+	if !ok {
+		return ctx
+	}
+
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		return ctx
+	}
+
+	var cred *unix.Ucred
+
+	err = rawConn.Control(func(fd uintptr) {
+		cred, err = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	})
+	if err != nil || cred == nil || cred.Uid != 0 {
+		return ctx
+	}
+
+	// Check whether the peer is one of the proxying applications, based on its cgroup.
+	cgroup, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", cred.Pid))
+	if err != nil {
+		return ctx
+	}
+
+	for line := range strings.SplitSeq(strings.TrimSpace(string(cgroup)), "\n") {
+		_, path, _ := strings.Cut(line, "::")
+		if slices.Contains(proxyUnits, path[strings.LastIndex(path, "/")+1:]) {
+			return context.WithValue(ctx, callerKey{}, caller{proxied: true})
+		}
+	}
+
+	return context.WithValue(ctx, callerKey{}, caller{local: true})
+}
+
+// getCaller returns the origin of a request.
+func getCaller(r *http.Request) caller {
+	c, _ := r.Context().Value(callerKey{}).(caller)
+
+	return c
+}
+
+// getIdentity returns the identity of the caller as vouched for by the proxy, if any.
+func getIdentity(r *http.Request) string {
+	if !getCaller(r).proxied {
+		return ""
+	}
+
+	return r.Header.Get(identityHeader)
+}
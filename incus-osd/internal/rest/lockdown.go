@@ -0,0 +1,32 @@
+package rest
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/lxc/incus-os/incus-osd/internal/rest/response"
+)
+
+// lockdownAllowedEndpoints lists the endpoints which can still be used with a mutating method while
+// the system is in lockdown mode, as they don't change the system.
+var lockdownAllowedEndpoints = []string{
+	"/1.0/system/security/:attest",
+	"/1.0/system/security/:unlock",
+}
+
+// checkLockdown wraps a handler, rejecting all the requests which may change the system while it's
+// in lockdown mode. Only reading the state, attesting the system and unlocking it remain possible.
+func (s *Server) checkLockdown(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.state.System.Security.Config.Lockdown || r.Method == http.MethodGet || r.Method == http.MethodHead || slices.Contains(lockdownAllowedEndpoints, r.URL.Path) {
+			handler.ServeHTTP(w, r)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		// Use a dedicated status code, so the rejection isn't mistaken for an authentication failure.
+		_ = response.ErrorResponse(http.StatusLocked, "the system is in lockdown mode, changes are rejected until unlocked").Render(w)
+	})
+}
@@ -8,21 +8,24 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/lxc/incus-os/incus-osd/internal/reset"
 	"github.com/lxc/incus-os/incus-osd/internal/state"
 )
 
 // Server holds the internal state of the REST API server.
 type Server struct {
-	socketPath string
-	state      *state.State
+	socketPath   string
+	state        *state.State
+	resetConfirm *reset.Confirmation
 }
 
 // NewServer returns a REST API server object.
 func NewServer(_ context.Context, s *state.State, socketPath string) (*Server, error) {
 	// Define the struct.
 	server := Server{
-		socketPath: socketPath,
-		state:      s,
+		socketPath:   socketPath,
+		state:        s,
+		resetConfirm: &reset.Confirmation{},
 	}
 
 	// Create runtime path if missing.
@@ -60,7 +63,15 @@ func (s *Server) Serve(ctx context.Context) error {
 	router.HandleFunc("/1.0/debug/log", s.apiDebugLog)
 	router.HandleFunc("/1.0/debug/secureboot/:update", s.apiDebugSecureBootUpdate)
 	router.HandleFunc("/1.0/debug/tui/:write-message", s.apiDebugTUI)
+	router.HandleFunc("/1.0/metrics", s.apiMetrics)
 	router.HandleFunc("/1.0/services", s.apiServices)
+	router.HandleFunc("/1.0/services/drbd/:demote", s.apiServicesDRBDDemote)
+	router.HandleFunc("/1.0/services/drbd/:promote", s.apiServicesDRBDPromote)
+	router.HandleFunc("/1.0/services/ipmi/:identify", s.apiServicesIPMIIdentify)
+	router.HandleFunc("/1.0/services/ipmi/:power-cycle", s.apiServicesIPMIPowerCycle)
+	router.HandleFunc("/1.0/services/redfish/:eject-media", s.apiServicesRedfishEjectMedia)
+	router.HandleFunc("/1.0/services/redfish/:insert-media", s.apiServicesRedfishInsertMedia)
+	router.HandleFunc("/1.0/services/redfish/:power", s.apiServicesRedfishPower)
 	router.HandleFunc("/1.0/services/{name}", s.apiServicesEndpoint)
 	router.HandleFunc("/1.0/services/{name}/:reset", s.apiServicesEndpointReset)
 	router.HandleFunc("/1.0/system", s.apiSystem)
@@ -69,25 +80,66 @@ func (s *Server) Serve(ctx context.Context) error {
 	router.HandleFunc("/1.0/system/:poweroff", s.apiSystemPoweroff)
 	router.HandleFunc("/1.0/system/:reboot", s.apiSystemReboot)
 	router.HandleFunc("/1.0/system/:restore", s.apiSystemRestore)
+	router.HandleFunc("/1.0/system/extensions", s.apiSystemExtensions)
+	router.HandleFunc("/1.0/system/extensions/{name}", s.apiSystemExtensionsEndpoint)
+	router.HandleFunc("/1.0/system/extensions/{name}/:activate", s.apiSystemExtensionsActivate)
+	router.HandleFunc("/1.0/system/extensions/{name}/:deactivate", s.apiSystemExtensionsDeactivate)
+	router.HandleFunc("/1.0/system/firmware", s.apiSystemFirmware)
+	router.HandleFunc("/1.0/system/firmware/:apply", s.apiSystemFirmwareApply)
+	router.HandleFunc("/1.0/system/kernel", s.apiSystemKernel)
 	router.HandleFunc("/1.0/system/logging", s.apiSystemLogging)
+	router.HandleFunc("/1.0/system/logging/audit", s.apiSystemLoggingAudit)
 	router.HandleFunc("/1.0/system/network", s.apiSystemNetwork)
+	router.HandleFunc("/1.0/system/network/:confirm", s.apiSystemNetworkConfirm)
+	router.HandleFunc("/1.0/system/network/:try", s.apiSystemNetworkTry)
 	router.HandleFunc("/1.0/system/provider", s.apiSystemProvider)
 	router.HandleFunc("/1.0/system/resources", s.apiSystemResources)
 	router.HandleFunc("/1.0/system/security", s.apiSystemSecurity)
+	router.HandleFunc("/1.0/system/security/:add-ca-certificate", s.apiSystemSecurityAddCACertificate)
+	router.HandleFunc("/1.0/system/security/:add-trusted-certificate", s.apiSystemSecurityAddTrustedCertificate)
+	router.HandleFunc("/1.0/system/security/:attest", s.apiSystemSecurityAttest)
+	router.HandleFunc("/1.0/system/security/:clear-tpm-unlock-warning", s.apiSystemSecurityClearTPMUnlockWarning)
+	router.HandleFunc("/1.0/system/security/:delete-secret", s.apiSystemSecurityDeleteSecret)
+	router.HandleFunc("/1.0/system/security/:fido2-enroll", s.apiSystemSecurityFIDO2Enroll)
+	router.HandleFunc("/1.0/system/security/:fido2-remove", s.apiSystemSecurityFIDO2Remove)
+	router.HandleFunc("/1.0/system/security/:generate-recovery-key", s.apiSystemSecurityGenerateRecoveryKey)
+	router.HandleFunc("/1.0/system/security/:invalidate-recovery-key", s.apiSystemSecurityInvalidateRecoveryKey)
+	router.HandleFunc("/1.0/system/security/:rotate-recovery-keys", s.apiSystemSecurityRotateRecoveryKeys)
+	router.HandleFunc("/1.0/system/security/:lock", s.apiSystemSecurityLock)
+	router.HandleFunc("/1.0/system/security/:remove-ca-certificate", s.apiSystemSecurityRemoveCACertificate)
+	router.HandleFunc("/1.0/system/security/:remove-trusted-certificate", s.apiSystemSecurityRemoveTrustedCertificate)
+	router.HandleFunc("/1.0/system/security/:secureboot-enroll", s.apiSystemSecuritySecureBootEnroll)
+	router.HandleFunc("/1.0/system/security/:set-secret", s.apiSystemSecuritySetSecret)
 	router.HandleFunc("/1.0/system/security/:tpm-rebind", s.apiSystemSecurityTPMRebind)
+	router.HandleFunc("/1.0/system/security/:tpm-reseal", s.apiSystemSecurityTPMReseal)
+	router.HandleFunc("/1.0/system/security/:unlock", s.apiSystemSecurityUnlock)
+	router.HandleFunc("/1.0/system/security/:usb-authorize", s.apiSystemSecurityUSBAuthorize)
+	router.HandleFunc("/1.0/system/security/posture", s.apiSystemSecurityPosture)
+	router.HandleFunc("/1.0/system/security/sbom", s.apiSystemSecuritySBOM)
 	router.HandleFunc("/1.0/system/storage", s.apiSystemStorage)
 	router.HandleFunc("/1.0/system/storage/:create-volume", s.apiSystemStorageCreateVolume)
 	router.HandleFunc("/1.0/system/storage/:delete-pool", s.apiSystemStorageDeletePool)
 	router.HandleFunc("/1.0/system/storage/:delete-volume", s.apiSystemStorageDeleteVolume)
+	router.HandleFunc("/1.0/system/storage/:discard-checkpoint", s.apiSystemStorageDiscardCheckpoint)
 	router.HandleFunc("/1.0/system/storage/:import-pool", s.apiSystemStorageImportPool)
+	router.HandleFunc("/1.0/system/storage/iostat", s.apiSystemStorageIOStats)
+	router.HandleFunc("/1.0/system/storage/:rewind-checkpoint", s.apiSystemStorageRewindCheckpoint)
 	router.HandleFunc("/1.0/system/storage/:wipe-drive", s.apiSystemStorageWipeDrive)
 	router.HandleFunc("/1.0/system/storage/:scrub-pool", s.apiSystemStorageScrubPool)
+	router.HandleFunc("/1.0/system/storage/:update-volume", s.apiSystemStorageUpdateVolume)
 	router.HandleFunc("/1.0/system/update", s.apiSystemUpdate)
 	router.HandleFunc("/1.0/system/update/:check", s.apiSystemUpdateCheck)
+	router.HandleFunc("/1.0/system/update/:export-bundle", s.apiSystemUpdateExportBundle)
+	router.HandleFunc("/1.0/system/update/:import-bundle", s.apiSystemUpdateImportBundle)
+	router.HandleFunc("/1.0/system/update/:apply", s.apiSystemUpdateApply)
+	router.HandleFunc("/1.0/system/update/:rollback", s.apiSystemUpdateRollback)
+	router.HandleFunc("/1.0/system/update/:stage", s.apiSystemUpdateStage)
+	router.HandleFunc("/1.0/system/watchdog", s.apiSystemWatchdog)
 
 	// Setup server.
 	server := &http.Server{
-		Handler: router,
+		Handler:     s.recordAudit(s.checkLockdown(s.checkAccess(router))),
+		ConnContext: connCaller,
 
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 0,
@@ -0,0 +1,3 @@
+// Package sbom provides logic to list the software bill of materials of the running system and check
+// it for known vulnerabilities.
+package sbom
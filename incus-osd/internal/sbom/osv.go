@@ -0,0 +1,145 @@
+package sbom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// DefaultVulnerabilityFeed is the OSV API used when no vulnerability feed is configured.
+const DefaultVulnerabilityFeed = "https://api.osv.dev"
+
+// osvEcosystem is the OSV ecosystem of the packages, matching the Debian release IncusOS is built from.
+const osvEcosystem = "Debian:13"
+
+// osvBatchSize is the maximum number of queries accepted by the OSV API in a single request.
+const osvBatchSize = 1000
+
+type osvQuery struct {
+	Package struct {
+		Name      string `json:"name"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	Version string `json:"version"`
+}
+
+type osvBatchResponse struct {
+	Results []struct {
+		Vulns []struct {
+			ID string `json:"id"`
+		} `json:"vulns"`
+	} `json:"results"`
+}
+
+// ValidateVulnerabilityFeed checks that the vulnerability feed is a usable URL.
+func ValidateVulnerabilityFeed(feed string) error {
+	if feed == "" {
+		return nil
+	}
+
+	feedURL, err := url.Parse(feed)
+	if err != nil {
+		return fmt.Errorf("invalid vulnerability feed: %w", err)
+	}
+
+	if (feedURL.Scheme != "https" && feedURL.Scheme != "http") || feedURL.Host == "" {
+		return errors.New("vulnerability feed must be an http or https URL")
+	}
+
+	return nil
+}
+
+// CheckVulnerabilities looks up the known vulnerabilities of each package's source using the OSV
+// batch query API of the vulnerability feed.
+func CheckVulnerabilities(ctx context.Context, feed string, packages []api.SystemSecuritySBOMPackage) error {
+	if feed == "" {
+		feed = DefaultVulnerabilityFeed
+	}
+
+	// Query each source package only once.
+	queries := []osvQuery{}
+	indexes := map[osvQuery][]int{}
+
+	for i, pkg := range packages {
+		packages[i].Vulnerabilities = []string{}
+
+		query := osvQuery{Version: pkg.SourceVersion}
+		query.Package.Name = pkg.SourceName
+		query.Package.Ecosystem = osvEcosystem
+
+		_, ok := indexes[query]
+		if !ok {
+			queries = append(queries, query)
+		}
+
+		indexes[query] = append(indexes[query], i)
+	}
+
+	for batch := range slices.Chunk(queries, osvBatchSize) {
+		resp, err := queryOSV(ctx, feed, batch)
+		if err != nil {
+			return err
+		}
+
+		if len(resp.Results) != len(batch) {
+			return fmt.Errorf("unexpected number of results from vulnerability feed: %d instead of %d", len(resp.Results), len(batch))
+		}
+
+		for i, result := range resp.Results {
+			for _, vuln := range result.Vulns {
+				for _, index := range indexes[batch[i]] {
+					packages[index].Vulnerabilities = append(packages[index].Vulnerabilities, vuln.ID)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// queryOSV sends a batch of queries to the OSV API.
+func queryOSV(ctx context.Context, feed string, queries []osvQuery) (*osvBatchResponse, error) {
+	body, err := json.Marshal(map[string][]osvQuery{"queries": queries})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(feed, "/")+"/v1/querybatch", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to query vulnerability feed: %s", resp.Status)
+	}
+
+	ret := &osvBatchResponse{}
+
+	err = json.NewDecoder(resp.Body).Decode(ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
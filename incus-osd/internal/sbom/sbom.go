@@ -0,0 +1,83 @@
+package sbom
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// Path is the directory holding the list of packages of each image, generated at build time.
+var Path = "/usr/lib/incus-os/sbom"
+
+// List returns the packages included in the running images.
+func List() ([]api.SystemSecuritySBOMPackage, error) {
+	ret := []api.SystemSecuritySBOMPackage{}
+
+	files, err := filepath.Glob(filepath.Join(Path, "*.tsv"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file) //nolint:gosec
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		packages, err := parsePackages(strings.TrimSuffix(filepath.Base(file), ".tsv"), string(content))
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, packages...)
+	}
+
+	return ret, nil
+}
+
+// parsePackages parses a list of packages, with each line holding the tab separated name, version,
+// architecture, source name and source version of a package.
+func parsePackages(component string, content string) ([]api.SystemSecuritySBOMPackage, error) {
+	ret := []api.SystemSecuritySBOMPackage{}
+
+	for line := range strings.SplitSeq(content, "\n") {
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("invalid package entry %q in %q", line, component)
+		}
+
+		pkg := api.SystemSecuritySBOMPackage{
+			Component:     component,
+			Name:          fields[0],
+			Version:       fields[1],
+			Architecture:  fields[2],
+			SourceName:    fields[3],
+			SourceVersion: fields[4],
+		}
+
+		// The source is only set when it differs from the binary package.
+		if pkg.SourceName == "" {
+			pkg.SourceName = pkg.Name
+		}
+
+		if pkg.SourceVersion == "" {
+			pkg.SourceVersion = pkg.Version
+		}
+
+		ret = append(ret, pkg)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,65 @@
+package sbom
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+func TestParsePackages(t *testing.T) {
+	t.Parallel()
+
+	packages, err := parsePackages("base", "libssl3t64\t3.5.1-1\tamd64\topenssl\t3.5.1-1\nzstd\t1.5.7+dfsg-1\tamd64\t\t\n")
+	require.NoError(t, err)
+	require.Equal(t, []api.SystemSecuritySBOMPackage{
+		{Component: "base", Name: "libssl3t64", Version: "3.5.1-1", Architecture: "amd64", SourceName: "openssl", SourceVersion: "3.5.1-1"},
+		{Component: "base", Name: "zstd", Version: "1.5.7+dfsg-1", Architecture: "amd64", SourceName: "zstd", SourceVersion: "1.5.7+dfsg-1"},
+	}, packages)
+
+	_, err = parsePackages("base", "zstd 1.5.7+dfsg-1\n")
+	require.Error(t, err)
+}
+
+func TestCheckVulnerabilities(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/querybatch", r.URL.Path)
+
+		req := map[string][]osvQuery{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		// Both openssl binary packages share a single query.
+		require.Len(t, req["queries"], 2)
+		require.Equal(t, "openssl", req["queries"][0].Package.Name)
+		require.Equal(t, osvEcosystem, req["queries"][0].Package.Ecosystem)
+
+		_, _ = w.Write([]byte(`{"results":[{"vulns":[{"id":"CVE-2025-9230"},{"id":"CVE-2025-9231"}]},{}]}`))
+	}))
+	defer server.Close()
+
+	packages := []api.SystemSecuritySBOMPackage{
+		{Name: "libssl3t64", SourceName: "openssl", SourceVersion: "3.5.1-1"},
+		{Name: "openssl", SourceName: "openssl", SourceVersion: "3.5.1-1"},
+		{Name: "zstd", SourceName: "zstd", SourceVersion: "1.5.7+dfsg-1"},
+	}
+
+	require.NoError(t, CheckVulnerabilities(t.Context(), server.URL, packages))
+	require.Equal(t, []string{"CVE-2025-9230", "CVE-2025-9231"}, packages[0].Vulnerabilities)
+	require.Equal(t, []string{"CVE-2025-9230", "CVE-2025-9231"}, packages[1].Vulnerabilities)
+	require.Empty(t, packages[2].Vulnerabilities)
+	require.NotNil(t, packages[2].Vulnerabilities)
+}
+
+func TestValidateVulnerabilityFeed(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidateVulnerabilityFeed(""))
+	require.NoError(t, ValidateVulnerabilityFeed("https://osv.example.com"))
+	require.Error(t, ValidateVulnerabilityFeed("osv.example.com"))
+}
@@ -0,0 +1,3 @@
+// Package secrets provides logic to resolve the secret references found in service configurations,
+// fetching the values from the configured secrets provider at use-time.
+package secrets
@@ -0,0 +1,121 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+)
+
+// LocalPath is the directory holding the locally stored secrets, sealed by the TPM.
+var LocalPath = "/var/lib/incus-os/secrets"
+
+// ErrNotFound is returned when a locally stored secret doesn't exist.
+var ErrNotFound = errors.New("secret not found")
+
+var nameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// ValidateName checks that the name of a locally stored secret is usable.
+func ValidateName(name string) error {
+	if !nameRegex.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q", name)
+	}
+
+	return nil
+}
+
+// ListLocal returns the names of the locally stored secrets.
+func ListLocal() ([]string, error) {
+	ret := []string{}
+
+	entries, err := os.ReadDir(LocalPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ret, nil
+		}
+
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".cred")
+		if ok {
+			ret = append(ret, name)
+		}
+	}
+
+	slices.Sort(ret)
+
+	return ret, nil
+}
+
+// SetLocal stores a secret locally, sealed by the TPM so it can only be read on this system.
+func SetLocal(ctx context.Context, name string, value string) error {
+	err := ValidateName(name)
+	if err != nil {
+		return err
+	}
+
+	if value == "" {
+		return errors.New("secret value can't be empty")
+	}
+
+	err = os.MkdirAll(LocalPath, 0o700)
+	if err != nil {
+		return err
+	}
+
+	return subprocess.RunCommandWithFds(ctx, strings.NewReader(value), nil, "systemd-creds", "encrypt", "--with-key=tpm2", "--name="+name, "-", getLocalPath(name))
+}
+
+// DeleteLocal removes a locally stored secret.
+func DeleteLocal(name string) error {
+	err := ValidateName(name)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(getLocalPath(name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// getLocal returns the value of a locally stored secret.
+func getLocal(ctx context.Context, name string) (string, error) {
+	_, err := os.Stat(getLocalPath(name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("%w: %q", ErrNotFound, name)
+		}
+
+		return "", err
+	}
+
+	var value bytes.Buffer
+
+	err = subprocess.RunCommandWithFds(ctx, nil, &value, "systemd-creds", "decrypt", "--name="+name, getLocalPath(name), "-")
+	if err != nil {
+		return "", err
+	}
+
+	return value.String(), nil
+}
+
+// getLocalPath returns the path of a locally stored secret.
+func getLocalPath(name string) string {
+	return filepath.Join(LocalPath, name+".cred")
+}
@@ -0,0 +1,98 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// referencePrefix is the prefix of the values referencing a secret, rather than holding it.
+const referencePrefix = "secret://"
+
+// IsReference returns whether the value references a secret.
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, referencePrefix)
+}
+
+// ValidateConfig checks that the secrets providers configuration is usable.
+func ValidateConfig(cfg *api.SystemSecuritySecrets) error {
+	if cfg == nil || cfg.Vault == nil {
+		return nil
+	}
+
+	return validateVaultConfig(cfg.Vault)
+}
+
+// ValidateReference checks that a value is either a plain value, or a reference to a secret from a
+// configured provider.
+func ValidateReference(cfg *api.SystemSecuritySecrets, value string) error {
+	if !IsReference(value) {
+		return nil
+	}
+
+	provider, _, err := parseReference(value)
+	if err != nil {
+		return err
+	}
+
+	if provider == "vault" && (cfg == nil || cfg.Vault == nil) {
+		return fmt.Errorf("secret %q references Vault, which isn't configured", value)
+	}
+
+	return nil
+}
+
+// Resolve returns the value of a secret reference, fetched from its provider. Any other value is
+// returned unchanged.
+func Resolve(ctx context.Context, cfg *api.SystemSecuritySecrets, value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+
+	provider, path, err := parseReference(value)
+	if err != nil {
+		return "", err
+	}
+
+	switch provider {
+	case "local":
+		return getLocal(ctx, path)
+	case "vault":
+		if cfg == nil || cfg.Vault == nil {
+			return "", fmt.Errorf("secret %q references Vault, which isn't configured", value)
+		}
+
+		return getVault(ctx, cfg.Vault, path)
+	}
+
+	return "", fmt.Errorf("unsupported secrets provider %q", provider)
+}
+
+// parseReference returns the provider and the provider-specific path of a secret reference, such as
+// "secret://local/NAME" or "secret://vault/PATH#KEY".
+func parseReference(value string) (string, string, error) {
+	provider, path, ok := strings.Cut(strings.TrimPrefix(value, referencePrefix), "/")
+	if !ok || path == "" {
+		return "", "", fmt.Errorf("invalid secret reference %q", value)
+	}
+
+	switch provider {
+	case "local":
+		if !nameRegex.MatchString(path) {
+			return "", "", fmt.Errorf("invalid secret reference %q, bad secret name", value)
+		}
+
+	case "vault":
+		secretPath, key, ok := strings.Cut(path, "#")
+		if !ok || secretPath == "" || key == "" {
+			return "", "", fmt.Errorf("invalid secret reference %q, expected \"secret://vault/PATH#KEY\"", value)
+		}
+
+	default:
+		return "", "", fmt.Errorf("unsupported secrets provider %q", provider)
+	}
+
+	return provider, path, nil
+}
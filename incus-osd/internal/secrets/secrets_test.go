@@ -0,0 +1,96 @@
+package secrets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+func TestValidateReference(t *testing.T) {
+	t.Parallel()
+
+	vault := &api.SystemSecuritySecrets{Vault: &api.SystemSecuritySecretsVault{Address: "https://vault.example.com", Token: "token"}}
+
+	require.NoError(t, ValidateReference(nil, "plain-value"))
+	require.NoError(t, ValidateReference(nil, "secret://local/kopia-password"))
+	require.NoError(t, ValidateReference(vault, "secret://vault/backup/s3#secret_key"))
+	require.Error(t, ValidateReference(nil, "secret://vault/backup/s3#secret_key"))
+	require.Error(t, ValidateReference(vault, "secret://vault/backup/s3"))
+	require.Error(t, ValidateReference(nil, "secret://local/../password"))
+	require.Error(t, ValidateReference(nil, "secret://aws/password"))
+	require.Error(t, ValidateReference(nil, "secret://local"))
+}
+
+func TestResolveVault(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		if r.URL.Path != "/v1/kv/data/backup/s3" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":{"data":{"secret_key":"s3cr3t"},"metadata":{"version":1}}}`))
+	}))
+	defer server.Close()
+
+	cfg := &api.SystemSecuritySecrets{Vault: &api.SystemSecuritySecretsVault{Address: server.URL, Token: "token", Mount: "kv"}}
+
+	value, err := Resolve(t.Context(), cfg, "secret://vault/backup/s3#secret_key")
+	require.NoError(t, err)
+	require.Equal(t, "s3cr3t", value)
+
+	_, err = Resolve(t.Context(), cfg, "secret://vault/backup/s3#access_key")
+	require.Error(t, err)
+
+	_, err = Resolve(t.Context(), cfg, "secret://vault/backup/missing#secret_key")
+	require.Error(t, err)
+
+	value, err = Resolve(t.Context(), nil, "plain-value")
+	require.NoError(t, err)
+	require.Equal(t, "plain-value", value)
+}
+
+func TestIssueCertificate(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/pki/issue/ovn" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":{"certificate":"CERT","private_key":"KEY","issuing_ca":"CA"}}`))
+	}))
+	defer server.Close()
+
+	cfg := &api.SystemSecuritySecrets{Vault: &api.SystemSecuritySecretsVault{Address: server.URL, Token: "token"}}
+
+	cert, err := IssueCertificate(t.Context(), cfg, "", "ovn", "server01", "")
+	require.NoError(t, err)
+	require.Equal(t, &Certificate{Certificate: "CERT", PrivateKey: "KEY", CA: "CA"}, cert)
+
+	_, err = IssueCertificate(t.Context(), cfg, "", "missing", "server01", "")
+	require.Error(t, err)
+
+	_, err = IssueCertificate(t.Context(), nil, "", "ovn", "server01", "")
+	require.Error(t, err)
+}
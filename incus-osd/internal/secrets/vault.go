@@ -0,0 +1,167 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// validateVaultConfig checks that the Vault configuration is usable.
+func validateVaultConfig(cfg *api.SystemSecuritySecretsVault) error {
+	vaultURL, err := url.Parse(cfg.Address)
+	if err != nil {
+		return fmt.Errorf("invalid Vault address: %w", err)
+	}
+
+	if (vaultURL.Scheme != "https" && vaultURL.Scheme != "http") || vaultURL.Host == "" {
+		return errors.New("vault address must be an http or https URL")
+	}
+
+	if cfg.Token == "" {
+		return errors.New("vault token must be provided")
+	}
+
+	return nil
+}
+
+// getVault fetches a secret from a Vault KV version 2 secrets engine. The path is formatted as
+// "PATH#KEY", KEY being the field of the secret to return.
+func getVault(ctx context.Context, cfg *api.SystemSecuritySecretsVault, path string) (string, error) {
+	secretPath, key, _ := strings.Cut(path, "#")
+
+	mount := cfg.Mount
+	if mount == "" {
+		mount = "secret"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	req, err := newVaultRequest(ctx, cfg, http.MethodGet, strings.Trim(mount, "/")+"/data/"+strings.Trim(secretPath, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch secret %q from Vault: %s", secretPath, resp.Status)
+	}
+
+	body := struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}{}
+
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := body.Data.Data[key].(string)
+	if !ok {
+		return "", fmt.Errorf("secret %q from Vault has no %q string field", secretPath, key)
+	}
+
+	return value, nil
+}
+
+// Certificate is a certificate issued by a Vault PKI secrets engine, along with its private key and
+// the certificate of its issuing CA, all PEM encoded.
+type Certificate struct {
+	Certificate string
+	PrivateKey  string
+	CA          string
+}
+
+// IssueCertificate issues a new certificate from a Vault PKI secrets engine, using the role's defaults
+// for the validity if no TTL is provided.
+func IssueCertificate(ctx context.Context, cfg *api.SystemSecuritySecrets, mount string, role string, commonName string, ttl string) (*Certificate, error) {
+	if cfg == nil || cfg.Vault == nil {
+		return nil, errors.New("vault isn't configured")
+	}
+
+	if mount == "" {
+		mount = "pki"
+	}
+
+	reqBody, err := json.Marshal(map[string]string{"common_name": commonName, "ttl": ttl})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	req, err := newVaultRequest(ctx, cfg.Vault, http.MethodPost, strings.Trim(mount, "/")+"/issue/"+role, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to issue certificate from Vault role %q: %s", role, resp.Status)
+	}
+
+	body := struct {
+		Data struct {
+			Certificate string `json:"certificate"`
+			PrivateKey  string `json:"private_key"`
+			IssuingCA   string `json:"issuing_ca"`
+		} `json:"data"`
+	}{}
+
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return nil, err
+	}
+
+	if body.Data.Certificate == "" || body.Data.PrivateKey == "" {
+		return nil, fmt.Errorf("vault role %q returned no certificate", role)
+	}
+
+	return &Certificate{
+		Certificate: body.Data.Certificate,
+		PrivateKey:  body.Data.PrivateKey,
+		CA:          body.Data.IssuingCA,
+	}, nil
+}
+
+// newVaultRequest prepares an authenticated request to the Vault API.
+func newVaultRequest(ctx context.Context, cfg *api.SystemSecuritySecretsVault, method string, path string, body io.Reader) (*http.Request, error) {
+	reqURL := strings.TrimSuffix(cfg.Address, "/") + "/v1/" + path
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-Vault-Token", cfg.Token)
+
+	if cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", cfg.Namespace)
+	}
+
+	return req, nil
+}
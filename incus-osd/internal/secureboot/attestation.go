@@ -0,0 +1,200 @@
+package secureboot
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// attestationKeyHandle is the persistent TPM handle the attestation key is stored at.
+const attestationKeyHandle = "0x81010002"
+
+// attestationKeyMu prevents concurrent requests from both creating the attestation key.
+var attestationKeyMu sync.Mutex
+
+// DefaultAttestationPCRs is the list of PCRs quoted when none are requested: firmware, option ROMs,
+// boot loader, Secure Boot policy and the UKI measurements.
+var DefaultAttestationPCRs = []int{0, 2, 4, 7, 11}
+
+// ValidateAttestationRequest checks that the nonce and PCR list of an attestation request are usable.
+func ValidateAttestationRequest(req *api.SystemSecurityAttestationRequest) error {
+	// The nonce is passed to the TPM as qualifying data, which is limited to the size of a SHA256 digest.
+	rawNonce, err := hex.DecodeString(req.Nonce)
+	if err != nil {
+		return fmt.Errorf("invalid nonce: %w", err)
+	}
+
+	if len(rawNonce) == 0 || len(rawNonce) > 32 {
+		return errors.New("nonce must be between 1 and 32 bytes long")
+	}
+
+	for _, pcr := range req.PCRs {
+		if pcr < 0 || pcr > 23 {
+			return fmt.Errorf("invalid PCR %d", pcr)
+		}
+	}
+
+	return nil
+}
+
+// GetAttestation returns a TPM quote over the requested PCRs, signed by the system's attestation key,
+// along with the current PCR values, TPM event log and OS version so an external verifier can check
+// that the system is running an unmodified, signed image. The request must have been validated with
+// ValidateAttestationRequest.
+func GetAttestation(ctx context.Context, osName string, osVersion string, req *api.SystemSecurityAttestationRequest) (*api.SystemSecurityAttestation, error) {
+	pcrs := req.PCRs
+	if len(pcrs) == 0 {
+		pcrs = DefaultAttestationPCRs
+
+		// Also cover the runtime measurements when IMA is enabled.
+		if GetIMAStatus() == "enabled" {
+			pcrs = append(slices.Clone(pcrs), imaPCR)
+		}
+	}
+
+	pcrs = slices.Clone(pcrs)
+	slices.Sort(pcrs)
+	pcrs = slices.Compact(pcrs)
+
+	pcrList := make([]string, 0, len(pcrs))
+	for _, pcr := range pcrs {
+		pcrList = append(pcrList, strconv.Itoa(pcr))
+	}
+
+	tmpDir, err := os.MkdirTemp("", "incus-os-attestation")
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.RemoveAll(tmpDir)
+
+	ret := &api.SystemSecurityAttestation{
+		Nonce:     req.Nonce,
+		OSName:    osName,
+		OSVersion: osVersion,
+		PCRs:      map[int]string{},
+	}
+
+	// Get the endorsement key, which is deterministically derived by the TPM.
+	ret.EndorsementKey, err = getEndorsementKey(ctx, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the attestation key, creating it on first use.
+	ret.AttestationKey, err = getAttestationKey(ctx, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Have the TPM produce and sign the quote.
+	quoteFile := filepath.Join(tmpDir, "quote.msg")
+	signatureFile := filepath.Join(tmpDir, "quote.sig")
+
+	_, err = subprocess.RunCommandContext(ctx, "tpm2_quote", "-c", attestationKeyHandle, "-l", "sha256:"+strings.Join(pcrList, ","), "-q", req.Nonce, "-g", "sha256", "-m", quoteFile, "-s", signatureFile)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := os.ReadFile(quoteFile) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := os.ReadFile(signatureFile) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	ret.Quote = base64.StdEncoding.EncodeToString(quote)
+	ret.Signature = base64.StdEncoding.EncodeToString(signature)
+
+	// Include the PCR values covered by the quote's digest.
+	for _, pcr := range pcrs {
+		value, err := readPCR(pcr)
+		if err != nil {
+			return nil, err
+		}
+
+		ret.PCRs[pcr] = hex.EncodeToString(value)
+	}
+
+	// Include the event log so the verifier can replay the measurements.
+	eventLog, err := readRawTPMEventLog()
+	if err != nil {
+		return nil, err
+	}
+
+	ret.EventLog = base64.StdEncoding.EncodeToString(eventLog)
+
+	// Include the IMA measurement log, if enabled, so the verifier can check the executed binaries.
+	imaLog, err := readIMALog()
+	if err != nil {
+		return nil, err
+	}
+
+	ret.IMALog = base64.StdEncoding.EncodeToString(imaLog)
+
+	return ret, nil
+}
+
+// getEndorsementKey returns the PEM-encoded public part of the TPM's RSA endorsement key.
+func getEndorsementKey(ctx context.Context, tmpDir string) (string, error) {
+	ekFile := filepath.Join(tmpDir, "ek.pem")
+
+	_, err := subprocess.RunCommandContext(ctx, "tpm2_createek", "-c", filepath.Join(tmpDir, "ek.ctx"), "-G", "rsa", "-u", ekFile, "-f", "pem")
+	if err != nil {
+		return "", err
+	}
+
+	ek, err := os.ReadFile(ekFile) //nolint:gosec
+	if err != nil {
+		return "", err
+	}
+
+	return string(ek), nil
+}
+
+// getAttestationKey returns the PEM-encoded public part of the persistent attestation key, creating
+// it under the endorsement key if it doesn't exist yet.
+func getAttestationKey(ctx context.Context, tmpDir string) (string, error) {
+	attestationKeyMu.Lock()
+	defer attestationKeyMu.Unlock()
+
+	akFile := filepath.Join(tmpDir, "ak.pem")
+
+	_, err := subprocess.RunCommandContext(ctx, "tpm2_readpublic", "-c", attestationKeyHandle, "-f", "pem", "-o", akFile)
+	if err != nil {
+		// Create a new restricted signing key and persist it.
+		akContext := filepath.Join(tmpDir, "ak.ctx")
+
+		_, err = subprocess.RunCommandContext(ctx, "tpm2_createak", "-C", filepath.Join(tmpDir, "ek.ctx"), "-c", akContext, "-G", "rsa", "-g", "sha256", "-s", "rsassa", "-u", akFile, "-f", "pem", "-n", filepath.Join(tmpDir, "ak.name"))
+		if err != nil {
+			return "", err
+		}
+
+		_, err = subprocess.RunCommandContext(ctx, "tpm2_evictcontrol", "-C", "o", "-c", akContext, attestationKeyHandle)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	ak, err := os.ReadFile(akFile) //nolint:gosec
+	if err != nil {
+		return "", err
+	}
+
+	return string(ak), nil
+}
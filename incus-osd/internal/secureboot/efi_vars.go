@@ -19,6 +19,7 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/google/go-eventlog/tcg"
 	"github.com/lxc/incus/v6/shared/subprocess"
@@ -208,10 +209,95 @@ func applySecureBootUpdates(ctx context.Context, varName string, newCerts map[st
 	return false, nil
 }
 
+// EnrollSecureBootKey applies a pre-signed (.auth) update to the PK, KEK or db EFI variable, allowing
+// organization-owned keys to be enrolled. The PK is replaced, while KEK and db updates are appended.
+// The firmware only accepts updates signed by a key currently in the PK (for PK and KEK) or KEK (for db).
+func EnrollSecureBootKey(ctx context.Context, varName string, authContents []byte) error {
+	if !slices.Contains([]string{"PK", "KEK", "db"}, varName) {
+		return fmt.Errorf("unsupported Secure Boot variable %q", varName)
+	}
+
+	if len(authContents) > 8192 {
+		return errors.New("update is greater than 8192 bytes, rejecting update")
+	}
+
+	certs, err := getCertificatesFromAuth(authContents)
+	if err != nil {
+		return err
+	}
+
+	if len(certs) != 1 {
+		return fmt.Errorf("expected exactly one certificate in %s update, got %d", varName, len(certs))
+	}
+
+	if time.Now().Before(certs[0].NotBefore) || time.Now().After(certs[0].NotAfter) {
+		return fmt.Errorf("certificate '%s' isn't currently valid, rejecting update", certs[0].Subject)
+	}
+
+	// Make sure the system can currently boot before changing anything.
+	err = VerifyBootComponents()
+	if err != nil {
+		return err
+	}
+
+	// Create a temp file for efi-updatevar to read from.
+	f, err := os.CreateTemp("", "incus-os-sb-enroll")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(authContents)
+	if err != nil {
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Enrolling certificate '"+certs[0].Subject.String()+"' into EFI variable "+varName)
+
+	return writeEFIVarUpdate(ctx, f.Name(), varName, varName != "PK")
+}
+
+// VerifyBootComponents checks that each UKI image is signed by a certificate present in the
+// Secure Boot db and not revoked in dbx.
+func VerifyBootComponents() error {
+	ukis, err := os.ReadDir("/boot/EFI/Linux/")
+	if err != nil {
+		return err
+	}
+
+	for _, uki := range ukis {
+		ukiFile := filepath.Join("/boot/EFI/Linux/", uki.Name())
+
+		ukiPubKey, err := getPublicKeyFromUKI(ukiFile)
+		if err != nil {
+			return err
+		}
+
+		err = validatePKICertificate(ukiPubKey)
+		if err != nil {
+			return fmt.Errorf("UKI image '%s' failed verification: %w", ukiFile, err)
+		}
+	}
+
+	return nil
+}
+
 // appendEFIVarUpdate takes a pre-signed (.auth) EFI variable update, appends it
 // to the current EFI value, and then updates the expected PCR7 value used to
 // decrypt the root file system and swap at boot.
 func appendEFIVarUpdate(ctx context.Context, efiUpdateFile string, varName string) error {
+	return writeEFIVarUpdate(ctx, efiUpdateFile, varName, true)
+}
+
+// writeEFIVarUpdate takes a pre-signed (.auth) EFI variable update, either appends it to or
+// replaces the current EFI value, and then updates the expected PCR7 value used to decrypt
+// the root file system and swap at boot.
+func writeEFIVarUpdate(ctx context.Context, efiUpdateFile string, varName string, appendValue bool) error {
 	// Verify the file exists.
 	_, err := os.Stat(efiUpdateFile)
 	if err != nil {
@@ -253,7 +339,12 @@ func appendEFIVarUpdate(ctx context.Context, efiUpdateFile string, varName strin
 	}
 
 	// Apply the EFI variable update.
-	_, err = subprocess.RunCommandContext(ctx, "efi-updatevar", "-a", "-f", efiUpdateFile, varName)
+	args := []string{"-f", efiUpdateFile, varName}
+	if appendValue {
+		args = append([]string{"-a"}, args...)
+	}
+
+	_, err = subprocess.RunCommandContext(ctx, "efi-updatevar", args...)
 	if err != nil {
 		if strings.Contains(err.Error(), "wrong filesystem permissions") {
 			// Internally, if an EFI update doesn't apply (such as when signed by an untrusted certificate),
@@ -272,15 +363,20 @@ func appendEFIVarUpdate(ctx context.Context, efiUpdateFile string, varName strin
 	}
 
 	// Update the LUKS-encrypted volumes to use the new PCR7 value.
-	newPCR7String := hex.EncodeToString(newPCR7)
-
 	luksVolumes, err := util.GetLUKSVolumePartitions()
 	if err != nil {
 		return err
 	}
 
 	for _, volume := range luksVolumes {
-		_, err = subprocess.RunCommandContext(ctx, "systemd-cryptenroll", "--unlock-tpm2-device=auto", "--tpm2-device=auto", "--wipe-slot=tpm2", "--tpm2-pcrlock=", "--tpm2-pcrs=7:sha256="+newPCR7String, volume)
+		bindingArgs, err := getTPMBindingArgs(ctx, volume, newPCR7)
+		if err != nil {
+			return err
+		}
+
+		args := append([]string{"--unlock-tpm2-device=auto", "--tpm2-device=auto", "--wipe-slot=tpm2", "--tpm2-pcrlock="}, bindingArgs...)
+
+		_, err = subprocess.RunCommandContext(ctx, "systemd-cryptenroll", append(args, volume)...)
 		if err != nil {
 			return err
 		}
@@ -305,19 +401,7 @@ func checkDbxUpdateWouldBrickUKI(dbxFilePath string) error {
 		return err
 	}
 
-	// .auth files have a EFI_VARIABLE_AUTHENTICATION_2 header before the EFI signature list.
-	// The first 16 bytes are EFI_TIME, followed by WIN_CERTIFICATE. The first four bytes of
-	// WIN_CERTIFICATE are the total size of the header including the PKCS7 certificate data.
-	// So the proper offset to the start of the EFI signature list is 16 + WIN_CERTIFICATE->dwLength.
-
-	headerSize := binary.LittleEndian.Uint32(buf[16:20])
-	offset := 16 + headerSize
-
-	efiVar := tcg.UEFIVariableData{
-		VariableData: buf[offset:],
-	}
-
-	certs, _, err := efiVar.SignatureData()
+	certs, err := getCertificatesFromAuth(buf)
 	if err != nil {
 		return err
 	} else if len(certs) != 1 {
@@ -356,6 +440,35 @@ func checkDbxUpdateWouldBrickUKI(dbxFilePath string) error {
 	return nil
 }
 
+// getCertificatesFromAuth returns the certificates contained in a pre-signed (.auth) EFI variable update.
+func getCertificatesFromAuth(buf []byte) ([]x509.Certificate, error) {
+	// .auth files have a EFI_VARIABLE_AUTHENTICATION_2 header before the EFI signature list.
+	// The first 16 bytes are EFI_TIME, followed by WIN_CERTIFICATE. The first four bytes of
+	// WIN_CERTIFICATE are the total size of the header including the PKCS7 certificate data.
+	// So the proper offset to the start of the EFI signature list is 16 + WIN_CERTIFICATE->dwLength.
+	if len(buf) < 20 {
+		return nil, errors.New("update is too short to be a signed EFI variable update")
+	}
+
+	headerSize := binary.LittleEndian.Uint32(buf[16:20])
+	offset := 16 + uint64(headerSize)
+
+	if offset > uint64(len(buf)) {
+		return nil, errors.New("invalid signed EFI variable update header")
+	}
+
+	efiVar := tcg.UEFIVariableData{
+		VariableData: buf[offset:],
+	}
+
+	certs, _, err := efiVar.SignatureData()
+	if err != nil {
+		return nil, err
+	}
+
+	return certs, nil
+}
+
 // readEFIVariable returns the current value, if any, of the specified EFI variable.
 func readEFIVariable(variableName string) ([]byte, error) {
 	// Determine which file to open.
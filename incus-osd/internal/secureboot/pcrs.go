@@ -79,10 +79,15 @@ func ForceUpdatePCRBindings(ctx context.Context, osName string, osVersion string
 	}
 
 	// Finally, we're ready to update the TPM bindings for each LUKS volume.
-	pcr7String := hex.EncodeToString(pcr7)
-
 	for _, volume := range luksVolumes {
-		_, _, err := subprocess.RunCommandSplit(ctx, append(os.Environ(), "PASSWORD="+luksPassword), nil, "systemd-cryptenroll", "--tpm2-device=auto", "--wipe-slot=tpm2", "--tpm2-pcrlock=", "--tpm2-pcrs=7:sha256="+pcr7String, volume)
+		bindingArgs, err := getTPMBindingArgs(ctx, volume, pcr7)
+		if err != nil {
+			return err
+		}
+
+		args := append([]string{"--tpm2-device=auto", "--wipe-slot=tpm2", "--tpm2-pcrlock="}, bindingArgs...)
+
+		_, _, err = subprocess.RunCommandSplit(ctx, append(os.Environ(), "PASSWORD="+luksPassword), nil, "systemd-cryptenroll", append(args, volume)...)
 		if err != nil {
 			return err
 		}
@@ -99,22 +104,29 @@ func ForceUpdatePCRBindings(ctx context.Context, osName string, osVersion string
 
 // readPCR7 returns the current PCR7 value from the TPM.
 func readPCR7() ([]byte, error) {
-	pcr7File, err := os.Open("/sys/class/tpm/tpm0/pcr-sha256/7")
+	return readPCR(7)
+}
+
+// readPCR returns the current SHA256 value of the given PCR from the TPM.
+func readPCR(index int) ([]byte, error) {
+	pcrFilename := fmt.Sprintf("/sys/class/tpm/tpm0/pcr-sha256/%d", index)
+
+	pcrFile, err := os.Open(pcrFilename) //nolint:gosec
 	if err != nil {
 		return nil, err
 	}
-	defer pcr7File.Close()
+	defer pcrFile.Close()
 
-	actualPCR7Buf := make([]byte, 64)
+	actualPCRBuf := make([]byte, 64)
 
-	numBytes, err := io.ReadFull(pcr7File, actualPCR7Buf)
+	numBytes, err := io.ReadFull(pcrFile, actualPCRBuf)
 	if err != nil {
 		return nil, err
 	} else if numBytes != 64 {
-		return nil, fmt.Errorf("only read %d bytes from /sys/class/tpm/tpm0/pcr-sha256/7", numBytes)
+		return nil, fmt.Errorf("only read %d bytes from %s", numBytes, pcrFilename)
 	}
 
-	return hex.DecodeString(string(actualPCR7Buf))
+	return hex.DecodeString(string(actualPCRBuf))
 }
 
 // computeNewPCR7Value will compute the future PCR7 value after the KEK, db, and/or dbx EFI variables are updated.
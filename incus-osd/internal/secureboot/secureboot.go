@@ -85,15 +85,20 @@ func HandleSecureBootKeyChange(ctx context.Context, luksPassword string, ukiFile
 		return err
 	}
 
-	newPCR7String := hex.EncodeToString(newPCR7)
-
 	luksVolumes, err := util.GetLUKSVolumePartitions()
 	if err != nil {
 		return err
 	}
 
 	for _, volume := range luksVolumes {
-		_, _, err := subprocess.RunCommandSplit(ctx, append(os.Environ(), "PASSWORD="+luksPassword), nil, "systemd-cryptenroll", "--tpm2-device=auto", "--wipe-slot=tpm2", "--tpm2-pcrlock=", "--tpm2-pcrs=7:sha256="+newPCR7String, volume)
+		bindingArgs, err := getTPMBindingArgs(ctx, volume, newPCR7)
+		if err != nil {
+			return err
+		}
+
+		args := append([]string{"--tpm2-device=auto", "--wipe-slot=tpm2", "--tpm2-pcrlock="}, bindingArgs...)
+
+		_, _, err = subprocess.RunCommandSplit(ctx, append(os.Environ(), "PASSWORD="+luksPassword), nil, "systemd-cryptenroll", append(args, volume)...)
 		if err != nil {
 			return err
 		}
@@ -65,10 +65,21 @@ func GetSWTPMInUse() bool {
 
 // readTPMEventLog reads the raw TPM measurements and returns a parsed array of Events with SHA256 hashes.
 func readTPMEventLog() ([]tcg.Event, error) {
-	var buf []byte
+	buf, err := readRawTPMEventLog()
+	if err != nil {
+		return nil, err
+	}
 
-	var err error
+	log, err := tcg.ParseEventLog(buf, tcg.ParseOpts{})
+	if err != nil {
+		return nil, err
+	}
+
+	return log.Events(register.HashSHA256), nil
+}
 
+// readRawTPMEventLog returns the binary TPM event log, falling back to a synthesized one for swtpm.
+func readRawTPMEventLog() ([]byte, error) {
 	rawLog, err := os.Open("/sys/kernel/security/tpm0/binary_bios_measurements")
 	if err != nil {
 		if !errors.Is(err, os.ErrNotExist) {
@@ -76,25 +87,11 @@ func readTPMEventLog() ([]tcg.Event, error) {
 		}
 
 		// Fallback to a synthesized TPM event log for swtpm.
-		buf, err = SynthesizeTPMEventLog()
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		defer rawLog.Close()
-
-		buf, err = io.ReadAll(rawLog)
-		if err != nil {
-			return nil, err
-		}
+		return SynthesizeTPMEventLog()
 	}
+	defer rawLog.Close()
 
-	log, err := tcg.ParseEventLog(buf, tcg.ParseOpts{})
-	if err != nil {
-		return nil, err
-	}
-
-	return log.Events(register.HashSHA256), nil
+	return io.ReadAll(rawLog)
 }
 
 // validateUntrustedTPMEventLog takes an untrusted TPM event log and verifies if its values
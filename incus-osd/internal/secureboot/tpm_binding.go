@@ -0,0 +1,201 @@
+package secureboot
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/util"
+)
+
+// DefaultTPMBinding is the TPM policy used when none is configured.
+var DefaultTPMBinding = api.SystemSecurityTPMBinding{
+	PCRs:         []int{7},
+	SignedPolicy: true,
+}
+
+// updatedPCRs are the PCRs measuring the boot loader, initrd and UKI sections, all of which are
+// replaced with every IncusOS update. Binding to their current value would make the TPM refuse to
+// unlock the volumes following the next update.
+var updatedPCRs = []int{4, 9, 11}
+
+// ValidateTPMBinding checks that a TPM binding policy is usable.
+func ValidateTPMBinding(binding *api.SystemSecurityTPMBinding) error {
+	if binding == nil {
+		return nil
+	}
+
+	for _, pcr := range binding.PCRs {
+		if pcr < 0 || pcr > 23 {
+			return fmt.Errorf("invalid PCR %d", pcr)
+		}
+
+		if slices.Contains(updatedPCRs, pcr) {
+			return fmt.Errorf("PCR %d changes with every IncusOS update and can't be bound to its current value", pcr)
+		}
+	}
+
+	if len(binding.PCRs) == 0 && !binding.SignedPolicy {
+		return errors.New("at least one PCR or the signed policy must be used")
+	}
+
+	return nil
+}
+
+// ApplyTPMBinding re-enrolls the TPM for each LUKS volume using the provided policy, binding each
+// PCR to its current value. This is used both to change the policy and to re-seal the volumes after
+// a deliberate firmware or configuration change altered the measurements.
+func ApplyTPMBinding(ctx context.Context, osName string, osVersion string, luksPassword string, binding *api.SystemSecurityTPMBinding) error {
+	if binding == nil {
+		binding = &DefaultTPMBinding
+	}
+
+	err := ValidateTPMBinding(binding)
+	if err != nil {
+		return err
+	}
+
+	// Make sure Secure Boot is enabled so we can have some confidence in the current running system.
+	sbEnabled, err := Enabled()
+	if err != nil {
+		return err
+	} else if !sbEnabled {
+		return errors.New("refusing to update TPM encryption bindings because Secure Boot is disabled")
+	}
+
+	pcrValues := map[int][]byte{}
+
+	for _, pcr := range binding.PCRs {
+		pcrValues[pcr], err = readPCR(pcr)
+		if err != nil {
+			return err
+		}
+	}
+
+	if binding.SignedPolicy {
+		// Extract the signing certificate from the UKI we're running from.
+		ukiCert, err := getPublicKeyFromUKI(fmt.Sprintf("/boot/EFI/Linux/%s_%s.efi", osName, osVersion))
+		if err != nil {
+			return err
+		}
+
+		// Write the UKI's cert to where systemd will pick it up.
+		err = os.WriteFile("/run/systemd/tpm2-pcr-public-key.pem", ukiCert, 0o600)
+		if err != nil {
+			return err
+		}
+	}
+
+	luksVolumes, err := util.GetLUKSVolumePartitions()
+	if err != nil {
+		return err
+	}
+
+	for _, volume := range luksVolumes {
+		args := append([]string{"--tpm2-device=auto", "--wipe-slot=tpm2", "--tpm2-pcrlock="}, generateTPMBindingArgs(pcrValues, binding.SignedPolicy)...)
+
+		_, _, err := subprocess.RunCommandSplit(ctx, append(os.Environ(), "PASSWORD="+luksPassword), nil, "systemd-cryptenroll", append(args, volume)...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getTPMBindingArgs returns the systemd-cryptenroll arguments binding a volume to the provided PCR 7
+// value, while preserving the rest of the volume's current TPM policy. Other PCRs are bound to their
+// current value.
+func getTPMBindingArgs(ctx context.Context, volume string, pcr7 []byte) ([]string, error) {
+	pcrs, signedPolicy, err := getCurrentTPMBinding(ctx, volume)
+	if err != nil {
+		return nil, err
+	}
+
+	pcrValues := map[int][]byte{}
+
+	for _, pcr := range pcrs {
+		if pcr == 7 {
+			pcrValues[pcr] = pcr7
+
+			continue
+		}
+
+		pcrValues[pcr], err = readPCR(pcr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return generateTPMBindingArgs(pcrValues, signedPolicy), nil
+}
+
+// getCurrentTPMBinding returns the PCRs bound to their value and whether the signed policy is in use,
+// based on the TPM token of the provided LUKS volume.
+func getCurrentTPMBinding(ctx context.Context, volume string) ([]int, bool, error) {
+	output, err := subprocess.RunCommandContext(ctx, "cryptsetup", "luksDump", "--dump-json-metadata", volume)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return parseTPMBinding(output)
+}
+
+// parseTPMBinding extracts the TPM policy from the LUKS2 JSON metadata of a volume, falling back
+// to the default policy if no TPM token is present.
+func parseTPMBinding(metadata string) ([]int, bool, error) {
+	luksMeta := struct {
+		Tokens map[string]struct {
+			Type       string `json:"type"`
+			PCRs       []int  `json:"tpm2-pcrs"`
+			PubkeyPCRs []int  `json:"tpm2_pubkey_pcrs"`
+		} `json:"tokens"`
+	}{}
+
+	err := json.Unmarshal([]byte(metadata), &luksMeta)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, token := range luksMeta.Tokens {
+		if token.Type != "systemd-tpm2" {
+			continue
+		}
+
+		return token.PCRs, len(token.PubkeyPCRs) > 0, nil
+	}
+
+	return DefaultTPMBinding.PCRs, DefaultTPMBinding.SignedPolicy, nil
+}
+
+// generateTPMBindingArgs returns the systemd-cryptenroll arguments for the provided PCR values and signed policy.
+func generateTPMBindingArgs(pcrValues map[int][]byte, signedPolicy bool) []string {
+	pcrs := make([]int, 0, len(pcrValues))
+	for pcr := range pcrValues {
+		pcrs = append(pcrs, pcr)
+	}
+
+	slices.Sort(pcrs)
+
+	pcrArgs := make([]string, 0, len(pcrs))
+	for _, pcr := range pcrs {
+		pcrArgs = append(pcrArgs, strconv.Itoa(pcr)+":sha256="+hex.EncodeToString(pcrValues[pcr]))
+	}
+
+	ret := []string{"--tpm2-pcrs=" + strings.Join(pcrArgs, "+")}
+
+	if !signedPolicy {
+		ret = append(ret, "--tpm2-public-key-pcrs=")
+	}
+
+	return ret
+}
@@ -0,0 +1,20 @@
+package seed
+
+import (
+	"context"
+
+	apiseed "github.com/lxc/incus-os/incus-osd/api/seed"
+)
+
+// GetLogForward extracts the log forwarding configuration from the seed data.
+func GetLogForward(_ context.Context) (*apiseed.LogForward, error) {
+	// Get the log forwarding configuration.
+	var config apiseed.LogForward
+
+	err := parseFileContents(getSeedPath(), "logforward", &config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
@@ -51,9 +51,9 @@ func GetNetwork(_ context.Context) (*api.SystemNetworkConfig, error) {
 	return &config.SystemNetworkConfig, nil
 }
 
-// NetworkConfigHasEmptyDevices checks if any device (interface, bond, or vlan) is defined in the given config.
+// NetworkConfigHasEmptyDevices checks if any device (interface, bond, bridge, vlan, or wifi) is defined in the given config.
 func NetworkConfigHasEmptyDevices(networkCfg api.SystemNetworkConfig) bool {
-	return len(networkCfg.Interfaces) == 0 && len(networkCfg.Bonds) == 0 && len(networkCfg.VLANs) == 0
+	return len(networkCfg.Interfaces) == 0 && len(networkCfg.Bonds) == 0 && len(networkCfg.Bridges) == 0 && len(networkCfg.VLANs) == 0 && len(networkCfg.Wifi) == 0
 }
 
 // getDefaultNetworkConfig returns a minimal network configuration, with every interface
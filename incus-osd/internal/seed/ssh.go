@@ -0,0 +1,20 @@
+package seed
+
+import (
+	"context"
+
+	apiseed "github.com/lxc/incus-os/incus-osd/api/seed"
+)
+
+// GetSSH extracts the SSH configuration from the seed data.
+func GetSSH(_ context.Context) (*apiseed.SSH, error) {
+	// Get the SSH configuration.
+	var config apiseed.SSH
+
+	err := parseFileContents(getSeedPath(), "ssh", &config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
@@ -0,0 +1,20 @@
+package seed
+
+import (
+	"context"
+
+	apiseed "github.com/lxc/incus-os/incus-osd/api/seed"
+)
+
+// GetTailscale extracts the Tailscale configuration from the seed data.
+func GetTailscale(_ context.Context) (*apiseed.Tailscale, error) {
+	// Get the Tailscale configuration.
+	var config apiseed.Tailscale
+
+	err := parseFileContents(getSeedPath(), "tailscale", &config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
@@ -11,7 +11,7 @@ import (
 // Supported returns the list of all valid services for this system.
 // The list is sorted in recommended startup order to handle service dependencies.
 func Supported(s *state.State) []string {
-	services := []string{"ceph", "iscsi", "kopia", "linstor", "nvme", "multipath", "lvm", "ovn", "tailscale", "usbip"}
+	services := []string{"bgp", "ceph", "drbd", "fc", "haproxy", "ipmi", "iscsi", "kopia", "linstor", "lldp", "logforward", "metrics", "netdata", "nvme", "multipath", "lvm", "nfs", "ovn", "redfish", "smartd", "ssh", "tailscale", "usbip", "vrrp"}
 	supported := make([]string, 0, len(services))
 
 	for _, service := range services {
@@ -49,26 +49,54 @@ func loadByName(s *state.State, name string) (Service, error) {
 	var srv Service
 
 	switch name {
+	case "bgp":
+		srv = &BGP{state: s}
 	case "ceph":
 		srv = &Ceph{state: s}
+	case "drbd":
+		srv = &DRBD{state: s}
+	case "fc":
+		srv = &FC{state: s}
+	case "haproxy":
+		srv = &HAProxy{state: s}
+	case "ipmi":
+		srv = &IPMI{state: s}
 	case "iscsi":
 		srv = &ISCSI{state: s}
 	case "kopia":
 		srv = &Kopia{state: s}
 	case "linstor":
 		srv = &Linstor{state: s}
+	case "lldp":
+		srv = &LLDP{state: s}
+	case "logforward":
+		srv = &LogForward{state: s}
 	case "lvm":
 		srv = &LVM{state: s}
+	case "metrics":
+		srv = &Metrics{state: s}
 	case "multipath":
 		srv = &Multipath{state: s}
+	case "netdata":
+		srv = &Netdata{state: s}
+	case "nfs":
+		srv = &NFS{state: s}
 	case "nvme":
 		srv = &NVME{state: s}
 	case "ovn":
 		srv = &OVN{state: s}
+	case "redfish":
+		srv = &Redfish{state: s}
+	case "smartd":
+		srv = &SMARTD{state: s}
+	case "ssh":
+		srv = &SSH{state: s}
 	case "tailscale":
 		srv = &Tailscale{state: s}
 	case "usbip":
 		srv = &USBIP{state: s}
+	case "vrrp":
+		srv = &VRRP{state: s}
 	default:
 		return nil, errors.New("unknown service")
 	}
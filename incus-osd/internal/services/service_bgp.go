@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/secrets"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+	"github.com/lxc/incus-os/incus-osd/internal/systemd"
+)
+
+// bgpDaemons is the FRR daemons configuration, only running the BGP daemon along with zebra.
+var bgpDaemons = `# Generated by IncusOS
+bgpd=yes
+vtysh_enable=yes
+zebra_options="-A 127.0.0.1"
+bgpd_options="-A 127.0.0.1"
+`
+
+// BGP represents the system BGP service.
+type BGP struct {
+	common
+
+	state *state.State
+}
+
+// Get returns the current service state.
+func (n *BGP) Get(ctx context.Context) (any, error) {
+	// Initialize the neighbor and prefix lists if missing.
+	if n.state.Services.BGP.Config.Neighbors == nil {
+		n.state.Services.BGP.Config.Neighbors = []api.ServiceBGPNeighbor{}
+	}
+
+	if n.state.Services.BGP.Config.Prefixes == nil {
+		n.state.Services.BGP.Config.Prefixes = []string{}
+	}
+
+	// Get runtime details if enabled.
+	if !n.state.Services.BGP.Config.Enabled {
+		return n.state.Services.BGP, nil
+	}
+
+	sessions, err := n.getSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	n.state.Services.BGP.State.Sessions = sessions
+
+	return n.state.Services.BGP, nil
+}
+
+// Update updates the service configuration.
+func (n *BGP) Update(ctx context.Context, req any) error {
+	newState, ok := req.(*api.ServiceBGP)
+	if !ok {
+		return fmt.Errorf("request type \"%T\" isn't expected ServiceBGP", req)
+	}
+
+	if newState.Config.Enabled {
+		err := n.validate(newState.Config)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Save the state on return.
+	defer n.state.Save()
+
+	// Disable the service if requested.
+	if n.state.Services.BGP.Config.Enabled && !newState.Config.Enabled {
+		err := n.Stop(ctx)
+		if err != nil {
+			return err
+		}
+
+		n.state.Services.BGP.Config = newState.Config
+	} else {
+		// Update the configuration.
+		n.state.Services.BGP.Config = newState.Config
+
+		// Enable or reconfigure the service if requested.
+		err := n.Start(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the service.
+func (n *BGP) Stop(ctx context.Context) error {
+	if !n.state.Services.BGP.Config.Enabled {
+		return nil
+	}
+
+	// Stop FRR, withdrawing the advertised prefixes.
+	err := systemd.StopUnit(ctx, "frr.service")
+	if err != nil {
+		return err
+	}
+
+	// Remove the configuration.
+	err = os.Remove("/etc/frr/frr.conf")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// Start starts the service.
+func (n *BGP) Start(ctx context.Context) error {
+	if !n.state.Services.BGP.Config.Enabled {
+		return nil
+	}
+
+	// Create the FRR config directory if missing.
+	err := os.Mkdir("/etc/frr", 0o755)
+	if err != nil && !errors.Is(err, os.ErrExist) {
+		return err
+	}
+
+	err = os.WriteFile("/etc/frr/daemons", []byte(bgpDaemons), 0o640)
+	if err != nil {
+		return err
+	}
+
+	conf, err := n.generateConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile("/etc/frr/frr.conf", []byte(conf), 0o640)
+	if err != nil {
+		return err
+	}
+
+	// (Re)start FRR to pick up the configuration.
+	err = systemd.RestartUnit(ctx, "frr.service")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ShouldStart returns true if the service should be started on boot.
+func (n *BGP) ShouldStart() bool {
+	return n.state.Services.BGP.Config.Enabled
+}
+
+// Struct returns the API struct for the BGP service.
+func (*BGP) Struct() any {
+	return &api.ServiceBGP{}
+}
+
+// validate checks that the BGP configuration is usable.
+func (n *BGP) validate(cfg api.ServiceBGPConfig) error {
+	if cfg.ASN == 0 {
+		return errors.New("a local ASN is required")
+	}
+
+	if cfg.RouterID != "" {
+		ip := net.ParseIP(cfg.RouterID)
+		if ip == nil || ip.To4() == nil {
+			return fmt.Errorf("invalid router ID %q, must be an IPv4 address", cfg.RouterID)
+		}
+	}
+
+	addresses := []string{}
+
+	for _, neighbor := range cfg.Neighbors {
+		if net.ParseIP(neighbor.Address) == nil || slices.Contains(addresses, neighbor.Address) {
+			return fmt.Errorf("invalid or duplicate neighbor address %q", neighbor.Address)
+		}
+
+		addresses = append(addresses, neighbor.Address)
+
+		if neighbor.ASN == 0 {
+			return fmt.Errorf("missing ASN for neighbor %q", neighbor.Address)
+		}
+
+		if strings.Contains(neighbor.Description, "\n") {
+			return fmt.Errorf("invalid description for neighbor %q", neighbor.Address)
+		}
+
+		err := secrets.ValidateReference(n.state.System.Security.Config.Secrets, neighbor.Password)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, prefix := range cfg.Prefixes {
+		_, _, err := net.ParseCIDR(prefix)
+		if err != nil {
+			return fmt.Errorf("invalid prefix %q: %w", prefix, err)
+		}
+	}
+
+	return nil
+}
+
+// generateConfig returns the FRR configuration, peering with each neighbor and advertising the
+// prefixes to them. As the prefixes may not be routed through the system itself, such as OVN
+// external addresses, they are advertised regardless of the routing table.
+func (n *BGP) generateConfig(ctx context.Context) (string, error) {
+	cfg := n.state.Services.BGP.Config
+
+	var ret strings.Builder
+
+	_, _ = ret.WriteString("# Generated by IncusOS\n")
+	_, _ = ret.WriteString("frr defaults traditional\n")
+	_, _ = fmt.Fprintf(&ret, "hostname %s\n", n.state.Hostname())
+	_, _ = ret.WriteString("log syslog informational\n")
+	_, _ = ret.WriteString("!\n")
+	_, _ = fmt.Fprintf(&ret, "router bgp %d\n", cfg.ASN)
+
+	if cfg.RouterID != "" {
+		_, _ = fmt.Fprintf(&ret, " bgp router-id %s\n", cfg.RouterID)
+	}
+
+	_, _ = ret.WriteString(" no bgp ebgp-requires-policy\n")
+	_, _ = ret.WriteString(" no bgp network import-check\n")
+
+	for _, neighbor := range cfg.Neighbors {
+		_, _ = fmt.Fprintf(&ret, " neighbor %s remote-as %d\n", neighbor.Address, neighbor.ASN)
+
+		if neighbor.Description != "" {
+			_, _ = fmt.Fprintf(&ret, " neighbor %s description %s\n", neighbor.Address, neighbor.Description)
+		}
+
+		if neighbor.Password != "" {
+			password, err := secrets.Resolve(ctx, n.state.System.Security.Config.Secrets, neighbor.Password)
+			if err != nil {
+				return "", err
+			}
+
+			_, _ = fmt.Fprintf(&ret, " neighbor %s password %s\n", neighbor.Address, password)
+		}
+	}
+
+	isIPv4 := func(address string) bool {
+		ip, _, err := net.ParseCIDR(address)
+		if err != nil {
+			ip = net.ParseIP(address)
+		}
+
+		return ip.To4() != nil
+	}
+
+	// Advertise each prefix to the neighbors of its address family.
+	for _, family := range []string{"ipv4", "ipv6"} {
+		_, _ = ret.WriteString(" !\n")
+		_, _ = fmt.Fprintf(&ret, " address-family %s unicast\n", family)
+
+		for _, prefix := range cfg.Prefixes {
+			if isIPv4(prefix) == (family == "ipv4") {
+				_, _ = fmt.Fprintf(&ret, "  network %s\n", prefix)
+			}
+		}
+
+		for _, neighbor := range cfg.Neighbors {
+			if isIPv4(neighbor.Address) == (family == "ipv4") {
+				_, _ = fmt.Fprintf(&ret, "  neighbor %s activate\n", neighbor.Address)
+			} else {
+				_, _ = fmt.Fprintf(&ret, "  no neighbor %s activate\n", neighbor.Address)
+			}
+		}
+
+		_, _ = ret.WriteString(" exit-address-family\n")
+	}
+
+	_, _ = ret.WriteString("!\n")
+
+	return ret.String(), nil
+}
+
+// getSessions returns the state of the session with each neighbor, along with the number of
+// prefixes exchanged across all address families.
+func (n *BGP) getSessions(ctx context.Context) ([]api.ServiceBGPSession, error) {
+	type bgpSummary struct {
+		Peers map[string]struct {
+			RemoteAs   uint32 `json:"remoteAs"`
+			State      string `json:"state"`
+			PeerUptime string `json:"peerUptime"`
+			PfxRcd     int    `json:"pfxRcd"`
+			PfxSnt     int    `json:"pfxSnt"`
+		} `json:"peers"`
+	}
+
+	output, err := subprocess.RunCommandContext(ctx, "vtysh", "-c", "show bgp summary json")
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := map[string]bgpSummary{}
+
+	err = json.Unmarshal([]byte(output), &summaries)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]api.ServiceBGPSession, 0, len(n.state.Services.BGP.Config.Neighbors))
+
+	for _, neighbor := range n.state.Services.BGP.Config.Neighbors {
+		session := api.ServiceBGPSession{
+			Address: neighbor.Address,
+			ASN:     neighbor.ASN,
+			State:   "Idle",
+		}
+
+		for _, summary := range summaries {
+			peer, ok := summary.Peers[neighbor.Address]
+			if !ok {
+				continue
+			}
+
+			session.State = peer.State
+			session.Uptime = peer.PeerUptime
+			session.ReceivedPrefixes += peer.PfxRcd
+			session.SentPrefixes += peer.PfxSnt
+		}
+
+		ret = append(ret, session)
+	}
+
+	return ret, nil
+}
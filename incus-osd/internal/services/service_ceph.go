@@ -2,16 +2,25 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/lxc/incus/v6/shared/subprocess"
+
 	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/secrets"
 	"github.com/lxc/incus-os/incus-osd/internal/state"
 )
 
+var cephNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
 // Ceph represents the system Ceph service.
 type Ceph struct {
 	common
@@ -20,12 +29,21 @@ type Ceph struct {
 }
 
 // Get returns the current service state.
-func (n *Ceph) Get(_ context.Context) (any, error) {
+func (n *Ceph) Get(ctx context.Context) (any, error) {
 	// Initialize target list if missing.
 	if n.state.Services.Ceph.Config.Clusters == nil {
 		n.state.Services.Ceph.Config.Clusters = map[string]api.ServiceCephCluster{}
 	}
 
+	// Check the status of each cluster if enabled.
+	n.state.Services.Ceph.State.Clusters = map[string]api.ServiceCephClusterState{}
+
+	if n.state.Services.Ceph.Config.Enabled {
+		for clusterName, cluster := range n.state.Services.Ceph.Config.Clusters {
+			n.state.Services.Ceph.State.Clusters[clusterName] = n.getClusterState(ctx, clusterName, cluster)
+		}
+	}
+
 	return n.state.Services.Ceph, nil
 }
 
@@ -36,6 +54,33 @@ func (n *Ceph) Update(ctx context.Context, req any) error {
 		return fmt.Errorf("request type \"%T\" isn't expected ServiceCeph", req)
 	}
 
+	// Validate the clusters.
+	for clusterName, cluster := range newState.Config.Clusters {
+		if !cephNameRegex.MatchString(clusterName) {
+			return fmt.Errorf("invalid Ceph cluster name %q", clusterName)
+		}
+
+		if len(cluster.Monitors) == 0 {
+			return fmt.Errorf("no monitors provided for Ceph cluster %q", clusterName)
+		}
+
+		for keyringName, keyring := range cluster.Keyrings {
+			if !cephNameRegex.MatchString(keyringName) {
+				return fmt.Errorf("invalid Ceph user %q for cluster %q", keyringName, clusterName)
+			}
+
+			err := secrets.ValidateReference(n.state.System.Security.Config.Secrets, keyring.Key)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, ok := cluster.Keyrings[cluster.StatusUser]
+		if cluster.StatusUser != "" && !ok {
+			return fmt.Errorf("no keyring for status user %q of Ceph cluster %q", cluster.StatusUser, clusterName)
+		}
+	}
+
 	// Save the state on return.
 	defer n.state.Save()
 
@@ -73,7 +118,7 @@ func (n *Ceph) Stop(_ context.Context) error {
 }
 
 // Start starts the service.
-func (n *Ceph) Start(_ context.Context) error {
+func (n *Ceph) Start(ctx context.Context) error {
 	if !n.state.Services.Ceph.Config.Enabled {
 		return nil
 	}
@@ -124,6 +169,11 @@ mon_host = %s
 	}
 
 	writeKeyring := func(clusterName string, keyringName string, keyring api.ServiceCephKeyring) error {
+		key, err := secrets.Resolve(ctx, n.state.System.Security.Config.Secrets, keyring.Key)
+		if err != nil {
+			return err
+		}
+
 		// Generate the keyring file.
 		rw, err := os.Create(filepath.Join("/etc/ceph", clusterName+".client."+keyringName+".keyring")) //nolint:gosec
 		if err != nil {
@@ -139,7 +189,7 @@ mon_host = %s
 
 		_, err = fmt.Fprintf(rw, `[client.%s]
 key = %s
-`, keyringName, keyring.Key)
+`, keyringName, key)
 		if err != nil {
 			return err
 		}
@@ -182,3 +232,62 @@ func (n *Ceph) Supported() bool {
 
 	return ok
 }
+
+// getClusterState checks the connectivity and health of a Ceph cluster, using its status user.
+func (*Ceph) getClusterState(ctx context.Context, clusterName string, cluster api.ServiceCephCluster) api.ServiceCephClusterState {
+	ret := api.ServiceCephClusterState{
+		HealthChecks: []string{},
+		Monitors:     []string{},
+	}
+
+	user := cluster.StatusUser
+	if user == "" {
+		users := slices.Sorted(maps.Keys(cluster.Keyrings))
+		if len(users) == 0 {
+			ret.Error = "no keyring to check the cluster status with"
+
+			return ret
+		}
+
+		user = users[0]
+	}
+
+	output, err := subprocess.RunCommandContext(ctx, "ceph", "--cluster", clusterName, "--id", user, "--connect-timeout", "5", "status", "--format", "json")
+	if err != nil {
+		ret.Error = strings.TrimSpace(err.Error())
+
+		return ret
+	}
+
+	status := struct {
+		Health struct {
+			Status string `json:"status"`
+			Checks map[string]struct {
+				Summary struct {
+					Message string `json:"message"`
+				} `json:"summary"`
+			} `json:"checks"`
+		} `json:"health"`
+		QuorumNames []string `json:"quorum_names"`
+	}{}
+
+	err = json.Unmarshal([]byte(output), &status)
+	if err != nil {
+		ret.Error = err.Error()
+
+		return ret
+	}
+
+	ret.Connected = true
+	ret.Health = status.Health.Status
+
+	for _, name := range slices.Sorted(maps.Keys(status.Health.Checks)) {
+		ret.HealthChecks = append(ret.HealthChecks, name+": "+status.Health.Checks[name].Summary.Message)
+	}
+
+	if status.QuorumNames != nil {
+		ret.Monitors = status.QuorumNames
+	}
+
+	return ret
+}
@@ -0,0 +1,449 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/secrets"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+// drbdPath holds the resource definitions, included by the default DRBD configuration.
+var drbdPath = "/etc/drbd.d"
+
+var drbdNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// DRBD represents the system DRBD service.
+type DRBD struct {
+	common
+
+	state *state.State
+}
+
+// drbdStatus represents the subset of "drbdsetup status --json" used to report the resource state.
+type drbdStatus struct {
+	Name    string `json:"name"`
+	Role    string `json:"role"`
+	Devices []struct {
+		DiskState string `json:"disk-state"`
+	} `json:"devices"`
+	Connections []struct {
+		ConnectionState string `json:"connection-state"`
+		PeerRole        string `json:"peer-role"`
+		PeerDevices     []struct {
+			PeerDiskState string  `json:"peer-disk-state"`
+			PercentInSync float64 `json:"percent-in-sync"`
+		} `json:"peer_devices"`
+	} `json:"connections"`
+}
+
+// Get returns the current service state.
+func (n *DRBD) Get(ctx context.Context) (any, error) {
+	// Initialize the resource list if missing.
+	if n.state.Services.DRBD.Config.Resources == nil {
+		n.state.Services.DRBD.Config.Resources = []api.ServiceDRBDResource{}
+	}
+
+	// Retrieve the state of each resource.
+	n.state.Services.DRBD.State.Resources = []api.ServiceDRBDResourceState{}
+
+	if n.state.Services.DRBD.Config.Enabled {
+		status := []drbdStatus{}
+
+		output, err := subprocess.RunCommandContext(ctx, "drbdsetup", "status", "--json")
+		if err == nil {
+			_ = json.Unmarshal([]byte(output), &status)
+		}
+
+		for _, resource := range n.state.Services.DRBD.Config.Resources {
+			resourceState := api.ServiceDRBDResourceState{
+				Name: resource.Name,
+				Role: "Unknown",
+			}
+
+			for _, entry := range status {
+				if entry.Name != resource.Name {
+					continue
+				}
+
+				resourceState.Role = entry.Role
+
+				if len(entry.Devices) > 0 {
+					resourceState.DiskState = entry.Devices[0].DiskState
+				}
+
+				if len(entry.Connections) > 0 {
+					resourceState.ConnectionState = entry.Connections[0].ConnectionState
+					resourceState.PeerRole = entry.Connections[0].PeerRole
+
+					if len(entry.Connections[0].PeerDevices) > 0 {
+						resourceState.PeerDiskState = entry.Connections[0].PeerDevices[0].PeerDiskState
+						resourceState.SyncProgress = entry.Connections[0].PeerDevices[0].PercentInSync
+					}
+				}
+			}
+
+			n.state.Services.DRBD.State.Resources = append(n.state.Services.DRBD.State.Resources, resourceState)
+		}
+	}
+
+	return n.state.Services.DRBD, nil
+}
+
+// Update updates the service configuration.
+func (n *DRBD) Update(ctx context.Context, req any) error {
+	newState, ok := req.(*api.ServiceDRBD)
+	if !ok {
+		return fmt.Errorf("request type \"%T\" isn't expected ServiceDRBD", req)
+	}
+
+	if newState.Config.Enabled {
+		err := n.validate(newState.Config)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Save the state on return.
+	defer n.state.Save()
+
+	// Disable the service if requested.
+	if n.state.Services.DRBD.Config.Enabled && !newState.Config.Enabled {
+		err := n.Stop(ctx)
+		if err != nil {
+			return err
+		}
+
+		n.state.Services.DRBD.Config = newState.Config
+
+		return nil
+	}
+
+	// Update the configuration.
+	n.state.Services.DRBD.Config = newState.Config
+
+	// Enable or reconfigure the service if requested.
+	return n.Start(ctx)
+}
+
+// Stop stops the service.
+func (n *DRBD) Stop(ctx context.Context) error {
+	if !n.state.Services.DRBD.Config.Enabled {
+		return nil
+	}
+
+	// Bring down all the resources, failing if any of them is still in use.
+	for _, resource := range n.state.Services.DRBD.Config.Resources {
+		err := n.removeResource(ctx, resource.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Start starts the service.
+func (n *DRBD) Start(ctx context.Context) error {
+	if !n.state.Services.DRBD.Config.Enabled {
+		return nil
+	}
+
+	_, err := subprocess.RunCommandContext(ctx, "modprobe", "drbd")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(drbdPath, 0o755)
+	if err != nil {
+		return err
+	}
+
+	// Bring down the resources which were removed from the configuration.
+	entries, err := os.ReadDir(drbdPath)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".res")
+		if !ok || slices.ContainsFunc(n.state.Services.DRBD.Config.Resources, func(resource api.ServiceDRBDResource) bool { return resource.Name == name }) {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(drbdPath, entry.Name())) //nolint:gosec
+		if err != nil || !strings.HasPrefix(string(content), "# Generated by IncusOS") {
+			continue
+		}
+
+		err = n.removeResource(ctx, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Write and apply the resource definitions.
+	for _, resource := range n.state.Services.DRBD.Config.Resources {
+		conf, err := n.generateResource(ctx, resource)
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(filepath.Join(drbdPath, resource.Name+".res"), []byte(conf), 0o600)
+		if err != nil {
+			return err
+		}
+
+		_, err = subprocess.RunCommandContext(ctx, "drbdadm", "adjust", resource.Name)
+		if err != nil {
+			// Initialize the metadata of new backing disks.
+			if !strings.Contains(err.Error(), "create-md") {
+				return err
+			}
+
+			_, err = subprocess.RunCommandContext(ctx, "drbdadm", "create-md", "--force", "--max-peers=1", resource.Name)
+			if err != nil {
+				return err
+			}
+
+			_, err = subprocess.RunCommandContext(ctx, "drbdadm", "adjust", resource.Name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ShouldStart returns true if the service should be started on boot.
+func (n *DRBD) ShouldStart() bool {
+	return n.state.Services.DRBD.Config.Enabled
+}
+
+// Struct returns the API struct for the DRBD service.
+func (*DRBD) Struct() any {
+	return &api.ServiceDRBD{}
+}
+
+// Promote makes the local node primary for a resource, allowing its device to be used.
+func (n *DRBD) Promote(ctx context.Context, name string, force bool) error {
+	err := n.checkResource(name)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"primary", name}
+	if force {
+		args = append(args, "--force")
+	}
+
+	_, err = subprocess.RunCommandContext(ctx, "drbdadm", args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Demote makes the local node secondary for a resource, allowing the peer to be promoted.
+func (n *DRBD) Demote(ctx context.Context, name string) error {
+	err := n.checkResource(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = subprocess.RunCommandContext(ctx, "drbdadm", "secondary", name)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// checkResource checks that a resource is managed by the service.
+func (n *DRBD) checkResource(name string) error {
+	if !n.state.Services.DRBD.Config.Enabled {
+		return errors.New("DRBD isn't currently enabled")
+	}
+
+	if !slices.ContainsFunc(n.state.Services.DRBD.Config.Resources, func(resource api.ServiceDRBDResource) bool { return resource.Name == name }) {
+		return fmt.Errorf("unknown DRBD resource %q", name)
+	}
+
+	return nil
+}
+
+// removeResource brings down a resource and removes its definition.
+func (*DRBD) removeResource(ctx context.Context, name string) error {
+	_, err := subprocess.RunCommandContext(ctx, "drbdadm", "down", name)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filepath.Join(drbdPath, name+".res"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// validate checks that the DRBD configuration is usable.
+func (n *DRBD) validate(cfg api.ServiceDRBDConfig) error {
+	if n.state.Services.Linstor.Config.Enabled {
+		return errors.New("DRBD resources are managed by Linstor while the Linstor service is enabled")
+	}
+
+	names := []string{}
+	minors := []int{}
+	ports := []int{}
+
+	for _, resource := range cfg.Resources {
+		if !drbdNameRegex.MatchString(resource.Name) || slices.Contains(names, resource.Name) {
+			return fmt.Errorf("invalid or duplicate DRBD resource name %q", resource.Name)
+		}
+
+		names = append(names, resource.Name)
+
+		if resource.Minor < 0 || resource.Minor > 1048575 || slices.Contains(minors, resource.Minor) {
+			return fmt.Errorf("invalid or duplicate minor %d for DRBD resource %q", resource.Minor, resource.Name)
+		}
+
+		minors = append(minors, resource.Minor)
+
+		port := n.getPort(resource)
+		if port < 1 || port > 65535 || slices.Contains(ports, port) {
+			return fmt.Errorf("invalid or duplicate port %d for DRBD resource %q", port, resource.Name)
+		}
+
+		ports = append(ports, port)
+
+		for _, disk := range []string{resource.Disk, resource.Peer.Disk} {
+			if disk != "" && (!strings.HasPrefix(disk, "/dev/") || strings.ContainsAny(disk, " \n;\"")) {
+				return fmt.Errorf("invalid disk %q for DRBD resource %q", disk, resource.Name)
+			}
+		}
+
+		if resource.Disk == "" {
+			return fmt.Errorf("a disk is required for DRBD resource %q", resource.Name)
+		}
+
+		if !slices.Contains([]string{"", "A", "B", "C"}, resource.Protocol) {
+			return fmt.Errorf("invalid protocol %q for DRBD resource %q", resource.Protocol, resource.Name)
+		}
+
+		if resource.Peer.Name == "" || strings.ContainsAny(resource.Peer.Name, " \n;\"") || resource.Peer.Name == n.state.Hostname() {
+			return fmt.Errorf("invalid peer name %q for DRBD resource %q", resource.Peer.Name, resource.Name)
+		}
+
+		address := net.ParseIP(resource.Address)
+		if address == nil {
+			return fmt.Errorf("invalid address %q for DRBD resource %q", resource.Address, resource.Name)
+		}
+
+		peerAddress := net.ParseIP(resource.Peer.Address)
+		if peerAddress == nil || (address.To4() == nil) != (peerAddress.To4() == nil) {
+			return fmt.Errorf("invalid peer address %q for DRBD resource %q", resource.Peer.Address, resource.Name)
+		}
+
+		if resource.Secret != "" {
+			if !secrets.IsReference(resource.Secret) && (len(resource.Secret) > 64 || strings.ContainsAny(resource.Secret, "\n\"")) {
+				return fmt.Errorf("invalid secret for DRBD resource %q, must be up to 64 characters", resource.Name)
+			}
+
+			err := secrets.ValidateReference(n.state.System.Security.Config.Secrets, resource.Secret)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// getPort returns the replication port of a resource.
+func (*DRBD) getPort(resource api.ServiceDRBDResource) int {
+	if resource.Port != 0 {
+		return resource.Port
+	}
+
+	return 7789 + resource.Minor
+}
+
+// generateResource returns the definition of a resource. The node IDs are derived from the sorted
+// hostnames, so that both nodes generate the same definition.
+func (n *DRBD) generateResource(ctx context.Context, resource api.ServiceDRBDResource) (string, error) {
+	var ret strings.Builder
+
+	protocol := resource.Protocol
+	if protocol == "" {
+		protocol = "C"
+	}
+
+	_, _ = ret.WriteString("# Generated by IncusOS\n")
+	_, _ = fmt.Fprintf(&ret, "resource \"%s\" {\n", resource.Name)
+	_, _ = ret.WriteString("    net {\n")
+	_, _ = fmt.Fprintf(&ret, "        protocol %s;\n", protocol)
+
+	if resource.Secret != "" {
+		secret, err := secrets.Resolve(ctx, n.state.System.Security.Config.Secrets, resource.Secret)
+		if err != nil {
+			return "", err
+		}
+
+		_, _ = ret.WriteString("        cram-hmac-alg sha256;\n")
+		_, _ = fmt.Fprintf(&ret, "        shared-secret \"%s\";\n", secret)
+	}
+
+	_, _ = ret.WriteString("    }\n")
+
+	peerDisk := resource.Peer.Disk
+	if peerDisk == "" {
+		peerDisk = resource.Disk
+	}
+
+	type drbdNode struct {
+		name    string
+		disk    string
+		address string
+	}
+
+	nodes := []drbdNode{
+		{name: n.state.Hostname(), disk: resource.Disk, address: resource.Address},
+		{name: resource.Peer.Name, disk: peerDisk, address: resource.Peer.Address},
+	}
+
+	slices.SortFunc(nodes, func(a drbdNode, b drbdNode) int { return strings.Compare(a.name, b.name) })
+
+	for i, node := range nodes {
+		_, _ = fmt.Fprintf(&ret, "    on \"%s\" {\n", node.name)
+		_, _ = fmt.Fprintf(&ret, "        node-id %d;\n", i)
+		_, _ = fmt.Fprintf(&ret, "        device minor %d;\n", resource.Minor)
+		_, _ = fmt.Fprintf(&ret, "        disk %s;\n", node.disk)
+		_, _ = ret.WriteString("        meta-disk internal;\n")
+		address := net.JoinHostPort(node.address, strconv.Itoa(n.getPort(resource)))
+		if net.ParseIP(node.address).To4() == nil {
+			address = "ipv6 " + address
+		}
+
+		_, _ = fmt.Fprintf(&ret, "        address %s;\n", address)
+		_, _ = ret.WriteString("    }\n")
+	}
+
+	_, _ = ret.WriteString("}\n")
+
+	return ret.String(), nil
+}
@@ -0,0 +1,412 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+// fcDisabledPath records the PCI functions unbound to disable their ports, along with their WWPN.
+var fcDisabledPath = "/run/incus-os/fc"
+
+var (
+	fcRemotePortRegex = regexp.MustCompile(`^rport-([0-9]+):([0-9]+)-[0-9]+$`)
+	fcWWNRegex        = regexp.MustCompile(`^[0-9a-f]{16}$`)
+)
+
+// FC represents the system Fibre Channel service.
+type FC struct {
+	common
+
+	state *state.State
+}
+
+// Get returns the current service state.
+func (n *FC) Get(_ context.Context) (any, error) {
+	// Initialize the disabled port list if missing.
+	if n.state.Services.FC.Config.DisabledPorts == nil {
+		n.state.Services.FC.Config.DisabledPorts = []string{}
+	}
+
+	// Retrieve the local ports if enabled.
+	n.state.Services.FC.State.Ports = []api.ServiceFCPort{}
+
+	if n.state.Services.FC.Config.Enabled {
+		n.state.Services.FC.State.Ports = getFCPorts()
+	}
+
+	return n.state.Services.FC, nil
+}
+
+// Update updates the service configuration.
+func (n *FC) Update(ctx context.Context, req any) error {
+	newState, ok := req.(*api.ServiceFC)
+	if !ok {
+		return fmt.Errorf("request type \"%T\" isn't expected ServiceFC", req)
+	}
+
+	// Validate and normalize the disabled ports.
+	disabledPorts := make([]string, 0, len(newState.Config.DisabledPorts))
+
+	for _, wwpn := range newState.Config.DisabledPorts {
+		normalized, err := normalizeFCWWN(wwpn)
+		if err != nil {
+			return err
+		}
+
+		if slices.Contains(disabledPorts, normalized) {
+			return fmt.Errorf("duplicate disabled port %q", wwpn)
+		}
+
+		disabledPorts = append(disabledPorts, normalized)
+	}
+
+	newState.Config.DisabledPorts = disabledPorts
+
+	// Save the state on return.
+	defer n.state.Save()
+
+	// Disable the service if requested.
+	if n.state.Services.FC.Config.Enabled && !newState.Config.Enabled {
+		err := n.Stop(ctx)
+		if err != nil {
+			return err
+		}
+
+		n.state.Services.FC.Config = newState.Config
+
+		return nil
+	}
+
+	// Update the configuration.
+	n.state.Services.FC.Config = newState.Config
+
+	// Enable or reconfigure the service if requested.
+	return n.Start(ctx)
+}
+
+// Reset issues a LIP on all the enabled ports and rescans them, detecting new remote ports and LUNs.
+func (n *FC) Reset(_ context.Context) error {
+	if !n.state.Services.FC.Config.Enabled {
+		return errors.New("the Fibre Channel service isn't enabled")
+	}
+
+	entries, err := os.ReadDir("/sys/class/fc_host")
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	for _, entry := range entries {
+		err := os.WriteFile(filepath.Join("/sys/class/fc_host", entry.Name(), "issue_lip"), []byte("1"), 0o200)
+		if err != nil {
+			return fmt.Errorf("failed to issue LIP on %q: %w", entry.Name(), err)
+		}
+
+		err = os.WriteFile(filepath.Join("/sys/class/scsi_host", entry.Name(), "scan"), []byte("- - -"), 0o200)
+		if err != nil {
+			return fmt.Errorf("failed to rescan %q: %w", entry.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the service.
+func (n *FC) Stop(_ context.Context) error {
+	if !n.state.Services.FC.Config.Enabled {
+		return nil
+	}
+
+	// Re-enable all the disabled ports.
+	disabled, err := getFCDisabledPorts()
+	if err != nil {
+		return err
+	}
+
+	for pciAddress := range disabled {
+		err := enableFCPort(pciAddress)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Start starts the service.
+func (n *FC) Start(_ context.Context) error {
+	if !n.state.Services.FC.Config.Enabled {
+		return nil
+	}
+
+	err := os.MkdirAll(fcDisabledPath, 0o700)
+	if err != nil {
+		return err
+	}
+
+	// Re-enable the ports which are no longer disabled.
+	disabled, err := getFCDisabledPorts()
+	if err != nil {
+		return err
+	}
+
+	for pciAddress, wwpn := range disabled {
+		if slices.Contains(n.state.Services.FC.Config.DisabledPorts, wwpn) {
+			continue
+		}
+
+		err := enableFCPort(pciAddress)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Disable the requested ports. Ports which aren't present are ignored.
+	for _, port := range getFCPorts() {
+		if port.State == "Disabled" || !slices.Contains(n.state.Services.FC.Config.DisabledPorts, port.WWPN) {
+			continue
+		}
+
+		err := disableFCPort(port)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ShouldStart returns true if the service should be started on boot.
+func (n *FC) ShouldStart() bool {
+	return n.state.Services.FC.Config.Enabled
+}
+
+// Struct returns the API struct for the Fibre Channel service.
+func (*FC) Struct() any {
+	return &api.ServiceFC{}
+}
+
+// disableFCPort disables a local port by unbinding its PCI function from the HBA driver.
+func disableFCPort(port api.ServiceFCPort) error {
+	if port.PCIAddress == "" {
+		return fmt.Errorf("unable to find the PCI function of port %q", port.WWPN)
+	}
+
+	err := os.WriteFile(filepath.Join(fcDisabledPath, port.PCIAddress), []byte(port.WWPN), 0o600)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(filepath.Join("/sys/bus/pci/devices", port.PCIAddress, "driver", "unbind"), []byte(port.PCIAddress), 0o200)
+	if err != nil {
+		return fmt.Errorf("failed to disable port %q: %w", port.WWPN, err)
+	}
+
+	return nil
+}
+
+// enableFCPort re-enables a local port by probing the drivers of its PCI function.
+func enableFCPort(pciAddress string) error {
+	err := os.WriteFile("/sys/bus/pci/drivers_probe", []byte(pciAddress), 0o200)
+	if err != nil {
+		return fmt.Errorf("failed to enable PCI function %q: %w", pciAddress, err)
+	}
+
+	err = os.Remove(filepath.Join(fcDisabledPath, pciAddress))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// getFCDisabledPorts returns the WWPN of the disabled ports, indexed by PCI address.
+func getFCDisabledPorts() (map[string]string, error) {
+	ret := map[string]string{}
+
+	entries, err := os.ReadDir(fcDisabledPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ret, nil
+		}
+
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		ret[entry.Name()] = readNVMEAttr(filepath.Join(fcDisabledPath, entry.Name()))
+	}
+
+	return ret, nil
+}
+
+// getFCPorts returns the local ports, including the disabled ones, along with their remote ports.
+func getFCPorts() []api.ServiceFCPort {
+	ports := []api.ServiceFCPort{}
+
+	entries, err := os.ReadDir("/sys/class/fc_host")
+	if err == nil {
+		for _, entry := range entries {
+			hostPath := filepath.Join("/sys/class/fc_host", entry.Name())
+
+			port := api.ServiceFCPort{
+				Name:        entry.Name(),
+				WWPN:        formatFCWWN(readNVMEAttr(filepath.Join(hostPath, "port_name"))),
+				WWNN:        formatFCWWN(readNVMEAttr(filepath.Join(hostPath, "node_name"))),
+				State:       readNVMEAttr(filepath.Join(hostPath, "port_state")),
+				Speed:       readNVMEAttr(filepath.Join(hostPath, "speed")),
+				FabricName:  formatFCWWN(readNVMEAttr(filepath.Join(hostPath, "fabric_name"))),
+				RemotePorts: getFCRemotePorts(strings.TrimPrefix(entry.Name(), "host")),
+			}
+
+			// The SCSI host sits right below its PCI function.
+			devicePath, err := filepath.EvalSymlinks(filepath.Join(hostPath, "device"))
+			if err == nil {
+				port.PCIAddress = filepath.Base(filepath.Dir(devicePath))
+
+				driverPath, err := filepath.EvalSymlinks(filepath.Join(filepath.Dir(devicePath), "driver"))
+				if err == nil {
+					port.Driver = filepath.Base(driverPath)
+				}
+			}
+
+			ports = append(ports, port)
+		}
+	}
+
+	// Add the disabled ports, which are no longer known to the kernel.
+	disabled, err := getFCDisabledPorts()
+	if err == nil {
+		for pciAddress, wwpn := range disabled {
+			ports = append(ports, api.ServiceFCPort{
+				WWPN:        wwpn,
+				State:       "Disabled",
+				PCIAddress:  pciAddress,
+				RemotePorts: []api.ServiceFCRemotePort{},
+			})
+		}
+	}
+
+	slices.SortFunc(ports, func(a api.ServiceFCPort, b api.ServiceFCPort) int { return strings.Compare(a.PCIAddress, b.PCIAddress) })
+
+	return ports
+}
+
+// getFCRemotePorts returns the remote ports detected through a local SCSI host, along with their LUNs.
+func getFCRemotePorts(host string) []api.ServiceFCRemotePort {
+	remotePorts := []api.ServiceFCRemotePort{}
+
+	entries, err := os.ReadDir("/sys/class/fc_remote_ports")
+	if err != nil {
+		return remotePorts
+	}
+
+	for _, entry := range entries {
+		// Remote ports are named "rport-<host>:<channel>-<index>".
+		match := fcRemotePortRegex.FindStringSubmatch(entry.Name())
+		if match == nil || match[1] != host {
+			continue
+		}
+
+		rportPath := filepath.Join("/sys/class/fc_remote_ports", entry.Name())
+
+		remotePort := api.ServiceFCRemotePort{
+			Name:   entry.Name(),
+			WWPN:   formatFCWWN(readNVMEAttr(filepath.Join(rportPath, "port_name"))),
+			WWNN:   formatFCWWN(readNVMEAttr(filepath.Join(rportPath, "node_name"))),
+			PortID: readNVMEAttr(filepath.Join(rportPath, "port_id")),
+			State:  readNVMEAttr(filepath.Join(rportPath, "port_state")),
+			Roles:  readNVMEAttr(filepath.Join(rportPath, "roles")),
+			LUNs:   []api.ServiceFCLUN{},
+		}
+
+		// Only targets get a SCSI target ID, "-1" otherwise.
+		target := readNVMEAttr(filepath.Join(rportPath, "scsi_target_id"))
+		if target != "" && target != "-1" {
+			remotePort.LUNs = getFCLUNs(host + ":" + match[2] + ":" + target + ":")
+		}
+
+		remotePorts = append(remotePorts, remotePort)
+	}
+
+	return remotePorts
+}
+
+// getFCLUNs returns the LUNs of a SCSI target, identified by its "<host>:<channel>:<target>:" prefix.
+func getFCLUNs(prefix string) []api.ServiceFCLUN {
+	luns := []api.ServiceFCLUN{}
+
+	entries, err := os.ReadDir("/sys/class/scsi_device")
+	if err != nil {
+		return luns
+	}
+
+	for _, entry := range entries {
+		lunStr, ok := strings.CutPrefix(entry.Name(), prefix)
+		if !ok {
+			continue
+		}
+
+		lunID, err := strconv.Atoi(lunStr)
+		if err != nil {
+			continue
+		}
+
+		devicePath := filepath.Join("/sys/class/scsi_device", entry.Name(), "device")
+
+		lun := api.ServiceFCLUN{
+			LUN:    lunID,
+			Vendor: readNVMEAttr(filepath.Join(devicePath, "vendor")),
+			Model:  readNVMEAttr(filepath.Join(devicePath, "model")),
+		}
+
+		blocks, err := os.ReadDir(filepath.Join(devicePath, "block"))
+		if err == nil && len(blocks) > 0 {
+			lun.Device = blocks[0].Name()
+		}
+
+		luns = append(luns, lun)
+	}
+
+	slices.SortFunc(luns, func(a api.ServiceFCLUN, b api.ServiceFCLUN) int { return a.LUN - b.LUN })
+
+	return luns
+}
+
+// normalizeFCWWN validates a WWN, accepting both "0x21000024ff3dbb6c" and "21:00:00:24:ff:3d:bb:6c".
+func normalizeFCWWN(wwn string) (string, error) {
+	value := strings.ReplaceAll(strings.TrimPrefix(strings.ToLower(wwn), "0x"), ":", "")
+	if !fcWWNRegex.MatchString(value) {
+		return "", fmt.Errorf("invalid WWN %q", wwn)
+	}
+
+	return formatFCWWN(value), nil
+}
+
+// formatFCWWN formats a WWN as reported by sysfs, like "0x21000024ff3dbb6c", as "21:00:00:24:ff:3d:bb:6c".
+func formatFCWWN(wwn string) string {
+	value := strings.TrimPrefix(strings.ToLower(wwn), "0x")
+	if !fcWWNRegex.MatchString(value) {
+		return ""
+	}
+
+	parts := make([]string, 0, 8)
+	for i := 0; i < len(value); i += 2 {
+		parts = append(parts, value[i:i+2])
+	}
+
+	return strings.Join(parts, ":")
+}
@@ -0,0 +1,355 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/csv"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/secrets"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+	"github.com/lxc/incus-os/incus-osd/internal/systemd"
+)
+
+// haproxyPath holds the TLS configuration and the stats socket of HAProxy.
+var haproxyPath = "/run/incus-os/haproxy"
+
+// haproxyConfig keeps the connections open for up to an hour, as the Incus API relies on
+// websockets for events and interactive operations.
+var haproxyConfig = `# Generated by IncusOS
+global
+    log /dev/log local0
+    stats socket %s mode 600 level admin
+    user haproxy
+    group haproxy
+    ssl-default-bind-options ssl-min-ver TLSv1.2
+    ssl-default-server-options ssl-min-ver TLSv1.2
+
+defaults
+    log global
+    timeout connect 5s
+    timeout client 1m
+    timeout server 1m
+    timeout tunnel 1h
+
+frontend incus
+    mode %s
+    bind %s
+    default_backend incus
+
+backend incus
+    mode %s
+    balance leastconn
+`
+
+// HAProxy represents the system HAProxy service.
+type HAProxy struct {
+	common
+
+	state *state.State
+}
+
+// Get returns the current service state.
+func (n *HAProxy) Get(ctx context.Context) (any, error) {
+	// Initialize the backend list if missing.
+	if n.state.Services.HAProxy.Config.Backends == nil {
+		n.state.Services.HAProxy.Config.Backends = []string{}
+	}
+
+	// Retrieve the health of the backends.
+	n.state.Services.HAProxy.State.Backends = []api.ServiceHAProxyBackendState{}
+
+	if n.state.Services.HAProxy.Config.Enabled {
+		status, err := n.getBackendStatus(ctx)
+		if err != nil {
+			status = map[string]string{}
+		}
+
+		for i, backend := range n.state.Services.HAProxy.Config.Backends {
+			backendStatus, ok := status[fmt.Sprintf("member%d", i+1)]
+			if !ok {
+				backendStatus = "UNKNOWN"
+			}
+
+			n.state.Services.HAProxy.State.Backends = append(n.state.Services.HAProxy.State.Backends, api.ServiceHAProxyBackendState{Address: backend, Status: backendStatus})
+		}
+	}
+
+	return n.state.Services.HAProxy, nil
+}
+
+// Update updates the service configuration.
+func (n *HAProxy) Update(ctx context.Context, req any) error {
+	newState, ok := req.(*api.ServiceHAProxy)
+	if !ok {
+		return fmt.Errorf("request type \"%T\" isn't expected ServiceHAProxy", req)
+	}
+
+	if newState.Config.Enabled {
+		err := n.validate(newState.Config)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Save the state on return.
+	defer n.state.Save()
+
+	// Disable the service if requested.
+	if n.state.Services.HAProxy.Config.Enabled && !newState.Config.Enabled {
+		err := n.Stop(ctx)
+		if err != nil {
+			return err
+		}
+
+		n.state.Services.HAProxy.Config = newState.Config
+
+		return nil
+	}
+
+	// Update the configuration.
+	n.state.Services.HAProxy.Config = newState.Config
+
+	// Enable or reconfigure the service if requested.
+	return n.Start(ctx)
+}
+
+// Stop stops the service.
+func (n *HAProxy) Stop(ctx context.Context) error {
+	if !n.state.Services.HAProxy.Config.Enabled {
+		return nil
+	}
+
+	// Stop HAProxy.
+	err := systemd.StopUnit(ctx, "haproxy.service")
+	if err != nil {
+		return err
+	}
+
+	// Remove the TLS configuration.
+	return os.RemoveAll(haproxyPath)
+}
+
+// Start starts the service.
+func (n *HAProxy) Start(ctx context.Context) error {
+	config := n.state.Services.HAProxy.Config
+
+	if !config.Enabled {
+		return nil
+	}
+
+	err := os.MkdirAll(haproxyPath, 0o700)
+	if err != nil {
+		return err
+	}
+
+	// Generate the configuration.
+	mode := config.Mode
+	if mode == "" {
+		mode = "http"
+	}
+
+	listenAddress := config.ListenAddress
+	if listenAddress == "" {
+		listenAddress = ":443"
+	}
+
+	bind := listenAddress
+
+	if mode == "http" {
+		key, err := secrets.Resolve(ctx, n.state.System.Security.Config.Secrets, config.TLSServerKey)
+		if err != nil {
+			return err
+		}
+
+		_, err = tls.X509KeyPair([]byte(config.TLSServerCertificate), []byte(key))
+		if err != nil {
+			return fmt.Errorf("invalid TLS server certificate: %w", err)
+		}
+
+		// HAProxy expects the certificate and its key in the same file.
+		err = os.WriteFile(filepath.Join(haproxyPath, "server.pem"), []byte(strings.TrimSpace(config.TLSServerCertificate)+"\n"+strings.TrimSpace(key)+"\n"), 0o600)
+		if err != nil {
+			return err
+		}
+
+		bind += " ssl crt " + filepath.Join(haproxyPath, "server.pem")
+	}
+
+	var conf strings.Builder
+
+	_, _ = fmt.Fprintf(&conf, haproxyConfig, filepath.Join(haproxyPath, "admin.sock"), mode, bind, mode)
+
+	// Check the health of the members through the API when terminating TLS, and reach them over TLS.
+	serverOptions := "check"
+
+	if mode == "http" {
+		_, _ = conf.WriteString("    option httpchk GET /1.0\n")
+		_, _ = conf.WriteString("    option forwardfor\n")
+
+		serverOptions += " ssl verify none"
+
+		if config.TLSBackendCertificate != "" {
+			err = os.WriteFile(filepath.Join(haproxyPath, "backend.crt"), []byte(config.TLSBackendCertificate), 0o600)
+			if err != nil {
+				return err
+			}
+
+			serverOptions = "check ssl verify required ca-file " + filepath.Join(haproxyPath, "backend.crt")
+		}
+	}
+
+	for i, backend := range config.Backends {
+		_, _, err := net.SplitHostPort(backend)
+		if err != nil {
+			backend = net.JoinHostPort(strings.Trim(backend, "[]"), "8443")
+		}
+
+		_, _ = fmt.Fprintf(&conf, "    server member%d %s %s\n", i+1, backend, serverOptions)
+	}
+
+	err = os.WriteFile("/etc/haproxy/haproxy.cfg", []byte(conf.String()), 0o644)
+	if err != nil {
+		return err
+	}
+
+	// (Re)start HAProxy to pick up the configuration.
+	err = systemd.RestartUnit(ctx, "haproxy.service")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ShouldStart returns true if the service should be started on boot.
+func (n *HAProxy) ShouldStart() bool {
+	return n.state.Services.HAProxy.Config.Enabled
+}
+
+// Struct returns the API struct for the HAProxy service.
+func (*HAProxy) Struct() any {
+	return &api.ServiceHAProxy{}
+}
+
+// validate checks that the HAProxy configuration is usable.
+func (n *HAProxy) validate(cfg api.ServiceHAProxyConfig) error {
+	if !slices.Contains([]string{"", "http", "tcp"}, cfg.Mode) {
+		return fmt.Errorf("invalid mode %q, must be either \"http\" or \"tcp\"", cfg.Mode)
+	}
+
+	if cfg.ListenAddress != "" {
+		_, port, err := net.SplitHostPort(cfg.ListenAddress)
+		if err != nil || port == "" {
+			return fmt.Errorf("invalid listen address %q", cfg.ListenAddress)
+		}
+	}
+
+	if len(cfg.Backends) == 0 {
+		return errors.New("at least one Incus cluster member is required")
+	}
+
+	for _, backend := range cfg.Backends {
+		if backend == "" || strings.ContainsAny(backend, " \n") {
+			return fmt.Errorf("invalid Incus cluster member %q", backend)
+		}
+	}
+
+	if cfg.Mode == "tcp" {
+		if cfg.TLSServerCertificate != "" || cfg.TLSServerKey != "" || cfg.TLSBackendCertificate != "" {
+			return errors.New("TLS certificates can't be set when passing TLS through")
+		}
+
+		return nil
+	}
+
+	if cfg.TLSServerCertificate == "" || cfg.TLSServerKey == "" {
+		return errors.New("a TLS server certificate and key are required to terminate TLS")
+	}
+
+	block, _ := pem.Decode([]byte(cfg.TLSServerCertificate))
+	if block == nil {
+		return errors.New("invalid TLS server certificate")
+	}
+
+	_, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("invalid TLS server certificate: %w", err)
+	}
+
+	err = secrets.ValidateReference(n.state.System.Security.Config.Secrets, cfg.TLSServerKey)
+	if err != nil {
+		return err
+	}
+
+	if cfg.TLSBackendCertificate != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(cfg.TLSBackendCertificate)) {
+		return errors.New("invalid TLS backend certificate")
+	}
+
+	return nil
+}
+
+// getBackendStatus returns the status of each server of the Incus backend, from the HAProxy stats socket.
+func (*HAProxy) getBackendStatus(ctx context.Context) (map[string]string, error) {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+
+	conn, err := dialer.DialContext(ctx, "unix", filepath.Join(haproxyPath, "admin.sock"))
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	_, err = io.WriteString(conn, "show stat\n")
+	if err != nil {
+		return nil, err
+	}
+
+	// The stats are returned as CSV, with the header prefixed by "# ".
+	content, err := io.ReadAll(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(content), "# "))).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, errors.New("no HAProxy statistics")
+	}
+
+	pxname := slices.Index(records[0], "pxname")
+	svname := slices.Index(records[0], "svname")
+	status := slices.Index(records[0], "status")
+
+	if pxname < 0 || svname < 0 || status < 0 {
+		return nil, errors.New("unexpected HAProxy statistics")
+	}
+
+	ret := map[string]string{}
+
+	for _, record := range records[1:] {
+		if len(record) <= max(pxname, svname, status) || record[pxname] != "incus" {
+			continue
+		}
+
+		ret[record[svname]] = record[status]
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+// IPMI represents the system IPMI service.
+type IPMI struct {
+	common
+
+	state *state.State
+}
+
+// Get returns the current service state.
+func (n *IPMI) Get(ctx context.Context) (any, error) {
+	n.state.Services.IPMI.State = api.ServiceIPMIState{
+		Sensors: []api.ServiceIPMISensor{},
+		Events:  []api.ServiceIPMIEvent{},
+	}
+
+	if !n.state.Services.IPMI.Config.Enabled {
+		return n.state.Services.IPMI, nil
+	}
+
+	// Don't let an unresponsive BMC block the API.
+	ctxTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// Retrieve the BMC firmware version.
+	output, err := subprocess.RunCommandContext(ctxTimeout, "ipmitool", "mc", "info")
+	if err != nil {
+		return nil, err
+	}
+
+	for line := range strings.SplitSeq(output, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if ok && strings.TrimSpace(key) == "Firmware Revision" {
+			n.state.Services.IPMI.State.FirmwareVersion = strings.TrimSpace(value)
+		}
+	}
+
+	// Retrieve the chassis power state, reported as "Chassis Power is on".
+	output, err = subprocess.RunCommandContext(ctxTimeout, "ipmitool", "chassis", "power", "status")
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Fields(output)
+	if len(fields) > 0 {
+		n.state.Services.IPMI.State.PowerState = fields[len(fields)-1]
+	}
+
+	// Retrieve the sensor readings, as "name,value,unit,status".
+	output, err = subprocess.RunCommandContext(ctxTimeout, "ipmitool", "-c", "sdr", "list", "full")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range parseIPMIRecords(output) {
+		if len(record) < 4 {
+			continue
+		}
+
+		n.state.Services.IPMI.State.Sensors = append(n.state.Services.IPMI.State.Sensors, api.ServiceIPMISensor{
+			Name:   record[0],
+			Value:  record[1],
+			Unit:   record[2],
+			Status: record[3],
+		})
+	}
+
+	// Retrieve the most recent System Event Log entries, as "id,date,time,sensor,description,direction".
+	output, err = subprocess.RunCommandContext(ctxTimeout, "ipmitool", "-c", "sel", "elist", "last", "100")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range parseIPMIRecords(output) {
+		if len(record) < 6 {
+			continue
+		}
+
+		n.state.Services.IPMI.State.Events = append(n.state.Services.IPMI.State.Events, api.ServiceIPMIEvent{
+			ID:          record[0],
+			Time:        record[1] + " " + record[2],
+			Sensor:      record[3],
+			Description: record[4],
+			Direction:   record[5],
+		})
+	}
+
+	return n.state.Services.IPMI, nil
+}
+
+// Update updates the service configuration.
+func (n *IPMI) Update(ctx context.Context, req any) error {
+	newState, ok := req.(*api.ServiceIPMI)
+	if !ok {
+		return fmt.Errorf("request type \"%T\" isn't expected ServiceIPMI", req)
+	}
+
+	// Save the state on return.
+	defer n.state.Save()
+
+	// Update the configuration.
+	n.state.Services.IPMI.Config = newState.Config
+
+	// Enable the service if requested.
+	return n.Start(ctx)
+}
+
+// Start starts the service.
+func (n *IPMI) Start(ctx context.Context) error {
+	if !n.state.Services.IPMI.Config.Enabled {
+		return nil
+	}
+
+	// Load the kernel modules exposing the local BMC.
+	for _, module := range []string{"ipmi_si", "ipmi_devintf"} {
+		_, err := subprocess.RunCommandContext(ctx, "modprobe", module)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ShouldStart returns true if the service should be started on boot.
+func (n *IPMI) ShouldStart() bool {
+	return n.state.Services.IPMI.Config.Enabled
+}
+
+// Struct returns the API struct for the IPMI service.
+func (*IPMI) Struct() any {
+	return &api.ServiceIPMI{}
+}
+
+// Supported returns whether the system has a BMC.
+func (*IPMI) Supported() bool {
+	// The BMC is either described by the firmware or already exposed by the kernel.
+	for _, path := range []string{"/sys/firmware/dmi/entries/38-0", "/sys/class/ipmi", "/dev/ipmi0"} {
+		_, err := os.Stat(path)
+		if err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Identify turns on the chassis identify LED for a number of seconds, or until turned off if forced.
+func (n *IPMI) Identify(ctx context.Context, duration int, force bool) error {
+	if !n.state.Services.IPMI.Config.Enabled {
+		return errors.New("the IPMI service isn't enabled")
+	}
+
+	if duration < 0 || duration > 255 {
+		return fmt.Errorf("invalid identify duration %d, must be between 0 and 255 seconds", duration)
+	}
+
+	interval := strconv.Itoa(duration)
+	if force {
+		interval = "force"
+	}
+
+	_, err := subprocess.RunCommandContext(ctx, "ipmitool", "chassis", "identify", interval)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PowerCycle flushes the file systems and has the BMC power cycle the system. This is meant as a last
+// resort when the system can no longer reboot cleanly.
+func (n *IPMI) PowerCycle(ctx context.Context) error {
+	if !n.state.Services.IPMI.Config.Enabled {
+		return errors.New("the IPMI service isn't enabled")
+	}
+
+	_, err := subprocess.RunCommandContext(ctx, "sync")
+	if err != nil {
+		return err
+	}
+
+	_, err = subprocess.RunCommandContext(ctx, "ipmitool", "chassis", "power", "cycle")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// parseIPMIRecords parses the CSV output of ipmitool, skipping malformed lines.
+func parseIPMIRecords(output string) [][]string {
+	records := [][]string{}
+
+	for line := range strings.SplitSeq(strings.TrimSpace(output), "\n") {
+		reader := csv.NewReader(strings.NewReader(line))
+		reader.FieldsPerRecord = -1
+		reader.LazyQuotes = true
+
+		record, err := reader.Read()
+		if err != nil {
+			continue
+		}
+
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+
+		records = append(records, record)
+	}
+
+	return records
+}
@@ -4,15 +4,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"net"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/lxc/incus/v6/shared/subprocess"
 
 	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/lio"
+	"github.com/lxc/incus-os/incus-osd/internal/secrets"
 	"github.com/lxc/incus-os/incus-osd/internal/state"
+	"github.com/lxc/incus-os/incus-osd/internal/storage"
 	"github.com/lxc/incus-os/incus-osd/internal/systemd"
+	"github.com/lxc/incus-os/incus-osd/internal/zfs"
 )
 
 // ISCSI represents the system ISCSI service.
@@ -23,7 +30,7 @@ type ISCSI struct {
 }
 
 // Get returns the current service state.
-func (n *ISCSI) Get(_ context.Context) (any, error) {
+func (n *ISCSI) Get(ctx context.Context) (any, error) {
 	// Initialize target list if missing.
 	if n.state.Services.ISCSI.Config.Targets == nil {
 		n.state.Services.ISCSI.Config.Targets = []api.ServiceISCSITarget{}
@@ -38,6 +45,21 @@ func (n *ISCSI) Get(_ context.Context) (any, error) {
 		}
 
 		n.state.Services.ISCSI.State.InitiatorName = strings.TrimPrefix(strings.TrimSpace(string(initiatorName)), "InitiatorName=")
+
+		// Retrieve the sessions to the exported targets.
+		n.state.Services.ISCSI.State.Exports = lio.GetSessions(n.state.Services.ISCSI.Config.Exports)
+
+		// Retrieve the targets registered with the iSNS server.
+		n.state.Services.ISCSI.State.Discovered = []api.ServiceISCSIDiscovered{}
+
+		if n.state.Services.ISCSI.Config.ISNS != nil {
+			discovered, err := n.discoverISNS(ctx)
+			if err != nil {
+				slog.WarnContext(ctx, "Failed to query the iSNS server", "err", err.Error())
+			} else {
+				n.state.Services.ISCSI.State.Discovered = discovered
+			}
+		}
 	}
 
 	return n.state.Services.ISCSI, nil
@@ -50,11 +72,46 @@ func (n *ISCSI) Update(ctx context.Context, req any) error {
 		return fmt.Errorf("request type \"%T\" isn't expected ServiceISCSI", req)
 	}
 
+	// Validate the credentials.
+	err := n.validateAuthentication(newState.Config.Authentication)
+	if err != nil {
+		return err
+	}
+
+	if newState.Config.ISNS != nil && newState.Config.ISNS.Address == "" {
+		return errors.New("an address is required for the iSNS server")
+	}
+
+	for _, target := range newState.Config.Targets {
+		if target.Address == "" && newState.Config.ISNS == nil {
+			return fmt.Errorf("target %q has no address and no iSNS server is configured", target.Target)
+		}
+
+		err := n.validateAuthentication(target.Authentication)
+		if err != nil {
+			return fmt.Errorf("invalid credentials for target %q: %w", target.Target, err)
+		}
+	}
+
+	err = lio.Validate(newState.Config.Exports)
+	if err != nil {
+		return err
+	}
+
+	for _, export := range newState.Config.Exports {
+		for _, acl := range export.ACLs {
+			err := n.validateAuthentication(acl.Authentication)
+			if err != nil {
+				return fmt.Errorf("invalid credentials for initiator %q: %w", acl.Initiator, err)
+			}
+		}
+	}
+
 	// Save the state on return.
 	defer n.state.Save()
 
 	// Disable the service.
-	err := n.Stop(ctx)
+	err = n.Stop(ctx)
 	if err != nil {
 		return err
 	}
@@ -79,22 +136,18 @@ func (n *ISCSI) Stop(ctx context.Context) error {
 
 	// Disconnect from the targets.
 	for _, target := range n.state.Services.ISCSI.Config.Targets {
-		// Determine portal address.
-		portal := target.Address
-		if strings.Contains(portal, ":") {
-			portal = "[" + portal + "]"
-		}
-
-		if target.Port > 0 {
-			portal = fmt.Sprintf("%s:%d", portal, target.Port)
-		}
-
 		// Attempt logout from the target.
-		_, _ = subprocess.RunCommandContext(ctx, "iscsiadm", "-m", "node", "-T", target.Target, "-p", portal, "--logout")
+		_, _ = subprocess.RunCommandContext(ctx, "iscsiadm", n.getNodeArgs(target, "--logout")...)
+	}
+
+	// Remove the exported targets.
+	err := lio.Clear()
+	if err != nil {
+		return err
 	}
 
 	// Stop the systemd unit.
-	err := systemd.StopUnit(ctx, "iscsid")
+	err = systemd.StopUnit(ctx, "iscsid")
 	if err != nil {
 		return err
 	}
@@ -144,17 +197,23 @@ func (n *ISCSI) Start(ctx context.Context) error {
 		}
 	}
 
-	// Generate iSCSI configuration if missing.
-	_, err = os.Stat("/etc/iscsi/iscsid.conf")
-	if err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
-			return err
-		}
+	// Generate iSCSI configuration, holding the service-wide credentials.
+	conf := strings.Builder{}
 
-		err = os.WriteFile("/etc/iscsi/iscsid.conf", []byte{}, 0o600)
+	for _, prefix := range []string{"node.session.auth", "discovery.sendtargets.auth"} {
+		settings, err := n.getAuthSettings(ctx, prefix, n.state.Services.ISCSI.Config.Authentication)
 		if err != nil {
 			return err
 		}
+
+		for _, setting := range settings {
+			_, _ = fmt.Fprintf(&conf, "%s = %s\n", setting.key, setting.value)
+		}
+	}
+
+	err = os.WriteFile("/etc/iscsi/iscsid.conf", []byte(conf.String()), 0o600)
+	if err != nil {
+		return err
 	}
 
 	// Start the service.
@@ -163,40 +222,202 @@ func (n *ISCSI) Start(ctx context.Context) error {
 		return err
 	}
 
+	// Discover the targets registered with the iSNS server.
+	if n.state.Services.ISCSI.Config.ISNS != nil {
+		for range 10 {
+			_, err = n.discoverISNS(ctx)
+			if err == nil {
+				break
+			}
+
+			time.Sleep(500 * time.Millisecond)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
 	// Connect to the targets.
 	for _, target := range n.state.Services.ISCSI.Config.Targets {
-		// Determine portal address.
-		portal := target.Address
-		if strings.Contains(portal, ":") {
-			portal = "[" + portal + "]"
+		// Use the target's own credentials if provided.
+		auth := n.state.Services.ISCSI.Config.Authentication
+		if target.Authentication != nil {
+			auth = target.Authentication
 		}
 
-		if target.Port > 0 {
-			portal = fmt.Sprintf("%s:%d", portal, target.Port)
+		nodeSettings, err := n.getAuthSettings(ctx, "node.session.auth", auth)
+		if err != nil {
+			return err
 		}
 
-		// Discover the targets.
-		for range 10 {
-			_, err = subprocess.RunCommandContext(ctx, "iscsiadm", "-m", "discovery", "-t", "sendtargets", "-p", portal)
-			if err == nil {
-				break
+		// Targets without an address were already discovered through iSNS.
+		if target.Address != "" {
+			err := n.discoverSendTargets(ctx, target, auth)
+			if err != nil {
+				return err
 			}
+		}
 
-			time.Sleep(500 * time.Millisecond)
+		// Record the login credentials.
+		for _, setting := range nodeSettings {
+			_, err = subprocess.RunCommandContext(ctx, "iscsiadm", n.getNodeArgs(target, "-o", "update", "-n", setting.key, "-v", setting.value)...)
+			if err != nil {
+				return err
+			}
 		}
 
+		// Login to the target.
+		_, err = subprocess.RunCommandContext(ctx, "iscsiadm", n.getNodeArgs(target, "--login")...)
 		if err != nil {
 			return err
 		}
+	}
 
-		// Login to the target.
-		_, err = subprocess.RunCommandContext(ctx, "iscsiadm", "-m", "node", "-T", target.Target, "-p", portal, "--login")
+	// Export the local volumes.
+	return n.startExports(ctx)
+}
+
+// discoverSendTargets discovers the targets of a portal, using the provided credentials.
+func (n *ISCSI) discoverSendTargets(ctx context.Context, target api.ServiceISCSITarget, auth *api.ServiceISCSIAuthentication) error {
+	portal := getISCSIPortal(target.Address, target.Port)
+
+	discoverySettings, err := n.getAuthSettings(ctx, "discovery.sendtargets.auth", auth)
+	if err != nil {
+		return err
+	}
+
+	// Record the discovery credentials.
+	_, _ = subprocess.RunCommandContext(ctx, "iscsiadm", "-m", "discoverydb", "-t", "sendtargets", "-p", portal, "-o", "new")
+
+	for _, setting := range discoverySettings {
+		_, err = subprocess.RunCommandContext(ctx, "iscsiadm", "-m", "discoverydb", "-t", "sendtargets", "-p", portal, "-o", "update", "-n", setting.key, "-v", setting.value)
 		if err != nil {
 			return err
 		}
 	}
 
-	return nil
+	// Discover the targets.
+	for range 10 {
+		_, err = subprocess.RunCommandContext(ctx, "iscsiadm", "-m", "discoverydb", "-t", "sendtargets", "-p", portal, "--discover")
+		if err == nil {
+			break
+		}
+
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	return err
+}
+
+// discoverISNS queries the iSNS server, recording the registered targets and returning them.
+func (n *ISCSI) discoverISNS(ctx context.Context) ([]api.ServiceISCSIDiscovered, error) {
+	port := n.state.Services.ISCSI.Config.ISNS.Port
+	if port == 0 {
+		port = 3205
+	}
+
+	portal := getISCSIPortal(n.state.Services.ISCSI.Config.ISNS.Address, port)
+
+	_, _ = subprocess.RunCommandContext(ctx, "iscsiadm", "-m", "discoverydb", "-t", "isns", "-p", portal, "-o", "new")
+
+	output, err := subprocess.RunCommandContext(ctx, "iscsiadm", "-m", "discoverydb", "-t", "isns", "-p", portal, "--discover")
+	if err != nil {
+		return nil, err
+	}
+
+	// Each line is of the form "<address>:<port>,<tpgt> <target>".
+	discovered := []api.ServiceISCSIDiscovered{}
+
+	for line := range strings.SplitSeq(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		portal, _, _ := strings.Cut(fields[0], ",")
+
+		address, portStr, err := net.SplitHostPort(portal)
+		if err != nil {
+			continue
+		}
+
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			continue
+		}
+
+		discovered = append(discovered, api.ServiceISCSIDiscovered{
+			Target:  fields[1],
+			Address: address,
+			Port:    port,
+		})
+	}
+
+	return discovered, nil
+}
+
+// getNodeArgs returns the iscsiadm arguments to act on a target, through all of its discovered
+// portals if it has no address.
+func (*ISCSI) getNodeArgs(target api.ServiceISCSITarget, args ...string) []string {
+	ret := []string{"-m", "node", "-T", target.Target}
+	if target.Address != "" {
+		ret = append(ret, "-p", getISCSIPortal(target.Address, target.Port))
+	}
+
+	return append(ret, args...)
+}
+
+// startExports configures the local targets, creating their volumes if requested.
+func (n *ISCSI) startExports(ctx context.Context) error {
+	exports := make([]api.ServiceISCSIExport, 0, len(n.state.Services.ISCSI.Config.Exports))
+
+	for _, export := range n.state.Services.ISCSI.Config.Exports {
+		for _, lun := range export.LUNs {
+			if storage.DatasetExists(ctx, lun.Pool+"/"+lun.Volume) {
+				continue
+			}
+
+			if lun.SizeInBytes <= 0 {
+				return fmt.Errorf("volume %q doesn't exist", lun.Pool+"/"+lun.Volume)
+			}
+
+			err := zfs.CreateVolume(ctx, lun.Pool, lun.Volume, lun.SizeInBytes)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Resolve the CHAP passwords, without altering the configuration.
+		acls := make([]api.ServiceISCSIExportACL, 0, len(export.ACLs))
+
+		for _, acl := range export.ACLs {
+			if acl.Authentication != nil {
+				auth := *acl.Authentication
+
+				password, err := secrets.Resolve(ctx, n.state.System.Security.Config.Secrets, auth.Password)
+				if err != nil {
+					return err
+				}
+
+				targetPassword, err := secrets.Resolve(ctx, n.state.System.Security.Config.Secrets, auth.TargetPassword)
+				if err != nil {
+					return err
+				}
+
+				auth.Password = password
+				auth.TargetPassword = targetPassword
+				acl.Authentication = &auth
+			}
+
+			acls = append(acls, acl)
+		}
+
+		export.ACLs = acls
+		exports = append(exports, export)
+	}
+
+	return lio.Apply(ctx, exports)
 }
 
 // ShouldStart returns true if the service should be started on boot.
@@ -208,3 +429,78 @@ func (n *ISCSI) ShouldStart() bool {
 func (*ISCSI) Struct() any {
 	return &api.ServiceISCSI{}
 }
+
+// getISCSIPortal returns the iscsiadm portal string for an address and optional port.
+func getISCSIPortal(address string, port int) string {
+	portal := address
+	if strings.Contains(portal, ":") {
+		portal = "[" + portal + "]"
+	}
+
+	if port > 0 {
+		portal = fmt.Sprintf("%s:%d", portal, port)
+	}
+
+	return portal
+}
+
+// iscsiSetting is an open-iscsi configuration key along with its value.
+type iscsiSetting struct {
+	key   string
+	value string
+}
+
+// validateAuthentication checks that CHAP credentials are complete.
+func (n *ISCSI) validateAuthentication(auth *api.ServiceISCSIAuthentication) error {
+	if auth == nil {
+		return nil
+	}
+
+	if auth.Username == "" || auth.Password == "" {
+		return errors.New("CHAP username and password are required")
+	}
+
+	if (auth.TargetUsername == "") != (auth.TargetPassword == "") {
+		return errors.New("mutual CHAP requires both the target username and password")
+	}
+
+	for _, value := range []string{auth.Password, auth.TargetPassword} {
+		err := secrets.ValidateReference(n.state.System.Security.Config.Secrets, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getAuthSettings returns the open-iscsi settings applying CHAP credentials, under either the
+// "node.session.auth" or "discovery.sendtargets.auth" prefix. Passwords referencing a secret are resolved.
+func (n *ISCSI) getAuthSettings(ctx context.Context, prefix string, auth *api.ServiceISCSIAuthentication) ([]iscsiSetting, error) {
+	if auth == nil {
+		return []iscsiSetting{{prefix + ".authmethod", "None"}}, nil
+	}
+
+	password, err := secrets.Resolve(ctx, n.state.System.Security.Config.Secrets, auth.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := []iscsiSetting{
+		{prefix + ".authmethod", "CHAP"},
+		{prefix + ".username", auth.Username},
+		{prefix + ".password", password},
+	}
+
+	// Mutual CHAP, where the target also authenticates itself.
+	if auth.TargetUsername != "" {
+		targetPassword, err := secrets.Resolve(ctx, n.state.System.Security.Config.Secrets, auth.TargetPassword)
+		if err != nil {
+			return nil, err
+		}
+
+		settings = append(settings, iscsiSetting{prefix + ".username_in", auth.TargetUsername}, iscsiSetting{prefix + ".password_in", targetPassword})
+	}
+
+	return settings, nil
+}
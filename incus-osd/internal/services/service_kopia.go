@@ -16,6 +16,7 @@ import (
 
 	"github.com/lxc/incus-os/incus-osd/api"
 	"github.com/lxc/incus-os/incus-osd/internal/applications"
+	"github.com/lxc/incus-os/incus-osd/internal/secrets"
 	"github.com/lxc/incus-os/incus-osd/internal/state"
 	"github.com/lxc/incus-os/incus-osd/internal/storage"
 	"github.com/lxc/incus-os/incus-osd/internal/zfs"
@@ -238,6 +239,13 @@ func (n *Kopia) validateBackendConfig(backend api.ServiceKopiaBackendConfig) err
 			return errors.New("S3 configuration incomplete")
 		}
 
+		for _, value := range []string{backend.S3.AccessKey, backend.S3.SecretKey, n.state.Services.Kopia.Config.RepositoryPassword} {
+			err := secrets.ValidateReference(n.state.System.Security.Config.Secrets, value)
+			if err != nil {
+				return err
+			}
+		}
+
 		return nil
 	default:
 		return fmt.Errorf("unsupported backend type: %s", backend.Type)
@@ -277,15 +285,20 @@ func (n *Kopia) initS3Repository(ctx context.Context, s3Config *api.ServiceKopia
 		return errors.New("repository_password is required for repository initialization")
 	}
 
+	accessKey, secretKey, password, err := n.getS3Credentials(ctx, s3Config)
+	if err != nil {
+		return err
+	}
+
 	// Build kopia repository create command.
 	args := []string{
 		"repository", "create", "s3",
 		"--bucket", s3Config.Bucket,
 		"--endpoint", s3Config.Endpoint,
 		"--disable-tls",
-		"--access-key", s3Config.AccessKey,
-		"--secret-access-key", s3Config.SecretKey,
-		"--password", config.RepositoryPassword,
+		"--access-key", accessKey,
+		"--secret-access-key", secretKey,
+		"--password", password,
 	}
 
 	if s3Config.Region != "" {
@@ -293,7 +306,7 @@ func (n *Kopia) initS3Repository(ctx context.Context, s3Config *api.ServiceKopia
 	}
 
 	// Run kopia repository create.
-	_, err := subprocess.RunCommandContext(ctx, "kopia", args...)
+	_, err = subprocess.RunCommandContext(ctx, "kopia", args...)
 	if err != nil {
 		return fmt.Errorf("failed to create repository: %w", err)
 	}
@@ -301,6 +314,23 @@ func (n *Kopia) initS3Repository(ctx context.Context, s3Config *api.ServiceKopia
 	return nil
 }
 
+// getS3Credentials returns the S3 access and secret keys, along with the repository password, fetching
+// those referencing secrets from their provider.
+func (n *Kopia) getS3Credentials(ctx context.Context, s3Config *api.ServiceKopiaBackendS3) (string, string, string, error) {
+	ret := []string{}
+
+	for _, value := range []string{s3Config.AccessKey, s3Config.SecretKey, n.state.Services.Kopia.Config.RepositoryPassword} {
+		resolved, err := secrets.Resolve(ctx, n.state.System.Security.Config.Secrets, value)
+		if err != nil {
+			return "", "", "", err
+		}
+
+		ret = append(ret, resolved)
+	}
+
+	return ret[0], ret[1], ret[2], nil
+}
+
 // connectRepository connects to an existing Kopia repository.
 func (n *Kopia) connectRepository(ctx context.Context, backend api.ServiceKopiaBackendConfig) error {
 	switch backend.Type {
@@ -319,15 +349,20 @@ func (n *Kopia) connectS3Repository(ctx context.Context, s3Config *api.ServiceKo
 		return errors.New("repository_password is required for repository connection")
 	}
 
+	accessKey, secretKey, password, err := n.getS3Credentials(ctx, s3Config)
+	if err != nil {
+		return err
+	}
+
 	// Build kopia repository connect command.
 	args := []string{
 		"repository", "connect", "s3",
 		"--bucket", s3Config.Bucket,
 		"--endpoint", s3Config.Endpoint,
 		"--disable-tls",
-		"--access-key", s3Config.AccessKey,
-		"--secret-access-key", s3Config.SecretKey,
-		"--password", config.RepositoryPassword,
+		"--access-key", accessKey,
+		"--secret-access-key", secretKey,
+		"--password", password,
 	}
 
 	if s3Config.Region != "" {
@@ -335,7 +370,7 @@ func (n *Kopia) connectS3Repository(ctx context.Context, s3Config *api.ServiceKo
 	}
 
 	// Run kopia repository connect.
-	_, err := subprocess.RunCommandContext(ctx, "kopia", args...)
+	_, err = subprocess.RunCommandContext(ctx, "kopia", args...)
 	if err != nil {
 		return fmt.Errorf("failed to connect repository: %w", err)
 	}
@@ -386,14 +421,58 @@ func (n *Kopia) refreshSnapshots(ctx context.Context) error {
 	return nil
 }
 
-// findLocalPool finds the "local" ZFS pool.
-func (n *Kopia) findLocalPool(ctx context.Context) (string, error) {
-	// Check if "local" pool exists.
-	if !storage.PoolExists(ctx, "local") {
-		return "", errors.New("local ZFS pool not found")
+// findPools returns the ZFS pools to back up, defaulting to the "local" pool.
+func (n *Kopia) findPools(ctx context.Context) ([]string, error) {
+	poolNames := n.state.Services.Kopia.Config.Pools
+	if len(poolNames) == 0 {
+		poolNames = []string{"local"}
 	}
 
-	return "local", nil
+	for _, poolName := range poolNames {
+		if !storage.PoolExists(ctx, poolName) {
+			return nil, errors.New("ZFS pool '" + poolName + "' not found")
+		}
+	}
+
+	return poolNames, nil
+}
+
+// findSnapshotPool determines which ZFS pool a Kopia snapshot was taken from.
+func (n *Kopia) findSnapshotPool(ctx context.Context, snapshotID string) (string, error) {
+	poolNames, err := n.findPools(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	source := ""
+
+	for _, snap := range n.state.Services.Kopia.State.AvailableSnapshots {
+		if snap.ID == snapshotID {
+			source = snap.Source
+
+			break
+		}
+	}
+
+	// Match the snapshot source against the mountpoint of each pool.
+	if source != "" {
+		for _, poolName := range poolNames {
+			mountpoint, err := n.getPoolMountpoint(ctx, poolName)
+			if err != nil {
+				return "", err
+			}
+
+			if strings.Contains(source, filepath.Join(mountpoint, ".zfs", "snapshot")+"/") {
+				return poolName, nil
+			}
+		}
+	}
+
+	if len(poolNames) == 1 {
+		return poolNames[0], nil
+	}
+
+	return "", errors.New("unable to determine the pool of snapshot '" + snapshotID + "'")
 }
 
 // getPoolMountpoint gets the mountpoint of a ZFS pool or dataset.
@@ -614,50 +693,86 @@ func (n *Kopia) startBackupScheduler(ctx context.Context) {
 	}()
 }
 
-// performBackup performs a backup of the local ZFS pool.
+// performBackup performs a backup of the configured ZFS pools.
 func (n *Kopia) performBackup(ctx context.Context) error {
 	// Check if repository is connected.
 	if !n.state.Services.Kopia.State.RepositoryConnected {
 		return errors.New("repository not connected")
 	}
 
-	// Find local pool.
-	poolName, err := n.findLocalPool(ctx)
+	// Find the pools to back up.
+	poolNames, err := n.findPools(ctx)
 	if err != nil {
-		n.state.Services.Kopia.State.LastStatus = "Failed to find local pool: " + err.Error()
+		n.state.Services.Kopia.State.LastStatus = "Failed to find pools: " + err.Error()
 		return err
 	}
 
 	// Mark as in progress.
 	n.state.Services.Kopia.State.InProgress = true
 	n.state.Services.Kopia.State.Progress = 0
-	n.state.Services.Kopia.State.LastStatus = "Creating ZFS snapshot"
+
+	for i, poolName := range poolNames {
+		err = n.backupPool(ctx, poolName)
+		if err != nil {
+			n.state.Services.Kopia.State.InProgress = false
+			return err
+		}
+
+		n.state.Services.Kopia.State.Progress = float64(75 * (i + 1) / len(poolNames))
+	}
+
+	n.state.Services.Kopia.State.Progress = 75
+	n.state.Services.Kopia.State.LastStatus = "Applying retention policies"
+
+	// Apply retention policies.
+	err = n.applyRetention(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to apply retention policies", "err", err)
+		// Don't fail the backup if retention fails.
+	}
+
+	// Mark as complete.
+	n.state.Services.Kopia.State.InProgress = false
+	n.state.Services.Kopia.State.Progress = 100
+	n.state.Services.Kopia.State.LastBackup = time.Now()
+	n.state.Services.Kopia.State.LastStatus = "Backup completed successfully"
+
+	// Update the window ID after successful backup.
+	currentWindowID := n.getCurrentMaintenanceWindowID()
+	if currentWindowID != "" {
+		n.state.Services.Kopia.State.LastBackupWindow = currentWindowID
+	}
+
+	return nil
+}
+
+// backupPool creates a Kopia snapshot of a single ZFS pool.
+func (n *Kopia) backupPool(ctx context.Context, poolName string) error {
+	n.state.Services.Kopia.State.LastStatus = "Creating ZFS snapshot of " + poolName
 
 	// Create ZFS snapshot.
 	snapshotName, err := n.createZFSSnapshot(ctx, poolName)
 	if err != nil {
-		n.state.Services.Kopia.State.InProgress = false
 		n.state.Services.Kopia.State.LastStatus = "Failed to create snapshot: " + err.Error()
 		return err
 	}
 
-	// Cleanup snapshot on error.
+	// Cleanup snapshot on exit.
 	defer func() {
+		err := n.destroyZFSSnapshot(ctx, snapshotName)
 		if err != nil {
-			_ = n.destroyZFSSnapshot(ctx, snapshotName)
+			slog.WarnContext(ctx, "Failed to destroy ZFS snapshot", "err", err)
 		}
 	}()
 
 	// Get snapshot path.
 	snapshotPath, err := n.getSnapshotPath(ctx, snapshotName)
 	if err != nil {
-		n.state.Services.Kopia.State.InProgress = false
 		n.state.Services.Kopia.State.LastStatus = "Failed to get snapshot path: " + err.Error()
 		return err
 	}
 
-	n.state.Services.Kopia.State.Progress = 25
-	n.state.Services.Kopia.State.LastStatus = "Creating Kopia snapshot"
+	n.state.Services.Kopia.State.LastStatus = "Creating Kopia snapshot of " + poolName
 
 	// Create Kopia snapshot.
 	description := fmt.Sprintf("Backup of %s pool at %s", poolName, time.Now().Format(time.RFC3339))
@@ -669,43 +784,10 @@ func (n *Kopia) performBackup(ctx context.Context) error {
 
 	_, err = subprocess.RunCommandContext(ctx, "kopia", args...)
 	if err != nil {
-		n.state.Services.Kopia.State.InProgress = false
 		n.state.Services.Kopia.State.LastStatus = "Failed to create Kopia snapshot: " + err.Error()
 		return err
 	}
 
-	n.state.Services.Kopia.State.Progress = 75
-	n.state.Services.Kopia.State.LastStatus = "Applying retention policies"
-
-	// Apply retention policies.
-	err = n.applyRetention(ctx)
-	if err != nil {
-		slog.WarnContext(ctx, "Failed to apply retention policies", "err", err)
-		// Don't fail the backup if retention fails.
-	}
-
-	n.state.Services.Kopia.State.Progress = 90
-	n.state.Services.Kopia.State.LastStatus = "Cleaning up ZFS snapshot"
-
-	// Destroy ZFS snapshot.
-	err = n.destroyZFSSnapshot(ctx, snapshotName)
-	if err != nil {
-		slog.WarnContext(ctx, "Failed to destroy ZFS snapshot", "err", err)
-		// Don't fail the backup if cleanup fails.
-	}
-
-	// Mark as complete.
-	n.state.Services.Kopia.State.InProgress = false
-	n.state.Services.Kopia.State.Progress = 100
-	n.state.Services.Kopia.State.LastBackup = time.Now()
-	n.state.Services.Kopia.State.LastStatus = "Backup completed successfully"
-
-	// Update the window ID after successful backup.
-	currentWindowID := n.getCurrentMaintenanceWindowID()
-	if currentWindowID != "" {
-		n.state.Services.Kopia.State.LastBackupWindow = currentWindowID
-	}
-
 	return nil
 }
 
@@ -754,7 +836,7 @@ func (n *Kopia) applyRetention(ctx context.Context) error {
 	return nil
 }
 
-// PerformRestore performs a full restore of the local ZFS pool from a Kopia snapshot.
+// PerformRestore performs a full restore of a ZFS pool from a Kopia snapshot.
 // It stops all services, creates a safety snapshot, restores data, and restarts services.
 func (n *Kopia) PerformRestore(ctx context.Context, snapshotID string) error {
 	// Check if repository is connected.
@@ -762,8 +844,8 @@ func (n *Kopia) PerformRestore(ctx context.Context, snapshotID string) error {
 		return errors.New("repository not connected")
 	}
 
-	// Find local pool.
-	poolName, err := n.findLocalPool(ctx)
+	// Find the pool the snapshot was taken from.
+	poolName, err := n.findSnapshotPool(ctx, snapshotID)
 	if err != nil {
 		return err
 	}
@@ -817,6 +899,14 @@ func (n *Kopia) PerformRestore(ctx context.Context, snapshotID string) error {
 	n.state.Services.Kopia.State.Progress = 20
 	n.state.Services.Kopia.State.LastStatus = "Creating safety snapshot"
 
+	// Checkpoint the pool, allowing it to be rewound should the restore go wrong.
+	err = zfs.CreateCheckpoint(ctx, poolName)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to create storage pool checkpoint", "pool", poolName, "err", err)
+	} else {
+		zfs.RecordCheckpoint(n.state, poolName, "Kopia restore")
+	}
+
 	// Create safety snapshot before restore.
 	safetySnapshotName := poolName + "@before-restore-" + time.Now().Format("20060102-150405")
 	_, err = n.createZFSSnapshot(ctx, safetySnapshotName)
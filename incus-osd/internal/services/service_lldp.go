@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+	"github.com/lxc/incus-os/incus-osd/internal/systemd"
+)
+
+// LLDP represents the system LLDP service.
+type LLDP struct {
+	common
+
+	state *state.State
+}
+
+// Get returns the current service state.
+func (n *LLDP) Get(ctx context.Context) (any, error) {
+	// Initialize the interface list if missing.
+	if n.state.Services.LLDP.Config.Interfaces == nil {
+		n.state.Services.LLDP.Config.Interfaces = []string{}
+	}
+
+	if n.state.Services.LLDP.Config.ManagementAddresses == nil {
+		n.state.Services.LLDP.Config.ManagementAddresses = []string{}
+	}
+
+	// Get runtime details if enabled.
+	if !n.state.Services.LLDP.Config.Enabled {
+		return n.state.Services.LLDP, nil
+	}
+
+	neighbors, err := n.getNeighbors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	n.state.Services.LLDP.State.Neighbors = neighbors
+
+	return n.state.Services.LLDP, nil
+}
+
+// Update updates the service configuration.
+func (n *LLDP) Update(ctx context.Context, req any) error {
+	newState, ok := req.(*api.ServiceLLDP)
+	if !ok {
+		return fmt.Errorf("request type \"%T\" isn't expected ServiceLLDP", req)
+	}
+
+	// Save the state on return.
+	defer n.state.Save()
+
+	// Disable the service if requested.
+	if n.state.Services.LLDP.Config.Enabled && !newState.Config.Enabled {
+		err := n.Stop(ctx)
+		if err != nil {
+			return err
+		}
+
+		n.state.Services.LLDP.Config = newState.Config
+	} else {
+		// Update the configuration.
+		n.state.Services.LLDP.Config = newState.Config
+
+		// Enable or reconfigure the service if requested.
+		err := n.Start(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the service.
+func (n *LLDP) Stop(ctx context.Context) error {
+	if !n.state.Services.LLDP.Config.Enabled {
+		return nil
+	}
+
+	// Stop the LLDP daemon.
+	err := systemd.StopUnit(ctx, "lldpd.service")
+	if err != nil {
+		return err
+	}
+
+	// Remove the configuration.
+	err = os.Remove("/etc/lldpd.d/incus-osd.conf")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// Start starts the service.
+func (n *LLDP) Start(ctx context.Context) error {
+	if !n.state.Services.LLDP.Config.Enabled {
+		return nil
+	}
+
+	ports := n.getPorts()
+	if len(ports) == 0 {
+		return errors.New("no physical interface to run LLDP on")
+	}
+
+	// Create the LLDP config directory if missing.
+	err := os.Mkdir("/etc/lldpd.d", 0o755)
+	if err != nil && !errors.Is(err, os.ErrExist) {
+		return err
+	}
+
+	err = os.WriteFile("/etc/lldpd.d/incus-osd.conf", []byte(n.generateConfig(ports)), 0o644)
+	if err != nil {
+		return err
+	}
+
+	// (Re)start the daemon to pick up the configuration.
+	err = systemd.RestartUnit(ctx, "lldpd.service")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ShouldStart returns true if the service should be started on boot.
+func (n *LLDP) ShouldStart() bool {
+	return n.state.Services.LLDP.Config.Enabled
+}
+
+// Struct returns the API struct for the LLDP service.
+func (*LLDP) Struct() any {
+	return &api.ServiceLLDP{}
+}
+
+// getPorts returns the physical devices LLDP should run on, mapped to the name they're reported as.
+func (n *LLDP) getPorts() map[string]string {
+	ports := map[string]string{}
+
+	networkCfg := n.state.System.Network.Config
+	if networkCfg == nil {
+		return ports
+	}
+
+	selected := func(name string) bool {
+		return len(n.state.Services.LLDP.Config.Interfaces) == 0 || slices.Contains(n.state.Services.LLDP.Config.Interfaces, name)
+	}
+
+	physicalDevice := func(hwaddr string) string {
+		return "_p" + strings.ToLower(strings.ReplaceAll(hwaddr, ":", ""))
+	}
+
+	for _, i := range networkCfg.Interfaces {
+		if selected(i.Name) {
+			ports[physicalDevice(i.Hwaddr)] = i.Name
+		}
+	}
+
+	for _, b := range networkCfg.Bonds {
+		if selected(b.Name) {
+			for _, member := range b.Members {
+				ports[physicalDevice(member)] = b.Name + "/" + member
+			}
+		}
+	}
+
+	for _, b := range networkCfg.Bridges {
+		if selected(b.Name) {
+			for _, member := range b.Members {
+				ports[physicalDevice(member)] = b.Name + "/" + member
+			}
+		}
+	}
+
+	return ports
+}
+
+// generateConfig returns the lldpd configuration, advertising the host's name, ports and management addresses.
+func (n *LLDP) generateConfig(ports map[string]string) string {
+	var ret strings.Builder
+
+	devices := make([]string, 0, len(ports))
+	for device := range ports {
+		devices = append(devices, device)
+	}
+
+	slices.Sort(devices)
+
+	_, _ = fmt.Fprintf(&ret, "configure system hostname %s\n", n.state.Hostname())
+	_, _ = fmt.Fprintf(&ret, "configure system interface pattern %s\n", strings.Join(devices, ","))
+	_, _ = ret.WriteString("configure lldp portidsubtype macaddress\n")
+
+	// Default to the addresses of the management interfaces.
+	managementAddresses := n.state.Services.LLDP.Config.ManagementAddresses
+	if len(managementAddresses) == 0 {
+		for _, name := range n.state.System.Network.State.GetInterfaceNamesByRole(api.SystemNetworkInterfaceRoleManagement) {
+			managementAddresses = append(managementAddresses, n.state.System.Network.State.Interfaces[name].Addresses...)
+		}
+	}
+
+	if len(managementAddresses) > 0 {
+		_, _ = fmt.Fprintf(&ret, "configure system ip management pattern %s\n", strings.Join(managementAddresses, ","))
+	}
+
+	for _, device := range devices {
+		_, _ = fmt.Fprintf(&ret, "configure ports %s lldp portdescription \"%s\"\n", device, ports[device])
+	}
+
+	return ret.String()
+}
+
+// getNeighbors returns the neighbors currently seen on each port.
+func (n *LLDP) getNeighbors(ctx context.Context) (map[string][]api.ServiceLLDPNeighbor, error) {
+	type lldpValue struct {
+		Value string `json:"value"`
+	}
+
+	type lldpNeighbors struct {
+		LLDP []struct {
+			Interface []struct {
+				Name    string `json:"name"`
+				Chassis []struct {
+					ID     []lldpValue `json:"id"`
+					Name   []lldpValue `json:"name"`
+					Descr  []lldpValue `json:"descr"`
+					MgmtIP []lldpValue `json:"mgmt-ip"`
+				} `json:"chassis"`
+				Port []struct {
+					ID    []lldpValue `json:"id"`
+					Descr []lldpValue `json:"descr"`
+				} `json:"port"`
+			} `json:"interface"`
+		} `json:"lldp"`
+	}
+
+	firstValue := func(values []lldpValue) string {
+		if len(values) == 0 {
+			return ""
+		}
+
+		return values[0].Value
+	}
+
+	output, err := subprocess.RunCommandContext(ctx, "lldpcli", "-f", "json0", "show", "neighbors", "details")
+	if err != nil {
+		return nil, err
+	}
+
+	neighbors := lldpNeighbors{}
+
+	err = json.Unmarshal([]byte(output), &neighbors)
+	if err != nil {
+		return nil, err
+	}
+
+	ports := n.getPorts()
+	ret := map[string][]api.ServiceLLDPNeighbor{}
+
+	for _, entry := range neighbors.LLDP {
+		for _, iface := range entry.Interface {
+			name, ok := ports[iface.Name]
+			if !ok {
+				slog.DebugContext(ctx, "Ignoring LLDP neighbor on unknown port", "port", iface.Name)
+
+				continue
+			}
+
+			neighbor := api.ServiceLLDPNeighbor{
+				ManagementAddresses: []string{},
+			}
+
+			if len(iface.Chassis) > 0 {
+				neighbor.ChassisID = firstValue(iface.Chassis[0].ID)
+				neighbor.ChassisName = firstValue(iface.Chassis[0].Name)
+				neighbor.SystemDescription = firstValue(iface.Chassis[0].Descr)
+
+				for _, address := range iface.Chassis[0].MgmtIP {
+					neighbor.ManagementAddresses = append(neighbor.ManagementAddresses, address.Value)
+				}
+			}
+
+			if len(iface.Port) > 0 {
+				neighbor.PortID = firstValue(iface.Port[0].ID)
+				neighbor.PortDescription = firstValue(iface.Port[0].Descr)
+			}
+
+			ret[name] = append(ret[name], neighbor)
+		}
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/logforward"
+	"github.com/lxc/incus-os/incus-osd/internal/secrets"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+// LogForward represents the system log forwarding service.
+type LogForward struct {
+	common
+
+	state *state.State
+}
+
+// Get returns the current service state.
+func (n *LogForward) Get(_ context.Context) (any, error) {
+	// Initialize the target list if missing.
+	if n.state.Services.LogForward.Config.Targets == nil {
+		n.state.Services.LogForward.Config.Targets = []api.ServiceLogForwardTarget{}
+	}
+
+	// Get the forwarding status if enabled.
+	n.state.Services.LogForward.State.Targets = []api.ServiceLogForwardTargetState{}
+
+	if n.state.Services.LogForward.Config.Enabled {
+		n.state.Services.LogForward.State.Targets = logforward.Status(n.state.Services.LogForward.Config.Targets)
+	}
+
+	return n.state.Services.LogForward, nil
+}
+
+// Update updates the service configuration.
+func (n *LogForward) Update(ctx context.Context, req any) error {
+	newState, ok := req.(*api.ServiceLogForward)
+	if !ok {
+		return fmt.Errorf("request type \"%T\" isn't expected ServiceLogForward", req)
+	}
+
+	err := logforward.Validate(newState.Config.Targets)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range newState.Config.Targets {
+		for _, value := range []string{target.Password, target.TLSClientKey} {
+			err := secrets.ValidateReference(n.state.System.Security.Config.Secrets, value)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Save the state on return.
+	defer n.state.Save()
+
+	// Disable the service if requested.
+	if n.state.Services.LogForward.Config.Enabled && !newState.Config.Enabled {
+		err := n.Stop(ctx)
+		if err != nil {
+			return err
+		}
+
+		n.state.Services.LogForward.Config = newState.Config
+
+		return nil
+	}
+
+	// Update the configuration.
+	n.state.Services.LogForward.Config = newState.Config
+
+	// Enable or reconfigure the service if requested.
+	return n.Start(ctx)
+}
+
+// Stop stops the service.
+func (n *LogForward) Stop(_ context.Context) error {
+	if !n.state.Services.LogForward.Config.Enabled {
+		return nil
+	}
+
+	logforward.Stop()
+
+	return nil
+}
+
+// Start starts the service.
+func (n *LogForward) Start(ctx context.Context) error {
+	if !n.state.Services.LogForward.Config.Enabled {
+		return nil
+	}
+
+	// Resolve the referenced secrets.
+	targets := make([]api.ServiceLogForwardTarget, 0, len(n.state.Services.LogForward.Config.Targets))
+
+	for _, target := range n.state.Services.LogForward.Config.Targets {
+		var err error
+
+		target.Password, err = secrets.Resolve(ctx, n.state.System.Security.Config.Secrets, target.Password)
+		if err != nil {
+			return err
+		}
+
+		target.TLSClientKey, err = secrets.Resolve(ctx, n.state.System.Security.Config.Secrets, target.TLSClientKey)
+		if err != nil {
+			return err
+		}
+
+		targets = append(targets, target)
+	}
+
+	return logforward.Start(ctx, targets)
+}
+
+// ShouldStart returns true if the service should be started on boot.
+func (n *LogForward) ShouldStart() bool {
+	return n.state.Services.LogForward.Config.Enabled
+}
+
+// Struct returns the API struct for the log forwarding service.
+func (*LogForward) Struct() any {
+	return &api.ServiceLogForward{}
+}
@@ -5,7 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/lxc/incus/v6/shared/subprocess"
 
@@ -72,6 +77,26 @@ func (n *LVM) Get(ctx context.Context) (any, error) {
 
 	n.state.Services.LVM.State.Log = vgs.Log
 
+	// Get thin pool usage.
+	if n.state.Services.LVM.Config.Enabled && len(n.state.Services.LVM.Config.ThinPools) > 0 {
+		thinPools, err := n.getThinPools(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		n.state.Services.LVM.State.ThinPools = thinPools
+	}
+
+	// Get VDO space savings.
+	if n.state.Services.LVM.Config.Enabled && len(n.state.Services.LVM.Config.VDOVolumes) > 0 {
+		vdoVolumes, err := n.getVDOVolumes(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		n.state.Services.LVM.State.VDOVolumes = vdoVolumes
+	}
+
 	return n.state.Services.LVM, nil
 }
 
@@ -82,6 +107,16 @@ func (n *LVM) Update(ctx context.Context, req any) error {
 		return fmt.Errorf("request type \"%T\" isn't expected ServiceLVM", req)
 	}
 
+	err := n.validateThinPools(newState.Config.ThinPools)
+	if err != nil {
+		return err
+	}
+
+	err = n.validateVDOVolumes(newState.Config.VDOVolumes)
+	if err != nil {
+		return err
+	}
+
 	// Save the state on return.
 	defer n.state.Save()
 
@@ -112,6 +147,19 @@ func (n *LVM) Update(ctx context.Context, req any) error {
 		if err != nil {
 			return err
 		}
+
+		// Create any new thin pool or VDO volume.
+		if n.state.Services.LVM.Config.Enabled {
+			err := n.createThinPools(ctx)
+			if err != nil {
+				return err
+			}
+
+			err = n.createVDOVolumes(ctx)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -162,7 +210,13 @@ func (n *LVM) Start(ctx context.Context) error {
 		return err
 	}
 
-	return nil
+	// Create the missing thin pools and VDO volumes.
+	err = n.createThinPools(ctx)
+	if err != nil {
+		return err
+	}
+
+	return n.createVDOVolumes(ctx)
 }
 
 // Reset will forcefully reset the service.
@@ -217,3 +271,357 @@ local {
 
 	return nil
 }
+
+// RunLVMThinPoolMonitor periodically checks the usage of the configured thin pools, logging an alarm
+// when crossing a warning threshold and extending the pools according to their policy, before they
+// fill up and suspend I/O. It only returns once the context is cancelled.
+func RunLVMThinPoolMonitor(ctx context.Context, s *state.State) {
+	n := &LVM{state: s}
+	alarms := map[string][]string{}
+
+	for {
+		if s.Services.LVM.Config.Enabled && len(s.Services.LVM.Config.ThinPools) > 0 {
+			n.checkThinPools(ctx, alarms)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Minute):
+		}
+	}
+}
+
+// checkThinPools updates the usage of the thin pools, logs the alarms which weren't previously
+// raised and extends the pools above their auto-extend threshold.
+func (n *LVM) checkThinPools(ctx context.Context, alarms map[string][]string) {
+	thinPools, err := n.getThinPools(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to get the LVM thin pools usage", "err", err.Error())
+
+		return
+	}
+
+	n.state.Services.LVM.State.ThinPools = thinPools
+
+	for i, pool := range thinPools {
+		name := pool.VGName + "/" + pool.Name
+
+		for _, alarm := range pool.Alarms {
+			if !slices.Contains(alarms[name], alarm) {
+				slog.WarnContext(ctx, "LVM thin pool usage alarm", "pool", name, "alarm", alarm, "data_percent", pool.DataPercent, "metadata_percent", pool.MetadataPercent)
+			}
+		}
+
+		alarms[name] = pool.Alarms
+
+		// Extend the pool, LVM taking care of both its data and metadata.
+		cfg := n.state.Services.LVM.Config.ThinPools[i]
+		if !pool.Active || cfg.AutoExtendThreshold == 0 || (pool.DataPercent < float64(cfg.AutoExtendThreshold) && pool.MetadataPercent < float64(cfg.AutoExtendThreshold)) {
+			continue
+		}
+
+		percent := cfg.AutoExtendPercent
+		if percent == 0 {
+			percent = 20
+		}
+
+		policy := fmt.Sprintf("activation { thin_pool_autoextend_threshold=%d thin_pool_autoextend_percent=%d }", cfg.AutoExtendThreshold, percent)
+
+		_, err := subprocess.RunCommandContext(ctx, "lvextend", "--use-policies", "--config", policy, name)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to extend LVM thin pool", "pool", name, "err", err.Error())
+
+			continue
+		}
+
+		slog.InfoContext(ctx, "Extended LVM thin pool", "pool", name, "percent", percent)
+	}
+}
+
+// validateThinPools checks that the thin pools configuration is usable.
+func (*LVM) validateThinPools(thinPools []api.ServiceLVMThinPool) error {
+	names := []string{}
+
+	for _, pool := range thinPools {
+		if pool.VGName == "" || pool.Name == "" {
+			return errors.New("thin pools require a volume group and a name")
+		}
+
+		name := pool.VGName + "/" + pool.Name
+		if slices.Contains(names, name) {
+			return fmt.Errorf("duplicate thin pool %q", name)
+		}
+
+		names = append(names, name)
+
+		if pool.Size == "" {
+			return fmt.Errorf("missing size for thin pool %q", name)
+		}
+
+		for _, threshold := range []int{pool.DataWarningThreshold, pool.MetadataWarningThreshold, pool.AutoExtendThreshold, pool.AutoExtendPercent} {
+			if threshold < 0 || threshold > 100 {
+				return fmt.Errorf("invalid percentage %d for thin pool %q", threshold, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// createThinPools creates the configured thin pools which don't exist yet.
+func (n *LVM) createThinPools(ctx context.Context) error {
+	for _, pool := range n.state.Services.LVM.Config.ThinPools {
+		name := pool.VGName + "/" + pool.Name
+
+		_, err := subprocess.RunCommandContext(ctx, "lvs", name)
+		if err == nil {
+			continue
+		}
+
+		args := []string{"--type", "thin-pool", "--name", pool.Name}
+
+		if strings.Contains(pool.Size, "%") {
+			args = append(args, "--extents", pool.Size)
+		} else {
+			args = append(args, "--size", pool.Size)
+		}
+
+		if pool.MetadataSize != "" {
+			args = append(args, "--poolmetadatasize", pool.MetadataSize)
+		}
+
+		args = append(args, pool.VGName)
+
+		_, err = subprocess.RunCommandContext(ctx, "lvcreate", args...)
+		if err != nil {
+			return fmt.Errorf("failed to create thin pool %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// getThinPools returns the usage of the configured thin pools, along with their alarms.
+func (n *LVM) getThinPools(ctx context.Context) ([]api.ServiceLVMThinPoolState, error) {
+	type lvsRaw struct {
+		Report []struct {
+			LV []struct {
+				VGName          string `json:"vg_name"`
+				LVName          string `json:"lv_name"`
+				LVSize          string `json:"lv_size"`
+				LVActive        string `json:"lv_active"`
+				DataPercent     string `json:"data_percent"`
+				MetadataPercent string `json:"metadata_percent"`
+			} `json:"lv"`
+		} `json:"report"`
+	}
+
+	output, err := subprocess.RunCommandContext(ctx, "lvs", "--reportformat", "json", "--select", "lv_layout=pool", "-o", "vg_name,lv_name,lv_size,lv_active,data_percent,metadata_percent")
+	if err != nil {
+		return nil, err
+	}
+
+	lvs := lvsRaw{}
+
+	err = json.Unmarshal([]byte(output), &lvs)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]api.ServiceLVMThinPoolState, 0, len(n.state.Services.LVM.Config.ThinPools))
+
+	for _, pool := range n.state.Services.LVM.Config.ThinPools {
+		poolState := api.ServiceLVMThinPoolState{
+			VGName: pool.VGName,
+			Name:   pool.Name,
+			Alarms: []string{},
+		}
+
+		for _, report := range lvs.Report {
+			for _, lv := range report.LV {
+				if lv.VGName != pool.VGName || lv.LVName != pool.Name {
+					continue
+				}
+
+				poolState.Size = lv.LVSize
+
+				// Usage is only reported while active.
+				if lv.DataPercent == "" {
+					continue
+				}
+
+				poolState.Active = true
+				poolState.DataPercent, _ = strconv.ParseFloat(lv.DataPercent, 64)
+				poolState.MetadataPercent, _ = strconv.ParseFloat(lv.MetadataPercent, 64)
+			}
+		}
+
+		if poolState.Size == "" {
+			poolState.Alarms = append(poolState.Alarms, "missing")
+		}
+
+		dataThreshold := pool.DataWarningThreshold
+		if dataThreshold == 0 {
+			dataThreshold = 80
+		}
+
+		metadataThreshold := pool.MetadataWarningThreshold
+		if metadataThreshold == 0 {
+			metadataThreshold = 80
+		}
+
+		if poolState.Active && poolState.DataPercent >= float64(dataThreshold) {
+			poolState.Alarms = append(poolState.Alarms, "data")
+		}
+
+		if poolState.Active && poolState.MetadataPercent >= float64(metadataThreshold) {
+			poolState.Alarms = append(poolState.Alarms, "metadata")
+		}
+
+		ret = append(ret, poolState)
+	}
+
+	return ret, nil
+}
+
+// validateVDOVolumes checks that the VDO volumes configuration is usable.
+func (*LVM) validateVDOVolumes(vdoVolumes []api.ServiceLVMVDOVolume) error {
+	names := []string{}
+
+	for _, volume := range vdoVolumes {
+		if volume.VGName == "" || volume.Name == "" {
+			return errors.New("VDO volumes require a volume group and a name")
+		}
+
+		name := volume.VGName + "/" + volume.Name
+		if slices.Contains(names, name) {
+			return fmt.Errorf("duplicate VDO volume %q", name)
+		}
+
+		names = append(names, name)
+
+		if volume.Size == "" || volume.VirtualSize == "" {
+			return fmt.Errorf("missing size or virtual size for VDO volume %q", name)
+		}
+	}
+
+	return nil
+}
+
+// createVDOVolumes creates the configured VDO volumes which don't exist yet, each backed by its own
+// VDO pool named after it.
+func (n *LVM) createVDOVolumes(ctx context.Context) error {
+	for _, volume := range n.state.Services.LVM.Config.VDOVolumes {
+		name := volume.VGName + "/" + volume.Name
+
+		_, err := subprocess.RunCommandContext(ctx, "lvs", name)
+		if err == nil {
+			continue
+		}
+
+		args := []string{"--type", "vdo", "--name", volume.Name, "--virtualsize", volume.VirtualSize}
+
+		if strings.Contains(volume.Size, "%") {
+			args = append(args, "--extents", volume.Size)
+		} else {
+			args = append(args, "--size", volume.Size)
+		}
+
+		args = append(args, "--compression", boolToYN(!volume.DisableCompression), "--deduplication", boolToYN(!volume.DisableDeduplication))
+		args = append(args, n.getVDOPoolName(volume))
+
+		_, err = subprocess.RunCommandContext(ctx, "lvcreate", args...)
+		if err != nil {
+			return fmt.Errorf("failed to create VDO volume %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// getVDOVolumes returns the space savings of the configured VDO volumes, as reported by their VDO pool.
+func (n *LVM) getVDOVolumes(ctx context.Context) ([]api.ServiceLVMVDOVolumeState, error) {
+	type lvsRaw struct {
+		Report []struct {
+			LV []struct {
+				VGName              string `json:"vg_name"`
+				LVName              string `json:"lv_name"`
+				LVSize              string `json:"lv_size"`
+				VDOOperatingMode    string `json:"vdo_operating_mode"`
+				VDOCompressionState string `json:"vdo_compression_state"`
+				VDOIndexState       string `json:"vdo_index_state"`
+				VDOUsedSize         string `json:"vdo_used_size"`
+				VDOSavingPercent    string `json:"vdo_saving_percent"`
+			} `json:"lv"`
+		} `json:"report"`
+	}
+
+	output, err := subprocess.RunCommandContext(ctx, "lvs", "--reportformat", "json", "--select", "segtype=vdo || segtype=vdo-pool", "-o", "vg_name,lv_name,lv_size,vdo_operating_mode,vdo_compression_state,vdo_index_state,vdo_used_size,vdo_saving_percent")
+	if err != nil {
+		return nil, err
+	}
+
+	lvs := lvsRaw{}
+
+	err = json.Unmarshal([]byte(output), &lvs)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]api.ServiceLVMVDOVolumeState, 0, len(n.state.Services.LVM.Config.VDOVolumes))
+
+	for _, volume := range n.state.Services.LVM.Config.VDOVolumes {
+		volumeState := api.ServiceLVMVDOVolumeState{
+			VGName: volume.VGName,
+			Name:   volume.Name,
+		}
+
+		_, poolName, _ := strings.Cut(n.getVDOPoolName(volume), "/")
+
+		for _, report := range lvs.Report {
+			for _, lv := range report.LV {
+				if lv.VGName != volume.VGName {
+					continue
+				}
+
+				switch lv.LVName {
+				case volume.Name:
+					volumeState.Size = lv.LVSize
+				case poolName:
+					volumeState.PhysicalSize = lv.LVSize
+
+					// Statistics are only reported while active.
+					if lv.VDOOperatingMode == "" {
+						continue
+					}
+
+					volumeState.Active = true
+					volumeState.UsedSize = lv.VDOUsedSize
+					volumeState.SavingPercent, _ = strconv.ParseFloat(lv.VDOSavingPercent, 64)
+					volumeState.OperatingMode = lv.VDOOperatingMode
+					volumeState.CompressionState = lv.VDOCompressionState
+					volumeState.IndexState = lv.VDOIndexState
+				}
+			}
+		}
+
+		ret = append(ret, volumeState)
+	}
+
+	return ret, nil
+}
+
+// getVDOPoolName returns the name of the VDO pool backing a VDO volume, prefixed with its volume group.
+func (*LVM) getVDOPoolName(volume api.ServiceLVMVDOVolume) string {
+	return volume.VGName + "/" + volume.Name + "_vdopool"
+}
+
+// boolToYN returns the "y" or "n" value of LVM boolean options.
+func boolToYN(value bool) string {
+	if value {
+		return "y"
+	}
+
+	return "n"
+}
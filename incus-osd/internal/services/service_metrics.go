@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/secrets"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+	"github.com/lxc/incus-os/incus-osd/internal/systemd"
+)
+
+// metricsPath holds the TLS configuration of the node exporter.
+var metricsPath = "/run/incus-os/metrics"
+
+// metricsOverridePath replaces the default node exporter override, only listening on localhost.
+var metricsOverridePath = "/run/systemd/system/prometheus-node-exporter.service.d/override.conf"
+
+var metricsOverride = `# Generated by IncusOS
+[Service]
+Environment="ARGS=--web.listen-address=%s --web.config.file=%s"
+EnvironmentFile=
+User=root
+`
+
+var metricsWebConfig = `# Generated by IncusOS
+tls_server_config:
+  cert_file: %s
+  key_file: %s
+  min_version: TLS12
+`
+
+var metricsWebConfigClientAuth = `  client_auth_type: RequireAndVerifyClientCert
+  client_ca_file: %s
+`
+
+// Metrics represents the system metrics service.
+type Metrics struct {
+	common
+
+	state *state.State
+}
+
+// Get returns the current service state.
+func (n *Metrics) Get(_ context.Context) (any, error) {
+	// Initialize the trusted certificate list if missing.
+	if n.state.Services.Metrics.Config.TLSTrustedCertificates == nil {
+		n.state.Services.Metrics.Config.TLSTrustedCertificates = []string{}
+	}
+
+	// Retrieve the expiry of the server certificate.
+	n.state.Services.Metrics.State.TLSServerCertificateExpiry = ""
+
+	cert, err := n.parseCertificate(n.state.Services.Metrics.Config.TLSServerCertificate)
+	if err == nil {
+		n.state.Services.Metrics.State.TLSServerCertificateExpiry = cert.NotAfter.UTC().Format(time.RFC3339)
+	}
+
+	return n.state.Services.Metrics, nil
+}
+
+// Update updates the service configuration.
+func (n *Metrics) Update(ctx context.Context, req any) error {
+	newState, ok := req.(*api.ServiceMetrics)
+	if !ok {
+		return fmt.Errorf("request type \"%T\" isn't expected ServiceMetrics", req)
+	}
+
+	if newState.Config.Enabled {
+		err := n.validate(newState.Config)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Save the state on return.
+	defer n.state.Save()
+
+	// Disable the service if requested.
+	if n.state.Services.Metrics.Config.Enabled && !newState.Config.Enabled {
+		err := n.Stop(ctx)
+		if err != nil {
+			return err
+		}
+
+		n.state.Services.Metrics.Config = newState.Config
+
+		return nil
+	}
+
+	// Update the configuration.
+	n.state.Services.Metrics.Config = newState.Config
+
+	// Enable or reconfigure the service if requested.
+	return n.Start(ctx)
+}
+
+// Stop stops the service.
+func (n *Metrics) Stop(ctx context.Context) error {
+	if !n.state.Services.Metrics.Config.Enabled {
+		return nil
+	}
+
+	// Remove the override and the TLS configuration.
+	err := os.Remove(metricsOverridePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	err = os.RemoveAll(metricsPath)
+	if err != nil {
+		return err
+	}
+
+	// Restart the node exporter, only listening on localhost again.
+	err = systemd.ReloadDaemon(ctx)
+	if err != nil {
+		return err
+	}
+
+	return systemd.RestartUnit(ctx, "prometheus-node-exporter.service")
+}
+
+// Start starts the service.
+func (n *Metrics) Start(ctx context.Context) error {
+	config := n.state.Services.Metrics.Config
+
+	if !config.Enabled {
+		return nil
+	}
+
+	key, err := secrets.Resolve(ctx, n.state.System.Security.Config.Secrets, config.TLSServerKey)
+	if err != nil {
+		return err
+	}
+
+	_, err = tls.X509KeyPair([]byte(config.TLSServerCertificate), []byte(key))
+	if err != nil {
+		return fmt.Errorf("invalid TLS server certificate: %w", err)
+	}
+
+	// Write the TLS configuration.
+	err = os.MkdirAll(metricsPath, 0o700)
+	if err != nil {
+		return err
+	}
+
+	files := map[string]string{
+		"server.crt": config.TLSServerCertificate,
+		"server.key": key,
+	}
+
+	webConfig := fmt.Sprintf(metricsWebConfig, filepath.Join(metricsPath, "server.crt"), filepath.Join(metricsPath, "server.key"))
+
+	if len(config.TLSTrustedCertificates) > 0 {
+		files["trusted.crt"] = strings.Join(config.TLSTrustedCertificates, "\n")
+		webConfig += fmt.Sprintf(metricsWebConfigClientAuth, filepath.Join(metricsPath, "trusted.crt"))
+	}
+
+	files["web.yml"] = webConfig
+
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(metricsPath, name), []byte(content), 0o600)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Override the node exporter configuration.
+	listenAddress := config.ListenAddress
+	if listenAddress == "" {
+		listenAddress = ":9100"
+	}
+
+	err = os.MkdirAll(filepath.Dir(metricsOverridePath), 0o755)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(metricsOverridePath, fmt.Appendf(nil, metricsOverride, listenAddress, filepath.Join(metricsPath, "web.yml")), 0o644)
+	if err != nil {
+		return err
+	}
+
+	err = systemd.ReloadDaemon(ctx)
+	if err != nil {
+		return err
+	}
+
+	return systemd.RestartUnit(ctx, "prometheus-node-exporter.service")
+}
+
+// ShouldStart returns true if the service should be started on boot.
+func (n *Metrics) ShouldStart() bool {
+	return n.state.Services.Metrics.Config.Enabled
+}
+
+// Struct returns the API struct for the metrics service.
+func (*Metrics) Struct() any {
+	return &api.ServiceMetrics{}
+}
+
+// validate checks that the metrics configuration is usable. Metrics are only exposed over TLS.
+func (n *Metrics) validate(cfg api.ServiceMetricsConfig) error {
+	if cfg.ListenAddress != "" {
+		_, port, err := net.SplitHostPort(cfg.ListenAddress)
+		if err != nil || port == "" {
+			return fmt.Errorf("invalid listen address %q", cfg.ListenAddress)
+		}
+	}
+
+	if cfg.TLSServerCertificate == "" || cfg.TLSServerKey == "" {
+		return errors.New("a TLS server certificate and key are required")
+	}
+
+	_, err := n.parseCertificate(cfg.TLSServerCertificate)
+	if err != nil {
+		return fmt.Errorf("invalid TLS server certificate: %w", err)
+	}
+
+	err = secrets.ValidateReference(n.state.System.Security.Config.Secrets, cfg.TLSServerKey)
+	if err != nil {
+		return err
+	}
+
+	for _, trusted := range cfg.TLSTrustedCertificates {
+		_, err := n.parseCertificate(trusted)
+		if err != nil {
+			return fmt.Errorf("invalid trusted certificate: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// parseCertificate parses the first certificate of a PEM encoded value.
+func (*Metrics) parseCertificate(value string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(value))
+	if block == nil {
+		return nil, errors.New("no certificate found")
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/secrets"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+	"github.com/lxc/incus-os/incus-osd/internal/systemd"
+)
+
+// netdataConfig only keeps the metrics in memory, streaming them to the parents, and disables the
+// local dashboard, alerts and machine learning, which are handled by the parents.
+var netdataConfig = `# Generated by IncusOS
+[global]
+    hostname = %s
+
+[db]
+    mode = ram
+    update every = %d
+
+[web]
+    mode = none
+
+[health]
+    enabled = no
+
+[ml]
+    enabled = no
+`
+
+var netdataStreamConfig = `# Generated by IncusOS
+[stream]
+    enabled = yes
+    destination = %s
+    api key = %s
+`
+
+// Netdata represents the system Netdata service.
+type Netdata struct {
+	common
+
+	state *state.State
+}
+
+// Get returns the current service state.
+func (n *Netdata) Get(_ context.Context) (any, error) {
+	// Initialize the destination list if missing.
+	if n.state.Services.Netdata.Config.Destinations == nil {
+		n.state.Services.Netdata.Config.Destinations = []string{}
+	}
+
+	return n.state.Services.Netdata, nil
+}
+
+// Update updates the service configuration.
+func (n *Netdata) Update(ctx context.Context, req any) error {
+	newState, ok := req.(*api.ServiceNetdata)
+	if !ok {
+		return fmt.Errorf("request type \"%T\" isn't expected ServiceNetdata", req)
+	}
+
+	if newState.Config.Enabled {
+		err := n.validate(newState.Config)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Save the state on return.
+	defer n.state.Save()
+
+	// Disable the service if requested.
+	if n.state.Services.Netdata.Config.Enabled && !newState.Config.Enabled {
+		err := n.Stop(ctx)
+		if err != nil {
+			return err
+		}
+
+		n.state.Services.Netdata.Config = newState.Config
+
+		return nil
+	}
+
+	// Update the configuration.
+	n.state.Services.Netdata.Config = newState.Config
+
+	// Enable or reconfigure the service if requested.
+	return n.Start(ctx)
+}
+
+// Stop stops the service.
+func (n *Netdata) Stop(ctx context.Context) error {
+	if !n.state.Services.Netdata.Config.Enabled {
+		return nil
+	}
+
+	// Stop Netdata.
+	err := systemd.StopUnit(ctx, "netdata.service")
+	if err != nil {
+		return err
+	}
+
+	// Remove the streaming configuration, holding the API key.
+	for _, path := range []string{"/etc/netdata/stream.conf", "/etc/netdata/ca.crt"} {
+		err := os.Remove(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Start starts the service.
+func (n *Netdata) Start(ctx context.Context) error {
+	config := n.state.Services.Netdata.Config
+
+	if !config.Enabled {
+		return nil
+	}
+
+	apiKey, err := secrets.Resolve(ctx, n.state.System.Security.Config.Secrets, config.APIKey)
+	if err != nil {
+		return err
+	}
+
+	err = uuid.Validate(apiKey)
+	if err != nil {
+		return fmt.Errorf("invalid Netdata API key: %w", err)
+	}
+
+	// Create the Netdata config directory if missing.
+	err = os.Mkdir("/etc/netdata", 0o755)
+	if err != nil && !errors.Is(err, os.ErrExist) {
+		return err
+	}
+
+	updateEvery := config.UpdateEvery
+	if updateEvery == 0 {
+		updateEvery = 1
+	}
+
+	err = os.WriteFile("/etc/netdata/netdata.conf", fmt.Appendf(nil, netdataConfig, n.state.Hostname(), updateEvery), 0o644)
+	if err != nil {
+		return err
+	}
+
+	// Generate the streaming configuration.
+	destinations := make([]string, 0, len(config.Destinations))
+
+	for _, destination := range config.Destinations {
+		_, _, err := net.SplitHostPort(destination)
+		if err != nil {
+			destination = net.JoinHostPort(strings.Trim(destination, "[]"), "19999")
+		}
+
+		if config.TLS {
+			destination += ":SSL"
+		}
+
+		destinations = append(destinations, destination)
+	}
+
+	streamConfig := fmt.Sprintf(netdataStreamConfig, strings.Join(destinations, " "), apiKey)
+
+	if config.TLSCACertificate != "" {
+		err = os.WriteFile("/etc/netdata/ca.crt", []byte(config.TLSCACertificate), 0o644)
+		if err != nil {
+			return err
+		}
+
+		streamConfig += "    CAfile = /etc/netdata/ca.crt\n"
+	}
+
+	err = os.WriteFile("/etc/netdata/stream.conf", []byte(streamConfig), 0o600)
+	if err != nil {
+		return err
+	}
+
+	// (Re)start Netdata to pick up the configuration.
+	err = systemd.RestartUnit(ctx, "netdata.service")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ShouldStart returns true if the service should be started on boot.
+func (n *Netdata) ShouldStart() bool {
+	return n.state.Services.Netdata.Config.Enabled
+}
+
+// Struct returns the API struct for the Netdata service.
+func (*Netdata) Struct() any {
+	return &api.ServiceNetdata{}
+}
+
+// validate checks that the Netdata configuration is usable.
+func (n *Netdata) validate(cfg api.ServiceNetdataConfig) error {
+	if len(cfg.Destinations) == 0 {
+		return errors.New("at least one Netdata parent is required")
+	}
+
+	for _, destination := range cfg.Destinations {
+		if destination == "" || strings.ContainsAny(destination, " \n") {
+			return fmt.Errorf("invalid Netdata parent %q", destination)
+		}
+	}
+
+	if cfg.APIKey == "" {
+		return errors.New("a Netdata API key is required")
+	}
+
+	if secrets.IsReference(cfg.APIKey) {
+		err := secrets.ValidateReference(n.state.System.Security.Config.Secrets, cfg.APIKey)
+		if err != nil {
+			return err
+		}
+	} else {
+		err := uuid.Validate(cfg.APIKey)
+		if err != nil {
+			return fmt.Errorf("invalid Netdata API key: %w", err)
+		}
+	}
+
+	if cfg.UpdateEvery < 0 || cfg.UpdateEvery > 60 {
+		return fmt.Errorf("invalid update interval %d, must be between 1 and 60 seconds", cfg.UpdateEvery)
+	}
+
+	if cfg.TLSCACertificate != "" {
+		if !cfg.TLS {
+			return errors.New("a TLS CA certificate requires TLS to be enabled")
+		}
+
+		if !x509.NewCertPool().AppendCertsFromPEM([]byte(cfg.TLSCACertificate)) {
+			return errors.New("invalid TLS CA certificate")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,333 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+	"golang.org/x/sys/unix"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+// nfsMountPath is the directory under which the NFS exports are mounted.
+var nfsMountPath = "/var/mnt/nfs"
+
+var nfsNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// NFS represents the system NFS service.
+type NFS struct {
+	common
+
+	state *state.State
+}
+
+// Get returns the current service state.
+func (n *NFS) Get(_ context.Context) (any, error) {
+	// Initialize the mount list if missing.
+	if n.state.Services.NFS.Config.Mounts == nil {
+		n.state.Services.NFS.Config.Mounts = []api.ServiceNFSMount{}
+	}
+
+	// Check the health of each mount.
+	mounts := make([]api.ServiceNFSMountState, 0, len(n.state.Services.NFS.Config.Mounts))
+
+	if n.state.Services.NFS.Config.Enabled {
+		for _, mount := range n.state.Services.NFS.Config.Mounts {
+			mounts = append(mounts, n.getMountState(mount))
+		}
+	}
+
+	n.state.Services.NFS.State.Mounts = mounts
+
+	return n.state.Services.NFS, nil
+}
+
+// Update updates the service configuration.
+func (n *NFS) Update(ctx context.Context, req any) error {
+	newState, ok := req.(*api.ServiceNFS)
+	if !ok {
+		return fmt.Errorf("request type \"%T\" isn't expected ServiceNFS", req)
+	}
+
+	err := n.validate(newState.Config)
+	if err != nil {
+		return err
+	}
+
+	// Save the state on return.
+	defer n.state.Save()
+
+	// Disable the service if requested.
+	if n.state.Services.NFS.Config.Enabled && !newState.Config.Enabled {
+		err := n.Stop(ctx)
+		if err != nil {
+			return err
+		}
+
+		n.state.Services.NFS.Config = newState.Config
+
+		return nil
+	}
+
+	// Unmount the exports which were removed or changed.
+	if n.state.Services.NFS.Config.Enabled {
+		for _, oldMount := range n.state.Services.NFS.Config.Mounts {
+			if slices.ContainsFunc(newState.Config.Mounts, func(newMount api.ServiceNFSMount) bool {
+				return nfsMountEqual(oldMount, newMount)
+			}) {
+				continue
+			}
+
+			err := n.unmount(ctx, oldMount)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Update the configuration.
+	n.state.Services.NFS.Config = newState.Config
+
+	// Enable or reconfigure the service if requested.
+	return n.Start(ctx)
+}
+
+// Stop stops the service.
+func (n *NFS) Stop(ctx context.Context) error {
+	if !n.state.Services.NFS.Config.Enabled {
+		return nil
+	}
+
+	for _, mount := range n.state.Services.NFS.Config.Mounts {
+		err := n.unmount(ctx, mount)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Start starts the service.
+func (n *NFS) Start(ctx context.Context) error {
+	if !n.state.Services.NFS.Config.Enabled {
+		return nil
+	}
+
+	// Attempt all the mounts, so a single unreachable server doesn't prevent the others from being mounted.
+	errs := []error{}
+
+	for _, mount := range n.state.Services.NFS.Config.Mounts {
+		err := n.mount(ctx, mount)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to mount NFS export %q: %w", mount.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ShouldStart returns true if the service should be started on boot.
+func (n *NFS) ShouldStart() bool {
+	return n.state.Services.NFS.Config.Enabled
+}
+
+// Struct returns the API struct for the NFS service.
+func (*NFS) Struct() any {
+	return &api.ServiceNFS{}
+}
+
+// validate checks that the NFS configuration is usable.
+func (*NFS) validate(cfg api.ServiceNFSConfig) error {
+	names := []string{}
+
+	for _, mount := range cfg.Mounts {
+		if !nfsNameRegex.MatchString(mount.Name) || slices.Contains(names, mount.Name) {
+			return fmt.Errorf("invalid or duplicate NFS mount name %q", mount.Name)
+		}
+
+		names = append(names, mount.Name)
+
+		if mount.Server == "" || strings.ContainsAny(mount.Server, " ,/[]") {
+			return fmt.Errorf("invalid server %q for NFS mount %q", mount.Server, mount.Name)
+		}
+
+		if strings.Contains(mount.Server, ":") && net.ParseIP(mount.Server) == nil {
+			return fmt.Errorf("invalid server %q for NFS mount %q", mount.Server, mount.Name)
+		}
+
+		if !strings.HasPrefix(mount.Export, "/") || strings.ContainsAny(mount.Export, " ,") {
+			return fmt.Errorf("invalid export %q for NFS mount %q, must be an absolute path", mount.Export, mount.Name)
+		}
+
+		if mount.Version != "" && !slices.Contains([]string{"3", "4", "4.1", "4.2"}, mount.Version) {
+			return fmt.Errorf("invalid version %q for NFS mount %q", mount.Version, mount.Name)
+		}
+
+		for _, option := range mount.Options {
+			if option == "" || strings.ContainsAny(option, " ,") {
+				return fmt.Errorf("invalid option %q for NFS mount %q", option, mount.Name)
+			}
+
+			if strings.HasPrefix(option, "vers=") || strings.HasPrefix(option, "nfsvers=") {
+				return fmt.Errorf("the version of NFS mount %q must be set through the version field", mount.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// mount mounts an NFS export, if not already mounted.
+func (*NFS) mount(ctx context.Context, mount api.ServiceNFSMount) error {
+	path := filepath.Join(nfsMountPath, mount.Name)
+
+	mounted, err := isMounted(path)
+	if err != nil {
+		return err
+	}
+
+	if mounted {
+		return nil
+	}
+
+	err = os.MkdirAll(path, 0o755)
+	if err != nil {
+		return err
+	}
+
+	options := slices.Clone(mount.Options)
+	if mount.Version != "" {
+		options = append(options, "vers="+mount.Version)
+	}
+
+	args := []string{"-t", "nfs"}
+	if len(options) > 0 {
+		args = append(args, "-o", strings.Join(options, ","))
+	}
+
+	args = append(args, nfsMountSource(mount), path)
+
+	_, err = subprocess.RunCommandContext(ctx, "mount", args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// unmount unmounts an NFS export and removes its mountpoint.
+func (*NFS) unmount(ctx context.Context, mount api.ServiceNFSMount) error {
+	path := filepath.Join(nfsMountPath, mount.Name)
+
+	mounted, err := isMounted(path)
+	if err != nil {
+		return err
+	}
+
+	if mounted {
+		_, err := subprocess.RunCommandContext(ctx, "umount", path)
+		if err != nil {
+			return fmt.Errorf("failed to unmount NFS export %q: %w", mount.Name, err)
+		}
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// getMountState returns the health of an NFS mount. As a hard NFS mount blocks until its server
+// responds, a mount failing to respond within a few seconds is reported as unresponsive.
+func (*NFS) getMountState(mount api.ServiceNFSMount) api.ServiceNFSMountState {
+	path := filepath.Join(nfsMountPath, mount.Name)
+
+	ret := api.ServiceNFSMountState{
+		Name:   mount.Name,
+		Path:   path,
+		Status: "unmounted",
+	}
+
+	mounted, err := isMounted(path)
+	if err != nil {
+		ret.Error = err.Error()
+
+		return ret
+	}
+
+	if !mounted {
+		return ret
+	}
+
+	result := make(chan error, 1)
+
+	go func() {
+		var stat unix.Statfs_t
+
+		result <- unix.Statfs(path, &stat)
+	}()
+
+	select {
+	case err := <-result:
+		if err != nil {
+			ret.Status = "unresponsive"
+			ret.Error = err.Error()
+
+			return ret
+		}
+
+		ret.Status = "mounted"
+	case <-time.After(5 * time.Second):
+		ret.Status = "unresponsive"
+		ret.Error = "timed out waiting for the server"
+	}
+
+	return ret
+}
+
+// nfsMountSource returns the "server:export" source of an NFS mount.
+func nfsMountSource(mount api.ServiceNFSMount) string {
+	server := mount.Server
+
+	ip := net.ParseIP(server)
+	if ip != nil && ip.To4() == nil {
+		server = "[" + server + "]"
+	}
+
+	return server + ":" + mount.Export
+}
+
+// nfsMountEqual returns whether two NFS mounts are identical.
+func nfsMountEqual(a api.ServiceNFSMount, b api.ServiceNFSMount) bool {
+	return a.Name == b.Name && a.Server == b.Server && a.Export == b.Export && a.Version == b.Version && slices.Equal(a.Options, b.Options)
+}
+
+// isMounted returns whether a filesystem is mounted at the given path.
+func isMounted(path string) (bool, error) {
+	content, err := os.ReadFile("/proc/self/mounts")
+	if err != nil {
+		return false, err
+	}
+
+	for line := range strings.SplitSeq(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[1] == path {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -4,7 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"net"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -16,6 +21,11 @@ import (
 	"github.com/lxc/incus-os/incus-osd/internal/state"
 )
 
+var (
+	nvmeControllerRegex = regexp.MustCompile(`^nvme[0-9]+$`)
+	nvmePathRegex       = regexp.MustCompile(`^nvme([0-9]+)c([0-9]+)n([0-9]+)$`)
+)
+
 // NVME represents the system NVME service.
 type NVME struct {
 	common
@@ -47,6 +57,11 @@ func (n *NVME) Get(_ context.Context) (any, error) {
 		}
 
 		n.state.Services.NVME.State.HostNQN = strings.TrimSpace(string(hostnqn))
+
+		// Retrieve the fabrics connections and the paths through them.
+		n.state.Services.NVME.State.Connections = getNVMEConnections()
+		n.state.Services.NVME.State.Multipath = readNVMEAttr("/sys/module/nvme_core/parameters/multipath") == "Y"
+		n.state.Services.NVME.State.Subsystems = getNVMESubsystems()
 	}
 
 	return n.state.Services.NVME, nil
@@ -59,6 +74,22 @@ func (n *NVME) Update(ctx context.Context, req any) error {
 		return fmt.Errorf("request type \"%T\" isn't expected ServiceNVME", req)
 	}
 
+	// Validate the configuration.
+	if newState.Config.IOPolicy != "" && !slices.Contains(api.ServiceNVMEIOPolicies, newState.Config.IOPolicy) {
+		return fmt.Errorf("unsupported NVME I/O policy %q", newState.Config.IOPolicy)
+	}
+
+	if newState.Config.ControllerLossTimeout < -1 {
+		return errors.New("NVME controller loss timeout must be -1 or greater")
+	}
+
+	for _, target := range newState.Config.Targets {
+		_, err := getNVMETargetArgs(target)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Save the state on return.
 	defer n.state.Save()
 
@@ -108,13 +139,29 @@ func (n *NVME) Start(ctx context.Context) error {
 	}
 
 	// Ensure we have the right modules.
-	for _, module := range []string{"nvme", "nvme-fabrics", "nvme-tcp"} {
+	modules := []string{"nvme", "nvme-fabrics", "nvme-tcp"}
+
+	for _, target := range n.state.Services.NVME.Config.Targets {
+		if target.Transport == api.ServiceNVMETransportRDMA && !slices.Contains(modules, "nvme-rdma") {
+			modules = append(modules, "nvme-rdma")
+		}
+	}
+
+	for _, module := range modules {
 		_, err := subprocess.RunCommandContext(ctx, "modprobe", module)
 		if err != nil {
 			return err
 		}
 	}
 
+	// Set the default I/O policy of new subsystems.
+	if n.state.Services.NVME.Config.IOPolicy != "" {
+		err := os.WriteFile("/sys/module/nvme_core/parameters/iopolicy", []byte(n.state.Services.NVME.Config.IOPolicy), 0o600)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Create the NVME config directory if missing.
 	err := os.Mkdir("/etc/nvme", 0o700)
 	if err != nil && !errors.Is(err, os.ErrExist) {
@@ -177,11 +224,23 @@ func (n *NVME) Start(ctx context.Context) error {
 	defer cancel()
 
 	for _, target := range n.state.Services.NVME.Config.Targets {
+		// Skip targets which can't currently be reached, like when the interface has no address yet.
+		args, err := getNVMETargetArgs(target)
+		if err != nil {
+			slog.WarnContext(ctx, "Skipping NVME target", "address", target.Address, "err", err.Error())
+
+			continue
+		}
+
+		if n.state.Services.NVME.Config.ControllerLossTimeout != 0 {
+			args = append(args, "--ctrl-loss-tmo="+strconv.Itoa(n.state.Services.NVME.Config.ControllerLossTimeout))
+		}
+
 		// Attempt to connect to the target (wait up to 5s).
 		//
 		// This isn't fatal as some controllers may be temporarily offline.
 		for range 10 {
-			_, err = subprocess.RunCommandContext(ctxTimeout, "nvme", "discover", "--transport", target.Transport, "--traddr", target.Address, "--trsvcid", strconv.Itoa(target.Port))
+			_, err = subprocess.RunCommandContext(ctxTimeout, "nvme", append([]string{"discover"}, args...)...)
 			if err == nil {
 				break
 			}
@@ -189,7 +248,7 @@ func (n *NVME) Start(ctx context.Context) error {
 			time.Sleep(500 * time.Millisecond)
 		}
 
-		_, err = fmt.Fprintf(f, "--transport=%s --traddr=%s --trsvcid=%d\n", target.Transport, target.Address, target.Port)
+		_, err = fmt.Fprintln(f, strings.Join(args, " "))
 		if err != nil {
 			return err
 		}
@@ -205,6 +264,27 @@ func (n *NVME) Start(ctx context.Context) error {
 		return err
 	}
 
+	// Apply the I/O policy to the subsystems which already existed.
+	return n.applyIOPolicy()
+}
+
+// applyIOPolicy sets the configured I/O policy on all the remote subsystems.
+func (n *NVME) applyIOPolicy() error {
+	if n.state.Services.NVME.Config.IOPolicy == "" {
+		return nil
+	}
+
+	for _, subsystem := range getNVMESubsystems() {
+		if subsystem.IOPolicy == n.state.Services.NVME.Config.IOPolicy {
+			continue
+		}
+
+		err := os.WriteFile(filepath.Join("/sys/class/nvme-subsystem", subsystem.Name, "iopolicy"), []byte(n.state.Services.NVME.Config.IOPolicy), 0o600)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -224,7 +304,7 @@ func (n *NVME) Reset(ctx context.Context) error {
 		return err
 	}
 
-	return nil
+	return n.applyIOPolicy()
 }
 
 // ShouldStart returns true if the service should be started on boot.
@@ -236,3 +316,192 @@ func (n *NVME) ShouldStart() bool {
 func (*NVME) Struct() any {
 	return &api.ServiceNVME{}
 }
+
+// getNVMETargetArgs validates a target and returns the nvme-cli arguments to reach it.
+func getNVMETargetArgs(target api.ServiceNVMETarget) ([]string, error) {
+	if target.Address == "" {
+		return nil, errors.New("NVME target address is required")
+	}
+
+	args := []string{"--transport=" + target.Transport, "--traddr=" + target.Address}
+	if target.Port != 0 {
+		args = append(args, "--trsvcid="+strconv.Itoa(target.Port))
+	}
+
+	switch target.Transport {
+	case api.ServiceNVMETransportTCP:
+		// Bind the connection to the interface.
+		if target.Interface != "" {
+			args = append(args, "--host-iface="+target.Interface)
+		}
+
+	case api.ServiceNVMETransportRDMA:
+		// RDMA connections are bound to a local address rather than an interface.
+		if target.Interface != "" {
+			hostAddress, err := getNVMEHostAddress(target.Interface, target.Address)
+			if err != nil {
+				return nil, err
+			}
+
+			args = append(args, "--host-traddr="+hostAddress)
+		}
+
+	case api.ServiceNVMETransportFC:
+		if target.Interface != "" {
+			return nil, errors.New("NVME fibre channel targets can't be bound to an interface")
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported NVME transport %q", target.Transport)
+	}
+
+	return args, nil
+}
+
+// getNVMEHostAddress returns the address of an interface in the same family as the target address.
+func getNVMEHostAddress(iface string, targetAddress string) (string, error) {
+	targetIP := net.ParseIP(targetAddress)
+	if targetIP == nil {
+		return "", fmt.Errorf("invalid NVME target address %q", targetAddress)
+	}
+
+	netIface, err := net.InterfaceByName(iface)
+	if err != nil {
+		return "", fmt.Errorf("failed to find interface %q: %w", iface, err)
+	}
+
+	addrs, err := netIface.Addrs()
+	if err != nil {
+		return "", err
+	}
+
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+
+		if (ipNet.IP.To4() != nil) == (targetIP.To4() != nil) {
+			return ipNet.IP.String(), nil
+		}
+	}
+
+	return "", fmt.Errorf("interface %q has no address to reach %q", iface, targetAddress)
+}
+
+// getNVMEConnections returns the connections to NVME controllers over a fabric.
+func getNVMEConnections() []api.ServiceNVMEConnection {
+	connections := []api.ServiceNVMEConnection{}
+
+	entries, err := os.ReadDir("/sys/class/nvme")
+	if err != nil {
+		return connections
+	}
+
+	for _, entry := range entries {
+		// Skip local devices.
+		transport := readNVMEAttr(filepath.Join("/sys/class/nvme", entry.Name(), "transport"))
+		if transport == "" || transport == "pcie" {
+			continue
+		}
+
+		connection := api.ServiceNVMEConnection{
+			Name:         entry.Name(),
+			Transport:    transport,
+			SubsystemNQN: readNVMEAttr(filepath.Join("/sys/class/nvme", entry.Name(), "subsysnqn")),
+			State:        readNVMEAttr(filepath.Join("/sys/class/nvme", entry.Name(), "state")),
+		}
+
+		// The address is reported as "traddr=10.0.0.1,trsvcid=4420,src_addr=10.0.0.2".
+		for field := range strings.SplitSeq(readNVMEAttr(filepath.Join("/sys/class/nvme", entry.Name(), "address")), ",") {
+			key, value, _ := strings.Cut(field, "=")
+
+			switch key {
+			case "traddr":
+				connection.Address = value
+			case "trsvcid":
+				connection.Port, _ = strconv.Atoi(value)
+			case "host_traddr", "src_addr":
+				connection.HostAddress = value
+			case "host_iface":
+				connection.HostInterface = value
+			}
+		}
+
+		connections = append(connections, connection)
+	}
+
+	return connections
+}
+
+// getNVMESubsystems returns the remote NVME subsystems, along with the path to each of their
+// namespaces through each controller.
+func getNVMESubsystems() []api.ServiceNVMESubsystem {
+	subsystems := []api.ServiceNVMESubsystem{}
+
+	entries, err := os.ReadDir("/sys/class/nvme-subsystem")
+	if err != nil {
+		return subsystems
+	}
+
+	for _, entry := range entries {
+		subsystemPath := filepath.Join("/sys/class/nvme-subsystem", entry.Name())
+
+		subsystem := api.ServiceNVMESubsystem{
+			Name:     entry.Name(),
+			NQN:      readNVMEAttr(filepath.Join(subsystemPath, "subsysnqn")),
+			IOPolicy: readNVMEAttr(filepath.Join(subsystemPath, "iopolicy")),
+			Paths:    []api.ServiceNVMEPath{},
+		}
+
+		isRemote := false
+
+		controllers, _ := filepath.Glob(filepath.Join(subsystemPath, "nvme[0-9]*"))
+		for _, controllerPath := range controllers {
+			controller := filepath.Base(controllerPath)
+			if !nvmeControllerRegex.MatchString(controller) {
+				continue
+			}
+
+			// Skip local devices.
+			transport := readNVMEAttr(filepath.Join(controllerPath, "transport"))
+			if transport == "" || transport == "pcie" {
+				continue
+			}
+
+			isRemote = true
+
+			// Paths are named "nvme<subsystem>c<controller>n<namespace>".
+			paths, _ := filepath.Glob(filepath.Join(controllerPath, "nvme*c*n*"))
+			for _, path := range paths {
+				match := nvmePathRegex.FindStringSubmatch(filepath.Base(path))
+				if match == nil {
+					continue
+				}
+
+				subsystem.Paths = append(subsystem.Paths, api.ServiceNVMEPath{
+					Name:       match[0],
+					Namespace:  "nvme" + match[1] + "n" + match[3],
+					Controller: controller,
+					ANAState:   readNVMEAttr(filepath.Join(path, "ana_state")),
+				})
+			}
+		}
+
+		if isRemote {
+			subsystems = append(subsystems, subsystem)
+		}
+	}
+
+	return subsystems
+}
+
+// readNVMEAttr returns the content of a sysfs attribute, or an empty string if it can't be read.
+func readNVMEAttr(path string) string {
+	content, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(content))
+}
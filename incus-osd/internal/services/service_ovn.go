@@ -2,12 +2,21 @@ package services
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/lxc/incus/v6/shared/subprocess"
 
 	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/secrets"
 	"github.com/lxc/incus-os/incus-osd/internal/state"
 	"github.com/lxc/incus-os/incus-osd/internal/systemd"
 )
@@ -30,6 +39,18 @@ ExecStart=/usr/bin/ovn-controller unix:/run/openvswitch/db.sock
 Restart=on-failure
 `
 
+var ovnICSystemd = `# Systemd unit generated by IncusOS
+[Unit]
+Description=Open Virtual Network interconnection controller
+
+[Service]
+ExecStart=/usr/bin/ovn-ic %s
+Restart=on-failure
+`
+
+// ovnPKIPath is the directory holding the client certificate issued from the Vault PKI secrets engine.
+var ovnPKIPath = "/var/lib/incus-os/ovn"
+
 // OVN represents the system OVS/OVN service.
 type OVN struct {
 	common
@@ -39,6 +60,22 @@ type OVN struct {
 
 // Get returns the current service state.
 func (n *OVN) Get(_ context.Context) (any, error) {
+	// Retrieve the expiry of the certificates in use.
+	n.state.Services.OVN.State.TLSClientCertificateExpiry = ""
+	n.state.Services.OVN.State.TLSCACertificateExpiry = ""
+
+	if n.state.Services.OVN.Config.Enabled {
+		cert, err := n.readCertificate("/run/ovn/client.crt")
+		if err == nil {
+			n.state.Services.OVN.State.TLSClientCertificateExpiry = cert.NotAfter.UTC().Format(time.RFC3339)
+		}
+
+		ca, err := n.readCertificate("/run/ovn/ca.crt")
+		if err == nil {
+			n.state.Services.OVN.State.TLSCACertificateExpiry = ca.NotAfter.UTC().Format(time.RFC3339)
+		}
+	}
+
 	return n.state.Services.OVN, nil
 }
 
@@ -49,8 +86,44 @@ func (n *OVN) Update(ctx context.Context, req any) error {
 		return fmt.Errorf("request type \"%T\" isn't expected ServiceOVN", req)
 	}
 
+	// Validate the PKI configuration.
+	if newState.Config.TLSPKI != nil {
+		if newState.Config.TLSClientCertificate != "" || newState.Config.TLSClientKey != "" || newState.Config.TLSCACertificate != "" {
+			return errors.New("TLS certificates can't be provided when issued from the PKI")
+		}
+
+		if newState.Config.TLSPKI.Role == "" {
+			return errors.New("a PKI role is required to issue the TLS certificate")
+		}
+
+		if n.state.System.Security.Config.Secrets == nil || n.state.System.Security.Config.Secrets.Vault == nil {
+			return errors.New("issuing the TLS certificate from the PKI requires Vault to be configured")
+		}
+	}
+
+	// Validate the interconnection configuration.
+	if newState.Config.Interconnect != nil {
+		ic := newState.Config.Interconnect
+
+		if ic.AvailabilityZone == "" || ic.NorthboundDatabase == "" || ic.ICNorthboundDatabase == "" || ic.ICSouthboundDatabase == "" {
+			return errors.New("the availability zone, northbound and interconnection databases are required for OVN-IC")
+		}
+
+		if slices.Contains(ic.TransitSwitches, "") {
+			return errors.New("transit switch names can't be empty")
+		}
+	}
+
 	oldState := n.state.Services.OVN
 
+	// Discard the issued certificate if its PKI configuration changed.
+	if !reflect.DeepEqual(oldState.Config.TLSPKI, newState.Config.TLSPKI) {
+		err := os.RemoveAll(ovnPKIPath)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Save the state on return.
 	defer n.state.Save()
 
@@ -74,7 +147,7 @@ func (n *OVN) Update(ctx context.Context, req any) error {
 	}
 
 	// Configure the service.
-	err := n.configure(ctx)
+	err := n.configure(ctx, false)
 	if err != nil {
 		return err
 	}
@@ -82,6 +155,16 @@ func (n *OVN) Update(ctx context.Context, req any) error {
 	return nil
 }
 
+// Reset rotates the TLS client certificate, issuing a new one from the PKI if configured, and
+// restarts the OVN controller.
+func (n *OVN) Reset(ctx context.Context) error {
+	if !n.state.Services.OVN.Config.Enabled {
+		return errors.New("the OVN service isn't enabled")
+	}
+
+	return n.configure(ctx, true)
+}
+
 // Stop stops the service.
 func (n *OVN) Stop(ctx context.Context) error {
 	if !n.state.Services.OVN.Config.Enabled {
@@ -89,7 +172,7 @@ func (n *OVN) Stop(ctx context.Context) error {
 	}
 
 	// Stop OVS and OVN.
-	err := systemd.StopUnit(ctx, "ovn-controller.service", "ovs-vswitchd.service", "ovsdb-server.service")
+	err := systemd.StopUnit(ctx, "ovn-ic.service", "ovn-controller.service", "ovs-vswitchd.service", "ovsdb-server.service")
 	if err != nil {
 		return err
 	}
@@ -110,7 +193,7 @@ func (n *OVN) Start(ctx context.Context) error {
 	}
 
 	// Configure OVS and bring up OVN.
-	return n.configure(ctx)
+	return n.configure(ctx, false)
 }
 
 // ShouldStart returns true if the service should be started on boot.
@@ -123,8 +206,9 @@ func (*OVN) Struct() any {
 	return &api.ServiceOVN{}
 }
 
-// configure takes care of configuring the running OVS and (re)spawning the OVN controller.
-func (n *OVN) configure(ctx context.Context) error {
+// configure takes care of configuring the running OVS and (re)spawning the OVN controller. A new
+// TLS client certificate is issued from the PKI if rotate is set.
+func (n *OVN) configure(ctx context.Context, rotate bool) error {
 	// Apply the OVS configuration.
 	args := []string{"set", "open_vswitch", "."}
 
@@ -145,29 +229,40 @@ func (n *OVN) configure(ctx context.Context) error {
 		return err
 	}
 
-	if n.state.Services.OVN.Config.TLSClientCertificate != "" {
-		err = os.WriteFile("/run/ovn/client.crt", []byte(n.state.Services.OVN.Config.TLSClientCertificate), 0o600)
+	tlsCert := n.state.Services.OVN.Config.TLSClientCertificate
+	tlsKey := n.state.Services.OVN.Config.TLSClientKey
+	tlsCA := n.state.Services.OVN.Config.TLSCACertificate
+
+	if n.state.Services.OVN.Config.TLSPKI != nil {
+		issued, err := n.getIssuedCertificate(ctx, rotate)
 		if err != nil {
 			return err
 		}
+
+		tlsCert = issued.Certificate
+		tlsKey = issued.PrivateKey
+		tlsCA = issued.CA
 	}
 
-	if n.state.Services.OVN.Config.TLSClientKey != "" {
-		err = os.WriteFile("/run/ovn/client.key", []byte(n.state.Services.OVN.Config.TLSClientKey), 0o600)
-		if err != nil {
-			return err
+	for path, value := range map[string]string{"/run/ovn/client.crt": tlsCert, "/run/ovn/client.key": tlsKey, "/run/ovn/ca.crt": tlsCA} {
+		// Don't leave a previous certificate behind.
+		if value == "" {
+			err = os.Remove(path)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+
+			continue
 		}
-	}
 
-	if n.state.Services.OVN.Config.TLSCACertificate != "" {
-		err = os.WriteFile("/run/ovn/ca.crt", []byte(n.state.Services.OVN.Config.TLSCACertificate), 0o600)
+		err = os.WriteFile(path, []byte(value), 0o600)
 		if err != nil {
 			return err
 		}
 	}
 
 	// Generate the systemd unit.
-	if n.state.Services.OVN.Config.TLSClientCertificate != "" {
+	if tlsCert != "" {
 		err = os.WriteFile("/run/systemd/system/ovn-controller.service", []byte(ovnSystemdTLS), 0o600)
 		if err != nil {
 			return err
@@ -190,5 +285,129 @@ func (n *OVN) configure(ctx context.Context) error {
 		return err
 	}
 
-	return nil
+	// Join the interconnection, or leave it.
+	if n.state.Services.OVN.Config.Interconnect == nil {
+		return systemd.StopUnit(ctx, "ovn-ic.service")
+	}
+
+	return n.configureInterconnect(ctx, tlsCert != "")
+}
+
+// configureInterconnect names the local availability zone, creates the missing transit switches and
+// (re)spawns the OVN-IC daemon. Multiple OVN-IC daemons of an availability zone can safely run, only
+// one of them being active at a time.
+func (n *OVN) configureInterconnect(ctx context.Context, useTLS bool) error {
+	ic := n.state.Services.OVN.Config.Interconnect
+
+	tlsArgs := []string{}
+	if useTLS {
+		tlsArgs = append(tlsArgs, "--private-key=/run/ovn/client.key", "--certificate=/run/ovn/client.crt", "--ca-cert=/run/ovn/ca.crt")
+	}
+
+	// Name the local availability zone.
+	args := append([]string{"--db=" + ic.NorthboundDatabase}, tlsArgs...)
+	args = append(args, "set", "NB_Global", ".", "name="+ic.AvailabilityZone)
+
+	_, err := subprocess.RunCommandContext(ctx, "ovn-nbctl", args...)
+	if err != nil {
+		return err
+	}
+
+	// Create the transit switches.
+	for _, ts := range ic.TransitSwitches {
+		args := append([]string{"--db=" + ic.ICNorthboundDatabase}, tlsArgs...)
+		args = append(args, "--may-exist", "ts-add", ts)
+
+		_, err := subprocess.RunCommandContext(ctx, "ovn-ic-nbctl", args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Generate the systemd unit.
+	args = []string{
+		"--ovnnb-db=" + ic.NorthboundDatabase,
+		"--ovnsb-db=" + n.state.Services.OVN.Config.Database,
+		"--ic-nb-db=" + ic.ICNorthboundDatabase,
+		"--ic-sb-db=" + ic.ICSouthboundDatabase,
+	}
+
+	args = append(args, tlsArgs...)
+
+	err = os.WriteFile("/run/systemd/system/ovn-ic.service", fmt.Appendf(nil, ovnICSystemd, strings.Join(args, " ")), 0o600)
+	if err != nil {
+		return err
+	}
+
+	err = systemd.ReloadDaemon(ctx)
+	if err != nil {
+		return err
+	}
+
+	// (Re)start the OVN-IC daemon.
+	return systemd.RestartUnit(ctx, "ovn-ic.service")
+}
+
+// getIssuedCertificate returns the client certificate issued from the PKI, issuing a new one if
+// requested, missing, or once two thirds of its validity have elapsed.
+func (n *OVN) getIssuedCertificate(ctx context.Context, rotate bool) (*secrets.Certificate, error) {
+	if !rotate {
+		cert, err := n.readCertificate(filepath.Join(ovnPKIPath, "client.crt"))
+		if err == nil && time.Until(cert.NotAfter) > cert.NotAfter.Sub(cert.NotBefore)/3 {
+			issued := &secrets.Certificate{}
+
+			for path, value := range map[string]*string{"client.crt": &issued.Certificate, "client.key": &issued.PrivateKey, "ca.crt": &issued.CA} {
+				content, err := os.ReadFile(filepath.Join(ovnPKIPath, path)) //nolint:gosec
+				if err != nil {
+					return nil, err
+				}
+
+				*value = string(content)
+			}
+
+			return issued, nil
+		}
+	}
+
+	pki := n.state.Services.OVN.Config.TLSPKI
+
+	commonName := pki.CommonName
+	if commonName == "" {
+		commonName = n.state.Hostname()
+	}
+
+	issued, err := secrets.IssueCertificate(ctx, n.state.System.Security.Config.Secrets, pki.Mount, pki.Role, commonName, pki.TTL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the certificate across restarts.
+	err = os.MkdirAll(ovnPKIPath, 0o700)
+	if err != nil {
+		return nil, err
+	}
+
+	for path, value := range map[string]string{"client.crt": issued.Certificate, "client.key": issued.PrivateKey, "ca.crt": issued.CA} {
+		err := os.WriteFile(filepath.Join(ovnPKIPath, path), []byte(value), 0o600)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return issued, nil
+}
+
+// readCertificate parses the first certificate of a PEM file.
+func (*OVN) readCertificate(path string) (*x509.Certificate, error) {
+	content, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("no certificate found in %q", path)
+	}
+
+	return x509.ParseCertificate(block.Bytes)
 }
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/redfish"
+	"github.com/lxc/incus-os/incus-osd/internal/secrets"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+var redfishNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// redfishResetTypes lists the power actions which may be triggered through a Redfish endpoint.
+var redfishResetTypes = []string{"On", "ForceOff", "GracefulShutdown", "GracefulRestart", "ForceRestart", "ForceOn", "PowerCycle", "Nmi"}
+
+// Redfish represents the system Redfish service.
+type Redfish struct {
+	common
+
+	state *state.State
+}
+
+// Get returns the current service state.
+func (n *Redfish) Get(ctx context.Context) (any, error) {
+	// Initialize the endpoint list if missing.
+	if n.state.Services.Redfish.Config.Endpoints == nil {
+		n.state.Services.Redfish.Config.Endpoints = []api.ServiceRedfishEndpoint{}
+	}
+
+	// Query each endpoint, reporting failures without failing the whole request.
+	endpoints := make([]api.ServiceRedfishEndpointState, 0, len(n.state.Services.Redfish.Config.Endpoints))
+
+	if n.state.Services.Redfish.Config.Enabled {
+		for _, endpoint := range n.state.Services.Redfish.Config.Endpoints {
+			endpointState, err := n.getEndpointState(ctx, endpoint)
+			if err != nil {
+				endpointState.Error = err.Error()
+			}
+
+			endpointState.Name = endpoint.Name
+
+			endpoints = append(endpoints, endpointState)
+		}
+	}
+
+	n.state.Services.Redfish.State.Endpoints = endpoints
+
+	return n.state.Services.Redfish, nil
+}
+
+// Update updates the service configuration.
+func (n *Redfish) Update(ctx context.Context, req any) error {
+	newState, ok := req.(*api.ServiceRedfish)
+	if !ok {
+		return fmt.Errorf("request type \"%T\" isn't expected ServiceRedfish", req)
+	}
+
+	if newState.Config.Enabled {
+		err := n.validate(newState.Config)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Save the state on return.
+	defer n.state.Save()
+
+	// Disable the service if requested.
+	if n.state.Services.Redfish.Config.Enabled && !newState.Config.Enabled {
+		err := n.Stop(ctx)
+		if err != nil {
+			return err
+		}
+
+		n.state.Services.Redfish.Config = newState.Config
+
+		return nil
+	}
+
+	// Update the configuration.
+	n.state.Services.Redfish.Config = newState.Config
+
+	// Enable or reconfigure the service if requested.
+	return n.Start(ctx)
+}
+
+// Stop stops the service. The Redfish service only runs requests on demand.
+func (*Redfish) Stop(_ context.Context) error {
+	return nil
+}
+
+// Start starts the service. The Redfish service only runs requests on demand.
+func (*Redfish) Start(_ context.Context) error {
+	return nil
+}
+
+// ShouldStart returns true if the service should be started on boot.
+func (n *Redfish) ShouldStart() bool {
+	return n.state.Services.Redfish.Config.Enabled
+}
+
+// Struct returns the API struct for the Redfish service.
+func (*Redfish) Struct() any {
+	return &api.ServiceRedfish{}
+}
+
+// Power triggers a power action on the system managed by a Redfish endpoint.
+func (n *Redfish) Power(ctx context.Context, endpoint string, action string) error {
+	if !slices.Contains(redfishResetTypes, action) {
+		return fmt.Errorf("invalid power action %q, must be one of %s", action, strings.Join(redfishResetTypes, ", "))
+	}
+
+	client, err := n.getClient(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+
+	return client.Reset(ctx, action)
+}
+
+// InsertMedia inserts an image in the virtual CD or DVD drive of a Redfish endpoint, optionally
+// booting from it once.
+func (n *Redfish) InsertMedia(ctx context.Context, endpoint string, image string, boot bool) error {
+	imageURL, err := url.Parse(image)
+	if err != nil || (imageURL.Scheme != "http" && imageURL.Scheme != "https") || imageURL.Host == "" {
+		return fmt.Errorf("invalid image URL %q", image)
+	}
+
+	client, err := n.getClient(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+
+	return client.InsertMedia(ctx, image, boot)
+}
+
+// EjectMedia ejects the virtual media images of a Redfish endpoint.
+func (n *Redfish) EjectMedia(ctx context.Context, endpoint string) error {
+	client, err := n.getClient(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+
+	return client.EjectMedia(ctx)
+}
+
+// getClient returns a client for the named endpoint, resolving its password.
+func (n *Redfish) getClient(ctx context.Context, name string) (*redfish.Client, error) {
+	if !n.state.Services.Redfish.Config.Enabled {
+		return nil, errors.New("the Redfish service isn't enabled")
+	}
+
+	for _, endpoint := range n.state.Services.Redfish.Config.Endpoints {
+		if endpoint.Name != name {
+			continue
+		}
+
+		password, err := secrets.Resolve(ctx, n.state.System.Security.Config.Secrets, endpoint.Password)
+		if err != nil {
+			return nil, err
+		}
+
+		endpoint.Password = password
+
+		return redfish.NewClient(endpoint)
+	}
+
+	return nil, fmt.Errorf("unknown Redfish endpoint %q", name)
+}
+
+// getEndpointState queries the system managed by an endpoint, bounding the time spent on unreachable BMCs.
+func (n *Redfish) getEndpointState(ctx context.Context, endpoint api.ServiceRedfishEndpoint) (api.ServiceRedfishEndpointState, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	client, err := n.getClient(ctx, endpoint.Name)
+	if err != nil {
+		return api.ServiceRedfishEndpointState{}, err
+	}
+
+	return client.GetState(ctx)
+}
+
+// validate checks that the Redfish configuration is usable.
+func (n *Redfish) validate(cfg api.ServiceRedfishConfig) error {
+	names := []string{}
+
+	for _, endpoint := range cfg.Endpoints {
+		if !redfishNameRegex.MatchString(endpoint.Name) || slices.Contains(names, endpoint.Name) {
+			return fmt.Errorf("invalid or duplicate Redfish endpoint name %q", endpoint.Name)
+		}
+
+		names = append(names, endpoint.Name)
+
+		address, err := url.Parse(endpoint.Address)
+		if err != nil || address.Scheme != "https" || address.Host == "" {
+			return fmt.Errorf("invalid address %q for Redfish endpoint %q, must be an HTTPS URL", endpoint.Address, endpoint.Name)
+		}
+
+		if endpoint.Username == "" || endpoint.Password == "" {
+			return fmt.Errorf("a username and password are required for Redfish endpoint %q", endpoint.Name)
+		}
+
+		err = secrets.ValidateReference(n.state.System.Security.Config.Secrets, endpoint.Password)
+		if err != nil {
+			return err
+		}
+
+		if endpoint.TLSFingerprint != "" {
+			fingerprint, err := hex.DecodeString(strings.ReplaceAll(endpoint.TLSFingerprint, ":", ""))
+			if err != nil || len(fingerprint) != 32 {
+				return fmt.Errorf("invalid TLS fingerprint for Redfish endpoint %q, must be a SHA256 fingerprint", endpoint.Name)
+			}
+		}
+
+		// Validate the TLS configuration by building a client.
+		_, err = redfish.NewClient(endpoint)
+		if err != nil {
+			return fmt.Errorf("invalid TLS configuration for Redfish endpoint %q: %w", endpoint.Name, err)
+		}
+	}
+
+	return nil
+}
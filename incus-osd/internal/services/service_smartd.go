@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+	"github.com/lxc/incus-os/incus-osd/internal/systemd"
+)
+
+// smartdPath holds the alert hook run by smartd.
+var smartdPath = "/run/incus-os/smartd"
+
+// smartdAlertsPath records the most recent alerts, one per line.
+var smartdAlertsPath = "/var/lib/incus-os/smartd/alerts"
+
+// smartdNotify records each alert, logs it to the journal and posts it to the webhook, if any.
+var smartdNotify = `#!/bin/sh
+# Generated by IncusOS
+ALERTS="` + smartdAlertsPath + `"
+WEBHOOK="` + smartdPath + `/webhook"
+NOW="$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+
+printf '%s\t%s\t%s\t%s\n' "${NOW}" "${SMARTD_DEVICE}" "${SMARTD_FAILTYPE}" "$(echo "${SMARTD_MESSAGE}" | tr '\t\n' '  ')" >> "${ALERTS}"
+tail -n 100 "${ALERTS}" > "${ALERTS}.tmp" && mv "${ALERTS}.tmp" "${ALERTS}"
+
+logger -p daemon.crit -t smartd-alert "${SMARTD_DEVICE}: ${SMARTD_MESSAGE}"
+
+[ -e "${WEBHOOK}" ] || exit 0
+
+esc() {
+    printf '%s' "$1" | tr '\t\n' '  ' | sed 's/\\/\\\\/g; s/"/\\"/g'
+}
+
+printf '{"time":"%s","hostname":"%s","device":"%s","type":"%s","message":"%s"}' \
+    "${NOW}" "$(esc "$(hostname)")" "$(esc "${SMARTD_DEVICE}")" "$(esc "${SMARTD_FAILTYPE}")" "$(esc "${SMARTD_MESSAGE}")" | \
+    curl --silent --max-time 30 -X POST -H "Content-Type: application/json" --data-binary @- "$(cat "${WEBHOOK}")" > /dev/null
+`
+
+var smartdScheduleRegex = regexp.MustCompile(`^[SLCO0-9/.|()\[\]+*-]+$`)
+
+// SMARTD represents the system smartd service.
+type SMARTD struct {
+	common
+
+	state *state.State
+}
+
+// Get returns the current service state.
+func (n *SMARTD) Get(_ context.Context) (any, error) {
+	// Initialize the device list if missing.
+	if n.state.Services.SMARTD.Config.Devices == nil {
+		n.state.Services.SMARTD.Config.Devices = []string{}
+	}
+
+	// Retrieve the recorded alerts.
+	n.state.Services.SMARTD.State.Alerts = []api.ServiceSMARTDAlert{}
+
+	content, err := os.ReadFile(smartdAlertsPath)
+	if err == nil {
+		for line := range strings.SplitSeq(strings.TrimSpace(string(content)), "\n") {
+			fields := strings.SplitN(line, "\t", 4)
+			if len(fields) != 4 {
+				continue
+			}
+
+			alertTime, err := time.Parse(time.RFC3339, fields[0])
+			if err != nil {
+				continue
+			}
+
+			n.state.Services.SMARTD.State.Alerts = append(n.state.Services.SMARTD.State.Alerts, api.ServiceSMARTDAlert{
+				Time:    alertTime,
+				Device:  fields[1],
+				Type:    fields[2],
+				Message: fields[3],
+			})
+		}
+	}
+
+	return n.state.Services.SMARTD, nil
+}
+
+// Update updates the service configuration.
+func (n *SMARTD) Update(ctx context.Context, req any) error {
+	newState, ok := req.(*api.ServiceSMARTD)
+	if !ok {
+		return fmt.Errorf("request type \"%T\" isn't expected ServiceSMARTD", req)
+	}
+
+	if newState.Config.Enabled {
+		err := n.validate(newState.Config)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Save the state on return.
+	defer n.state.Save()
+
+	// Disable the service if requested.
+	if n.state.Services.SMARTD.Config.Enabled && !newState.Config.Enabled {
+		err := n.Stop(ctx)
+		if err != nil {
+			return err
+		}
+
+		n.state.Services.SMARTD.Config = newState.Config
+
+		return nil
+	}
+
+	// Update the configuration.
+	n.state.Services.SMARTD.Config = newState.Config
+
+	// Enable or reconfigure the service if requested.
+	return n.Start(ctx)
+}
+
+// Stop stops the service.
+func (n *SMARTD) Stop(ctx context.Context) error {
+	if !n.state.Services.SMARTD.Config.Enabled {
+		return nil
+	}
+
+	// Stop smartd.
+	err := systemd.StopUnit(ctx, "smartmontools.service")
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(smartdPath)
+}
+
+// Start starts the service.
+func (n *SMARTD) Start(ctx context.Context) error {
+	config := n.state.Services.SMARTD.Config
+
+	if !config.Enabled {
+		return nil
+	}
+
+	// Write the alert hook.
+	for _, path := range []string{smartdPath, filepath.Dir(smartdAlertsPath)} {
+		err := os.MkdirAll(path, 0o700)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := os.WriteFile(filepath.Join(smartdPath, "notify.sh"), []byte(smartdNotify), 0o700) //nolint:gosec
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filepath.Join(smartdPath, "webhook"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if config.WebhookURL != "" {
+		err = os.WriteFile(filepath.Join(smartdPath, "webhook"), []byte(config.WebhookURL), 0o600)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Generate the configuration, skipping drives in standby to let them spin down.
+	directives := []string{"-a", "-n", "standby,q"}
+
+	if config.SelfTestSchedule != "" {
+		directives = append(directives, "-s", config.SelfTestSchedule)
+	}
+
+	if config.TemperatureWarning != 0 || config.TemperatureCritical != 0 {
+		directives = append(directives, "-W", fmt.Sprintf("0,%d,%d", config.TemperatureWarning, config.TemperatureCritical))
+	}
+
+	directives = append(directives, "-m", "<nomailer>", "-M", "exec", filepath.Join(smartdPath, "notify.sh"))
+
+	devices := config.Devices
+	if len(devices) == 0 {
+		devices = []string{"DEVICESCAN"}
+	}
+
+	var conf strings.Builder
+
+	_, _ = conf.WriteString("# Generated by IncusOS\n")
+
+	for _, device := range devices {
+		_, _ = fmt.Fprintf(&conf, "%s %s\n", device, strings.Join(directives, " "))
+	}
+
+	err = os.WriteFile("/etc/smartd.conf", []byte(conf.String()), 0o644)
+	if err != nil {
+		return err
+	}
+
+	// (Re)start smartd to pick up the configuration.
+	err = systemd.RestartUnit(ctx, "smartmontools.service")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ShouldStart returns true if the service should be started on boot.
+func (n *SMARTD) ShouldStart() bool {
+	return n.state.Services.SMARTD.Config.Enabled
+}
+
+// Struct returns the API struct for the smartd service.
+func (*SMARTD) Struct() any {
+	return &api.ServiceSMARTD{}
+}
+
+// validate checks that the smartd configuration is usable.
+func (*SMARTD) validate(cfg api.ServiceSMARTDConfig) error {
+	for _, device := range cfg.Devices {
+		if !strings.HasPrefix(device, "/dev/") || strings.ContainsAny(device, " \t\n#") {
+			return fmt.Errorf("invalid device %q", device)
+		}
+	}
+
+	if cfg.SelfTestSchedule != "" {
+		if !smartdScheduleRegex.MatchString(cfg.SelfTestSchedule) {
+			return fmt.Errorf("invalid self-test schedule %q", cfg.SelfTestSchedule)
+		}
+
+		_, err := regexp.Compile(cfg.SelfTestSchedule)
+		if err != nil {
+			return fmt.Errorf("invalid self-test schedule %q: %w", cfg.SelfTestSchedule, err)
+		}
+	}
+
+	if cfg.TemperatureWarning < 0 || cfg.TemperatureWarning > 255 || cfg.TemperatureCritical < 0 || cfg.TemperatureCritical > 255 {
+		return errors.New("invalid temperature limits")
+	}
+
+	if cfg.TemperatureWarning != 0 && cfg.TemperatureCritical != 0 && cfg.TemperatureWarning >= cfg.TemperatureCritical {
+		return errors.New("the warning temperature must be lower than the critical temperature")
+	}
+
+	if cfg.WebhookURL != "" {
+		webhookURL, err := url.Parse(cfg.WebhookURL)
+		if err != nil || (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") || webhookURL.Host == "" || strings.ContainsAny(cfg.WebhookURL, " \t\n#") {
+			return fmt.Errorf("invalid webhook URL %q", cfg.WebhookURL)
+		}
+	}
+
+	return nil
+}
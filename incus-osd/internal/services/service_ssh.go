@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/nftables"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+	"github.com/lxc/incus-os/incus-osd/internal/systemd"
+)
+
+const (
+	sshStatePath   = "/var/lib/incus-os/ssh"
+	sshRuntimePath = "/run/incus-os/ssh"
+)
+
+// SSH represents the system SSH service, providing emergency access to the host.
+type SSH struct {
+	common
+
+	state *state.State
+}
+
+// Get returns the current service state.
+func (n *SSH) Get(ctx context.Context) (any, error) {
+	// Initialize key list if missing.
+	if n.state.Services.SSH.Config.AuthorizedKeys == nil {
+		n.state.Services.SSH.Config.AuthorizedKeys = []string{}
+	}
+
+	n.state.Services.SSH.State.HostKeyFingerprint = ""
+
+	if n.state.Services.SSH.Config.Enabled {
+		output, err := subprocess.RunCommandContext(ctx, "ssh-keygen", "-l", "-f", filepath.Join(sshStatePath, "ssh_host_ed25519_key.pub"))
+		if err == nil {
+			n.state.Services.SSH.State.HostKeyFingerprint = strings.TrimSpace(output)
+		}
+	}
+
+	return n.state.Services.SSH, nil
+}
+
+// Update updates the service configuration.
+func (n *SSH) Update(ctx context.Context, req any) error {
+	newState, ok := req.(*api.ServiceSSH)
+	if !ok {
+		return fmt.Errorf("request type \"%T\" isn't expected ServiceSSH", req)
+	}
+
+	// Validate the configuration.
+	if newState.Config.Enabled && len(newState.Config.AuthorizedKeys) == 0 {
+		return errors.New("at least one authorized key must be provided")
+	}
+
+	if newState.Config.Port < 0 || newState.Config.Port > 65535 {
+		return fmt.Errorf("invalid port %d", newState.Config.Port)
+	}
+
+	for _, key := range newState.Config.AuthorizedKeys {
+		_, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key))
+		if err != nil {
+			return fmt.Errorf("invalid authorized key %q: %w", key, err)
+		}
+	}
+
+	// Save the state on return.
+	defer n.state.Save()
+
+	// Stop the service, lifting any restriction.
+	err := n.Stop(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Apply the new configuration.
+	n.state.Services.SSH.Config = newState.Config
+
+	// Start the service.
+	err = n.Start(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Stop stops the service.
+func (n *SSH) Stop(ctx context.Context) error {
+	if !n.state.Services.SSH.Config.Enabled {
+		return nil
+	}
+
+	// Stop the SSH daemon.
+	err := systemd.StopUnit(ctx, "incus-osd-ssh.service")
+	if err != nil {
+		return err
+	}
+
+	// Lift any source restriction.
+	err = nftables.ApplySSHRestriction(ctx, n.getPort(), nil)
+	if err != nil {
+		return err
+	}
+
+	// Remove the runtime configuration, including any decrypted host key.
+	err = os.RemoveAll(sshRuntimePath)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Start starts the service.
+func (n *SSH) Start(ctx context.Context) error {
+	if !n.state.Services.SSH.Config.Enabled {
+		return nil
+	}
+
+	// Restrict the sources before exposing the service.
+	err := nftables.ApplySSHRestriction(ctx, n.getPort(), n.state.Services.SSH.Config.AllowedSources)
+	if err != nil {
+		return err
+	}
+
+	err = n.writeConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Ensure the service is running.
+	err = systemd.StartUnit(ctx, "incus-osd-ssh.service")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ShouldStart returns true if the service should be started on boot.
+func (n *SSH) ShouldStart() bool {
+	return n.state.Services.SSH.Config.Enabled
+}
+
+// Struct returns the API struct for the SSH service.
+func (*SSH) Struct() any {
+	return &api.ServiceSSH{}
+}
+
+// getPort returns the port the SSH daemon listens on.
+func (n *SSH) getPort() int {
+	if n.state.Services.SSH.Config.Port == 0 {
+		return 22
+	}
+
+	return n.state.Services.SSH.Config.Port
+}
+
+// writeConfig writes the SSH daemon configuration, authorized keys and host key to the runtime directory.
+func (n *SSH) writeConfig(ctx context.Context) error {
+	err := os.MkdirAll(sshRuntimePath, 0o700)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(sshStatePath, 0o700)
+	if err != nil {
+		return err
+	}
+
+	// Get the host key.
+	hostKeyPath, err := n.setupHostKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Write the authorized keys.
+	authorizedKeysPath := filepath.Join(sshRuntimePath, "authorized_keys")
+
+	err = os.WriteFile(authorizedKeysPath, []byte(strings.Join(n.state.Services.SSH.Config.AuthorizedKeys, "\n")+"\n"), 0o600)
+	if err != nil {
+		return err
+	}
+
+	// Write the configuration, only allowing public key authentication as root and logging the key
+	// fingerprint of each login.
+	cfg := fmt.Sprintf(`Port %d
+HostKey %s
+AuthorizedKeysFile %s
+PermitRootLogin prohibit-password
+PubkeyAuthentication yes
+PasswordAuthentication no
+KbdInteractiveAuthentication no
+UsePAM no
+AllowAgentForwarding no
+AllowTcpForwarding no
+X11Forwarding no
+LogLevel VERBOSE
+`, n.getPort(), hostKeyPath, authorizedKeysPath)
+
+	return os.WriteFile(filepath.Join(sshRuntimePath, "sshd_config"), []byte(cfg), 0o600)
+}
+
+// setupHostKey generates the host key if missing, moving it to or from TPM-sealed storage as
+// configured, and returns the path of the usable host key.
+func (n *SSH) setupHostKey(ctx context.Context) (string, error) {
+	plainPath := filepath.Join(sshStatePath, "ssh_host_ed25519_key")
+	sealedPath := plainPath + ".cred"
+	runtimePath := filepath.Join(sshRuntimePath, "ssh_host_ed25519_key")
+
+	_, err := os.Stat(plainPath)
+	hasPlain := err == nil
+
+	_, err = os.Stat(sealedPath)
+	hasSealed := err == nil
+
+	// Generate a new host key.
+	if !hasPlain && !hasSealed {
+		_, err := subprocess.RunCommandContext(ctx, "ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "", "-f", plainPath)
+		if err != nil {
+			return "", err
+		}
+
+		hasPlain = true
+	}
+
+	if !n.state.Services.SSH.Config.TPMHostKeys {
+		// Move a sealed key back to plain storage.
+		if hasSealed {
+			_, err := subprocess.RunCommandContext(ctx, "systemd-creds", "decrypt", "--name=ssh-host-key", sealedPath, plainPath)
+			if err != nil {
+				return "", err
+			}
+
+			err = os.Remove(sealedPath)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		return plainPath, nil
+	}
+
+	// Seal a plain key with the TPM.
+	if hasPlain {
+		_, err := subprocess.RunCommandContext(ctx, "systemd-creds", "encrypt", "--with-key=tpm2", "--name=ssh-host-key", plainPath, sealedPath)
+		if err != nil {
+			return "", err
+		}
+
+		err = os.Remove(plainPath)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// Unseal the key to the runtime directory, which isn't persisted.
+	_, err = subprocess.RunCommandContext(ctx, "systemd-creds", "decrypt", "--name=ssh-host-key", sealedPath, runtimePath)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.Chmod(runtimePath, 0o600)
+	if err != nil {
+		return "", err
+	}
+
+	return runtimePath, nil
+}
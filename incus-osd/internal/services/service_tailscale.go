@@ -10,6 +10,8 @@ import (
 	"github.com/lxc/incus/v6/shared/subprocess"
 
 	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/nftables"
+	"github.com/lxc/incus-os/incus-osd/internal/secrets"
 	"github.com/lxc/incus-os/incus-osd/internal/state"
 	"github.com/lxc/incus-os/incus-osd/internal/systemd"
 )
@@ -33,6 +35,16 @@ func (n *Tailscale) Update(ctx context.Context, req any) error {
 		return fmt.Errorf("request type \"%T\" isn't expected ServiceTailscale", req)
 	}
 
+	// The authentication key may reference a secret.
+	err := secrets.ValidateReference(n.state.System.Security.Config.Secrets, newState.Config.AuthKey)
+	if err != nil {
+		return err
+	}
+
+	if newState.Config.APIPort < 0 || newState.Config.APIPort > 65535 {
+		return fmt.Errorf("invalid API port %d", newState.Config.APIPort)
+	}
+
 	// Save the state on return.
 	defer n.state.Save()
 
@@ -81,6 +93,12 @@ func (n *Tailscale) Stop(ctx context.Context) error {
 		return err
 	}
 
+	// Lift any API restriction.
+	err = nftables.ApplyAPIRestriction(ctx, "", n.getAPIPort())
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -96,6 +114,17 @@ func (n *Tailscale) Start(ctx context.Context) error {
 		return err
 	}
 
+	// Only allow access to the API over the tailnet if requested.
+	restrictedInterface := ""
+	if n.state.Services.Tailscale.Config.RestrictAPI {
+		restrictedInterface = "tailscale0"
+	}
+
+	err = nftables.ApplyAPIRestriction(ctx, restrictedInterface, n.getAPIPort())
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -118,8 +147,13 @@ func (n *Tailscale) configure(ctx context.Context, needsRejoin bool) error {
 			return err
 		}
 
-		// Join with the provided key and login server.
-		args := []string{"up", "--auth-key", n.state.Services.Tailscale.Config.AuthKey}
+		// Join with the provided key, which may reference a secret, and login server.
+		authKey, err := secrets.Resolve(ctx, n.state.System.Security.Config.Secrets, n.state.Services.Tailscale.Config.AuthKey)
+		if err != nil {
+			return err
+		}
+
+		args := []string{"up", "--auth-key", authKey}
 		if n.state.Services.Tailscale.Config.LoginServer != "" {
 			args = append(args, "--login-server", n.state.Services.Tailscale.Config.LoginServer)
 		}
@@ -145,7 +179,7 @@ func (n *Tailscale) configure(ctx context.Context, needsRejoin bool) error {
 		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 		defer cancel()
 
-		_, err = subprocess.RunCommandContext(ctx, "tailscale", "serve", "--bg", "--https="+strconv.Itoa(int(n.state.Services.Tailscale.Config.ServePort)), "https+insecure://localhost:8443")
+		_, err = subprocess.RunCommandContext(ctx, "tailscale", "serve", "--bg", "--https="+strconv.Itoa(int(n.state.Services.Tailscale.Config.ServePort)), "https+insecure://localhost:"+strconv.Itoa(n.getAPIPort()))
 		if err != nil {
 			return err
 		}
@@ -153,3 +187,12 @@ func (n *Tailscale) configure(ctx context.Context, needsRejoin bool) error {
 
 	return nil
 }
+
+// getAPIPort returns the port of the primary application's API.
+func (n *Tailscale) getAPIPort() int {
+	if n.state.Services.Tailscale.Config.APIPort == 0 {
+		return 8443
+	}
+
+	return n.state.Services.Tailscale.Config.APIPort
+}
@@ -2,15 +2,30 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/lxc/incus/v6/shared/subprocess"
 
 	"github.com/lxc/incus-os/incus-osd/api"
 	"github.com/lxc/incus-os/incus-osd/internal/state"
+	"github.com/lxc/incus-os/incus-osd/internal/systemd"
+	"github.com/lxc/incus-os/incus-osd/internal/usb"
 )
 
+var usbipdSystemd = `# Systemd unit generated by IncusOS
+[Unit]
+Description=USB/IP server
+
+[Service]
+ExecStart=/usr/sbin/usbipd
+Restart=on-failure
+`
+
 // USBIP represents the system USBIP service.
 type USBIP struct {
 	common
@@ -25,6 +40,14 @@ func (n *USBIP) Get(_ context.Context) (any, error) {
 		n.state.Services.USBIP.Config.Targets = []api.ServiceUSBIPTarget{}
 	}
 
+	// Retrieve the status of the exported devices.
+	exports, err := n.getExports()
+	if err != nil {
+		return nil, err
+	}
+
+	n.state.Services.USBIP.State.Exports = exports
+
 	return n.state.Services.USBIP, nil
 }
 
@@ -35,23 +58,49 @@ func (n *USBIP) Update(ctx context.Context, req any) error {
 		return fmt.Errorf("request type \"%T\" isn't expected ServiceUSBIP", req)
 	}
 
+	for _, export := range newState.Config.Exports {
+		if export.Device == "" && export.Serial == "" && export.BusID == "" {
+			return errors.New("exported USB devices must be matched by device, serial or bus ID")
+		}
+
+		if export.Device != "" {
+			err := usb.ValidateDeviceID(export.Device)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	// Save the state on return.
 	defer n.state.Save()
 
 	// Update the configuration.
 	n.state.Services.USBIP.Config = newState.Config
 
-	// Attach the devices.
+	// Attach and export the devices.
 	err := n.Start(ctx)
 	if err != nil {
 		return err
 	}
 
+	// Stop exporting devices if none are allowed anymore.
+	if len(n.state.Services.USBIP.Config.Exports) == 0 {
+		return n.applyExports(ctx)
+	}
+
 	return nil
 }
 
 // Start starts the service.
 func (n *USBIP) Start(ctx context.Context) error {
+	// Export the allowed local devices.
+	if len(n.state.Services.USBIP.Config.Exports) > 0 {
+		err := n.applyExports(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
 	// If nothing to be attached, we're done.
 	if len(n.state.Services.USBIP.Config.Targets) == 0 {
 		return nil
@@ -77,10 +126,157 @@ func (n *USBIP) Start(ctx context.Context) error {
 
 // ShouldStart returns true if the service should be started on boot.
 func (n *USBIP) ShouldStart() bool {
-	return len(n.state.Services.USBIP.Config.Targets) > 0
+	return len(n.state.Services.USBIP.Config.Targets) > 0 || len(n.state.Services.USBIP.Config.Exports) > 0
 }
 
 // Struct returns the API struct for the USBIP service.
 func (*USBIP) Struct() any {
 	return &api.ServiceUSBIP{}
 }
+
+// applyExports binds the local devices matching the exports allowlist to the USBIP host driver,
+// making them available through the USBIP daemon, and releases any other device. The device IncusOS
+// is running from is never exported.
+func (n *USBIP) applyExports(ctx context.Context) error {
+	exports := n.state.Services.USBIP.Config.Exports
+
+	if len(exports) > 0 {
+		// Load the kernel module.
+		_, err := subprocess.RunCommandContext(ctx, "modprobe", "usbip-host")
+		if err != nil {
+			return err
+		}
+
+		// Start the daemon.
+		err = os.WriteFile("/run/systemd/system/usbipd.service", []byte(usbipdSystemd), 0o600)
+		if err != nil {
+			return err
+		}
+
+		err = systemd.ReloadDaemon(ctx)
+		if err != nil {
+			return err
+		}
+
+		err = systemd.StartUnit(ctx, "usbipd.service")
+		if err != nil {
+			return err
+		}
+	}
+
+	devices, err := usb.ListDevices()
+	if err != nil {
+		return err
+	}
+
+	systemDevice := ""
+	if len(exports) > 0 {
+		systemDevice = usb.GetSystemDevice(ctx)
+	}
+
+	for _, device := range devices {
+		exported := device.ID != systemDevice && n.isExported(device)
+		bound := n.isBound(device.ID)
+
+		if exported && !bound {
+			_, err := subprocess.RunCommandContext(ctx, "usbip", "bind", "-b", device.ID)
+			if err != nil {
+				slog.WarnContext(ctx, "Unable to export USB device", "busid", device.ID, "err", err)
+			}
+		} else if !exported && bound {
+			_, err := subprocess.RunCommandContext(ctx, "usbip", "unbind", "-b", device.ID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Stop the daemon once nothing is exported.
+	if len(exports) == 0 {
+		_, err := os.Stat("/run/systemd/system/usbipd.service")
+		if err == nil {
+			return systemd.StopUnit(ctx, "usbipd.service")
+		}
+	}
+
+	return nil
+}
+
+// getExports returns the status of the local devices matching the exports allowlist.
+func (n *USBIP) getExports() ([]api.ServiceUSBIPExportState, error) {
+	ret := []api.ServiceUSBIPExportState{}
+
+	if len(n.state.Services.USBIP.Config.Exports) == 0 {
+		return ret, nil
+	}
+
+	devices, err := usb.ListDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, device := range devices {
+		if !n.isExported(device) {
+			continue
+		}
+
+		export := api.ServiceUSBIPExportState{
+			BusID:     device.ID,
+			VendorID:  device.VendorID,
+			ProductID: device.ProductID,
+			Product:   device.Product,
+			Serial:    device.Serial,
+			Status:    "error",
+		}
+
+		if !n.isBound(device.ID) {
+			export.Error = "device isn't bound to the USBIP host driver"
+			ret = append(ret, export)
+
+			continue
+		}
+
+		// The status is reported by the USBIP host driver.
+		status, err := os.ReadFile(filepath.Join(usb.SysfsPath, device.ID, "usbip_status")) //nolint:gosec
+		if err == nil {
+			switch strings.TrimSpace(string(status)) {
+			case "1":
+				export.Status = "available"
+			case "2":
+				export.Status = "in-use"
+			}
+		}
+
+		ret = append(ret, export)
+	}
+
+	return ret, nil
+}
+
+// isExported returns whether a local device matches an entry of the exports allowlist.
+func (n *USBIP) isExported(device api.SystemSecurityUSBDevice) bool {
+	for _, export := range n.state.Services.USBIP.Config.Exports {
+		if export.Device != "" && export.Device != device.VendorID+":"+device.ProductID {
+			continue
+		}
+
+		if export.Serial != "" && export.Serial != device.Serial {
+			continue
+		}
+
+		if export.BusID != "" && export.BusID != device.ID {
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// isBound returns whether a local device is bound to the USBIP host driver.
+func (*USBIP) isBound(busID string) bool {
+	_, err := os.Stat(filepath.Join("/sys/bus/usb/drivers/usbip-host", busID))
+
+	return err == nil
+}
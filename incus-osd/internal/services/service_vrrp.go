@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/secrets"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+	"github.com/lxc/incus-os/incus-osd/internal/systemd"
+)
+
+// vrrpStatePath holds the current state of each VRRP instance, as reported by keepalived.
+var vrrpStatePath = "/run/incus-os/vrrp"
+
+// vrrpNotify records the state transitions of the VRRP instances.
+var vrrpNotify = `#!/bin/sh
+# Generated by IncusOS
+mkdir -p ` + vrrpStatePath + `
+echo "$3" > "` + vrrpStatePath + `/$2"
+`
+
+var vrrpNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// VRRP represents the system VRRP service.
+type VRRP struct {
+	common
+
+	state *state.State
+}
+
+// Get returns the current service state.
+func (n *VRRP) Get(_ context.Context) (any, error) {
+	// Initialize the instance list if missing.
+	if n.state.Services.VRRP.Config.Instances == nil {
+		n.state.Services.VRRP.Config.Instances = []api.ServiceVRRPInstance{}
+	}
+
+	// Retrieve the state of each instance.
+	instances := make([]api.ServiceVRRPInstanceState, 0, len(n.state.Services.VRRP.Config.Instances))
+
+	if n.state.Services.VRRP.Config.Enabled {
+		for _, instance := range n.state.Services.VRRP.Config.Instances {
+			instanceState := "UNKNOWN"
+
+			content, err := os.ReadFile(filepath.Join(vrrpStatePath, instance.Name)) //nolint:gosec
+			if err == nil && strings.TrimSpace(string(content)) != "" {
+				instanceState = strings.TrimSpace(string(content))
+			}
+
+			instances = append(instances, api.ServiceVRRPInstanceState{Name: instance.Name, State: instanceState})
+		}
+	}
+
+	n.state.Services.VRRP.State.Instances = instances
+
+	return n.state.Services.VRRP, nil
+}
+
+// Update updates the service configuration.
+func (n *VRRP) Update(ctx context.Context, req any) error {
+	newState, ok := req.(*api.ServiceVRRP)
+	if !ok {
+		return fmt.Errorf("request type \"%T\" isn't expected ServiceVRRP", req)
+	}
+
+	if newState.Config.Enabled {
+		err := n.validate(newState.Config)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Save the state on return.
+	defer n.state.Save()
+
+	// Disable the service if requested.
+	if n.state.Services.VRRP.Config.Enabled && !newState.Config.Enabled {
+		err := n.Stop(ctx)
+		if err != nil {
+			return err
+		}
+
+		n.state.Services.VRRP.Config = newState.Config
+
+		return nil
+	}
+
+	// Update the configuration.
+	n.state.Services.VRRP.Config = newState.Config
+
+	// Enable or reconfigure the service if requested.
+	return n.Start(ctx)
+}
+
+// Stop stops the service.
+func (n *VRRP) Stop(ctx context.Context) error {
+	if !n.state.Services.VRRP.Config.Enabled {
+		return nil
+	}
+
+	// Stop keepalived, releasing the floating addresses.
+	err := systemd.StopUnit(ctx, "keepalived.service")
+	if err != nil {
+		return err
+	}
+
+	// Remove the configuration and state.
+	err = os.Remove("/etc/keepalived/keepalived.conf")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return os.RemoveAll(vrrpStatePath)
+}
+
+// Start starts the service.
+func (n *VRRP) Start(ctx context.Context) error {
+	if !n.state.Services.VRRP.Config.Enabled {
+		return nil
+	}
+
+	// Create the keepalived config directory if missing.
+	err := os.Mkdir("/etc/keepalived", 0o755)
+	if err != nil && !errors.Is(err, os.ErrExist) {
+		return err
+	}
+
+	err = os.WriteFile("/etc/keepalived/notify.sh", []byte(vrrpNotify), 0o700) //nolint:gosec
+	if err != nil {
+		return err
+	}
+
+	conf, err := n.generateConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile("/etc/keepalived/keepalived.conf", []byte(conf), 0o600)
+	if err != nil {
+		return err
+	}
+
+	// Clear the previous state, as the instances start over.
+	err = os.RemoveAll(vrrpStatePath)
+	if err != nil {
+		return err
+	}
+
+	// (Re)start keepalived to pick up the configuration.
+	err = systemd.RestartUnit(ctx, "keepalived.service")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ShouldStart returns true if the service should be started on boot.
+func (n *VRRP) ShouldStart() bool {
+	return n.state.Services.VRRP.Config.Enabled
+}
+
+// Struct returns the API struct for the VRRP service.
+func (*VRRP) Struct() any {
+	return &api.ServiceVRRP{}
+}
+
+// validate checks that the VRRP configuration is usable.
+func (n *VRRP) validate(cfg api.ServiceVRRPConfig) error {
+	names := []string{}
+
+	for _, instance := range cfg.Instances {
+		if !vrrpNameRegex.MatchString(instance.Name) || slices.Contains(names, instance.Name) {
+			return fmt.Errorf("invalid or duplicate VRRP instance name %q", instance.Name)
+		}
+
+		names = append(names, instance.Name)
+
+		if instance.Interface == "" || len(instance.Interface) > 15 || strings.ContainsAny(instance.Interface, " /") {
+			return fmt.Errorf("invalid interface %q for VRRP instance %q", instance.Interface, instance.Name)
+		}
+
+		if instance.VRID < 1 || instance.VRID > 255 {
+			return fmt.Errorf("invalid virtual router ID %d for VRRP instance %q", instance.VRID, instance.Name)
+		}
+
+		if instance.Priority < 1 || instance.Priority > 254 {
+			return fmt.Errorf("invalid priority %d for VRRP instance %q", instance.Priority, instance.Name)
+		}
+
+		if instance.AdvertInterval < 0 || instance.AdvertInterval > 255 {
+			return fmt.Errorf("invalid advertisement interval %d for VRRP instance %q", instance.AdvertInterval, instance.Name)
+		}
+
+		if len(instance.Addresses) == 0 {
+			return fmt.Errorf("no floating address for VRRP instance %q", instance.Name)
+		}
+
+		// All the addresses of an instance must be of the same family.
+		ipv6 := false
+
+		for i, address := range instance.Addresses {
+			ip, _, err := net.ParseCIDR(address)
+			if err != nil {
+				return fmt.Errorf("invalid floating address %q for VRRP instance %q", address, instance.Name)
+			}
+
+			if i > 0 && ipv6 != (ip.To4() == nil) {
+				return fmt.Errorf("mixed IPv4 and IPv6 floating addresses for VRRP instance %q", instance.Name)
+			}
+
+			ipv6 = ip.To4() == nil
+		}
+
+		for _, peer := range instance.UnicastPeers {
+			ip := net.ParseIP(peer)
+			if ip == nil || ipv6 != (ip.To4() == nil) {
+				return fmt.Errorf("invalid unicast peer %q for VRRP instance %q", peer, instance.Name)
+			}
+		}
+
+		for _, iface := range instance.TrackInterfaces {
+			if iface == "" || len(iface) > 15 || strings.ContainsAny(iface, " /") {
+				return fmt.Errorf("invalid tracked interface %q for VRRP instance %q", iface, instance.Name)
+			}
+		}
+
+		// Authentication is only supported by VRRPv2, used for IPv4.
+		if instance.Password != "" {
+			if ipv6 {
+				return fmt.Errorf("authentication isn't supported for the IPv6 VRRP instance %q", instance.Name)
+			}
+
+			if !secrets.IsReference(instance.Password) && (len(instance.Password) > 8 || strings.ContainsAny(instance.Password, " \n")) {
+				return fmt.Errorf("invalid password for VRRP instance %q, must be up to 8 characters without spaces", instance.Name)
+			}
+
+			err := secrets.ValidateReference(n.state.System.Security.Config.Secrets, instance.Password)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// generateConfig returns the keepalived configuration. All instances start as backups, electing the
+// master based on their priority.
+func (n *VRRP) generateConfig(ctx context.Context) (string, error) {
+	var ret strings.Builder
+
+	_, _ = ret.WriteString("# Generated by IncusOS\n")
+	_, _ = ret.WriteString("global_defs {\n")
+	_, _ = fmt.Fprintf(&ret, "    router_id %s\n", n.state.Hostname())
+	_, _ = ret.WriteString("    script_user root\n")
+	_, _ = ret.WriteString("    enable_script_security\n")
+	_, _ = ret.WriteString("}\n")
+
+	for _, instance := range n.state.Services.VRRP.Config.Instances {
+		ip, _, _ := net.ParseCIDR(instance.Addresses[0])
+
+		advertInterval := instance.AdvertInterval
+		if advertInterval == 0 {
+			advertInterval = 1
+		}
+
+		_, _ = fmt.Fprintf(&ret, "\nvrrp_instance %s {\n", instance.Name)
+		_, _ = ret.WriteString("    state BACKUP\n")
+		_, _ = fmt.Fprintf(&ret, "    interface %s\n", instance.Interface)
+		_, _ = fmt.Fprintf(&ret, "    virtual_router_id %d\n", instance.VRID)
+		_, _ = fmt.Fprintf(&ret, "    priority %d\n", instance.Priority)
+		_, _ = fmt.Fprintf(&ret, "    advert_int %d\n", advertInterval)
+
+		if ip != nil && ip.To4() == nil {
+			_, _ = ret.WriteString("    version 3\n")
+		}
+
+		if instance.NoPreempt {
+			_, _ = ret.WriteString("    nopreempt\n")
+		}
+
+		if instance.Password != "" {
+			password, err := secrets.Resolve(ctx, n.state.System.Security.Config.Secrets, instance.Password)
+			if err != nil {
+				return "", err
+			}
+
+			_, _ = ret.WriteString("    authentication {\n")
+			_, _ = ret.WriteString("        auth_type PASS\n")
+			_, _ = fmt.Fprintf(&ret, "        auth_pass %s\n", password)
+			_, _ = ret.WriteString("    }\n")
+		}
+
+		if len(instance.TrackInterfaces) > 0 {
+			_, _ = ret.WriteString("    track_interface {\n")
+
+			for _, iface := range instance.TrackInterfaces {
+				_, _ = fmt.Fprintf(&ret, "        %s\n", iface)
+			}
+
+			_, _ = ret.WriteString("    }\n")
+		}
+
+		if len(instance.UnicastPeers) > 0 {
+			_, _ = ret.WriteString("    unicast_peer {\n")
+
+			for _, peer := range instance.UnicastPeers {
+				_, _ = fmt.Fprintf(&ret, "        %s\n", peer)
+			}
+
+			_, _ = ret.WriteString("    }\n")
+		}
+
+		_, _ = ret.WriteString("    virtual_ipaddress {\n")
+
+		for _, address := range instance.Addresses {
+			_, _ = fmt.Fprintf(&ret, "        %s\n", address)
+		}
+
+		_, _ = ret.WriteString("    }\n")
+		_, _ = ret.WriteString("    notify /etc/keepalived/notify.sh\n")
+		_, _ = ret.WriteString("}\n")
+	}
+
+	return ret.String(), nil
+}
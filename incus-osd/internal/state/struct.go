@@ -21,7 +21,22 @@ type OS struct {
 	Name           string `json:"name"`
 	RunningRelease string `json:"running_release"`
 	NextRelease    string `json:"next_release"`
+	StagedRelease  string `json:"staged_release"`
 	SuccessfulBoot bool   `jsno:"successful_boot"`
+
+	// OS version to run the health checks of once booted, and the checks already failing beforehand.
+	HealthCheckRelease       string   `json:"health_check_release"`
+	HealthCheckKnownFailures []string `json:"health_check_known_failures"`
+
+	// Provenance of the update bundle last used by the bundle provider.
+	UpdateBundle *api.SystemUpdateBundle `json:"update_bundle"`
+
+	// OS version the storage pools were checkpointed ahead of, until it has booted and is healthy.
+	CheckpointRelease string `json:"checkpoint_release"`
+
+	// Most recent boots, and why the next boot is expected to fall back to the previous version.
+	BootHistory    []api.SystemUpdateBootEvent `json:"boot_history"`
+	RollbackReason string                      `json:"rollback_reason"`
 }
 
 // State represents the on-disk persistent state.
@@ -39,33 +54,59 @@ type State struct {
 	TriggerReboot   chan error `json:"-"`
 	TriggerShutdown chan error `json:"-"`
 	TriggerUpdate   chan bool  `json:"-"`
+	TriggerStage    chan bool  `json:"-"`
 
 	SecureBoot SecureBoot `json:"secure_boot"`
 	UsingSWTPM bool       `json:"using_swtpm"`
 
+	// Network configuration to revert to if the current one was only tried and never confirmed.
+	NetworkRollbackConfig *api.SystemNetworkConfig `json:"network_rollback_config"`
+
+	// Why each storage pool checkpoint taken by IncusOS was taken.
+	StorageCheckpoints map[string]string `json:"storage_checkpoints"`
+
 	Applications map[string]api.Application `json:"applications"`
 
 	OS OS `json:"os"`
 
 	Services struct {
-		Ceph      api.ServiceCeph      `json:"ceph"`
-		ISCSI     api.ServiceISCSI     `json:"iscsi"`
-		Kopia     api.ServiceKopia     `json:"kopia"`
-		Linstor   api.ServiceLinstor   `json:"linstor"`
-		LVM       api.ServiceLVM       `json:"lvm"`
-		Multipath api.ServiceMultipath `json:"multipath"`
-		NVME      api.ServiceNVME      `json:"nvme"`
-		OVN       api.ServiceOVN       `json:"ovn"`
-		Tailscale api.ServiceTailscale `json:"tailscale"`
-		USBIP     api.ServiceUSBIP     `json:"usbip"`
+		BGP        api.ServiceBGP        `json:"bgp"`
+		Ceph       api.ServiceCeph       `json:"ceph"`
+		DRBD       api.ServiceDRBD       `json:"drbd"`
+		FC         api.ServiceFC         `json:"fc"`
+		HAProxy    api.ServiceHAProxy    `json:"haproxy"`
+		IPMI       api.ServiceIPMI       `json:"ipmi"`
+		ISCSI      api.ServiceISCSI      `json:"iscsi"`
+		Kopia      api.ServiceKopia      `json:"kopia"`
+		Linstor    api.ServiceLinstor    `json:"linstor"`
+		LLDP       api.ServiceLLDP       `json:"lldp"`
+		LogForward api.ServiceLogForward `json:"logforward"`
+		LVM        api.ServiceLVM        `json:"lvm"`
+		Metrics    api.ServiceMetrics    `json:"metrics"`
+		Multipath  api.ServiceMultipath  `json:"multipath"`
+		Netdata    api.ServiceNetdata    `json:"netdata"`
+		NFS        api.ServiceNFS        `json:"nfs"`
+		NVME       api.ServiceNVME       `json:"nvme"`
+		OVN        api.ServiceOVN        `json:"ovn"`
+		Redfish    api.ServiceRedfish    `json:"redfish"`
+		SMARTD     api.ServiceSMARTD     `json:"smartd"`
+		SSH        api.ServiceSSH        `json:"ssh"`
+		Tailscale  api.ServiceTailscale  `json:"tailscale"`
+		USBIP      api.ServiceUSBIP      `json:"usbip"`
+		VRRP       api.ServiceVRRP       `json:"vrrp"`
 	} `json:"services"`
 
 	System struct {
-		Logging  api.SystemLogging  `json:"logging"`
-		Network  api.SystemNetwork  `json:"network"`
-		Provider api.SystemProvider `json:"provider"`
-		Security api.SystemSecurity `json:"security"`
-		Update   api.SystemUpdate   `json:"update"`
+		Extensions api.SystemExtensions `json:"extensions"`
+		Firmware   api.SystemFirmware   `json:"firmware"`
+		Kernel     api.SystemKernel     `json:"kernel"`
+		Logging    api.SystemLogging    `json:"logging"`
+		Network    api.SystemNetwork    `json:"network"`
+		Provider   api.SystemProvider   `json:"provider"`
+		Security   api.SystemSecurity   `json:"security"`
+		Storage    api.SystemStorage    `json:"storage"`
+		Update     api.SystemUpdate     `json:"update"`
+		Watchdog   api.SystemWatchdog   `json:"watchdog"`
 	} `json:"system"`
 }
 
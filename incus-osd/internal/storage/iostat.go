@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// GetIOStats returns I/O statistics for each pool, its member devices and its datasets.
+// Device statistics are averaged over a one second interval, so this function blocks for at least a second.
+func GetIOStats(ctx context.Context) (api.SystemStorageIOStats, error) {
+	ret := api.SystemStorageIOStats{
+		Pools: []api.SystemStoragePoolIOStats{},
+	}
+
+	// Get the list of imported pools.
+	output, err := subprocess.RunCommandContext(ctx, "zpool", "list", "-H", "-o", "name")
+	if err != nil {
+		return ret, err
+	}
+
+	for _, zpoolName := range strings.Fields(output) {
+		poolStats, err := getPoolIOStats(ctx, zpoolName)
+		if err != nil {
+			return ret, err
+		}
+
+		ret.Pools = append(ret.Pools, poolStats)
+	}
+
+	return ret, nil
+}
+
+func getPoolIOStats(ctx context.Context, zpoolName string) (api.SystemStoragePoolIOStats, error) {
+	ret := api.SystemStoragePoolIOStats{
+		Name:     zpoolName,
+		Devices:  []api.SystemStorageDeviceIOStats{},
+		Datasets: []api.SystemStorageDatasetIOStats{},
+	}
+
+	// Get bandwidth and latency over a one second interval, skipping the since-import summary.
+	latencyOutput, err := subprocess.RunCommandContext(ctx, "zpool", "iostat", "-Hpvly", zpoolName, "1", "1")
+	if err != nil {
+		return ret, err
+	}
+
+	// Get the current queue depths.
+	queueOutput, err := subprocess.RunCommandContext(ctx, "zpool", "iostat", "-Hpvq", zpoolName)
+	if err != nil {
+		return ret, err
+	}
+
+	devices := parseZpoolIOStat(latencyOutput, queueOutput)
+
+	for _, device := range devices {
+		if device.Name == zpoolName {
+			ret.Pool = device
+
+			continue
+		}
+
+		ret.Devices = append(ret.Devices, device)
+	}
+
+	// Get the per-dataset counters from the kernel.
+	objsets, err := filepath.Glob(filepath.Join("/proc/spl/kstat/zfs", zpoolName, "objset-*"))
+	if err != nil {
+		return ret, err
+	}
+
+	for _, objset := range objsets {
+		f, err := os.Open(objset) //nolint:gosec
+		if err != nil {
+			return ret, err
+		}
+
+		dataset, err := parseObjsetKstat(f)
+		_ = f.Close()
+
+		if err != nil {
+			return ret, err
+		}
+
+		// Skip internal datasets.
+		if dataset.Name == "" || strings.Contains(dataset.Name, "$") {
+			continue
+		}
+
+		ret.Datasets = append(ret.Datasets, dataset)
+	}
+
+	return ret, nil
+}
+
+// parseZpoolIOStat parses the scripted output of `zpool iostat -Hpvly` and `zpool iostat -Hpvq`.
+// Both list the same rows in the same order. Rows without statistics, such as the "logs" or
+// "cache" headers, are skipped.
+func parseZpoolIOStat(latencyOutput string, queueOutput string) []api.SystemStorageDeviceIOStats {
+	ret := []api.SystemStorageDeviceIOStats{}
+
+	latencyLines := strings.Split(strings.TrimSpace(latencyOutput), "\n")
+	queueLines := strings.Split(strings.TrimSpace(queueOutput), "\n")
+
+	for i, line := range latencyLines {
+		// Columns: name, alloc, free, read ops, write ops, read bandwidth, write bandwidth,
+		// total read wait, total write wait, followed by more detailed latencies.
+		fields := strings.Split(line, "\t")
+		if len(fields) < 9 || fields[3] == "-" {
+			continue
+		}
+
+		device := api.SystemStorageDeviceIOStats{
+			Name:                strings.TrimSpace(fields[0]),
+			ReadOpsPerSecond:    parseIOStatValue(fields[3]),
+			WriteOpsPerSecond:   parseIOStatValue(fields[4]),
+			ReadBytesPerSecond:  parseIOStatValue(fields[5]),
+			WriteBytesPerSecond: parseIOStatValue(fields[6]),
+			ReadLatency:         parseIOStatValue(fields[7]),
+			WriteLatency:        parseIOStatValue(fields[8]),
+		}
+
+		// Columns: name, alloc, free, read ops, write ops, read bandwidth, write bandwidth, followed
+		// by pending/active pairs for the sync read, sync write, async read and async write queues.
+		if i < len(queueLines) {
+			queueFields := strings.Split(queueLines[i], "\t")
+			if len(queueFields) >= 15 && strings.TrimSpace(queueFields[0]) == device.Name {
+				for _, value := range queueFields[7:15] {
+					device.QueueDepth += parseIOStatValue(value)
+				}
+			}
+		}
+
+		ret = append(ret, device)
+	}
+
+	return ret
+}
+
+// parseIOStatValue converts a single `zpool iostat -p` value, treating "-" as zero.
+func parseIOStatValue(value string) int {
+	i, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0
+	}
+
+	return i
+}
+
+// parseObjsetKstat parses a /proc/spl/kstat/zfs/<pool>/objset-* file.
+func parseObjsetKstat(r io.Reader) (api.SystemStorageDatasetIOStats, error) {
+	ret := api.SystemStorageDatasetIOStats{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		// Lines are made of a name, a type and a value.
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+
+		switch fields[0] {
+		case "dataset_name":
+			ret.Name = fields[2]
+		case "reads":
+			ret.Reads = parseIOStatValue(fields[2])
+		case "writes":
+			ret.Writes = parseIOStatValue(fields[2])
+		case "nread":
+			ret.BytesRead = parseIOStatValue(fields[2])
+		case "nwritten":
+			ret.BytesWritten = parseIOStatValue(fields[2])
+		}
+	}
+
+	return ret, scanner.Err()
+}
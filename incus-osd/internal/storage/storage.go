@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -260,6 +261,22 @@ func PoolExists(ctx context.Context, zpoolName string) bool {
 	return err == nil
 }
 
+// PoolHasCheckpoint checks if a given ZFS pool currently has a checkpoint.
+func PoolHasCheckpoint(ctx context.Context, zpoolName string) (bool, error) {
+	output, err := subprocess.RunCommandContext(ctx, "zpool", "status", zpoolName)
+	if err != nil {
+		return false, err
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "checkpoint:") {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 // DatasetExists checks if a given ZFS dataset exists.
 func DatasetExists(ctx context.Context, datasetName string) bool {
 	_, err := subprocess.RunCommandContext(ctx, "zfs", "list", datasetName)
@@ -267,6 +284,16 @@ func DatasetExists(ctx context.Context, datasetName string) bool {
 	return err == nil
 }
 
+// GetDatasetType returns the type of a given ZFS dataset, such as "filesystem" or "volume".
+func GetDatasetType(ctx context.Context, datasetName string) (string, error) {
+	output, err := subprocess.RunCommandContext(ctx, "zfs", "get", "-H", "-o", "value", "type", datasetName)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
 // GetZpoolMembers returns an instantiated SystemStoragePool struct for the specified storage pool.
 // Logically it makes more sense for this to be in the zfs package, but that would cause an import loop.
 func GetZpoolMembers(ctx context.Context, zpoolName string) (api.SystemStoragePool, error) {
@@ -304,6 +331,24 @@ func getZpoolMembersHelper(ctx context.Context, rawJSONContent []byte, zpoolName
 		return api.SystemStoragePool{}, errors.New("bad type for keystatus field")
 	}
 
+	// Get the checkpoint status.
+	zpoolHasCheckpoint, err := PoolHasCheckpoint(ctx, zpoolName)
+	if err != nil {
+		return api.SystemStoragePool{}, err
+	}
+
+	zpoolCheckpointSpace := 0
+
+	if zpoolHasCheckpoint {
+		output, err := subprocess.RunCommandContext(ctx, "zpool", "get", "-Hp", "-o", "value", "checkpoint", zpoolName)
+		if err != nil {
+			return api.SystemStoragePool{}, err
+		}
+
+		// A fresh checkpoint doesn't consume any space yet and is reported as "-".
+		zpoolCheckpointSpace, _ = strconv.Atoi(strings.TrimSpace(output))
+	}
+
 	zpoolType := ""
 	zpoolAllocSpace := 0
 	zpoolTotalSpace := 0
@@ -378,7 +423,7 @@ func getZpoolMembersHelper(ctx context.Context, rawJSONContent []byte, zpoolName
 	}
 
 	// Get ZFS datasets and fill in volumes.
-	zfsListOutput, err := subprocess.RunCommandContext(ctx, "zfs", "list", "-r", "-d1", zpoolName, "-o", "name,quota,used,incusos:use", "-j", "--json-int")
+	zfsListOutput, err := subprocess.RunCommandContext(ctx, "zfs", "list", "-r", "-d1", zpoolName, "-o", "name,quota,used,incusos:use,compression,recordsize,atime,sync", "-j", "--json-int")
 	if err != nil {
 		return api.SystemStoragePool{}, err
 	}
@@ -416,6 +461,26 @@ func getZpoolMembersHelper(ctx context.Context, rawJSONContent []byte, zpoolName
 				if ok {
 					vol.UsageInBytes = int(val)
 				}
+			case "compression":
+				val, ok := prop.Value.(string)
+				if ok {
+					vol.Properties.Compression = val
+				}
+			case "recordsize":
+				val, ok := prop.Value.(float64)
+				if ok {
+					vol.Properties.RecordSize = formatRecordSize(int(val))
+				}
+			case "atime":
+				val, ok := prop.Value.(string)
+				if ok {
+					vol.Properties.Atime = val
+				}
+			case "sync":
+				val, ok := prop.Value.(string)
+				if ok {
+					vol.Properties.Sync = val
+				}
 			default:
 			}
 		}
@@ -459,10 +524,24 @@ func getZpoolMembersHelper(ctx context.Context, rawJSONContent []byte, zpoolName
 		RawPoolSizeInBytes:        zpoolTotalSpace,
 		UsablePoolSizeInBytes:     zpoolDefSpace,
 		PoolAllocatedSpaceInBytes: zpoolAllocSpace,
+		HasCheckpoint:             zpoolHasCheckpoint,
+		CheckpointSizeInBytes:     zpoolCheckpointSpace,
 		Volumes:                   zpoolVolumes,
 	}, nil
 }
 
+// formatRecordSize converts a record size in bytes into the short form used by ZFS, such as "128K".
+func formatRecordSize(size int) string {
+	switch {
+	case size >= 1024*1024 && size%(1024*1024) == 0:
+		return strconv.Itoa(size/(1024*1024)) + "M"
+	case size >= 1024 && size%1024 == 0:
+		return strconv.Itoa(size/1024) + "K"
+	default:
+		return strconv.Itoa(size)
+	}
+}
+
 // calculateScrubProgress calculates the scrub progress for a given zpool and returns it in a formatted percentage string.
 func calculateScrubProgress(stats zpoolScanStats) string {
 	// If we know the scan is finished, the progress is 100%.
@@ -732,6 +811,26 @@ func IsRemoteDevice(deviceName string) (bool, error) {
 	return false, nil
 }
 
+// IsLocalNVMEDevice determines if a given device is a locally attached NVMe drive.
+func IsLocalNVMEDevice(deviceName string) (bool, error) {
+	// We might be given a symlink, such as /dev/disk/by-id/....; if so, first resolve it to the actual device.
+	symlink, err := os.Readlink(deviceName)
+	if err == nil {
+		deviceName = filepath.Join(filepath.Dir(deviceName), symlink)
+	}
+
+	if !strings.HasPrefix(filepath.Base(deviceName), "nvme") {
+		return false, nil
+	}
+
+	isRemote, err := IsRemoteDevice(deviceName)
+	if err != nil {
+		return false, err
+	}
+
+	return !isRemote, nil
+}
+
 // WipeDrive will wipe all data on the given drive, unless it is the boot device,
 // a remote device, or currently a member of a storage pool.
 func WipeDrive(ctx context.Context, drive string) error {
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -70,3 +71,63 @@ func TestCalculateScrubProgress(t *testing.T) {
 		})
 	}
 }
+
+func TestParseZpoolIOStat(t *testing.T) {
+	t.Parallel()
+
+	latencyOutput := "local\t4313088\t17712427008\t2\t50\t8192\t1048576\t150000\t320000\t120000\t300000\t-\t-\t-\t-\t-\t-\n" +
+		"mirror-0\t4313088\t17712427008\t2\t50\t8192\t1048576\t150000\t320000\t120000\t300000\t-\t-\t-\t-\t-\t-\n" +
+		"nvme0n1p11\t-\t-\t1\t25\t4096\t524288\t100000\t300000\t90000\t280000\t-\t-\t-\t-\t-\t-\n" +
+		"nvme1n1p11\t-\t-\t1\t25\t4096\t524288\t200000\t340000\t150000\t320000\t-\t-\t-\t-\t-\t-\n" +
+		"cache\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\n" +
+		"nvme2n1\t1024\t1000000\t0\t0\t0\t0\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\n"
+
+	queueOutput := "local\t4313088\t17712427008\t2\t50\t8192\t1048576\t0\t0\t0\t0\t1\t2\t3\t4\t0\t0\t0\t0\n" +
+		"mirror-0\t4313088\t17712427008\t2\t50\t8192\t1048576\t0\t0\t0\t0\t1\t2\t3\t4\t0\t0\t0\t0\n" +
+		"nvme0n1p11\t-\t-\t1\t25\t4096\t524288\t0\t0\t0\t0\t1\t1\t1\t2\t0\t0\t0\t0\n" +
+		"nvme1n1p11\t-\t-\t1\t25\t4096\t524288\t0\t0\t0\t0\t0\t1\t2\t2\t0\t0\t0\t0\n" +
+		"cache\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\n" +
+		"nvme2n1\t1024\t1000000\t0\t0\t0\t0\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\n"
+
+	devices := parseZpoolIOStat(latencyOutput, queueOutput)
+	require.Len(t, devices, 5)
+
+	require.Equal(t, "local", devices[0].Name)
+	require.Equal(t, 50, devices[0].WriteOpsPerSecond)
+	require.Equal(t, 1048576, devices[0].WriteBytesPerSecond)
+	require.Equal(t, 10, devices[0].QueueDepth)
+
+	require.Equal(t, "nvme1n1p11", devices[3].Name)
+	require.Equal(t, 1, devices[3].ReadOpsPerSecond)
+	require.Equal(t, 4096, devices[3].ReadBytesPerSecond)
+	require.Equal(t, 200000, devices[3].ReadLatency)
+	require.Equal(t, 340000, devices[3].WriteLatency)
+	require.Equal(t, 5, devices[3].QueueDepth)
+
+	require.Equal(t, "nvme2n1", devices[4].Name)
+	require.Equal(t, 0, devices[4].ReadLatency)
+	require.Equal(t, 0, devices[4].QueueDepth)
+}
+
+func TestParseObjsetKstat(t *testing.T) {
+	t.Parallel()
+
+	kstat := `28 1 0x01 7 2160 5214294046 5357436862048
+name                            type data
+dataset_name                    7    local/incus
+writes                          4    1520
+nwritten                        4    6225920
+reads                           4    312
+nread                           4    1277952
+nunlinks                        4    3
+nunlinked                       4    3
+`
+
+	dataset, err := parseObjsetKstat(strings.NewReader(kstat))
+	require.NoError(t, err)
+	require.Equal(t, "local/incus", dataset.Name)
+	require.Equal(t, 312, dataset.Reads)
+	require.Equal(t, 1520, dataset.Writes)
+	require.Equal(t, 1277952, dataset.BytesRead)
+	require.Equal(t, 6225920, dataset.BytesWritten)
+}
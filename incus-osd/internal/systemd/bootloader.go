@@ -0,0 +1,117 @@
+package systemd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+// ErrNoRollbackVersion is returned when no previous OS version is available for rollback.
+var ErrNoRollbackVersion = errors.New("no previous version available for rollback")
+
+// GetRollbackVersion returns the most recent OS version older than the running one which is still
+// installed and has previously booted successfully, or an empty string if none is available.
+func GetRollbackVersion(osName string, runningVersion string) string {
+	entries, err := os.ReadDir(BootImagesPath)
+	if err != nil {
+		return ""
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	for _, version := range getHealthyBootVersions(osName, names) {
+		if version >= runningVersion {
+			continue
+		}
+
+		// The matching usr partition must also still be present.
+		_, err := os.Stat(filepath.Join(PartitionLabelsPath, osName+"_"+version))
+		if err != nil {
+			continue
+		}
+
+		return version
+	}
+
+	return ""
+}
+
+// RollbackOS makes the previous OS version the default boot entry and returns it, recording the reason
+// in the boot history once booted. The system must then be rebooted to complete the rollback.
+func RollbackOS(ctx context.Context, s *state.State, reason string) (string, error) {
+	version := GetRollbackVersion(s.OS.Name, s.OS.RunningRelease)
+	if version == "" {
+		return "", ErrNoRollbackVersion
+	}
+
+	err := SetDefaultBootVersion(ctx, s.OS.Name, version)
+	if err != nil {
+		return "", err
+	}
+
+	// Keep the running version recorded as the next release, so it's skipped by the update checker
+	// once the previous version has been booted.
+	s.OS.NextRelease = s.OS.RunningRelease
+	s.OS.RollbackReason = reason
+	s.System.Update.State.RollbackVersion = version
+	s.System.Update.State.RollbackPending = true
+	s.System.Update.State.NeedsReboot = true
+	_ = s.Save()
+
+	return version, nil
+}
+
+// SetDefaultBootVersion makes the boot loader default to the given OS version. An empty version clears
+// the default, letting the boot loader pick the most recent version.
+func SetDefaultBootVersion(ctx context.Context, osName string, version string) error {
+	// WORKAROUND: Start the boot.mount unit so /boot autofs is active.
+	err := StartUnit(ctx, "boot.mount")
+	if err != nil {
+		return err
+	}
+
+	entry := ""
+	if version != "" {
+		entry = osName + "_" + version + ".efi"
+	}
+
+	_, err = subprocess.RunCommandContext(ctx, "bootctl", "set-default", entry)
+
+	return err
+}
+
+// getHealthyBootVersions returns the OS versions, newest first, of the boot images which have been
+// marked as good by systemd-bless-boot. Images still carrying a boot counter in their name either
+// haven't been booted yet or failed to boot.
+func getHealthyBootVersions(osName string, names []string) []string {
+	ret := []string{}
+
+	for _, name := range names {
+		version, ok := strings.CutPrefix(name, osName+"_")
+		if !ok {
+			continue
+		}
+
+		version, ok = strings.CutSuffix(version, ".efi")
+		if !ok || version == "" || strings.Contains(version, "+") {
+			continue
+		}
+
+		ret = append(ret, version)
+	}
+
+	slices.Sort(ret)
+	slices.Reverse(ret)
+
+	return ret
+}
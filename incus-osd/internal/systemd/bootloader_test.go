@@ -0,0 +1,54 @@
+package systemd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetHealthyBootVersions(t *testing.T) {
+	t.Parallel()
+
+	names := []string{
+		"IncusOS_202511040120.efi",
+		"IncusOS_202510280120.efi",
+		"IncusOS_202511110120+2-1.efi",
+		"IncusOS_202510210120+0-3.efi",
+		"Other_202511180120.efi",
+		"IncusOS_.efi",
+		"IncusOS_202511180120.conf",
+	}
+
+	require.Equal(t, []string{"202511040120", "202510280120"}, getHealthyBootVersions("IncusOS", names))
+	require.Empty(t, getHealthyBootVersions("IncusOS", nil))
+}
+
+func TestGetBootStatus(t *testing.T) {
+	t.Parallel()
+
+	names := []string{
+		"IncusOS_202511040120.efi",
+		"IncusOS_202511110120+2-1.efi",
+		"IncusOS_202510210120+0-3.efi",
+		"IncusOS_2025111801200.efi",
+	}
+
+	tests := []struct {
+		version string
+		status  string
+		left    int
+		done    int
+	}{
+		{"202511040120", "good", 0, 0},
+		{"202511110120", "pending", 2, 1},
+		{"202510210120", "bad", 0, 3},
+		{"202511180120", "missing", 0, 0},
+	}
+
+	for _, tc := range tests {
+		status, left, done := getBootStatus("IncusOS", tc.version, names)
+		require.Equal(t, tc.status, status, tc.version)
+		require.Equal(t, tc.left, left, tc.version)
+		require.Equal(t, tc.done, done, tc.version)
+	}
+}
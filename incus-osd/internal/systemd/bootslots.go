@@ -0,0 +1,211 @@
+package systemd
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+// maxBootHistory is the number of boots kept in the boot history.
+const maxBootHistory = 20
+
+// slotPartitions maps the partition number of each usr partition to its slot.
+var slotPartitions = map[string]string{"5": "a", "8": "b"}
+
+// sysClassBlockPath is the sysfs location of the block devices.
+var sysClassBlockPath = "/sys/class/block"
+
+// bootIDPath holds the identifier of the current boot.
+var bootIDPath = "/proc/sys/kernel/random/boot_id"
+
+// GetBootSlots returns the status of the two OS image slots.
+func GetBootSlots(s *state.State) []api.SystemUpdateBootSlot {
+	slots := []api.SystemUpdateBootSlot{{Name: "a"}, {Name: "b"}}
+	versions := getSlotVersions(s.OS.Name)
+
+	bootImages := []string{}
+
+	entries, err := os.ReadDir(BootImagesPath)
+	if err == nil {
+		for _, entry := range entries {
+			bootImages = append(bootImages, entry.Name())
+		}
+	}
+
+	for i := range slots {
+		slots[i].Version = versions[slots[i].Name]
+		if slots[i].Version == "" {
+			continue
+		}
+
+		if slots[i].Version == s.OS.RunningRelease {
+			slots[i].Role = "active"
+		} else {
+			slots[i].Role = "previous"
+		}
+
+		slots[i].BootStatus, slots[i].TriesLeft, slots[i].TriesDone = getBootStatus(s.OS.Name, slots[i].Version, bootImages)
+
+		// Find the last time the system fell back from this slot's version.
+		for _, event := range s.OS.BootHistory {
+			if event.FailedVersion == slots[i].Version {
+				slots[i].LastFailure = event.Reason
+			}
+		}
+	}
+
+	return slots
+}
+
+// RecordBoot adds the current boot to the boot history, recording why the system fell back to a
+// previous version if it didn't boot the expected one. Each boot is only recorded once.
+func RecordBoot(s *state.State) {
+	content, err := os.ReadFile(bootIDPath)
+	if err != nil {
+		return
+	}
+
+	bootID := strings.TrimSpace(string(content))
+
+	for _, event := range s.OS.BootHistory {
+		if event.BootID == bootID {
+			return
+		}
+	}
+
+	event := api.SystemUpdateBootEvent{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		BootID:    bootID,
+		Version:   s.OS.RunningRelease,
+	}
+
+	for slot, version := range getSlotVersions(s.OS.Name) {
+		if version == s.OS.RunningRelease {
+			event.Slot = slot
+		}
+	}
+
+	if s.OS.NextRelease != "" && s.OS.RunningRelease != s.OS.NextRelease {
+		event.FailedVersion = s.OS.NextRelease
+		event.Reason = s.OS.RollbackReason
+
+		if event.Reason == "" {
+			event.Reason = getBootFailureReason(s.OS.Name, s.OS.NextRelease)
+		}
+	}
+
+	s.OS.RollbackReason = ""
+	s.OS.BootHistory = append(s.OS.BootHistory, event)
+
+	if len(s.OS.BootHistory) > maxBootHistory {
+		s.OS.BootHistory = s.OS.BootHistory[len(s.OS.BootHistory)-maxBootHistory:]
+	}
+
+	_ = s.Save()
+}
+
+// getSlotVersions returns the OS version held by each slot, based on the labels of the usr partitions.
+func getSlotVersions(osName string) map[string]string {
+	ret := map[string]string{}
+
+	entries, err := os.ReadDir(PartitionLabelsPath)
+	if err != nil {
+		return ret
+	}
+
+	for _, entry := range entries {
+		version, ok := strings.CutPrefix(entry.Name(), osName+"_")
+		if !ok || version == "" || strings.Contains(version, "_") {
+			continue
+		}
+
+		device, err := filepath.EvalSymlinks(filepath.Join(PartitionLabelsPath, entry.Name()))
+		if err != nil {
+			continue
+		}
+
+		partition, err := os.ReadFile(filepath.Join(sysClassBlockPath, filepath.Base(device), "partition"))
+		if err != nil {
+			continue
+		}
+
+		slot, ok := slotPartitions[strings.TrimSpace(string(partition))]
+		if ok {
+			ret[slot] = version
+		}
+	}
+
+	return ret
+}
+
+// getBootStatus returns the boot assessment status of an OS version along with its boot counters,
+// based on the name of its boot image. Boot images are named "<name>_<version>+<left>-<done>.efi"
+// until systemd-bless-boot marks them as good by removing the counters.
+func getBootStatus(osName string, version string, bootImages []string) (string, int, int) {
+	for _, name := range bootImages {
+		counters, ok := strings.CutPrefix(name, osName+"_"+version)
+		if !ok {
+			continue
+		}
+
+		counters, ok = strings.CutSuffix(counters, ".efi")
+		if !ok {
+			continue
+		}
+
+		if counters == "" {
+			return "good", 0, 0
+		}
+
+		counters, ok = strings.CutPrefix(counters, "+")
+		if !ok {
+			continue
+		}
+
+		leftStr, doneStr, _ := strings.Cut(counters, "-")
+
+		left, err := strconv.Atoi(leftStr)
+		if err != nil {
+			continue
+		}
+
+		done, _ := strconv.Atoi(doneStr)
+
+		if left == 0 {
+			return "bad", left, done
+		}
+
+		return "pending", left, done
+	}
+
+	return "missing", 0, 0
+}
+
+// getBootFailureReason guesses why an OS version didn't boot when no reason was recorded.
+func getBootFailureReason(osName string, version string) string {
+	entries, err := os.ReadDir(BootImagesPath)
+	if err != nil {
+		return "unknown"
+	}
+
+	bootImages := []string{}
+	for _, entry := range entries {
+		bootImages = append(bootImages, entry.Name())
+	}
+
+	status, _, done := getBootStatus(osName, version, bootImages)
+
+	switch status {
+	case "bad":
+		return "failed to boot after " + strconv.Itoa(done) + " attempts"
+	case "missing":
+		return "boot image is missing"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,143 @@
+package systemd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// generateChronyContents generates the chrony configuration. If no timeservers are defined, the NTP
+// servers learned by systemd-networkd are used along with the default pool.
+func generateChronyContents(timeCfg api.SystemNetworkTime) string {
+	var ret strings.Builder
+
+	_, _ = ret.WriteString("driftfile /var/lib/chrony/chrony.drift\nntsdumpdir /var/lib/chrony\nmakestep 1.0 3\nrtcsync\nleapsectz right/UTC\n\n")
+
+	if len(timeCfg.NTPServers) == 0 {
+		_, _ = ret.WriteString("sourcedir " + strings.TrimSuffix(ChronySourcesPath, "/") + "\n")
+		_, _ = ret.WriteString("pool 2.debian.pool.ntp.org iburst\n")
+
+		return ret.String()
+	}
+
+	for _, ts := range timeCfg.NTPServers {
+		if timeCfg.NTS {
+			_, _ = ret.WriteString(fmt.Sprintf("server %s iburst nts\n", ts))
+		} else {
+			_, _ = ret.WriteString(fmt.Sprintf("server %s iburst\n", ts))
+		}
+	}
+
+	return ret.String()
+}
+
+// updateChronySources writes the NTP servers learned by systemd-networkd, such as through DHCP, as
+// chrony sources and has chrony reload them if it's running.
+func updateChronySources(ctx context.Context) error {
+	entries, err := os.ReadDir(NetworkdLinksStatePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	servers := []string{}
+
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(NetworkdLinksStatePath, entry.Name()))
+		if err != nil {
+			return err
+		}
+
+		for _, server := range parseNetworkdNTPServers(string(content)) {
+			if !slices.Contains(servers, server) {
+				servers = append(servers, server)
+			}
+		}
+	}
+
+	err = os.MkdirAll(ChronySourcesPath, 0o755)
+	if err != nil {
+		return err
+	}
+
+	var sources strings.Builder
+
+	for _, server := range servers {
+		_, _ = sources.WriteString(fmt.Sprintf("server %s iburst\n", server))
+	}
+
+	err = os.WriteFile(filepath.Join(ChronySourcesPath, "networkd.sources"), []byte(sources.String()), 0o644)
+	if err != nil {
+		return err
+	}
+
+	if IsActive(ctx, "chrony.service") {
+		_, err = subprocess.RunCommandContext(ctx, "chronyc", "reload", "sources")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseNetworkdNTPServers returns the NTP servers listed in a systemd-networkd link state file.
+func parseNetworkdNTPServers(content string) []string {
+	for _, line := range strings.Split(content, "\n") {
+		value, ok := strings.CutPrefix(line, "NTP=")
+		if ok {
+			return strings.Fields(value)
+		}
+	}
+
+	return []string{}
+}
+
+// waitForTimeSync waits up to a provided timeout for chrony to perform an initial NTP synchronization.
+func waitForTimeSync(ctx context.Context, timeout time.Duration) error {
+	// Poll every second, without any requirement on the remaining correction or skew.
+	_, err := subprocess.RunCommandContext(ctx, "chronyc", "waitsync", strconv.Itoa(int(timeout.Seconds())), "0", "0", "1")
+
+	return err
+}
+
+// getTimeState queries chrony for the current time synchronization status.
+func getTimeState(ctx context.Context) *api.SystemNetworkTimeState {
+	output, err := subprocess.RunCommandContext(ctx, "chronyc", "-c", "tracking")
+	if err != nil {
+		return &api.SystemNetworkTimeState{}
+	}
+
+	return parseChronyTracking(output)
+}
+
+// parseChronyTracking parses the CSV output of "chronyc -c tracking".
+func parseChronyTracking(output string) *api.SystemNetworkTimeState {
+	ret := &api.SystemNetworkTimeState{}
+
+	fields := strings.Split(strings.TrimSpace(output), ",")
+	if len(fields) < 14 {
+		return ret
+	}
+
+	ret.Server = fields[1]
+	ret.Stratum, _ = strconv.Atoi(fields[2])
+	ret.Offset, _ = strconv.ParseFloat(fields[4], 64)
+	ret.Synchronized = fields[13] != "Not synchronised" && ret.Stratum > 0
+
+	// An unsynchronized chrony reports itself as the reference.
+	if !ret.Synchronized {
+		ret.Server = ""
+	}
+
+	return ret
+}
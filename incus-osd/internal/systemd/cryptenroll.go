@@ -2,10 +2,17 @@ package systemd
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"maps"
 	"os"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/lxc/incus/v6/shared/subprocess"
 	"github.com/muesli/crunchy"
@@ -16,19 +23,39 @@ import (
 	"github.com/lxc/incus-os/incus-osd/internal/util"
 )
 
+// Origins of a recovery key, as recorded in its metadata.
+const (
+	RecoveryKeyCreatedByInstall = "install"
+	RecoveryKeyCreatedByAPI     = "api"
+	RecoveryKeyCreatedByUser    = "user"
+	RecoveryKeyCreatedByUnknown = "unknown"
+)
+
+// luksMetadata is the subset of the LUKS2 JSON metadata needed to identify key slots and tokens.
+type luksMetadata struct {
+	Keyslots map[string]any `json:"keyslots"`
+	Tokens   map[string]struct {
+		Type                      string   `json:"type"`
+		Keyslots                  []string `json:"keyslots"`
+		FIDO2ClientPINRequired    bool     `json:"fido2-clientPin-required"`
+		FIDO2UserPresenceRequired bool     `json:"fido2-up-required"`
+		FIDO2UserVerifyRequired   bool     `json:"fido2-uv-required"`
+	} `json:"tokens"`
+}
+
 // GenerateRecoveryKey utilizes systemd-cryptenroll to generate a recovery key for the
 // root and swap LUKS volumes. Depends on an existing tpm2-backed key being enrolled and accessible.
-func GenerateRecoveryKey(ctx context.Context, s *state.State) error {
+func GenerateRecoveryKey(ctx context.Context, s *state.State, createdBy string) (string, error) {
 	// Get the underlying LUKS partitions.
 	luksVolumes, err := util.GetLUKSVolumePartitions()
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	// First, generate a recovery key for the root volume.
 	recoveryPassword, err := subprocess.RunCommandContext(ctx, "systemd-cryptenroll", "--unlock-tpm2-device", "auto", "--recovery-key", luksVolumes["root"])
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	recoveryPassword = strings.TrimSuffix(recoveryPassword, "\n")
@@ -36,19 +63,20 @@ func GenerateRecoveryKey(ctx context.Context, s *state.State) error {
 	// Second, set the same recovery key for the swap volume. Need to pass to systemd-cryptenroll via NEWPASSWORD environment variable.
 	_, _, err = subprocess.RunCommandSplit(ctx, append(os.Environ(), "NEWPASSWORD="+recoveryPassword), nil, "systemd-cryptenroll", "--unlock-tpm2-device", "auto", "--password", luksVolumes["swap"])
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	// Finally, save the recovery key into the state.
 	s.System.Security.Config.EncryptionRecoveryKeys = append(s.System.Security.Config.EncryptionRecoveryKeys, recoveryPassword)
 	s.System.Security.State.EncryptionRecoveryKeysRetrieved = false
+	recordRecoveryKeyMetadata(s, recoveryPassword, createdBy)
 
-	return nil
+	return recoveryPassword, nil
 }
 
 // AddEncryptionKey utilizes systemd-cryptenroll to add a user-specified key for the
 // root and swap LUKS volumes. Depends on an existing tpm2-backed key being enrolled and accessible.
-func AddEncryptionKey(ctx context.Context, s *state.State, key string) error {
+func AddEncryptionKey(ctx context.Context, s *state.State, key string, createdBy string) error {
 	if slices.Contains(s.System.Security.Config.EncryptionRecoveryKeys, key) {
 		return errors.New("provided encryption key is already enrolled")
 	}
@@ -80,6 +108,7 @@ func AddEncryptionKey(ctx context.Context, s *state.State, key string) error {
 	}
 
 	s.System.Security.Config.EncryptionRecoveryKeys = append(s.System.Security.Config.EncryptionRecoveryKeys, key)
+	recordRecoveryKeyMetadata(s, key, createdBy)
 
 	return nil
 }
@@ -120,15 +149,113 @@ func DeleteEncryptionKey(ctx context.Context, s *state.State, key string) error
 			continue
 		}
 
-		err := AddEncryptionKey(ctx, s, existingKey)
+		err := AddEncryptionKey(ctx, s, existingKey, RecoveryKeyCreatedByUnknown)
 		if err != nil {
 			return err
 		}
 	}
 
+	s.System.Security.State.EncryptionRecoveryKeysMetadata = slices.DeleteFunc(s.System.Security.State.EncryptionRecoveryKeysMetadata, func(metadata api.SystemSecurityRecoveryKey) bool {
+		return metadata.ID == RecoveryKeyID(key)
+	})
+
 	return nil
 }
 
+// RotateRecoveryKeys generates a new recovery key and invalidates all existing ones.
+func RotateRecoveryKeys(ctx context.Context, s *state.State) (string, error) {
+	newKey, err := GenerateRecoveryKey(ctx, s, RecoveryKeyCreatedByAPI)
+	if err != nil {
+		return "", err
+	}
+
+	// Get the underlying LUKS partitions.
+	luksVolumes, err := util.GetLUKSVolumePartitions()
+	if err != nil {
+		return "", err
+	}
+
+	// Wipe all recovery and password slots, then re-add only the new key.
+	for _, volume := range luksVolumes {
+		err := WipeAllRecoveryKeys(ctx, volume)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	s.System.Security.Config.EncryptionRecoveryKeys = []string{}
+
+	err = AddEncryptionKey(ctx, s, newKey, RecoveryKeyCreatedByAPI)
+	if err != nil {
+		return "", err
+	}
+
+	s.System.Security.State.EncryptionRecoveryKeysMetadata = slices.DeleteFunc(s.System.Security.State.EncryptionRecoveryKeysMetadata, func(metadata api.SystemSecurityRecoveryKey) bool {
+		return metadata.ID != RecoveryKeyID(newKey)
+	})
+
+	return newKey, nil
+}
+
+// GetRecoveryKeyByID returns the enrolled recovery key matching the provided ID.
+func GetRecoveryKeyByID(s *state.State, id string) (string, error) {
+	for _, key := range s.System.Security.Config.EncryptionRecoveryKeys {
+		if RecoveryKeyID(key) == id {
+			return key, nil
+		}
+	}
+
+	return "", fmt.Errorf("no recovery key with ID %q", id)
+}
+
+// RecoveryKeyID returns a short identifier for a recovery key, derived from its SHA256 hash.
+func RecoveryKeyID(key string) string {
+	hash := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(hash[:])[:12]
+}
+
+// GetRecoveryKeysMetadata returns the metadata of each enrolled recovery key, in the same
+// order as the keys themselves.
+func GetRecoveryKeysMetadata(s *state.State) []api.SystemSecurityRecoveryKey {
+	ret := make([]api.SystemSecurityRecoveryKey, 0, len(s.System.Security.Config.EncryptionRecoveryKeys))
+
+	for _, key := range s.System.Security.Config.EncryptionRecoveryKeys {
+		id := RecoveryKeyID(key)
+
+		index := slices.IndexFunc(s.System.Security.State.EncryptionRecoveryKeysMetadata, func(metadata api.SystemSecurityRecoveryKey) bool {
+			return metadata.ID == id
+		})
+
+		if index == -1 {
+			ret = append(ret, api.SystemSecurityRecoveryKey{ID: id, CreatedBy: RecoveryKeyCreatedByUnknown})
+
+			continue
+		}
+
+		ret = append(ret, s.System.Security.State.EncryptionRecoveryKeysMetadata[index])
+	}
+
+	return ret
+}
+
+// recordRecoveryKeyMetadata records when and how a recovery key was created, unless already known.
+func recordRecoveryKeyMetadata(s *state.State, key string, createdBy string) {
+	id := RecoveryKeyID(key)
+
+	if slices.ContainsFunc(s.System.Security.State.EncryptionRecoveryKeysMetadata, func(metadata api.SystemSecurityRecoveryKey) bool {
+		return metadata.ID == id
+	}) {
+		return
+	}
+
+	s.System.Security.State.EncryptionRecoveryKeysMetadata = append(s.System.Security.State.EncryptionRecoveryKeysMetadata, api.SystemSecurityRecoveryKey{
+		ID:        id,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		CreatedBy: createdBy,
+	})
+}
+
 // WipeAllRecoveryKeys will wipe all recovery and password key slots for the provided volume.
 func WipeAllRecoveryKeys(ctx context.Context, volume string) error {
 	_, err := subprocess.RunCommandContext(ctx, "systemd-cryptenroll", "--unlock-tpm2-device", "auto", "--wipe-slot", "recovery,password", volume)
@@ -136,6 +263,100 @@ func WipeAllRecoveryKeys(ctx context.Context, volume string) error {
 	return err
 }
 
+// ListEncryptionKeySlots returns the key slots in use on each encrypted volume.
+func ListEncryptionKeySlots(ctx context.Context) ([]api.SystemSecurityEncryptionKeySlot, error) {
+	ret := []api.SystemSecurityEncryptionKeySlot{}
+
+	// Get the LUKS partitions.
+	luksVolumes, err := util.GetLUKSVolumePartitions()
+	if err != nil {
+		return ret, err
+	}
+
+	for _, volumeName := range slices.Sorted(maps.Keys(luksVolumes)) {
+		luksMeta, err := getLUKSMetadata(ctx, luksVolumes[volumeName])
+		if err != nil {
+			return ret, err
+		}
+
+		slots, err := parseEncryptionKeySlots(volumeName, luksMeta)
+		if err != nil {
+			return ret, err
+		}
+
+		ret = append(ret, slots...)
+	}
+
+	return ret, nil
+}
+
+// getLUKSMetadata returns the parsed LUKS2 JSON metadata of a volume.
+func getLUKSMetadata(ctx context.Context, volume string) (*luksMetadata, error) {
+	output, err := subprocess.RunCommandContext(ctx, "cryptsetup", "luksDump", "--dump-json-metadata", volume)
+	if err != nil {
+		return nil, err
+	}
+
+	luksMeta := &luksMetadata{}
+
+	err = json.Unmarshal([]byte(output), luksMeta)
+	if err != nil {
+		return nil, err
+	}
+
+	return luksMeta, nil
+}
+
+// parseEncryptionKeySlots determines the type of each key slot from the tokens referencing it.
+// Slots without a token are plain passwords.
+func parseEncryptionKeySlots(volumeName string, luksMeta *luksMetadata) ([]api.SystemSecurityEncryptionKeySlot, error) {
+	tokenTypes := map[string]string{
+		"clevis":           "clevis",
+		"systemd-fido2":    "fido2",
+		"systemd-recovery": "recovery",
+		"systemd-tpm2":     "tpm2",
+	}
+
+	slotTypes := map[string]string{}
+
+	for _, token := range luksMeta.Tokens {
+		slotType, ok := tokenTypes[token.Type]
+		if !ok {
+			continue
+		}
+
+		for _, keyslot := range token.Keyslots {
+			slotTypes[keyslot] = slotType
+		}
+	}
+
+	ret := []api.SystemSecurityEncryptionKeySlot{}
+
+	for keyslot := range luksMeta.Keyslots {
+		slot, err := strconv.Atoi(keyslot)
+		if err != nil {
+			return nil, err
+		}
+
+		slotType, ok := slotTypes[keyslot]
+		if !ok {
+			slotType = "password"
+		}
+
+		ret = append(ret, api.SystemSecurityEncryptionKeySlot{
+			Volume: volumeName,
+			Slot:   slot,
+			Type:   slotType,
+		})
+	}
+
+	slices.SortFunc(ret, func(a api.SystemSecurityEncryptionKeySlot, b api.SystemSecurityEncryptionKeySlot) int {
+		return a.Slot - b.Slot
+	})
+
+	return ret, nil
+}
+
 // ListEncryptedVolumes returns a list of each encrypted volume and its status.
 func ListEncryptedVolumes(ctx context.Context) ([]api.SystemSecurityEncryptedVolume, error) {
 	ret := []api.SystemSecurityEncryptedVolume{}
@@ -188,3 +409,34 @@ func ListEncryptedVolumes(ctx context.Context) ([]api.SystemSecurityEncryptedVol
 
 	return ret, nil
 }
+
+// RecordTPMUnlockFailure checks whether the TPM failed to automatically unlock any of the encrypted
+// volumes during the current boot, updating the failure counter and raising the persistent warning
+// if so. Returns true if a new failure was recorded.
+func RecordTPMUnlockFailure(s *state.State, bootTime time.Time) bool {
+	failedVolumes := []string{}
+
+	for _, volume := range s.System.Security.State.EncryptedVolumes {
+		if volume.State == "unlocked (recovery passphrase)" {
+			failedVolumes = append(failedVolumes, volume.Volume)
+		}
+	}
+
+	if len(failedVolumes) == 0 {
+		return false
+	}
+
+	// Only count each boot once, even if the daemon is restarted.
+	if s.System.Security.State.TPMUnlockLastFailure != "" {
+		lastFailure, err := time.Parse(time.RFC3339, s.System.Security.State.TPMUnlockLastFailure)
+		if err == nil && !lastFailure.Before(bootTime) {
+			return false
+		}
+	}
+
+	s.System.Security.State.TPMUnlockFailures++
+	s.System.Security.State.TPMUnlockLastFailure = time.Now().UTC().Format(time.RFC3339)
+	s.System.Security.State.TPMUnlockWarning = true
+
+	return true
+}
@@ -0,0 +1,122 @@
+package systemd
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+var luksJSONMetadata = `{
+  "keyslots": {
+    "0": {"type": "luks2"},
+    "1": {"type": "luks2"},
+    "2": {"type": "luks2"},
+    "3": {"type": "luks2"},
+    "4": {"type": "luks2"}
+  },
+  "tokens": {
+    "0": {
+      "type": "systemd-tpm2",
+      "keyslots": ["0"]
+    },
+    "1": {
+      "type": "systemd-recovery",
+      "keyslots": ["1"]
+    },
+    "2": {
+      "type": "systemd-fido2",
+      "keyslots": ["3"],
+      "fido2-credential": "c2VjcmV0",
+      "fido2-rp": "io.systemd.cryptsetup",
+      "fido2-clientPin-required": true,
+      "fido2-up-required": true,
+      "fido2-uv-required": false
+    },
+    "3": {
+      "type": "systemd-fido2",
+      "keyslots": ["2"],
+      "fido2-credential": "b3RoZXI=",
+      "fido2-rp": "io.systemd.cryptsetup",
+      "fido2-clientPin-required": false,
+      "fido2-up-required": true,
+      "fido2-uv-required": true
+    }
+  }
+}`
+
+func TestEncryptionKeySlots(t *testing.T) {
+	t.Parallel()
+
+	luksMeta := &luksMetadata{}
+
+	err := json.Unmarshal([]byte(luksJSONMetadata), luksMeta)
+	require.NoError(t, err)
+
+	slots, err := parseEncryptionKeySlots("root", luksMeta)
+	require.NoError(t, err)
+	require.Equal(t, []api.SystemSecurityEncryptionKeySlot{
+		{Volume: "root", Slot: 0, Type: "tpm2"},
+		{Volume: "root", Slot: 1, Type: "recovery"},
+		{Volume: "root", Slot: 2, Type: "fido2"},
+		{Volume: "root", Slot: 3, Type: "fido2"},
+		{Volume: "root", Slot: 4, Type: "password"},
+	}, slots)
+}
+
+func TestRecoveryKeysMetadata(t *testing.T) {
+	t.Parallel()
+
+	s := &state.State{}
+	s.System.Security.Config.EncryptionRecoveryKeys = []string{"first-recovery-key!", "second-recovery-key!"}
+
+	recordRecoveryKeyMetadata(s, "first-recovery-key!", RecoveryKeyCreatedByInstall)
+	recordRecoveryKeyMetadata(s, "first-recovery-key!", RecoveryKeyCreatedByUser)
+
+	require.Len(t, RecoveryKeyID("first-recovery-key!"), 12)
+	require.NotEqual(t, RecoveryKeyID("first-recovery-key!"), RecoveryKeyID("second-recovery-key!"))
+
+	metadata := GetRecoveryKeysMetadata(s)
+	require.Len(t, metadata, 2)
+	require.Equal(t, RecoveryKeyID("first-recovery-key!"), metadata[0].ID)
+	require.Equal(t, RecoveryKeyCreatedByInstall, metadata[0].CreatedBy)
+	require.NotEmpty(t, metadata[0].CreatedAt)
+	require.Equal(t, RecoveryKeyID("second-recovery-key!"), metadata[1].ID)
+	require.Equal(t, RecoveryKeyCreatedByUnknown, metadata[1].CreatedBy)
+
+	key, err := GetRecoveryKeyByID(s, RecoveryKeyID("second-recovery-key!"))
+	require.NoError(t, err)
+	require.Equal(t, "second-recovery-key!", key)
+
+	_, err = GetRecoveryKeyByID(s, "000000000000")
+	require.Error(t, err)
+}
+
+func TestRecordTPMUnlockFailure(t *testing.T) {
+	t.Parallel()
+
+	s := &state.State{}
+	bootTime := time.Now().Add(-time.Hour)
+
+	// No failure when all volumes were unlocked by the TPM.
+	s.System.Security.State.EncryptedVolumes = []api.SystemSecurityEncryptedVolume{{Volume: "root", State: "unlocked (TPM)"}, {Volume: "swap", State: "unlocked (TPM; PCR update pending)"}}
+	require.False(t, RecordTPMUnlockFailure(s, bootTime))
+	require.Zero(t, s.System.Security.State.TPMUnlockFailures)
+	require.False(t, s.System.Security.State.TPMUnlockWarning)
+
+	// A failure is only counted once per boot.
+	s.System.Security.State.EncryptedVolumes = []api.SystemSecurityEncryptedVolume{{Volume: "root", State: "unlocked (recovery passphrase)"}}
+	require.True(t, RecordTPMUnlockFailure(s, bootTime))
+	require.False(t, RecordTPMUnlockFailure(s, bootTime))
+	require.Equal(t, 1, s.System.Security.State.TPMUnlockFailures)
+	require.True(t, s.System.Security.State.TPMUnlockWarning)
+	require.NotEmpty(t, s.System.Security.State.TPMUnlockLastFailure)
+
+	// A failure on a later boot is counted again.
+	require.True(t, RecordTPMUnlockFailure(s, time.Now().Add(time.Minute)))
+	require.Equal(t, 2, s.System.Security.State.TPMUnlockFailures)
+}
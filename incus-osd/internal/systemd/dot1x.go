@@ -0,0 +1,113 @@
+package systemd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// wiredSupplicantPrefix is prepended to the wpa_supplicant configuration names of wired interfaces,
+// keeping them apart from the Wi-Fi ones.
+const wiredSupplicantPrefix = "wired-"
+
+// applyWiredAuthConfiguration writes the wpa_supplicant configuration for each wired interface using
+// 802.1X authentication and (re)starts the matching wpa_supplicant-wired@ unit, stopping any unit no
+// longer configured.
+func applyWiredAuthConfiguration(ctx context.Context, networkCfg *api.SystemNetworkConfig) error {
+	err := os.MkdirAll(WPASupplicantConfigPath, 0o700)
+	if err != nil {
+		return err
+	}
+
+	// Authentication happens on the physical device, not on the host-side bridge.
+	devices := map[string]api.SystemNetworkInterface{}
+
+	for _, i := range networkCfg.Interfaces {
+		if i.EAP != nil {
+			devices["_p"+strings.ToLower(strings.ReplaceAll(i.Hwaddr, ":", ""))] = i
+		}
+	}
+
+	// Stop and remove any wired interface that doesn't use 802.1X anymore.
+	entries, err := os.ReadDir(WPASupplicantConfigPath)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		device, ok := strings.CutPrefix(entry.Name(), "wpa_supplicant-"+wiredSupplicantPrefix)
+		if !ok {
+			continue
+		}
+
+		device, ok = strings.CutSuffix(device, ".conf")
+		if !ok {
+			continue
+		}
+
+		_, ok = devices[device]
+		if ok {
+			continue
+		}
+
+		err := StopUnit(ctx, "wpa_supplicant-wired@"+device+".service")
+		if err != nil {
+			return err
+		}
+
+		err = removeSupplicantFiles(wiredSupplicantPrefix + device)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Write the configuration and (re)start wpa_supplicant for each wired interface.
+	names := make([]string, 0, len(devices))
+	for device := range devices {
+		names = append(names, device)
+	}
+
+	slices.Sort(names)
+
+	for _, device := range names {
+		name := wiredSupplicantPrefix + device
+
+		err := removeSupplicantFiles(name)
+		if err != nil {
+			return err
+		}
+
+		err = writeEAPFiles(name, devices[device].EAP)
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(filepath.Join(WPASupplicantConfigPath, "wpa_supplicant-"+name+".conf"), []byte(generateWiredSupplicantContents(name, devices[device].EAP)), 0o600)
+		if err != nil {
+			return err
+		}
+
+		err = RestartUnit(ctx, "wpa_supplicant-wired@"+device+".service")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// generateWiredSupplicantContents generates the contents of the wpa_supplicant configuration for a wired interface.
+func generateWiredSupplicantContents(name string, eap *api.SystemNetworkEAP) string {
+	var ret strings.Builder
+
+	// Wired authentication doesn't scan for networks, the single network block always applies.
+	_, _ = ret.WriteString("ctrl_interface=/run/wpa_supplicant\nap_scan=0\n\nnetwork={\n\tkey_mgmt=IEEE8021X\n")
+	_, _ = ret.WriteString(generateEAPContents(name, eap))
+	_, _ = ret.WriteString("}\n")
+
+	return ret.String()
+}
@@ -0,0 +1,203 @@
+package systemd
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+// failoverRouteMetric is the metric of the default route installed through the active uplink. It
+// takes precedence over the default routes configured by systemd-networkd, which are left untouched.
+const failoverRouteMetric = 10
+
+// RunUplinkFailover periodically checks the health of the configured failover uplinks, sending the
+// default traffic through the first healthy one. It only returns once the context is cancelled.
+func RunUplinkFailover(ctx context.Context, s *state.State) {
+	failures := map[string]int{}
+	active := ""
+
+	for {
+		interval := 10 * time.Second
+
+		var failoverCfg *api.SystemNetworkFailover
+		if s.System.Network.Config != nil {
+			failoverCfg = s.System.Network.Config.Failover
+		}
+
+		if failoverCfg != nil {
+			if failoverCfg.Interval > 0 {
+				interval = time.Duration(failoverCfg.Interval) * time.Second
+			}
+
+			active = checkUplinks(ctx, s, failoverCfg, failures, active)
+		} else if active != "" {
+			// Failover was disabled, go back to the regular default routes.
+			clearFailoverRoutes(ctx)
+
+			active = ""
+			s.System.Network.State.Failover = nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// checkUplinks updates the health of each uplink, routes the default traffic through the first
+// healthy one and returns its name.
+func checkUplinks(ctx context.Context, s *state.State, failoverCfg *api.SystemNetworkFailover, failures map[string]int, active string) string {
+	threshold := failoverCfg.Threshold
+	if threshold == 0 {
+		threshold = 3
+	}
+
+	uplinkState := &api.SystemNetworkFailoverState{
+		Uplinks: map[string]string{},
+	}
+
+	for _, uplink := range failoverCfg.Uplinks {
+		if isUplinkReachable(ctx, resolveBridge(uplink), failoverCfg.Targets) {
+			failures[uplink] = 0
+		} else {
+			failures[uplink]++
+		}
+
+		if failures[uplink] >= threshold {
+			uplinkState.Uplinks[uplink] = "down"
+		} else {
+			uplinkState.Uplinks[uplink] = "up"
+
+			if uplinkState.ActiveUplink == "" {
+				uplinkState.ActiveUplink = uplink
+			}
+		}
+	}
+
+	if uplinkState.ActiveUplink != active {
+		slog.WarnContext(ctx, "Switching active uplink", "previous", active, "active", uplinkState.ActiveUplink)
+	}
+
+	// Only record a failover once an uplink was previously selected.
+	previousState := s.System.Network.State.Failover
+	if previousState != nil {
+		uplinkState.LastFailover = getLastFailover(previousState.LastFailover, previousState.ActiveUplink, uplinkState.ActiveUplink, time.Now())
+	}
+
+	// Re-apply the routes on every check, as a network reconfiguration would have flushed them.
+	if uplinkState.ActiveUplink == "" {
+		clearFailoverRoutes(ctx)
+	} else {
+		err := setFailoverRoutes(ctx, resolveBridge(uplinkState.ActiveUplink))
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to route traffic through the active uplink", "uplink", uplinkState.ActiveUplink, "err", err)
+		}
+	}
+
+	s.System.Network.State.Failover = uplinkState
+
+	return uplinkState.ActiveUplink
+}
+
+// isUplinkReachable returns true if any of the targets replies to a ping sent through the device.
+func isUplinkReachable(ctx context.Context, device string, targets []string) bool {
+	for _, target := range targets {
+		_, err := subprocess.RunCommandContext(ctx, "ping", "-n", "-q", "-c", "1", "-W", "2", "-I", device, target)
+		if err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setFailoverRoutes installs a preferred default route for each address family, using the gateway
+// systemd-networkd configured on the device.
+func setFailoverRoutes(ctx context.Context, device string) error {
+	for _, family := range []string{"-4", "-6"} {
+		gateway, found, err := getDefaultGateway(ctx, family, device)
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			_, _ = subprocess.RunCommandContext(ctx, "ip", family, "route", "del", "default", "metric", strconv.Itoa(failoverRouteMetric))
+
+			continue
+		}
+
+		args := []string{family, "route", "replace", "default"}
+		if gateway != "" {
+			args = append(args, "via", gateway)
+		}
+
+		args = append(args, "dev", device, "metric", strconv.Itoa(failoverRouteMetric))
+
+		_, err = subprocess.RunCommandContext(ctx, "ip", args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// clearFailoverRoutes removes any preferred default route.
+func clearFailoverRoutes(ctx context.Context) {
+	for _, family := range []string{"-4", "-6"} {
+		_, _ = subprocess.RunCommandContext(ctx, "ip", family, "route", "del", "default", "metric", strconv.Itoa(failoverRouteMetric))
+	}
+}
+
+// getDefaultGateway returns the gateway of the regular default route through the device, if any.
+// Point-to-point devices have a default route without a gateway.
+func getDefaultGateway(ctx context.Context, family string, device string) (string, bool, error) {
+	output, err := subprocess.RunCommandContext(ctx, "ip", "-j", family, "route", "show", "default", "dev", device)
+	if err != nil {
+		return "", false, err
+	}
+
+	return parseDefaultGateway(output)
+}
+
+// parseDefaultGateway parses the JSON output of "ip route show default", ignoring the failover route.
+func parseDefaultGateway(output string) (string, bool, error) {
+	routes := []struct {
+		Gateway string `json:"gateway"`
+		Metric  int    `json:"metric"`
+	}{}
+
+	err := json.Unmarshal([]byte(output), &routes)
+	if err != nil {
+		return "", false, err
+	}
+
+	for _, route := range routes {
+		if route.Metric == failoverRouteMetric {
+			continue
+		}
+
+		return route.Gateway, true, nil
+	}
+
+	return "", false, nil
+}
+
+// getLastFailover returns the last failover event, recording a new one if the active uplink or bond
+// member changed from the previous one.
+func getLastFailover(last *api.SystemNetworkFailoverEvent, previous string, current string, now time.Time) *api.SystemNetworkFailoverEvent {
+	if previous == current {
+		return last
+	}
+
+	return &api.SystemNetworkFailoverEvent{Time: now.UTC(), From: previous, To: current}
+}
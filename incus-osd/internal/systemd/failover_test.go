@@ -0,0 +1,66 @@
+package systemd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+func TestGetLastFailover(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 10, 15, 9, 12, 44, 0, time.UTC)
+	last := &api.SystemNetworkFailoverEvent{Time: now.Add(-time.Hour), From: "eth0", To: "eth1"}
+
+	cases := []struct {
+		name     string
+		last     *api.SystemNetworkFailoverEvent
+		previous string
+		current  string
+		expected *api.SystemNetworkFailoverEvent
+	}{
+		{
+			name:     "No change",
+			previous: "eth0",
+			current:  "eth0",
+			expected: nil,
+		},
+		{
+			name:     "No change keeps the last event",
+			last:     last,
+			previous: "eth1",
+			current:  "eth1",
+			expected: last,
+		},
+		{
+			name:     "Failover",
+			previous: "eth0",
+			current:  "eth1",
+			expected: &api.SystemNetworkFailoverEvent{Time: now, From: "eth0", To: "eth1"},
+		},
+		{
+			name:     "Failback replaces the last event",
+			last:     last,
+			previous: "eth1",
+			current:  "eth0",
+			expected: &api.SystemNetworkFailoverEvent{Time: now, From: "eth1", To: "eth0"},
+		},
+		{
+			name:     "All uplinks down",
+			previous: "eth0",
+			current:  "",
+			expected: &api.SystemNetworkFailoverEvent{Time: now, From: "eth0", To: ""},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.expected, getLastFailover(tc.last, tc.previous, tc.current, now))
+		})
+	}
+}
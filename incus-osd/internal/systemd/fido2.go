@@ -0,0 +1,139 @@
+package systemd
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+	"github.com/lxc/incus-os/incus-osd/internal/util"
+)
+
+// EnrollFIDO2Token utilizes systemd-cryptenroll to add a FIDO2 token as an unlock method for the
+// root and swap LUKS volumes. The volumes are unlocked using the first recovery key, so this
+// doesn't depend on the TPM. The token must be touched once for each volume.
+func EnrollFIDO2Token(ctx context.Context, s *state.State, req *api.SystemSecurityFIDO2Enroll) error {
+	if len(s.System.Security.Config.EncryptionRecoveryKeys) == 0 {
+		return errors.New("no recovery key available to unlock the encrypted volumes")
+	}
+
+	device := req.Device
+	if device == "" {
+		device = "auto"
+	}
+
+	// Get the underlying LUKS partitions.
+	luksVolumes, err := util.GetLUKSVolumePartitions()
+	if err != nil {
+		return err
+	}
+
+	env := append(os.Environ(), "PASSWORD="+s.System.Security.Config.EncryptionRecoveryKeys[0])
+	if req.PIN != "" {
+		env = append(env, "PIN="+req.PIN)
+	}
+
+	args := []string{
+		"--fido2-device=" + device,
+		"--fido2-with-client-pin=" + strconv.FormatBool(req.PIN != ""),
+		"--fido2-with-user-presence=true",
+		"--fido2-with-user-verification=" + strconv.FormatBool(req.UserVerification),
+	}
+
+	for _, volumeName := range []string{"root", "swap"} {
+		_, _, err := subprocess.RunCommandSplit(ctx, env, nil, "systemd-cryptenroll", append(args, luksVolumes[volumeName])...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RemoveFIDO2Tokens utilizes systemd-cryptenroll to remove all enrolled FIDO2 tokens from the
+// root and swap LUKS volumes.
+func RemoveFIDO2Tokens(ctx context.Context, s *state.State) error {
+	if len(s.System.Security.Config.EncryptionRecoveryKeys) == 0 {
+		return errors.New("no recovery key available to unlock the encrypted volumes")
+	}
+
+	// Get the underlying LUKS partitions.
+	luksVolumes, err := util.GetLUKSVolumePartitions()
+	if err != nil {
+		return err
+	}
+
+	for _, volume := range luksVolumes {
+		_, _, err := subprocess.RunCommandSplit(ctx, append(os.Environ(), "PASSWORD="+s.System.Security.Config.EncryptionRecoveryKeys[0]), nil, "systemd-cryptenroll", "--wipe-slot=fido2", volume)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListFIDO2Tokens returns the FIDO2 tokens enrolled on each encrypted volume.
+func ListFIDO2Tokens(ctx context.Context) ([]api.SystemSecurityFIDO2Token, error) {
+	ret := []api.SystemSecurityFIDO2Token{}
+
+	// Get the LUKS partitions.
+	luksVolumes, err := util.GetLUKSVolumePartitions()
+	if err != nil {
+		return ret, err
+	}
+
+	for _, volumeName := range slices.Sorted(maps.Keys(luksVolumes)) {
+		luksMeta, err := getLUKSMetadata(ctx, luksVolumes[volumeName])
+		if err != nil {
+			return ret, err
+		}
+
+		tokens, err := parseFIDO2Tokens(volumeName, luksMeta)
+		if err != nil {
+			return ret, err
+		}
+
+		ret = append(ret, tokens...)
+	}
+
+	return ret, nil
+}
+
+// parseFIDO2Tokens extracts the FIDO2 tokens from the LUKS2 JSON metadata of a volume.
+func parseFIDO2Tokens(volumeName string, luksMeta *luksMetadata) ([]api.SystemSecurityFIDO2Token, error) {
+	ret := []api.SystemSecurityFIDO2Token{}
+
+	for _, token := range luksMeta.Tokens {
+		if token.Type != "systemd-fido2" {
+			continue
+		}
+
+		for _, keyslot := range token.Keyslots {
+			slot, err := strconv.Atoi(keyslot)
+			if err != nil {
+				return nil, err
+			}
+
+			ret = append(ret, api.SystemSecurityFIDO2Token{
+				Volume:                   volumeName,
+				Slot:                     slot,
+				ClientPINRequired:        token.FIDO2ClientPINRequired,
+				UserPresenceRequired:     token.FIDO2UserPresenceRequired,
+				UserVerificationRequired: token.FIDO2UserVerifyRequired,
+			})
+		}
+	}
+
+	slices.SortFunc(ret, func(a api.SystemSecurityFIDO2Token, b api.SystemSecurityFIDO2Token) int {
+		return a.Slot - b.Slot
+	})
+
+	return ret, nil
+}
@@ -0,0 +1,30 @@
+package systemd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+func TestFIDO2Tokens(t *testing.T) {
+	t.Parallel()
+
+	luksMeta := &luksMetadata{}
+
+	err := json.Unmarshal([]byte(luksJSONMetadata), luksMeta)
+	require.NoError(t, err)
+
+	tokens, err := parseFIDO2Tokens("root", luksMeta)
+	require.NoError(t, err)
+	require.Equal(t, []api.SystemSecurityFIDO2Token{
+		{Volume: "root", Slot: 2, UserPresenceRequired: true, UserVerificationRequired: true},
+		{Volume: "root", Slot: 3, ClientPINRequired: true, UserPresenceRequired: true},
+	}, tokens)
+
+	tokens, err = parseFIDO2Tokens("swap", &luksMetadata{})
+	require.NoError(t, err)
+	require.Empty(t, tokens)
+}
@@ -0,0 +1,185 @@
+package systemd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"slices"
+	"time"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+// fwupdDevices is the JSON output of fwupdmgr, listing devices and possibly their releases.
+type fwupdDevices struct {
+	Devices []struct {
+		DeviceID string   `json:"DeviceId"`
+		Name     string   `json:"Name"`
+		Vendor   string   `json:"Vendor"`
+		Version  string   `json:"Version"`
+		Flags    []string `json:"Flags"`
+		Releases []struct {
+			Version string `json:"Version"`
+			Summary string `json:"Summary"`
+			Urgency string `json:"Urgency"`
+		} `json:"Releases"`
+	} `json:"Devices"`
+}
+
+// RefreshFirmware downloads the latest firmware metadata from the LVFS and records the updatable devices,
+// along with their available updates.
+func RefreshFirmware(ctx context.Context, s *state.State) error {
+	s.System.Firmware.State.LastCheck = time.Now()
+
+	_, err := runFwupdmgr(ctx, "refresh", "--force")
+	if err != nil {
+		return err
+	}
+
+	devices, err := GetFirmwareDevices(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.System.Firmware.State.Devices = devices
+
+	return nil
+}
+
+// GetFirmwareDevices returns the updatable devices, along with their available updates.
+func GetFirmwareDevices(ctx context.Context) ([]api.SystemFirmwareDevice, error) {
+	devices, err := runFwupdmgr(ctx, "get-devices")
+	if err != nil {
+		return nil, err
+	}
+
+	// fwupdmgr exits with status 2 when no update is available.
+	updates, err := runFwupdmgr(ctx, "get-updates")
+	if err != nil {
+		exitErr := &exec.ExitError{}
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 2 {
+			return nil, err
+		}
+
+		updates = ""
+	}
+
+	return parseFirmwareDevices(devices, updates)
+}
+
+// ApplyFirmwareUpdates applies the pending firmware updates, or all the available ones if configured to,
+// then schedules the reboot finalizing them according to the update reboot policy.
+func ApplyFirmwareUpdates(ctx context.Context, s *state.State) error {
+	pending := s.System.Firmware.State.PendingDevices
+
+	if s.System.Firmware.Config.AutoApply {
+		for _, device := range s.System.Firmware.State.Devices {
+			if len(device.Updates) > 0 && !slices.Contains(pending, device.ID) {
+				pending = append(pending, device.ID)
+			}
+		}
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	// Don't retry failed updates, which are still reported as available.
+	s.System.Firmware.State.PendingDevices = []string{}
+
+	for _, id := range pending {
+		index := slices.IndexFunc(s.System.Firmware.State.Devices, func(device api.SystemFirmwareDevice) bool { return device.ID == id })
+		if index < 0 || len(s.System.Firmware.State.Devices[index].Updates) == 0 {
+			continue
+		}
+
+		device := s.System.Firmware.State.Devices[index]
+
+		slog.InfoContext(ctx, "Applying firmware update", "device", device.Name, "version", device.Updates[0].Version)
+
+		_, err := runFwupdmgr(ctx, "update", id)
+		if err != nil {
+			return fmt.Errorf("failed to update the firmware of %q: %w", device.Name, err)
+		}
+	}
+
+	devices, err := GetFirmwareDevices(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.System.Firmware.State.Devices = devices
+
+	// Some updates, like UEFI capsules, are only applied by the firmware during the next boot.
+	if slices.ContainsFunc(devices, func(device api.SystemFirmwareDevice) bool { return device.NeedsReboot }) {
+		s.System.Update.State.NeedsReboot = true
+
+		ScheduleUpdateReboot(ctx, s)
+	}
+
+	return nil
+}
+
+// parseFirmwareDevices combines the updatable devices with their available updates, newest first.
+func parseFirmwareDevices(devicesOutput string, updatesOutput string) ([]api.SystemFirmwareDevice, error) {
+	devices := fwupdDevices{}
+
+	err := json.Unmarshal([]byte(devicesOutput), &devices)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := fwupdDevices{}
+
+	if updatesOutput != "" {
+		err := json.Unmarshal([]byte(updatesOutput), &updates)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ret := []api.SystemFirmwareDevice{}
+
+	for _, device := range devices.Devices {
+		needsReboot := slices.Contains(device.Flags, "needs-reboot")
+		if !slices.Contains(device.Flags, "updatable") && !needsReboot {
+			continue
+		}
+
+		entry := api.SystemFirmwareDevice{
+			ID:          device.DeviceID,
+			Name:        device.Name,
+			Vendor:      device.Vendor,
+			Version:     device.Version,
+			NeedsReboot: needsReboot,
+			Updates:     []api.SystemFirmwareUpdate{},
+		}
+
+		for _, update := range updates.Devices {
+			if update.DeviceID != device.DeviceID {
+				continue
+			}
+
+			for _, release := range update.Releases {
+				entry.Updates = append(entry.Updates, api.SystemFirmwareUpdate{Version: release.Version, Summary: release.Summary, Urgency: release.Urgency})
+			}
+		}
+
+		ret = append(ret, entry)
+	}
+
+	return ret, nil
+}
+
+// runFwupdmgr runs a non-interactive fwupdmgr command, leaving any reboot to the update reboot policy.
+func runFwupdmgr(ctx context.Context, args ...string) (string, error) {
+	args = append(args, "--json", "--assume-yes", "--no-reboot-check", "--no-unreported-check", "--no-metadata-check")
+
+	return subprocess.RunCommandContext(ctx, "fwupdmgr", args...)
+}
@@ -0,0 +1,47 @@
+package systemd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+func TestParseFirmwareDevices(t *testing.T) {
+	t.Parallel()
+
+	devices := `{"Devices":[
+		{"Name":"System Firmware","DeviceId":"a45df35ac0e948ee180fe216a5f703f32dda163f","Vendor":"Example","Version":"1.10","Flags":["internal","updatable","require-ac","needs-reboot"]},
+		{"Name":"Samsung SSD 980 PRO 1TB","DeviceId":"71b677ca0f1bc2c5b804fa1d59e52064ce589293","Vendor":"Samsung","Version":"5B2QGXA7","Flags":["internal","updatable"]},
+		{"Name":"TPM","DeviceId":"c6a80ac3a22083423992a3cb15018989f37834d6","Vendor":"Infineon","Version":"7.85","Flags":["internal"]}
+	]}`
+
+	updates := `{"Devices":[
+		{"Name":"Samsung SSD 980 PRO 1TB","DeviceId":"71b677ca0f1bc2c5b804fa1d59e52064ce589293","Releases":[
+			{"Version":"5B2QGXA8","Summary":"Firmware for the Samsung 980 PRO","Urgency":"high"},
+			{"Version":"5B2QGXA7","Summary":"Firmware for the Samsung 980 PRO"}
+		]}
+	]}`
+
+	expected := []api.SystemFirmwareDevice{
+		{ID: "a45df35ac0e948ee180fe216a5f703f32dda163f", Name: "System Firmware", Vendor: "Example", Version: "1.10", NeedsReboot: true, Updates: []api.SystemFirmwareUpdate{}},
+		{ID: "71b677ca0f1bc2c5b804fa1d59e52064ce589293", Name: "Samsung SSD 980 PRO 1TB", Vendor: "Samsung", Version: "5B2QGXA7", Updates: []api.SystemFirmwareUpdate{
+			{Version: "5B2QGXA8", Summary: "Firmware for the Samsung 980 PRO", Urgency: "high"},
+			{Version: "5B2QGXA7", Summary: "Firmware for the Samsung 980 PRO"},
+		}},
+	}
+
+	ret, err := parseFirmwareDevices(devices, updates)
+	require.NoError(t, err)
+	require.Equal(t, expected, ret)
+
+	// No update available.
+	ret, err = parseFirmwareDevices(devices, "")
+	require.NoError(t, err)
+	require.Len(t, ret, 2)
+	require.Empty(t, ret[1].Updates)
+
+	_, err = parseFirmwareDevices("invalid", "")
+	require.Error(t, err)
+}
@@ -0,0 +1,164 @@
+package systemd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+	"github.com/lxc/incus-os/incus-osd/internal/util"
+)
+
+// ValidateNBDEConfiguration checks that the network-bound disk encryption configuration is usable.
+func ValidateNBDEConfiguration(cfg *api.SystemSecurityNBDE) error {
+	if cfg == nil {
+		return nil
+	}
+
+	if len(cfg.Servers) == 0 {
+		return errors.New("at least one Tang server must be provided")
+	}
+
+	for index, server := range cfg.Servers {
+		serverURL, err := url.Parse(server.URL)
+		if err != nil {
+			return fmt.Errorf("server %d has invalid URL: %w", index, err)
+		}
+
+		if serverURL.Scheme != "http" && serverURL.Scheme != "https" {
+			return fmt.Errorf("server %d URL must use http or https", index)
+		}
+
+		if server.Thumbprint == "" {
+			return fmt.Errorf("server %d is missing its key thumbprint", index)
+		}
+	}
+
+	if cfg.Threshold < 0 || cfg.Threshold > len(cfg.Servers) {
+		return fmt.Errorf("threshold must be between 1 and the number of Tang servers (%d)", len(cfg.Servers))
+	}
+
+	return nil
+}
+
+// ApplyNBDEConfiguration utilizes clevis to bind the root and swap LUKS volumes to the configured
+// Tang servers, replacing any existing binding. A nil configuration removes the binding.
+func ApplyNBDEConfiguration(ctx context.Context, s *state.State, cfg *api.SystemSecurityNBDE) error {
+	if len(s.System.Security.Config.EncryptionRecoveryKeys) == 0 {
+		return errors.New("no recovery key available to unlock the encrypted volumes")
+	}
+
+	err := ValidateNBDEConfiguration(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Get the underlying LUKS partitions.
+	luksVolumes, err := util.GetLUKSVolumePartitions()
+	if err != nil {
+		return err
+	}
+
+	for name, volume := range luksVolumes {
+		// Remove any existing binding.
+		output, err := subprocess.RunCommandContext(ctx, "clevis", "luks", "list", "-d", volume)
+		if err != nil {
+			return err
+		}
+
+		slots, err := parseClevisSlots(output)
+		if err != nil {
+			return err
+		}
+
+		for _, slot := range slots {
+			_, err := subprocess.RunCommandContext(ctx, "clevis", "luks", "unbind", "-f", "-d", volume, "-s", strconv.Itoa(slot))
+			if err != nil {
+				return err
+			}
+		}
+
+		// The root volume is unlocked from the initrd, which has no network access to reach the Tang servers.
+		if cfg == nil || name == "root" {
+			continue
+		}
+
+		// Bind to the Tang servers, passing the recovery key on stdin.
+		clevisCfg, err := generateClevisConfig(cfg)
+		if err != nil {
+			return err
+		}
+
+		err = subprocess.RunCommandWithFds(ctx, strings.NewReader(s.System.Security.Config.EncryptionRecoveryKeys[0]), nil, "clevis", "luks", "bind", "-y", "-k", "-", "-d", volume, "sss", clevisCfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.System.Security.Config.NBDE = cfg
+
+	return nil
+}
+
+// generateClevisConfig returns the clevis Shamir Secret Sharing configuration requiring the
+// threshold number of Tang servers to be reachable.
+func generateClevisConfig(cfg *api.SystemSecurityNBDE) (string, error) {
+	type tangPin struct {
+		URL        string `json:"url"`
+		Thumbprint string `json:"thp"`
+	}
+
+	type sssConfig struct {
+		Threshold int                  `json:"t"`
+		Pins      map[string][]tangPin `json:"pins"`
+	}
+
+	threshold := cfg.Threshold
+	if threshold == 0 {
+		threshold = 1
+	}
+
+	sss := sssConfig{
+		Threshold: threshold,
+		Pins:      map[string][]tangPin{"tang": {}},
+	}
+
+	for _, server := range cfg.Servers {
+		sss.Pins["tang"] = append(sss.Pins["tang"], tangPin{URL: server.URL, Thumbprint: server.Thumbprint})
+	}
+
+	ret, err := json.Marshal(sss)
+	if err != nil {
+		return "", err
+	}
+
+	return string(ret), nil
+}
+
+// parseClevisSlots returns the LUKS key slots bound by clevis from the output of "clevis luks list".
+func parseClevisSlots(output string) ([]int, error) {
+	slots := []int{}
+
+	for line := range strings.SplitSeq(output, "\n") {
+		slotStr, _, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+
+		slot, err := strconv.Atoi(strings.TrimSpace(slotStr))
+		if err != nil {
+			return nil, fmt.Errorf("unexpected clevis output %q", line)
+		}
+
+		slots = append(slots, slot)
+	}
+
+	return slots, nil
+}
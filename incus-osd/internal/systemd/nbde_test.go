@@ -0,0 +1,49 @@
+package systemd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+func TestNBDE(t *testing.T) {
+	t.Parallel()
+
+	// Validation.
+	require.NoError(t, ValidateNBDEConfiguration(nil))
+	require.Error(t, ValidateNBDEConfiguration(&api.SystemSecurityNBDE{}))
+	require.Error(t, ValidateNBDEConfiguration(&api.SystemSecurityNBDE{Servers: []api.SystemSecurityTangServer{{URL: "tang.example.com", Thumbprint: "abc"}}}))
+	require.Error(t, ValidateNBDEConfiguration(&api.SystemSecurityNBDE{Servers: []api.SystemSecurityTangServer{{URL: "http://tang.example.com"}}}))
+	require.Error(t, ValidateNBDEConfiguration(&api.SystemSecurityNBDE{Servers: []api.SystemSecurityTangServer{{URL: "http://tang.example.com", Thumbprint: "abc"}}, Threshold: 2}))
+
+	cfg := &api.SystemSecurityNBDE{
+		Servers: []api.SystemSecurityTangServer{
+			{URL: "http://tang1.example.com", Thumbprint: "abc"},
+			{URL: "https://tang2.example.com", Thumbprint: "def"},
+		},
+	}
+
+	require.NoError(t, ValidateNBDEConfiguration(cfg))
+
+	// Clevis configuration, defaulting to a single server being required.
+	clevisCfg, err := generateClevisConfig(cfg)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"t":1,"pins":{"tang":[{"url":"http://tang1.example.com","thp":"abc"},{"url":"https://tang2.example.com","thp":"def"}]}}`, clevisCfg)
+
+	cfg.Threshold = 2
+
+	clevisCfg, err = generateClevisConfig(cfg)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"t":2,"pins":{"tang":[{"url":"http://tang1.example.com","thp":"abc"},{"url":"https://tang2.example.com","thp":"def"}]}}`, clevisCfg)
+
+	// Bound slots.
+	slots, err := parseClevisSlots("1: sss '{\"t\":1,\"pins\":{\"tang\":[{\"url\":\"http://tang1.example.com\"}]}}'\n")
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, slots)
+
+	slots, err = parseClevisSlots("")
+	require.NoError(t, err)
+	require.Empty(t, slots)
+}
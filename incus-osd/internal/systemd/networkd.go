@@ -61,7 +61,7 @@ func ApplyNetworkConfiguration(ctx context.Context, s *state.State, networkCfg *
 		return err
 	}
 
-	// Delete any interfaces, bonds, or vlans that currently exist but don't in
+	// Delete any interfaces, bonds, bridges, or vlans that currently exist but don't in
 	// the new configuration, or have a different configuration.
 	err = cleanupStaleDevices(ctx, s.System.Network.Config, networkCfg)
 	if err != nil {
@@ -115,12 +115,36 @@ func ApplyNetworkConfiguration(ctx context.Context, s *state.State, networkCfg *
 		return err
 	}
 
+	// Refresh the host firewall for the management interfaces.
+	err = nftables.ApplyHostFirewall(ctx, s)
+	if err != nil {
+		return err
+	}
+
 	// Restart networking after new config files have been generated.
 	err = RestartUnit(ctx, "systemd-networkd")
 	if err != nil {
 		return err
 	}
 
+	// Connect any Wi-Fi interface.
+	err = applyWifiConfiguration(ctx, networkCfg)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate any wired interface using 802.1X.
+	err = applyWiredAuthConfiguration(ctx, networkCfg)
+	if err != nil {
+		return err
+	}
+
+	// Start any PPPoE session.
+	err = applyPPPoEConfiguration(ctx, networkCfg)
+	if err != nil {
+		return err
+	}
+
 	// Wait for the network to apply.
 	err = waitForNetworkOnline(ctx, networkCfg, timeout)
 	if err != nil {
@@ -137,17 +161,23 @@ func ApplyNetworkConfiguration(ctx context.Context, s *state.State, networkCfg *
 		slog.WarnContext(ctx, "DNS check failed, system may have trouble resolving hostnames")
 	}
 
-	// (Re)start NTP time synchronization. Since we might be overriding the default fallback NTP servers,
+	// Provide the NTP servers learned from the network to chrony.
+	err = updateChronySources(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to update the NTP servers learned from the network", "err", err)
+	}
+
+	// (Re)start NTP time synchronization. Since we might be overriding the default NTP servers,
 	// the service is disabled by default and only started once we have performed the network (re)configuration.
-	err = RestartUnit(ctx, "systemd-timesyncd")
+	err = RestartUnit(ctx, "chrony")
 	if err != nil {
 		return err
 	}
 
 	// Wait up to 30 seconds for NTP synchronization, but don't fail if it doesn't happen.
-	err = waitForSystemdTimesyncd(ctx, 30*time.Second)
+	err = waitForTimeSync(ctx, 30*time.Second)
 	if err != nil {
-		slog.WarnContext(ctx, "systemd-timesyncd failed to perform NTP synchronization, system time may be incorrect")
+		slog.WarnContext(ctx, "chrony failed to perform NTP synchronization, system time may be incorrect")
 	}
 
 	// Refresh the state struct.
@@ -183,11 +213,11 @@ func ValidateNetworkConfiguration(networkCfg *api.SystemNetworkConfig, requireVa
 		return errors.New("no network configuration provided")
 	}
 
-	// Check that all interface/bond/vlan names are unique.
+	// Check that all interface/bond/bridge/vlan names are unique.
 	names := []string{}
 	for _, iface := range networkCfg.Interfaces {
 		if slices.Contains(names, iface.Name) {
-			return errors.New("duplicate interface/bond/vlan/wireguard name: " + iface.Name)
+			return errors.New("duplicate interface/bond/bridge/vlan/wifi/pppoe/wireguard/tunnel name: " + iface.Name)
 		}
 
 		names = append(names, iface.Name)
@@ -195,28 +225,60 @@ func ValidateNetworkConfiguration(networkCfg *api.SystemNetworkConfig, requireVa
 
 	for _, bond := range networkCfg.Bonds {
 		if slices.Contains(names, bond.Name) {
-			return errors.New("duplicate interface/bond/vlan/wireguard name: " + bond.Name)
+			return errors.New("duplicate interface/bond/bridge/vlan/wifi/pppoe/wireguard/tunnel name: " + bond.Name)
 		}
 
 		names = append(names, bond.Name)
 	}
 
+	for _, bridge := range networkCfg.Bridges {
+		if slices.Contains(names, bridge.Name) {
+			return errors.New("duplicate interface/bond/bridge/vlan/wifi/pppoe/wireguard/tunnel name: " + bridge.Name)
+		}
+
+		names = append(names, bridge.Name)
+	}
+
 	for _, vlan := range networkCfg.VLANs {
 		if slices.Contains(names, vlan.Name) {
-			return errors.New("duplicate interface/bond/vlan/wireguard name: " + vlan.Name)
+			return errors.New("duplicate interface/bond/bridge/vlan/wifi/pppoe/wireguard/tunnel name: " + vlan.Name)
 		}
 
 		names = append(names, vlan.Name)
 	}
 
+	for _, wifi := range networkCfg.Wifi {
+		if slices.Contains(names, wifi.Name) {
+			return errors.New("duplicate interface/bond/bridge/vlan/wifi/pppoe/wireguard/tunnel name: " + wifi.Name)
+		}
+
+		names = append(names, wifi.Name)
+	}
+
+	for _, pppoe := range networkCfg.PPPoE {
+		if slices.Contains(names, pppoe.Name) {
+			return errors.New("duplicate interface/bond/bridge/vlan/wifi/pppoe/wireguard/tunnel name: " + pppoe.Name)
+		}
+
+		names = append(names, pppoe.Name)
+	}
+
 	for _, wg := range networkCfg.Wireguard {
 		if slices.Contains(names, wg.Name) {
-			return errors.New("duplicate interface/bond/vlan/wireguard name: " + wg.Name)
+			return errors.New("duplicate interface/bond/bridge/vlan/wifi/pppoe/wireguard/tunnel name: " + wg.Name)
 		}
 
 		names = append(names, wg.Name)
 	}
 
+	for _, tunnel := range networkCfg.Tunnels {
+		if slices.Contains(names, tunnel.Name) {
+			return errors.New("duplicate interface/bond/bridge/vlan/wifi/pppoe/wireguard/tunnel name: " + tunnel.Name)
+		}
+
+		names = append(names, tunnel.Name)
+	}
+
 	// Some USB NICs have a default name of "enx<MAC>", which is 15 characters long.
 	// To work around this, strip the leading "enx" before validating network interfaces.
 	mangleUSBNICs(networkCfg)
@@ -231,16 +293,61 @@ func ValidateNetworkConfiguration(networkCfg *api.SystemNetworkConfig, requireVa
 		return err
 	}
 
+	err = validateBridges(networkCfg, requireValidMAC)
+	if err != nil {
+		return err
+	}
+
 	err = validateVLANs(networkCfg)
 	if err != nil {
 		return err
 	}
 
+	err = validateWifi(networkCfg, requireValidMAC)
+	if err != nil {
+		return err
+	}
+
 	err = validateWireguard(networkCfg)
 	if err != nil {
 		return err
 	}
 
+	err = validatePPPoE(networkCfg)
+	if err != nil {
+		return err
+	}
+
+	err = validateTunnels(networkCfg)
+	if err != nil {
+		return err
+	}
+
+	err = validateDHCPServer(networkCfg)
+	if err != nil {
+		return err
+	}
+
+	err = validateIPv6(networkCfg)
+	if err != nil {
+		return err
+	}
+
+	err = validateDNS(networkCfg)
+	if err != nil {
+		return err
+	}
+
+	err = validateTime(networkCfg)
+	if err != nil {
+		return err
+	}
+
+	err = validateFailover(networkCfg)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -252,9 +359,12 @@ func UpdateNetworkState(ctx context.Context, n *api.SystemNetwork) error {
 		return errors.New("no network configuration defined")
 	}
 
-	// Clear any existing state.
+	// Clear any existing state, keeping track of the previous one to detect bond failovers.
+	previousState := n.State
 	n.State = api.SystemNetworkState{
-		Interfaces: make(map[string]api.SystemNetworkInterfaceState),
+		ConfirmationDeadline: previousState.ConfirmationDeadline,
+		Failover:             previousState.Failover,
+		Interfaces:           make(map[string]api.SystemNetworkInterfaceState),
 	}
 
 	// Keep track of all the roles being applied.
@@ -267,6 +377,7 @@ func UpdateNetworkState(ctx context.Context, n *api.SystemNetwork) error {
 			return err
 		}
 
+		iState.Path = getDevicePath(ctx, "_p"+strings.ToLower(strings.ReplaceAll(i.Hwaddr, ":", "")))
 		iState.Roles = i.Roles
 		rolesFound = append(rolesFound, i.Roles...)
 		n.State.Interfaces[i.Name] = iState
@@ -290,6 +401,55 @@ func UpdateNetworkState(ctx context.Context, n *api.SystemNetwork) error {
 			return err
 		}
 
+		// Get the link monitoring state from the bonding driver.
+		contents, err := os.ReadFile("/proc/net/bonding/_b" + b.Name) //nolint:gosec
+		if err == nil {
+			bondState, memberStates := parseBondingStatus(string(contents))
+
+			previous, ok := previousState.Interfaces[b.Name]
+			if ok && previous.Bond != nil && previous.Bond.ActiveMember != "" {
+				bondState.LastFailover = getLastFailover(previous.Bond.LastFailover, previous.Bond.ActiveMember, bondState.ActiveMember, time.Now())
+				if bondState.ActiveMember != previous.Bond.ActiveMember {
+					slog.WarnContext(ctx, "Bond failover detected", "bond", b.Name, "previous", previous.Bond.ActiveMember, "active", bondState.ActiveMember)
+				}
+			}
+
+			bState.Bond = &bondState
+
+			for mName, mState := range bState.Members {
+				memberState, ok := memberStates[mName]
+				if ok {
+					mState.Bond = &memberState
+					bState.Members[mName] = mState
+				}
+			}
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		bState.Roles = b.Roles
+		rolesFound = append(rolesFound, b.Roles...)
+		n.State.Interfaces[b.Name] = bState
+	}
+
+	// State update for managed bridges.
+	for _, b := range n.Config.Bridges {
+		members := make(map[string]api.SystemNetworkInterfaceState)
+
+		for _, m := range b.Members {
+			mName := "_p" + strings.ToLower(strings.ReplaceAll(m, ":", ""))
+
+			members[mName], err = getInterfaceState(ctx, "bridge_member", mName, m, "", nil)
+			if err != nil {
+				return err
+			}
+		}
+
+		bState, err := getInterfaceState(ctx, "bridge", b.Name, getBridgeHwaddr(b), "", members)
+		if err != nil {
+			return err
+		}
+
 		bState.Roles = b.Roles
 		rolesFound = append(rolesFound, b.Roles...)
 		n.State.Interfaces[b.Name] = bState
@@ -314,6 +474,35 @@ func UpdateNetworkState(ctx context.Context, n *api.SystemNetwork) error {
 		n.State.Interfaces[v.Name] = vState
 	}
 
+	// State update for Wi-Fi.
+	for _, w := range n.Config.Wifi {
+		wState, err := getInterfaceState(ctx, "wifi", w.Name, w.Hwaddr, "", nil)
+		if err != nil {
+			return err
+		}
+
+		wState.Wifi = getWifiState(ctx, w.Name)
+		if wState.Wifi.LinkSpeed > 0 {
+			wState.Speed = strconv.Itoa(wState.Wifi.LinkSpeed)
+		}
+
+		wState.Roles = w.Roles
+		rolesFound = append(rolesFound, w.Roles...)
+		n.State.Interfaces[w.Name] = wState
+	}
+
+	// State update for PPPoE.
+	for _, p := range n.Config.PPPoE {
+		pState, err := getPPPoEState(ctx, p.Name)
+		if err != nil {
+			return err
+		}
+
+		pState.Roles = p.Roles
+		rolesFound = append(rolesFound, p.Roles...)
+		n.State.Interfaces[p.Name] = pState
+	}
+
 	// State update for wireguard.
 	for _, wg := range n.Config.Wireguard {
 		wgState, err := getWireguardState(ctx, wg.Name)
@@ -326,6 +515,18 @@ func UpdateNetworkState(ctx context.Context, n *api.SystemNetwork) error {
 		n.State.Interfaces[wg.Name] = wgState
 	}
 
+	// State update for tunnels.
+	for _, t := range n.Config.Tunnels {
+		tState, err := getInterfaceState(ctx, "tunnel", t.Name, "", "", nil)
+		if err != nil {
+			return err
+		}
+
+		tState.Roles = t.Roles
+		rolesFound = append(rolesFound, t.Roles...)
+		n.State.Interfaces[t.Name] = tState
+	}
+
 	// Ensure required roles exist.
 	if !slices.Contains(rolesFound, api.SystemNetworkInterfaceRoleManagement) || !slices.Contains(rolesFound, api.SystemNetworkInterfaceRoleCluster) {
 		for iName, i := range n.State.Interfaces {
@@ -347,6 +548,16 @@ func UpdateNetworkState(ctx context.Context, n *api.SystemNetwork) error {
 		}
 	}
 
+	// State update for time synchronization, picking up any change to the NTP servers learned from the network.
+	if n.Config.Time == nil || len(n.Config.Time.NTPServers) == 0 {
+		err = updateChronySources(ctx)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to update the NTP servers learned from the network", "err", err)
+		}
+	}
+
+	n.State.Time = getTimeState(ctx)
+
 	// Report any unused additional physical interface.
 	ifaces, err := net.Interfaces()
 	if err != nil {
@@ -386,6 +597,8 @@ func UpdateNetworkState(ctx context.Context, n *api.SystemNetwork) error {
 			return err
 		}
 
+		iState.Path = getDevicePath(ctx, i.Name)
+
 		n.State.Interfaces[i.Name] = iState
 	}
 
@@ -675,9 +888,9 @@ func getInterfaceState(ctx context.Context, ifaceType string, iface string, hwad
 	var underlyingDevice string
 
 	switch ifaceType {
-	case "interface", "bond_member":
+	case "interface", "bond_member", "bridge_member":
 		underlyingDevice = "_p" + strings.ToLower(strings.ReplaceAll(hwaddr, ":", ""))
-	case "bond", "physical":
+	case "bond", "bridge", "physical", "wifi":
 		underlyingDevice = iface
 	case "vlan":
 		if hwaddr == "" {
@@ -691,7 +904,10 @@ func getInterfaceState(ctx context.Context, ifaceType string, iface string, hwad
 
 	var speed string
 
-	if interfaceState != "off" {
+	// Wireless devices don't report a speed through sysfs, it's taken from the link state instead.
+	if ifaceType == "wifi" {
+		speed = "unknown"
+	} else if interfaceState != "off" {
 		// #nosec G304
 		contents, err := os.ReadFile("/sys/class/net/" + underlyingDevice + "/speed")
 		if err != nil {
@@ -708,7 +924,7 @@ func getInterfaceState(ctx context.Context, ifaceType string, iface string, hwad
 	// Fetch any LLDP info.
 	lldp := []api.SystemNetworkLLDPState{}
 
-	if ifaceType == "interface" || ifaceType == "bond_member" {
+	if ifaceType == "interface" || ifaceType == "bond_member" || ifaceType == "bridge_member" {
 		lldpIface := iface
 		if ifaceType == "interface" {
 			lldpIface = "_p" + strings.ToLower(strings.ReplaceAll(localMAC, ":", ""))
@@ -749,6 +965,58 @@ func getInterfaceState(ctx context.Context, ifaceType string, iface string, hwad
 	}, nil
 }
 
+// parseBondingStatus parses the contents of /proc/net/bonding/<bond>, returning the state of the bond
+// itself and of each of its members.
+func parseBondingStatus(contents string) (api.SystemNetworkBondState, map[string]api.SystemNetworkBondState) {
+	bondState := api.SystemNetworkBondState{}
+	memberStates := map[string]api.SystemNetworkBondState{}
+
+	currentMember := ""
+
+	for _, line := range strings.Split(contents, "\n") {
+		// Nested entries, such as LACP details, are indented.
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "Currently Active Slave":
+			if value != "None" {
+				bondState.ActiveMember = value
+			}
+		case "Slave Interface":
+			currentMember = value
+			memberStates[currentMember] = api.SystemNetworkBondState{}
+		case "MII Status":
+			if currentMember == "" {
+				bondState.MIIStatus = value
+			} else {
+				memberState := memberStates[currentMember]
+				memberState.MIIStatus = value
+				memberStates[currentMember] = memberState
+			}
+		case "Link Failure Count":
+			failures, err := strconv.Atoi(value)
+			if err == nil && currentMember != "" {
+				memberState := memberStates[currentMember]
+				memberState.LinkFailures = failures
+				memberStates[currentMember] = memberState
+
+				bondState.LinkFailures += failures
+			}
+		}
+	}
+
+	return bondState, memberStates
+}
+
 // When dealing with a bridge, we can't just get its IP address or route. So,
 // determine the "main" member corresponding to the physical NIC and return that
 // device name instead. If the device isn't a bridge, return the original name
@@ -764,7 +1032,7 @@ func resolveBridge(iface string) string {
 
 // GetIPAddresses returns any non-link-local address for an interface.
 func GetIPAddresses(ctx context.Context, iface string) ([]string, error) {
-	ipAddressRegex := regexp.MustCompile(`inet6? (.+)/\d+ `)
+	ipAddressRegex := regexp.MustCompile(`inet6? ([^ /]+)(?:/\d+)? `)
 
 	output, err := subprocess.RunCommandContext(ctx, "ip", "address", "show", resolveBridge(iface))
 	if err != nil {
@@ -879,22 +1147,20 @@ func generateNetworkConfiguration(_ context.Context, networkCfg *api.SystemNetwo
 		}
 	}
 
-	// Generate systemd-timesyncd configuration if any timeservers are defined.
-	ntpCfg := ""
+	// Generate the chrony configuration.
+	timeCfg := api.SystemNetworkTime{}
 	if networkCfg.Time != nil {
-		ntpCfg = generateTimesyncContents(*networkCfg.Time)
+		timeCfg = *networkCfg.Time
+	}
 
-		if ntpCfg != "" {
-			err := os.WriteFile(SystemdTimesyncConfigFile, []byte(ntpCfg), 0o644)
-			if err != nil {
-				return err
-			}
-		}
+	err = os.MkdirAll(filepath.Dir(ChronyConfigFile), 0o755)
+	if err != nil {
+		return err
 	}
 
-	// If there's no NTP configuration, remove the old config file that might exist.
-	if networkCfg.Time == nil || ntpCfg == "" {
-		_ = os.Remove(SystemdTimesyncConfigFile)
+	err = os.WriteFile(ChronyConfigFile, []byte(generateChronyContents(timeCfg)), 0o644)
+	if err != nil {
+		return err
 	}
 
 	return nil
@@ -946,7 +1212,7 @@ func waitForUdevInterfaceRename(ctx context.Context, expectedInterfaces []string
 }
 
 // waitForNetworkOnline waits up to a provided timeout for configured network interfaces,
-// bonds, and vlans to configure their IP address(es) and come online.
+// bonds, bridges, and vlans to configure their IP address(es) and come online.
 func waitForNetworkOnline(ctx context.Context, networkCfg *api.SystemNetworkConfig, timeout time.Duration) error {
 	isOnline := func(name string) (bool, bool) {
 		output, err := subprocess.RunCommandContext(ctx, "networkctl", "status", resolveBridge(name))
@@ -993,6 +1259,18 @@ func waitForNetworkOnline(ctx context.Context, networkCfg *api.SystemNetworkConf
 		devicesToCheck = append(devicesToCheck, b.Name)
 	}
 
+	for _, b := range networkCfg.Bridges {
+		if len(b.Addresses) == 0 {
+			continue
+		}
+
+		if slices.Contains([]string{"ipv6", "both"}, b.RequiredForOnline) {
+			needIPv6Delay = true
+		}
+
+		devicesToCheck = append(devicesToCheck, b.Name)
+	}
+
 	for _, v := range networkCfg.VLANs {
 		if len(v.Addresses) == 0 {
 			continue
@@ -1005,6 +1283,18 @@ func waitForNetworkOnline(ctx context.Context, networkCfg *api.SystemNetworkConf
 		devicesToCheck = append(devicesToCheck, v.Name)
 	}
 
+	for _, w := range networkCfg.Wifi {
+		if len(w.Addresses) == 0 {
+			continue
+		}
+
+		if slices.Contains([]string{"ipv6", "both"}, w.RequiredForOnline) {
+			needIPv6Delay = true
+		}
+
+		devicesToCheck = append(devicesToCheck, w.Name)
+	}
+
 	for {
 		if time.Now().After(endTime) {
 			return errors.New("timed out waiting for network to come online")
@@ -1077,39 +1367,6 @@ func waitForDNS(ctx context.Context, timeout time.Duration) error {
 	}
 }
 
-// waitForSystemdTimesyncd waits up to a provided timeout for systemd-timesyncd to
-// perform an initial NTP synchronization.
-func waitForSystemdTimesyncd(ctx context.Context, timeout time.Duration) error {
-	endTime := time.Now().Add(timeout)
-
-	count := 0
-
-	for {
-		if time.Now().After(endTime) {
-			return errors.New("timed out waiting for NTP synchronization")
-		}
-
-		// Check if systemd-timesyncd has performed its initial synchronization.
-		_, err := subprocess.RunCommandContext(ctx, "journalctl", "--since", "10 seconds ago", "-u", "systemd-timesyncd", "-g", "Initial clock synchronization")
-		if err == nil {
-			return nil
-		}
-
-		// Restart systemd-timesyncd every 5 tries.
-		count++
-		if count == 5 {
-			count = 0
-
-			err = RestartUnit(ctx, "systemd-timesyncd")
-			if err != nil {
-				return err
-			}
-		}
-
-		time.Sleep(500 * time.Millisecond)
-	}
-}
-
 // generateLinkFileContents generates the contents of systemd.link files. Returns an array of ConfigFile structs.
 // https://www.freedesktop.org/software/systemd/man/latest/systemd.link.html
 func generateLinkFileContents(networkCfg api.SystemNetworkConfig) []networkdConfigFile {
@@ -1122,11 +1379,11 @@ func generateLinkFileContents(networkCfg api.SystemNetworkConfig) []networkdConf
 
 		segments := []string{}
 		if s.DisableGRO {
-			segments = append(segments, "GenericSegmentationOffload=false")
+			segments = append(segments, "GenericReceiveOffload=false")
 		}
 
 		if s.DisableGSO {
-			segments = append(segments, "GenericReceiveOffload=false")
+			segments = append(segments, "GenericSegmentationOffload=false")
 		}
 
 		if s.DisableIPv4TSO {
@@ -1137,6 +1394,10 @@ func generateLinkFileContents(networkCfg api.SystemNetworkConfig) []networkdConf
 			segments = append(segments, "TCP6SegmentationOffload=false")
 		}
 
+		if s.DisableLRO {
+			segments = append(segments, "LargeReceiveOffload=false")
+		}
+
 		if s.WakeOnLAN {
 			if len(s.WakeOnLANModes) > 0 {
 				for _, mode := range s.WakeOnLANModes {
@@ -1162,18 +1423,52 @@ Enable=false`
 		return out
 	}
 
+	// SR-IOV settings reopen the [Link] section, as generateEthernet may have ended it.
+	generateSRIOV := func(s *api.SystemNetworkSRIOV) string {
+		if s == nil {
+			return ""
+		}
+
+		var ret strings.Builder
+
+		_, _ = ret.WriteString(fmt.Sprintf("\n[Link]\nSR-IOVVirtualFunctions=%d\n", s.VFs))
+
+		for _, vf := range s.VirtualFunctions {
+			_, _ = ret.WriteString(fmt.Sprintf("\n[SR-IOV]\nVirtualFunction=%d\n", vf.Index))
+
+			if vf.Hwaddr != "" {
+				_, _ = ret.WriteString(fmt.Sprintf("MACAddress=%s\n", vf.Hwaddr))
+			}
+
+			if vf.VLAN != 0 {
+				_, _ = ret.WriteString(fmt.Sprintf("VLANId=%d\n", vf.VLAN))
+			}
+
+			_, _ = ret.WriteString(fmt.Sprintf("Trust=%s\nMACSpoofCheck=%s\n", strconv.FormatBool(vf.Trust), strconv.FormatBool(!vf.DisableSpoofCheck)))
+		}
+
+		return ret.String()
+	}
+
 	for _, i := range networkCfg.Interfaces {
 		strippedHwaddr := strings.ToLower(strings.ReplaceAll(i.Hwaddr, ":", ""))
+
+		// Pinning the interface to a path keeps it configured when the card in that slot is replaced.
+		match := "PermanentMACAddress=" + i.Hwaddr
+		if i.Path != "" {
+			match = "Path=" + i.Path
+		}
+
 		ret = append(ret, networkdConfigFile{
 			Name: fmt.Sprintf("00-_p%s.link", strippedHwaddr),
 			Contents: fmt.Sprintf(`[Match]
-PermanentMACAddress=%s
+%s
 
 [Link]
 MACAddressPolicy=random
 NamePolicy=
 Name=_p%s
-%s`, i.Hwaddr, strippedHwaddr, generateEthernet(i.Ethernet)),
+%s%s`, match, strippedHwaddr, generateEthernet(i.Ethernet), generateSRIOV(i.SRIOV)),
 		})
 	}
 
@@ -1193,6 +1488,35 @@ Name=_p%s
 		}
 	}
 
+	for _, b := range networkCfg.Bridges {
+		for _, member := range b.Members {
+			strippedHwaddr := strings.ToLower(strings.ReplaceAll(member, ":", ""))
+			ret = append(ret, networkdConfigFile{
+				Name: fmt.Sprintf("02-_p%s.link", strippedHwaddr),
+				Contents: fmt.Sprintf(`[Match]
+PermanentMACAddress=%s
+
+[Link]
+NamePolicy=
+Name=_p%s
+%s`, member, strippedHwaddr, generateEthernet(b.Ethernet)),
+			})
+		}
+	}
+
+	for _, w := range networkCfg.Wifi {
+		ret = append(ret, networkdConfigFile{
+			Name: fmt.Sprintf("03-%s.link", w.Name),
+			Contents: fmt.Sprintf(`[Match]
+PermanentMACAddress=%s
+
+[Link]
+NamePolicy=
+Name=%s
+`, w.Hwaddr, w.Name),
+		})
+	}
+
 	return ret
 }
 
@@ -1244,6 +1568,20 @@ Name=_i%s
 			mtuString = fmt.Sprintf("MTUBytes=%d", b.MTU)
 		}
 
+		var bondOptions strings.Builder
+
+		if b.LACPRate != "" {
+			_, _ = bondOptions.WriteString(fmt.Sprintf("LACPTransmitRate=%s\n", b.LACPRate))
+		}
+
+		if b.TransmitHashPolicy != "" {
+			_, _ = bondOptions.WriteString(fmt.Sprintf("TransmitHashPolicy=%s\n", b.TransmitHashPolicy))
+		}
+
+		if b.MIIMonitorInterval != 0 {
+			_, _ = bondOptions.WriteString(fmt.Sprintf("MIIMonitorSec=%dms\n", b.MIIMonitorInterval))
+		}
+
 		// Bond.
 		ret = append(ret, networkdConfigFile{
 			Name: fmt.Sprintf("11-_b%s.netdev", b.Name),
@@ -1254,7 +1592,7 @@ Kind=bond
 
 [Bond]
 Mode=%s
-`, b.Name, mtuString, b.Mode),
+%s`, b.Name, mtuString, b.Mode, bondOptions.String()),
 		})
 
 		// Bridge.
@@ -1291,11 +1629,49 @@ Name=_i%s
 		})
 	}
 
-	// Create vlans.
-	for _, v := range networkCfg.VLANs {
+	// Create bridge and veth devices for each managed bridge.
+	for _, b := range networkCfg.Bridges {
 		mtuString := ""
-		if v.MTU != 0 {
-			mtuString = fmt.Sprintf("MTUBytes=%d", v.MTU)
+		if b.MTU != 0 {
+			mtuString = fmt.Sprintf("MTUBytes=%d", b.MTU)
+		}
+
+		// Bridge.
+		ret = append(ret, networkdConfigFile{
+			Name: fmt.Sprintf("14-%s.netdev", b.Name),
+			Contents: fmt.Sprintf(`[NetDev]
+Name=%s
+Kind=bridge
+%s
+
+[Bridge]
+STP=%s
+VLANFiltering=%s
+`, b.Name, mtuString, strconv.FormatBool(b.STP), strconv.FormatBool(b.VLANFiltering)),
+		})
+
+		// veth.
+		bridgeMacAddr := getBridgeHwaddr(b)
+		strippedHwaddr := strings.ToLower(strings.ReplaceAll(bridgeMacAddr, ":", ""))
+		ret = append(ret, networkdConfigFile{
+			Name: fmt.Sprintf("14-_v%s.netdev", b.Name),
+			Contents: fmt.Sprintf(`[NetDev]
+Name=_v%s
+Kind=veth
+MACAddress=%s
+%s
+
+[Peer]
+Name=_i%s
+`, b.Name, bridgeMacAddr, mtuString, strippedHwaddr),
+		})
+	}
+
+	// Create vlans.
+	for _, v := range networkCfg.VLANs {
+		mtuString := ""
+		if v.MTU != 0 {
+			mtuString = fmt.Sprintf("MTUBytes=%d", v.MTU)
 		}
 
 		ret = append(ret, networkdConfigFile{
@@ -1367,6 +1743,14 @@ PublicKey=%s
 		})
 	}
 
+	// Create tunnels.
+	for _, t := range networkCfg.Tunnels {
+		ret = append(ret, networkdConfigFile{
+			Name:     fmt.Sprintf("15-%s.netdev", t.Name),
+			Contents: generateTunnelContents(t),
+		})
+	}
+
 	return ret
 }
 
@@ -1390,14 +1774,22 @@ RouteMetric=100
 UseMTU=true
 
 [Network]
-%s`, i.Name, generateLinkSectionContents(i.Addresses, i.RequiredForOnline), generateNetworkSectionContents(i.Name, networkCfg.VLANs, networkCfg.DNS, networkCfg.Time))
+%s`, i.Name, generateLinkSectionContents(i.Addresses, i.RequiredForOnline), generateNetworkSectionContents(i.Name, networkCfg.VLANs, networkCfg.DNS))
 
 		cfgString += processAddresses(i.Addresses)
+		cfgString += generateDHCPServerContents(i.Name, networkCfg.DHCPServer)
+		cfgString += generateIPv6Contents(i.Name, networkCfg)
+		cfgString += generateNeighborContents(i.Neighbors, i.ProxyARP, i.ProxyNDP)
+		cfgString += generateHostQoSContents(i.QoS)
 
 		if len(i.Routes) > 0 {
 			cfgString += processRoutes(i.Routes)
 		}
 
+		if len(i.RoutingRules) > 0 {
+			cfgString += processRoutingRules(i.RoutingRules)
+		}
+
 		ret = append(ret, networkdConfigFile{
 			Name:     fmt.Sprintf("20-_v%s.network", i.Name),
 			Contents: cfgString,
@@ -1435,6 +1827,8 @@ Bridge=%s
 			cfgString += fmt.Sprintf("[Link]\nMTUBytes=%d\n", i.MTU)
 		}
 
+		cfgString += generateQoSContents(i.QoS)
+
 		ret = append(ret, networkdConfigFile{
 			Name:     fmt.Sprintf("20-_p%s.network", strippedHwaddr),
 			Contents: cfgString,
@@ -1474,14 +1868,22 @@ RouteMetric=100
 UseMTU=true
 
 [Network]
-%s`, b.Name, generateLinkSectionContents(b.Addresses, b.RequiredForOnline), generateNetworkSectionContents(b.Name, networkCfg.VLANs, networkCfg.DNS, networkCfg.Time))
+%s`, b.Name, generateLinkSectionContents(b.Addresses, b.RequiredForOnline), generateNetworkSectionContents(b.Name, networkCfg.VLANs, networkCfg.DNS))
 
 		cfgString += processAddresses(b.Addresses)
+		cfgString += generateDHCPServerContents(b.Name, networkCfg.DHCPServer)
+		cfgString += generateIPv6Contents(b.Name, networkCfg)
+		cfgString += generateNeighborContents(b.Neighbors, b.ProxyARP, b.ProxyNDP)
+		cfgString += generateHostQoSContents(b.QoS)
 
 		if len(b.Routes) > 0 {
 			cfgString += processRoutes(b.Routes)
 		}
 
+		if len(b.RoutingRules) > 0 {
+			cfgString += processRoutingRules(b.RoutingRules)
+		}
+
 		ret = append(ret, networkdConfigFile{
 			Name:     fmt.Sprintf("21-_v%s.network", b.Name),
 			Contents: cfgString,
@@ -1520,6 +1922,7 @@ Bridge=%s
 `, b.Name, b.Name)
 
 		cfgString += generateVLANContents(b.Name, b.VLANTags, networkCfg.VLANs)
+		cfgString += generateQoSContents(b.QoS)
 
 		ret = append(ret, networkdConfigFile{
 			Name:     fmt.Sprintf("21-_b%s.network", b.Name),
@@ -1558,6 +1961,106 @@ Bond=_b%s
 		}
 	}
 
+	// Create networks for each managed bridge and its member(s).
+	for _, b := range networkCfg.Bridges {
+		// User side of veth device.
+		cfgString := fmt.Sprintf(`[Match]
+Name=_v%s
+
+[Link]
+%s
+
+[DHCP]
+ClientIdentifier=mac
+RouteMetric=100
+UseMTU=true
+
+[Network]
+%s`, b.Name, generateLinkSectionContents(b.Addresses, b.RequiredForOnline), generateNetworkSectionContents(b.Name, networkCfg.VLANs, networkCfg.DNS))
+
+		cfgString += processAddresses(b.Addresses)
+		cfgString += generateDHCPServerContents(b.Name, networkCfg.DHCPServer)
+		cfgString += generateIPv6Contents(b.Name, networkCfg)
+		cfgString += generateNeighborContents(b.Neighbors, b.ProxyARP, b.ProxyNDP)
+
+		if len(b.Routes) > 0 {
+			cfgString += processRoutes(b.Routes)
+		}
+
+		if len(b.RoutingRules) > 0 {
+			cfgString += processRoutingRules(b.RoutingRules)
+		}
+
+		ret = append(ret, networkdConfigFile{
+			Name:     fmt.Sprintf("24-_v%s.network", b.Name),
+			Contents: cfgString,
+		})
+
+		// Bridge side of veth device.
+		strippedHwaddr := strings.ToLower(strings.ReplaceAll(getBridgeHwaddr(b), ":", ""))
+
+		cfgString = fmt.Sprintf(`[Match]
+Name=_i%s
+
+[Network]
+Bridge=%s
+`, strippedHwaddr, b.Name)
+
+		if b.VLANFiltering {
+			cfgString += generateVLANContents(b.Name, b.VLANTags, networkCfg.VLANs)
+		}
+
+		ret = append(ret, networkdConfigFile{
+			Name:     fmt.Sprintf("24-_i%s.network", strippedHwaddr),
+			Contents: cfgString,
+		})
+
+		// Bridge.
+		cfgString = fmt.Sprintf(`[Match]
+Name=%s
+
+[Network]
+LinkLocalAddressing=no
+ConfigureWithoutCarrier=yes
+`, b.Name)
+
+		if b.MTU != 0 {
+			cfgString += fmt.Sprintf("[Link]\nMTUBytes=%d\n", b.MTU)
+		}
+
+		ret = append(ret, networkdConfigFile{
+			Name:     fmt.Sprintf("24-%s.network", b.Name),
+			Contents: cfgString,
+		})
+
+		// Bridge members.
+		for index, member := range b.Members {
+			memberStrippedHwaddr := strings.ToLower(strings.ReplaceAll(member, ":", ""))
+
+			cfgString = fmt.Sprintf(`[Match]
+Name=_p%s
+
+[Network]
+LinkLocalAddressing=no
+ConfigureWithoutCarrier=yes
+Bridge=%s
+`, memberStrippedHwaddr, b.Name)
+
+			if b.VLANFiltering {
+				cfgString += generateVLANContents(b.Name, b.VLANTags, networkCfg.VLANs)
+			}
+
+			if b.MTU != 0 {
+				cfgString += fmt.Sprintf("[Link]\nMTUBytes=%d\n", b.MTU)
+			}
+
+			ret = append(ret, networkdConfigFile{
+				Name:     fmt.Sprintf("24-%s-dev%d.network", b.Name, index),
+				Contents: cfgString,
+			})
+		}
+	}
+
 	// Create network for each VLAN.
 	for _, v := range networkCfg.VLANs {
 		cfgString := fmt.Sprintf(`[Match]
@@ -1572,20 +2075,63 @@ RouteMetric=100
 UseMTU=true
 
 [Network]
-%s`, v.Name, generateLinkSectionContents(v.Addresses, v.RequiredForOnline), generateNetworkSectionContents(v.Name, nil, networkCfg.DNS, networkCfg.Time))
+%s`, v.Name, generateLinkSectionContents(v.Addresses, v.RequiredForOnline), generateNetworkSectionContents(v.Name, nil, networkCfg.DNS))
 
 		cfgString += processAddresses(v.Addresses)
+		cfgString += generateDHCPServerContents(v.Name, networkCfg.DHCPServer)
+		cfgString += generateIPv6Contents(v.Name, networkCfg)
+		cfgString += generateNeighborContents(v.Neighbors, v.ProxyARP, v.ProxyNDP)
 
 		if len(v.Routes) > 0 {
 			cfgString += processRoutes(v.Routes)
 		}
 
+		if len(v.RoutingRules) > 0 {
+			cfgString += processRoutingRules(v.RoutingRules)
+		}
+
 		ret = append(ret, networkdConfigFile{
 			Name:     fmt.Sprintf("22-%s.network", v.Name),
 			Contents: cfgString,
 		})
 	}
 
+	// Create network for each Wi-Fi interface.
+	for _, w := range networkCfg.Wifi {
+		cfgString := fmt.Sprintf(`[Match]
+Name=%s
+
+[Link]
+%s
+
+[DHCP]
+ClientIdentifier=mac
+RouteMetric=100
+UseMTU=true
+
+[Network]
+%s`, w.Name, generateLinkSectionContents(w.Addresses, w.RequiredForOnline), generateNetworkSectionContents(w.Name, nil, networkCfg.DNS))
+
+		cfgString += processAddresses(w.Addresses)
+
+		if len(w.Routes) > 0 {
+			cfgString += processRoutes(w.Routes)
+		}
+
+		if len(w.RoutingRules) > 0 {
+			cfgString += processRoutingRules(w.RoutingRules)
+		}
+
+		if w.MTU != 0 {
+			cfgString += fmt.Sprintf("\n[Link]\nMTUBytes=%d\n", w.MTU)
+		}
+
+		ret = append(ret, networkdConfigFile{
+			Name:     fmt.Sprintf("25-%s.network", w.Name),
+			Contents: cfgString,
+		})
+	}
+
 	// Create network for each Wireguard.
 	for _, wg := range networkCfg.Wireguard {
 		cfgString := fmt.Sprintf(`[Match]
@@ -1600,15 +2146,65 @@ Name=%s
 			cfgString += processRoutes(wg.Routes)
 		}
 
+		if len(wg.RoutingRules) > 0 {
+			cfgString += processRoutingRules(wg.RoutingRules)
+		}
+
 		ret = append(ret, networkdConfigFile{
 			Name:     fmt.Sprintf("23-%s.network", wg.Name),
 			Contents: cfgString,
 		})
 	}
 
+	// Create network for each tunnel.
+	for _, t := range networkCfg.Tunnels {
+		cfgString := fmt.Sprintf(`[Match]
+Name=%s
+
+[Link]
+%s
+
+[Network]
+`, t.Name, generateLinkSectionContents(t.Addresses, t.RequiredForOnline))
+
+		cfgString += processAddresses(t.Addresses)
+
+		if len(t.Routes) > 0 {
+			cfgString += processRoutes(t.Routes)
+		}
+
+		if len(t.RoutingRules) > 0 {
+			cfgString += processRoutingRules(t.RoutingRules)
+		}
+
+		ret = append(ret, networkdConfigFile{
+			Name:     fmt.Sprintf("26-%s.network", t.Name),
+			Contents: cfgString,
+		})
+	}
+
 	return ret
 }
 
+// getHostDevice returns the device used by the host for a named interface, bond, bridge or vlan. Interfaces,
+// bonds and bridges are reached through the host side of their veth pair, while vlans are used directly.
+func getHostDevice(name string, networkCfg *api.SystemNetworkConfig) string {
+	if slices.ContainsFunc(networkCfg.VLANs, func(v api.SystemNetworkVLAN) bool { return v.Name == name }) {
+		return name
+	}
+
+	return "_v" + name
+}
+
+// getBridgeHwaddr returns the MAC address used by the host side of a managed bridge.
+func getBridgeHwaddr(b api.SystemNetworkBridge) string {
+	if b.Hwaddr != "" {
+		return b.Hwaddr
+	}
+
+	return b.Members[0]
+}
+
 func processAddresses(addresses []string) string {
 	var ret strings.Builder
 
@@ -1654,6 +2250,115 @@ func processAddresses(addresses []string) string {
 	return ret.String()
 }
 
+// generateDHCPServerContents enables the DHCP server on the given device if it's the one
+// designated in the DHCP server configuration.
+func generateDHCPServerContents(name string, server *api.SystemNetworkDHCPServer) string {
+	if server == nil || server.Interface != name {
+		return ""
+	}
+
+	var ret strings.Builder
+
+	_, _ = ret.WriteString("DHCPServer=yes\n\n[DHCPServer]\n")
+
+	if server.PoolOffset != 0 {
+		_, _ = ret.WriteString(fmt.Sprintf("PoolOffset=%d\n", server.PoolOffset))
+	}
+
+	if server.PoolSize != 0 {
+		_, _ = ret.WriteString(fmt.Sprintf("PoolSize=%d\n", server.PoolSize))
+	}
+
+	if server.LeaseTime != 0 {
+		_, _ = ret.WriteString(fmt.Sprintf("DefaultLeaseTimeSec=%d\nMaxLeaseTimeSec=%d\n", server.LeaseTime, server.LeaseTime))
+	}
+
+	if server.Router != "" {
+		_, _ = ret.WriteString(fmt.Sprintf("Router=%s\n", server.Router))
+	}
+
+	for _, dns := range server.DNS {
+		_, _ = ret.WriteString(fmt.Sprintf("DNS=%s\n", dns))
+	}
+
+	if server.BootServer != "" {
+		_, _ = ret.WriteString(fmt.Sprintf("BootServerAddress=%s\n", server.BootServer))
+	}
+
+	if server.BootFilename != "" {
+		_, _ = ret.WriteString(fmt.Sprintf("BootFilename=%s\n", server.BootFilename))
+	}
+
+	for _, lease := range server.StaticLeases {
+		_, _ = ret.WriteString(fmt.Sprintf("\n[DHCPServerStaticLease]\nMACAddress=%s\nAddress=%s\n", lease.Hwaddr, lease.Address))
+	}
+
+	return ret.String()
+}
+
+// generateIPv6Contents generates the prefix delegation and router advertisement configuration of a given device.
+func generateIPv6Contents(name string, networkCfg api.SystemNetworkConfig) string {
+	if networkCfg.IPv6 == nil {
+		return ""
+	}
+
+	var ret strings.Builder
+
+	sendRA := false
+
+	// Request a prefix on the uplink, even if the upstream router doesn't advertise DHCPv6.
+	pd := networkCfg.IPv6.PrefixDelegation
+	if pd != nil && pd.Uplink == name {
+		_, _ = ret.WriteString("\n[DHCPv6]\nWithoutRA=solicit\n")
+
+		if pd.PrefixLength != 0 {
+			_, _ = ret.WriteString(fmt.Sprintf("PrefixDelegationHint=::/%d\n", pd.PrefixLength))
+		}
+	}
+
+	// Assign and announce a sub-prefix on downstream devices.
+	if pd != nil {
+		for _, downstream := range pd.Downstreams {
+			if downstream.Interface != name {
+				continue
+			}
+
+			sendRA = true
+
+			_, _ = ret.WriteString(fmt.Sprintf("\n[Network]\nDHCPPrefixDelegation=yes\n\n[DHCPPrefixDelegation]\nUplinkInterface=%s\nSubnetId=0x%x\nAnnounce=yes\nAssign=yes\n", getHostDevice(pd.Uplink, &networkCfg), downstream.SubnetID))
+		}
+	}
+
+	for _, ra := range networkCfg.IPv6.RouterAdvertisements {
+		if ra.Interface != name {
+			continue
+		}
+
+		sendRA = true
+
+		_, _ = ret.WriteString("\n[IPv6SendRA]\n")
+		_, _ = ret.WriteString(fmt.Sprintf("Managed=%s\nOtherInformation=%s\n", strconv.FormatBool(ra.Managed), strconv.FormatBool(ra.OtherInformation)))
+
+		if ra.RouterLifetime != 0 {
+			_, _ = ret.WriteString(fmt.Sprintf("RouterLifetimeSec=%d\n", ra.RouterLifetime))
+		}
+
+		for _, dns := range ra.DNS {
+			_, _ = ret.WriteString(fmt.Sprintf("DNS=%s\n", dns))
+		}
+
+		for _, prefix := range ra.Prefixes {
+			_, _ = ret.WriteString(fmt.Sprintf("\n[IPv6Prefix]\nPrefix=%s\n", prefix))
+		}
+	}
+
+	if sendRA {
+		_, _ = ret.WriteString("\n[Network]\nIPv6SendRA=yes\n")
+	}
+
+	return ret.String()
+}
+
 func processRoutes(routes []api.SystemNetworkRoute) string {
 	var ret strings.Builder
 
@@ -1670,12 +2375,44 @@ func processRoutes(routes []api.SystemNetworkRoute) string {
 		}
 
 		_, _ = ret.WriteString(fmt.Sprintf("Destination=%s\n", route.To))
+
+		if route.Metric != 0 {
+			_, _ = ret.WriteString(fmt.Sprintf("Metric=%d\n", route.Metric))
+		}
+
+		if route.Table != 0 {
+			_, _ = ret.WriteString(fmt.Sprintf("Table=%d\n", route.Table))
+		}
+	}
+
+	return ret.String()
+}
+
+func processRoutingRules(rules []api.SystemNetworkRoutingRule) string {
+	var ret strings.Builder
+
+	for _, rule := range rules {
+		_, _ = ret.WriteString("\n[RoutingPolicyRule]\n")
+
+		if rule.From != "" {
+			_, _ = ret.WriteString(fmt.Sprintf("From=%s\n", rule.From))
+		}
+
+		if rule.To != "" {
+			_, _ = ret.WriteString(fmt.Sprintf("To=%s\n", rule.To))
+		}
+
+		_, _ = ret.WriteString(fmt.Sprintf("Table=%d\n", rule.Table))
+
+		if rule.Priority != 0 {
+			_, _ = ret.WriteString(fmt.Sprintf("Priority=%d\n", rule.Priority))
+		}
 	}
 
 	return ret.String()
 }
 
-func generateNetworkSectionContents(name string, vlans []api.SystemNetworkVLAN, dns *api.SystemNetworkDNS, timeCfg *api.SystemNetworkTime) string {
+func generateNetworkSectionContents(name string, vlans []api.SystemNetworkVLAN, dns *api.SystemNetworkDNS) string {
 	var ret strings.Builder
 
 	// Add any matching VLANs to the config.
@@ -1692,27 +2429,131 @@ func generateNetworkSectionContents(name string, vlans []api.SystemNetworkVLAN,
 			_, _ = ret.WriteString(fmt.Sprintf("Domains=%s\n", strings.Join(dns.SearchDomains, " ")))
 		}
 
-		for _, ns := range dns.Nameservers {
+		// A device carrying a DNS route only uses the route's name servers, so that
+		// queries for the routed domains don't leak to the other name servers.
+		nameservers := dns.Nameservers
+
+		for _, route := range dns.Routes {
+			if route.Interface != name {
+				continue
+			}
+
+			nameservers = route.Nameservers
+
+			routingDomains := []string{}
+			for _, domain := range route.Domains {
+				routingDomains = append(routingDomains, "~"+domain)
+			}
+
+			_, _ = ret.WriteString(fmt.Sprintf("Domains=%s\n", strings.Join(routingDomains, " ")))
+		}
+
+		for _, ns := range nameservers {
 			_, _ = ret.WriteString(fmt.Sprintf("DNS=%s\n", ns))
 		}
+
+		if dns.DNSOverTLS != "" {
+			_, _ = ret.WriteString(fmt.Sprintf("DNSOverTLS=%s\n", dns.DNSOverTLS))
+		}
+	}
+
+	return ret.String()
+}
+
+// generateTunnelContents generates the netdev of a GRE or VXLAN tunnel. Tunnels aren't bound to an
+// underlying device, the kernel routes the encapsulated traffic towards the remote endpoint.
+func generateTunnelContents(t api.SystemNetworkTunnel) string {
+	var ret strings.Builder
+
+	// GRE over IPv6 is its own kind of device.
+	kind := t.Kind
+	if kind != "vxlan" && net.ParseIP(t.Remote).To4() == nil {
+		kind = "ip6" + kind
+	}
+
+	_, _ = ret.WriteString(fmt.Sprintf("[NetDev]\nName=%s\nKind=%s\n", t.Name, kind))
+
+	if t.MTU != 0 {
+		_, _ = ret.WriteString(fmt.Sprintf("MTUBytes=%d\n", t.MTU))
+	}
+
+	if t.Kind == "vxlan" {
+		_, _ = ret.WriteString(fmt.Sprintf("\n[VXLAN]\nVNI=%d\nRemote=%s\n", t.VNI, t.Remote))
+
+		if t.Port != 0 {
+			_, _ = ret.WriteString(fmt.Sprintf("DestinationPort=%d\n", t.Port))
+		}
+	} else {
+		_, _ = ret.WriteString(fmt.Sprintf("\n[Tunnel]\nRemote=%s\n", t.Remote))
+
+		if t.Key != 0 {
+			_, _ = ret.WriteString(fmt.Sprintf("Key=%d\n", t.Key))
+		}
 	}
 
-	// If there are time servers defined, add them to the config.
-	if timeCfg != nil {
-		for _, ts := range timeCfg.NTPServers {
-			_, _ = ret.WriteString(fmt.Sprintf("NTP=%s\n", ts))
+	if t.Local != "" {
+		_, _ = ret.WriteString(fmt.Sprintf("Local=%s\n", t.Local))
+	}
+
+	_, _ = ret.WriteString("Independent=yes\n")
+
+	return ret.String()
+}
+
+// generateNeighborContents generates the static neighbor entries and proxy ARP/NDP settings of a device.
+func generateNeighborContents(neighbors []api.SystemNetworkNeighbor, proxyARP bool, proxyNDP []string) string {
+	var ret strings.Builder
+
+	if proxyARP || len(proxyNDP) > 0 {
+		_, _ = ret.WriteString("\n[Network]\n")
+
+		if proxyARP {
+			_, _ = ret.WriteString("ProxyARP=yes\n")
+		}
+
+		if len(proxyNDP) > 0 {
+			_, _ = ret.WriteString("IPv6ProxyNDP=yes\n")
+
+			for _, address := range proxyNDP {
+				_, _ = ret.WriteString(fmt.Sprintf("IPv6ProxyNDPAddress=%s\n", address))
+			}
 		}
 	}
 
+	for _, neighbor := range neighbors {
+		_, _ = ret.WriteString(fmt.Sprintf("\n[Neighbor]\nAddress=%s\nLinkLayerAddress=%s\n", neighbor.Address, neighbor.Hwaddr))
+	}
+
 	return ret.String()
 }
 
-func generateTimesyncContents(timeCfg api.SystemNetworkTime) string {
-	if len(timeCfg.NTPServers) == 0 {
+// generateQoSContents generates the CAKE queuing discipline shaping and prioritizing all traffic leaving an uplink.
+func generateQoSContents(qos *api.SystemNetworkQoS) string {
+	if qos == nil || (qos.EgressRate == 0 && qos.PriorityQueuing == "") {
+		return ""
+	}
+
+	ret := "\n[CAKE]\n"
+
+	if qos.EgressRate > 0 {
+		ret += fmt.Sprintf("Bandwidth=%dM\n", qos.EgressRate)
+	}
+
+	if qos.PriorityQueuing != "" {
+		ret += fmt.Sprintf("PriorityQueueingPreset=%s\n", qos.PriorityQueuing)
+	}
+
+	return ret
+}
+
+// generateHostQoSContents generates the queuing discipline capping the host's own traffic, leaving
+// the remaining uplink capacity to instances.
+func generateHostQoSContents(qos *api.SystemNetworkQoS) string {
+	if qos == nil || qos.HostEgressRate == 0 {
 		return ""
 	}
 
-	return "[Time]\nFallbackNTP=" + strings.Join(timeCfg.NTPServers, " ") + "\n"
+	return fmt.Sprintf("\n[CAKE]\nBandwidth=%dM\n", qos.HostEgressRate)
 }
 
 func generateVLANContents(devName string, additionalVLANTags []int, vlans []api.SystemNetworkVLAN) string {
@@ -1831,6 +2672,41 @@ func cleanupStaleDevices(ctx context.Context, oldCfg *api.SystemNetworkConfig, n
 		}
 	}
 
+	// Check for changed/deleted bridges.
+	for oldIndex := range oldCfg.Bridges {
+		newIndex := slices.IndexFunc(newCfg.Bridges, func(b api.SystemNetworkBridge) bool {
+			return oldCfg.Bridges[oldIndex].Name == b.Name
+		})
+
+		// If not found, remove the existing bridge (either deleted, or the device is now an interface, bond or vlan).
+		if newIndex < 0 {
+			deleteInterfaces = append(deleteInterfaces, "_v"+oldCfg.Bridges[oldIndex].Name, oldCfg.Bridges[oldIndex].Name)
+
+			continue
+		}
+
+		// Check if the bridge's configuration has changed.
+		oldConfig, err := json.Marshal(oldCfg.Bridges[oldIndex])
+		if err != nil {
+			return err
+		}
+
+		newConfig, err := json.Marshal(newCfg.Bridges[newIndex])
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(oldConfig, newConfig) {
+			deleteInterfaces = append(deleteInterfaces, "_v"+oldCfg.Bridges[oldIndex].Name)
+
+			if !isBridgeInUse(oldCfg.Bridges[oldIndex].Name) {
+				deleteInterfaces = append(deleteInterfaces, oldCfg.Bridges[oldIndex].Name)
+			}
+
+			continue
+		}
+	}
+
 	// Check for changed/deleted vlans.
 	for oldIndex := range oldCfg.VLANs {
 		newIndex := slices.IndexFunc(newCfg.VLANs, func(v api.SystemNetworkVLAN) bool {
@@ -1893,6 +2769,37 @@ func cleanupStaleDevices(ctx context.Context, oldCfg *api.SystemNetworkConfig, n
 		}
 	}
 
+	// Check for changed/deleted tunnels.
+	for oldIndex := range oldCfg.Tunnels {
+		newIndex := slices.IndexFunc(newCfg.Tunnels, func(t api.SystemNetworkTunnel) bool {
+			return oldCfg.Tunnels[oldIndex].Name == t.Name
+		})
+
+		// If not found, remove the existing tunnel.
+		if newIndex < 0 {
+			deleteInterfaces = append(deleteInterfaces, oldCfg.Tunnels[oldIndex].Name)
+
+			continue
+		}
+
+		// Check if the tunnel configuration has changed.
+		oldConfig, err := json.Marshal(oldCfg.Tunnels[oldIndex])
+		if err != nil {
+			return err
+		}
+
+		newConfig, err := json.Marshal(newCfg.Tunnels[newIndex])
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(oldConfig, newConfig) {
+			deleteInterfaces = append(deleteInterfaces, oldCfg.Tunnels[oldIndex].Name)
+
+			continue
+		}
+	}
+
 	// Delete all the interfaces.
 	if len(deleteInterfaces) > 0 {
 		deleteNetworkDevice(ctx, deleteInterfaces...)
@@ -1946,6 +2853,23 @@ func resolveMACs(ctx context.Context, config *api.SystemNetworkConfig) error {
 	hwaddrhRegex := regexp.MustCompile(`^[[:xdigit:]]{2}:[[:xdigit:]]{2}:[[:xdigit:]]{2}:[[:xdigit:]]{2}:[[:xdigit:]]{2}:[[:xdigit:]]{2}$`)
 
 	for i := range len(config.Interfaces) {
+		// Always follow the device currently found at the interface's path.
+		if config.Interfaces[i].Path != "" {
+			hwaddr, err := getMacForPath(ctx, config.Interfaces[i].Path)
+			if err == nil {
+				config.Interfaces[i].Hwaddr = hwaddr
+
+				continue
+			}
+
+			// Keep the last known MAC while no device is present.
+			if !hwaddrhRegex.MatchString(config.Interfaces[i].Hwaddr) {
+				return fmt.Errorf("interface %d failed getting MAC for path '%s': %s", i, config.Interfaces[i].Path, err.Error())
+			}
+
+			continue
+		}
+
 		if !hwaddrhRegex.MatchString(config.Interfaces[i].Hwaddr) {
 			hwaddr, err := getMacForInterface(ctx, config.Interfaces[i].Hwaddr)
 			if err != nil {
@@ -1978,6 +2902,39 @@ func resolveMACs(ctx context.Context, config *api.SystemNetworkConfig) error {
 		}
 	}
 
+	for i := range len(config.Bridges) {
+		if config.Bridges[i].Hwaddr != "" && !hwaddrhRegex.MatchString(config.Bridges[i].Hwaddr) {
+			hwaddr, err := getMacForInterface(ctx, config.Bridges[i].Hwaddr)
+			if err != nil {
+				return fmt.Errorf("bridge %d failed getting MAC for '%s': %s", i, config.Bridges[i].Hwaddr, err.Error())
+			}
+
+			config.Bridges[i].Hwaddr = hwaddr
+		}
+
+		for j := range len(config.Bridges[i].Members) {
+			if !hwaddrhRegex.MatchString(config.Bridges[i].Members[j]) {
+				hwaddr, err := getMacForInterface(ctx, config.Bridges[i].Members[j])
+				if err != nil {
+					return fmt.Errorf("bridge %d member %d failed getting MAC for '%s': %s", i, j, config.Bridges[i].Members[j], err.Error())
+				}
+
+				config.Bridges[i].Members[j] = hwaddr
+			}
+		}
+	}
+
+	for i := range len(config.Wifi) {
+		if !hwaddrhRegex.MatchString(config.Wifi[i].Hwaddr) {
+			hwaddr, err := getMacForInterface(ctx, config.Wifi[i].Hwaddr)
+			if err != nil {
+				return fmt.Errorf("wifi %d failed getting MAC for '%s': %s", i, config.Wifi[i].Hwaddr, err.Error())
+			}
+
+			config.Wifi[i].Hwaddr = hwaddr
+		}
+	}
+
 	return nil
 }
 
@@ -1998,12 +2955,69 @@ func getMacForInterface(ctx context.Context, iface string) (string, error) {
 	return match[0][1], nil
 }
 
+// getMacForPath returns the permanent MAC address of the network device found at a given udev path.
+func getMacForPath(ctx context.Context, path string) (string, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return "", err
+	}
+
+	for _, i := range ifaces {
+		if getDevicePath(ctx, i.Name) != path {
+			continue
+		}
+
+		output, err := subprocess.RunCommandContext(ctx, "ip", "-j", "link", "show", "dev", i.Name)
+		if err != nil {
+			return "", err
+		}
+
+		return parsePermanentMAC(output)
+	}
+
+	return "", errors.New("no device found")
+}
+
+// getDevicePath returns the udev path (ID_PATH) of a network device, or an empty string for virtual devices.
+func getDevicePath(ctx context.Context, iface string) string {
+	output, err := subprocess.RunCommandContext(ctx, "udevadm", "info", "--query=property", "--property=ID_PATH", "--value", "/sys/class/net/"+iface)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(output)
+}
+
+// parsePermanentMAC parses the JSON output of "ip link show", returning the permanent MAC address.
+// The permanent address is only reported separately when it differs from the current one.
+func parsePermanentMAC(output string) (string, error) {
+	links := []struct {
+		Address  string `json:"address"`
+		Permaddr string `json:"permaddr"`
+	}{}
+
+	err := json.Unmarshal([]byte(output), &links)
+	if err != nil {
+		return "", err
+	}
+
+	if len(links) != 1 {
+		return "", errors.New("no MAC address found")
+	}
+
+	if links[0].Permaddr != "" {
+		return links[0].Permaddr, nil
+	}
+
+	return links[0].Address, nil
+}
+
 func getExpectedNewPhysicalDevices(ctx context.Context, config *api.SystemNetworkConfig) []string {
 	devices := []string{}
 	ret := []string{}
 
-	// Get a list of all the expected "_p" physical devices referenced by the interfaces or bond
-	// members in the given network configuration.
+	// Get a list of all the expected "_p" physical devices referenced by the interfaces, bond
+	// or bridge members in the given network configuration.
 	for i := range config.Interfaces {
 		devices = append(devices, "_p"+strings.ToLower(strings.ReplaceAll(config.Interfaces[i].Hwaddr, ":", "")))
 	}
@@ -2014,6 +3028,17 @@ func getExpectedNewPhysicalDevices(ctx context.Context, config *api.SystemNetwor
 		}
 	}
 
+	for i := range config.Bridges {
+		for j := range config.Bridges[i].Members {
+			devices = append(devices, "_p"+strings.ToLower(strings.ReplaceAll(config.Bridges[i].Members[j], ":", "")))
+		}
+	}
+
+	// Wi-Fi devices are directly renamed to their configured name.
+	for i := range config.Wifi {
+		devices = append(devices, config.Wifi[i].Name)
+	}
+
 	// Check if the given device is already known to networkd; if not, add it to the list
 	// of devices we need to wait for.
 	for _, dev := range devices {
@@ -184,6 +184,7 @@ interfaces:
       disable_ipv6_tso: true
       disable_gro: true
       disable_gso: true
+      disable_lro: true
       wakeonlan: true
       wakeonlan_modes:
       - magic
@@ -213,6 +214,25 @@ bonds:
       disable_ipv6_tso: true
 `
 
+var networkdConfig6 = `
+bridges:
+  - name: br0
+    stp: true
+    vlan_filtering: true
+    vlan_tags:
+      - 200
+    addresses:
+      - dhcp4
+    members:
+      - AA:BB:CC:DD:EE:05
+      - AA:BB:CC:DD:EE:06
+
+vlans:
+  - name: instances
+    parent: br0
+    id: 300
+`
+
 var badNetworkdConfig1 = `
 interfaces:
   - name: myreallylongname
@@ -265,6 +285,62 @@ wireguard:
       - 192.168.0.100/24
 `
 
+var badNetworkdConfig7 = `
+interfaces:
+  - name: eth0
+    hwaddr: AA:BB:CC:DD:EE:01
+
+vlans:
+  - name: mgmt
+    parent: eth0
+    id: 100
+  - name: storage
+    parent: eth0
+    id: 100
+`
+
+var badNetworkdConfig8 = `
+interfaces:
+  - name: eth0
+    hwaddr: AA:BB:CC:DD:EE:01
+
+vlans:
+  - name: mgmt
+    parent: eth0
+    id: 0
+`
+
+var badNetworkdConfig9 = `
+interfaces:
+  - name: eth0
+    hwaddr: AA:BB:CC:DD:EE:01
+
+bridges:
+  - name: br0
+    members:
+      - aa:bb:cc:dd:ee:01
+`
+
+var badNetworkdConfig10 = `
+bridges:
+  - name: br0
+    vlan_tags:
+      - 100
+    members:
+      - AA:BB:CC:DD:EE:05
+`
+
+var badNetworkdConfig11 = `
+interfaces:
+  - name: san
+    hwaddr: AA:BB:CC:DD:EE:01
+vlans:
+  - name: storage
+    parent: san
+    id: 20
+    mtu: 9000
+`
+
 func TestBadNetworkConfig(t *testing.T) {
 	t.Parallel()
 
@@ -295,7 +371,7 @@ func TestBadNetworkConfig(t *testing.T) {
 		require.NoError(t, err)
 
 		err = ValidateNetworkConfiguration(&cfg, false)
-		require.EqualError(t, err, "duplicate interface/bond/vlan/wireguard name: iface")
+		require.EqualError(t, err, "duplicate interface/bond/bridge/vlan/wifi/pppoe/wireguard/tunnel name: iface")
 	}
 
 	{
@@ -327,6 +403,56 @@ func TestBadNetworkConfig(t *testing.T) {
 		err = ValidateNetworkConfiguration(&cfg, false)
 		require.EqualError(t, err, "wireguard 0 port '65536' out of range")
 	}
+
+	{
+		var cfg api.SystemNetworkConfig
+
+		err := yaml.Unmarshal([]byte(badNetworkdConfig7), &cfg)
+		require.NoError(t, err)
+
+		err = ValidateNetworkConfiguration(&cfg, false)
+		require.EqualError(t, err, "vlan 1 ID 100 already defined on parent 'eth0' by vlan 0")
+	}
+
+	{
+		var cfg api.SystemNetworkConfig
+
+		err := yaml.Unmarshal([]byte(badNetworkdConfig8), &cfg)
+		require.NoError(t, err)
+
+		err = ValidateNetworkConfiguration(&cfg, false)
+		require.EqualError(t, err, "vlan 0 ID 0 out of range")
+	}
+
+	{
+		var cfg api.SystemNetworkConfig
+
+		err := yaml.Unmarshal([]byte(badNetworkdConfig9), &cfg)
+		require.NoError(t, err)
+
+		err = ValidateNetworkConfiguration(&cfg, false)
+		require.EqualError(t, err, "bridge 0 member 0 'aa:bb:cc:dd:ee:01' is already in use")
+	}
+
+	{
+		var cfg api.SystemNetworkConfig
+
+		err := yaml.Unmarshal([]byte(badNetworkdConfig10), &cfg)
+		require.NoError(t, err)
+
+		err = ValidateNetworkConfiguration(&cfg, false)
+		require.EqualError(t, err, "bridge 0 VLAN tags require VLAN filtering")
+	}
+
+	{
+		var cfg api.SystemNetworkConfig
+
+		err := yaml.Unmarshal([]byte(badNetworkdConfig11), &cfg)
+		require.NoError(t, err)
+
+		err = ValidateNetworkConfiguration(&cfg, false)
+		require.EqualError(t, err, "vlan 0 MTU 9000 is larger than parent 'san' MTU 1500")
+	}
 }
 
 func TestNetworkConfigMarshalling(t *testing.T) {
@@ -564,7 +690,7 @@ func TestLinkFileGeneration(t *testing.T) {
 	cfgs = generateLinkFileContents(networkCfg)
 	require.Len(t, cfgs, 3)
 	require.Equal(t, "00-_paabbccddee01.link", cfgs[0].Name)
-	require.Equal(t, "[Match]\nPermanentMACAddress=AA:BB:CC:DD:EE:01\n\n[Link]\nMACAddressPolicy=random\nNamePolicy=\nName=_paabbccddee01\nGenericSegmentationOffload=false\nGenericReceiveOffload=false\nTCPSegmentationOffload=false\nTCP6SegmentationOffload=false\nWakeOnLan=magic\nWakeOnLan=secureon\nWakeOnLanPassword=11:22:33:44:55:66\n[EnergyEfficientEthernet]\nEnable=false", cfgs[0].Contents)
+	require.Equal(t, "[Match]\nPermanentMACAddress=AA:BB:CC:DD:EE:01\n\n[Link]\nMACAddressPolicy=random\nNamePolicy=\nName=_paabbccddee01\nGenericReceiveOffload=false\nGenericSegmentationOffload=false\nTCPSegmentationOffload=false\nTCP6SegmentationOffload=false\nLargeReceiveOffload=false\nWakeOnLan=magic\nWakeOnLan=secureon\nWakeOnLanPassword=11:22:33:44:55:66\n[EnergyEfficientEthernet]\nEnable=false", cfgs[0].Contents)
 	require.Equal(t, "01-_paabbccddee02.link", cfgs[1].Name)
 	require.Equal(t, "[Match]\nPermanentMACAddress=AA:BB:CC:DD:EE:02\n\n[Link]\nNamePolicy=\nName=_paabbccddee02\nTCPSegmentationOffload=false\nTCP6SegmentationOffload=false\n[EnergyEfficientEthernet]\nEnable=false", cfgs[1].Contents)
 	require.Equal(t, "01-_paabbccddee03.link", cfgs[2].Name)
@@ -724,7 +850,7 @@ func TestNetworkFileGeneration(t *testing.T) {
 	cfgs = generateNetworkFileContents(networkCfg)
 	require.Len(t, cfgs, 4)
 	require.Equal(t, "20-_vffeeddccbbaa.network", cfgs[0].Name)
-	require.Equal(t, "[Match]\nName=_vffeeddccbbaa\n\n[Link]\nRequiredForOnline=no\n\n[DHCP]\nClientIdentifier=mac\nRouteMetric=100\nUseMTU=true\n\n[Network]\nDomains=example.org\nDNS=ns1.example.org\nDNS=ns2.example.org\nNTP=pool.ntp.example.org\nNTP=10.10.10.10\nLinkLocalAddressing=ipv6\nIPv6AcceptRA=false\nDHCP=ipv4\n", cfgs[0].Contents)
+	require.Equal(t, "[Match]\nName=_vffeeddccbbaa\n\n[Link]\nRequiredForOnline=no\n\n[DHCP]\nClientIdentifier=mac\nRouteMetric=100\nUseMTU=true\n\n[Network]\nDomains=example.org\nDNS=ns1.example.org\nDNS=ns2.example.org\nLinkLocalAddressing=ipv6\nIPv6AcceptRA=false\nDHCP=ipv4\n", cfgs[0].Contents)
 	require.Equal(t, "20-_iffeeddccbbaa.network", cfgs[1].Name)
 	require.Equal(t, "[Match]\nName=_iffeeddccbbaa\n\n[Network]\nBridge=ffeeddccbbaa\n", cfgs[1].Contents)
 	require.Equal(t, "20-_pffeeddccbbaa.network", cfgs[2].Name)
@@ -754,3 +880,630 @@ func TestNetworkFileGeneration(t *testing.T) {
 	require.Equal(t, "22-management.network", cfgs[6].Name)
 	require.Equal(t, "[Match]\nName=management\n\n[Link]\nRequiredForOnline=yes\nRequiredFamilyForOnline=both\n\n[DHCP]\nClientIdentifier=mac\nRouteMetric=100\nUseMTU=true\n\n[Network]\nLinkLocalAddressing=ipv6\nIPv6AcceptRA=true\nDHCP=ipv4\n", cfgs[6].Contents)
 }
+
+var bondingStatus = `Ethernet Channel Bonding Driver: v6.12.0
+
+Bonding Mode: IEEE 802.3ad Dynamic link aggregation
+Transmit Hash Policy: layer3+4 (1)
+MII Status: up
+MII Polling Interval (ms): 100
+Up Delay (ms): 0
+Down Delay (ms): 0
+Peer Notification Delay (ms): 0
+
+802.3ad info
+LACP active: on
+LACP rate: fast
+Min links: 0
+Aggregator selection policy (ad_select): stable
+
+Slave Interface: _paabbccddee03
+MII Status: up
+Speed: 10000 Mbps
+Duplex: full
+Link Failure Count: 0
+Permanent HW addr: aa:bb:cc:dd:ee:03
+Slave queue ID: 0
+Aggregator ID: 1
+details actor lacp pdu:
+    system priority: 65535
+    port state: 63
+
+Slave Interface: _paabbccddee04
+MII Status: down
+Speed: Unknown
+Duplex: Unknown
+Link Failure Count: 2
+Permanent HW addr: aa:bb:cc:dd:ee:04
+Slave queue ID: 0
+Aggregator ID: 2
+`
+
+func TestParseBondingStatus(t *testing.T) {
+	t.Parallel()
+
+	bondState, memberStates := parseBondingStatus(bondingStatus)
+	require.Equal(t, api.SystemNetworkBondState{MIIStatus: "up", LinkFailures: 2}, bondState)
+	require.Len(t, memberStates, 2)
+	require.Equal(t, api.SystemNetworkBondState{MIIStatus: "up"}, memberStates["_paabbccddee03"])
+	require.Equal(t, api.SystemNetworkBondState{MIIStatus: "down", LinkFailures: 2}, memberStates["_paabbccddee04"])
+
+	bondState, _ = parseBondingStatus("Bonding Mode: fault-tolerance (active-backup)\nCurrently Active Slave: _paabbccddee03\nMII Status: up\n\nSlave Interface: _paabbccddee03\nMII Status: up\nLink Failure Count: 0\n")
+	require.Equal(t, "_paabbccddee03", bondState.ActiveMember)
+}
+
+func TestBondOptions(t *testing.T) {
+	t.Parallel()
+
+	bond := api.SystemNetworkBond{
+		Name:               "uplink",
+		Mode:               "802.3ad",
+		Members:            []string{"AA:BB:CC:DD:EE:03", "AA:BB:CC:DD:EE:04"},
+		LACPRate:           "fast",
+		TransmitHashPolicy: "layer3+4",
+		MIIMonitorInterval: 100,
+	}
+
+	err := ValidateNetworkConfiguration(&api.SystemNetworkConfig{Bonds: []api.SystemNetworkBond{bond}}, false)
+	require.NoError(t, err)
+
+	cfgs := generateNetdevFileContents(api.SystemNetworkConfig{Bonds: []api.SystemNetworkBond{bond}})
+	require.Equal(t, "11-_buplink.netdev", cfgs[0].Name)
+	require.Equal(t, "[NetDev]\nName=_buplink\nKind=bond\n\n\n[Bond]\nMode=802.3ad\nLACPTransmitRate=fast\nTransmitHashPolicy=layer3+4\nMIIMonitorSec=100ms\n", cfgs[0].Contents)
+
+	bond.Mode = "active-backup"
+	err = ValidateNetworkConfiguration(&api.SystemNetworkConfig{Bonds: []api.SystemNetworkBond{bond}}, false)
+	require.EqualError(t, err, "bond 0 LACP rate requires mode '802.3ad'")
+
+	bond.LACPRate = ""
+	err = ValidateNetworkConfiguration(&api.SystemNetworkConfig{Bonds: []api.SystemNetworkBond{bond}}, false)
+	require.EqualError(t, err, "bond 0 transmit hash policy requires mode 'balance-xor', '802.3ad' or 'balance-tlb'")
+}
+
+func TestBridgeGeneration(t *testing.T) {
+	t.Parallel()
+
+	var networkCfg api.SystemNetworkConfig
+
+	err := yaml.Unmarshal([]byte(networkdConfig6), &networkCfg)
+	require.NoError(t, err)
+
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.NoError(t, err)
+
+	cfgs := generateLinkFileContents(networkCfg)
+	require.Len(t, cfgs, 2)
+	require.Equal(t, "02-_paabbccddee05.link", cfgs[0].Name)
+	require.Equal(t, "[Match]\nPermanentMACAddress=AA:BB:CC:DD:EE:05\n\n[Link]\nNamePolicy=\nName=_paabbccddee05\n", cfgs[0].Contents)
+	require.Equal(t, "02-_paabbccddee06.link", cfgs[1].Name)
+
+	cfgs = generateNetdevFileContents(networkCfg)
+	require.Len(t, cfgs, 3)
+	require.Equal(t, "14-br0.netdev", cfgs[0].Name)
+	require.Equal(t, "[NetDev]\nName=br0\nKind=bridge\n\n\n[Bridge]\nSTP=true\nVLANFiltering=true\n", cfgs[0].Contents)
+	require.Equal(t, "14-_vbr0.netdev", cfgs[1].Name)
+	require.Equal(t, "[NetDev]\nName=_vbr0\nKind=veth\nMACAddress=AA:BB:CC:DD:EE:05\n\n\n[Peer]\nName=_iaabbccddee05\n", cfgs[1].Contents)
+	require.Equal(t, "12-instances.netdev", cfgs[2].Name)
+
+	cfgs = generateNetworkFileContents(networkCfg)
+	require.Len(t, cfgs, 6)
+	require.Equal(t, "24-_vbr0.network", cfgs[0].Name)
+	require.Equal(t, "[Match]\nName=_vbr0\n\n[Link]\nRequiredForOnline=yes\nRequiredFamilyForOnline=any\n\n[DHCP]\nClientIdentifier=mac\nRouteMetric=100\nUseMTU=true\n\n[Network]\nVLAN=instances\nLinkLocalAddressing=ipv6\nIPv6AcceptRA=false\nDHCP=ipv4\n", cfgs[0].Contents)
+	require.Equal(t, "24-_iaabbccddee05.network", cfgs[1].Name)
+	require.Equal(t, "[Match]\nName=_iaabbccddee05\n\n[Network]\nBridge=br0\n\n[BridgeVLAN]\nVLAN=200\n\n[BridgeVLAN]\nVLAN=300\n", cfgs[1].Contents)
+	require.Equal(t, "24-br0.network", cfgs[2].Name)
+	require.Equal(t, "[Match]\nName=br0\n\n[Network]\nLinkLocalAddressing=no\nConfigureWithoutCarrier=yes\n", cfgs[2].Contents)
+	require.Equal(t, "24-br0-dev0.network", cfgs[3].Name)
+	require.Equal(t, "[Match]\nName=_paabbccddee05\n\n[Network]\nLinkLocalAddressing=no\nConfigureWithoutCarrier=yes\nBridge=br0\n\n[BridgeVLAN]\nVLAN=200\n\n[BridgeVLAN]\nVLAN=300\n", cfgs[3].Contents)
+	require.Equal(t, "24-br0-dev1.network", cfgs[4].Name)
+	require.Equal(t, "22-instances.network", cfgs[5].Name)
+}
+
+func TestRoutingPolicy(t *testing.T) {
+	t.Parallel()
+
+	iface := api.SystemNetworkInterface{
+		Name:      "storage",
+		Hwaddr:    "AA:BB:CC:DD:EE:01",
+		Addresses: []string{"10.0.200.10/24"},
+		Routes: []api.SystemNetworkRoute{
+			{To: "0.0.0.0/0", Via: "10.0.200.1", Metric: 200, Table: 100},
+		},
+		RoutingRules: []api.SystemNetworkRoutingRule{
+			{From: "10.0.200.0/24", Table: 100, Priority: 1000},
+		},
+	}
+
+	err := ValidateNetworkConfiguration(&api.SystemNetworkConfig{Interfaces: []api.SystemNetworkInterface{iface}}, true)
+	require.NoError(t, err)
+
+	cfgs := generateNetworkFileContents(api.SystemNetworkConfig{Interfaces: []api.SystemNetworkInterface{iface}})
+	require.Equal(t, "20-_vstorage.network", cfgs[0].Name)
+	require.Equal(t, "[Match]\nName=_vstorage\n\n[Link]\nRequiredForOnline=yes\nRequiredFamilyForOnline=any\n\n[DHCP]\nClientIdentifier=mac\nRouteMetric=100\nUseMTU=true\n\n[Network]\nLinkLocalAddressing=ipv6\nAddress=10.0.200.10/24\nIPv6AcceptRA=false\n\n[Route]\nGateway=10.0.200.1\nDestination=0.0.0.0/0\nMetric=200\nTable=100\n\n[RoutingPolicyRule]\nFrom=10.0.200.0/24\nTable=100\nPriority=1000\n", cfgs[0].Contents)
+
+	iface.RoutingRules = []api.SystemNetworkRoutingRule{{Table: 100}}
+	err = ValidateNetworkConfiguration(&api.SystemNetworkConfig{Interfaces: []api.SystemNetworkInterface{iface}}, true)
+	require.EqualError(t, err, "interface 0 routing rule 0 must match on 'From' or 'To'")
+
+	iface.RoutingRules = []api.SystemNetworkRoutingRule{{To: "10.0.300.0/24", Table: 100}}
+	err = ValidateNetworkConfiguration(&api.SystemNetworkConfig{Interfaces: []api.SystemNetworkInterface{iface}}, true)
+	require.EqualError(t, err, "interface 0 routing rule 0 'To' invalid subnet '10.0.300.0/24'")
+
+	iface.RoutingRules = []api.SystemNetworkRoutingRule{{From: "10.0.200.0/24"}}
+	err = ValidateNetworkConfiguration(&api.SystemNetworkConfig{Interfaces: []api.SystemNetworkInterface{iface}}, true)
+	require.EqualError(t, err, "interface 0 routing rule 0 table '0' out of range")
+}
+
+func TestWifi(t *testing.T) {
+	t.Parallel()
+
+	wifi := api.SystemNetworkWifi{
+		Name:      "wlan",
+		Hwaddr:    "AA:BB:CC:DD:EE:07",
+		SSID:      "edge",
+		PSK:       "supersecret",
+		Security:  "wpa2-wpa3",
+		Country:   "CA",
+		Addresses: []string{"dhcp4"},
+	}
+
+	err := ValidateNetworkConfiguration(&api.SystemNetworkConfig{Wifi: []api.SystemNetworkWifi{wifi}}, true)
+	require.NoError(t, err)
+
+	cfgs := generateLinkFileContents(api.SystemNetworkConfig{Wifi: []api.SystemNetworkWifi{wifi}})
+	require.Len(t, cfgs, 1)
+	require.Equal(t, "03-wlan.link", cfgs[0].Name)
+	require.Equal(t, "[Match]\nPermanentMACAddress=AA:BB:CC:DD:EE:07\n\n[Link]\nNamePolicy=\nName=wlan\n", cfgs[0].Contents)
+
+	cfgs = generateNetworkFileContents(api.SystemNetworkConfig{Wifi: []api.SystemNetworkWifi{wifi}})
+	require.Len(t, cfgs, 1)
+	require.Equal(t, "25-wlan.network", cfgs[0].Name)
+	require.Equal(t, "[Match]\nName=wlan\n\n[Link]\nRequiredForOnline=yes\nRequiredFamilyForOnline=any\n\n[DHCP]\nClientIdentifier=mac\nRouteMetric=100\nUseMTU=true\n\n[Network]\nLinkLocalAddressing=ipv6\nIPv6AcceptRA=false\nDHCP=ipv4\n", cfgs[0].Contents)
+
+	require.Equal(t, "ctrl_interface=/run/wpa_supplicant\ncountry=CA\n\nnetwork={\n\tssid=65646765\n\tkey_mgmt=WPA-PSK SAE\n\tieee80211w=1\n\tpsk=\"supersecret\"\n\tsae_password=\"supersecret\"\n}\n", generateWpaSupplicantContents(wifi))
+
+	wifi.PSK = ""
+	wifi.Security = ""
+	wifi.EAP = &api.SystemNetworkEAP{Method: "peap", Identity: "user", Password: "pass"}
+	err = ValidateNetworkConfiguration(&api.SystemNetworkConfig{Wifi: []api.SystemNetworkWifi{wifi}}, true)
+	require.NoError(t, err)
+	require.Equal(t, "ctrl_interface=/run/wpa_supplicant\ncountry=CA\n\nnetwork={\n\tssid=65646765\n\tkey_mgmt=WPA-EAP\n\teap=PEAP\n\tidentity=\"user\"\n\tpassword=\"pass\"\n\tphase2=\"auth=MSCHAPV2\"\n}\n", generateWpaSupplicantContents(wifi))
+
+	wifi.EAP.Method = "tls"
+	err = ValidateNetworkConfiguration(&api.SystemNetworkConfig{Wifi: []api.SystemNetworkWifi{wifi}}, true)
+	require.EqualError(t, err, "wifi 0 EAP method 'tls' requires a client certificate and key")
+
+	wifi.EAP = nil
+	wifi.PSK = "short"
+	err = ValidateNetworkConfiguration(&api.SystemNetworkConfig{Wifi: []api.SystemNetworkWifi{wifi}}, true)
+	require.EqualError(t, err, "wifi 0 PSK must be between 8 and 63 characters")
+}
+
+func TestParseWifiStatus(t *testing.T) {
+	t.Parallel()
+
+	state := &api.SystemNetworkWifiState{}
+	parseWifiStatus("bssid=11:22:33:44:55:66\nfreq=5180\nssid=edge\nid=0\nmode=station\nkey_mgmt=WPA2-PSK\nwpa_state=COMPLETED\n", state)
+	parseWifiStatus("RSSI=-54\nLINKSPEED=866\nNOISE=9999\nFREQUENCY=5180\n", state)
+
+	require.Equal(t, api.SystemNetworkWifiState{
+		BSSID:     "11:22:33:44:55:66",
+		Frequency: 5180,
+		LinkSpeed: 866,
+		Security:  "WPA2-PSK",
+		Signal:    -54,
+		SSID:      "edge",
+		State:     "completed",
+	}, *state)
+}
+
+func TestChrony(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "driftfile /var/lib/chrony/chrony.drift\nntsdumpdir /var/lib/chrony\nmakestep 1.0 3\nrtcsync\nleapsectz right/UTC\n\nsourcedir /run/chrony-networkd\npool 2.debian.pool.ntp.org iburst\n", generateChronyContents(api.SystemNetworkTime{}))
+	require.Equal(t, "driftfile /var/lib/chrony/chrony.drift\nntsdumpdir /var/lib/chrony\nmakestep 1.0 3\nrtcsync\nleapsectz right/UTC\n\nserver time.cloudflare.com iburst nts\nserver nts.netnod.se iburst nts\n", generateChronyContents(api.SystemNetworkTime{NTPServers: []string{"time.cloudflare.com", "nts.netnod.se"}, NTS: true}))
+
+	require.Equal(t, api.SystemNetworkTimeState{
+		Offset:       -0.000012345,
+		Server:       "time.cloudflare.com",
+		Stratum:      4,
+		Synchronized: true,
+	}, *parseChronyTracking("A29FC87B,time.cloudflare.com,4,1760534412.123456789,-0.000012345,0.000001234,0.000023456,-12.345,0.001,0.042,0.012345678,0.000456789,64.4,Normal\n"))
+
+	err := ValidateNetworkConfiguration(&api.SystemNetworkConfig{Time: &api.SystemNetworkTime{NTS: true}}, true)
+	require.EqualError(t, err, "NTS requires at least one NTP server")
+
+	require.Equal(t, api.SystemNetworkTimeState{}, *parseChronyTracking("7F7F0101,,0,0.000000000,0.000000000,0.000000000,0.000000000,0.000,0.000,0.000,1.000000000,1.000000000,0.0,Not synchronised\n"))
+}
+
+func TestParseNetworkdNTPServers(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{"192.0.2.1", "2001:db8::1"}, parseNetworkdNTPServers("# This is private data. Do not parse.\nADMIN_STATE=configured\nOPER_STATE=routable\nNTP=192.0.2.1 2001:db8::1\nDNS=192.0.2.53\n"))
+	require.Equal(t, []string{}, parseNetworkdNTPServers("ADMIN_STATE=configured\nOPER_STATE=routable\n"))
+}
+
+func TestDHCPServer(t *testing.T) {
+	t.Parallel()
+
+	networkCfg := api.SystemNetworkConfig{
+		Interfaces: []api.SystemNetworkInterface{
+			{Name: "lab", Hwaddr: "AA:BB:CC:DD:EE:01", Addresses: []string{"10.10.0.1/24"}},
+		},
+		DHCPServer: &api.SystemNetworkDHCPServer{
+			Interface:    "lab",
+			PoolOffset:   100,
+			PoolSize:     50,
+			LeaseTime:    3600,
+			DNS:          []string{"10.10.0.1"},
+			BootServer:   "10.10.0.1",
+			BootFilename: "ipxe.efi",
+			StaticLeases: []api.SystemNetworkDHCPStaticLease{{Hwaddr: "AA:BB:CC:DD:EE:10", Address: "10.10.0.10"}},
+		},
+	}
+
+	err := ValidateNetworkConfiguration(&networkCfg, true)
+	require.NoError(t, err)
+
+	cfgs := generateNetworkFileContents(networkCfg)
+	require.Equal(t, "20-_vlab.network", cfgs[0].Name)
+	require.Equal(t, "[Match]\nName=_vlab\n\n[Link]\nRequiredForOnline=yes\nRequiredFamilyForOnline=any\n\n[DHCP]\nClientIdentifier=mac\nRouteMetric=100\nUseMTU=true\n\n[Network]\nLinkLocalAddressing=ipv6\nAddress=10.10.0.1/24\nIPv6AcceptRA=false\nDHCPServer=yes\n\n[DHCPServer]\nPoolOffset=100\nPoolSize=50\nDefaultLeaseTimeSec=3600\nMaxLeaseTimeSec=3600\nDNS=10.10.0.1\nBootServerAddress=10.10.0.1\nBootFilename=ipxe.efi\n\n[DHCPServerStaticLease]\nMACAddress=AA:BB:CC:DD:EE:10\nAddress=10.10.0.10\n", cfgs[0].Contents)
+
+	networkCfg.Interfaces[0].Addresses = []string{"dhcp4"}
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "dhcp server interface 'lab' has no static IPv4 address")
+
+	networkCfg.DHCPServer.Interface = "missing"
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "dhcp server unable to find interface 'missing'")
+}
+
+func TestIPv6(t *testing.T) {
+	t.Parallel()
+
+	networkCfg := api.SystemNetworkConfig{
+		Interfaces: []api.SystemNetworkInterface{
+			{Name: "wan", Hwaddr: "AA:BB:CC:DD:EE:01", Addresses: []string{"dhcp6"}},
+			{Name: "lan", Hwaddr: "AA:BB:CC:DD:EE:02", Addresses: []string{"10.0.0.1/24"}},
+		},
+		IPv6: &api.SystemNetworkIPv6{
+			PrefixDelegation: &api.SystemNetworkIPv6PrefixDelegation{
+				Uplink:       "wan",
+				PrefixLength: 56,
+				Downstreams:  []api.SystemNetworkIPv6PrefixDownstream{{Interface: "lan", SubnetID: 1}},
+			},
+			RouterAdvertisements: []api.SystemNetworkIPv6RouterAdvertisement{
+				{Interface: "lan", OtherInformation: true, RouterLifetime: 1800, DNS: []string{"2001:db8::53"}, Prefixes: []string{"fd00:1::/64"}},
+			},
+		},
+	}
+
+	err := ValidateNetworkConfiguration(&networkCfg, true)
+	require.NoError(t, err)
+
+	contents := map[string]string{}
+	for _, cfg := range generateNetworkFileContents(networkCfg) {
+		contents[cfg.Name] = cfg.Contents
+	}
+
+	require.Equal(t, "[Match]\nName=_vwan\n\n[Link]\nRequiredForOnline=yes\nRequiredFamilyForOnline=any\n\n[DHCP]\nClientIdentifier=mac\nRouteMetric=100\nUseMTU=true\n\n[Network]\nLinkLocalAddressing=ipv6\nIPv6AcceptRA=false\nDHCP=ipv6\n\n[DHCPv6]\nWithoutRA=solicit\nPrefixDelegationHint=::/56\n", contents["20-_vwan.network"])
+	require.Equal(t, "[Match]\nName=_vlan\n\n[Link]\nRequiredForOnline=yes\nRequiredFamilyForOnline=any\n\n[DHCP]\nClientIdentifier=mac\nRouteMetric=100\nUseMTU=true\n\n[Network]\nLinkLocalAddressing=ipv6\nAddress=10.0.0.1/24\nIPv6AcceptRA=false\n\n[Network]\nDHCPPrefixDelegation=yes\n\n[DHCPPrefixDelegation]\nUplinkInterface=_vwan\nSubnetId=0x1\nAnnounce=yes\nAssign=yes\n\n[IPv6SendRA]\nManaged=false\nOtherInformation=true\nRouterLifetimeSec=1800\nDNS=2001:db8::53\n\n[IPv6Prefix]\nPrefix=fd00:1::/64\n\n[Network]\nIPv6SendRA=yes\n", contents["20-_vlan.network"])
+
+	networkCfg.IPv6.RouterAdvertisements[0].Prefixes = []string{"10.1.0.0/24"}
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "router advertisement 0 invalid IPv6 prefix '10.1.0.0/24'")
+
+	networkCfg.IPv6.PrefixDelegation.Downstreams[0].Interface = "wan"
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "prefix delegation downstream 0 invalid interface 'wan'")
+
+	networkCfg.Interfaces[0].Addresses = []string{"dhcp4"}
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "prefix delegation uplink 'wan' must use dhcp6")
+}
+
+func TestDNSRoutes(t *testing.T) {
+	t.Parallel()
+
+	networkCfg := api.SystemNetworkConfig{
+		DNS: &api.SystemNetworkDNS{
+			DNSOverTLS:  "yes",
+			Nameservers: []string{"9.9.9.9#dns.quad9.net"},
+			Routes: []api.SystemNetworkDNSRoute{
+				{Interface: "corp", Domains: []string{"corp.example.com"}, Nameservers: []string{"10.1.0.53#ns.corp.example.com"}},
+			},
+		},
+		Interfaces: []api.SystemNetworkInterface{
+			{Name: "wan", Hwaddr: "AA:BB:CC:DD:EE:01", Addresses: []string{"dhcp4"}},
+		},
+		VLANs: []api.SystemNetworkVLAN{
+			{Name: "corp", Parent: "wan", ID: 10, Addresses: []string{"10.1.0.10/24"}},
+		},
+	}
+
+	err := ValidateNetworkConfiguration(&networkCfg, true)
+	require.NoError(t, err)
+
+	require.Equal(t, "DNS=9.9.9.9#dns.quad9.net\nDNSOverTLS=yes\n", generateNetworkSectionContents("wan", nil, networkCfg.DNS))
+	require.Equal(t, "Domains=~corp.example.com\nDNS=10.1.0.53#ns.corp.example.com\nDNSOverTLS=yes\n", generateNetworkSectionContents("corp", nil, networkCfg.DNS))
+
+	networkCfg.DNS.Nameservers = []string{"https://dns.quad9.net/dns-query"}
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "DNS over HTTPS name server 'https://dns.quad9.net/dns-query' isn't supported")
+
+	networkCfg.DNS.Nameservers = nil
+	networkCfg.DNS.Routes[0].Interface = "missing"
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "dns route 0 unable to find interface 'missing'")
+
+	networkCfg.DNS.DNSOverTLS = "always"
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "unsupported DNS over TLS mode 'always'")
+}
+
+func TestSRIOV(t *testing.T) {
+	t.Parallel()
+
+	networkCfg := api.SystemNetworkConfig{
+		Interfaces: []api.SystemNetworkInterface{
+			{
+				Name:   "uplink",
+				Hwaddr: "AA:BB:CC:DD:EE:01",
+				SRIOV: &api.SystemNetworkSRIOV{
+					VFs: 4,
+					VirtualFunctions: []api.SystemNetworkSRIOVVF{
+						{Index: 0, Hwaddr: "AA:BB:CC:DD:EE:10", VLAN: 100, Trust: true},
+						{Index: 3, DisableSpoofCheck: true},
+					},
+				},
+			},
+		},
+	}
+
+	err := ValidateNetworkConfiguration(&networkCfg, true)
+	require.NoError(t, err)
+
+	cfgs := generateLinkFileContents(networkCfg)
+	require.Len(t, cfgs, 1)
+	require.Equal(t, "[Match]\nPermanentMACAddress=AA:BB:CC:DD:EE:01\n\n[Link]\nMACAddressPolicy=random\nNamePolicy=\nName=_paabbccddee01\n\n[Link]\nSR-IOVVirtualFunctions=4\n\n[SR-IOV]\nVirtualFunction=0\nMACAddress=AA:BB:CC:DD:EE:10\nVLANId=100\nTrust=true\nMACSpoofCheck=true\n\n[SR-IOV]\nVirtualFunction=3\nTrust=false\nMACSpoofCheck=false\n", cfgs[0].Contents)
+
+	networkCfg.Interfaces[0].SRIOV.VirtualFunctions[1].Index = 4
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "interface 0 SR-IOV VF index 4 out of range")
+
+	networkCfg.Interfaces[0].SRIOV.VirtualFunctions[1].Index = 0
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "interface 0 SR-IOV VF 0 configured more than once")
+}
+
+func TestWiredAuth(t *testing.T) {
+	t.Parallel()
+
+	networkCfg := api.SystemNetworkConfig{
+		Interfaces: []api.SystemNetworkInterface{
+			{
+				Name:   "campus",
+				Hwaddr: "AA:BB:CC:DD:EE:01",
+				EAP: &api.SystemNetworkEAP{
+					Method:            "tls",
+					Identity:          "node01",
+					CACertificate:     "CA",
+					ClientCertificate: "CERT",
+					ClientKey:         "KEY",
+				},
+			},
+		},
+	}
+
+	err := ValidateNetworkConfiguration(&networkCfg, true)
+	require.NoError(t, err)
+	require.Equal(t, "ctrl_interface=/run/wpa_supplicant\nap_scan=0\n\nnetwork={\n\tkey_mgmt=IEEE8021X\n\teap=TLS\n\tidentity=\"node01\"\n\tca_cert=\"/etc/wpa_supplicant/wired-_paabbccddee01-ca.crt\"\n\tclient_cert=\"/etc/wpa_supplicant/wired-_paabbccddee01-client.crt\"\n\tprivate_key=\"/etc/wpa_supplicant/wired-_paabbccddee01-client.key\"\n}\n", generateWiredSupplicantContents("wired-_paabbccddee01", networkCfg.Interfaces[0].EAP))
+
+	networkCfg.Interfaces[0].EAP = &api.SystemNetworkEAP{Method: "peap", Identity: "user"}
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "interface 0 EAP method 'peap' requires an identity and password")
+
+	networkCfg.Interfaces[0].EAP.Method = "md5"
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "interface 0 invalid EAP method 'md5'")
+}
+
+func TestNeighbors(t *testing.T) {
+	t.Parallel()
+
+	networkCfg := api.SystemNetworkConfig{
+		Interfaces: []api.SystemNetworkInterface{
+			{
+				Name:      "uplink",
+				Hwaddr:    "AA:BB:CC:DD:EE:01",
+				Addresses: []string{"10.0.0.2/24", "2001:db8::2/64"},
+				Neighbors: []api.SystemNetworkNeighbor{{Address: "10.0.0.1", Hwaddr: "AA:BB:CC:DD:EE:FF"}},
+				ProxyARP:  true,
+				ProxyNDP:  []string{"2001:db8::10"},
+			},
+		},
+	}
+
+	err := ValidateNetworkConfiguration(&networkCfg, true)
+	require.NoError(t, err)
+
+	cfgs := generateNetworkFileContents(networkCfg)
+	require.Equal(t, "20-_vuplink.network", cfgs[0].Name)
+	require.Contains(t, cfgs[0].Contents, "\n[Network]\nProxyARP=yes\nIPv6ProxyNDP=yes\nIPv6ProxyNDPAddress=2001:db8::10\n\n[Neighbor]\nAddress=10.0.0.1\nLinkLayerAddress=AA:BB:CC:DD:EE:FF\n")
+
+	networkCfg.Interfaces[0].ProxyNDP = []string{"10.0.0.10"}
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "interface 0 invalid proxy NDP address '10.0.0.10'")
+
+	networkCfg.Interfaces[0].ProxyNDP = nil
+	networkCfg.Interfaces[0].Neighbors[0].Hwaddr = "invalid"
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "interface 0 neighbor 0 invalid MAC address 'invalid'")
+}
+
+func TestFailover(t *testing.T) {
+	t.Parallel()
+
+	networkCfg := api.SystemNetworkConfig{
+		Interfaces: []api.SystemNetworkInterface{
+			{Name: "fiber", Hwaddr: "AA:BB:CC:DD:EE:01", Addresses: []string{"dhcp4"}},
+			{Name: "lte", Hwaddr: "AA:BB:CC:DD:EE:02", Addresses: []string{"dhcp4"}},
+		},
+		Failover: &api.SystemNetworkFailover{
+			Uplinks: []string{"fiber", "lte"},
+			Targets: []string{"1.1.1.1", "9.9.9.9"},
+		},
+	}
+
+	err := ValidateNetworkConfiguration(&networkCfg, true)
+	require.NoError(t, err)
+
+	networkCfg.Failover.Uplinks = []string{"fiber", "dsl"}
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "failover uplink 1 unable to find 'dsl'")
+
+	networkCfg.Failover.Uplinks = []string{"fiber", "lte"}
+	networkCfg.Failover.Targets = []string{"one.one.one.one"}
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "failover target 0 invalid address 'one.one.one.one'")
+
+	gateway, found, err := parseDefaultGateway(`[{"dst":"default","gateway":"192.0.2.1","dev":"_vlte","protocol":"static","metric":10,"flags":[]},{"dst":"default","gateway":"192.0.2.254","dev":"_vlte","protocol":"dhcp","prefsrc":"192.0.2.10","metric":100,"flags":[]}]`)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "192.0.2.254", gateway)
+
+	_, found, err = parseDefaultGateway("[]")
+	require.NoError(t, err)
+	require.False(t, found)
+}
+
+func TestTunnels(t *testing.T) {
+	t.Parallel()
+
+	networkCfg := api.SystemNetworkConfig{
+		Tunnels: []api.SystemNetworkTunnel{
+			{Name: "gre0", Kind: "gre", Local: "192.0.2.10", Remote: "198.51.100.1", Key: 42, Addresses: []string{"10.99.0.1/30"}},
+			{Name: "overlay", Kind: "vxlan", Remote: "2001:db8::1", VNI: 1000, Port: 4789, MTU: 1450},
+			{Name: "gre6", Kind: "gretap", Remote: "2001:db8::2"},
+		},
+	}
+
+	err := ValidateNetworkConfiguration(&networkCfg, true)
+	require.NoError(t, err)
+
+	cfgs := generateNetdevFileContents(networkCfg)
+	require.Len(t, cfgs, 3)
+	require.Equal(t, "15-gre0.netdev", cfgs[0].Name)
+	require.Equal(t, "[NetDev]\nName=gre0\nKind=gre\n\n[Tunnel]\nRemote=198.51.100.1\nKey=42\nLocal=192.0.2.10\nIndependent=yes\n", cfgs[0].Contents)
+	require.Equal(t, "[NetDev]\nName=overlay\nKind=vxlan\nMTUBytes=1450\n\n[VXLAN]\nVNI=1000\nRemote=2001:db8::1\nDestinationPort=4789\nIndependent=yes\n", cfgs[1].Contents)
+	require.Equal(t, "[NetDev]\nName=gre6\nKind=ip6gretap\n\n[Tunnel]\nRemote=2001:db8::2\nIndependent=yes\n", cfgs[2].Contents)
+
+	cfgs = generateNetworkFileContents(networkCfg)
+	require.Len(t, cfgs, 3)
+	require.Equal(t, "26-gre0.network", cfgs[0].Name)
+	require.Equal(t, "[Match]\nName=gre0\n\n[Link]\nRequiredForOnline=yes\nRequiredFamilyForOnline=any\n\n[Network]\nLinkLocalAddressing=ipv6\nAddress=10.99.0.1/30\nIPv6AcceptRA=false\n", cfgs[0].Contents)
+
+	networkCfg.Tunnels[1].VNI = 0
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "tunnel 1 VNI '0' out of range")
+
+	networkCfg.Tunnels[1].VNI = 1000
+	networkCfg.Tunnels[0].Local = "2001:db8::10"
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "tunnel 0 invalid local address '2001:db8::10'")
+}
+
+func TestInterfacePath(t *testing.T) {
+	t.Parallel()
+
+	networkCfg := api.SystemNetworkConfig{
+		Interfaces: []api.SystemNetworkInterface{
+			{Name: "uplink", Hwaddr: "AA:BB:CC:DD:EE:01", Path: "pci-0000:05:00.0"},
+			{Name: "storage", Hwaddr: "AA:BB:CC:DD:EE:02", Path: "pci-0000:05:00.1"},
+		},
+	}
+
+	err := ValidateNetworkConfiguration(&networkCfg, true)
+	require.NoError(t, err)
+
+	cfgs := generateLinkFileContents(networkCfg)
+	require.Len(t, cfgs, 2)
+	require.Equal(t, "[Match]\nPath=pci-0000:05:00.0\n\n[Link]\nMACAddressPolicy=random\nNamePolicy=\nName=_paabbccddee01\n", cfgs[0].Contents)
+
+	networkCfg.Interfaces[1].Path = "pci-0000:05:00.0"
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "interface 1 path 'pci-0000:05:00.0' used more than once")
+
+	mac, err := parsePermanentMAC(`[{"ifindex":2,"ifname":"_paabbccddee01","flags":["BROADCAST","MULTICAST","UP","LOWER_UP"],"mtu":1500,"link_type":"ether","address":"3a:5c:08:91:7e:42","broadcast":"ff:ff:ff:ff:ff:ff","permaddr":"aa:bb:cc:dd:ee:01"}]`)
+	require.NoError(t, err)
+	require.Equal(t, "aa:bb:cc:dd:ee:01", mac)
+
+	mac, err = parsePermanentMAC(`[{"ifindex":3,"ifname":"enp6s0","link_type":"ether","address":"aa:bb:cc:dd:ee:03","broadcast":"ff:ff:ff:ff:ff:ff"}]`)
+	require.NoError(t, err)
+	require.Equal(t, "aa:bb:cc:dd:ee:03", mac)
+}
+
+func TestQoS(t *testing.T) {
+	t.Parallel()
+
+	networkCfg := api.SystemNetworkConfig{
+		Interfaces: []api.SystemNetworkInterface{
+			{
+				Name:   "uplink",
+				Hwaddr: "AA:BB:CC:DD:EE:01",
+				QoS:    &api.SystemNetworkQoS{EgressRate: 1000, HostEgressRate: 200, PriorityQueuing: "diffserv4"},
+			},
+		},
+	}
+
+	err := ValidateNetworkConfiguration(&networkCfg, true)
+	require.NoError(t, err)
+
+	cfgs := generateNetworkFileContents(networkCfg)
+	require.Len(t, cfgs, 4)
+	require.Equal(t, "20-_vuplink.network", cfgs[0].Name)
+	require.Contains(t, cfgs[0].Contents, "\n\n[CAKE]\nBandwidth=200M\n")
+	require.Equal(t, "20-_paabbccddee01.network", cfgs[2].Name)
+	require.Equal(t, "[Match]\nName=_paabbccddee01\n\n[Network]\nLLDP=false\nEmitLLDP=false\nBridge=uplink\n\n[CAKE]\nBandwidth=1000M\nPriorityQueueingPreset=diffserv4\n", cfgs[2].Contents)
+
+	networkCfg.Interfaces[0].QoS.HostEgressRate = 2000
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "interface 0 QoS host egress rate can't exceed the egress rate")
+
+	networkCfg.Interfaces[0].QoS.HostEgressRate = 0
+	networkCfg.Interfaces[0].QoS.PriorityQueuing = "strict"
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "interface 0 invalid QoS priority queuing 'strict'")
+}
+
+func TestPPPoE(t *testing.T) {
+	t.Parallel()
+
+	networkCfg := api.SystemNetworkConfig{
+		Interfaces: []api.SystemNetworkInterface{
+			{Name: "wan", Hwaddr: "AA:BB:CC:DD:EE:01"},
+		},
+		VLANs: []api.SystemNetworkVLAN{
+			{Name: "wan7", Parent: "wan", ID: 7},
+		},
+		PPPoE: []api.SystemNetworkPPPoE{
+			{Name: "dsl", Parent: "wan7", Username: "user@isp", Password: "secret", DefaultRoute: true, IPv6: true, MTU: 1492},
+		},
+	}
+
+	err := ValidateNetworkConfiguration(&networkCfg, true)
+	require.NoError(t, err)
+
+	require.Equal(t, "plugin pppoe.so\nnic-wan7\nifname dsl\nuser \"user@isp\"\npassword \"secret\"\nnoauth\nhide-password\npersist\nmaxfail 0\nholdoff 5\nlcp-echo-interval 20\nlcp-echo-failure 3\nnoipdefault\ndefaultroute\n+ipv6\nmtu 1492\nmru 1492\n", generatePPPoEPeerContents(networkCfg.PPPoE[0], &networkCfg))
+
+	networkCfg.PPPoE[0].Parent = "wan"
+	require.Contains(t, generatePPPoEPeerContents(networkCfg.PPPoE[0], &networkCfg), "nic-_vwan\n")
+
+	networkCfg.PPPoE[0].Parent = "missing"
+	err = ValidateNetworkConfiguration(&networkCfg, true)
+	require.EqualError(t, err, "pppoe 0 unable to find parent 'missing'")
+
+	addresses, peer := parsePPPoEAddresses("5: dsl: <POINTOPOINT,MULTICAST,NOARP,UP,LOWER_UP> mtu 1492 qdisc fq_codel state UNKNOWN group default qlen 3\n    link/ppp\n    inet 100.64.1.2 peer 100.64.0.1/32 scope global dsl\n       valid_lft forever preferred_lft forever\n    inet6 fe80::1/128 scope link\n       valid_lft forever preferred_lft forever\n")
+	require.Equal(t, []string{"100.64.1.2"}, addresses)
+	require.Equal(t, "100.64.0.1", peer)
+}
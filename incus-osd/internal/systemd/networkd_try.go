@@ -0,0 +1,144 @@
+package systemd
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/nftables"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+var (
+	networkTryMu    sync.Mutex
+	networkTryTimer *time.Timer
+)
+
+// applyTriedNetworkConfiguration applies a tried or reverted network configuration, along with its
+// hardware address filters. Tests override it so the host's network is left alone.
+var applyTriedNetworkConfiguration = func(ctx context.Context, s *state.State, networkCfg *api.SystemNetworkConfig, timeout time.Duration, allowPartialConfig bool, refresh func(context.Context, *state.State) error) error {
+	err := nftables.ApplyHwaddrFilters(ctx, networkCfg)
+	if err != nil {
+		return err
+	}
+
+	return ApplyNetworkConfiguration(ctx, s, networkCfg, timeout, allowPartialConfig, refresh, false)
+}
+
+// TryNetworkConfiguration applies the supplied network configuration, automatically reverting to the
+// current one unless ConfirmNetworkConfiguration is called before the confirmation timeout expires.
+//
+// The previous configuration is recorded in the state, so a system restarted before confirmation
+// will also come back up with it.
+func TryNetworkConfiguration(ctx context.Context, s *state.State, networkCfg *api.SystemNetworkConfig, timeout time.Duration, confirmTimeout time.Duration, refresh func(context.Context, *state.State) error) error {
+	networkTryMu.Lock()
+	defer networkTryMu.Unlock()
+
+	if s.NetworkRollbackConfig != nil {
+		return errors.New("a network configuration is already pending confirmation")
+	}
+
+	if s.System.Network.Config == nil {
+		return errors.New("no existing network configuration to revert to")
+	}
+
+	// Record the configuration to revert to before touching anything.
+	s.NetworkRollbackConfig = s.System.Network.Config
+
+	err := s.Save()
+	if err != nil {
+		s.NetworkRollbackConfig = nil
+
+		return err
+	}
+
+	err = applyTriedNetworkConfiguration(ctx, s, networkCfg, timeout, false, refresh)
+	if err != nil {
+		// Immediately go back to the previous configuration.
+		rollbackErr := rollbackNetworkConfiguration(ctx, s, refresh)
+		if rollbackErr != nil {
+			slog.ErrorContext(ctx, "Failed to revert network configuration", "err", rollbackErr)
+		}
+
+		return err
+	}
+
+	deadline := time.Now().Add(confirmTimeout)
+	s.System.Network.State.ConfirmationDeadline = &deadline
+
+	networkTryTimer = time.AfterFunc(confirmTimeout, func() {
+		networkTryMu.Lock()
+		defer networkTryMu.Unlock()
+
+		// Confirmation may have raced with the timer firing.
+		if s.NetworkRollbackConfig == nil {
+			return
+		}
+
+		ctx := context.Background()
+
+		slog.WarnContext(ctx, "Network configuration wasn't confirmed in time, reverting to the previous configuration")
+
+		err := rollbackNetworkConfiguration(ctx, s, refresh)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to revert network configuration", "err", err)
+		}
+	})
+
+	return nil
+}
+
+// ConfirmNetworkConfiguration confirms a network configuration applied through TryNetworkConfiguration.
+func ConfirmNetworkConfiguration(s *state.State) error {
+	networkTryMu.Lock()
+	defer networkTryMu.Unlock()
+
+	if s.NetworkRollbackConfig == nil {
+		return errors.New("no network configuration is pending confirmation")
+	}
+
+	if networkTryTimer != nil {
+		networkTryTimer.Stop()
+		networkTryTimer = nil
+	}
+
+	s.NetworkRollbackConfig = nil
+	s.System.Network.State.ConfirmationDeadline = nil
+
+	return s.Save()
+}
+
+// RestoreUnconfirmedNetworkConfiguration swaps back the previous network configuration if the system
+// was restarted while a tried configuration was still pending confirmation. It must be called prior
+// to the initial network configuration.
+func RestoreUnconfirmedNetworkConfiguration(ctx context.Context, s *state.State) {
+	if s.NetworkRollbackConfig == nil {
+		return
+	}
+
+	slog.WarnContext(ctx, "Network configuration wasn't confirmed before restart, reverting to the previous configuration")
+
+	s.System.Network.Config = s.NetworkRollbackConfig
+	s.NetworkRollbackConfig = nil
+}
+
+// rollbackNetworkConfiguration re-applies the recorded previous network configuration. The caller must hold networkTryMu.
+func rollbackNetworkConfiguration(ctx context.Context, s *state.State, refresh func(context.Context, *state.State) error) error {
+	previousCfg := s.NetworkRollbackConfig
+
+	networkTryTimer = nil
+
+	// Allow for a partial configuration, getting back to a reachable system matters more than DNS.
+	err := applyTriedNetworkConfiguration(ctx, s, previousCfg, 30*time.Second, true, refresh)
+	if err != nil {
+		return err
+	}
+
+	s.NetworkRollbackConfig = nil
+	s.System.Network.State.ConfirmationDeadline = nil
+
+	return s.Save()
+}
@@ -0,0 +1,121 @@
+package systemd
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+func TestTryNetworkConfiguration(t *testing.T) { //nolint:paralleltest
+	cases := []struct {
+		name            string
+		failApply       bool
+		confirm         bool
+		reboot          bool
+		expectedErr     string
+		expectedConfig  string
+		expectedApplied []string
+	}{
+		{
+			name:            "Confirmed before the deadline",
+			confirm:         true,
+			expectedConfig:  "new",
+			expectedApplied: []string{"new"},
+		},
+		{
+			name:            "Rolled back on timeout",
+			expectedConfig:  "old",
+			expectedApplied: []string{"new", "old"},
+		},
+		{
+			name:            "Rolled back on failure",
+			failApply:       true,
+			expectedErr:     "failed to apply",
+			expectedConfig:  "old",
+			expectedApplied: []string{"new", "old"},
+		},
+		{
+			name:            "Restored after a reboot",
+			reboot:          true,
+			expectedConfig:  "old",
+			expectedApplied: []string{"new"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			applied := []string{}
+
+			applyTriedNetworkConfiguration = func(_ context.Context, s *state.State, networkCfg *api.SystemNetworkConfig, _ time.Duration, _ bool, _ func(context.Context, *state.State) error) error {
+				applied = append(applied, networkCfg.DNS.Hostname)
+
+				if tc.failApply && networkCfg.DNS.Hostname == "new" {
+					return errors.New("failed to apply")
+				}
+
+				s.System.Network.Config = networkCfg
+
+				return nil
+			}
+
+			statePath := filepath.Join(t.TempDir(), "state.txt")
+
+			s, err := state.LoadOrCreate(statePath)
+			require.NoError(t, err)
+
+			s.System.Network.Config = &api.SystemNetworkConfig{DNS: &api.SystemNetworkDNS{Hostname: "old"}}
+
+			// Don't let the deadline expire while simulating a reboot.
+			confirmTimeout := 50 * time.Millisecond
+			if tc.reboot {
+				confirmTimeout = time.Hour
+			}
+
+			err = TryNetworkConfiguration(context.Background(), s, &api.SystemNetworkConfig{DNS: &api.SystemNetworkDNS{Hostname: "new"}}, time.Second, confirmTimeout, nil)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			switch {
+			case tc.confirm:
+				require.NoError(t, ConfirmNetworkConfiguration(s))
+
+				// Nothing gets reverted once the deadline has passed.
+				time.Sleep(2 * confirmTimeout)
+			case tc.reboot:
+				// Come back up from the state saved on disk, then clear the pending confirmation timer.
+				rebooted, err := state.LoadOrCreate(statePath)
+				require.NoError(t, err)
+
+				RestoreUnconfirmedNetworkConfiguration(context.Background(), rebooted)
+				require.NoError(t, ConfirmNetworkConfiguration(s))
+
+				s = rebooted
+			case tc.expectedErr == "":
+				require.Eventually(t, func() bool {
+					networkTryMu.Lock()
+					defer networkTryMu.Unlock()
+
+					return s.NetworkRollbackConfig == nil
+				}, 5*time.Second, 10*time.Millisecond)
+			}
+
+			networkTryMu.Lock()
+			defer networkTryMu.Unlock()
+
+			require.Equal(t, tc.expectedConfig, s.System.Network.Config.DNS.Hostname)
+			require.Equal(t, tc.expectedApplied, applied)
+			require.Nil(t, s.NetworkRollbackConfig)
+			require.Nil(t, s.System.Network.State.ConfirmationDeadline)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"math"
 	"net"
 	"regexp"
 	"slices"
@@ -56,6 +57,23 @@ func validateInterfaces(interfaces []api.SystemNetworkInterface, requireValidMAC
 			if err != nil {
 				return fmt.Errorf("interface %d route %d 'Via' %s", index, routeIndex, err.Error())
 			}
+
+			err = validateRouteOptions(route)
+			if err != nil {
+				return fmt.Errorf("interface %d route %d %s", index, routeIndex, err.Error())
+			}
+		}
+
+		for ruleIndex, rule := range iface.RoutingRules {
+			err := validateRoutingRule(rule)
+			if err != nil {
+				return fmt.Errorf("interface %d routing rule %d %s", index, ruleIndex, err.Error())
+			}
+		}
+
+		err = validateNeighbors(iface.Neighbors, iface.ProxyNDP)
+		if err != nil {
+			return fmt.Errorf("interface %d %s", index, err.Error())
 		}
 
 		err = validateHwaddr(iface.Hwaddr, requireValidMAC)
@@ -63,10 +81,74 @@ func validateInterfaces(interfaces []api.SystemNetworkInterface, requireValidMAC
 			return fmt.Errorf("interface %d %s", index, err.Error())
 		}
 
+		if iface.Path != "" {
+			if strings.ContainsAny(iface.Path, " \t\n") {
+				return fmt.Errorf("interface %d invalid path '%s'", index, iface.Path)
+			}
+
+			if slices.IndexFunc(interfaces, func(i api.SystemNetworkInterface) bool { return i.Path == iface.Path }) != index {
+				return fmt.Errorf("interface %d path '%s' used more than once", index, iface.Path)
+			}
+		}
+
 		err = validateEthernet(iface.Ethernet)
 		if err != nil {
 			return fmt.Errorf("interface %d %s", index, err.Error())
 		}
+
+		err = validateSRIOV(iface.SRIOV)
+		if err != nil {
+			return fmt.Errorf("interface %d %s", index, err.Error())
+		}
+
+		err = validateQoS(iface.QoS)
+		if err != nil {
+			return fmt.Errorf("interface %d %s", index, err.Error())
+		}
+
+		if iface.EAP != nil {
+			err = validateEAP(iface.EAP)
+			if err != nil {
+				return fmt.Errorf("interface %d %s", index, err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateSRIOV(sriov *api.SystemNetworkSRIOV) error {
+	if sriov == nil {
+		return nil
+	}
+
+	if sriov.VFs < 0 {
+		return errors.New("SR-IOV VF count can't be negative")
+	}
+
+	indexes := []int{}
+
+	for _, vf := range sriov.VirtualFunctions {
+		if vf.Index < 0 || vf.Index >= sriov.VFs {
+			return fmt.Errorf("SR-IOV VF index %d out of range", vf.Index)
+		}
+
+		if slices.Contains(indexes, vf.Index) {
+			return fmt.Errorf("SR-IOV VF %d configured more than once", vf.Index)
+		}
+
+		indexes = append(indexes, vf.Index)
+
+		if vf.Hwaddr != "" {
+			err := validateHwaddr(vf.Hwaddr, true)
+			if err != nil {
+				return fmt.Errorf("SR-IOV VF %d %s", vf.Index, err.Error())
+			}
+		}
+
+		if vf.VLAN < 0 || vf.VLAN > 4094 {
+			return fmt.Errorf("SR-IOV VF %d VLAN out of range", vf.Index)
+		}
 	}
 
 	return nil
@@ -84,6 +166,11 @@ func validateBonds(bonds []api.SystemNetworkBond, requireValidMAC bool) error {
 			return fmt.Errorf("bond %d %s", index, err.Error())
 		}
 
+		err = validateBondOptions(bond)
+		if err != nil {
+			return fmt.Errorf("bond %d %s", index, err.Error())
+		}
+
 		err = validateMTU(bond.MTU)
 		if err != nil {
 			return fmt.Errorf("bond %d %s", index, err.Error())
@@ -96,7 +183,7 @@ func validateBonds(bonds []api.SystemNetworkBond, requireValidMAC bool) error {
 
 		err = validateFirewall(bond.FirewallRules)
 		if err != nil {
-			return fmt.Errorf("interface %d %s", index, err.Error())
+			return fmt.Errorf("bond %d %s", index, err.Error())
 		}
 
 		for addressIndex, address := range bond.Addresses {
@@ -121,6 +208,23 @@ func validateBonds(bonds []api.SystemNetworkBond, requireValidMAC bool) error {
 			if err != nil {
 				return fmt.Errorf("bond %d route %d 'Via' %s", index, routeIndex, err.Error())
 			}
+
+			err = validateRouteOptions(route)
+			if err != nil {
+				return fmt.Errorf("bond %d route %d %s", index, routeIndex, err.Error())
+			}
+		}
+
+		for ruleIndex, rule := range bond.RoutingRules {
+			err := validateRoutingRule(rule)
+			if err != nil {
+				return fmt.Errorf("bond %d routing rule %d %s", index, ruleIndex, err.Error())
+			}
+		}
+
+		err = validateNeighbors(bond.Neighbors, bond.ProxyNDP)
+		if err != nil {
+			return fmt.Errorf("bond %d %s", index, err.Error())
 		}
 
 		if bond.Hwaddr != "" {
@@ -130,78 +234,760 @@ func validateBonds(bonds []api.SystemNetworkBond, requireValidMAC bool) error {
 			}
 		}
 
-		if len(bond.Members) == 0 {
-			return fmt.Errorf("bond %d has no members", index)
-		}
+		if len(bond.Members) == 0 {
+			return fmt.Errorf("bond %d has no members", index)
+		}
+
+		for memberIndex, member := range bond.Members {
+			err := validateHwaddr(member, requireValidMAC)
+			if err != nil {
+				return fmt.Errorf("bond %d member %d %s", index, memberIndex, err.Error())
+			}
+		}
+
+		err = validateEthernet(bond.Ethernet)
+		if err != nil {
+			return fmt.Errorf("bond %d %s", index, err.Error())
+		}
+
+		err = validateQoS(bond.QoS)
+		if err != nil {
+			return fmt.Errorf("bond %d %s", index, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func validateBridges(cfg *api.SystemNetworkConfig, requireValidMAC bool) error {
+	for index, bridge := range cfg.Bridges {
+		err := validateName(bridge.Name)
+		if err != nil {
+			return fmt.Errorf("bridge %d %s", index, err.Error())
+		}
+
+		err = validateMTU(bridge.MTU)
+		if err != nil {
+			return fmt.Errorf("bridge %d %s", index, err.Error())
+		}
+
+		err = validateRoles(bridge.Roles)
+		if err != nil {
+			return fmt.Errorf("bridge %d %s", index, err.Error())
+		}
+
+		err = validateFirewall(bridge.FirewallRules)
+		if err != nil {
+			return fmt.Errorf("bridge %d %s", index, err.Error())
+		}
+
+		for addressIndex, address := range bridge.Addresses {
+			err := validateAddressWithCIDR(address)
+			if err != nil {
+				return fmt.Errorf("bridge %d address %d %s", index, addressIndex, err.Error())
+			}
+		}
+
+		err = validateRequiredForOnline(bridge.RequiredForOnline)
+		if err != nil {
+			return fmt.Errorf("bridge %d %s", index, err.Error())
+		}
+
+		for routeIndex, route := range bridge.Routes {
+			err := validateAddressWithCIDR(route.To)
+			if err != nil {
+				return fmt.Errorf("bridge %d route %d 'To' %s", index, routeIndex, err.Error())
+			}
+
+			err = validateAddress(route.Via)
+			if err != nil {
+				return fmt.Errorf("bridge %d route %d 'Via' %s", index, routeIndex, err.Error())
+			}
+
+			err = validateRouteOptions(route)
+			if err != nil {
+				return fmt.Errorf("bridge %d route %d %s", index, routeIndex, err.Error())
+			}
+		}
+
+		for ruleIndex, rule := range bridge.RoutingRules {
+			err := validateRoutingRule(rule)
+			if err != nil {
+				return fmt.Errorf("bridge %d routing rule %d %s", index, ruleIndex, err.Error())
+			}
+		}
+
+		err = validateNeighbors(bridge.Neighbors, bridge.ProxyNDP)
+		if err != nil {
+			return fmt.Errorf("bridge %d %s", index, err.Error())
+		}
+
+		err = validateEthernet(bridge.Ethernet)
+		if err != nil {
+			return fmt.Errorf("bridge %d %s", index, err.Error())
+		}
+
+		// The host side of the bridge needs a MAC address, taken from the first member if not set.
+		if bridge.Hwaddr == "" && len(bridge.Members) == 0 {
+			return fmt.Errorf("bridge %d has no members or hwaddr", index)
+		}
+
+		if bridge.Hwaddr != "" {
+			err = validateHwaddr(bridge.Hwaddr, requireValidMAC)
+			if err != nil {
+				return fmt.Errorf("bridge %d %s", index, err.Error())
+			}
+		}
+
+		for memberIndex, member := range bridge.Members {
+			err := validateHwaddr(member, requireValidMAC)
+			if err != nil {
+				return fmt.Errorf("bridge %d member %d %s", index, memberIndex, err.Error())
+			}
+
+			if isMemberInUse(cfg, index, member) {
+				return fmt.Errorf("bridge %d member %d '%s' is already in use", index, memberIndex, member)
+			}
+		}
+
+		if len(bridge.VLANTags) > 0 && !bridge.VLANFiltering {
+			return fmt.Errorf("bridge %d VLAN tags require VLAN filtering", index)
+		}
+
+		for _, tag := range bridge.VLANTags {
+			if tag < 1 || tag > 4094 {
+				return fmt.Errorf("bridge %d VLAN tag %d out of range", index, tag)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateVLANs(cfg *api.SystemNetworkConfig) error {
+	for index, vlan := range cfg.VLANs {
+		err := validateName(vlan.Name)
+		if err != nil {
+			return fmt.Errorf("vlan %d %s", index, err.Error())
+		}
+
+		err = validateParent(vlan.Parent, cfg.Interfaces, cfg.Bonds, cfg.Bridges)
+		if err != nil {
+			return fmt.Errorf("vlan %d %s", index, err.Error())
+		}
+
+		if vlan.ID < 1 || vlan.ID > 4094 {
+			return fmt.Errorf("vlan %d ID %d out of range", index, vlan.ID)
+		}
+
+		// Each VLAN ID can only be defined once per parent.
+		for otherIndex, other := range cfg.VLANs[:index] {
+			if other.Parent == vlan.Parent && other.ID == vlan.ID {
+				return fmt.Errorf("vlan %d ID %d already defined on parent '%s' by vlan %d", index, vlan.ID, vlan.Parent, otherIndex)
+			}
+		}
+
+		err = validateMTU(vlan.MTU)
+		if err != nil {
+			return fmt.Errorf("vlan %d %s", index, err.Error())
+		}
+
+		// Jumbo frames on a VLAN must also be enabled on its parent.
+		parentMTU := getDeviceMTU(cfg, vlan.Parent)
+		if vlan.MTU > parentMTU {
+			return fmt.Errorf("vlan %d MTU %d is larger than parent '%s' MTU %d", index, vlan.MTU, vlan.Parent, parentMTU)
+		}
+
+		err = validateRoles(vlan.Roles)
+		if err != nil {
+			return fmt.Errorf("vlan %d %s", index, err.Error())
+		}
+
+		err = validateFirewall(vlan.FirewallRules)
+		if err != nil {
+			return fmt.Errorf("vlan %d %s", index, err.Error())
+		}
+
+		for addressIndex, address := range vlan.Addresses {
+			err := validateAddressWithCIDR(address)
+			if err != nil {
+				return fmt.Errorf("vlan %d address %d %s", index, addressIndex, err.Error())
+			}
+		}
+
+		err = validateRequiredForOnline(vlan.RequiredForOnline)
+		if err != nil {
+			return fmt.Errorf("vlan %d %s", index, err.Error())
+		}
+
+		for routeIndex, route := range vlan.Routes {
+			err := validateAddressWithCIDR(route.To)
+			if err != nil {
+				return fmt.Errorf("vlan %d route %d 'To' %s", index, routeIndex, err.Error())
+			}
+
+			err = validateAddress(route.Via)
+			if err != nil {
+				return fmt.Errorf("vlan %d route %d 'Via' %s", index, routeIndex, err.Error())
+			}
+
+			err = validateRouteOptions(route)
+			if err != nil {
+				return fmt.Errorf("vlan %d route %d %s", index, routeIndex, err.Error())
+			}
+		}
+
+		for ruleIndex, rule := range vlan.RoutingRules {
+			err := validateRoutingRule(rule)
+			if err != nil {
+				return fmt.Errorf("vlan %d routing rule %d %s", index, ruleIndex, err.Error())
+			}
+		}
+
+		err = validateNeighbors(vlan.Neighbors, vlan.ProxyNDP)
+		if err != nil {
+			return fmt.Errorf("vlan %d %s", index, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func validateWifi(cfg *api.SystemNetworkConfig, requireValidMAC bool) error {
+	for index, wifi := range cfg.Wifi {
+		err := validateName(wifi.Name)
+		if err != nil {
+			return fmt.Errorf("wifi %d %s", index, err.Error())
+		}
+
+		err = validateMTU(wifi.MTU)
+		if err != nil {
+			return fmt.Errorf("wifi %d %s", index, err.Error())
+		}
+
+		err = validateRoles(wifi.Roles)
+		if err != nil {
+			return fmt.Errorf("wifi %d %s", index, err.Error())
+		}
+
+		err = validateFirewall(wifi.FirewallRules)
+		if err != nil {
+			return fmt.Errorf("wifi %d %s", index, err.Error())
+		}
+
+		for addressIndex, address := range wifi.Addresses {
+			err := validateAddressWithCIDR(address)
+			if err != nil {
+				return fmt.Errorf("wifi %d address %d %s", index, addressIndex, err.Error())
+			}
+		}
+
+		err = validateRequiredForOnline(wifi.RequiredForOnline)
+		if err != nil {
+			return fmt.Errorf("wifi %d %s", index, err.Error())
+		}
+
+		for routeIndex, route := range wifi.Routes {
+			err := validateAddressWithCIDR(route.To)
+			if err != nil {
+				return fmt.Errorf("wifi %d route %d 'To' %s", index, routeIndex, err.Error())
+			}
+
+			err = validateAddress(route.Via)
+			if err != nil {
+				return fmt.Errorf("wifi %d route %d 'Via' %s", index, routeIndex, err.Error())
+			}
+
+			err = validateRouteOptions(route)
+			if err != nil {
+				return fmt.Errorf("wifi %d route %d %s", index, routeIndex, err.Error())
+			}
+		}
+
+		for ruleIndex, rule := range wifi.RoutingRules {
+			err := validateRoutingRule(rule)
+			if err != nil {
+				return fmt.Errorf("wifi %d routing rule %d %s", index, ruleIndex, err.Error())
+			}
+		}
+
+		err = validateHwaddr(wifi.Hwaddr, requireValidMAC)
+		if err != nil {
+			return fmt.Errorf("wifi %d %s", index, err.Error())
+		}
+
+		if isMemberInUse(cfg, -1, wifi.Hwaddr) {
+			return fmt.Errorf("wifi %d hwaddr '%s' is already in use", index, wifi.Hwaddr)
+		}
+
+		if wifi.SSID == "" || len(wifi.SSID) > 32 {
+			return fmt.Errorf("wifi %d SSID must be between 1 and 32 bytes", index)
+		}
+
+		if wifi.Country != "" && !regexp.MustCompile(`^[A-Z]{2}$`).MatchString(wifi.Country) {
+			return fmt.Errorf("wifi %d invalid country code '%s'", index, wifi.Country)
+		}
+
+		err = validateWifiSecurity(wifi)
+		if err != nil {
+			return fmt.Errorf("wifi %d %s", index, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func validateWifiSecurity(wifi api.SystemNetworkWifi) error {
+	if !slices.Contains([]string{"", "wpa2", "wpa3", "wpa2-wpa3"}, wifi.Security) {
+		return fmt.Errorf("invalid security '%s'", wifi.Security)
+	}
+
+	// Values end up quoted in the wpa_supplicant configuration.
+	isQuotable := func(value string) bool {
+		return !strings.ContainsAny(value, "\"\n")
+	}
+
+	if wifi.EAP == nil {
+		if len(wifi.PSK) < 8 || len(wifi.PSK) > 63 {
+			return errors.New("PSK must be between 8 and 63 characters")
+		}
+
+		if !isQuotable(wifi.PSK) {
+			return errors.New("PSK cannot contain quotes or newlines")
+		}
+
+		return nil
+	}
+
+	if wifi.PSK != "" {
+		return errors.New("can't set both a PSK and EAP credentials")
+	}
+
+	if wifi.Security == "wpa2-wpa3" {
+		return errors.New("security 'wpa2-wpa3' can't be used with EAP")
+	}
+
+	return validateEAP(wifi.EAP)
+}
+
+func validateEAP(eap *api.SystemNetworkEAP) error {
+	switch eap.Method {
+	case "peap", "ttls":
+		if eap.Identity == "" || eap.Password == "" {
+			return fmt.Errorf("EAP method '%s' requires an identity and password", eap.Method)
+		}
+
+		if !slices.Contains([]string{"", "mschapv2", "pap", "chap", "gtc"}, eap.Phase2) {
+			return fmt.Errorf("invalid EAP phase2 '%s'", eap.Phase2)
+		}
+	case "tls":
+		if eap.ClientCertificate == "" || eap.ClientKey == "" {
+			return errors.New("EAP method 'tls' requires a client certificate and key")
+		}
+	default:
+		return fmt.Errorf("invalid EAP method '%s'", eap.Method)
+	}
+
+	// Values end up quoted in the wpa_supplicant configuration.
+	for _, value := range []string{eap.Identity, eap.AnonymousIdentity, eap.Password} {
+		if strings.ContainsAny(value, "\"\n") {
+			return errors.New("EAP credentials cannot contain quotes or newlines")
+		}
+	}
+
+	return nil
+}
+
+func validatePPPoE(cfg *api.SystemNetworkConfig) error {
+	for index, pppoe := range cfg.PPPoE {
+		err := validateName(pppoe.Name)
+		if err != nil {
+			return fmt.Errorf("pppoe %d %s", index, err.Error())
+		}
+
+		_, found := getDeviceAddresses(cfg, pppoe.Parent)
+		if !found {
+			return fmt.Errorf("pppoe %d unable to find parent '%s'", index, pppoe.Parent)
+		}
+
+		// PPPoE adds an 8 byte header to each frame.
+		if pppoe.MTU != 0 && (pppoe.MTU < 576 || pppoe.MTU > 1492) {
+			return fmt.Errorf("pppoe %d MTU '%d' must be between 576 and 1492", index, pppoe.MTU)
+		}
+
+		err = validateRoles(pppoe.Roles)
+		if err != nil {
+			return fmt.Errorf("pppoe %d %s", index, err.Error())
+		}
+
+		err = validateFirewall(pppoe.FirewallRules)
+		if err != nil {
+			return fmt.Errorf("pppoe %d %s", index, err.Error())
+		}
+
+		if pppoe.Username == "" || pppoe.Password == "" {
+			return fmt.Errorf("pppoe %d requires a username and password", index)
+		}
+
+		// Values end up quoted in the pppd peer file.
+		for _, value := range []string{pppoe.Username, pppoe.Password, pppoe.ServiceName} {
+			if strings.ContainsAny(value, "\"\n") {
+				return fmt.Errorf("pppoe %d credentials and service name cannot contain quotes or newlines", index)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateWireguard(cfg *api.SystemNetworkConfig) error {
+	for index, wg := range cfg.Wireguard {
+		err := validateName(wg.Name)
+		if err != nil {
+			return fmt.Errorf("wireguard %d %s", index, err.Error())
+		}
+
+		err = validateMTU(wg.MTU)
+		if err != nil {
+			return fmt.Errorf("wireguard %d %s", index, err.Error())
+		}
+
+		err = validateRoles(wg.Roles)
+		if err != nil {
+			return fmt.Errorf("wireguard %d %s", index, err.Error())
+		}
+
+		err = validateFirewall(wg.FirewallRules)
+		if err != nil {
+			return fmt.Errorf("wireguard %d %s", index, err.Error())
+		}
+
+		for addressIndex, address := range wg.Addresses {
+			err := validateAddressWithCIDR(address)
+			if err != nil {
+				return fmt.Errorf("wireguard %d address %d %s", index, addressIndex, err.Error())
+			}
+		}
+
+		err = validateRequiredForOnline(wg.RequiredForOnline)
+		if err != nil {
+			return fmt.Errorf("wireguard %d %s", index, err.Error())
+		}
+
+		if !isValidBase64(wg.PrivateKey) {
+			return fmt.Errorf("wireguard %d private key '%s' invalid", index, wg.PrivateKey)
+		}
+
+		if wg.Port < 0 || wg.Port > 65535 {
+			return fmt.Errorf("wireguard %d port '%d' out of range", index, wg.Port)
+		}
+
+		for routeIndex, route := range wg.Routes {
+			err := validateAddressWithCIDR(route.To)
+			if err != nil {
+				return fmt.Errorf("wireguard %d route %d 'To' %s", index, routeIndex, err.Error())
+			}
+
+			err = validateAddress(route.Via)
+			if err != nil {
+				return fmt.Errorf("wireguard %d route %d 'Via' %s", index, routeIndex, err.Error())
+			}
+
+			err = validateRouteOptions(route)
+			if err != nil {
+				return fmt.Errorf("wireguard %d route %d %s", index, routeIndex, err.Error())
+			}
+		}
+
+		for ruleIndex, rule := range wg.RoutingRules {
+			err := validateRoutingRule(rule)
+			if err != nil {
+				return fmt.Errorf("wireguard %d routing rule %d %s", index, ruleIndex, err.Error())
+			}
+		}
+
+		for peerIndex, peer := range wg.Peers {
+			if !isValidBase64(peer.PublicKey) {
+				return fmt.Errorf("wireguard %d peer %d public key invalid", index, peerIndex)
+			}
+
+			if peer.PresharedKey != "" && !isValidBase64(peer.PresharedKey) {
+				return fmt.Errorf("wireguard %d peer %d preshared key invalid", index, peerIndex)
+			}
+
+			for addressIndex, address := range peer.AllowedIPs {
+				err := validateAddressWithCIDR(address)
+				if err != nil {
+					return fmt.Errorf("wireguard %d peer %d allowed IP %d %s", index, peerIndex, addressIndex, err.Error())
+				}
+			}
+
+			if peer.PersistentKeepalive < 0 || peer.PersistentKeepalive > 65535 {
+				return fmt.Errorf("wireguard %d peer %d persitent keepalive %d out of range", index, peerIndex, peer.PersistentKeepalive)
+			}
+
+			if peer.Endpoint != "" {
+				endpointishRegex := regexp.MustCompile(`^[.:[:xdigit:]]+:\d+$`)
+				if !endpointishRegex.MatchString(peer.Endpoint) {
+					return fmt.Errorf("wireguard %d peer %d invalid endpoint '%s'", index, peerIndex, peer.Endpoint)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateTunnels(cfg *api.SystemNetworkConfig) error {
+	for index, tunnel := range cfg.Tunnels {
+		err := validateName(tunnel.Name)
+		if err != nil {
+			return fmt.Errorf("tunnel %d %s", index, err.Error())
+		}
+
+		if !slices.Contains([]string{"gre", "gretap", "vxlan"}, tunnel.Kind) {
+			return fmt.Errorf("tunnel %d invalid kind '%s'", index, tunnel.Kind)
+		}
+
+		remote := net.ParseIP(tunnel.Remote)
+		if remote == nil {
+			return fmt.Errorf("tunnel %d invalid remote address '%s'", index, tunnel.Remote)
+		}
+
+		if tunnel.Local != "" {
+			local := net.ParseIP(tunnel.Local)
+			if local == nil || (local.To4() == nil) != (remote.To4() == nil) {
+				return fmt.Errorf("tunnel %d invalid local address '%s'", index, tunnel.Local)
+			}
+		}
+
+		if tunnel.Kind == "vxlan" {
+			if tunnel.VNI < 1 || tunnel.VNI > 16777215 {
+				return fmt.Errorf("tunnel %d VNI '%d' out of range", index, tunnel.VNI)
+			}
+
+			if tunnel.Port < 0 || tunnel.Port > 65535 {
+				return fmt.Errorf("tunnel %d port '%d' out of range", index, tunnel.Port)
+			}
+
+			if tunnel.Key != 0 {
+				return fmt.Errorf("tunnel %d key can only be set on GRE tunnels", index)
+			}
+		} else {
+			if tunnel.Key < 0 || int64(tunnel.Key) > math.MaxUint32 {
+				return fmt.Errorf("tunnel %d key '%d' out of range", index, tunnel.Key)
+			}
+
+			if tunnel.VNI != 0 || tunnel.Port != 0 {
+				return fmt.Errorf("tunnel %d VNI and port can only be set on VXLAN tunnels", index)
+			}
+		}
+
+		err = validateMTU(tunnel.MTU)
+		if err != nil {
+			return fmt.Errorf("tunnel %d %s", index, err.Error())
+		}
+
+		err = validateRoles(tunnel.Roles)
+		if err != nil {
+			return fmt.Errorf("tunnel %d %s", index, err.Error())
+		}
+
+		err = validateFirewall(tunnel.FirewallRules)
+		if err != nil {
+			return fmt.Errorf("tunnel %d %s", index, err.Error())
+		}
+
+		for addressIndex, address := range tunnel.Addresses {
+			err := validateAddressWithCIDR(address)
+			if err != nil {
+				return fmt.Errorf("tunnel %d address %d %s", index, addressIndex, err.Error())
+			}
+		}
+
+		err = validateRequiredForOnline(tunnel.RequiredForOnline)
+		if err != nil {
+			return fmt.Errorf("tunnel %d %s", index, err.Error())
+		}
+
+		for routeIndex, route := range tunnel.Routes {
+			err := validateAddressWithCIDR(route.To)
+			if err != nil {
+				return fmt.Errorf("tunnel %d route %d 'To' %s", index, routeIndex, err.Error())
+			}
+
+			err = validateAddress(route.Via)
+			if err != nil {
+				return fmt.Errorf("tunnel %d route %d 'Via' %s", index, routeIndex, err.Error())
+			}
+
+			err = validateRouteOptions(route)
+			if err != nil {
+				return fmt.Errorf("tunnel %d route %d %s", index, routeIndex, err.Error())
+			}
+		}
+
+		for ruleIndex, rule := range tunnel.RoutingRules {
+			err := validateRoutingRule(rule)
+			if err != nil {
+				return fmt.Errorf("tunnel %d routing rule %d %s", index, ruleIndex, err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateName(name string) error {
+	if name == "" {
+		return errors.New("has no name")
+	}
+
+	if strings.HasPrefix(name, "_") {
+		return errors.New("name cannot begin with an underscore")
+	}
+
+	if len(name) > 13 {
+		return errors.New("name '" + name + "' cannot be longer than 13 characters")
+	}
+
+	return nil
+}
+
+func validateMode(mode string) error {
+	if mode != "balance-rr" && mode != "active-backup" && mode != "balance-xor" && mode != "broadcast" && mode != "802.3ad" && mode != "balance-tlb" && mode != "balance-alb" {
+		return fmt.Errorf("invalid Mode value '%s'", mode)
+	}
+
+	return nil
+}
+
+func validateBondOptions(bond api.SystemNetworkBond) error {
+	if bond.LACPRate != "" {
+		if bond.Mode != "802.3ad" {
+			return errors.New("LACP rate requires mode '802.3ad'")
+		}
+
+		if bond.LACPRate != "slow" && bond.LACPRate != "fast" {
+			return fmt.Errorf("invalid LACP rate value '%s'", bond.LACPRate)
+		}
+	}
+
+	if bond.TransmitHashPolicy != "" {
+		if !slices.Contains([]string{"balance-xor", "802.3ad", "balance-tlb"}, bond.Mode) {
+			return errors.New("transmit hash policy requires mode 'balance-xor', '802.3ad' or 'balance-tlb'")
+		}
+
+		if !slices.Contains([]string{"layer2", "layer3+4", "layer2+3", "encap2+3", "encap3+4", "vlan+srcmac"}, bond.TransmitHashPolicy) {
+			return fmt.Errorf("invalid transmit hash policy value '%s'", bond.TransmitHashPolicy)
+		}
+	}
+
+	if bond.MIIMonitorInterval < 0 {
+		return fmt.Errorf("invalid MII monitor interval '%d'", bond.MIIMonitorInterval)
+	}
+
+	return nil
+}
+
+func validateDHCPServer(cfg *api.SystemNetworkConfig) error {
+	server := cfg.DHCPServer
+	if server == nil {
+		return nil
+	}
+
+	if server.Interface == "" {
+		return errors.New("dhcp server has no interface")
+	}
+
+	addresses, found := getDeviceAddresses(cfg, server.Interface)
+	if !found {
+		return fmt.Errorf("dhcp server unable to find interface '%s'", server.Interface)
+	}
+
+	// The server hands out addresses from the subnet of the interface's static IPv4 address.
+	hasStaticIPv4 := false
+
+	for _, address := range addresses {
+		ip, _, err := net.ParseCIDR(address)
+		if err == nil && ip.To4() != nil {
+			hasStaticIPv4 = true
+
+			break
+		}
+	}
+
+	if !hasStaticIPv4 {
+		return fmt.Errorf("dhcp server interface '%s' has no static IPv4 address", server.Interface)
+	}
+
+	if server.PoolOffset < 0 || server.PoolSize < 0 || server.LeaseTime < 0 {
+		return errors.New("dhcp server pool offset, pool size and lease time can't be negative")
+	}
 
-		for memberIndex, member := range bond.Members {
-			err := validateHwaddr(member, requireValidMAC)
-			if err != nil {
-				return fmt.Errorf("bond %d member %d %s", index, memberIndex, err.Error())
-			}
+	for _, address := range append([]string{server.Router, server.BootServer}, server.DNS...) {
+		if address != "" && net.ParseIP(address) == nil {
+			return fmt.Errorf("dhcp server invalid IP address '%s'", address)
 		}
+	}
 
-		err = validateEthernet(bond.Ethernet)
+	for index, lease := range server.StaticLeases {
+		err := validateHwaddr(lease.Hwaddr, true)
 		if err != nil {
-			return fmt.Errorf("bond %d %s", index, err.Error())
+			return fmt.Errorf("dhcp server static lease %d %s", index, err.Error())
+		}
+
+		ip := net.ParseIP(lease.Address)
+		if ip == nil || ip.To4() == nil {
+			return fmt.Errorf("dhcp server static lease %d invalid IPv4 address '%s'", index, lease.Address)
 		}
 	}
 
 	return nil
 }
 
-func validateVLANs(cfg *api.SystemNetworkConfig) error {
-	for index, vlan := range cfg.VLANs {
-		err := validateName(vlan.Name)
-		if err != nil {
-			return fmt.Errorf("vlan %d %s", index, err.Error())
-		}
+func validateDNS(cfg *api.SystemNetworkConfig) error {
+	if cfg.DNS == nil {
+		return nil
+	}
+
+	if !slices.Contains([]string{"", "no", "opportunistic", "yes"}, cfg.DNS.DNSOverTLS) {
+		return fmt.Errorf("unsupported DNS over TLS mode '%s'", cfg.DNS.DNSOverTLS)
+	}
 
-		err = validateParent(vlan.Parent, cfg.Interfaces, cfg.Bonds)
+	for _, nameserver := range cfg.DNS.Nameservers {
+		err := validateNameserver(nameserver)
 		if err != nil {
-			return fmt.Errorf("vlan %d %s", index, err.Error())
+			return err
 		}
+	}
 
-		if vlan.ID < 0 || vlan.ID > 4094 {
-			return fmt.Errorf("vlan %d ID %d out of range", index, vlan.ID)
-		}
+	interfaces := []string{}
 
-		err = validateMTU(vlan.MTU)
-		if err != nil {
-			return fmt.Errorf("vlan %d %s", index, err.Error())
+	for index, route := range cfg.DNS.Routes {
+		_, found := getDeviceAddresses(cfg, route.Interface)
+		if !found {
+			return fmt.Errorf("dns route %d unable to find interface '%s'", index, route.Interface)
 		}
 
-		err = validateRoles(vlan.Roles)
-		if err != nil {
-			return fmt.Errorf("vlan %d %s", index, err.Error())
+		if slices.Contains(interfaces, route.Interface) {
+			return fmt.Errorf("dns route %d interface '%s' already used by another route", index, route.Interface)
 		}
 
-		err = validateFirewall(vlan.FirewallRules)
-		if err != nil {
-			return fmt.Errorf("interface %d %s", index, err.Error())
-		}
+		interfaces = append(interfaces, route.Interface)
 
-		for addressIndex, address := range vlan.Addresses {
-			err := validateAddressWithCIDR(address)
-			if err != nil {
-				return fmt.Errorf("vlan %d address %d %s", index, addressIndex, err.Error())
-			}
+		if len(route.Domains) == 0 {
+			return fmt.Errorf("dns route %d has no domains", index)
 		}
 
-		err = validateRequiredForOnline(vlan.RequiredForOnline)
-		if err != nil {
-			return fmt.Errorf("vlan %d %s", index, err.Error())
+		if len(route.Nameservers) == 0 {
+			return fmt.Errorf("dns route %d has no name servers", index)
 		}
 
-		for routeIndex, route := range vlan.Routes {
-			err := validateAddressWithCIDR(route.To)
-			if err != nil {
-				return fmt.Errorf("vlan %d route %d 'To' %s", index, routeIndex, err.Error())
-			}
-
-			err = validateAddress(route.Via)
+		for _, nameserver := range route.Nameservers {
+			err := validateNameserver(nameserver)
 			if err != nil {
-				return fmt.Errorf("vlan %d route %d 'Via' %s", index, routeIndex, err.Error())
+				return fmt.Errorf("dns route %d: %w", index, err)
 			}
 		}
 	}
@@ -209,117 +995,147 @@ func validateVLANs(cfg *api.SystemNetworkConfig) error {
 	return nil
 }
 
-func validateWireguard(cfg *api.SystemNetworkConfig) error {
-	for index, wg := range cfg.Wireguard {
-		err := validateName(wg.Name)
-		if err != nil {
-			return fmt.Errorf("wireguard %d %s", index, err.Error())
-		}
+// validateNameserver checks a name server can be handled by systemd-resolved.
+func validateNameserver(nameserver string) error {
+	// systemd-resolved has no support for DNS over HTTPS.
+	if strings.HasPrefix(nameserver, "https://") {
+		return fmt.Errorf("DNS over HTTPS name server '%s' isn't supported", nameserver)
+	}
 
-		err = validateMTU(wg.MTU)
-		if err != nil {
-			return fmt.Errorf("wireguard %d %s", index, err.Error())
+	return nil
+}
+
+func validateIPv6(cfg *api.SystemNetworkConfig) error {
+	if cfg.IPv6 == nil {
+		return nil
+	}
+
+	pd := cfg.IPv6.PrefixDelegation
+	if pd != nil {
+		addresses, found := getDeviceAddresses(cfg, pd.Uplink)
+		if !found {
+			return fmt.Errorf("prefix delegation unable to find uplink '%s'", pd.Uplink)
 		}
 
-		err = validateRoles(wg.Roles)
-		if err != nil {
-			return fmt.Errorf("wireguard %d %s", index, err.Error())
+		if !slices.Contains(addresses, "dhcp6") {
+			return fmt.Errorf("prefix delegation uplink '%s' must use dhcp6", pd.Uplink)
 		}
 
-		err = validateFirewall(wg.FirewallRules)
-		if err != nil {
-			return fmt.Errorf("wireguard %d %s", index, err.Error())
+		if pd.PrefixLength < 0 || pd.PrefixLength > 64 {
+			return fmt.Errorf("prefix delegation prefix length '%d' out of range", pd.PrefixLength)
 		}
 
-		for addressIndex, address := range wg.Addresses {
-			err := validateAddressWithCIDR(address)
-			if err != nil {
-				return fmt.Errorf("wireguard %d address %d %s", index, addressIndex, err.Error())
+		subnetIDs := []int{}
+
+		for index, downstream := range pd.Downstreams {
+			_, found := getDeviceAddresses(cfg, downstream.Interface)
+			if !found || downstream.Interface == pd.Uplink {
+				return fmt.Errorf("prefix delegation downstream %d invalid interface '%s'", index, downstream.Interface)
+			}
+
+			if downstream.SubnetID < 0 || slices.Contains(subnetIDs, downstream.SubnetID) {
+				return fmt.Errorf("prefix delegation downstream %d invalid or duplicate subnet ID '%d'", index, downstream.SubnetID)
 			}
+
+			subnetIDs = append(subnetIDs, downstream.SubnetID)
 		}
+	}
 
-		err = validateRequiredForOnline(wg.RequiredForOnline)
-		if err != nil {
-			return fmt.Errorf("wireguard %d %s", index, err.Error())
+	interfaces := []string{}
+
+	for index, ra := range cfg.IPv6.RouterAdvertisements {
+		_, found := getDeviceAddresses(cfg, ra.Interface)
+		if !found {
+			return fmt.Errorf("router advertisement %d unable to find interface '%s'", index, ra.Interface)
 		}
 
-		if !isValidBase64(wg.PrivateKey) {
-			return fmt.Errorf("wireguard %d private key '%s' invalid", index, wg.PrivateKey)
+		if slices.Contains(interfaces, ra.Interface) {
+			return fmt.Errorf("router advertisement %d interface '%s' already configured", index, ra.Interface)
 		}
 
-		if wg.Port < 0 || wg.Port > 65535 {
-			return fmt.Errorf("wireguard %d port '%d' out of range", index, wg.Port)
+		interfaces = append(interfaces, ra.Interface)
+
+		if ra.RouterLifetime < 0 {
+			return fmt.Errorf("router advertisement %d router lifetime can't be negative", index)
 		}
 
-		for routeIndex, route := range wg.Routes {
-			err := validateAddressWithCIDR(route.To)
-			if err != nil {
-				return fmt.Errorf("wireguard %d route %d 'To' %s", index, routeIndex, err.Error())
+		for _, prefix := range ra.Prefixes {
+			ip, _, err := net.ParseCIDR(prefix)
+			if err != nil || ip.To4() != nil {
+				return fmt.Errorf("router advertisement %d invalid IPv6 prefix '%s'", index, prefix)
 			}
+		}
 
-			err = validateAddress(route.Via)
-			if err != nil {
-				return fmt.Errorf("wireguard %d route %d 'Via' %s", index, routeIndex, err.Error())
+		for _, dns := range ra.DNS {
+			ip := net.ParseIP(dns)
+			if ip == nil || ip.To4() != nil {
+				return fmt.Errorf("router advertisement %d invalid IPv6 DNS server '%s'", index, dns)
 			}
 		}
+	}
 
-		for peerIndex, peer := range wg.Peers {
-			if !isValidBase64(peer.PublicKey) {
-				return fmt.Errorf("wireguard %d peer %d public key invalid", index, peerIndex)
-			}
+	return nil
+}
 
-			if peer.PresharedKey != "" && !isValidBase64(peer.PresharedKey) {
-				return fmt.Errorf("wireguard %d peer %d preshared key invalid", index, peerIndex)
-			}
+// getDeviceAddresses returns the configured addresses of the named interface, bond, bridge or vlan, if found.
+func getDeviceAddresses(cfg *api.SystemNetworkConfig, name string) ([]string, bool) {
+	for _, i := range cfg.Interfaces {
+		if i.Name == name {
+			return i.Addresses, true
+		}
+	}
 
-			for addressIndex, address := range peer.AllowedIPs {
-				err := validateAddressWithCIDR(address)
-				if err != nil {
-					return fmt.Errorf("wireguard %d peer %d allowed IP %d %s", index, peerIndex, addressIndex, err.Error())
-				}
-			}
+	for _, b := range cfg.Bonds {
+		if b.Name == name {
+			return b.Addresses, true
+		}
+	}
 
-			if peer.PersistentKeepalive < 0 || peer.PersistentKeepalive > 65535 {
-				return fmt.Errorf("wireguard %d peer %d persitent keepalive %d out of range", index, peerIndex, peer.PersistentKeepalive)
-			}
+	for _, b := range cfg.Bridges {
+		if b.Name == name {
+			return b.Addresses, true
+		}
+	}
 
-			if peer.Endpoint != "" {
-				endpointishRegex := regexp.MustCompile(`^[.:[:xdigit:]]+:\d+$`)
-				if !endpointishRegex.MatchString(peer.Endpoint) {
-					return fmt.Errorf("wireguard %d peer %d invalid endpoint '%s'", index, peerIndex, peer.Endpoint)
-				}
-			}
+	for _, v := range cfg.VLANs {
+		if v.Name == name {
+			return v.Addresses, true
 		}
 	}
 
-	return nil
+	return nil, false
 }
 
-func validateName(name string) error {
-	if name == "" {
-		return errors.New("has no name")
-	}
+// getDeviceMTU returns the MTU of the named interface, bond or bridge, defaulting to 1500 if not set.
+func getDeviceMTU(cfg *api.SystemNetworkConfig, name string) int {
+	mtu := 0
 
-	if strings.HasPrefix(name, "_") {
-		return errors.New("name cannot begin with an underscore")
+	for _, i := range cfg.Interfaces {
+		if i.Name == name {
+			mtu = i.MTU
+		}
 	}
 
-	if len(name) > 13 {
-		return errors.New("name '" + name + "' cannot be longer than 13 characters")
+	for _, b := range cfg.Bonds {
+		if b.Name == name {
+			mtu = b.MTU
+		}
 	}
 
-	return nil
-}
+	for _, b := range cfg.Bridges {
+		if b.Name == name {
+			mtu = b.MTU
+		}
+	}
 
-func validateMode(mode string) error {
-	if mode != "balance-rr" && mode != "active-backup" && mode != "balance-xor" && mode != "broadcast" && mode != "802.3ad" && mode != "balance-tlb" && mode != "balance-alb" {
-		return fmt.Errorf("invalid Mode value '%s'", mode)
+	if mtu == 0 {
+		return 1500
 	}
 
-	return nil
+	return mtu
 }
 
-func validateParent(parent string, interfaces []api.SystemNetworkInterface, bonds []api.SystemNetworkBond) error {
+func validateParent(parent string, interfaces []api.SystemNetworkInterface, bonds []api.SystemNetworkBond, bridges []api.SystemNetworkBridge) error {
 	if parent == "" {
 		return errors.New("has no parent")
 	}
@@ -344,6 +1160,16 @@ func validateParent(parent string, interfaces []api.SystemNetworkInterface, bond
 		}
 	}
 
+	if !foundParent {
+		for _, b := range bridges {
+			if b.Name == parent {
+				foundParent = true
+
+				break
+			}
+		}
+	}
+
 	if !foundParent {
 		return fmt.Errorf("unable to find parent '%s'", parent)
 	}
@@ -351,6 +1177,39 @@ func validateParent(parent string, interfaces []api.SystemNetworkInterface, bond
 	return nil
 }
 
+// isMemberInUse checks if the given MAC is already used by an interface, a bond or a bridge other than bridgeIndex.
+func isMemberInUse(cfg *api.SystemNetworkConfig, bridgeIndex int, member string) bool {
+	member = strings.ToLower(member)
+
+	for _, i := range cfg.Interfaces {
+		if strings.ToLower(i.Hwaddr) == member {
+			return true
+		}
+	}
+
+	for _, b := range cfg.Bonds {
+		for _, m := range b.Members {
+			if strings.ToLower(m) == member {
+				return true
+			}
+		}
+	}
+
+	for index, b := range cfg.Bridges {
+		if index == bridgeIndex {
+			continue
+		}
+
+		for _, m := range b.Members {
+			if strings.ToLower(m) == member {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func validateRoles(roles []string) error {
 	existing := make([]string, 0, len(roles))
 
@@ -464,6 +1323,48 @@ func validateAddressWithCIDR(address string) error {
 	return nil
 }
 
+func validateRouteOptions(route api.SystemNetworkRoute) error {
+	if route.Metric < 0 {
+		return fmt.Errorf("metric '%d' can't be negative", route.Metric)
+	}
+
+	if route.Table < 0 || route.Table > math.MaxUint32 {
+		return fmt.Errorf("table '%d' out of range", route.Table)
+	}
+
+	return nil
+}
+
+func validateRoutingRule(rule api.SystemNetworkRoutingRule) error {
+	if rule.From == "" && rule.To == "" {
+		return errors.New("must match on 'From' or 'To'")
+	}
+
+	if rule.From != "" {
+		_, _, err := net.ParseCIDR(rule.From)
+		if err != nil {
+			return fmt.Errorf("'From' invalid subnet '%s'", rule.From)
+		}
+	}
+
+	if rule.To != "" {
+		_, _, err := net.ParseCIDR(rule.To)
+		if err != nil {
+			return fmt.Errorf("'To' invalid subnet '%s'", rule.To)
+		}
+	}
+
+	if rule.Table < 1 || rule.Table > math.MaxUint32 {
+		return fmt.Errorf("table '%d' out of range", rule.Table)
+	}
+
+	if rule.Priority < 0 || rule.Priority > math.MaxUint32 {
+		return fmt.Errorf("priority '%d' out of range", rule.Priority)
+	}
+
+	return nil
+}
+
 func validateRequiredForOnline(val string) error {
 	if val != "" && val != "ipv6" && val != "ipv4" && val != "both" && val != "any" && val != "no" {
 		return fmt.Errorf("invalid RequiredForOnline value '%s'", val)
@@ -508,3 +1409,102 @@ func validateEthernet(eth *api.SystemNetworkEthernet) error {
 
 	return nil
 }
+
+func validateNeighbors(neighbors []api.SystemNetworkNeighbor, proxyNDP []string) error {
+	for index, neighbor := range neighbors {
+		if net.ParseIP(neighbor.Address) == nil {
+			return fmt.Errorf("neighbor %d invalid address '%s'", index, neighbor.Address)
+		}
+
+		err := validateHwaddr(neighbor.Hwaddr, true)
+		if err != nil {
+			return fmt.Errorf("neighbor %d %s", index, err.Error())
+		}
+	}
+
+	for _, address := range proxyNDP {
+		ip := net.ParseIP(address)
+		if ip == nil || ip.To4() != nil {
+			return fmt.Errorf("invalid proxy NDP address '%s'", address)
+		}
+	}
+
+	return nil
+}
+
+func validateQoS(qos *api.SystemNetworkQoS) error {
+	if qos == nil {
+		return nil
+	}
+
+	if qos.EgressRate < 0 || qos.HostEgressRate < 0 {
+		return errors.New("QoS egress rates can't be negative")
+	}
+
+	if qos.EgressRate > 0 && qos.HostEgressRate > qos.EgressRate {
+		return errors.New("QoS host egress rate can't exceed the egress rate")
+	}
+
+	if !slices.Contains([]string{"", "besteffort", "precedence", "diffserv3", "diffserv4", "diffserv8"}, qos.PriorityQueuing) {
+		return fmt.Errorf("invalid QoS priority queuing '%s'", qos.PriorityQueuing)
+	}
+
+	return nil
+}
+
+func validateTime(cfg *api.SystemNetworkConfig) error {
+	if cfg.Time == nil {
+		return nil
+	}
+
+	// NTS key exchange validates the server's certificate, so the default pool can't be used.
+	if cfg.Time.NTS && len(cfg.Time.NTPServers) == 0 {
+		return errors.New("NTS requires at least one NTP server")
+	}
+
+	for _, ts := range cfg.Time.NTPServers {
+		if ts == "" || strings.ContainsAny(ts, " \t\n") {
+			return fmt.Errorf("invalid NTP server '%s'", ts)
+		}
+	}
+
+	return nil
+}
+
+func validateFailover(cfg *api.SystemNetworkConfig) error {
+	if cfg.Failover == nil {
+		return nil
+	}
+
+	if len(cfg.Failover.Uplinks) < 2 {
+		return errors.New("failover requires at least two uplinks")
+	}
+
+	for index, uplink := range cfg.Failover.Uplinks {
+		// Wi-Fi and PPPoE sessions commonly provide the backup link.
+		_, found := getDeviceAddresses(cfg, uplink)
+		if !found && !slices.ContainsFunc(cfg.Wifi, func(w api.SystemNetworkWifi) bool { return w.Name == uplink }) && !slices.ContainsFunc(cfg.PPPoE, func(p api.SystemNetworkPPPoE) bool { return p.Name == uplink }) {
+			return fmt.Errorf("failover uplink %d unable to find '%s'", index, uplink)
+		}
+
+		if slices.Index(cfg.Failover.Uplinks, uplink) != index {
+			return fmt.Errorf("failover uplink %d '%s' listed more than once", index, uplink)
+		}
+	}
+
+	if len(cfg.Failover.Targets) == 0 {
+		return errors.New("failover requires at least one health check target")
+	}
+
+	for index, target := range cfg.Failover.Targets {
+		if net.ParseIP(target) == nil {
+			return fmt.Errorf("failover target %d invalid address '%s'", index, target)
+		}
+	}
+
+	if cfg.Failover.Interval < 0 || cfg.Failover.Threshold < 0 {
+		return errors.New("failover interval and threshold can't be negative")
+	}
+
+	return nil
+}
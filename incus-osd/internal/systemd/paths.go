@@ -7,9 +7,27 @@ var (
 	// SystemUpdatesPath is the systemd location for system updates.
 	SystemUpdatesPath = "/var/lib/updates"
 
+	// BootImagesPath is the location of the installed Unified Kernel Images.
+	BootImagesPath = "/boot/EFI/Linux"
+
+	// PartitionLabelsPath is the location of the partition symlinks indexed by label.
+	PartitionLabelsPath = "/dev/disk/by-partlabel"
+
 	// SystemdNetworkConfigPath is the location for systemd network config files.
 	SystemdNetworkConfigPath = "/run/systemd/network/"
 
-	// SystemdTimesyncConfigFile is the configuration file for systemd-timesyncd.
-	SystemdTimesyncConfigFile = "/run/systemd/timesyncd.conf"
+	// ChronyConfigFile is the configuration file for chrony.
+	ChronyConfigFile = "/etc/chrony/chrony.conf"
+
+	// ChronySourcesPath is the location of the chrony sources learned by systemd-networkd.
+	ChronySourcesPath = "/run/chrony-networkd/"
+
+	// NetworkdLinksStatePath is the location of the per-link systemd-networkd state files.
+	NetworkdLinksStatePath = "/run/systemd/netif/links/"
+
+	// PPPPeersPath is the location for pppd peer files.
+	PPPPeersPath = "/etc/ppp/peers/"
+
+	// WPASupplicantConfigPath is the location for per-interface wpa_supplicant config files.
+	WPASupplicantConfigPath = "/etc/wpa_supplicant/"
 )
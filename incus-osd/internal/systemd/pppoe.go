@@ -0,0 +1,192 @@
+package systemd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+var pppoeSystemd = `# Systemd unit generated by IncusOS
+[Unit]
+Description=PPPoE session %s
+After=systemd-networkd.service
+
+[Service]
+ExecStart=/usr/sbin/pppd call %s nodetach
+Restart=always
+RestartSec=5
+`
+
+// applyPPPoEConfiguration writes the pppd peer and systemd unit for each PPPoE session and
+// (re)starts it, stopping any session no longer configured.
+func applyPPPoEConfiguration(ctx context.Context, networkCfg *api.SystemNetworkConfig) error {
+	err := os.MkdirAll(PPPPeersPath, 0o755)
+	if err != nil {
+		return err
+	}
+
+	// Stop and remove any session that isn't configured anymore.
+	units, err := filepath.Glob("/run/systemd/system/pppoe-*.service")
+	if err != nil {
+		return err
+	}
+
+	for _, unit := range units {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(unit), "pppoe-"), ".service")
+
+		if slices.ContainsFunc(networkCfg.PPPoE, func(p api.SystemNetworkPPPoE) bool { return p.Name == name }) {
+			continue
+		}
+
+		err := StopUnit(ctx, filepath.Base(unit))
+		if err != nil {
+			return err
+		}
+
+		err = os.Remove(unit)
+		if err != nil {
+			return err
+		}
+
+		err = os.Remove(filepath.Join(PPPPeersPath, name))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	// Write the peer and unit for each session.
+	for _, p := range networkCfg.PPPoE {
+		err := os.WriteFile(filepath.Join(PPPPeersPath, p.Name), []byte(generatePPPoEPeerContents(p, networkCfg)), 0o600)
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile("/run/systemd/system/pppoe-"+p.Name+".service", []byte(fmt.Sprintf(pppoeSystemd, p.Name, p.Name)), 0o644)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = ReloadDaemon(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range networkCfg.PPPoE {
+		err := RestartUnit(ctx, "pppoe-"+p.Name+".service")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// generatePPPoEPeerContents generates the pppd peer file for a PPPoE session.
+func generatePPPoEPeerContents(p api.SystemNetworkPPPoE, networkCfg *api.SystemNetworkConfig) string {
+	var ret strings.Builder
+
+	_, _ = ret.WriteString("plugin pppoe.so\n")
+	_, _ = ret.WriteString(fmt.Sprintf("nic-%s\n", getHostDevice(p.Parent, networkCfg)))
+	_, _ = ret.WriteString(fmt.Sprintf("ifname %s\n", p.Name))
+	_, _ = ret.WriteString(fmt.Sprintf("user \"%s\"\n", p.Username))
+	_, _ = ret.WriteString(fmt.Sprintf("password \"%s\"\n", p.Password))
+
+	if p.ServiceName != "" {
+		_, _ = ret.WriteString(fmt.Sprintf("rp_pppoe_service \"%s\"\n", p.ServiceName))
+	}
+
+	// Keep the session up, reconnecting as needed.
+	_, _ = ret.WriteString("noauth\nhide-password\npersist\nmaxfail 0\nholdoff 5\nlcp-echo-interval 20\nlcp-echo-failure 3\nnoipdefault\n")
+
+	if p.DefaultRoute {
+		_, _ = ret.WriteString("defaultroute\n")
+	}
+
+	if p.IPv6 {
+		_, _ = ret.WriteString("+ipv6\n")
+	}
+
+	if p.MTU != 0 {
+		_, _ = ret.WriteString(fmt.Sprintf("mtu %d\nmru %d\n", p.MTU, p.MTU))
+	}
+
+	return ret.String()
+}
+
+// getPPPoEState gathers the session state and addresses of a PPPoE session.
+func getPPPoEState(ctx context.Context, iface string) (api.SystemNetworkInterfaceState, error) {
+	ret := api.SystemNetworkInterfaceState{
+		Type:  "pppoe",
+		Speed: "unknown",
+		State: "off",
+		PPPoE: &api.SystemNetworkPPPoEState{
+			Session: "disconnected",
+		},
+	}
+
+	if !IsActive(ctx, "pppoe-"+iface+".service") {
+		return ret, nil
+	}
+
+	ret.PPPoE.Session = "connecting"
+
+	// The ppp device only exists once the session is established.
+	_, err := os.Stat("/sys/class/net/" + iface)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ret, nil
+		}
+
+		return api.SystemNetworkInterfaceState{}, err
+	}
+
+	output, err := subprocess.RunCommandContext(ctx, "ip", "address", "show", iface)
+	if err != nil {
+		return api.SystemNetworkInterfaceState{}, err
+	}
+
+	addresses, peer := parsePPPoEAddresses(output)
+	if len(addresses) == 0 {
+		return ret, nil
+	}
+
+	ret.Addresses = addresses
+	ret.State = "routable"
+	ret.PPPoE.Session = "connected"
+	ret.PPPoE.PeerAddress = peer
+
+	return ret, nil
+}
+
+// parsePPPoEAddresses parses the output of "ip address show" for a ppp device, returning the
+// local non-link-local addresses and the peer's IPv4 address.
+func parsePPPoEAddresses(output string) ([]string, string) {
+	addressRegex := regexp.MustCompile(`inet6? ([^ /]+)(?:/\d+)?(?: peer ([^ /]+))?`)
+
+	addresses := []string{}
+	peer := ""
+
+	for _, match := range addressRegex.FindAllStringSubmatch(output, -1) {
+		if strings.HasPrefix(match[1], "fe80:") {
+			continue
+		}
+
+		addresses = append(addresses, match[1])
+
+		if peer == "" && match[2] != "" {
+			peer = match[2]
+		}
+	}
+
+	return addresses, peer
+}
@@ -0,0 +1,54 @@
+package systemd
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+var (
+	rebootMu    sync.Mutex
+	rebootTimer *time.Timer
+)
+
+// ScheduleUpdateReboot schedules the reboot finalizing a pending update according to the configured
+// reboot policy, replacing any previously scheduled reboot. Nothing is scheduled if no update is
+// pending or the policy never allows the system to reboot by itself.
+func ScheduleUpdateReboot(ctx context.Context, s *state.State) {
+	rebootMu.Lock()
+	defer rebootMu.Unlock()
+
+	if rebootTimer != nil {
+		rebootTimer.Stop()
+		rebootTimer = nil
+	}
+
+	s.System.Update.State.RebootScheduledAt = nil
+
+	if !s.System.Update.State.NeedsReboot {
+		return
+	}
+
+	delay, ok := s.System.Update.Config.TimeUntilReboot(time.Now())
+	if !ok {
+		slog.InfoContext(ctx, "A reboot is required to finalize the update")
+
+		return
+	}
+
+	rebootAt := time.Now().Add(delay)
+	s.System.Update.State.RebootScheduledAt = &rebootAt
+
+	slog.InfoContext(ctx, "Scheduled reboot to finalize the update", "policy", s.System.Update.Config.GetRebootPolicy(), "time", rebootAt.Format(time.RFC3339))
+
+	rebootTimer = time.AfterFunc(delay, func() {
+		// Go through the regular shutdown sequence.
+		select {
+		case s.TriggerReboot <- nil:
+		default:
+		}
+	})
+}
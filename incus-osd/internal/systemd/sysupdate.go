@@ -64,6 +64,12 @@ func ApplySystemUpdate(ctx context.Context, luksPassword string, version string,
 		return err
 	}
 
+	// Clear any default set by a previous rollback so the new version gets booted.
+	err = SetDefaultBootVersion(ctx, "", "")
+	if err != nil {
+		return err
+	}
+
 	// Check if the Secure Boot key has changed; if it has apply the necessary updates.
 	var newUKIFile string
 
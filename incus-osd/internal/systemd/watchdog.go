@@ -0,0 +1,273 @@
+package systemd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+)
+
+// watchdogConfigPath overrides the hardware watchdog settings of systemd.
+var watchdogConfigPath = "/etc/systemd/system.conf.d/incus-os-watchdog.conf"
+
+// watchdogResetReasons maps the WDIOF_* flags of the watchdog boot status to the reported reasons.
+var watchdogResetReasons = []struct {
+	flag   int
+	reason string
+}{
+	{0x0020, "watchdog"},
+	{0x0001, "overheat"},
+	{0x0002, "fan-fault"},
+	{0x0010, "power-under"},
+	{0x0040, "power-over"},
+	{0x0004, "external"},
+	{0x0008, "external"},
+}
+
+// ValidateWatchdog checks that the hardware watchdog configuration is usable.
+func ValidateWatchdog(cfg api.SystemWatchdogConfig) error {
+	if cfg.Device != "" && (!strings.HasPrefix(cfg.Device, "/dev/watchdog") || strings.ContainsAny(cfg.Device, " \n")) {
+		return fmt.Errorf("invalid watchdog device %q", cfg.Device)
+	}
+
+	if cfg.Timeout < 0 || cfg.Timeout > 3600 {
+		return fmt.Errorf("invalid watchdog timeout %d, must be between 1 and 3600 seconds", cfg.Timeout)
+	}
+
+	if cfg.RebootTimeout < 0 || cfg.RebootTimeout > 86400 {
+		return fmt.Errorf("invalid watchdog reboot timeout %d, must be between 1 and 86400 seconds", cfg.RebootTimeout)
+	}
+
+	return nil
+}
+
+// ApplyWatchdog configures systemd to keep the hardware watchdog armed, re-executing systemd if the
+// configuration changed.
+func ApplyWatchdog(ctx context.Context, cfg api.SystemWatchdogConfig) error {
+	current, err := os.ReadFile(watchdogConfigPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	contents := generateWatchdogContents(cfg)
+	if contents == string(current) {
+		return nil
+	}
+
+	if contents == "" {
+		err = os.Remove(watchdogConfigPath)
+		if err != nil {
+			return err
+		}
+	} else {
+		err = os.MkdirAll(filepath.Dir(watchdogConfigPath), 0o755)
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(watchdogConfigPath, []byte(contents), 0o644)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The manager settings are only read when systemd is (re-)executed.
+	_, err = subprocess.RunCommandContext(ctx, "systemctl", "daemon-reexec")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// generateWatchdogContents returns the systemd configuration for the hardware watchdog, or an empty
+// string if disabled.
+func generateWatchdogContents(cfg api.SystemWatchdogConfig) string {
+	if !cfg.Enabled {
+		return ""
+	}
+
+	device := cfg.Device
+	if device == "" {
+		device = "/dev/watchdog0"
+	}
+
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = 60
+	}
+
+	rebootTimeout := cfg.RebootTimeout
+	if rebootTimeout == 0 {
+		rebootTimeout = 600
+	}
+
+	return fmt.Sprintf("# Generated by IncusOS\n[Manager]\nWatchdogDevice=%s\nRuntimeWatchdogSec=%ds\nRebootWatchdogSec=%ds\n", device, timeout, rebootTimeout)
+}
+
+// GetWatchdogState returns the hardware watchdogs and the reason of the last reset they reported.
+func GetWatchdogState() api.SystemWatchdogState {
+	ret := api.SystemWatchdogState{
+		Devices: []api.SystemWatchdogDevice{},
+	}
+
+	entries, err := os.ReadDir("/sys/class/watchdog")
+	if err != nil {
+		return ret
+	}
+
+	for _, entry := range entries {
+		devicePath := filepath.Join("/sys/class/watchdog", entry.Name())
+
+		device := api.SystemWatchdogDevice{
+			Name:     entry.Name(),
+			Identity: readWatchdogAttr(devicePath, "identity"),
+			Active:   readWatchdogAttr(devicePath, "state") == "active",
+		}
+
+		device.Timeout, _ = strconv.Atoi(readWatchdogAttr(devicePath, "timeout"))
+
+		ret.Devices = append(ret.Devices, device)
+
+		// Report the first reset reason found.
+		bootStatus, err := strconv.Atoi(readWatchdogAttr(devicePath, "bootstatus"))
+		if err != nil || ret.LastResetReason != "" && ret.LastResetReason != "none" {
+			continue
+		}
+
+		ret.LastResetReason = "none"
+
+		for _, resetReason := range watchdogResetReasons {
+			if bootStatus&resetReason.flag != 0 {
+				ret.LastResetReason = resetReason.reason
+
+				break
+			}
+		}
+	}
+
+	slices.SortFunc(ret.Devices, func(a api.SystemWatchdogDevice, b api.SystemWatchdogDevice) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return ret
+}
+
+// RunWatchdog arms the systemd watchdog of incus-osd when configured, sending keepalives as long as the
+// API responds. It only returns once the context is cancelled.
+func RunWatchdog(ctx context.Context, s *state.State, socketPath string) {
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _ string, _ string) (net.Conn, error) {
+				var d net.Dialer
+
+				return d.DialContext(ctx, "unix", socketPath)
+			},
+		},
+	}
+
+	armed := 0
+
+	for {
+		timeout := 0
+		if s.System.Watchdog.Config.Daemon {
+			timeout = s.System.Watchdog.Config.Timeout
+			if timeout == 0 {
+				timeout = 60
+			}
+		}
+
+		// Arm, update or disarm the watchdog.
+		if timeout != armed {
+			err := sdNotify(fmt.Sprintf("WATCHDOG_USEC=%d", timeout*1000000))
+			if err != nil {
+				slog.WarnContext(ctx, "Failed configuring the systemd watchdog", "err", err)
+			} else {
+				armed = timeout
+			}
+		}
+
+		interval := 10 * time.Second
+
+		if armed > 0 {
+			err := checkWatchdogAPI(ctx, client)
+			if err != nil {
+				slog.WarnContext(ctx, "Skipping watchdog keepalive, the API isn't responding", "err", err)
+			} else {
+				_ = sdNotify("WATCHDOG=1")
+			}
+
+			interval = max(time.Duration(armed)*time.Second/4, time.Second)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// checkWatchdogAPI checks that the local API responds.
+func checkWatchdogAPI(ctx context.Context, client *http.Client) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://incus-osd/1.0", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// sdNotify sends a notification to systemd.
+func sdNotify(notification string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return errors.New("systemd notification socket isn't available")
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	_, err = conn.Write([]byte(notification))
+
+	return err
+}
+
+// readWatchdogAttr reads a sysfs attribute of a watchdog device.
+func readWatchdogAttr(devicePath string, name string) string {
+	content, err := os.ReadFile(filepath.Join(devicePath, name)) //nolint:gosec
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(content))
+}
@@ -0,0 +1,25 @@
+package systemd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+func TestWatchdog(t *testing.T) {
+	t.Parallel()
+
+	// Validation.
+	require.NoError(t, ValidateWatchdog(api.SystemWatchdogConfig{}))
+	require.NoError(t, ValidateWatchdog(api.SystemWatchdogConfig{Enabled: true, Device: "/dev/watchdog1", Timeout: 30}))
+	require.Error(t, ValidateWatchdog(api.SystemWatchdogConfig{Enabled: true, Device: "/dev/sda"}))
+	require.Error(t, ValidateWatchdog(api.SystemWatchdogConfig{Enabled: true, Timeout: -1}))
+	require.Error(t, ValidateWatchdog(api.SystemWatchdogConfig{Enabled: true, RebootTimeout: 100000}))
+
+	// Systemd configuration, with the defaults applied.
+	require.Empty(t, generateWatchdogContents(api.SystemWatchdogConfig{Daemon: true}))
+	require.Equal(t, "# Generated by IncusOS\n[Manager]\nWatchdogDevice=/dev/watchdog0\nRuntimeWatchdogSec=60s\nRebootWatchdogSec=600s\n", generateWatchdogContents(api.SystemWatchdogConfig{Enabled: true}))
+	require.Equal(t, "# Generated by IncusOS\n[Manager]\nWatchdogDevice=/dev/watchdog1\nRuntimeWatchdogSec=30s\nRebootWatchdogSec=120s\n", generateWatchdogContents(api.SystemWatchdogConfig{Enabled: true, Device: "/dev/watchdog1", Timeout: 30, RebootTimeout: 120}))
+}
@@ -0,0 +1,246 @@
+package systemd
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/incus/v6/shared/subprocess"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+// applyWifiConfiguration writes the wpa_supplicant configuration for each Wi-Fi interface and
+// (re)starts the matching wpa_supplicant@ unit, stopping any unit no longer configured.
+func applyWifiConfiguration(ctx context.Context, networkCfg *api.SystemNetworkConfig) error {
+	err := os.MkdirAll(WPASupplicantConfigPath, 0o700)
+	if err != nil {
+		return err
+	}
+
+	// Stop and remove any Wi-Fi interface that isn't configured anymore.
+	entries, err := os.ReadDir(WPASupplicantConfigPath)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name, ok := strings.CutPrefix(entry.Name(), "wpa_supplicant-")
+		if !ok {
+			continue
+		}
+
+		name, ok = strings.CutSuffix(name, ".conf")
+		if !ok || strings.HasPrefix(name, wiredSupplicantPrefix) {
+			continue
+		}
+
+		if slices.ContainsFunc(networkCfg.Wifi, func(w api.SystemNetworkWifi) bool { return w.Name == name }) {
+			continue
+		}
+
+		err := StopUnit(ctx, "wpa_supplicant@"+name+".service")
+		if err != nil {
+			return err
+		}
+
+		err = removeSupplicantFiles(name)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Write the configuration and (re)start wpa_supplicant for each Wi-Fi interface.
+	for _, w := range networkCfg.Wifi {
+		err := removeSupplicantFiles(w.Name)
+		if err != nil {
+			return err
+		}
+
+		if w.EAP != nil {
+			err := writeEAPFiles(w.Name, w.EAP)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = os.WriteFile(filepath.Join(WPASupplicantConfigPath, "wpa_supplicant-"+w.Name+".conf"), []byte(generateWpaSupplicantContents(w)), 0o600)
+		if err != nil {
+			return err
+		}
+
+		err = RestartUnit(ctx, "wpa_supplicant@"+w.Name+".service")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeSupplicantFiles removes a wpa_supplicant configuration and its certificates.
+func removeSupplicantFiles(name string) error {
+	for _, file := range []string{"wpa_supplicant-" + name + ".conf", name + "-ca.crt", name + "-client.crt", name + "-client.key"} {
+		err := os.Remove(filepath.Join(WPASupplicantConfigPath, file))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// generateWpaSupplicantContents generates the contents of the wpa_supplicant configuration for a Wi-Fi interface.
+func generateWpaSupplicantContents(w api.SystemNetworkWifi) string {
+	var ret strings.Builder
+
+	_, _ = ret.WriteString("ctrl_interface=/run/wpa_supplicant\n")
+
+	if w.Country != "" {
+		_, _ = ret.WriteString(fmt.Sprintf("country=%s\n", w.Country))
+	}
+
+	_, _ = ret.WriteString("\nnetwork={\n")
+
+	// Hex-encode the SSID so it can contain any character.
+	_, _ = ret.WriteString(fmt.Sprintf("\tssid=%s\n", hex.EncodeToString([]byte(w.SSID))))
+
+	if w.Hidden {
+		_, _ = ret.WriteString("\tscan_ssid=1\n")
+	}
+
+	if w.EAP != nil {
+		if w.Security == "wpa3" {
+			_, _ = ret.WriteString("\tkey_mgmt=WPA-EAP-SHA256\n\tieee80211w=2\n")
+		} else {
+			_, _ = ret.WriteString("\tkey_mgmt=WPA-EAP\n")
+		}
+
+		_, _ = ret.WriteString(generateEAPContents(w.Name, w.EAP))
+	} else {
+		switch w.Security {
+		case "wpa3":
+			_, _ = ret.WriteString(fmt.Sprintf("\tkey_mgmt=SAE\n\tieee80211w=2\n\tsae_password=\"%s\"\n", w.PSK))
+		case "wpa2-wpa3":
+			_, _ = ret.WriteString(fmt.Sprintf("\tkey_mgmt=WPA-PSK SAE\n\tieee80211w=1\n\tpsk=\"%s\"\n\tsae_password=\"%s\"\n", w.PSK, w.PSK))
+		default:
+			_, _ = ret.WriteString(fmt.Sprintf("\tkey_mgmt=WPA-PSK\n\tpsk=\"%s\"\n", w.PSK))
+		}
+	}
+
+	_, _ = ret.WriteString("}\n")
+
+	return ret.String()
+}
+
+// writeEAPFiles writes the certificates and key referenced by a wpa_supplicant EAP configuration.
+func writeEAPFiles(name string, eap *api.SystemNetworkEAP) error {
+	for suffix, contents := range map[string]string{"ca.crt": eap.CACertificate, "client.crt": eap.ClientCertificate, "client.key": eap.ClientKey} {
+		if contents == "" {
+			continue
+		}
+
+		err := os.WriteFile(filepath.Join(WPASupplicantConfigPath, name+"-"+suffix), []byte(contents), 0o600)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// generateEAPContents generates the EAP method and credential lines of a wpa_supplicant network block.
+func generateEAPContents(name string, eap *api.SystemNetworkEAP) string {
+	var ret strings.Builder
+
+	_, _ = ret.WriteString(fmt.Sprintf("\teap=%s\n", strings.ToUpper(eap.Method)))
+
+	if eap.Identity != "" {
+		_, _ = ret.WriteString(fmt.Sprintf("\tidentity=\"%s\"\n", eap.Identity))
+	}
+
+	if eap.AnonymousIdentity != "" {
+		_, _ = ret.WriteString(fmt.Sprintf("\tanonymous_identity=\"%s\"\n", eap.AnonymousIdentity))
+	}
+
+	if eap.Password != "" {
+		_, _ = ret.WriteString(fmt.Sprintf("\tpassword=\"%s\"\n", eap.Password))
+	}
+
+	if eap.Method != "tls" {
+		phase2 := eap.Phase2
+		if phase2 == "" {
+			phase2 = "mschapv2"
+		}
+
+		_, _ = ret.WriteString(fmt.Sprintf("\tphase2=\"auth=%s\"\n", strings.ToUpper(phase2)))
+	}
+
+	if eap.CACertificate != "" {
+		_, _ = ret.WriteString(fmt.Sprintf("\tca_cert=\"%s\"\n", filepath.Join(WPASupplicantConfigPath, name+"-ca.crt")))
+	}
+
+	if eap.ClientCertificate != "" {
+		_, _ = ret.WriteString(fmt.Sprintf("\tclient_cert=\"%s\"\n", filepath.Join(WPASupplicantConfigPath, name+"-client.crt")))
+	}
+
+	if eap.ClientKey != "" {
+		_, _ = ret.WriteString(fmt.Sprintf("\tprivate_key=\"%s\"\n", filepath.Join(WPASupplicantConfigPath, name+"-client.key")))
+	}
+
+	return ret.String()
+}
+
+// getWifiState queries wpa_supplicant for the connection status and signal of a Wi-Fi interface.
+func getWifiState(ctx context.Context, iface string) *api.SystemNetworkWifiState {
+	ret := &api.SystemNetworkWifiState{
+		State: "unknown",
+	}
+
+	output, err := subprocess.RunCommandContext(ctx, "wpa_cli", "-i", iface, "status")
+	if err != nil {
+		return ret
+	}
+
+	parseWifiStatus(output, ret)
+
+	output, err = subprocess.RunCommandContext(ctx, "wpa_cli", "-i", iface, "signal_poll")
+	if err == nil {
+		parseWifiStatus(output, ret)
+	}
+
+	return ret
+}
+
+// parseWifiStatus parses the key=value output of "wpa_cli status" and "wpa_cli signal_poll".
+func parseWifiStatus(output string, state *api.SystemNetworkWifiState) {
+	for line := range strings.SplitSeq(output, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "wpa_state":
+			state.State = strings.ToLower(value)
+		case "ssid":
+			state.SSID = value
+		case "bssid":
+			state.BSSID = value
+		case "key_mgmt":
+			state.Security = value
+		case "RSSI":
+			state.Signal, _ = strconv.Atoi(value)
+		case "LINKSPEED":
+			state.LinkSpeed, _ = strconv.Atoi(value)
+		case "FREQUENCY", "freq":
+			state.Frequency, _ = strconv.Atoi(value)
+		}
+	}
+}
@@ -0,0 +1,2 @@
+// Package truststore provides logic to manage the custom CA certificates added to the system trust store.
+package truststore
@@ -0,0 +1,136 @@
+package truststore
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+var (
+	// CertsPath is the system certificates directory, read by most TLS clients.
+	CertsPath = "/etc/ssl/certs"
+
+	// SystemCertsPath is the read-only directory holding the CA bundle shipped with the IncusOS image.
+	SystemCertsPath = "/usr/share/certs"
+
+	// RuntimeCertsPath is the directory holding the CA bundle including the custom CA certificates.
+	RuntimeCertsPath = "/run/incus-os/certs"
+)
+
+// bundleName is the name of the CA bundle in the certificates directories.
+const bundleName = "ca-certificates.crt"
+
+// ParseCertificate checks that a PEM-encoded CA certificate is usable and returns its SHA256 fingerprint.
+func ParseCertificate(certificate string) (string, error) {
+	block, rest := pem.Decode([]byte(certificate))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return "", errors.New("cannot parse certificate PEM")
+	}
+
+	if strings.TrimSpace(string(rest)) != "" {
+		return "", errors.New("only a single certificate may be provided")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("invalid certificate: %w", err)
+	}
+
+	if !cert.IsCA {
+		return "", fmt.Errorf("certificate %q isn't a CA certificate", cert.Subject.String())
+	}
+
+	rawFp := sha256.Sum256(cert.Raw)
+
+	return hex.EncodeToString(rawFp[:]), nil
+}
+
+// Validate checks the custom CA certificates, filling in their fingerprint.
+func Validate(certs []api.SystemSecurityCACertificate) error {
+	seen := map[string]bool{}
+
+	for i, cert := range certs {
+		fingerprint, err := ParseCertificate(cert.Certificate)
+		if err != nil {
+			return fmt.Errorf("invalid CA certificate %q: %w", cert.Name, err)
+		}
+
+		if seen[fingerprint] {
+			return fmt.Errorf("CA certificate %q is listed more than once", fingerprint)
+		}
+
+		seen[fingerprint] = true
+		certs[i].Fingerprint = fingerprint
+	}
+
+	return nil
+}
+
+// Apply updates the system trust store to include the custom CA certificates. Processes only pick up
+// the changes once restarted, as TLS libraries typically load the trust store once.
+func Apply(certs []api.SystemSecurityCACertificate) error {
+	target := SystemCertsPath
+
+	if len(certs) > 0 {
+		systemBundle, err := os.ReadFile(filepath.Join(SystemCertsPath, bundleName))
+		if err != nil {
+			return err
+		}
+
+		err = os.MkdirAll(RuntimeCertsPath, 0o755)
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(filepath.Join(RuntimeCertsPath, bundleName), generateBundle(systemBundle, certs), 0o644)
+		if err != nil {
+			return err
+		}
+
+		target = RuntimeCertsPath
+	}
+
+	// Nothing to do if already pointing to the right bundle.
+	current, err := os.Readlink(CertsPath)
+	if err == nil && current == target {
+		return nil
+	}
+
+	// Atomically replace the symlink.
+	tmpPath := CertsPath + ".tmp"
+
+	_ = os.Remove(tmpPath)
+
+	err = os.Symlink(target, tmpPath)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, CertsPath)
+}
+
+// generateBundle returns the system CA bundle with the custom CA certificates appended.
+func generateBundle(systemBundle []byte, certs []api.SystemSecurityCACertificate) []byte {
+	var b strings.Builder
+
+	b.Write(systemBundle)
+
+	if len(systemBundle) > 0 && !strings.HasSuffix(string(systemBundle), "\n") {
+		b.WriteString("\n")
+	}
+
+	for _, cert := range certs {
+		b.WriteString("\n# IncusOS custom CA: " + strings.Join(strings.Fields(cert.Name), " ") + "\n")
+		b.WriteString(strings.TrimSpace(cert.Certificate) + "\n")
+	}
+
+	return []byte(b.String())
+}
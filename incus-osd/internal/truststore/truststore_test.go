@@ -0,0 +1,60 @@
+package truststore
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+func generateCertificate(t *testing.T, isCA bool) string {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	ca := generateCertificate(t, true)
+
+	certs := []api.SystemSecurityCACertificate{{Name: "internal", Certificate: ca}}
+	require.NoError(t, Validate(certs))
+	require.Len(t, certs[0].Fingerprint, 64)
+
+	require.Error(t, Validate([]api.SystemSecurityCACertificate{{Name: "internal", Certificate: ca}, {Name: "duplicate", Certificate: ca}}))
+	require.Error(t, Validate([]api.SystemSecurityCACertificate{{Name: "leaf", Certificate: generateCertificate(t, false)}}))
+	require.Error(t, Validate([]api.SystemSecurityCACertificate{{Name: "bundle", Certificate: ca + ca}}))
+	require.Error(t, Validate([]api.SystemSecurityCACertificate{{Name: "garbage", Certificate: "garbage"}}))
+}
+
+func TestGenerateBundle(t *testing.T) {
+	t.Parallel()
+
+	bundle := generateBundle([]byte("SYSTEM"), []api.SystemSecurityCACertificate{{Name: "internal\nCA", Certificate: "CERT\n\n"}})
+	require.Equal(t, "SYSTEM\n\n# IncusOS custom CA: internal CA\nCERT\n", string(bundle))
+}
@@ -311,6 +311,10 @@ func (t *TUI) redrawScreen() {
 		if !t.state.System.Security.State.EncryptionRecoveryKeysRetrieved {
 			t.frame.AddText("WARNING: Some encryption recovery keys have not been retrieved yet!", false, tview.AlignLeft, tcell.ColorRed)
 		}
+
+		if t.state.System.Security.State.TPMUnlockWarning {
+			t.frame.AddText("WARNING: The TPM failed to unlock the encrypted volumes on a previous boot!", false, tview.AlignLeft, tcell.ColorRed)
+		}
 	}
 
 	// Show main content.
@@ -354,12 +358,28 @@ func (t *TUI) getIPAddresses() []string {
 		}
 	}
 
+	for _, b := range t.state.System.Network.Config.Bridges {
+		if len(b.Addresses) > 0 {
+			appendIPs(b.Name)
+		}
+	}
+
 	for _, v := range t.state.System.Network.Config.VLANs {
 		if len(v.Addresses) > 0 {
 			appendIPs(v.Name)
 		}
 	}
 
+	for _, w := range t.state.System.Network.Config.Wifi {
+		if len(w.Addresses) > 0 {
+			appendIPs(w.Name)
+		}
+	}
+
+	for _, p := range t.state.System.Network.Config.PPPoE {
+		appendIPs(p.Name)
+	}
+
 	return ret
 }
 
@@ -0,0 +1,2 @@
+// Package usb provides logic to enforce the USB device authorization policy.
+package usb
@@ -0,0 +1,353 @@
+package usb
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+	"github.com/lxc/incus-os/incus-osd/internal/state"
+	"github.com/lxc/incus-os/incus-osd/internal/storage"
+)
+
+// SysfsPath is the path listing the USB devices.
+var SysfsPath = "/sys/bus/usb/devices"
+
+// hubClass is the class of USB hubs, which are always allowed so devices behind them can be evaluated.
+const hubClass = "09"
+
+var (
+	classRegex  = regexp.MustCompile(`^[0-9a-f]{2}$`)
+	deviceRegex = regexp.MustCompile(`^[0-9a-f]{4}:[0-9a-f]{4}$`)
+)
+
+// ValidatePolicy checks that the USB device authorization policy is usable.
+func ValidatePolicy(cfg *api.SystemSecurityUSB) error {
+	if cfg == nil {
+		return nil
+	}
+
+	if cfg.Policy != "" && cfg.Policy != "allow" && cfg.Policy != "block" {
+		return fmt.Errorf("invalid USB policy %q", cfg.Policy)
+	}
+
+	for _, class := range cfg.AllowedClasses {
+		if !classRegex.MatchString(class) {
+			return fmt.Errorf("invalid USB class %q, expected two lowercase hexadecimal digits", class)
+		}
+	}
+
+	for _, device := range cfg.AllowedDevices {
+		err := ValidateDeviceID(device)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateDeviceID checks that a USB device is identified as "vendor:product" lowercase hexadecimal IDs.
+func ValidateDeviceID(device string) error {
+	if !deviceRegex.MatchString(device) {
+		return fmt.Errorf("invalid USB device %q, expected \"vendor:product\" lowercase hexadecimal IDs", device)
+	}
+
+	return nil
+}
+
+// ListDevices returns the USB devices attached to the host, excluding the root hubs.
+func ListDevices() ([]api.SystemSecurityUSBDevice, error) {
+	ret := []api.SystemSecurityUSBDevice{}
+
+	entries, err := os.ReadDir(SysfsPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ret, nil
+		}
+
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		// Skip the root hubs and interfaces.
+		if strings.HasPrefix(entry.Name(), "usb") || strings.Contains(entry.Name(), ":") {
+			continue
+		}
+
+		device, err := getDevice(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, *device)
+	}
+
+	return ret, nil
+}
+
+// ApplyPolicy applies the USB device authorization policy. With the "block" policy, new devices are
+// only authorized once allowed, and any attached device not allowed is immediately disconnected,
+// except for the one IncusOS is running from.
+func ApplyPolicy(ctx context.Context, cfg *api.SystemSecurityUSB) error {
+	block := cfg != nil && cfg.Policy == "block"
+
+	// Set the default for new devices on each root hub.
+	entries, err := os.ReadDir(SysfsPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	authorizedDefault := "1"
+	if block {
+		authorizedDefault = "0"
+	}
+
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), "usb") {
+			continue
+		}
+
+		err := os.WriteFile(filepath.Join(SysfsPath, entry.Name(), "authorized_default"), []byte(authorizedDefault), 0o600)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Evaluate the attached devices.
+	devices, err := ListDevices()
+	if err != nil {
+		return err
+	}
+
+	systemDevice := GetSystemDevice(ctx)
+
+	for _, device := range devices {
+		allowed := !block || isAllowed(cfg, device) || device.ID == systemDevice
+		if allowed == device.Authorized {
+			continue
+		}
+
+		if !allowed {
+			slog.WarnContext(ctx, "Blocking USB device", "id", device.ID, "vendor", device.VendorID, "product", device.ProductID)
+		}
+
+		err := setAuthorized(device.ID, allowed)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Authorize authorizes a blocked USB device.
+func Authorize(id string) error {
+	if id == "" || strings.ContainsAny(id, "/:") || strings.HasPrefix(id, "usb") {
+		return fmt.Errorf("invalid USB device %q", id)
+	}
+
+	_, err := os.Stat(filepath.Join(SysfsPath, id))
+	if err != nil {
+		return fmt.Errorf("USB device %q not found", id)
+	}
+
+	return setAuthorized(id, true)
+}
+
+// RunPolicy periodically authorizes the newly attached USB devices allowed by the policy. It only
+// returns once the context is cancelled.
+func RunPolicy(ctx context.Context, s *state.State) {
+	blocked := map[string]bool{}
+
+	for {
+		cfg := s.System.Security.Config.USB
+
+		if cfg != nil && cfg.Policy == "block" {
+			devices, err := ListDevices()
+			if err != nil {
+				slog.WarnContext(ctx, "Failed to list USB devices", "err", err)
+			}
+
+			stillBlocked := map[string]bool{}
+
+			for _, device := range devices {
+				if device.Authorized {
+					continue
+				}
+
+				if !isAllowed(cfg, device) {
+					// Only log once per attachment.
+					if !blocked[device.ID] {
+						slog.WarnContext(ctx, "Blocked USB device", "id", device.ID, "vendor", device.VendorID, "product", device.ProductID)
+					}
+
+					stillBlocked[device.ID] = true
+
+					continue
+				}
+
+				err := setAuthorized(device.ID, true)
+				if err != nil {
+					slog.WarnContext(ctx, "Failed to authorize USB device", "id", device.ID, "err", err)
+				}
+			}
+
+			blocked = stillBlocked
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// isAllowed returns whether the device is allowed by the policy. A device is allowed if listed, or if
+// all of its classes are allowed.
+func isAllowed(cfg *api.SystemSecurityUSB, device api.SystemSecurityUSBDevice) bool {
+	if slices.Contains(cfg.AllowedDevices, device.VendorID+":"+device.ProductID) {
+		return true
+	}
+
+	if len(device.Classes) == 0 {
+		return false
+	}
+
+	for _, class := range device.Classes {
+		if class != hubClass && !slices.Contains(cfg.AllowedClasses, class) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// getDevice returns the details of a USB device from sysfs.
+func getDevice(id string) (*api.SystemSecurityUSBDevice, error) {
+	devicePath := filepath.Join(SysfsPath, id)
+
+	readAttribute := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(devicePath, name)) //nolint:gosec
+		if err != nil {
+			return ""
+		}
+
+		return strings.TrimSpace(string(content))
+	}
+
+	device := &api.SystemSecurityUSBDevice{
+		ID:           id,
+		VendorID:     readAttribute("idVendor"),
+		ProductID:    readAttribute("idProduct"),
+		Manufacturer: readAttribute("manufacturer"),
+		Product:      readAttribute("product"),
+		Serial:       readAttribute("serial"),
+		Authorized:   readAttribute("authorized") == "1",
+	}
+
+	// The descriptors are available even for devices which aren't authorized.
+	descriptors, err := os.ReadFile(filepath.Join(devicePath, "descriptors")) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	device.Classes = parseClasses(descriptors)
+
+	return device, nil
+}
+
+// parseClasses returns the device and interface classes found in the raw USB descriptors, as
+// exposed by sysfs: the device descriptor followed by the configuration descriptors.
+func parseClasses(descriptors []byte) []string {
+	classes := []string{}
+
+	addClass := func(class byte) {
+		// Class 0 means the class is defined at the interface level.
+		if class == 0 {
+			return
+		}
+
+		value := hex.EncodeToString([]byte{class})
+		if !slices.Contains(classes, value) {
+			classes = append(classes, value)
+		}
+	}
+
+	for offset := 0; offset+2 <= len(descriptors); {
+		length := int(descriptors[offset])
+		if length < 2 || offset+length > len(descriptors) {
+			break
+		}
+
+		switch descriptors[offset+1] {
+		case 1:
+			// Device descriptor.
+			if length >= 5 {
+				addClass(descriptors[offset+4])
+			}
+
+		case 4:
+			// Interface descriptor.
+			if length >= 6 {
+				addClass(descriptors[offset+5])
+			}
+		}
+
+		offset += length
+	}
+
+	slices.Sort(classes)
+
+	return classes
+}
+
+// setAuthorized authorizes or disconnects a USB device.
+func setAuthorized(id string, authorized bool) error {
+	value := "0"
+	if authorized {
+		value = "1"
+	}
+
+	return os.WriteFile(filepath.Join(SysfsPath, id, "authorized"), []byte(value), 0o600)
+}
+
+// GetSystemDevice returns the USB device IncusOS is running from, if any.
+func GetSystemDevice(ctx context.Context) string {
+	drive, err := storage.GetUnderlyingDevice()
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to determine the system drive", "err", err)
+
+		return ""
+	}
+
+	devicePath, err := filepath.EvalSymlinks(filepath.Join("/sys/class/block", filepath.Base(drive), "device"))
+	if err != nil {
+		return ""
+	}
+
+	// Find the closest USB device in the path.
+	ret := ""
+
+	for part := range strings.SplitSeq(devicePath, "/") {
+		_, err := os.Stat(filepath.Join(SysfsPath, part))
+		if err == nil && !strings.HasPrefix(part, "usb") && !strings.Contains(part, ":") {
+			ret = part
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,54 @@
+package usb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+func TestValidatePolicy(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidatePolicy(nil))
+	require.NoError(t, ValidatePolicy(&api.SystemSecurityUSB{Policy: "block", AllowedClasses: []string{"03"}, AllowedDevices: []string{"046d:c52b"}}))
+	require.Error(t, ValidatePolicy(&api.SystemSecurityUSB{Policy: "deny"}))
+	require.Error(t, ValidatePolicy(&api.SystemSecurityUSB{AllowedClasses: []string{"3"}}))
+	require.Error(t, ValidatePolicy(&api.SystemSecurityUSB{AllowedDevices: []string{"046D:C52B"}}))
+}
+
+func TestParseClasses(t *testing.T) {
+	t.Parallel()
+
+	// Keyboard with an additional mass storage interface.
+	descriptors := []byte{
+		// Device descriptor, class defined at the interface level.
+		18, 1, 0x00, 0x02, 0x00, 0x00, 0x00, 64, 0x6d, 0x04, 0x2b, 0xc5, 0x00, 0x01, 1, 2, 3, 1,
+		// Configuration descriptor.
+		9, 2, 41, 0, 2, 1, 0, 0xa0, 50,
+		// HID interface and its endpoint.
+		9, 4, 0, 0, 1, 0x03, 1, 1, 0,
+		7, 5, 0x81, 3, 8, 0, 10,
+		// Mass storage interface.
+		9, 4, 1, 0, 2, 0x08, 6, 80, 0,
+		// Truncated descriptor.
+		9, 4,
+	}
+
+	require.Equal(t, []string{"03", "08"}, parseClasses(descriptors))
+	require.Equal(t, []string{"09"}, parseClasses([]byte{18, 1, 0x00, 0x02, 0x09, 0x00, 0x01, 64, 0xe3, 0x05, 0x10, 0x06, 0x00, 0x01, 0, 1, 0, 1}))
+	require.Empty(t, parseClasses(nil))
+}
+
+func TestIsAllowed(t *testing.T) {
+	t.Parallel()
+
+	cfg := &api.SystemSecurityUSB{Policy: "block", AllowedClasses: []string{"03"}, AllowedDevices: []string{"0781:5583"}}
+
+	require.True(t, isAllowed(cfg, api.SystemSecurityUSBDevice{VendorID: "046d", ProductID: "c52b", Classes: []string{"03"}}))
+	require.True(t, isAllowed(cfg, api.SystemSecurityUSBDevice{VendorID: "0781", ProductID: "5583", Classes: []string{"08"}}))
+	require.True(t, isAllowed(cfg, api.SystemSecurityUSBDevice{VendorID: "05e3", ProductID: "0610", Classes: []string{"09"}}))
+	require.False(t, isAllowed(cfg, api.SystemSecurityUSBDevice{VendorID: "046d", ProductID: "c52c", Classes: []string{"03", "08"}}))
+	require.False(t, isAllowed(cfg, api.SystemSecurityUSBDevice{VendorID: "1234", ProductID: "5678"}))
+}
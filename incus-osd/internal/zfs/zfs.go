@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -80,9 +81,80 @@ func LoadPools(ctx context.Context, s *state.State) error {
 	// NOTE: This logic can go away in January 2026.
 	_, _ = subprocess.RunCommand("zfs", "set", "incusos:use=incus", "local/incus")
 
+	// Refresh the list of tracked pools, which also picks up any pools created before pools were tracked in the state.
+	pools, err = getPoolsWithKnownKeys()
+	if err != nil {
+		return err
+	}
+
+	for _, pool := range pools {
+		if !storage.PoolExists(ctx, pool) {
+			continue
+		}
+
+		err := trackPool(ctx, s, pool)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, pool := range s.System.Storage.Config.Pools {
+		if !storage.PoolExists(ctx, pool.Name) {
+			slog.WarnContext(ctx, "Storage pool '"+pool.Name+"' is missing")
+		}
+	}
+
 	return nil
 }
 
+// Helper function to record the current member devices of a pool in the state.
+func trackPool(ctx context.Context, s *state.State, zpoolName string) error {
+	poolConfig, err := storage.GetZpoolMembers(ctx, zpoolName)
+	if err != nil {
+		return err
+	}
+
+	// Only record the configurable fields. The degraded devices are kept after the others, rather
+	// than sorted in, as updates to the pool compare the devices by position.
+	tracked := api.SystemStoragePool{
+		Name:    poolConfig.Name,
+		Type:    poolConfig.Type,
+		Devices: slices.Concat(poolConfig.Devices, poolConfig.DevicesDegraded),
+		Cache:   slices.Concat(poolConfig.Cache, poolConfig.CacheDegraded),
+		Log:     slices.Concat(poolConfig.Log, poolConfig.LogDegraded),
+	}
+
+	for i, pool := range s.System.Storage.Config.Pools {
+		if pool.Name == zpoolName {
+			s.System.Storage.Config.Pools[i] = tracked
+
+			return nil
+		}
+	}
+
+	s.System.Storage.Config.Pools = append(s.System.Storage.Config.Pools, tracked)
+
+	return nil
+}
+
+// Helper function to remove a pool from the state.
+func untrackPool(s *state.State, zpoolName string) {
+	s.System.Storage.Config.Pools = slices.DeleteFunc(s.System.Storage.Config.Pools, func(pool api.SystemStoragePool) bool {
+		return pool.Name == zpoolName
+	})
+}
+
+// GetTrackedPool returns the last known configuration of a pool managed by IncusOS.
+func GetTrackedPool(s *state.State, zpoolName string) (api.SystemStoragePool, bool) {
+	for _, pool := range s.System.Storage.Config.Pools {
+		if pool.Name == zpoolName {
+			return pool, true
+		}
+	}
+
+	return api.SystemStoragePool{}, false
+}
+
 func recoverLocalPool(ctx context.Context) error {
 	poolConfig, err := storage.GetZpoolMembers(ctx, "local")
 	if err != nil {
@@ -299,11 +371,11 @@ func CreateZpool(ctx context.Context, zpool api.SystemStoragePool, s *state.Stat
 	// Reset encryption retrieval flag when a new zpool is created.
 	s.System.Security.State.EncryptionRecoveryKeysRetrieved = false
 
-	return nil
+	return trackPool(ctx, s, zpool.Name)
 }
 
 // DestroyZpool destroys an existing zpool.
-func DestroyZpool(ctx context.Context, zpoolName string) error {
+func DestroyZpool(ctx context.Context, s *state.State, zpoolName string) error {
 	// Don't allow destruction of the "local" zpool.
 	if zpoolName == "local" {
 		return errors.New("cannot destroy special zpool 'local'")
@@ -321,6 +393,8 @@ func DestroyZpool(ctx context.Context, zpoolName string) error {
 		return err
 	}
 
+	untrackPool(s, zpoolName)
+
 	// Remove the old encryption key.
 	err = os.Remove("/var/lib/incus-os/zpool." + zpoolName + ".key")
 	if err != nil {
@@ -387,7 +461,16 @@ func clearDevice(ctx context.Context, device string) error {
 }
 
 // UpdateZpool updates the devices used for an existing zpool.
-func UpdateZpool(ctx context.Context, newConfig api.SystemStoragePool) error {
+func UpdateZpool(ctx context.Context, s *state.State, newConfig api.SystemStoragePool) error {
+	err := updateZpool(ctx, newConfig)
+	if err != nil {
+		return err
+	}
+
+	return trackPool(ctx, s, newConfig.Name)
+}
+
+func updateZpool(ctx context.Context, newConfig api.SystemStoragePool) error {
 	// Check if the zpool exists.
 	if !storage.PoolExists(ctx, newConfig.Name) {
 		return errors.New("zpool '" + newConfig.Name + "' doesn't exist")
@@ -469,20 +552,11 @@ func prepareLocalPoolUpdate(ctx context.Context, currentConfig api.SystemStorage
 		return errors.New("special zpool 'local' type must be either zfs-raid0 or zfs-raid1")
 	}
 
-	// Must have exactly one or two data devices and no log or cache.
+	// Must have exactly one or two data devices.
 	if len(newConfig.Devices) > 2 {
 		return errors.New("special zpool 'local' cannot consist of more than two devices")
 	}
 
-	if len(newConfig.Log) > 0 {
-		return errors.New("special zpool 'local' cannot have any log devices")
-	}
-
-	if len(newConfig.Cache) > 0 {
-		return errors.New("special zpool 'local' cannot have any cache devices")
-	}
-
-	// The main system drive must ALWAYS be a member of the pool.
 	rootDev, err := storage.GetUnderlyingDevice()
 	if err != nil {
 		return err
@@ -493,6 +567,34 @@ func prepareLocalPoolUpdate(ctx context.Context, currentConfig api.SystemStorage
 		return err
 	}
 
+	// Log (SLOG) and cache (L2ARC) devices must be dedicated local NVMe drives, otherwise
+	// they won't provide any meaningful speedup over the main system drive.
+	for _, dev := range slices.Concat(newConfig.Log, newConfig.Cache) {
+		// An empty entry means the device is being removed from the pool.
+		if dev == "" {
+			continue
+		}
+
+		actualDev, err := storage.DeviceToID(ctx, dev)
+		if err != nil {
+			return err
+		}
+
+		if actualDev == actualrootDev || strings.HasPrefix(actualDev, actualrootDev+"-part") {
+			return errors.New("special zpool 'local' cannot use the main system drive as a log or cache device")
+		}
+
+		isNVME, err := storage.IsLocalNVMEDevice(actualDev)
+		if err != nil {
+			return err
+		}
+
+		if !isNVME {
+			return errors.New("special zpool 'local' only supports local NVMe log and cache devices, '" + dev + "' isn't one")
+		}
+	}
+
+	// The main system drive must ALWAYS be a member of the pool.
 	if !slices.Contains(newConfig.Devices, actualrootDev+"-part11") {
 		return errors.New("special zpool 'local' must always include main system partition '" + actualrootDev + "-part11'")
 	}
@@ -712,7 +814,7 @@ func GetZpoolEncryptionKeys() (map[string]string, error) {
 
 // ImportExistingPool will import an existing but currently unmanaged ZFS pool.
 // After importing, it will save and then load the encryption key.
-func ImportExistingPool(ctx context.Context, pool string, key string) error {
+func ImportExistingPool(ctx context.Context, s *state.State, pool string, key string) error {
 	reverter := revert.New()
 	defer reverter.Fail()
 
@@ -781,6 +883,11 @@ func ImportExistingPool(ctx context.Context, pool string, key string) error {
 		return err
 	}
 
+	err = trackPool(ctx, s, pool)
+	if err != nil {
+		return err
+	}
+
 	reverter.Success()
 
 	return nil
@@ -799,6 +906,13 @@ func CreateDataset(ctx context.Context, poolName string, name string, properties
 	return err
 }
 
+// CreateVolume creates a new block volume of the provided size in the specified pool.
+func CreateVolume(ctx context.Context, poolName string, name string, sizeInBytes int) error {
+	_, err := subprocess.RunCommandContext(ctx, "zfs", "create", "-p", "-V", strconv.Itoa(sizeInBytes), poolName+"/"+name)
+
+	return err
+}
+
 // DestroyDataset removes a dataset from the specified pool.
 func DestroyDataset(ctx context.Context, poolName string, name string, force bool) error {
 	args := []string{"destroy", poolName + "/" + name}
@@ -811,6 +925,130 @@ func DestroyDataset(ctx context.Context, poolName string, name string, force boo
 	return err
 }
 
+// ValidateDatasetProperties checks that the provided volume properties are supported by the type of
+// dataset, either "filesystem" or "volume", and returns them as a map of ZFS property names to values.
+// Properties without a value are omitted.
+func ValidateDatasetProperties(props api.SystemStoragePoolVolumeProperties, datasetType string) (map[string]string, error) {
+	ret := map[string]string{}
+
+	if props.Compression != "" {
+		if !isValidCompression(props.Compression) {
+			return nil, errors.New("unsupported compression algorithm '" + props.Compression + "'")
+		}
+
+		ret["compression"] = props.Compression
+	}
+
+	if props.RecordSize != "" {
+		// Block volumes use volblocksize instead, which can only be set when creating them.
+		if datasetType != "filesystem" {
+			return nil, errors.New("record size can only be set on filesystems")
+		}
+
+		size, err := parseRecordSize(props.RecordSize)
+		if err != nil {
+			return nil, err
+		}
+
+		ret["recordsize"] = strconv.Itoa(size)
+	}
+
+	if props.Atime != "" {
+		if !slices.Contains([]string{"on", "off"}, props.Atime) {
+			return nil, errors.New("unsupported atime value '" + props.Atime + "'")
+		}
+
+		ret["atime"] = props.Atime
+	}
+
+	if props.Sync != "" {
+		if !slices.Contains([]string{"standard", "always", "disabled"}, props.Sync) {
+			return nil, errors.New("unsupported sync value '" + props.Sync + "'")
+		}
+
+		ret["sync"] = props.Sync
+	}
+
+	return ret, nil
+}
+
+// Helper function to check if a compression value is supported by ZFS.
+func isValidCompression(value string) bool {
+	if slices.Contains([]string{"on", "off", "lz4", "lzjb", "zle", "gzip", "zstd", "zstd-fast"}, value) {
+		return true
+	}
+
+	level, found := strings.CutPrefix(value, "zstd-fast-")
+	if found {
+		n, err := strconv.Atoi(level)
+
+		return err == nil && ((n >= 1 && n <= 10) || (n >= 20 && n <= 100 && n%10 == 0) || n == 500 || n == 1000)
+	}
+
+	level, found = strings.CutPrefix(value, "zstd-")
+	if found {
+		n, err := strconv.Atoi(level)
+
+		return err == nil && n >= 1 && n <= 19
+	}
+
+	level, found = strings.CutPrefix(value, "gzip-")
+	if found {
+		n, err := strconv.Atoi(level)
+
+		return err == nil && n >= 1 && n <= 9
+	}
+
+	return false
+}
+
+// Helper function to parse a record size such as "128K" or "1M" into bytes. The record size must
+// be a power of two between 512 bytes and 16MiB.
+func parseRecordSize(value string) (int, error) {
+	multiplier := 1
+	number := strings.ToUpper(value)
+
+	if strings.HasSuffix(number, "K") {
+		multiplier = 1024
+		number = strings.TrimSuffix(number, "K")
+	} else if strings.HasSuffix(number, "M") {
+		multiplier = 1024 * 1024
+		number = strings.TrimSuffix(number, "M")
+	}
+
+	size, err := strconv.Atoi(number)
+	if err != nil {
+		return 0, errors.New("invalid record size '" + value + "'")
+	}
+
+	size *= multiplier
+
+	if size < 512 || size > 16*1024*1024 || size&(size-1) != 0 {
+		return 0, errors.New("record size '" + value + "' must be a power of two between 512 and 16M")
+	}
+
+	return size, nil
+}
+
+// SetDatasetProperties updates one or more properties of an existing dataset in the specified pool.
+func SetDatasetProperties(ctx context.Context, poolName string, name string, properties map[string]string) error {
+	if len(properties) == 0 {
+		return nil
+	}
+
+	args := []string{"set"}
+
+	for k, v := range properties {
+		args = append(args, k+"="+v)
+	}
+
+	args = append(args, poolName+"/"+name)
+
+	_, err := subprocess.RunCommandContext(ctx, "zfs", args...)
+
+	return err
+}
+
 // ScrubZpool scrubs the specified pool.
 func ScrubZpool(ctx context.Context, poolName string) error {
 	// Check if the zpool exists.
@@ -843,3 +1081,161 @@ func ScrubZpool(ctx context.Context, poolName string) error {
 
 	return nil
 }
+
+// CreateCheckpoint creates a checkpoint of the given pool, replacing any existing one.
+func CreateCheckpoint(ctx context.Context, zpoolName string) error {
+	hasCheckpoint, err := storage.PoolHasCheckpoint(ctx, zpoolName)
+	if err != nil {
+		return err
+	}
+
+	// A pool can only have a single checkpoint.
+	if hasCheckpoint {
+		err := DiscardCheckpoint(ctx, zpoolName)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = subprocess.RunCommandContext(ctx, "zpool", "checkpoint", zpoolName)
+
+	return err
+}
+
+// DiscardCheckpoint discards the checkpoint of the given pool.
+func DiscardCheckpoint(ctx context.Context, zpoolName string) error {
+	hasCheckpoint, err := storage.PoolHasCheckpoint(ctx, zpoolName)
+	if err != nil {
+		return err
+	}
+
+	if !hasCheckpoint {
+		return errors.New("pool '" + zpoolName + "' doesn't have a checkpoint")
+	}
+
+	// Wait for the discard to complete, so a new checkpoint can be taken right away.
+	_, err = subprocess.RunCommandContext(ctx, "zpool", "checkpoint", "-d", "-w", zpoolName)
+
+	return err
+}
+
+// RewindCheckpoint rewinds the given pool to its checkpoint. All changes made since the
+// checkpoint was taken, including any configuration changes to the pool, are lost.
+func RewindCheckpoint(ctx context.Context, s *state.State, zpoolName string) error {
+	hasCheckpoint, err := storage.PoolHasCheckpoint(ctx, zpoolName)
+	if err != nil {
+		return err
+	}
+
+	if !hasCheckpoint {
+		return errors.New("pool '" + zpoolName + "' doesn't have a checkpoint")
+	}
+
+	// The pool must be exported before it can be rewound.
+	_, err = subprocess.RunCommandContext(ctx, "zpool", "export", zpoolName)
+	if err != nil {
+		return err
+	}
+
+	_, err = subprocess.RunCommandContext(ctx, "zpool", "import", "--rewind-to-checkpoint", zpoolName)
+	if err != nil {
+		return err
+	}
+
+	_, err = subprocess.RunCommandContext(ctx, "zfs", "load-key", zpoolName)
+	if err != nil {
+		return err
+	}
+
+	_, err = subprocess.RunCommandContext(ctx, "zfs", "mount", "-a")
+	if err != nil {
+		return err
+	}
+
+	// Rewinding the pool also discards its checkpoint.
+	ForgetCheckpoint(s, zpoolName)
+
+	// The pool's devices may have changed since the checkpoint was taken.
+	return trackPool(ctx, s, zpoolName)
+}
+
+// CheckpointPools creates a fresh checkpoint of every pool managed by IncusOS ahead of
+// a potentially destructive operation. Failures are logged but otherwise ignored.
+func CheckpointPools(ctx context.Context, s *state.State, reason string) {
+	for _, pool := range s.System.Storage.Config.Pools {
+		if !storage.PoolExists(ctx, pool.Name) {
+			continue
+		}
+
+		slog.InfoContext(ctx, "Creating storage pool checkpoint", "pool", pool.Name, "reason", reason)
+
+		err := CreateCheckpoint(ctx, pool.Name)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to create storage pool checkpoint", "pool", pool.Name, "err", err)
+
+			continue
+		}
+
+		RecordCheckpoint(s, pool.Name, reason)
+	}
+}
+
+// CheckpointPoolsForUpdate checkpoints the pools ahead of an OS update, so the checkpoints can be
+// discarded once the new version has booted and is healthy.
+func CheckpointPoolsForUpdate(ctx context.Context, s *state.State, version string) {
+	CheckpointPools(ctx, s, getUpdateCheckpointReason(version))
+
+	s.OS.CheckpointRelease = version
+}
+
+// DiscardUpdateCheckpoints discards the checkpoints taken ahead of updating to the running OS
+// version. Checkpoints are kept if the update was rolled back, as the pools may need rewinding.
+func DiscardUpdateCheckpoints(ctx context.Context, s *state.State) {
+	version := s.OS.CheckpointRelease
+	if version == "" || version != s.OS.RunningRelease {
+		return
+	}
+
+	for zpoolName, reason := range s.StorageCheckpoints {
+		if reason != getUpdateCheckpointReason(version) {
+			continue
+		}
+
+		slog.InfoContext(ctx, "Discarding storage pool checkpoint", "pool", zpoolName, "reason", reason)
+
+		hasCheckpoint, err := storage.PoolHasCheckpoint(ctx, zpoolName)
+		if err == nil && hasCheckpoint {
+			err = DiscardCheckpoint(ctx, zpoolName)
+		}
+
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to discard storage pool checkpoint", "pool", zpoolName, "err", err)
+
+			continue
+		}
+
+		ForgetCheckpoint(s, zpoolName)
+	}
+
+	s.OS.CheckpointRelease = ""
+	_ = s.Save()
+}
+
+// RecordCheckpoint records why the checkpoint of the given pool was taken.
+func RecordCheckpoint(s *state.State, zpoolName string, reason string) {
+	if s.StorageCheckpoints == nil {
+		s.StorageCheckpoints = map[string]string{}
+	}
+
+	s.StorageCheckpoints[zpoolName] = reason
+}
+
+// ForgetCheckpoint forgets about the checkpoint of the given pool once discarded or rewound to.
+func ForgetCheckpoint(s *state.State, zpoolName string) {
+	delete(s.StorageCheckpoints, zpoolName)
+}
+
+// getUpdateCheckpointReason returns the reason recorded for checkpoints taken ahead of an OS update.
+func getUpdateCheckpointReason(version string) string {
+	return "OS update to " + version
+}
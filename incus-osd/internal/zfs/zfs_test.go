@@ -0,0 +1,125 @@
+package zfs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus-os/incus-osd/api"
+)
+
+func TestValidateDatasetProperties(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name        string
+		props       api.SystemStoragePoolVolumeProperties
+		datasetType string
+		expected    map[string]string
+		expectedErr string
+	}{
+		{
+			name:     "No properties",
+			props:    api.SystemStoragePoolVolumeProperties{},
+			expected: map[string]string{},
+		},
+		{
+			name: "All properties",
+			props: api.SystemStoragePoolVolumeProperties{
+				Compression: "zstd-3",
+				RecordSize:  "16K",
+				Atime:       "off",
+				Sync:        "always",
+			},
+			expected: map[string]string{
+				"compression": "zstd-3",
+				"recordsize":  "16384",
+				"atime":       "off",
+				"sync":        "always",
+			},
+		},
+		{
+			name:     "zstd-fast level",
+			props:    api.SystemStoragePoolVolumeProperties{Compression: "zstd-fast-500"},
+			expected: map[string]string{"compression": "zstd-fast-500"},
+		},
+		{
+			name:     "zstd-fast step level",
+			props:    api.SystemStoragePoolVolumeProperties{Compression: "zstd-fast-20"},
+			expected: map[string]string{"compression": "zstd-fast-20"},
+		},
+		{
+			name:        "Volume properties",
+			props:       api.SystemStoragePoolVolumeProperties{Compression: "lz4", Sync: "disabled"},
+			datasetType: "volume",
+			expected:    map[string]string{"compression": "lz4", "sync": "disabled"},
+		},
+		{
+			name:     "Record size in bytes",
+			props:    api.SystemStoragePoolVolumeProperties{RecordSize: "4096"},
+			expected: map[string]string{"recordsize": "4096"},
+		},
+		{
+			name:        "Bad compression",
+			props:       api.SystemStoragePoolVolumeProperties{Compression: "zstd-20"},
+			expectedErr: "unsupported compression algorithm 'zstd-20'",
+		},
+		{
+			name:        "Bad zstd-fast level",
+			props:       api.SystemStoragePoolVolumeProperties{Compression: "zstd-fast-15"},
+			expectedErr: "unsupported compression algorithm 'zstd-fast-15'",
+		},
+		{
+			name:        "Zero zstd-fast level",
+			props:       api.SystemStoragePoolVolumeProperties{Compression: "zstd-fast-0"},
+			expectedErr: "unsupported compression algorithm 'zstd-fast-0'",
+		},
+		{
+			name:        "Negative zstd-fast level",
+			props:       api.SystemStoragePoolVolumeProperties{Compression: "zstd-fast--10"},
+			expectedErr: "unsupported compression algorithm 'zstd-fast--10'",
+		},
+		{
+			name:        "Record size on a volume",
+			props:       api.SystemStoragePoolVolumeProperties{RecordSize: "16K"},
+			datasetType: "volume",
+			expectedErr: "record size can only be set on filesystems",
+		},
+		{
+			name:        "Record size not a power of two",
+			props:       api.SystemStoragePoolVolumeProperties{RecordSize: "96K"},
+			expectedErr: "record size '96K' must be a power of two between 512 and 16M",
+		},
+		{
+			name:        "Record size too large",
+			props:       api.SystemStoragePoolVolumeProperties{RecordSize: "32M"},
+			expectedErr: "record size '32M' must be a power of two between 512 and 16M",
+		},
+		{
+			name:        "Bad sync",
+			props:       api.SystemStoragePoolVolumeProperties{Sync: "sometimes"},
+			expectedErr: "unsupported sync value 'sometimes'",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			datasetType := tc.datasetType
+			if datasetType == "" {
+				datasetType = "filesystem"
+			}
+
+			got, err := ValidateDatasetProperties(tc.props, datasetType)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, got)
+		})
+	}
+}
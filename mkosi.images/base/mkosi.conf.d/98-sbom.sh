@@ -0,0 +1,7 @@
+#!/bin/sh -eux
+
+# Record the installed packages, as the package database isn't part of the image.
+mkdir -p "${DESTDIR}/usr/lib/incus-os/sbom"
+dpkg-query -W -f '${Package}\t${Version}\t${Architecture}\t${source:Package}\t${source:Version}\n' | sort > "${DESTDIR}/usr/lib/incus-os/sbom/base.tsv"
+
+exit 0